@@ -305,4 +305,26 @@ func JWTMiddleware(next http.Handler) http.Handler {
 		ctx := context.WithValue(r.Context(), userKey, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
-}
\ No newline at end of file
+}
+
+// CurrentUserEmail returns the email of the user authenticated by
+// JWTMiddleware, or an empty string outside of an authenticated request
+func CurrentUserEmail(ctx context.Context) string {
+	claims, ok := ctx.Value(userKey).(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	email, _ := claims["email"].(string)
+	return email
+}
+
+// CurrentUserRole returns the role of the user authenticated by
+// JWTMiddleware, or an empty string outside of an authenticated request
+func CurrentUserRole(ctx context.Context) string {
+	claims, ok := ctx.Value(userKey).(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	role, _ := claims["role"].(string)
+	return role
+}
@@ -2,11 +2,88 @@
 
 package model
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/samObot19/shopverse/api-gate-way/money"
+)
+
+type Category struct {
+	ID        string      `json:"id"`
+	ParentID  *string     `json:"parentID,omitempty"`
+	Name      string      `json:"name"`
+	Slug      string      `json:"slug"`
+	Position  int32       `json:"position"`
+	CreatedAt string      `json:"createdAt"`
+	Children  []*Category `json:"children"`
+}
+
+type CategoryInput struct {
+	Name     string  `json:"name"`
+	Slug     *string `json:"slug,omitempty"`
+	ParentID *string `json:"parentID,omitempty"`
+	Position *int32  `json:"position,omitempty"`
+}
+
+// What one USD buys of another currency, and how prices converted into it are rounded
+type ExchangeRate struct {
+	// ISO 4217 code, e.g. EUR
+	Currency string `json:"currency"`
+	// Decimal with at most 10 places, e.g. 0.92
+	Rate     string       `json:"rate"`
+	Rounding RoundingMode `json:"rounding"`
+	// Minor units prices are rounded to a multiple of, e.g. 5 for 0.05 CHF
+	RoundingIncrement int32  `json:"roundingIncrement"`
+	UpdatedAt         string `json:"updatedAt"`
+}
+
+type ExchangeRateInput struct {
+	Currency          string        `json:"currency"`
+	Rate              string        `json:"rate"`
+	Rounding          *RoundingMode `json:"rounding,omitempty"`
+	RoundingIncrement *int32        `json:"roundingIncrement,omitempty"`
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int32  `json:"count"`
+}
+
+type FilterConditionInput struct {
+	Field    ProductFilterField `json:"field"`
+	Operator FilterOperator     `json:"operator"`
+	Values   []string           `json:"values,omitempty"`
+	Min      *float64           `json:"min,omitempty"`
+	Max      *float64           `json:"max,omitempty"`
+}
+
 type FilterInput struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
 }
 
+// A product image encoded in one size and format
+type ImageRendition struct {
+	// e.g. thumbnail, medium or large
+	Size   string      `json:"size"`
+	Format ImageFormat `json:"format"`
+	// In pixels
+	Width int32 `json:"width"`
+	// In pixels
+	Height int32 `json:"height"`
+	// Length of the file
+	Bytes int32  `json:"bytes"`
+	URL   string `json:"url"`
+}
+
+// A point on the map
+type LocationInput struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
 type Mutation struct {
 }
 
@@ -15,7 +92,7 @@ type Order struct {
 	UserID          string       `json:"userID"`
 	OrderStatus     string       `json:"orderStatus"`
 	PaymentStatus   string       `json:"paymentStatus"`
-	TotalAmount     float64      `json:"totalAmount"`
+	TotalAmount     money.Money  `json:"totalAmount"`
 	ShippingAddress string       `json:"shippingAddress"`
 	BillingAddress  string       `json:"billingAddress"`
 	CreatedAt       string       `json:"createdAt"`
@@ -27,35 +104,141 @@ type OrderInput struct {
 	UserID          string            `json:"userID"`
 	Items           []*OrderItemInput `json:"items"`
 	ShippingAddress string            `json:"shippingAddress"`
-	BillingAddress  string            `json:"billingAddress"`
+	// Where the order ships to; its stock is then taken from the nearest warehouse
+	ShippingLocation *LocationInput `json:"shippingLocation,omitempty"`
+	BillingAddress   string         `json:"billingAddress"`
 }
 
 type OrderItem struct {
-	ID           string  `json:"id"`
-	ProductID    string  `json:"productID"`
-	ProductPrice float64 `json:"productPrice"`
-	Quantity     int32   `json:"quantity"`
-	TotalPrice   float64 `json:"totalPrice"`
+	ID           string      `json:"id"`
+	ProductID    string      `json:"productID"`
+	VariantID    *string     `json:"variantID,omitempty"`
+	ProductPrice money.Money `json:"productPrice"`
+	Quantity     int32       `json:"quantity"`
+	TotalPrice   money.Money `json:"totalPrice"`
+	// Currency the product is priced in, when productPrice was converted from it
+	BaseCurrency *string `json:"baseCurrency,omitempty"`
+	// Rate productPrice was converted at from baseCurrency
+	ExchangeRate *string `json:"exchangeRate,omitempty"`
 }
 
 type OrderItemInput struct {
-	ProductID    string  `json:"productID"`
-	ProductPrice float64 `json:"productPrice"`
-	Quantity     int32   `json:"quantity"`
-	TotalPrice   float64 `json:"totalPrice"`
+	ProductID string  `json:"productID"`
+	VariantID *string `json:"variantID,omitempty"`
+	// Ignored; items are charged the effective price of the product or variant at checkout
+	ProductPrice *float64 `json:"productPrice,omitempty"`
+	Quantity     int32    `json:"quantity"`
+	// Ignored; the total is the item's price times its quantity
+	TotalPrice *float64 `json:"totalPrice,omitempty"`
+}
+
+type PriceBucket struct {
+	Min      float64  `json:"min"`
+	Max      *float64 `json:"max,omitempty"`
+	Currency string   `json:"currency"`
+	Count    int32    `json:"count"`
+}
+
+type PriceChange struct {
+	ID        string  `json:"id"`
+	ProductID string  `json:"productID"`
+	VariantID *string `json:"variantID,omitempty"`
+	// Empty for a new base price
+	PriceListID *string      `json:"priceListID,omitempty"`
+	Price       *money.Money `json:"price,omitempty"`
+	PercentOff  float64      `json:"percentOff"`
+	StartsAt    string       `json:"startsAt"`
+	EndsAt      *string      `json:"endsAt,omitempty"`
+	CreatedAt   string       `json:"createdAt"`
+}
+
+// The exchange rate prices were converted at
+type PriceConversion struct {
+	// Currency the prices are set in
+	FromCurrency string `json:"fromCurrency"`
+	// Units of the currency asked for per unit of fromCurrency, as a decimal
+	Rate string `json:"rate"`
+}
+
+type PriceHistoryPage struct {
+	Changes []*PriceChange `json:"changes"`
+	// Empty on the last page
+	NextPageToken *string `json:"nextPageToken,omitempty"`
+}
+
+type PriceList struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	StartsAt string `json:"startsAt"`
+	// Empty for a price list without an end
+	EndsAt    *string           `json:"endsAt,omitempty"`
+	Entries   []*PriceListEntry `json:"entries"`
+	CreatedAt string            `json:"createdAt"`
+}
+
+type PriceListEntry struct {
+	ProductID string `json:"productID"`
+	// Empty for the product and all of its variants
+	VariantID *string `json:"variantID,omitempty"`
+	// Fixed sale price
+	Price *money.Money `json:"price,omitempty"`
+	// Taken off the base price when price is empty
+	PercentOff float64 `json:"percentOff"`
+}
+
+type PriceListEntryInput struct {
+	ProductID string  `json:"productID"`
+	VariantID *string `json:"variantID,omitempty"`
+	// Must be in the product's currency
+	Price      *money.Money `json:"price,omitempty"`
+	PercentOff *float64     `json:"percentOff,omitempty"`
+}
+
+type PriceListInput struct {
+	Name string `json:"name"`
+	// RFC 3339
+	StartsAt string `json:"startsAt"`
+	// RFC 3339; empty for a price list without an end
+	EndsAt  *string                `json:"endsAt,omitempty"`
+	Entries []*PriceListEntryInput `json:"entries"`
 }
 
 type Product struct {
-	ID          string             `json:"id"`
-	Title       string             `json:"title"`
-	Description string             `json:"description"`
-	Price       float64            `json:"price"`
-	Stock       int32              `json:"stock"`
-	Category    string             `json:"category"`
-	Attributes  *ProductAttributes `json:"attributes"`
-	Images      []string           `json:"images"`
-	Ratings     float64            `json:"ratings"`
-	CreatedAt   string             `json:"createdAt"`
+	ID string `json:"id"`
+	// Stock keeping unit, unique across products when set
+	Sku         *string `json:"sku,omitempty"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	// Base price, before any price list
+	Price money.Money `json:"price"`
+	// What a customer pays now: the sale price while the product is on sale, or else the base price
+	EffectivePrice money.Money `json:"effectivePrice"`
+	// The base price, while the product is on sale
+	CompareAtPrice *money.Money       `json:"compareAtPrice,omitempty"`
+	Stock          int32              `json:"stock"`
+	Category       string             `json:"category"`
+	CategoryID     *string            `json:"categoryID,omitempty"`
+	Attributes     *ProductAttributes `json:"attributes"`
+	Images         []string           `json:"images"`
+	Ratings        float64            `json:"ratings"`
+	ReviewCount    int32              `json:"reviewCount"`
+	CreatedAt      string             `json:"createdAt"`
+	// When the product was archived; null while it is active
+	DeletedAt *string `json:"deletedAt,omitempty"`
+	// Incremented by every change; pass it back to updateProduct, updateStock or deleteProduct
+	Version int32 `json:"version"`
+	// Set when the prices were converted into the currency asked for
+	Conversion *PriceConversion `json:"conversion,omitempty"`
+	// Locale the title and description are in, the first of the Accept-Language header the product is translated into; null for its default content
+	Locale *string `json:"locale,omitempty"`
+	// The title and description in every locale the product is translated into
+	Translations []*ProductTranslation `json:"translations"`
+	// Images uploaded for the product, in the order they are shown in
+	Media       []*ProductImage `json:"media"`
+	Variants    []*Variant      `json:"variants"`
+	StockLevels []*StockLevel   `json:"stockLevels"`
+	Breadcrumbs []*Category     `json:"breadcrumbs"`
+	Reviews     []*Review       `json:"reviews"`
 }
 
 type ProductAttributes struct {
@@ -68,24 +251,590 @@ type ProductAttributesInput struct {
 	Size  string `json:"size"`
 }
 
+type ProductFilterInput struct {
+	Operator   *LogicalOperator        `json:"operator,omitempty"`
+	Conditions []*FilterConditionInput `json:"conditions,omitempty"`
+	Groups     []*ProductFilterInput   `json:"groups,omitempty"`
+}
+
+// An image uploaded for a product, kept as JPEG and WebP renditions of several sizes
+type ProductImage struct {
+	ID        string `json:"id"`
+	ProductID string `json:"productID"`
+	// Describes the image to shoppers who cannot see it
+	AltText string `json:"altText"`
+	// Width of the upload once turned upright, in pixels
+	Width int32 `json:"width"`
+	// Height of the upload once turned upright, in pixels
+	Height int32 `json:"height"`
+	// Order among the images of the product, from 0
+	Position int32 `json:"position"`
+	// Smallest first
+	Renditions []*ImageRendition `json:"renditions"`
+	CreatedAt  string            `json:"createdAt"`
+}
+
 type ProductInput struct {
+	Sku         *string                 `json:"sku,omitempty"`
 	Title       string                  `json:"title"`
 	Description string                  `json:"description"`
-	Price       float64                 `json:"price"`
+	Price       money.Money             `json:"price"`
 	Stock       int32                   `json:"stock"`
 	Category    string                  `json:"category"`
+	CategoryID  *string                 `json:"categoryID,omitempty"`
 	Attributes  *ProductAttributesInput `json:"attributes"`
 	Images      []string                `json:"images"`
-	Ratings     float64                 `json:"ratings"`
+	// Ignored; the rating of a product is the average of its approved reviews
+	Ratings *float64 `json:"ratings,omitempty"`
+}
+
+type ProductSearchResult struct {
+	Products []*Product    `json:"products"`
+	Total    int32         `json:"total"`
+	Facets   *SearchFacets `json:"facets"`
+}
+
+type ProductSortInput struct {
+	Field     ProductSortField `json:"field"`
+	Direction *SortDirection   `json:"direction,omitempty"`
+}
+
+type ProductSuggestion struct {
+	Text      string         `json:"text"`
+	Kind      SuggestionKind `json:"kind"`
+	ProductID *string        `json:"productID,omitempty"`
+}
+
+// The content of a product in one locale; a missing field falls back to the next locale asked for, and finally to the default content
+type ProductTranslation struct {
+	// BCP 47 language tag, e.g. fr-CA
+	Locale      string  `json:"locale"`
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+type ProductTranslationInput struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
 }
 
 type Query struct {
 }
 
+type Review struct {
+	ID           string       `json:"id"`
+	ProductID    string       `json:"productID"`
+	UserID       string       `json:"userID"`
+	OrderID      string       `json:"orderID"`
+	Rating       int32        `json:"rating"`
+	Title        string       `json:"title"`
+	Body         string       `json:"body"`
+	Status       ReviewStatus `json:"status"`
+	HelpfulVotes int32        `json:"helpfulVotes"`
+	CreatedAt    string       `json:"createdAt"`
+	UpdatedAt    string       `json:"updatedAt"`
+}
+
+type ReviewEditInput struct {
+	Rating int32   `json:"rating"`
+	Title  *string `json:"title,omitempty"`
+	Body   string  `json:"body"`
+}
+
+type ReviewInput struct {
+	ProductID string  `json:"productID"`
+	Rating    int32   `json:"rating"`
+	Title     *string `json:"title,omitempty"`
+	Body      string  `json:"body"`
+}
+
+type SearchFacets struct {
+	Category []*FacetValue  `json:"category"`
+	Color    []*FacetValue  `json:"color"`
+	Size     []*FacetValue  `json:"size"`
+	Price    []*PriceBucket `json:"price"`
+}
+
+type StockLevel struct {
+	Warehouse *Warehouse `json:"warehouse"`
+	VariantID *string    `json:"variantID,omitempty"`
+	Quantity  int32      `json:"quantity"`
+}
+
+type UpdateProductAttributesInput struct {
+	Color *string `json:"color,omitempty"`
+	// Comma-separated sizes; an empty string removes them all
+	Size *string `json:"size,omitempty"`
+}
+
+// The fields of a product to change; fields left out or null keep their value
+type UpdateProductInput struct {
+	// An empty SKU removes it
+	Sku         *string `json:"sku,omitempty"`
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	// Must be in the product's currency
+	Price      *money.Money                  `json:"price,omitempty"`
+	Stock      *int32                        `json:"stock,omitempty"`
+	Category   *string                       `json:"category,omitempty"`
+	CategoryID *string                       `json:"categoryID,omitempty"`
+	Attributes *UpdateProductAttributesInput `json:"attributes,omitempty"`
+	Images     []string                      `json:"images,omitempty"`
+}
+
 type User struct {
 	ID       *string `json:"id,omitempty"`
 	Name     *string `json:"name,omitempty"`
 	Email    *string `json:"email,omitempty"`
 	Password *string `json:"password,omitempty"`
 	Role     *string `json:"role,omitempty"`
+	// ISO 4217 code prices are shown in when a request sends no X-Currency header
+	PreferredCurrency *string `json:"preferredCurrency,omitempty"`
+}
+
+type Variant struct {
+	ID            string           `json:"id"`
+	ProductID     string           `json:"productID"`
+	Sku           string           `json:"sku"`
+	Options       []*VariantOption `json:"options"`
+	Price         money.Money      `json:"price"`
+	PriceOverride *money.Money     `json:"priceOverride,omitempty"`
+	// The price before the sale, while the variant is on sale
+	CompareAtPrice *money.Money `json:"compareAtPrice,omitempty"`
+	Stock          int32        `json:"stock"`
+	Images         []string     `json:"images"`
+	CreatedAt      string       `json:"createdAt"`
+	// Set when the prices were converted into the currency asked for
+	Conversion *PriceConversion `json:"conversion,omitempty"`
+}
+
+type VariantInput struct {
+	Sku     string                `json:"sku"`
+	Options []*VariantOptionInput `json:"options,omitempty"`
+	// Must be in the product's currency
+	PriceOverride *money.Money `json:"priceOverride,omitempty"`
+	Stock         int32        `json:"stock"`
+	Images        []string     `json:"images,omitempty"`
+}
+
+type VariantOption struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type VariantOptionInput struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Warehouse struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Address   string  `json:"address"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Active    bool    `json:"active"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+type WarehouseInput struct {
+	Name      string  `json:"name"`
+	Address   *string `json:"address,omitempty"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Active    *bool   `json:"active,omitempty"`
+}
+
+type FilterOperator string
+
+const (
+	FilterOperatorEq    FilterOperator = "EQ"
+	FilterOperatorIn    FilterOperator = "IN"
+	FilterOperatorRange FilterOperator = "RANGE"
+	FilterOperatorGte   FilterOperator = "GTE"
+	FilterOperatorLte   FilterOperator = "LTE"
+	FilterOperatorGt    FilterOperator = "GT"
+)
+
+var AllFilterOperator = []FilterOperator{
+	FilterOperatorEq,
+	FilterOperatorIn,
+	FilterOperatorRange,
+	FilterOperatorGte,
+	FilterOperatorLte,
+	FilterOperatorGt,
+}
+
+func (e FilterOperator) IsValid() bool {
+	switch e {
+	case FilterOperatorEq, FilterOperatorIn, FilterOperatorRange, FilterOperatorGte, FilterOperatorLte, FilterOperatorGt:
+		return true
+	}
+	return false
+}
+
+func (e FilterOperator) String() string {
+	return string(e)
+}
+
+func (e *FilterOperator) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FilterOperator(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FilterOperator", str)
+	}
+	return nil
+}
+
+func (e FilterOperator) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ImageFormat string
+
+const (
+	ImageFormatJpeg ImageFormat = "JPEG"
+	ImageFormatWebp ImageFormat = "WEBP"
+)
+
+var AllImageFormat = []ImageFormat{
+	ImageFormatJpeg,
+	ImageFormatWebp,
+}
+
+func (e ImageFormat) IsValid() bool {
+	switch e {
+	case ImageFormatJpeg, ImageFormatWebp:
+		return true
+	}
+	return false
+}
+
+func (e ImageFormat) String() string {
+	return string(e)
+}
+
+func (e *ImageFormat) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImageFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImageFormat", str)
+	}
+	return nil
+}
+
+func (e ImageFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type LogicalOperator string
+
+const (
+	LogicalOperatorAnd LogicalOperator = "AND"
+	LogicalOperatorOr  LogicalOperator = "OR"
+)
+
+var AllLogicalOperator = []LogicalOperator{
+	LogicalOperatorAnd,
+	LogicalOperatorOr,
+}
+
+func (e LogicalOperator) IsValid() bool {
+	switch e {
+	case LogicalOperatorAnd, LogicalOperatorOr:
+		return true
+	}
+	return false
+}
+
+func (e LogicalOperator) String() string {
+	return string(e)
+}
+
+func (e *LogicalOperator) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LogicalOperator(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LogicalOperator", str)
+	}
+	return nil
+}
+
+func (e LogicalOperator) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ProductFilterField string
+
+const (
+	ProductFilterFieldCategory ProductFilterField = "CATEGORY"
+	ProductFilterFieldColor    ProductFilterField = "COLOR"
+	ProductFilterFieldSize     ProductFilterField = "SIZE"
+	ProductFilterFieldPrice    ProductFilterField = "PRICE"
+	ProductFilterFieldStock    ProductFilterField = "STOCK"
+	ProductFilterFieldRatings  ProductFilterField = "RATINGS"
+)
+
+var AllProductFilterField = []ProductFilterField{
+	ProductFilterFieldCategory,
+	ProductFilterFieldColor,
+	ProductFilterFieldSize,
+	ProductFilterFieldPrice,
+	ProductFilterFieldStock,
+	ProductFilterFieldRatings,
+}
+
+func (e ProductFilterField) IsValid() bool {
+	switch e {
+	case ProductFilterFieldCategory, ProductFilterFieldColor, ProductFilterFieldSize, ProductFilterFieldPrice, ProductFilterFieldStock, ProductFilterFieldRatings:
+		return true
+	}
+	return false
+}
+
+func (e ProductFilterField) String() string {
+	return string(e)
+}
+
+func (e *ProductFilterField) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProductFilterField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProductFilterField", str)
+	}
+	return nil
+}
+
+func (e ProductFilterField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ProductSortField string
+
+const (
+	ProductSortFieldPrice     ProductSortField = "PRICE"
+	ProductSortFieldCreatedAt ProductSortField = "CREATED_AT"
+	ProductSortFieldRatings   ProductSortField = "RATINGS"
+	ProductSortFieldTitle     ProductSortField = "TITLE"
+)
+
+var AllProductSortField = []ProductSortField{
+	ProductSortFieldPrice,
+	ProductSortFieldCreatedAt,
+	ProductSortFieldRatings,
+	ProductSortFieldTitle,
+}
+
+func (e ProductSortField) IsValid() bool {
+	switch e {
+	case ProductSortFieldPrice, ProductSortFieldCreatedAt, ProductSortFieldRatings, ProductSortFieldTitle:
+		return true
+	}
+	return false
+}
+
+func (e ProductSortField) String() string {
+	return string(e)
+}
+
+func (e *ProductSortField) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProductSortField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProductSortField", str)
+	}
+	return nil
+}
+
+func (e ProductSortField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ReviewStatus string
+
+const (
+	ReviewStatusPending  ReviewStatus = "PENDING"
+	ReviewStatusApproved ReviewStatus = "APPROVED"
+	ReviewStatusRejected ReviewStatus = "REJECTED"
+)
+
+var AllReviewStatus = []ReviewStatus{
+	ReviewStatusPending,
+	ReviewStatusApproved,
+	ReviewStatusRejected,
+}
+
+func (e ReviewStatus) IsValid() bool {
+	switch e {
+	case ReviewStatusPending, ReviewStatusApproved, ReviewStatusRejected:
+		return true
+	}
+	return false
+}
+
+func (e ReviewStatus) String() string {
+	return string(e)
+}
+
+func (e *ReviewStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReviewStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReviewStatus", str)
+	}
+	return nil
+}
+
+func (e ReviewStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RoundingMode string
+
+const (
+	// Halves away from zero
+	RoundingModeNearest RoundingMode = "NEAREST"
+	RoundingModeUp      RoundingMode = "UP"
+	RoundingModeDown    RoundingMode = "DOWN"
+)
+
+var AllRoundingMode = []RoundingMode{
+	RoundingModeNearest,
+	RoundingModeUp,
+	RoundingModeDown,
+}
+
+func (e RoundingMode) IsValid() bool {
+	switch e {
+	case RoundingModeNearest, RoundingModeUp, RoundingModeDown:
+		return true
+	}
+	return false
+}
+
+func (e RoundingMode) String() string {
+	return string(e)
+}
+
+func (e *RoundingMode) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RoundingMode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RoundingMode", str)
+	}
+	return nil
+}
+
+func (e RoundingMode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SortDirection string
+
+const (
+	SortDirectionAsc  SortDirection = "ASC"
+	SortDirectionDesc SortDirection = "DESC"
+)
+
+var AllSortDirection = []SortDirection{
+	SortDirectionAsc,
+	SortDirectionDesc,
+}
+
+func (e SortDirection) IsValid() bool {
+	switch e {
+	case SortDirectionAsc, SortDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e SortDirection) String() string {
+	return string(e)
+}
+
+func (e *SortDirection) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SortDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SortDirection", str)
+	}
+	return nil
+}
+
+func (e SortDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SuggestionKind string
+
+const (
+	SuggestionKindTitle    SuggestionKind = "TITLE"
+	SuggestionKindCategory SuggestionKind = "CATEGORY"
+)
+
+var AllSuggestionKind = []SuggestionKind{
+	SuggestionKindTitle,
+	SuggestionKindCategory,
+}
+
+func (e SuggestionKind) IsValid() bool {
+	switch e {
+	case SuggestionKindTitle, SuggestionKindCategory:
+		return true
+	}
+	return false
+}
+
+func (e SuggestionKind) String() string {
+	return string(e)
+}
+
+func (e *SuggestionKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SuggestionKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SuggestionKind", str)
+	}
+	return nil
+}
+
+func (e SuggestionKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
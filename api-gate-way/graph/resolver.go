@@ -1,6 +1,14 @@
 package graph
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	user_client "github.com/samObot19/shopverse/api-gate-way/user-client"
 	"github.com/samObot19/shopverse/api-gate-way/product-client"
 	"github.com/samObot19/shopverse/api-gate-way/order-client"
@@ -15,3 +23,46 @@ type Resolver struct {
 	UserClient    *user_client.UserClient
 	OrderClient   *orderclient.OrderClient // Added OrderClient
 }
+
+// derefInt32 returns the value of an optional Int argument, or zero when it was omitted
+func derefInt32(v *int32) int32 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// derefString returns the value of an optional String or ID argument, or "" when it was omitted
+func derefString(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+// optionalString maps "" to a null field
+func optionalString(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+// productWriteError explains why a write to a product failed. A write based on
+// a version the product has since moved past fails with a VERSION_CONFLICT
+// error carrying the product's currentVersion, so the client can re-read it
+// and retry.
+func (r *Resolver) productWriteError(ctx context.Context, id, action string, err error) error {
+	if status.Code(err) != codes.Aborted {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	conflict := &gqlerror.Error{
+		Message:    fmt.Sprintf("failed to %s: product was changed since it was read", action),
+		Path:       graphql.GetPath(ctx),
+		Extensions: map[string]interface{}{"code": "VERSION_CONFLICT"},
+	}
+	if current, err := r.ProductClient.GetProductByID(ctx, id); err == nil {
+		conflict.Extensions["currentVersion"] = current.Version
+	}
+	return conflict
+}
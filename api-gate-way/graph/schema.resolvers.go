@@ -11,10 +11,14 @@ import (
 	"time"
 	"strconv"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/google/uuid"
+	"github.com/samObot19/shopverse/api-gate-way/authenticate"
 	"github.com/samObot19/shopverse/api-gate-way/graph/model"
+	orderclient "github.com/samObot19/shopverse/api-gate-way/order-client"
 	productclient "github.com/samObot19/shopverse/api-gate-way/product-client"
 	"github.com/samObot19/shopverse/api-gate-way/order-client/proto/pb"
+	"github.com/samObot19/shopverse/api-gate-way/preferences"
 )
 
 
@@ -48,17 +52,18 @@ func (r *mutationResolver) PromoteUser(ctx context.Context, username string) (*s
 func (r *mutationResolver) CreateProduct(ctx context.Context, input model.ProductInput) (string, error) {
 	product := &model.Product{
 		ID:          uuid.New().String(), 
+		Sku:         input.Sku,
 		Title:       input.Title,
 		Description: input.Description,
 		Price:       input.Price,
 		Stock:       input.Stock,
 		Category:    input.Category,
+		CategoryID:  input.CategoryID,
 		Attributes: &model.ProductAttributes{
 			Color: input.Attributes.Color,
 			Size:  input.Attributes.Size,
 		},
 		Images:    input.Images,
-		Ratings:   input.Ratings,
 		CreatedAt: time.Now().Format(time.RFC3339), 
 	}
 	err := r.Resolver.ProductClient.CreateProduct(ctx, productclient.ToProtoProduct(product))
@@ -70,56 +75,335 @@ func (r *mutationResolver) CreateProduct(ctx context.Context, input model.Produc
 }
 
 
-func (r *mutationResolver) UpdateProduct(ctx context.Context, id string, input model.ProductInput) (string, error) {
-	product := &model.Product{
-		Title:       input.Title,
-		Description: input.Description,
-		Price:       input.Price,
-		Stock:       input.Stock,
-		Category:    input.Category,
-		Attributes: &model.ProductAttributes{
-			Color: input.Attributes.Color,
-			Size:  input.Attributes.Size,
-		},
-		Images:  input.Images,
-		Ratings: input.Ratings,
+func (r *mutationResolver) UpdateProduct(ctx context.Context, id string, input model.UpdateProductInput, version int32) (string, error) {
+	product, mask := productclient.ToProtoProductUpdate(input, version)
+	if len(mask.Paths) == 0 {
+		return "", fmt.Errorf("failed to update product: no fields to update")
 	}
-	err := r.Resolver.ProductClient.UpdateProduct(ctx, id, productclient.ToProtoProduct(product))
+	err := r.Resolver.ProductClient.UpdateProduct(ctx, id, product, mask)
 	if err != nil {
 		log.Printf("Error updating product: %v", err)
-		return "", fmt.Errorf("failed to update product: %w", err)
+		return "", r.productWriteError(ctx, id, "update product", err)
 	}
 	return "Product updated successfully", nil
 }
 
-func (r *mutationResolver) DeleteProduct(ctx context.Context, id string) (string, error) {
-	err := r.Resolver.ProductClient.DeleteProduct(ctx, id)
+func (r *mutationResolver) DeleteProduct(ctx context.Context, id string, version int32) (string, error) {
+	err := r.Resolver.ProductClient.DeleteProduct(ctx, id, int64(version))
 	if err != nil {
 		log.Printf("Error deleting product: %v", err)
-		return "", fmt.Errorf("failed to delete product: %w", err)
+		return "", r.productWriteError(ctx, id, "delete product", err)
 	}
-	return "Product deleted successfully", nil
+	return "Product archived successfully", nil
+}
+
+// RestoreProduct is the resolver for the restoreProduct mutation.
+func (r *mutationResolver) RestoreProduct(ctx context.Context, id string) (*model.Product, error) {
+	protoProduct, err := r.Resolver.ProductClient.RestoreProduct(ctx, id)
+	if err != nil {
+		log.Printf("Error restoring product: %v", err)
+		return nil, fmt.Errorf("failed to restore product: %w", err)
+	}
+	return productclient.FromProtoProduct(protoProduct), nil
 }
 
 
-func (r *mutationResolver) UpdateStock(ctx context.Context, id string, quantity int32) (string, error) {
-	err := r.Resolver.ProductClient.UpdateStock(ctx, id, quantity)
+func (r *mutationResolver) UpdateStock(ctx context.Context, id string, quantity int32, warehouseID *string, version int32) (string, error) {
+	err := r.Resolver.ProductClient.UpdateStock(ctx, id, quantity, derefString(warehouseID), authenticate.CurrentUserEmail(ctx), int64(version))
 	if err != nil {
 		log.Printf("Error updating stock: %v", err)
-		return "", fmt.Errorf("failed to update stock: %w", err)
+		return "", r.productWriteError(ctx, id, "update stock", err)
 	}
 	return "Stock updated successfully", nil
 }
 
+// CreateVariant is the resolver for the createVariant mutation.
+func (r *mutationResolver) CreateVariant(ctx context.Context, productID string, input model.VariantInput) (string, error) {
+	id, err := r.Resolver.ProductClient.CreateVariant(ctx, productclient.ToProtoVariant(productID, input))
+	if err != nil {
+		log.Printf("Error creating variant: %v", err)
+		return "", fmt.Errorf("failed to create variant: %w", err)
+	}
+	return id, nil
+}
+
+// UpdateVariant is the resolver for the updateVariant mutation.
+func (r *mutationResolver) UpdateVariant(ctx context.Context, id string, input model.VariantInput) (string, error) {
+	err := r.Resolver.ProductClient.UpdateVariant(ctx, id, productclient.ToProtoVariant("", input))
+	if err != nil {
+		log.Printf("Error updating variant: %v", err)
+		return "", fmt.Errorf("failed to update variant: %w", err)
+	}
+	return "Variant updated successfully", nil
+}
+
+// DeleteVariant is the resolver for the deleteVariant mutation.
+func (r *mutationResolver) DeleteVariant(ctx context.Context, id string) (string, error) {
+	err := r.Resolver.ProductClient.DeleteVariant(ctx, id)
+	if err != nil {
+		log.Printf("Error deleting variant: %v", err)
+		return "", fmt.Errorf("failed to delete variant: %w", err)
+	}
+	return "Variant deleted successfully", nil
+}
+
+// UpdateVariantStock is the resolver for the updateVariantStock mutation.
+func (r *mutationResolver) UpdateVariantStock(ctx context.Context, id string, quantity int32, warehouseID *string) (string, error) {
+	err := r.Resolver.ProductClient.UpdateVariantStock(ctx, id, quantity, derefString(warehouseID), authenticate.CurrentUserEmail(ctx))
+	if err != nil {
+		log.Printf("Error updating variant stock: %v", err)
+		return "", fmt.Errorf("failed to update variant stock: %w", err)
+	}
+	return "Variant stock updated successfully", nil
+}
+
+// CreateWarehouse is the resolver for the createWarehouse mutation.
+func (r *mutationResolver) CreateWarehouse(ctx context.Context, input model.WarehouseInput) (string, error) {
+	id, err := r.Resolver.ProductClient.CreateWarehouse(ctx, productclient.ToProtoWarehouse(input))
+	if err != nil {
+		log.Printf("Error creating warehouse: %v", err)
+		return "", fmt.Errorf("failed to create warehouse: %w", err)
+	}
+	return id, nil
+}
+
+// UpdateWarehouse is the resolver for the updateWarehouse mutation.
+func (r *mutationResolver) UpdateWarehouse(ctx context.Context, id string, input model.WarehouseInput) (string, error) {
+	err := r.Resolver.ProductClient.UpdateWarehouse(ctx, id, productclient.ToProtoWarehouse(input))
+	if err != nil {
+		log.Printf("Error updating warehouse: %v", err)
+		return "", fmt.Errorf("failed to update warehouse: %w", err)
+	}
+	return "Warehouse updated successfully", nil
+}
+
+// CreateCategory is the resolver for the createCategory mutation.
+func (r *mutationResolver) CreateCategory(ctx context.Context, input model.CategoryInput) (string, error) {
+	id, err := r.Resolver.ProductClient.CreateCategory(ctx, productclient.ToProtoCategory(input))
+	if err != nil {
+		log.Printf("Error creating category: %v", err)
+		return "", fmt.Errorf("failed to create category: %w", err)
+	}
+	return id, nil
+}
+
+// UpdateCategory is the resolver for the updateCategory mutation.
+func (r *mutationResolver) UpdateCategory(ctx context.Context, id string, input model.CategoryInput) (string, error) {
+	err := r.Resolver.ProductClient.UpdateCategory(ctx, id, productclient.ToProtoCategory(input))
+	if err != nil {
+		log.Printf("Error updating category: %v", err)
+		return "", fmt.Errorf("failed to update category: %w", err)
+	}
+	return "Category updated successfully", nil
+}
+
+// DeleteCategory is the resolver for the deleteCategory mutation.
+func (r *mutationResolver) DeleteCategory(ctx context.Context, id string) (string, error) {
+	err := r.Resolver.ProductClient.DeleteCategory(ctx, id)
+	if err != nil {
+		log.Printf("Error deleting category: %v", err)
+		return "", fmt.Errorf("failed to delete category: %w", err)
+	}
+	return "Category deleted successfully", nil
+}
+
+// CreateReview is the resolver for the createReview mutation.
+func (r *mutationResolver) CreateReview(ctx context.Context, input model.ReviewInput) (string, error) {
+	email := authenticate.CurrentUserEmail(ctx)
+	if email == "" {
+		return "", fmt.Errorf("failed to create review: not logged in")
+	}
+	id, err := r.Resolver.ProductClient.CreateReview(ctx, productclient.ToProtoCreateReview(input, email))
+	if err != nil {
+		log.Printf("Error creating review: %v", err)
+		return "", fmt.Errorf("failed to create review: %w", err)
+	}
+	return id, nil
+}
+
+// EditReview is the resolver for the editReview mutation.
+func (r *mutationResolver) EditReview(ctx context.Context, id string, input model.ReviewEditInput) (string, error) {
+	email := authenticate.CurrentUserEmail(ctx)
+	if email == "" {
+		return "", fmt.Errorf("failed to edit review: not logged in")
+	}
+	err := r.Resolver.ProductClient.EditReview(ctx, productclient.ToProtoEditReview(id, email, input))
+	if err != nil {
+		log.Printf("Error editing review: %v", err)
+		return "", fmt.Errorf("failed to edit review: %w", err)
+	}
+	return "Review updated successfully", nil
+}
+
+// ModerateReview is the resolver for the moderateReview mutation.
+func (r *mutationResolver) ModerateReview(ctx context.Context, id string, status model.ReviewStatus) (string, error) {
+	err := r.Resolver.ProductClient.ModerateReview(ctx, id, productclient.ToProtoReviewStatus(&status))
+	if err != nil {
+		log.Printf("Error moderating review: %v", err)
+		return "", fmt.Errorf("failed to moderate review: %w", err)
+	}
+	return "Review moderated successfully", nil
+}
+
+// DeleteReview is the resolver for the deleteReview mutation.
+// Admins delete any review as moderators; other users only their own.
+func (r *mutationResolver) DeleteReview(ctx context.Context, id string) (string, error) {
+	email := authenticate.CurrentUserEmail(ctx)
+	if email == "" {
+		return "", fmt.Errorf("failed to delete review: not logged in")
+	}
+	if authenticate.CurrentUserRole(ctx) == "admin" {
+		email = ""
+	}
+	err := r.Resolver.ProductClient.DeleteReview(ctx, id, email)
+	if err != nil {
+		log.Printf("Error deleting review: %v", err)
+		return "", fmt.Errorf("failed to delete review: %w", err)
+	}
+	return "Review deleted successfully", nil
+}
+
+// VoteReviewHelpful is the resolver for the voteReviewHelpful mutation.
+func (r *mutationResolver) VoteReviewHelpful(ctx context.Context, id string) (string, error) {
+	email := authenticate.CurrentUserEmail(ctx)
+	if email == "" {
+		return "", fmt.Errorf("failed to vote for review: not logged in")
+	}
+	err := r.Resolver.ProductClient.VoteReviewHelpful(ctx, id, email)
+	if err != nil {
+		log.Printf("Error voting for review: %v", err)
+		return "", fmt.Errorf("failed to vote for review: %w", err)
+	}
+	return "Vote recorded successfully", nil
+}
+
+// CreatePriceList is the resolver for the createPriceList mutation.
+func (r *mutationResolver) CreatePriceList(ctx context.Context, input model.PriceListInput) (string, error) {
+	id, err := r.Resolver.ProductClient.CreatePriceList(ctx, productclient.ToProtoPriceList(input))
+	if err != nil {
+		log.Printf("Error creating price list: %v", err)
+		return "", fmt.Errorf("failed to create price list: %w", err)
+	}
+	return id, nil
+}
+
+// UpdatePriceList is the resolver for the updatePriceList mutation.
+func (r *mutationResolver) UpdatePriceList(ctx context.Context, id string, input model.PriceListInput) (string, error) {
+	err := r.Resolver.ProductClient.UpdatePriceList(ctx, id, productclient.ToProtoPriceList(input))
+	if err != nil {
+		log.Printf("Error updating price list: %v", err)
+		return "", fmt.Errorf("failed to update price list: %w", err)
+	}
+	return "Price list updated successfully", nil
+}
+
+// DeletePriceList is the resolver for the deletePriceList mutation.
+func (r *mutationResolver) DeletePriceList(ctx context.Context, id string) (string, error) {
+	err := r.Resolver.ProductClient.DeletePriceList(ctx, id)
+	if err != nil {
+		log.Printf("Error deleting price list: %v", err)
+		return "", fmt.Errorf("failed to delete price list: %w", err)
+	}
+	return "Price list deleted successfully", nil
+}
+
+// SetExchangeRate is the resolver for the setExchangeRate mutation.
+func (r *mutationResolver) SetExchangeRate(ctx context.Context, input model.ExchangeRateInput) (*model.ExchangeRate, error) {
+	rate, err := r.Resolver.ProductClient.SetExchangeRate(ctx, productclient.ToProtoExchangeRate(input))
+	if err != nil {
+		log.Printf("Error setting exchange rate: %v", err)
+		return nil, fmt.Errorf("failed to set exchange rate: %w", err)
+	}
+	return productclient.FromProtoExchangeRate(rate), nil
+}
+
+// DeleteExchangeRate is the resolver for the deleteExchangeRate mutation.
+func (r *mutationResolver) DeleteExchangeRate(ctx context.Context, currency string) (string, error) {
+	err := r.Resolver.ProductClient.DeleteExchangeRate(ctx, currency)
+	if err != nil {
+		log.Printf("Error deleting exchange rate: %v", err)
+		return "", fmt.Errorf("failed to delete exchange rate: %w", err)
+	}
+	return "Exchange rate deleted successfully", nil
+}
+
+// SetProductTranslation is the resolver for the setProductTranslation mutation.
+func (r *mutationResolver) SetProductTranslation(ctx context.Context, productID string, locale string, input model.ProductTranslationInput, version int32) (*model.Product, error) {
+	protoProduct, err := r.Resolver.ProductClient.SetProductTranslation(ctx, productID, locale, productclient.ToProtoTranslation(input), int64(version))
+	if err != nil {
+		log.Printf("Error setting product translation: %v", err)
+		return nil, r.productWriteError(ctx, productID, "set product translation", err)
+	}
+	return productclient.FromProtoProduct(protoProduct), nil
+}
+
+// DeleteProductTranslation is the resolver for the deleteProductTranslation mutation.
+func (r *mutationResolver) DeleteProductTranslation(ctx context.Context, productID string, locale string, version int32) (*model.Product, error) {
+	protoProduct, err := r.Resolver.ProductClient.DeleteProductTranslation(ctx, productID, locale, int64(version))
+	if err != nil {
+		log.Printf("Error deleting product translation: %v", err)
+		return nil, r.productWriteError(ctx, productID, "delete product translation", err)
+	}
+	return productclient.FromProtoProduct(protoProduct), nil
+}
+
+// UploadProductImage is the resolver for the uploadProductImage mutation.
+func (r *mutationResolver) UploadProductImage(ctx context.Context, productID string, file graphql.Upload, altText *string) (*model.ProductImage, error) {
+	protoImage, err := r.Resolver.ProductClient.UploadProductImage(ctx, productID, derefString(altText), file.File)
+	if err != nil {
+		log.Printf("Error uploading product image: %v", err)
+		return nil, fmt.Errorf("failed to upload product image: %w", err)
+	}
+	return productclient.FromProtoProductImage(protoImage), nil
+}
+
+// UpdateProductImage is the resolver for the updateProductImage mutation.
+func (r *mutationResolver) UpdateProductImage(ctx context.Context, id string, altText *string, position *int32) (*model.ProductImage, error) {
+	protoImage, err := r.Resolver.ProductClient.UpdateProductImage(ctx, id, altText, position)
+	if err != nil {
+		log.Printf("Error updating product image: %v", err)
+		return nil, fmt.Errorf("failed to update product image: %w", err)
+	}
+	return productclient.FromProtoProductImage(protoImage), nil
+}
+
+// DeleteProductImage is the resolver for the deleteProductImage mutation.
+func (r *mutationResolver) DeleteProductImage(ctx context.Context, id string) (string, error) {
+	if err := r.Resolver.ProductClient.DeleteProductImage(ctx, id); err != nil {
+		log.Printf("Error deleting product image: %v", err)
+		return "", fmt.Errorf("failed to delete product image: %w", err)
+	}
+	return "Image deleted successfully", nil
+}
+
+// SetPreferredCurrency is the resolver for the setPreferredCurrency mutation.
+func (r *mutationResolver) SetPreferredCurrency(ctx context.Context, currency *string) (*model.User, error) {
+	email := authenticate.CurrentUserEmail(ctx)
+	if email == "" {
+		return nil, fmt.Errorf("failed to set preferred currency: not logged in")
+	}
+	resp, err := r.Resolver.UserClient.UpdatePreferences(ctx, email, derefString(currency))
+	if err != nil {
+		log.Printf("Error setting preferred currency: %v", err)
+		return nil, fmt.Errorf("failed to set preferred currency: %w", err)
+	}
+	return &model.User{
+		ID:                &resp.User.Id,
+		Name:              &resp.User.Name,
+		Email:             &resp.User.Email,
+		Role:              &resp.User.Role,
+		PreferredCurrency: optionalString(resp.User.PreferredCurrency),
+	}, nil
+}
+
 
 func (r *mutationResolver) CreateOrder(ctx context.Context, input model.OrderInput) (string, error) {
 	orderItems := []*pb.OrderItem{}
 	for _, item := range input.Items {
 		orderItems = append(orderItems, &pb.OrderItem{
 			ProductId: item.ProductID,
-			ProductPrice: float32(item.ProductPrice),
+			VariantId: derefString(item.VariantID),
 			Quantity:     uint32(item.Quantity),
-			TotalPrice:   float32(item.TotalPrice),
 		})
 	}
 
@@ -127,13 +411,16 @@ func (r *mutationResolver) CreateOrder(ctx context.Context, input model.OrderInp
 	for _, item := range orderItems {
 		protoOrderItems = append(protoOrderItems, &pb.OrderItem{
 			ProductId:    item.ProductId,
-			ProductPrice: float32(item.ProductPrice),
+			VariantId:    item.VariantId,
 			Quantity:     uint32(item.Quantity),
-			TotalPrice:   item.TotalPrice,
 		})
 	}
+	var shippingLocation *pb.Location
+	if input.ShippingLocation != nil {
+		shippingLocation = &pb.Location{Latitude: input.ShippingLocation.Latitude, Longitude: input.ShippingLocation.Longitude}
+	}
 	userID := input.UserID
-	resp, err := r.Resolver.OrderClient.CreateOrder(ctx, userID, protoOrderItems, input.ShippingAddress, input.BillingAddress)
+	resp, err := r.Resolver.OrderClient.CreateOrder(ctx, userID, protoOrderItems, input.ShippingAddress, shippingLocation, input.BillingAddress, preferences.Currency(ctx))
 	if err != nil {
 		log.Printf("Error creating order: %v", err)
 		return "", fmt.Errorf("failed to create order: %w", err)
@@ -185,6 +472,98 @@ func (r *mutationResolver) DeleteOrder(ctx context.Context, orderID string) (str
 	return resp.Message, nil
 }
 
+// Media is the resolver for the media field.
+func (r *productResolver) Media(ctx context.Context, obj *model.Product) ([]*model.ProductImage, error) {
+	protoImages, err := r.Resolver.ProductClient.ListProductImages(ctx, obj.ID)
+	if err != nil {
+		log.Printf("Error listing product images: %v", err)
+		return nil, fmt.Errorf("failed to list product images: %w", err)
+	}
+	images := []*model.ProductImage{}
+	for _, protoImage := range protoImages {
+		images = append(images, productclient.FromProtoProductImage(protoImage))
+	}
+	return images, nil
+}
+
+// Variants is the resolver for the variants field.
+func (r *productResolver) Variants(ctx context.Context, obj *model.Product) ([]*model.Variant, error) {
+	protoVariants, err := r.Resolver.ProductClient.ListVariants(ctx, obj.ID)
+	if err != nil {
+		log.Printf("Error listing variants: %v", err)
+		return nil, fmt.Errorf("failed to list variants: %w", err)
+	}
+	variants := []*model.Variant{}
+	for _, protoVariant := range protoVariants {
+		variants = append(variants, productclient.FromProtoVariant(protoVariant))
+	}
+	return variants, nil
+}
+
+// StockLevels is the resolver for the stockLevels field.
+func (r *productResolver) StockLevels(ctx context.Context, obj *model.Product) ([]*model.StockLevel, error) {
+	protoLevels, err := r.Resolver.ProductClient.GetStockLevels(ctx, obj.ID)
+	if err != nil {
+		log.Printf("Error fetching stock levels: %v", err)
+		return nil, fmt.Errorf("failed to fetch stock levels: %w", err)
+	}
+	protoWarehouses, err := r.Resolver.ProductClient.ListWarehouses(ctx)
+	if err != nil {
+		log.Printf("Error listing warehouses: %v", err)
+		return nil, fmt.Errorf("failed to list warehouses: %w", err)
+	}
+	warehouses := make(map[string]*model.Warehouse, len(protoWarehouses))
+	for _, protoWarehouse := range protoWarehouses {
+		warehouses[protoWarehouse.Id] = productclient.FromProtoWarehouse(protoWarehouse)
+	}
+
+	levels := []*model.StockLevel{}
+	for _, protoLevel := range protoLevels {
+		warehouse, ok := warehouses[protoLevel.WarehouseId]
+		if !ok {
+			warehouse = &model.Warehouse{ID: protoLevel.WarehouseId}
+		}
+		levels = append(levels, &model.StockLevel{
+			Warehouse: warehouse,
+			VariantID: optionalString(protoLevel.VariantId),
+			Quantity:  protoLevel.Quantity,
+		})
+	}
+	return levels, nil
+}
+
+// Reviews is the resolver for the reviews field.
+func (r *productResolver) Reviews(ctx context.Context, obj *model.Product, limit *int32, offset *int32) ([]*model.Review, error) {
+	approved := model.ReviewStatusApproved
+	protoReviews, err := r.Resolver.ProductClient.ListReviews(ctx, obj.ID, productclient.ToProtoReviewStatus(&approved), derefInt32(limit), derefInt32(offset))
+	if err != nil {
+		log.Printf("Error listing reviews: %v", err)
+		return nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+	reviews := []*model.Review{}
+	for _, protoReview := range protoReviews {
+		reviews = append(reviews, productclient.FromProtoReview(protoReview))
+	}
+	return reviews, nil
+}
+
+// Breadcrumbs is the resolver for the breadcrumbs field.
+func (r *productResolver) Breadcrumbs(ctx context.Context, obj *model.Product) ([]*model.Category, error) {
+	if obj.CategoryID == nil {
+		return []*model.Category{}, nil
+	}
+	resp, err := r.Resolver.ProductClient.GetCategory(ctx, *obj.CategoryID)
+	if err != nil {
+		log.Printf("Error fetching category: %v", err)
+		return nil, fmt.Errorf("failed to fetch category: %w", err)
+	}
+	breadcrumbs := []*model.Category{}
+	for _, protoCategory := range resp.Breadcrumbs {
+		breadcrumbs = append(breadcrumbs, productclient.FromProtoCategory(protoCategory))
+	}
+	return breadcrumbs, nil
+}
+
 
 func (r *queryResolver) GetUser(ctx context.Context, username string) (*model.User, error) {
 	resp, err := r.Resolver.UserClient.GetUser(ctx, username)
@@ -198,6 +577,7 @@ func (r *queryResolver) GetUser(ctx context.Context, username string) (*model.Us
 		Email:    &resp.User.Email,
 		Password: &resp.User.Password,
 		Role:     &resp.User.Role,
+		PreferredCurrency: optionalString(resp.User.PreferredCurrency),
 	}, nil
 }
 
@@ -216,6 +596,7 @@ func (r *queryResolver) GetAllUsers(ctx context.Context) ([]*model.User, error)
 			Email:    &u.Email,
 			Password: &u.Password,
 			Role:     &u.Role,
+			PreferredCurrency: optionalString(u.PreferredCurrency),
 		})
 	}
 	return users, nil
@@ -231,12 +612,12 @@ func (r *queryResolver) GetProductByID(ctx context.Context, id string) (*model.P
 }
 
 
-func (r *queryResolver) GetAllProducts(ctx context.Context, filters []*model.FilterInput) ([]*model.Product, error) {
+func (r *queryResolver) GetAllProducts(ctx context.Context, filters []*model.FilterInput, filter *model.ProductFilterInput, sort []*model.ProductSortInput, includeArchived *bool) ([]*model.Product, error) {
 	filterMap := make(map[string]string)
 	for _, filter := range filters {
 		filterMap[filter.Key] = filter.Value
 	}
-	protoProducts, err := r.Resolver.ProductClient.GetAllProducts(ctx, filterMap)
+	protoProducts, err := r.Resolver.ProductClient.GetAllProducts(ctx, filterMap, productclient.ToProtoFilter(filter), productclient.ToProtoSort(sort), includeArchived != nil && *includeArchived)
 	if err != nil {
 		log.Printf("Error fetching all products: %v", err)
 		return nil, fmt.Errorf("failed to fetch all products: %w", err)
@@ -249,8 +630,8 @@ func (r *queryResolver) GetAllProducts(ctx context.Context, filters []*model.Fil
 }
 
 // GetProductsByCategory is the resolver for the getProductsByCategory query.
-func (r *queryResolver) GetProductsByCategory(ctx context.Context, category string) ([]*model.Product, error) {
-	protoProducts, err := r.Resolver.ProductClient.GetProductsByCategory(ctx, category)
+func (r *queryResolver) GetProductsByCategory(ctx context.Context, category string, sort []*model.ProductSortInput) ([]*model.Product, error) {
+	protoProducts, err := r.Resolver.ProductClient.GetProductsByCategory(ctx, category, productclient.ToProtoSort(sort))
 	if err != nil {
 		log.Printf("Error fetching products by category: %v", err)
 		return nil, fmt.Errorf("failed to fetch products by category: %w", err)
@@ -262,20 +643,197 @@ func (r *queryResolver) GetProductsByCategory(ctx context.Context, category stri
 	return products, nil
 }
 
+// ProductsInCategory is the resolver for the productsInCategory query.
+func (r *queryResolver) ProductsInCategory(ctx context.Context, categoryID string, includeDescendants *bool, sort []*model.ProductSortInput) ([]*model.Product, error) {
+	descendants := includeDescendants == nil || *includeDescendants
+	protoProducts, err := r.Resolver.ProductClient.GetProductsInCategory(ctx, categoryID, descendants, productclient.ToProtoSort(sort))
+	if err != nil {
+		log.Printf("Error fetching products in category: %v", err)
+		return nil, fmt.Errorf("failed to fetch products in category: %w", err)
+	}
+	products := []*model.Product{}
+	for _, protoProduct := range protoProducts {
+		products = append(products, productclient.FromProtoProduct(protoProduct))
+	}
+	return products, nil
+}
+
 // SearchProducts is the resolver for the searchProducts query.
-func (r *queryResolver) SearchProducts(ctx context.Context, query string) ([]*model.Product, error) {
-	protoProducts, err := r.Resolver.ProductClient.SearchProducts(ctx, query)
+func (r *queryResolver) SearchProducts(ctx context.Context, query string, sort []*model.ProductSortInput, limit *int32, offset *int32) ([]*model.Product, error) {
+	resp, err := r.Resolver.ProductClient.SearchProducts(ctx, query, productclient.ToProtoSort(sort), derefInt32(limit), derefInt32(offset))
 	if err != nil {
 		log.Printf("Error searching products: %v", err)
 		return nil, fmt.Errorf("failed to search products: %w", err)
 	}
 	var products []*model.Product
-	for _, protoProduct := range protoProducts {
+	for _, protoProduct := range resp.Products {
 		products = append(products, productclient.FromProtoProduct(protoProduct))
 	}
 	return products, nil
 }
 
+// ProductSearch is the resolver for the productSearch query.
+func (r *queryResolver) ProductSearch(ctx context.Context, query string, sort []*model.ProductSortInput, limit *int32, offset *int32) (*model.ProductSearchResult, error) {
+	resp, err := r.Resolver.ProductClient.SearchProducts(ctx, query, productclient.ToProtoSort(sort), derefInt32(limit), derefInt32(offset))
+	if err != nil {
+		log.Printf("Error searching products: %v", err)
+		return nil, fmt.Errorf("failed to search products: %w", err)
+	}
+	result := &model.ProductSearchResult{
+		Products: []*model.Product{},
+		Total:    resp.Total,
+		Facets:   productclient.FromProtoSearchFacets(resp.Facets),
+	}
+	for _, protoProduct := range resp.Products {
+		result.Products = append(result.Products, productclient.FromProtoProduct(protoProduct))
+	}
+	return result, nil
+}
+
+// ProductSuggestions is the resolver for the productSuggestions query.
+func (r *queryResolver) ProductSuggestions(ctx context.Context, prefix string, limit *int32) ([]*model.ProductSuggestion, error) {
+	protoSuggestions, err := r.Resolver.ProductClient.SuggestProducts(ctx, prefix, derefInt32(limit))
+	if err != nil {
+		log.Printf("Error fetching product suggestions: %v", err)
+		return nil, fmt.Errorf("failed to fetch product suggestions: %w", err)
+	}
+	suggestions := []*model.ProductSuggestion{}
+	for _, protoSuggestion := range protoSuggestions {
+		suggestions = append(suggestions, productclient.FromProtoSuggestion(protoSuggestion))
+	}
+	return suggestions, nil
+}
+
+// GetVariant is the resolver for the getVariant query.
+func (r *queryResolver) GetVariant(ctx context.Context, id string) (*model.Variant, error) {
+	protoVariant, err := r.Resolver.ProductClient.GetVariant(ctx, id)
+	if err != nil {
+		log.Printf("Error fetching variant: %v", err)
+		return nil, fmt.Errorf("failed to fetch variant: %w", err)
+	}
+	return productclient.FromProtoVariant(protoVariant), nil
+}
+
+// Warehouses is the resolver for the warehouses query.
+func (r *queryResolver) Warehouses(ctx context.Context) ([]*model.Warehouse, error) {
+	protoWarehouses, err := r.Resolver.ProductClient.ListWarehouses(ctx)
+	if err != nil {
+		log.Printf("Error listing warehouses: %v", err)
+		return nil, fmt.Errorf("failed to list warehouses: %w", err)
+	}
+	warehouses := []*model.Warehouse{}
+	for _, protoWarehouse := range protoWarehouses {
+		warehouses = append(warehouses, productclient.FromProtoWarehouse(protoWarehouse))
+	}
+	return warehouses, nil
+}
+
+// GetReview is the resolver for the getReview query.
+func (r *queryResolver) GetReview(ctx context.Context, id string) (*model.Review, error) {
+	protoReview, err := r.Resolver.ProductClient.GetReview(ctx, id)
+	if err != nil {
+		log.Printf("Error fetching review: %v", err)
+		return nil, fmt.Errorf("failed to fetch review: %w", err)
+	}
+	return productclient.FromProtoReview(protoReview), nil
+}
+
+// Reviews is the resolver for the reviews query.
+func (r *queryResolver) Reviews(ctx context.Context, productID *string, status *model.ReviewStatus, limit *int32, offset *int32) ([]*model.Review, error) {
+	protoReviews, err := r.Resolver.ProductClient.ListReviews(ctx, derefString(productID), productclient.ToProtoReviewStatus(status), derefInt32(limit), derefInt32(offset))
+	if err != nil {
+		log.Printf("Error listing reviews: %v", err)
+		return nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+	reviews := []*model.Review{}
+	for _, protoReview := range protoReviews {
+		reviews = append(reviews, productclient.FromProtoReview(protoReview))
+	}
+	return reviews, nil
+}
+
+// CategoryTree is the resolver for the categoryTree query.
+func (r *queryResolver) CategoryTree(ctx context.Context, rootID *string) ([]*model.Category, error) {
+	protoNodes, err := r.Resolver.ProductClient.GetCategoryTree(ctx, derefString(rootID))
+	if err != nil {
+		log.Printf("Error fetching category tree: %v", err)
+		return nil, fmt.Errorf("failed to fetch category tree: %w", err)
+	}
+	return productclient.FromProtoCategoryNodes(protoNodes), nil
+}
+
+// CategoryBreadcrumbs is the resolver for the categoryBreadcrumbs query.
+func (r *queryResolver) CategoryBreadcrumbs(ctx context.Context, id string) ([]*model.Category, error) {
+	resp, err := r.Resolver.ProductClient.GetCategory(ctx, id)
+	if err != nil {
+		log.Printf("Error fetching category: %v", err)
+		return nil, fmt.Errorf("failed to fetch category: %w", err)
+	}
+	breadcrumbs := []*model.Category{}
+	for _, protoCategory := range resp.Breadcrumbs {
+		breadcrumbs = append(breadcrumbs, productclient.FromProtoCategory(protoCategory))
+	}
+	return breadcrumbs, nil
+}
+
+// PriceLists is the resolver for the priceLists query.
+func (r *queryResolver) PriceLists(ctx context.Context) ([]*model.PriceList, error) {
+	protoLists, err := r.Resolver.ProductClient.ListPriceLists(ctx)
+	if err != nil {
+		log.Printf("Error listing price lists: %v", err)
+		return nil, fmt.Errorf("failed to list price lists: %w", err)
+	}
+	lists := []*model.PriceList{}
+	for _, protoList := range protoLists {
+		lists = append(lists, productclient.FromProtoPriceList(protoList))
+	}
+	return lists, nil
+}
+
+// GetPriceList is the resolver for the getPriceList query.
+func (r *queryResolver) GetPriceList(ctx context.Context, id string) (*model.PriceList, error) {
+	protoList, err := r.Resolver.ProductClient.GetPriceList(ctx, id)
+	if err != nil {
+		log.Printf("Error fetching price list: %v", err)
+		return nil, fmt.Errorf("failed to fetch price list: %w", err)
+	}
+	return productclient.FromProtoPriceList(protoList), nil
+}
+
+// PriceHistory is the resolver for the priceHistory query.
+func (r *queryResolver) PriceHistory(ctx context.Context, productID string, pageSize *int32, pageToken *string) (*model.PriceHistoryPage, error) {
+	resp, err := r.Resolver.ProductClient.GetPriceHistory(ctx, productID, derefString(pageToken), derefInt32(pageSize))
+	if err != nil {
+		log.Printf("Error fetching price history: %v", err)
+		return nil, fmt.Errorf("failed to fetch price history: %w", err)
+	}
+	return productclient.FromProtoPriceHistory(resp), nil
+}
+
+// ExchangeRates is the resolver for the exchangeRates query.
+func (r *queryResolver) ExchangeRates(ctx context.Context) ([]*model.ExchangeRate, error) {
+	protoRates, err := r.Resolver.ProductClient.ListExchangeRates(ctx)
+	if err != nil {
+		log.Printf("Error listing exchange rates: %v", err)
+		return nil, fmt.Errorf("failed to list exchange rates: %w", err)
+	}
+	rates := []*model.ExchangeRate{}
+	for _, protoRate := range protoRates {
+		rates = append(rates, productclient.FromProtoExchangeRate(protoRate))
+	}
+	return rates, nil
+}
+
+// FindVariant is the resolver for the findVariant query.
+func (r *queryResolver) FindVariant(ctx context.Context, productID string, options []*model.VariantOptionInput) (*model.Variant, error) {
+	protoVariants, err := r.Resolver.ProductClient.ListVariants(ctx, productID)
+	if err != nil {
+		log.Printf("Error listing variants: %v", err)
+		return nil, fmt.Errorf("failed to list variants: %w", err)
+	}
+	return productclient.FromProtoVariant(productclient.MatchVariant(protoVariants, options)), nil
+}
+
 // GetOrderByID is the resolver for the getOrderByID query.
 func (r *queryResolver) GetOrderByID(ctx context.Context, orderID string) (*model.Order, error) {
 	orderIDUint, err := strconv.ParseUint(orderID, 10, 32)
@@ -293,19 +851,22 @@ func (r *queryResolver) GetOrderByID(ctx context.Context, orderID string) (*mode
 	for _, item := range order.Items {
 		orderItems = append(orderItems, &model.OrderItem{
 			ID:          fmt.Sprintf("%d", item.Id),
-			ProductID:   fmt.Sprintf("%d", item.ProductId),
-			ProductPrice: float64(item.ProductPrice),
+			ProductID:   item.ProductId,
+			VariantID:   optionalString(item.VariantId),
+			ProductPrice: orderclient.FromProtoMoney(item.ProductPrice),
 			Quantity:    int32(item.Quantity),
-			TotalPrice:  float64(item.TotalPrice),
+			TotalPrice:  orderclient.FromProtoMoney(item.TotalPrice),
+			BaseCurrency: optionalString(item.BaseCurrency),
+			ExchangeRate: optionalString(item.ExchangeRate),
 		})
 	}
 
 	return &model.Order{
 		ID:             fmt.Sprintf("%d", order.Id),
-		UserID:         fmt.Sprintf("%d", order.UserId),
+		UserID:         order.UserId,
 		OrderStatus:    order.OrderStatus,
 		PaymentStatus:  order.PaymentStatus,
-		TotalAmount:    float64(order.TotalAmount),
+		TotalAmount:    orderclient.FromProtoMoney(order.TotalAmount),
 		ShippingAddress: order.ShippingAddress,
 		BillingAddress: order.BillingAddress,
 		CreatedAt:      order.CreatedAt,
@@ -328,19 +889,22 @@ func (r *queryResolver) GetAllOrders(ctx context.Context, userID string) ([]*mod
 		for _, item := range order.Items {
 			orderItems = append(orderItems, &model.OrderItem{
 				ID:          fmt.Sprintf("%d", item.Id),
-				ProductID:   fmt.Sprintf("%d", item.ProductId),
-				ProductPrice: float64(item.ProductPrice),
+				ProductID:   item.ProductId,
+			VariantID:   optionalString(item.VariantId),
+				ProductPrice: orderclient.FromProtoMoney(item.ProductPrice),
 				Quantity:    int32(item.Quantity),
-				TotalPrice:  float64(item.TotalPrice),
+				TotalPrice:  orderclient.FromProtoMoney(item.TotalPrice),
+				BaseCurrency: optionalString(item.BaseCurrency),
+				ExchangeRate: optionalString(item.ExchangeRate),
 			})
 		}
 
 		result = append(result, &model.Order{
 			ID:             fmt.Sprintf("%d", order.Id),
-			UserID:         fmt.Sprintf("%d", order.UserId),
+			UserID:         order.UserId,
 			OrderStatus:    order.OrderStatus,
 			PaymentStatus:  order.PaymentStatus,
-			TotalAmount:    float64(order.TotalAmount),
+			TotalAmount:    orderclient.FromProtoMoney(order.TotalAmount),
 			ShippingAddress: order.ShippingAddress,
 			BillingAddress: order.BillingAddress,
 			CreatedAt:      order.CreatedAt,
@@ -354,9 +918,13 @@ func (r *queryResolver) GetAllOrders(ctx context.Context, userID string) ([]*mod
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
+// Product returns ProductResolver implementation.
+func (r *Resolver) Product() ProductResolver { return &productResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
 type mutationResolver struct{ *Resolver }
+type productResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
 
@@ -0,0 +1,32 @@
+package money
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// MarshalMoney writes m as the GraphQL Money scalar, a string of the decimal
+// amount in major units and the currency code, such as "19.99 USD"
+func MarshalMoney(m Money) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		io.WriteString(w, strconv.Quote(m.String()))
+	})
+}
+
+// UnmarshalMoney reads the GraphQL Money scalar, rounding its amount to the
+// currency's minor unit as Parse does
+func UnmarshalMoney(v interface{}) (Money, error) {
+	s, ok := v.(string)
+	if !ok {
+		return Money{}, fmt.Errorf("%w %v: money is a string such as \"19.99 USD\"", ErrInvalidAmount, v)
+	}
+	decimal, currency, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		return Money{}, fmt.Errorf("%w %q: money is a string such as \"19.99 USD\"", ErrInvalidAmount, s)
+	}
+	return Parse(decimal, strings.TrimSpace(currency))
+}
@@ -0,0 +1,110 @@
+// Package money converts between the Money scalar the GraphQL API speaks,
+// such as "19.99 USD", and the whole minor units of a currency the services keep.
+// It follows the rules of product-service's money package: amounts between two
+// minor units are rounded to the nearer one, halves away from zero.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidCurrency = errors.New("invalid currency")
+	ErrInvalidAmount   = errors.New("invalid amount")
+)
+
+// Money is an amount of a currency in its minor units, such as cents
+type Money struct {
+	Amount   int64  `json:"amount"`   // Amount in minor units of the currency
+	Currency string `json:"currency"` // ISO 4217 code
+}
+
+// exponents lists the currencies whose minor unit is not a hundredth
+var exponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+var (
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+	decimalPattern  = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+)
+
+// Exponent is the number of decimal places of the currency's minor unit
+func Exponent(currency string) int {
+	if e, ok := exponents[currency]; ok {
+		return e
+	}
+	return 2
+}
+
+// New returns amount minor units of currency
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse reads a decimal amount in major units, such as "12.34", rounding it
+// to the currency's minor unit
+func Parse(decimal, currency string) (Money, error) {
+	if !currencyPattern.MatchString(currency) {
+		return Money{}, fmt.Errorf("%w %q", ErrInvalidCurrency, currency)
+	}
+	decimal = strings.TrimSpace(decimal)
+	if !decimalPattern.MatchString(decimal) {
+		return Money{}, fmt.Errorf("%w %q", ErrInvalidAmount, decimal)
+	}
+	r, _ := new(big.Rat).SetString(decimal)
+	amount, ok := round(r.Mul(r, scale(currency)))
+	if !ok {
+		return Money{}, fmt.Errorf("%w %q: out of range", ErrInvalidAmount, decimal)
+	}
+	return New(amount, currency), nil
+}
+
+// Decimal formats the amount in major units with the currency's decimal
+// places, such as "12.30"
+func (m Money) Decimal() string {
+	exp := Exponent(m.Currency)
+	if exp == 0 {
+		return strconv.FormatInt(m.Amount, 10)
+	}
+	sign := ""
+	if m.Amount < 0 {
+		sign = "-"
+	}
+	digits := new(big.Int).Abs(big.NewInt(m.Amount)).String()
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// scale is the number of minor units in a major unit of currency
+func scale(currency string) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(Exponent(currency))), nil))
+}
+
+// round rounds r to a whole number, halves away from zero, reporting whether
+// it fits an int64
+func round(r *big.Rat) (int64, bool) {
+	num, den := new(big.Int).Set(r.Num()), r.Denom()
+	// |r| + 1/2, truncated, with the sign of r
+	twice := new(big.Int).Mul(new(big.Int).Abs(num), big.NewInt(2))
+	twice.Add(twice, den)
+	q := new(big.Int).Quo(twice, new(big.Int).Mul(den, big.NewInt(2)))
+	if num.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q.Int64(), q.IsInt64()
+}
@@ -0,0 +1,99 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		decimal  string
+		currency string
+		want     Money
+		err      error
+	}{
+		{"12.34", "USD", New(1234, "USD"), nil},
+		{"12.3", "USD", New(1230, "USD"), nil},
+		{"12", "USD", New(1200, "USD"), nil},
+		{"0.1", "USD", New(10, "USD"), nil},
+		{" 7.50 ", "EUR", New(750, "EUR"), nil},
+		{"12.3400", "USD", New(1234, "USD"), nil},
+		// more places than the currency has are rounded, halves away from zero
+		{"0.125", "USD", New(13, "USD"), nil},
+		{"0.124", "USD", New(12, "USD"), nil},
+		{"-0.125", "USD", New(-13, "USD"), nil},
+		{"1500.5", "JPY", New(1501, "JPY"), nil},
+		{"1.2345", "KWD", New(1235, "KWD"), nil},
+		{"1e3", "USD", Money{}, ErrInvalidAmount},
+		{"1/3", "USD", Money{}, ErrInvalidAmount},
+		{"", "USD", Money{}, ErrInvalidAmount},
+		{"99999999999999999999", "USD", Money{}, ErrInvalidAmount},
+		{"1.00", "usd", Money{}, ErrInvalidCurrency},
+		{"1.00", "", Money{}, ErrInvalidCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.decimal+" "+tt.currency, func(t *testing.T) {
+			got, err := Parse(tt.decimal, tt.currency)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{New(1234, "USD"), "12.34"},
+		{New(5, "USD"), "0.05"},
+		{New(0, "USD"), "0.00"},
+		{New(-5, "EUR"), "-0.05"},
+		{New(1500, "JPY"), "1500"},
+		{New(1, "BHD"), "0.001"},
+		{New(math.MinInt64, "USD"), "-92233720368547758.08"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Decimal(); got != tt.want {
+			t.Errorf("%#v.Decimal() = %q, want %q", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyScalar(t *testing.T) {
+	tests := []struct {
+		input interface{}
+		want  Money
+		err   error
+	}{
+		{"19.99 USD", New(1999, "USD"), nil},
+		{" 0.125 USD ", New(13, "USD"), nil},
+		{"1500 JPY", New(1500, "JPY"), nil},
+		{"19.99", Money{}, ErrInvalidAmount},
+		{"19.99 usd", Money{}, ErrInvalidCurrency},
+		{"USD 19.99", Money{}, ErrInvalidCurrency},
+		{19.99, Money{}, ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		got, err := UnmarshalMoney(tt.input)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("UnmarshalMoney(%#v) error = %v, want %v", tt.input, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("UnmarshalMoney(%#v) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	var b strings.Builder
+	MarshalMoney(New(1230, "EUR")).MarshalGQL(&b)
+	if got := b.String(); got != `"12.30 EUR"` {
+		t.Errorf("MarshalMoney() = %s, want \"12.30 EUR\"", got)
+	}
+}
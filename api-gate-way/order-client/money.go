@@ -0,0 +1,11 @@
+package orderclient
+
+import (
+	"github.com/samObot19/shopverse/api-gate-way/money"
+	pb "github.com/samObot19/shopverse/api-gate-way/order-client/proto/pb"
+)
+
+// FromProtoMoney converts a pb.Money to a money.Money
+func FromProtoMoney(m *pb.Money) money.Money {
+	return money.New(m.GetAmount(), m.GetCurrency())
+}
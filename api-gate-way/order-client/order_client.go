@@ -20,13 +20,17 @@ func NewOrderClient(conn *grpc.ClientConn) *OrderClient {
 	}
 }
 
-// CreateOrder calls the CreateOrder gRPC method
-func (oc *OrderClient) CreateOrder(ctx context.Context, userID string, items []*pb.OrderItem, shippingAddress, billingAddress string) (*pb.CreateOrderResponse, error) {
+// CreateOrder calls the CreateOrder gRPC method; the items are priced in
+// currency, or in the currency products are priced in when it is empty. The
+// shipping location is optional.
+func (oc *OrderClient) CreateOrder(ctx context.Context, userID string, items []*pb.OrderItem, shippingAddress string, shippingLocation *pb.Location, billingAddress, currency string) (*pb.CreateOrderResponse, error) {
 	req := &pb.CreateOrderRequest{
-		UserId:          userID,
-		Items:           items,
-		ShippingAddress: shippingAddress,
-		BillingAddress:  billingAddress,
+		UserId:           userID,
+		Items:            items,
+		ShippingAddress:  shippingAddress,
+		ShippingLocation: shippingLocation,
+		BillingAddress:   billingAddress,
+		Currency:         currency,
 	}
 	resp, err := oc.client.CreateOrder(ctx, req)
 	if (err != nil) {
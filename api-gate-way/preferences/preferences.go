@@ -0,0 +1,148 @@
+// Package preferences carries what a shopper asked for, such as the currency
+// prices are shown in and the languages products are described in, from the
+// HTTP request to the services behind the gateway.
+package preferences
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/samObot19/shopverse/api-gate-way/authenticate"
+	userclient "github.com/samObot19/shopverse/api-gate-way/user-client"
+)
+
+const (
+	// CurrencyHeader names the currency prices are shown in, such as EUR
+	CurrencyHeader = "X-Currency"
+	// currencyMetadataKey is the gRPC metadata product-service reads the currency from
+	currencyMetadataKey = "x-currency"
+	// localeMetadataKey is the gRPC metadata product-service reads the locales from
+	localeMetadataKey = "x-locale"
+	// maxLocales caps the languages of an Accept-Language header that are passed on
+	maxLocales = 8
+)
+
+type currencyKey struct{}
+
+type localesKey struct{}
+
+// Middleware takes the currency from the X-Currency header or, failing that,
+// from the preferences of the logged-in user, and the locales from the
+// Accept-Language header. It must run after authenticate.JWTMiddleware for the
+// user to be known.
+func Middleware(users *userclient.UserClient) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			currency := r.Header.Get(CurrencyHeader)
+			if currency == "" {
+				currency = preferredCurrency(ctx, users)
+			}
+			if currency != "" {
+				ctx = WithCurrency(ctx, currency)
+			}
+			if locales := parseAcceptLanguage(r.Header.Get("Accept-Language")); len(locales) > 0 {
+				ctx = WithLocales(ctx, locales)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// preferredCurrency looks up the currency the logged-in user prefers; a user
+// who cannot be found is shown the catalog's own prices
+func preferredCurrency(ctx context.Context, users *userclient.UserClient) string {
+	email := authenticate.CurrentUserEmail(ctx)
+	if email == "" {
+		return ""
+	}
+	resp, err := users.GetUser(ctx, email)
+	if err != nil {
+		log.Printf("Error fetching preferences of %s: %v", email, err)
+		return ""
+	}
+	return resp.User.GetPreferredCurrency()
+}
+
+// WithCurrency sets the currency prices are shown in
+func WithCurrency(ctx context.Context, currency string) context.Context {
+	return context.WithValue(ctx, currencyKey{}, strings.ToUpper(strings.TrimSpace(currency)))
+}
+
+// Currency returns the currency prices are shown in, or "" for the currency
+// products are priced in
+func Currency(ctx context.Context) string {
+	currency, _ := ctx.Value(currencyKey{}).(string)
+	return currency
+}
+
+// parseAcceptLanguage lists the languages of an Accept-Language header, such
+// as "fr-CA,fr;q=0.9,en;q=0.5", by descending quality; the wildcard and
+// languages of quality zero are left out
+func parseAcceptLanguage(header string) []string {
+	type language struct {
+		tag     string
+		quality float64
+	}
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality <= 0 {
+			continue
+		}
+		languages = append(languages, language{tag: tag, quality: quality})
+	}
+	sort.SliceStable(languages, func(i, j int) bool { return languages[i].quality > languages[j].quality })
+
+	locales := make([]string, 0, min(len(languages), maxLocales))
+	for _, l := range languages {
+		if len(locales) == maxLocales {
+			break
+		}
+		locales = append(locales, l.tag)
+	}
+	return locales
+}
+
+// WithLocales sets the locales products are described in, most preferred first
+func WithLocales(ctx context.Context, locales []string) context.Context {
+	return context.WithValue(ctx, localesKey{}, locales)
+}
+
+// Locales returns the locales products are described in, most preferred
+// first, or nil for their default content
+func Locales(ctx context.Context) []string {
+	locales, _ := ctx.Value(localesKey{}).([]string)
+	return locales
+}
+
+// UnaryClientInterceptor forwards the preferences of the request as gRPC
+// metadata on every call made with its context
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if currency := Currency(ctx); currency != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, currencyMetadataKey, currency)
+	}
+	if locales := Locales(ctx); len(locales) > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, localeMetadataKey, strings.Join(locales, ","))
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
@@ -0,0 +1,118 @@
+package productclient
+
+import (
+	"context"
+	"log"
+
+	"github.com/samObot19/shopverse/api-gate-way/graph/model"
+	pb "github.com/samObot19/shopverse/api-gate-way/product-client/proto/pb"
+)
+
+// CreateCategory calls the CreateCategory gRPC method and returns the new category ID
+func (pc *ProductClient) CreateCategory(ctx context.Context, category *pb.Category) (string, error) {
+	resp, err := pc.client.CreateCategory(ctx, &pb.CreateCategoryRequest{Category: category})
+	if err != nil {
+		log.Printf("Error creating category: %v", err)
+		return "", err
+	}
+	return resp.Id, nil
+}
+
+// GetCategory calls the GetCategory gRPC method
+func (pc *ProductClient) GetCategory(ctx context.Context, id string) (*pb.GetCategoryResponse, error) {
+	resp, err := pc.client.GetCategory(ctx, &pb.GetCategoryRequest{Id: id})
+	if err != nil {
+		log.Printf("Error fetching category: %v", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// UpdateCategory calls the UpdateCategory gRPC method
+func (pc *ProductClient) UpdateCategory(ctx context.Context, id string, category *pb.Category) error {
+	_, err := pc.client.UpdateCategory(ctx, &pb.UpdateCategoryRequest{Id: id, Category: category})
+	if err != nil {
+		log.Printf("Error updating category: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteCategory calls the DeleteCategory gRPC method
+func (pc *ProductClient) DeleteCategory(ctx context.Context, id string) error {
+	_, err := pc.client.DeleteCategory(ctx, &pb.DeleteCategoryRequest{Id: id})
+	if err != nil {
+		log.Printf("Error deleting category: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetCategoryTree calls the GetCategoryTree gRPC method; an empty rootID
+// returns the whole tree
+func (pc *ProductClient) GetCategoryTree(ctx context.Context, rootID string) ([]*pb.CategoryNode, error) {
+	resp, err := pc.client.GetCategoryTree(ctx, &pb.GetCategoryTreeRequest{RootId: rootID})
+	if err != nil {
+		log.Printf("Error fetching category tree: %v", err)
+		return nil, err
+	}
+	return resp.Roots, nil
+}
+
+// GetProductsInCategory calls the GetProductsByCategory gRPC method with a
+// category from the category tree
+func (pc *ProductClient) GetProductsInCategory(ctx context.Context, categoryID string, includeDescendants bool, sort []*pb.SortKey) ([]*pb.Product, error) {
+	resp, err := pc.client.GetProductsByCategory(ctx, &pb.GetProductsByCategoryRequest{
+		CategoryId:         categoryID,
+		IncludeDescendants: includeDescendants,
+		Sort:               sort,
+	})
+	if err != nil {
+		log.Printf("Error fetching products in category: %v", err)
+		return nil, err
+	}
+	return resp.Products, nil
+}
+
+// FromProtoCategory converts a pb.Category to a model.Category without children
+func FromProtoCategory(c *pb.Category) *model.Category {
+	category := &model.Category{
+		ID:        c.Id,
+		Name:      c.Name,
+		Slug:      c.Slug,
+		Position:  c.Position,
+		CreatedAt: c.CreatedAt,
+		Children:  []*model.Category{},
+	}
+	if c.ParentId != "" {
+		category.ParentID = &c.ParentId
+	}
+	return category
+}
+
+// FromProtoCategoryNodes converts category tree nodes to model.Category
+// values with their children filled in
+func FromProtoCategoryNodes(nodes []*pb.CategoryNode) []*model.Category {
+	categories := []*model.Category{}
+	for _, node := range nodes {
+		category := FromProtoCategory(node.Category)
+		category.Children = FromProtoCategoryNodes(node.Children)
+		categories = append(categories, category)
+	}
+	return categories
+}
+
+// ToProtoCategory converts a model.CategoryInput to a pb.Category
+func ToProtoCategory(input model.CategoryInput) *pb.Category {
+	category := &pb.Category{Name: input.Name}
+	if input.Slug != nil {
+		category.Slug = *input.Slug
+	}
+	if input.ParentID != nil {
+		category.ParentId = *input.ParentID
+	}
+	if input.Position != nil {
+		category.Position = *input.Position
+	}
+	return category
+}
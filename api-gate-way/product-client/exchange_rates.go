@@ -0,0 +1,86 @@
+package productclient
+
+import (
+	"context"
+	"log"
+
+	"github.com/samObot19/shopverse/api-gate-way/graph/model"
+	pb "github.com/samObot19/shopverse/api-gate-way/product-client/proto/pb"
+)
+
+var roundingModes = map[model.RoundingMode]pb.RoundingMode{
+	model.RoundingModeNearest: pb.RoundingMode_ROUNDING_MODE_NEAREST,
+	model.RoundingModeUp:      pb.RoundingMode_ROUNDING_MODE_UP,
+	model.RoundingModeDown:    pb.RoundingMode_ROUNDING_MODE_DOWN,
+}
+
+// SetExchangeRate calls the SetExchangeRate gRPC method and returns the rate as saved
+func (pc *ProductClient) SetExchangeRate(ctx context.Context, rate *pb.ExchangeRate) (*pb.ExchangeRate, error) {
+	resp, err := pc.client.SetExchangeRate(ctx, &pb.SetExchangeRateRequest{ExchangeRate: rate})
+	if err != nil {
+		log.Printf("Error setting exchange rate: %v", err)
+		return nil, err
+	}
+	return resp.ExchangeRate, nil
+}
+
+// ListExchangeRates calls the ListExchangeRates gRPC method
+func (pc *ProductClient) ListExchangeRates(ctx context.Context) ([]*pb.ExchangeRate, error) {
+	resp, err := pc.client.ListExchangeRates(ctx, &pb.ListExchangeRatesRequest{})
+	if err != nil {
+		log.Printf("Error listing exchange rates: %v", err)
+		return nil, err
+	}
+	return resp.ExchangeRates, nil
+}
+
+// DeleteExchangeRate calls the DeleteExchangeRate gRPC method
+func (pc *ProductClient) DeleteExchangeRate(ctx context.Context, currency string) error {
+	_, err := pc.client.DeleteExchangeRate(ctx, &pb.DeleteExchangeRateRequest{Currency: currency})
+	if err != nil {
+		log.Printf("Error deleting exchange rate: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ToProtoExchangeRate converts a model.ExchangeRateInput to a pb.ExchangeRate
+func ToProtoExchangeRate(input model.ExchangeRateInput) *pb.ExchangeRate {
+	rate := &pb.ExchangeRate{
+		Currency: input.Currency,
+		Rate:     input.Rate,
+	}
+	if input.Rounding != nil {
+		rate.RoundingMode = roundingModes[*input.Rounding]
+	}
+	if input.RoundingIncrement != nil {
+		rate.RoundingIncrement = int64(*input.RoundingIncrement)
+	}
+	return rate
+}
+
+// FromProtoExchangeRate converts a pb.ExchangeRate to a model.ExchangeRate
+func FromProtoExchangeRate(r *pb.ExchangeRate) *model.ExchangeRate {
+	rate := &model.ExchangeRate{
+		Currency:          r.Currency,
+		Rate:              r.Rate,
+		Rounding:          model.RoundingModeNearest,
+		RoundingIncrement: int32(r.RoundingIncrement),
+		UpdatedAt:         r.UpdatedAt,
+	}
+	for mode, pbMode := range roundingModes {
+		if pbMode == r.RoundingMode {
+			rate.Rounding = mode
+		}
+	}
+	return rate
+}
+
+// FromProtoConversion converts a pb.PriceConversion, or nil when the prices
+// were not converted
+func FromProtoConversion(c *pb.PriceConversion) *model.PriceConversion {
+	if c == nil {
+		return nil
+	}
+	return &model.PriceConversion{FromCurrency: c.FromCurrency, Rate: c.Rate}
+}
@@ -0,0 +1,118 @@
+package productclient
+
+import (
+	"context"
+	"io"
+	"log"
+
+	"github.com/samObot19/shopverse/api-gate-way/graph/model"
+	pb "github.com/samObot19/shopverse/api-gate-way/product-client/proto/pb"
+)
+
+// uploadChunkSize is the size of the chunks an image is streamed to product-service in
+const uploadChunkSize = 64 << 10
+
+var imageFormats = map[pb.ImageFormat]model.ImageFormat{
+	pb.ImageFormat_IMAGE_FORMAT_JPEG: model.ImageFormatJpeg,
+	pb.ImageFormat_IMAGE_FORMAT_WEBP: model.ImageFormatWebp,
+}
+
+// UploadProductImage streams an image to the UploadProductImage gRPC method and returns the image as saved
+func (pc *ProductClient) UploadProductImage(ctx context.Context, productID, altText string, file io.Reader) (*pb.ProductImage, error) {
+	stream, err := pc.client.UploadProductImage(ctx)
+	if err != nil {
+		log.Printf("Error uploading product image: %v", err)
+		return nil, err
+	}
+	err = stream.Send(&pb.UploadProductImageRequest{
+		Payload: &pb.UploadProductImageRequest_Upload{Upload: &pb.ImageUpload{ProductId: productID, AltText: altText}},
+	})
+	buf := make([]byte, uploadChunkSize)
+	for err == nil {
+		n, readErr := file.Read(buf)
+		if n > 0 {
+			err = stream.Send(&pb.UploadProductImageRequest{
+				Payload: &pb.UploadProductImageRequest_Chunk{Chunk: buf[:n]},
+			})
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			log.Printf("Error reading product image: %v", readErr)
+			return nil, readErr
+		}
+	}
+	// io.EOF means product-service ended the stream, such as for an image
+	// that is too large; why is told by CloseAndRecv
+	if err != nil && err != io.EOF {
+		log.Printf("Error uploading product image: %v", err)
+		return nil, err
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		log.Printf("Error uploading product image: %v", err)
+		return nil, err
+	}
+	return resp.Image, nil
+}
+
+// ListProductImages calls the ListProductImages gRPC method
+func (pc *ProductClient) ListProductImages(ctx context.Context, productID string) ([]*pb.ProductImage, error) {
+	resp, err := pc.client.ListProductImages(ctx, &pb.ListProductImagesRequest{ProductId: productID})
+	if err != nil {
+		log.Printf("Error listing product images: %v", err)
+		return nil, err
+	}
+	return resp.Images, nil
+}
+
+// UpdateProductImage calls the UpdateProductImage gRPC method; a nil alt text
+// or position is left as it is
+func (pc *ProductClient) UpdateProductImage(ctx context.Context, id string, altText *string, position *int32) (*pb.ProductImage, error) {
+	resp, err := pc.client.UpdateProductImage(ctx, &pb.UpdateProductImageRequest{
+		Id:       id,
+		AltText:  altText,
+		Position: position,
+	})
+	if err != nil {
+		log.Printf("Error updating product image: %v", err)
+		return nil, err
+	}
+	return resp.Image, nil
+}
+
+// DeleteProductImage calls the DeleteProductImage gRPC method
+func (pc *ProductClient) DeleteProductImage(ctx context.Context, id string) error {
+	_, err := pc.client.DeleteProductImage(ctx, &pb.DeleteProductImageRequest{Id: id})
+	if err != nil {
+		log.Printf("Error deleting product image: %v", err)
+		return err
+	}
+	return nil
+}
+
+// FromProtoProductImage converts a pb.ProductImage to a model.ProductImage
+func FromProtoProductImage(image *pb.ProductImage) *model.ProductImage {
+	renditions := make([]*model.ImageRendition, 0, len(image.Renditions))
+	for _, r := range image.Renditions {
+		renditions = append(renditions, &model.ImageRendition{
+			Size:   r.Size,
+			Format: imageFormats[r.Format],
+			Width:  r.Width,
+			Height: r.Height,
+			Bytes:  int32(r.Bytes),
+			URL:    r.Url,
+		})
+	}
+	return &model.ProductImage{
+		ID:         image.Id,
+		ProductID:  image.ProductId,
+		AltText:    image.AltText,
+		Width:      image.Width,
+		Height:     image.Height,
+		Position:   image.Position,
+		Renditions: renditions,
+		CreatedAt:  image.CreatedAt,
+	}
+}
@@ -0,0 +1,28 @@
+package productclient
+
+import (
+	"github.com/samObot19/shopverse/api-gate-way/money"
+	pb "github.com/samObot19/shopverse/api-gate-way/product-client/proto/pb"
+)
+
+// FromProtoMoney converts a pb.Money to a money.Money
+func FromProtoMoney(m *pb.Money) money.Money {
+	return money.New(m.GetAmount(), m.GetCurrency())
+}
+
+// FromProtoOptionalMoney converts a pb.Money to a money.Money, or nil when unset
+func FromProtoOptionalMoney(m *pb.Money) *money.Money {
+	if m == nil {
+		return nil
+	}
+	converted := FromProtoMoney(m)
+	return &converted
+}
+
+// ToProtoMoney converts a money.Money to a pb.Money, or nil when unset
+func ToProtoMoney(m *money.Money) *pb.Money {
+	if m == nil {
+		return nil
+	}
+	return &pb.Money{Amount: m.Amount, Currency: m.Currency}
+}
@@ -0,0 +1,143 @@
+package productclient
+
+import (
+	"context"
+	"log"
+
+	"github.com/samObot19/shopverse/api-gate-way/graph/model"
+	pb "github.com/samObot19/shopverse/api-gate-way/product-client/proto/pb"
+)
+
+// CreatePriceList calls the CreatePriceList gRPC method and returns the new price list ID
+func (pc *ProductClient) CreatePriceList(ctx context.Context, list *pb.PriceList) (string, error) {
+	resp, err := pc.client.CreatePriceList(ctx, &pb.CreatePriceListRequest{PriceList: list})
+	if err != nil {
+		log.Printf("Error creating price list: %v", err)
+		return "", err
+	}
+	return resp.Id, nil
+}
+
+// GetPriceList calls the GetPriceList gRPC method
+func (pc *ProductClient) GetPriceList(ctx context.Context, id string) (*pb.PriceList, error) {
+	resp, err := pc.client.GetPriceList(ctx, &pb.GetPriceListRequest{Id: id})
+	if err != nil {
+		log.Printf("Error fetching price list: %v", err)
+		return nil, err
+	}
+	return resp.PriceList, nil
+}
+
+// ListPriceLists calls the ListPriceLists gRPC method
+func (pc *ProductClient) ListPriceLists(ctx context.Context) ([]*pb.PriceList, error) {
+	resp, err := pc.client.ListPriceLists(ctx, &pb.ListPriceListsRequest{})
+	if err != nil {
+		log.Printf("Error listing price lists: %v", err)
+		return nil, err
+	}
+	return resp.PriceLists, nil
+}
+
+// UpdatePriceList calls the UpdatePriceList gRPC method
+func (pc *ProductClient) UpdatePriceList(ctx context.Context, id string, list *pb.PriceList) error {
+	_, err := pc.client.UpdatePriceList(ctx, &pb.UpdatePriceListRequest{Id: id, PriceList: list})
+	if err != nil {
+		log.Printf("Error updating price list: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeletePriceList calls the DeletePriceList gRPC method
+func (pc *ProductClient) DeletePriceList(ctx context.Context, id string) error {
+	_, err := pc.client.DeletePriceList(ctx, &pb.DeletePriceListRequest{Id: id})
+	if err != nil {
+		log.Printf("Error deleting price list: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetPriceHistory calls the GetPriceHistory gRPC method
+func (pc *ProductClient) GetPriceHistory(ctx context.Context, productID, pageToken string, pageSize int32) (*pb.GetPriceHistoryResponse, error) {
+	resp, err := pc.client.GetPriceHistory(ctx, &pb.GetPriceHistoryRequest{
+		ProductId: productID,
+		PageSize:  pageSize,
+		PageToken: pageToken,
+	})
+	if err != nil {
+		log.Printf("Error fetching price history: %v", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ToProtoPriceList converts a model.PriceListInput to a pb.PriceList
+func ToProtoPriceList(input model.PriceListInput) *pb.PriceList {
+	list := &pb.PriceList{Name: input.Name, StartsAt: input.StartsAt}
+	if input.EndsAt != nil {
+		list.EndsAt = *input.EndsAt
+	}
+	for _, e := range input.Entries {
+		entry := &pb.PriceListEntry{ProductId: e.ProductID, Price: ToProtoMoney(e.Price)}
+		if e.VariantID != nil {
+			entry.VariantId = *e.VariantID
+		}
+		if e.PercentOff != nil {
+			entry.PercentOff = *e.PercentOff
+		}
+		list.Entries = append(list.Entries, entry)
+	}
+	return list
+}
+
+// FromProtoPriceList converts a pb.PriceList to a model.PriceList
+func FromProtoPriceList(l *pb.PriceList) *model.PriceList {
+	list := &model.PriceList{
+		ID:        l.Id,
+		Name:      l.Name,
+		StartsAt:  l.StartsAt,
+		Entries:   []*model.PriceListEntry{},
+		CreatedAt: l.CreatedAt,
+	}
+	if l.EndsAt != "" {
+		list.EndsAt = &l.EndsAt
+	}
+	for _, e := range l.Entries {
+		entry := &model.PriceListEntry{ProductID: e.ProductId, Price: FromProtoOptionalMoney(e.Price), PercentOff: e.PercentOff}
+		if e.VariantId != "" {
+			entry.VariantID = &e.VariantId
+		}
+		list.Entries = append(list.Entries, entry)
+	}
+	return list
+}
+
+// FromProtoPriceHistory converts a pb.GetPriceHistoryResponse to a model.PriceHistoryPage
+func FromProtoPriceHistory(resp *pb.GetPriceHistoryResponse) *model.PriceHistoryPage {
+	page := &model.PriceHistoryPage{Changes: []*model.PriceChange{}}
+	if resp.NextPageToken != "" {
+		page.NextPageToken = &resp.NextPageToken
+	}
+	for _, c := range resp.Changes {
+		change := &model.PriceChange{
+			ID:         c.Id,
+			ProductID:  c.ProductId,
+			Price:      FromProtoOptionalMoney(c.Price),
+			PercentOff: c.PercentOff,
+			StartsAt:   c.StartsAt,
+			CreatedAt:  c.CreatedAt,
+		}
+		if c.VariantId != "" {
+			change.VariantID = &c.VariantId
+		}
+		if c.PriceListId != "" {
+			change.PriceListID = &c.PriceListId
+		}
+		if c.EndsAt != "" {
+			change.EndsAt = &c.EndsAt
+		}
+		page.Changes = append(page.Changes, change)
+	}
+	return page
+}
@@ -9,6 +9,7 @@ import (
 	pb "github.com/samObot19/shopverse/api-gate-way/product-client/proto/pb"
 	"github.com/samObot19/shopverse/api-gate-way/graph/model"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 type ProductClient struct {
@@ -44,8 +45,8 @@ func (pc *ProductClient) GetProductByID(ctx context.Context, id string) (*pb.Pro
 }
 
 // GetAllProducts calls the GetAllProducts gRPC method
-func (pc *ProductClient) GetAllProducts(ctx context.Context, filters map[string]string) ([]*pb.Product, error) {
-	resp, err := pc.client.GetAllProducts(ctx, &pb.GetAllProductsRequest{Filters: filters})
+func (pc *ProductClient) GetAllProducts(ctx context.Context, filters map[string]string, filter *pb.ProductFilter, sort []*pb.SortKey, includeArchived bool) ([]*pb.Product, error) {
+	resp, err := pc.client.GetAllProducts(ctx, &pb.GetAllProductsRequest{Filters: filters, Filter: filter, Sort: sort, IncludeArchived: includeArchived})
 	if err != nil {
 		log.Printf("Error fetching all products: %v", err)
 		return nil, err
@@ -53,9 +54,10 @@ func (pc *ProductClient) GetAllProducts(ctx context.Context, filters map[string]
 	return resp.Products, nil
 }
 
-// UpdateProduct calls the UpdateProduct gRPC method
-func (pc *ProductClient) UpdateProduct(ctx context.Context, id string, product *pb.Product) error {
-	_, err := pc.client.UpdateProduct(ctx, &pb.UpdateProductRequest{Id: id, Product: product})
+// UpdateProduct calls the UpdateProduct gRPC method; only the fields named by
+// mask are set
+func (pc *ProductClient) UpdateProduct(ctx context.Context, id string, product *pb.Product, mask *fieldmaskpb.FieldMask) error {
+	_, err := pc.client.UpdateProduct(ctx, &pb.UpdateProductRequest{Id: id, Product: product, UpdateMask: mask})
 	if err != nil {
 		log.Printf("Error updating product: %v", err)
 		return err
@@ -64,20 +66,39 @@ func (pc *ProductClient) UpdateProduct(ctx context.Context, id string, product *
 	return nil
 }
 
-// DeleteProduct calls the DeleteProduct gRPC method
-func (pc *ProductClient) DeleteProduct(ctx context.Context, id string) error {
-	_, err := pc.client.DeleteProduct(ctx, &pb.DeleteProductRequest{Id: id})
+// DeleteProduct calls the DeleteProduct gRPC method for a product still at version
+func (pc *ProductClient) DeleteProduct(ctx context.Context, id string, version int64) error {
+	_, err := pc.client.DeleteProduct(ctx, &pb.DeleteProductRequest{Id: id, Version: version})
 	if err != nil {
 		log.Printf("Error deleting product: %v", err)
 		return err
 	}
-	log.Println("Product deleted successfully")
+	log.Println("Product archived successfully")
 	return nil
 }
 
-// UpdateStock calls the UpdateStock gRPC method
-func (pc *ProductClient) UpdateStock(ctx context.Context, id string, quantity int32) error {
-	_, err := pc.client.UpdateStock(ctx, &pb.UpdateStockRequest{Id: id, Quantity: quantity})
+// RestoreProduct calls the RestoreProduct gRPC method and returns the restored product
+func (pc *ProductClient) RestoreProduct(ctx context.Context, id string) (*pb.Product, error) {
+	resp, err := pc.client.RestoreProduct(ctx, &pb.RestoreProductRequest{Id: id})
+	if err != nil {
+		log.Printf("Error restoring product: %v", err)
+		return nil, err
+	}
+	return resp.Product, nil
+}
+
+// UpdateStock calls the UpdateStock gRPC method; the change is recorded in the
+// stock ledger as an adjustment made by actor. An empty warehouseID means the
+// default warehouse. The product must still be at version.
+func (pc *ProductClient) UpdateStock(ctx context.Context, id string, quantity int32, warehouseID, actor string, version int64) error {
+	_, err := pc.client.UpdateStock(ctx, &pb.UpdateStockRequest{
+		Id:          id,
+		Quantity:    quantity,
+		Reason:      pb.StockReason_STOCK_REASON_ADJUSTMENT,
+		Actor:       actor,
+		WarehouseId: warehouseID,
+		Version:     version,
+	})
 	if err != nil {
 		log.Printf("Error updating stock: %v", err)
 		return err
@@ -87,8 +108,8 @@ func (pc *ProductClient) UpdateStock(ctx context.Context, id string, quantity in
 }
 
 // GetProductsByCategory calls the GetProductsByCategory gRPC method
-func (pc *ProductClient) GetProductsByCategory(ctx context.Context, category string) ([]*pb.Product, error) {
-	resp, err := pc.client.GetProductsByCategory(ctx, &pb.GetProductsByCategoryRequest{Category: category})
+func (pc *ProductClient) GetProductsByCategory(ctx context.Context, category string, sort []*pb.SortKey) ([]*pb.Product, error) {
+	resp, err := pc.client.GetProductsByCategory(ctx, &pb.GetProductsByCategoryRequest{Category: category, Sort: sort})
 	if err != nil {
 		log.Printf("Error fetching products by category: %v", err)
 		return nil, err
@@ -97,18 +118,30 @@ func (pc *ProductClient) GetProductsByCategory(ctx context.Context, category str
 }
 
 // SearchProducts calls the SearchProducts gRPC method
-func (pc *ProductClient) SearchProducts(ctx context.Context, query string) ([]*pb.Product, error) {
-	resp, err := pc.client.SearchProducts(ctx, &pb.SearchProductsRequest{Query: query})
+func (pc *ProductClient) SearchProducts(ctx context.Context, query string, sort []*pb.SortKey, limit, offset int32) (*pb.SearchProductsResponse, error) {
+	resp, err := pc.client.SearchProducts(ctx, &pb.SearchProductsRequest{Query: query, Sort: sort, Limit: limit, Offset: offset})
 	if err != nil {
 		log.Printf("Error searching products: %v", err)
 		return nil, err
 	}
-	return resp.Products, nil
+	return resp, nil
 }
 
-// ConnectToProductService establishes a connection to the product service
-func ConnectToProductService(address string) (*grpc.ClientConn, error) {
-	conn, err := grpc.Dial(address, grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(5*time.Second))
+// SuggestProducts calls the SuggestProducts gRPC method
+func (pc *ProductClient) SuggestProducts(ctx context.Context, prefix string, limit int32) ([]*pb.Suggestion, error) {
+	resp, err := pc.client.SuggestProducts(ctx, &pb.SuggestProductsRequest{Prefix: prefix, Limit: limit})
+	if err != nil {
+		log.Printf("Error fetching product suggestions: %v", err)
+		return nil, err
+	}
+	return resp.Suggestions, nil
+}
+
+// ConnectToProductService establishes a connection to the product service,
+// with any extra options such as interceptors
+func ConnectToProductService(address string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	opts = append([]grpc.DialOption{grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(5 * time.Second)}, opts...)
+	conn, err := grpc.Dial(address, opts...)
 	if err != nil {
 		log.Printf("Failed to connect to product service: %v", err)
 		return nil, err
@@ -140,21 +173,40 @@ func FromProtoProduct(protoProduct *pb.Product) *model.Product {
 		return nil
 	}
 
-	return &model.Product{
+	product := &model.Product{
 		ID:          protoProduct.Id,
 		Title:       protoProduct.Title,
 		Description: protoProduct.Description,
-		Price:       protoProduct.Price,
+		Price:       FromProtoMoney(protoProduct.Price),
 		Stock:       int32(protoProduct.Stock), // Fixed type mismatch
 		Category:    protoProduct.Category,
 		Attributes: &model.ProductAttributes{
 			Color: protoProduct.Attributes.Color,
 			Size:  strings.Join(protoProduct.Attributes.Size, ","),
 		},
-		Images:    protoProduct.Images,
-		Ratings:   protoProduct.Ratings,
-		CreatedAt: protoProduct.CreatedAt,
+		Images:         protoProduct.Images,
+		Ratings:        protoProduct.Ratings,
+		ReviewCount:    protoProduct.ReviewCount,
+		EffectivePrice: FromProtoMoney(protoProduct.EffectivePrice),
+		CompareAtPrice: FromProtoOptionalMoney(protoProduct.CompareAtPrice),
+		CreatedAt:      protoProduct.CreatedAt,
+		Version:        int32(protoProduct.Version),
+		Conversion:     FromProtoConversion(protoProduct.Conversion),
+		Translations:   FromProtoTranslations(protoProduct.Translations),
+	}
+	if protoProduct.CategoryId != "" {
+		product.CategoryID = &protoProduct.CategoryId
+	}
+	if protoProduct.Sku != "" {
+		product.Sku = &protoProduct.Sku
+	}
+	if protoProduct.DeletedAt != "" {
+		product.DeletedAt = &protoProduct.DeletedAt
 	}
+	if protoProduct.Locale != "" {
+		product.Locale = &protoProduct.Locale
+	}
+	return product
 }
 
 // ToProtoProduct converts a model.Product to a pb.Product
@@ -163,11 +215,11 @@ func ToProtoProduct(product *model.Product) *pb.Product {
 		return nil
 	}
 
-	return &pb.Product{
+	protoProduct := &pb.Product{
 		Id:          product.ID, // Ensure ID is passed
 		Title:       product.Title,
 		Description: product.Description,
-		Price:       product.Price,
+		Price:       ToProtoMoney(&product.Price),
 		Stock:       int32(product.Stock),
 		Category:    product.Category,
 		Attributes: &pb.Attributes{
@@ -177,5 +229,165 @@ func ToProtoProduct(product *model.Product) *pb.Product {
 		Images:    product.Images,
 		Ratings:   product.Ratings,
 		CreatedAt: product.CreatedAt,
+		Version:   int64(product.Version),
+	}
+	if product.CategoryID != nil {
+		protoProduct.CategoryId = *product.CategoryID
+	}
+	if product.Sku != nil {
+		protoProduct.Sku = *product.Sku
+	}
+	return protoProduct
+}
+
+// ToProtoProductUpdate converts a model.UpdateProductInput to a pb.Product at
+// version and the mask of the fields it sets
+func ToProtoProductUpdate(input model.UpdateProductInput, version int32) (*pb.Product, *fieldmaskpb.FieldMask) {
+	product := &pb.Product{Attributes: &pb.Attributes{}, Version: int64(version)}
+	mask := &fieldmaskpb.FieldMask{}
+	if input.Sku != nil {
+		product.Sku = *input.Sku
+		mask.Paths = append(mask.Paths, "sku")
+	}
+	if input.Title != nil {
+		product.Title = *input.Title
+		mask.Paths = append(mask.Paths, "title")
+	}
+	if input.Description != nil {
+		product.Description = *input.Description
+		mask.Paths = append(mask.Paths, "description")
+	}
+	if input.Price != nil {
+		product.Price = ToProtoMoney(input.Price)
+		mask.Paths = append(mask.Paths, "price")
+	}
+	if input.Stock != nil {
+		product.Stock = *input.Stock
+		mask.Paths = append(mask.Paths, "stock")
+	}
+	if input.Category != nil {
+		product.Category = *input.Category
+		mask.Paths = append(mask.Paths, "category")
+	}
+	if input.CategoryID != nil {
+		product.CategoryId = *input.CategoryID
+		mask.Paths = append(mask.Paths, "category_id")
+	}
+	if input.Attributes != nil && input.Attributes.Color != nil {
+		product.Attributes.Color = *input.Attributes.Color
+		mask.Paths = append(mask.Paths, "attributes.color")
+	}
+	if input.Attributes != nil && input.Attributes.Size != nil {
+		if *input.Attributes.Size != "" {
+			product.Attributes.Size = strings.Split(*input.Attributes.Size, ",")
+		}
+		mask.Paths = append(mask.Paths, "attributes.size")
+	}
+	if input.Images != nil {
+		product.Images = input.Images
+		mask.Paths = append(mask.Paths, "images")
+	}
+	return product, mask
+}
+
+var filterFields = map[model.ProductFilterField]pb.FilterField{
+	model.ProductFilterFieldCategory: pb.FilterField_FILTER_FIELD_CATEGORY,
+	model.ProductFilterFieldColor:    pb.FilterField_FILTER_FIELD_COLOR,
+	model.ProductFilterFieldSize:     pb.FilterField_FILTER_FIELD_SIZE,
+	model.ProductFilterFieldPrice:    pb.FilterField_FILTER_FIELD_PRICE,
+	model.ProductFilterFieldStock:    pb.FilterField_FILTER_FIELD_STOCK,
+	model.ProductFilterFieldRatings:  pb.FilterField_FILTER_FIELD_RATINGS,
+}
+
+var filterOperators = map[model.FilterOperator]pb.FilterOperator{
+	model.FilterOperatorEq:    pb.FilterOperator_FILTER_OPERATOR_EQ,
+	model.FilterOperatorIn:    pb.FilterOperator_FILTER_OPERATOR_IN,
+	model.FilterOperatorRange: pb.FilterOperator_FILTER_OPERATOR_RANGE,
+	model.FilterOperatorGte:   pb.FilterOperator_FILTER_OPERATOR_GTE,
+	model.FilterOperatorLte:   pb.FilterOperator_FILTER_OPERATOR_LTE,
+	model.FilterOperatorGt:    pb.FilterOperator_FILTER_OPERATOR_GT,
+}
+
+// ToProtoFilter converts a model.ProductFilterInput to a pb.ProductFilter
+func ToProtoFilter(filter *model.ProductFilterInput) *pb.ProductFilter {
+	if filter == nil {
+		return nil
+	}
+
+	protoFilter := &pb.ProductFilter{Operator: pb.LogicalOperator_LOGICAL_OPERATOR_AND}
+	if filter.Operator != nil && *filter.Operator == model.LogicalOperatorOr {
+		protoFilter.Operator = pb.LogicalOperator_LOGICAL_OPERATOR_OR
+	}
+	for _, c := range filter.Conditions {
+		protoFilter.Conditions = append(protoFilter.Conditions, &pb.FilterCondition{
+			Field:    filterFields[c.Field],
+			Operator: filterOperators[c.Operator],
+			Values:   c.Values,
+			Min:      c.Min,
+			Max:      c.Max,
+		})
+	}
+	for _, g := range filter.Groups {
+		protoFilter.Groups = append(protoFilter.Groups, ToProtoFilter(g))
+	}
+	return protoFilter
+}
+
+var sortFields = map[model.ProductSortField]pb.SortField{
+	model.ProductSortFieldPrice:     pb.SortField_SORT_FIELD_PRICE,
+	model.ProductSortFieldCreatedAt: pb.SortField_SORT_FIELD_CREATED_AT,
+	model.ProductSortFieldRatings:   pb.SortField_SORT_FIELD_RATINGS,
+	model.ProductSortFieldTitle:     pb.SortField_SORT_FIELD_TITLE,
+}
+
+// ToProtoSort converts model.ProductSortInput keys to pb.SortKey keys
+func ToProtoSort(sort []*model.ProductSortInput) []*pb.SortKey {
+	var keys []*pb.SortKey
+	for _, s := range sort {
+		key := &pb.SortKey{Field: sortFields[s.Field], Direction: pb.SortDirection_SORT_DIRECTION_ASC}
+		if s.Direction != nil && *s.Direction == model.SortDirectionDesc {
+			key.Direction = pb.SortDirection_SORT_DIRECTION_DESC
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// FromProtoSearchFacets converts search facets to their GraphQL model
+func FromProtoSearchFacets(facets *pb.SearchFacets) *model.SearchFacets {
+	result := &model.SearchFacets{
+		Category: fromProtoFacetValues(facets.GetCategory()),
+		Color:    fromProtoFacetValues(facets.GetColor()),
+		Size:     fromProtoFacetValues(facets.GetSize()),
+		Price:    []*model.PriceBucket{},
+	}
+	for _, bucket := range facets.GetPrice() {
+		result.Price = append(result.Price, &model.PriceBucket{
+			Min:      bucket.Min,
+			Max:      bucket.Max,
+			Currency: bucket.Currency,
+			Count:    bucket.Count,
+		})
+	}
+	return result
+}
+
+func fromProtoFacetValues(values []*pb.FacetValue) []*model.FacetValue {
+	result := []*model.FacetValue{}
+	for _, v := range values {
+		result = append(result, &model.FacetValue{Value: v.Value, Count: v.Count})
+	}
+	return result
+}
+
+// FromProtoSuggestion converts an autocomplete suggestion to its GraphQL model
+func FromProtoSuggestion(suggestion *pb.Suggestion) *model.ProductSuggestion {
+	result := &model.ProductSuggestion{Text: suggestion.Text, Kind: model.SuggestionKindTitle}
+	if suggestion.Kind == pb.SuggestionKind_SUGGESTION_KIND_CATEGORY {
+		result.Kind = model.SuggestionKindCategory
+	}
+	if suggestion.ProductId != "" {
+		result.ProductID = &suggestion.ProductId
 	}
+	return result
 }
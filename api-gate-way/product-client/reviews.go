@@ -0,0 +1,151 @@
+package productclient
+
+import (
+	"context"
+	"log"
+
+	"github.com/samObot19/shopverse/api-gate-way/graph/model"
+	pb "github.com/samObot19/shopverse/api-gate-way/product-client/proto/pb"
+)
+
+// CreateReview calls the CreateReview gRPC method and returns the new review ID
+func (pc *ProductClient) CreateReview(ctx context.Context, req *pb.CreateReviewRequest) (string, error) {
+	resp, err := pc.client.CreateReview(ctx, req)
+	if err != nil {
+		log.Printf("Error creating review: %v", err)
+		return "", err
+	}
+	return resp.Id, nil
+}
+
+// GetReview calls the GetReview gRPC method
+func (pc *ProductClient) GetReview(ctx context.Context, id string) (*pb.Review, error) {
+	resp, err := pc.client.GetReview(ctx, &pb.GetReviewRequest{Id: id})
+	if err != nil {
+		log.Printf("Error fetching review: %v", err)
+		return nil, err
+	}
+	return resp.Review, nil
+}
+
+// ListReviews calls the ListReviews gRPC method; an empty productID lists the
+// reviews of every product
+func (pc *ProductClient) ListReviews(ctx context.Context, productID string, status pb.ReviewStatus, limit, offset int32) ([]*pb.Review, error) {
+	resp, err := pc.client.ListReviews(ctx, &pb.ListReviewsRequest{
+		ProductId: productID,
+		Status:    status,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		log.Printf("Error listing reviews: %v", err)
+		return nil, err
+	}
+	return resp.Reviews, nil
+}
+
+// EditReview calls the EditReview gRPC method
+func (pc *ProductClient) EditReview(ctx context.Context, req *pb.EditReviewRequest) error {
+	_, err := pc.client.EditReview(ctx, req)
+	if err != nil {
+		log.Printf("Error editing review: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ModerateReview calls the ModerateReview gRPC method
+func (pc *ProductClient) ModerateReview(ctx context.Context, id string, status pb.ReviewStatus) error {
+	_, err := pc.client.ModerateReview(ctx, &pb.ModerateReviewRequest{Id: id, Status: status})
+	if err != nil {
+		log.Printf("Error moderating review: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteReview calls the DeleteReview gRPC method; an empty userID deletes
+// the review as a moderator
+func (pc *ProductClient) DeleteReview(ctx context.Context, id, userID string) error {
+	_, err := pc.client.DeleteReview(ctx, &pb.DeleteReviewRequest{Id: id, UserId: userID})
+	if err != nil {
+		log.Printf("Error deleting review: %v", err)
+		return err
+	}
+	return nil
+}
+
+// VoteReviewHelpful calls the VoteReviewHelpful gRPC method
+func (pc *ProductClient) VoteReviewHelpful(ctx context.Context, id, userID string) error {
+	_, err := pc.client.VoteReviewHelpful(ctx, &pb.VoteReviewHelpfulRequest{Id: id, UserId: userID})
+	if err != nil {
+		log.Printf("Error voting for review: %v", err)
+		return err
+	}
+	return nil
+}
+
+var reviewStatuses = map[model.ReviewStatus]pb.ReviewStatus{
+	model.ReviewStatusPending:  pb.ReviewStatus_REVIEW_STATUS_PENDING,
+	model.ReviewStatusApproved: pb.ReviewStatus_REVIEW_STATUS_APPROVED,
+	model.ReviewStatusRejected: pb.ReviewStatus_REVIEW_STATUS_REJECTED,
+}
+
+// ToProtoReviewStatus converts an optional model.ReviewStatus to a
+// pb.ReviewStatus; nil means any status
+func ToProtoReviewStatus(status *model.ReviewStatus) pb.ReviewStatus {
+	if status == nil {
+		return pb.ReviewStatus_REVIEW_STATUS_UNSPECIFIED
+	}
+	return reviewStatuses[*status]
+}
+
+// ToProtoCreateReview converts a model.ReviewInput to a pb.CreateReviewRequest
+func ToProtoCreateReview(input model.ReviewInput, userID string) *pb.CreateReviewRequest {
+	req := &pb.CreateReviewRequest{
+		ProductId: input.ProductID,
+		UserId:    userID,
+		Rating:    input.Rating,
+		Body:      input.Body,
+	}
+	if input.Title != nil {
+		req.Title = *input.Title
+	}
+	return req
+}
+
+// ToProtoEditReview converts a model.ReviewEditInput to a pb.EditReviewRequest
+func ToProtoEditReview(id, userID string, input model.ReviewEditInput) *pb.EditReviewRequest {
+	req := &pb.EditReviewRequest{
+		Id:     id,
+		UserId: userID,
+		Rating: input.Rating,
+		Body:   input.Body,
+	}
+	if input.Title != nil {
+		req.Title = *input.Title
+	}
+	return req
+}
+
+// FromProtoReview converts a pb.Review to a model.Review
+func FromProtoReview(r *pb.Review) *model.Review {
+	review := &model.Review{
+		ID:           r.Id,
+		ProductID:    r.ProductId,
+		UserID:       r.UserId,
+		OrderID:      r.OrderId,
+		Rating:       r.Rating,
+		Title:        r.Title,
+		Body:         r.Body,
+		HelpfulVotes: r.HelpfulVotes,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}
+	for status, protoStatus := range reviewStatuses {
+		if protoStatus == r.Status {
+			review.Status = status
+		}
+	}
+	return review
+}
@@ -0,0 +1,73 @@
+package productclient
+
+import (
+	"context"
+	"log"
+	"sort"
+
+	"github.com/samObot19/shopverse/api-gate-way/graph/model"
+	pb "github.com/samObot19/shopverse/api-gate-way/product-client/proto/pb"
+)
+
+// SetProductTranslation calls the SetProductTranslation gRPC method and returns the product as saved
+func (pc *ProductClient) SetProductTranslation(ctx context.Context, productID, locale string, translation *pb.Translation, version int64) (*pb.Product, error) {
+	resp, err := pc.client.SetProductTranslation(ctx, &pb.SetProductTranslationRequest{
+		ProductId:   productID,
+		Locale:      locale,
+		Translation: translation,
+		Version:     version,
+	})
+	if err != nil {
+		log.Printf("Error setting product translation: %v", err)
+		return nil, err
+	}
+	return resp.Product, nil
+}
+
+// DeleteProductTranslation calls the DeleteProductTranslation gRPC method and returns the product as saved
+func (pc *ProductClient) DeleteProductTranslation(ctx context.Context, productID, locale string, version int64) (*pb.Product, error) {
+	resp, err := pc.client.DeleteProductTranslation(ctx, &pb.DeleteProductTranslationRequest{
+		ProductId: productID,
+		Locale:    locale,
+		Version:   version,
+	})
+	if err != nil {
+		log.Printf("Error deleting product translation: %v", err)
+		return nil, err
+	}
+	return resp.Product, nil
+}
+
+// ToProtoTranslation converts a model.ProductTranslationInput to a pb.Translation
+func ToProtoTranslation(input model.ProductTranslationInput) *pb.Translation {
+	translation := &pb.Translation{}
+	if input.Title != nil {
+		translation.Title = *input.Title
+	}
+	if input.Description != nil {
+		translation.Description = *input.Description
+	}
+	return translation
+}
+
+// FromProtoTranslations converts the translations of a pb.Product, in locale order
+func FromProtoTranslations(translations map[string]*pb.Translation) []*model.ProductTranslation {
+	locales := make([]string, 0, len(translations))
+	for locale := range translations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	result := make([]*model.ProductTranslation, 0, len(locales))
+	for _, locale := range locales {
+		t := &model.ProductTranslation{Locale: locale}
+		if title := translations[locale].GetTitle(); title != "" {
+			t.Title = &title
+		}
+		if description := translations[locale].GetDescription(); description != "" {
+			t.Description = &description
+		}
+		result = append(result, t)
+	}
+	return result
+}
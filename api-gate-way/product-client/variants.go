@@ -0,0 +1,154 @@
+package productclient
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/samObot19/shopverse/api-gate-way/graph/model"
+	pb "github.com/samObot19/shopverse/api-gate-way/product-client/proto/pb"
+)
+
+// CreateVariant calls the CreateVariant gRPC method and returns the new variant ID
+func (pc *ProductClient) CreateVariant(ctx context.Context, variant *pb.Variant) (string, error) {
+	resp, err := pc.client.CreateVariant(ctx, &pb.CreateVariantRequest{Variant: variant})
+	if err != nil {
+		log.Printf("Error creating variant: %v", err)
+		return "", err
+	}
+	return resp.Id, nil
+}
+
+// GetVariant calls the GetVariant gRPC method
+func (pc *ProductClient) GetVariant(ctx context.Context, id string) (*pb.Variant, error) {
+	resp, err := pc.client.GetVariant(ctx, &pb.GetVariantRequest{Id: id})
+	if err != nil {
+		log.Printf("Error fetching variant: %v", err)
+		return nil, err
+	}
+	return resp.Variant, nil
+}
+
+// ListVariants calls the ListVariants gRPC method
+func (pc *ProductClient) ListVariants(ctx context.Context, productID string) ([]*pb.Variant, error) {
+	resp, err := pc.client.ListVariants(ctx, &pb.ListVariantsRequest{ProductId: productID})
+	if err != nil {
+		log.Printf("Error listing variants: %v", err)
+		return nil, err
+	}
+	return resp.Variants, nil
+}
+
+// UpdateVariant calls the UpdateVariant gRPC method
+func (pc *ProductClient) UpdateVariant(ctx context.Context, id string, variant *pb.Variant) error {
+	_, err := pc.client.UpdateVariant(ctx, &pb.UpdateVariantRequest{Id: id, Variant: variant})
+	if err != nil {
+		log.Printf("Error updating variant: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteVariant calls the DeleteVariant gRPC method
+func (pc *ProductClient) DeleteVariant(ctx context.Context, id string) error {
+	_, err := pc.client.DeleteVariant(ctx, &pb.DeleteVariantRequest{Id: id})
+	if err != nil {
+		log.Printf("Error deleting variant: %v", err)
+		return err
+	}
+	return nil
+}
+
+// UpdateVariantStock calls the UpdateVariantStock gRPC method; the change is
+// recorded in the stock ledger as an adjustment made by actor. An empty
+// warehouseID means the default warehouse.
+func (pc *ProductClient) UpdateVariantStock(ctx context.Context, id string, quantity int32, warehouseID, actor string) error {
+	_, err := pc.client.UpdateVariantStock(ctx, &pb.UpdateVariantStockRequest{
+		Id:          id,
+		Quantity:    quantity,
+		Reason:      pb.StockReason_STOCK_REASON_ADJUSTMENT,
+		Actor:       actor,
+		WarehouseId: warehouseID,
+	})
+	if err != nil {
+		log.Printf("Error updating variant stock: %v", err)
+		return err
+	}
+	return nil
+}
+
+// FromProtoVariant converts a pb.Variant to a model.Variant; options are ordered by name
+func FromProtoVariant(protoVariant *pb.Variant) *model.Variant {
+	if protoVariant == nil {
+		return nil
+	}
+
+	options := []*model.VariantOption{}
+	for name, value := range protoVariant.Options {
+		options = append(options, &model.VariantOption{Name: name, Value: value})
+	}
+	sort.Slice(options, func(i, j int) bool { return options[i].Name < options[j].Name })
+
+	images := protoVariant.Images
+	if images == nil {
+		images = []string{}
+	}
+
+	return &model.Variant{
+		ID:             protoVariant.Id,
+		ProductID:      protoVariant.ProductId,
+		Sku:            protoVariant.Sku,
+		Options:        options,
+		Price:          FromProtoMoney(protoVariant.EffectivePrice),
+		PriceOverride:  FromProtoOptionalMoney(protoVariant.Price),
+		CompareAtPrice: FromProtoOptionalMoney(protoVariant.CompareAtPrice),
+		Stock:          protoVariant.Stock,
+		Images:         images,
+		CreatedAt:      protoVariant.CreatedAt,
+		Conversion:     FromProtoConversion(protoVariant.Conversion),
+	}
+}
+
+// ToProtoVariant converts a model.VariantInput to a pb.Variant
+func ToProtoVariant(productID string, input model.VariantInput) *pb.Variant {
+	options := make(map[string]string, len(input.Options))
+	for _, option := range input.Options {
+		options[option.Name] = option.Value
+	}
+
+	return &pb.Variant{
+		ProductId: productID,
+		Sku:       input.Sku,
+		Options:   options,
+		Price:     ToProtoMoney(input.PriceOverride),
+		Stock:     input.Stock,
+		Images:    input.Images,
+	}
+}
+
+// MatchVariant returns the variant whose options are exactly the selected ones,
+// compared case-insensitively, or nil when no variant matches
+func MatchVariant(variants []*pb.Variant, selected []*model.VariantOptionInput) *pb.Variant {
+	want := make(map[string]string, len(selected))
+	for _, option := range selected {
+		want[strings.ToLower(option.Name)] = strings.ToLower(option.Value)
+	}
+
+	for _, variant := range variants {
+		if len(variant.Options) != len(want) {
+			continue
+		}
+		matched := true
+		for name, value := range variant.Options {
+			if v, ok := want[strings.ToLower(name)]; !ok || v != strings.ToLower(value) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return variant
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package productclient
+
+import (
+	"context"
+	"log"
+
+	"github.com/samObot19/shopverse/api-gate-way/graph/model"
+	pb "github.com/samObot19/shopverse/api-gate-way/product-client/proto/pb"
+)
+
+// CreateWarehouse calls the CreateWarehouse gRPC method and returns the new warehouse ID
+func (pc *ProductClient) CreateWarehouse(ctx context.Context, warehouse *pb.Warehouse) (string, error) {
+	resp, err := pc.client.CreateWarehouse(ctx, &pb.CreateWarehouseRequest{Warehouse: warehouse})
+	if err != nil {
+		log.Printf("Error creating warehouse: %v", err)
+		return "", err
+	}
+	return resp.Id, nil
+}
+
+// ListWarehouses calls the ListWarehouses gRPC method
+func (pc *ProductClient) ListWarehouses(ctx context.Context) ([]*pb.Warehouse, error) {
+	resp, err := pc.client.ListWarehouses(ctx, &pb.ListWarehousesRequest{})
+	if err != nil {
+		log.Printf("Error listing warehouses: %v", err)
+		return nil, err
+	}
+	return resp.Warehouses, nil
+}
+
+// UpdateWarehouse calls the UpdateWarehouse gRPC method
+func (pc *ProductClient) UpdateWarehouse(ctx context.Context, id string, warehouse *pb.Warehouse) error {
+	_, err := pc.client.UpdateWarehouse(ctx, &pb.UpdateWarehouseRequest{Id: id, Warehouse: warehouse})
+	if err != nil {
+		log.Printf("Error updating warehouse: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetStockLevels calls the GetStockLevels gRPC method
+func (pc *ProductClient) GetStockLevels(ctx context.Context, productID string) ([]*pb.StockLevel, error) {
+	resp, err := pc.client.GetStockLevels(ctx, &pb.GetStockLevelsRequest{ProductId: productID})
+	if err != nil {
+		log.Printf("Error fetching stock levels: %v", err)
+		return nil, err
+	}
+	return resp.Levels, nil
+}
+
+// FromProtoWarehouse converts a pb.Warehouse to a model.Warehouse
+func FromProtoWarehouse(w *pb.Warehouse) *model.Warehouse {
+	return &model.Warehouse{
+		ID:        w.Id,
+		Name:      w.Name,
+		Address:   w.Address,
+		Latitude:  w.GetLocation().GetLatitude(),
+		Longitude: w.GetLocation().GetLongitude(),
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+// ToProtoWarehouse converts a model.WarehouseInput to a pb.Warehouse
+func ToProtoWarehouse(input model.WarehouseInput) *pb.Warehouse {
+	warehouse := &pb.Warehouse{
+		Name:     input.Name,
+		Location: &pb.Location{Latitude: input.Latitude, Longitude: input.Longitude},
+		Active:   true,
+	}
+	if input.Address != nil {
+		warehouse.Address = *input.Address
+	}
+	if input.Active != nil {
+		warehouse.Active = *input.Active
+	}
+	return warehouse
+}
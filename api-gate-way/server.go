@@ -8,8 +8,10 @@ import (
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/joho/godotenv"
+	"google.golang.org/grpc"
 	"github.com/samObot19/shopverse/api-gate-way/authenticate"
 	"github.com/samObot19/shopverse/api-gate-way/graph"
+	"github.com/samObot19/shopverse/api-gate-way/preferences"
 	"github.com/samObot19/shopverse/api-gate-way/product-client"
 	userclient "github.com/samObot19/shopverse/api-gate-way/user-client"
 	orderclient "github.com/samObot19/shopverse/api-gate-way/order-client"
@@ -49,7 +51,8 @@ func main() {
 	defer userConn.Close()
 	userClient := userclient.NewUserClient(userConn)
 
-	productConn, err := productclient.ConnectToProductService(productServiceAddress)
+	// Prices are read in the currency each request asks for
+	productConn, err := productclient.ConnectToProductService(productServiceAddress, grpc.WithUnaryInterceptor(preferences.UnaryClientInterceptor))
 	if err != nil {
 		log.Fatalf("Failed to connect to product service: %v", err)
 	}
@@ -77,7 +80,7 @@ func main() {
 	http.HandleFunc("/refresh", authenticate.HandleRefreshToken)
 	http.HandleFunc("/logout", authenticate.HandleLogout) // Add logout handler
 
-	http.Handle("/query", authenticate.JWTMiddleware(srv))
+	http.Handle("/query", authenticate.JWTMiddleware(preferences.Middleware(userClient)(srv)))
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
 
 	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
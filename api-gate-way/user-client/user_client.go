@@ -104,6 +104,20 @@ func (uc *UserClient) PromoteUser(ctx context.Context, username string) (*pb.Pro
 	return resp, nil
 }
 
+// UpdatePreferences sets the preferences of the user with the given email
+func (uc *UserClient) UpdatePreferences(ctx context.Context, email, preferredCurrency string) (*pb.UpdatePreferencesResponse, error) {
+	req := &pb.UpdatePreferencesRequest{
+		Email:             email,
+		PreferredCurrency: preferredCurrency,
+	}
+	resp, err := uc.client.UpdatePreferences(ctx, req)
+	if err != nil {
+		log.Printf("Error updating preferences: %v", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
 func (uc *UserClient) GetUser(ctx context.Context, username string) (*pb.GetUserResponse, error) {
 	req := &pb.GetUserRequest{
 		Username: username,
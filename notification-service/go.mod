@@ -1,3 +1,5 @@
 module github.com/samObot19/shopverse/notification-service
 
 go 1.22.5
+
+require github.com/joho/godotenv v1.5.1
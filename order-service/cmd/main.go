@@ -1,10 +1,18 @@
 package main
 
 import (
+    "context"
+    "database/sql"
+    "fmt"
     "log"
     "net"
+    "os"
+    "text/tabwriter"
+    "time"
 
     "github.com/samObot19/shopverse/order-service/internal/config"
+    "github.com/samObot19/shopverse/order-service/internal/events/subscribe"
+    "github.com/samObot19/shopverse/order-service/internal/migrations"
     "github.com/samObot19/shopverse/order-service/internal/repository"
     "github.com/samObot19/shopverse/order-service/internal/services"
     "github.com/samObot19/shopverse/order-service/internal/usecases"
@@ -23,6 +31,23 @@ func main() {
     }
     defer db.Close()
 
+    // `order-service migrate up|down|status` manages the schema and exits
+    if len(os.Args) > 1 && os.Args[1] == "migrate" {
+        if err := runMigrate(context.Background(), db, os.Args[2:]); err != nil {
+            log.Fatalf("Migration failed: %v", err)
+        }
+        return
+    }
+
+    // Apply any pending migrations before serving
+    migrator, err := migrations.NewMigrator(db)
+    if err != nil {
+        log.Fatalf("Failed to load migrations: %v", err)
+    }
+    if err := migrator.Up(context.Background()); err != nil {
+        log.Fatalf("Failed to apply migrations: %v", err)
+    }
+
     orderRepo := repository.NewOrderRepository(db)
 
     conn, err := grpc.Dial("localhost:" + conf.ProductPORT, grpc.WithTransportCredentials(insecure.NewCredentials()))
@@ -35,6 +60,9 @@ func main() {
     orderUsecase := usecases.NewOrderUsecase(orderRepo, productClient)
     orderService := services.NewOrderServiceServer(orderUsecase)
 
+    // Orders are accepted or failed as product-service reports on their stock
+    go subscribe.SubscribeAndProcessStockEvent(orderUsecase)
+
     grpcServer := grpc.NewServer()
     orderpb.RegisterOrderServiceServer(grpcServer, orderService)
 
@@ -47,4 +75,40 @@ func main() {
     if err := grpcServer.Serve(listener); err != nil {
         log.Fatalf("Failed to serve: %v", err)
     }
-}
\ No newline at end of file
+}
+
+// runMigrate implements `order-service migrate up|down|status`
+func runMigrate(ctx context.Context, sqlDB *sql.DB, args []string) error {
+    if len(args) != 1 {
+        return fmt.Errorf("usage: migrate up|down|status")
+    }
+
+    migrator, err := migrations.NewMigrator(sqlDB)
+    if err != nil {
+        return err
+    }
+
+    switch args[0] {
+    case "up":
+        return migrator.Up(ctx)
+    case "down":
+        return migrator.Down(ctx)
+    case "status":
+        statuses, err := migrator.Status(ctx)
+        if err != nil {
+            return err
+        }
+        w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+        fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+        for _, s := range statuses {
+            appliedAt := "pending"
+            if s.AppliedAt != nil {
+                appliedAt = s.AppliedAt.Format(time.RFC3339)
+            }
+            fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+        }
+        return w.Flush()
+    default:
+        return fmt.Errorf("unknown migrate command %q, expected up, down or status", args[0])
+    }
+}
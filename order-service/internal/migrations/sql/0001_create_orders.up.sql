@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS orders (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id VARCHAR(64) NOT NULL,
+    order_status VARCHAR(32) NOT NULL,
+    payment_status VARCHAR(32) NOT NULL,
+    total_amount DOUBLE NOT NULL,
+    shipping_addr VARCHAR(512) NOT NULL,
+    billing_addr VARCHAR(512) NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    INDEX idx_orders_user (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    order_id INT UNSIGNED NOT NULL,
+    product_id VARCHAR(64) NOT NULL,
+    product_price DOUBLE NOT NULL,
+    quantity INT NOT NULL,
+    total_price DOUBLE NOT NULL,
+    INDEX idx_order_items_order (order_id),
+    INDEX idx_order_items_product (product_id),
+    CONSTRAINT fk_order_items_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+DROP INDEX idx_order_items_variant ON order_items;
+ALTER TABLE order_items DROP COLUMN variant_id;
@@ -0,0 +1,2 @@
+ALTER TABLE order_items ADD COLUMN variant_id VARCHAR(64) NOT NULL DEFAULT '' AFTER product_id;
+CREATE INDEX idx_order_items_variant ON order_items (variant_id);
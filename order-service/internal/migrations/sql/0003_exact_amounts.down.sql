@@ -0,0 +1,4 @@
+ALTER TABLE order_items MODIFY total_price DOUBLE NOT NULL;
+ALTER TABLE order_items MODIFY product_price DOUBLE NOT NULL;
+ALTER TABLE orders DROP COLUMN currency;
+ALTER TABLE orders MODIFY total_amount DOUBLE NOT NULL;
@@ -0,0 +1,9 @@
+-- Amounts are kept exactly as decimals in major units; every item of an order
+-- is in the order's ISO 4217 currency. Orders placed before were all in USD.
+ALTER TABLE orders MODIFY total_amount DECIMAL(19,4) NOT NULL;
+ALTER TABLE orders ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD' AFTER total_amount;
+ALTER TABLE orders ALTER COLUMN currency DROP DEFAULT;
+UPDATE orders SET total_amount = ROUND(total_amount, 2);
+ALTER TABLE order_items MODIFY product_price DECIMAL(19,4) NOT NULL;
+ALTER TABLE order_items MODIFY total_price DECIMAL(19,4) NOT NULL;
+UPDATE order_items SET product_price = ROUND(product_price, 2), total_price = ROUND(total_price, 2);
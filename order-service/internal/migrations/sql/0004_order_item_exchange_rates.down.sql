@@ -0,0 +1,2 @@
+ALTER TABLE order_items DROP COLUMN exchange_rate;
+ALTER TABLE order_items DROP COLUMN base_currency;
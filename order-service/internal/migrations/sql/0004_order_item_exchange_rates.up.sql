@@ -0,0 +1,4 @@
+-- Items priced in a currency other than the one product-service sets their
+-- price in record that currency and the rate the price was converted at.
+ALTER TABLE order_items ADD COLUMN base_currency CHAR(3) NULL AFTER total_price;
+ALTER TABLE order_items ADD COLUMN exchange_rate DECIMAL(20,10) NULL AFTER base_currency;
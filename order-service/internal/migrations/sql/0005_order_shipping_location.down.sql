@@ -0,0 +1,2 @@
+ALTER TABLE orders DROP COLUMN shipping_longitude;
+ALTER TABLE orders DROP COLUMN shipping_latitude;
@@ -0,0 +1,4 @@
+-- Orders may say where they ship to, so product-service takes their stock
+-- from the nearest warehouse.
+ALTER TABLE orders ADD COLUMN shipping_latitude DOUBLE NULL AFTER shipping_addr;
+ALTER TABLE orders ADD COLUMN shipping_longitude DOUBLE NULL AFTER shipping_latitude;
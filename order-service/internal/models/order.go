@@ -1,25 +1,39 @@
 package models
 
-import "time"
+import (
+    "time"
+
+    "github.com/samObot19/shopverse/order-service/internal/money"
+)
 // 
 type Order struct {
-    ID             uint      `json:"id"`
-    UserID         string    `json:"user_id"`
-    OrderStatus    string    `json:"order_status"`
-    PaymentStatus  string    `json:"payment_status"`
-    TotalAmount    float64   `json:"total_amount"`
-    ShippingAddr   string    `json:"shipping_address"`
-    BillingAddr    string    `json:"billing_address"`
-    CreatedAt      time.Time `json:"created_at"`
-    UpdatedAt      time.Time `json:"updated_at"`
-    Items          []OrderItem
+    ID               uint        `json:"id"`
+    UserID           string      `json:"user_id"`
+    OrderStatus      string      `json:"order_status"`
+    PaymentStatus    string      `json:"payment_status"`
+    TotalAmount      money.Money `json:"total_amount"` // Sum of the item totals, all of one currency
+    ShippingAddr     string      `json:"shipping_address"`
+    ShippingLocation *Location   `json:"shipping_location,omitempty"` // Where the order ships to, when given
+    BillingAddr      string      `json:"billing_address"`
+    CreatedAt        time.Time   `json:"created_at"`
+    UpdatedAt        time.Time   `json:"updated_at"`
+    Items            []OrderItem
 }
 
 type OrderItem struct {
-    ID           uint    `json:"id"`
-    OrderID      uint    `json:"order_id"`
-    ProductID    string  `json:"product_id"`
-    ProductPrice float64 `json:"product_price"`
-    Quantity     int     `json:"quantity"`
-    TotalPrice   float64 `json:"total_price"`
+    ID           uint        `json:"id"`
+    OrderID      uint        `json:"order_id"`
+    ProductID    string      `json:"product_id"`
+    VariantID    string      `json:"variant_id,omitempty"`
+    ProductPrice money.Money `json:"product_price"` // Effective price at checkout, as quoted by product-service
+    Quantity     int         `json:"quantity"`
+    TotalPrice   money.Money `json:"total_price"`
+    BaseCurrency string      `json:"base_currency,omitempty"` // Currency product-service sets the price in, when it was converted
+    ExchangeRate string      `json:"exchange_rate,omitempty"` // Decimal rate from BaseCurrency the price was converted at
+}
+
+// Location is a point on the map
+type Location struct {
+    Latitude  float64 `json:"latitude"`
+    Longitude float64 `json:"longitude"`
 }
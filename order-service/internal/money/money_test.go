@@ -0,0 +1,87 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		decimal  string
+		currency string
+		want     Money
+		err      error
+	}{
+		{"12.34", "USD", New(1234, "USD"), nil},
+		{"12.3", "USD", New(1230, "USD"), nil},
+		{"12", "USD", New(1200, "USD"), nil},
+		{"0.1", "USD", New(10, "USD"), nil},
+		{" 7.50 ", "EUR", New(750, "EUR"), nil},
+		{"12.3400", "USD", New(1234, "USD"), nil},
+		// more places than the currency has are rounded, halves away from zero
+		{"0.125", "USD", New(13, "USD"), nil},
+		{"0.124", "USD", New(12, "USD"), nil},
+		{"-0.125", "USD", New(-13, "USD"), nil},
+		{"1500.5", "JPY", New(1501, "JPY"), nil},
+		{"1.2345", "KWD", New(1235, "KWD"), nil},
+		{"1e3", "USD", Money{}, ErrInvalidAmount},
+		{"1/3", "USD", Money{}, ErrInvalidAmount},
+		{"", "USD", Money{}, ErrInvalidAmount},
+		{"99999999999999999999", "USD", Money{}, ErrInvalidAmount},
+		{"1.00", "usd", Money{}, ErrInvalidCurrency},
+		{"1.00", "", Money{}, ErrInvalidCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.decimal+" "+tt.currency, func(t *testing.T) {
+			got, err := Parse(tt.decimal, tt.currency)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{New(1234, "USD"), "12.34"},
+		{New(5, "USD"), "0.05"},
+		{New(0, "USD"), "0.00"},
+		{New(-5, "EUR"), "-0.05"},
+		{New(1500, "JPY"), "1500"},
+		{New(1, "BHD"), "0.001"},
+		{New(math.MinInt64, "USD"), "-92233720368547758.08"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Decimal(); got != tt.want {
+			t.Errorf("%#v.Decimal() = %q, want %q", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	// summed as floats, ten 0.10 items come to 0.9999999999999999
+	total, err := Sum("USD", New(10, "USD"), New(10, "USD"), New(10, "USD"), New(10, "USD"), New(10, "USD"),
+		New(10, "USD"), New(10, "USD"), New(10, "USD"), New(10, "USD"), New(10, "USD"))
+	if err != nil || total != New(100, "USD") {
+		t.Errorf("Sum() = %v, %v, want 1.00 USD", total, err)
+	}
+	if _, err := New(10, "USD").Add(New(10, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add() of another currency error = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := New(math.MaxInt64, "USD").Add(New(1, "USD")); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Add() overflow error = %v, want ErrInvalidAmount", err)
+	}
+	if got, err := New(1999, "USD").Mul(3); err != nil || got != New(5997, "USD") {
+		t.Errorf("Mul() = %v, %v, want 59.97 USD", got, err)
+	}
+	if _, err := New(math.MaxInt64/2, "USD").Mul(3); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Mul() overflow error = %v, want ErrInvalidAmount", err)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/samObot19/shopverse/order-service/internal/models"
+	"github.com/samObot19/shopverse/order-service/internal/money"
 )
 
 
@@ -17,9 +19,15 @@ type OrderRepository interface {
 	UpdatePaymentStatus(ctx context.Context, orderID string, status string) error
 	DeleteOrder(ctx context.Context, orderID string) error
 	GetAllOrders(ctx context.Context, userID string) ([]*models.Order, error)
+	// GetReferencedProducts returns the given products that appear in any order
+	GetReferencedProducts(ctx context.Context, productIDs []string) ([]string, error)
 }
 
 
+// selectItemsQuery reads the items of an order
+const selectItemsQuery = `SELECT id, order_id, product_id, variant_id, product_price, quantity, total_price, base_currency, exchange_rate FROM order_items WHERE order_id = ?`
+
+
 type orderRepository struct {
 	DB *sql.DB
 }
@@ -36,9 +44,14 @@ func (r *orderRepository) CreateOrder(ctx context.Context, order *models.Order)
 		return 0, err
 	}
 
-	query := `INSERT INTO orders (user_id, order_status, payment_status, total_amount, shipping_addr, billing_addr, created_at, updated_at)
-              VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
-	result, err := tx.Exec(query, order.UserID, order.OrderStatus, order.PaymentStatus, order.TotalAmount, order.ShippingAddr, order.BillingAddr, time.Now(), time.Now())
+	var latitude, longitude sql.NullFloat64
+	if order.ShippingLocation != nil {
+		latitude = sql.NullFloat64{Float64: order.ShippingLocation.Latitude, Valid: true}
+		longitude = sql.NullFloat64{Float64: order.ShippingLocation.Longitude, Valid: true}
+	}
+	query := `INSERT INTO orders (user_id, order_status, payment_status, total_amount, currency, shipping_addr, shipping_latitude, shipping_longitude, billing_addr, created_at, updated_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := tx.Exec(query, order.UserID, order.OrderStatus, order.PaymentStatus, order.TotalAmount.Decimal(), order.TotalAmount.Currency, order.ShippingAddr, latitude, longitude, order.BillingAddr, time.Now(), time.Now())
 	if err != nil {
 		tx.Rollback()
 		return 0, err
@@ -52,9 +65,9 @@ func (r *orderRepository) CreateOrder(ctx context.Context, order *models.Order)
 
 	
 	for _, item := range order.Items {
-		itemQuery := `INSERT INTO order_items (order_id, product_id, product_price, quantity, total_price)
-                      VALUES (?, ?, ?, ?, ?)`
-		_, err := tx.Exec(itemQuery, orderID, item.ProductID, item.ProductPrice, item.Quantity, item.TotalPrice)
+		itemQuery := `INSERT INTO order_items (order_id, product_id, variant_id, product_price, quantity, total_price, base_currency, exchange_rate)
+                      VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+		_, err := tx.Exec(itemQuery, orderID, item.ProductID, item.VariantID, item.ProductPrice.Decimal(), item.Quantity, item.TotalPrice.Decimal(), nullString(item.BaseCurrency), nullString(item.ExchangeRate))
 		if err != nil {
 			tx.Rollback()
 			return 0, err
@@ -71,12 +84,11 @@ func (r *orderRepository) CreateOrder(ctx context.Context, order *models.Order)
 
 
 func (r *orderRepository) GetOrderByID(ctx context.Context, orderID string) (*models.Order, error) {
-	query := `SELECT id, user_id, order_status, payment_status, total_amount, shipping_addr, billing_addr, created_at, updated_at
+	query := `SELECT id, user_id, order_status, payment_status, total_amount, currency, shipping_addr, shipping_latitude, shipping_longitude, billing_addr, created_at, updated_at
               FROM orders WHERE id = ?`
 	row := r.DB.QueryRow(query, orderID)
 
-	var order models.Order
-	err := row.Scan(&order.ID, &order.UserID, &order.OrderStatus, &order.PaymentStatus, &order.TotalAmount, &order.ShippingAddr, &order.BillingAddr, &order.CreatedAt, &order.UpdatedAt)
+	order, err := scanOrder(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -85,25 +97,18 @@ func (r *orderRepository) GetOrderByID(ctx context.Context, orderID string) (*mo
 	}
 
 	
-	itemQuery := `SELECT id, order_id, product_id, product_price, quantity, total_price FROM order_items WHERE order_id = ?`
-	rows, err := r.DB.Query(itemQuery, orderID)
+	rows, err := r.DB.Query(selectItemsQuery, orderID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var items []models.OrderItem
-	for rows.Next() {
-		var item models.OrderItem
-		err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.ProductPrice, &item.Quantity, &item.TotalPrice)
-		if err != nil {
-			return nil, err
-		}
-		items = append(items, item)
+	order.Items, err = scanItems(rows, order.TotalAmount.Currency)
+	if err != nil {
+		return nil, err
 	}
-	order.Items = items
 
-	return &order, nil
+	return order, nil
 }
 
 
@@ -129,7 +134,7 @@ func (r *orderRepository) DeleteOrder(ctx context.Context, orderID string) error
 
 // GetAllOrders retrieves all orders for a specific user
 func (r *orderRepository) GetAllOrders(ctx context.Context, userID string) ([]*models.Order, error) {
-	query := `SELECT id, user_id, order_status, payment_status, total_amount, shipping_addr, billing_addr, created_at, updated_at
+	query := `SELECT id, user_id, order_status, payment_status, total_amount, currency, shipping_addr, shipping_latitude, shipping_longitude, billing_addr, created_at, updated_at
               FROM orders WHERE user_id = ?`
 	rows, err := r.DB.QueryContext(ctx, query, userID)
 	if err != nil {
@@ -139,33 +144,107 @@ func (r *orderRepository) GetAllOrders(ctx context.Context, userID string) ([]*m
 
 	var orders []*models.Order
 	for rows.Next() {
-		var order models.Order
-		err := rows.Scan(&order.ID, &order.UserID, &order.OrderStatus, &order.PaymentStatus, &order.TotalAmount, &order.ShippingAddr, &order.BillingAddr, &order.CreatedAt, &order.UpdatedAt)
+		order, err := scanOrder(rows)
 		if err != nil {
 			return nil, err
 		}
 
 		// Fetch order items for each order
-		itemQuery := `SELECT id, order_id, product_id, product_price, quantity, total_price FROM order_items WHERE order_id = ?`
-		itemRows, err := r.DB.QueryContext(ctx, itemQuery, order.ID)
+		itemRows, err := r.DB.QueryContext(ctx, selectItemsQuery, order.ID)
 		if err != nil {
 			return nil, err
 		}
 		defer itemRows.Close()
 
-		var items []models.OrderItem
-		for itemRows.Next() {
-			var item models.OrderItem
-			err := itemRows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.ProductPrice, &item.Quantity, &item.TotalPrice)
-			if err != nil {
-				return nil, err
-			}
-			items = append(items, item)
+		order.Items, err = scanItems(itemRows, order.TotalAmount.Currency)
+		if err != nil {
+			return nil, err
 		}
-		order.Items = items
 
-		orders = append(orders, &order)
+		orders = append(orders, order)
 	}
 
 	return orders, nil
 }
+
+// GetReferencedProducts returns the given products that appear in any order
+func (r *orderRepository) GetReferencedProducts(ctx context.Context, productIDs []string) ([]string, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, len(productIDs))
+	for i, id := range productIDs {
+		args[i] = id
+	}
+	query := `SELECT DISTINCT product_id FROM order_items WHERE product_id IN (` +
+		strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ") + `)`
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var referenced []string
+	for rows.Next() {
+		var productID string
+		if err := rows.Scan(&productID); err != nil {
+			return nil, err
+		}
+		referenced = append(referenced, productID)
+	}
+	return referenced, rows.Err()
+}
+
+
+// scanOrder reads an order row; its amounts are DECIMAL columns, read as
+// strings so they are parsed exactly
+func scanOrder(row interface{ Scan(dest ...interface{}) error }) (*models.Order, error) {
+	var order models.Order
+	var totalAmount, currency string
+	var latitude, longitude sql.NullFloat64
+	err := row.Scan(&order.ID, &order.UserID, &order.OrderStatus, &order.PaymentStatus, &totalAmount, &currency, &order.ShippingAddr, &latitude, &longitude, &order.BillingAddr, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if latitude.Valid && longitude.Valid {
+		order.ShippingLocation = &models.Location{Latitude: latitude.Float64, Longitude: longitude.Float64}
+	}
+	if order.TotalAmount, err = money.Parse(totalAmount, currency); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+
+// nullString stores an empty string as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+
+// scanItems reads the item rows of an order, whose amounts are in currency
+func scanItems(rows *sql.Rows, currency string) ([]models.OrderItem, error) {
+	var items []models.OrderItem
+	for rows.Next() {
+		var item models.OrderItem
+		var productPrice, totalPrice string
+		var baseCurrency, exchangeRate sql.NullString
+		err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.VariantID, &productPrice, &item.Quantity, &totalPrice, &baseCurrency, &exchangeRate)
+		if err != nil {
+			return nil, err
+		}
+		item.BaseCurrency = baseCurrency.String
+		// DECIMAL columns come back padded with zeros, such as 0.9200000000
+		if exchangeRate.Valid {
+			item.ExchangeRate = strings.TrimSuffix(strings.TrimRight(exchangeRate.String, "0"), ".")
+		}
+		if item.ProductPrice, err = money.Parse(productPrice, currency); err != nil {
+			return nil, err
+		}
+		if item.TotalPrice, err = money.Parse(totalPrice, currency); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"github.com/samObot19/shopverse/order-service/internal/models"
+	"github.com/samObot19/shopverse/order-service/internal/money"
 	"github.com/samObot19/shopverse/order-service/internal/usecases"
 	"github.com/samObot19/shopverse/order-service/proto/pb"
 )
@@ -22,13 +23,14 @@ func NewOrderServiceServer(usecase usecases.OrderUsecase) *OrderServiceServer {
 
 func (s *OrderServiceServer) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.CreateOrderResponse, error) {
 	order := &models.Order{
-		UserID:       req.UserId, 
-		ShippingAddr: req.ShippingAddress,
-		BillingAddr:  req.BillingAddress,
-		Items:        convertProtoOrderItemsToModel(req.Items),
+		UserID:           req.UserId, 
+		ShippingAddr:     req.ShippingAddress,
+		ShippingLocation: convertProtoLocationToModel(req.ShippingLocation),
+		BillingAddr:      req.BillingAddress,
+		Items:            convertProtoOrderItemsToModel(req.Items),
 	}
 
-	orderID, err := s.usecase.CreateOrder(ctx, order)
+	orderID, err := s.usecase.CreateOrder(ctx, order, req.Currency)
 	if err != nil {
 		log.Printf("Failed to create order: %v", err)
 		return nil, err
@@ -92,15 +94,24 @@ func (s *OrderServiceServer) GetAllOrders(ctx context.Context, req *pb.GetAllOrd
 	return &pb.GetAllOrdersResponse{Orders: convertModelOrdersToProto(orders)}, nil
 }
 
+func (s *OrderServiceServer) GetReferencedProducts(ctx context.Context, req *pb.GetReferencedProductsRequest) (*pb.GetReferencedProductsResponse, error) {
+	productIDs, err := s.usecase.GetReferencedProducts(ctx, req.ProductIds)
+	if err != nil {
+		log.Printf("Failed to look up referenced products: %v", err)
+		return nil, err
+	}
+
+	return &pb.GetReferencedProductsResponse{ProductIds: productIDs}, nil
+}
+
 
 func convertProtoOrderItemsToModel(items []*pb.OrderItem) []models.OrderItem {
 	var modelItems []models.OrderItem
 	for _, item := range items {
 		modelItems = append(modelItems, models.OrderItem{
 			ProductID:    item.ProductId, 
-			ProductPrice: float64(item.ProductPrice),
+			VariantID:    item.VariantId,
 			Quantity:     int(item.Quantity),
-			TotalPrice:   float64(item.TotalPrice),
 		})
 	}
 	return modelItems
@@ -108,16 +119,17 @@ func convertProtoOrderItemsToModel(items []*pb.OrderItem) []models.OrderItem {
 
 func convertModelOrderToProto(order *models.Order) *pb.Order {
 	return &pb.Order{
-		Id:              uint32(order.ID),
-		UserId:          order.UserID, 
-		OrderStatus:     order.OrderStatus,
-		PaymentStatus:   order.PaymentStatus,
-		TotalAmount:     float32(order.TotalAmount),
-		ShippingAddress: order.ShippingAddr,
-		BillingAddress:  order.BillingAddr,
-		CreatedAt:       order.CreatedAt.String(),
-		UpdatedAt:       order.UpdatedAt.String(),
-		Items:           convertModelOrderItemsToProto(order.Items),
+		Id:               uint32(order.ID),
+		UserId:           order.UserID, 
+		OrderStatus:      order.OrderStatus,
+		PaymentStatus:    order.PaymentStatus,
+		TotalAmount:      convertMoneyToProto(order.TotalAmount),
+		ShippingAddress:  order.ShippingAddr,
+		ShippingLocation: convertLocationToProto(order.ShippingLocation),
+		BillingAddress:   order.BillingAddr,
+		CreatedAt:        order.CreatedAt.String(),
+		UpdatedAt:        order.UpdatedAt.String(),
+		Items:            convertModelOrderItemsToProto(order.Items),
 	}
 }
 
@@ -127,9 +139,12 @@ func convertModelOrderItemsToProto(items []models.OrderItem) []*pb.OrderItem {
 		protoItems = append(protoItems, &pb.OrderItem{
 			Id:           uint32(item.ID),
 			ProductId:    item.ProductID, 
-			ProductPrice: float32(item.ProductPrice),
+			VariantId:    item.VariantID,
+			ProductPrice: convertMoneyToProto(item.ProductPrice),
 			Quantity:     uint32(item.Quantity),
-			TotalPrice:   float32(item.TotalPrice),
+			TotalPrice:   convertMoneyToProto(item.TotalPrice),
+			BaseCurrency: item.BaseCurrency,
+			ExchangeRate: item.ExchangeRate,
 		})
 	}
 	return protoItems
@@ -142,3 +157,23 @@ func convertModelOrdersToProto(orders []*models.Order) []*pb.Order {
 	}
 	return protoOrders
 }
+
+func convertMoneyToProto(m money.Money) *pb.Money {
+	return &pb.Money{Amount: m.Amount, Currency: m.Currency}
+}
+
+// convertProtoLocationToModel converts a pb.Location, or nil when unset
+func convertProtoLocationToModel(l *pb.Location) *models.Location {
+	if l == nil {
+		return nil
+	}
+	return &models.Location{Latitude: l.Latitude, Longitude: l.Longitude}
+}
+
+// convertLocationToProto converts a models.Location, or nil when unset
+func convertLocationToProto(l *models.Location) *pb.Location {
+	if l == nil {
+		return nil
+	}
+	return &pb.Location{Latitude: l.Latitude, Longitude: l.Longitude}
+}
@@ -7,18 +7,23 @@ import (
 	"log"
 	"github.com/samObot19/shopverse/order-service/internal/events/publish"
 	"github.com/samObot19/shopverse/order-service/internal/models"
+	"github.com/samObot19/shopverse/order-service/internal/money"
 	"github.com/samObot19/shopverse/order-service/internal/repository"
 	"github.com/samObot19/shopverse/order-service/clients/product-client/proto/pb"
+	"google.golang.org/grpc/metadata"
 )
 
 
 type OrderUsecase interface {
-	CreateOrder(ctx context.Context, order *models.Order) (uint, error)
+	// CreateOrder prices the items in currency, or in the currency products
+	// are priced in when it is empty
+	CreateOrder(ctx context.Context, order *models.Order, currency string) (uint, error)
 	GetOrderByID(ctx context.Context, orderID uint) (*models.Order, error)
 	UpdateOrderStatus(ctx context.Context, orderID uint, status string) error
 	UpdatePaymentStatus(ctx context.Context, orderID uint, status string) error
 	DeleteOrder(ctx context.Context, orderID uint) error
 	GetAllOrders(ctx context.Context, userID string) ([]*models.Order, error)
+	GetReferencedProducts(ctx context.Context, productIDs []string) ([]string, error)
 }
 
 
@@ -36,12 +41,35 @@ func NewOrderUsecase(repo repository.OrderRepository, productClient pb.ProductSe
 }
 
 
-func (u *orderUsecase) CreateOrder(ctx context.Context, order *models.Order) (uint, error) {
+// currencyMetadataKey is the metadata asking product-service for prices in a currency
+const currencyMetadataKey = "x-currency"
+
+
+func (u *orderUsecase) CreateOrder(ctx context.Context, order *models.Order, currency string) (uint, error) {
 	if len(order.Items) == 0 {
 		return 0, errors.New("order must contain at least one item")
 	}
+	if l := order.ShippingLocation; l != nil {
+		if l.Latitude < -90 || l.Latitude > 90 {
+			return 0, errors.New("shipping latitude must be between -90 and 90")
+		}
+		if l.Longitude < -180 || l.Longitude > 180 {
+			return 0, errors.New("shipping longitude must be between -180 and 180")
+		}
+	}
+	if currency != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, currencyMetadataKey, currency)
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		if item.VariantID != "" {
+			if err := u.checkVariantStock(ctx, item); err != nil {
+				return 0, err
+			}
+			continue
+		}
 
-	for _, item := range order.Items {
 		productResponse, err := u.productClient.GetProductByID(ctx, &pb.GetProductByIDRequest{
 			Id: item.ProductID,
 		})
@@ -51,18 +79,21 @@ func (u *orderUsecase) CreateOrder(ctx context.Context, order *models.Order) (ui
 		}
 
 		product := productResponse.Product
+		if product.DeletedAt != "" {
+			return 0, fmt.Errorf("product ID %s is no longer sold", item.ProductID)
+		}
 		if product.Stock < int32(item.Quantity) {
 			log.Printf("Insufficient stock for product ID %s: available %d, required %d", item.ProductID, product.Stock, item.Quantity)
 			return 0, fmt.Errorf("insufficient stock for product ID %s", item.ProductID)
 		}
+		// snapshot the price in effect now; the client's quote is not trusted
+		item.ProductPrice = fromProtoMoney(product.EffectivePrice)
+		setConversion(item, product.Conversion)
 	}
 
-	var totalAmount float64
-	for _, item := range order.Items {
-		item.TotalPrice = item.ProductPrice * float64(item.Quantity)
-		totalAmount += item.TotalPrice
+	if err := totalOrder(order); err != nil {
+		return 0, err
 	}
-	order.TotalAmount = totalAmount
 
 	order.OrderStatus = "Pending"
 	order.PaymentStatus = "Unpaid"
@@ -72,7 +103,9 @@ func (u *orderUsecase) CreateOrder(ctx context.Context, order *models.Order) (ui
 		log.Printf("Failed to create order: %v", err)
 		return 0, err
 	}
+	order.ID = orderID
 
+	// product-service answers with a stockEvent once it has reserved the stock
 	err = publish.PublishEvent("orderCreated", *order)
 	if err != nil {
 		log.Printf("Failed to publish order-created event: %v", err)
@@ -83,6 +116,75 @@ func (u *orderUsecase) CreateOrder(ctx context.Context, order *models.Order) (ui
 }
 
 
+// checkVariantStock verifies that an item's variant belongs to its product and
+// has enough stock; an item naming only a variant gets the variant's product.
+// The item is priced at the variant's effective price.
+func (u *orderUsecase) checkVariantStock(ctx context.Context, item *models.OrderItem) error {
+	variantResponse, err := u.productClient.GetVariant(ctx, &pb.GetVariantRequest{
+		Id: item.VariantID,
+	})
+	if err != nil {
+		log.Printf("Failed to fetch variant details for variant ID %s: %v", item.VariantID, err)
+		return fmt.Errorf("failed to fetch variant details for variant ID %s", item.VariantID)
+	}
+
+	variant := variantResponse.Variant
+	if item.ProductID == "" {
+		item.ProductID = variant.ProductId
+	} else if item.ProductID != variant.ProductId {
+		return fmt.Errorf("variant %s does not belong to product %s", item.VariantID, item.ProductID)
+	}
+	if variant.Stock < int32(item.Quantity) {
+		log.Printf("Insufficient stock for variant ID %s: available %d, required %d", item.VariantID, variant.Stock, item.Quantity)
+		return fmt.Errorf("insufficient stock for variant ID %s", item.VariantID)
+	}
+	item.ProductPrice = fromProtoMoney(variant.EffectivePrice)
+	setConversion(item, variant.Conversion)
+	return nil
+}
+
+
+// setConversion records the exchange rate an item's price was converted at,
+// if it was
+func setConversion(item *models.OrderItem, conversion *pb.PriceConversion) {
+	item.BaseCurrency = conversion.GetFromCurrency()
+	item.ExchangeRate = conversion.GetRate()
+}
+
+
+// totalOrder prices each item at its quantity and sums the items into the
+// order's total, exactly. An order is paid in one currency, so items priced
+// in different currencies are refused.
+func totalOrder(order *models.Order) error {
+	currency := order.Items[0].ProductPrice.Currency
+	totals := make([]money.Money, len(order.Items))
+	for i := range order.Items {
+		item := &order.Items[i]
+		if item.ProductPrice.Currency != currency {
+			return fmt.Errorf("product ID %s is priced in %s, not the order's %s", item.ProductID, item.ProductPrice.Currency, currency)
+		}
+		total, err := item.ProductPrice.Mul(int64(item.Quantity))
+		if err != nil {
+			return fmt.Errorf("pricing product ID %s: %w", item.ProductID, err)
+		}
+		item.TotalPrice = total
+		totals[i] = total
+	}
+	total, err := money.Sum(currency, totals...)
+	if err != nil {
+		return fmt.Errorf("totaling order: %w", err)
+	}
+	order.TotalAmount = total
+	return nil
+}
+
+
+// fromProtoMoney converts a price quoted by product-service
+func fromProtoMoney(m *pb.Money) money.Money {
+	return money.New(m.GetAmount(), m.GetCurrency())
+}
+
+
 func (u *orderUsecase) GetOrderByID(ctx context.Context, orderID uint) (*models.Order, error) {
 	order, err := u.repo.GetOrderByID(ctx, fmt.Sprintf("%d", orderID))
 	if err != nil {
@@ -97,7 +199,8 @@ func (u *orderUsecase) GetOrderByID(ctx context.Context, orderID uint) (*models.
 
 
 func (u *orderUsecase) UpdateOrderStatus(ctx context.Context, orderID uint, status string) error {
-	validStatuses := []string{"Pending", "Processing", "Shipped", "Delivered", "Cancelled"}
+	// Accepted and Failed are set once product-service has checked the stock
+	validStatuses := []string{"Pending", "Accepted", "Failed", "Processing", "Shipped", "Delivered", "Cancelled"}
 	isValid := false
 	for _, validStatus := range validStatuses {
 		if status == validStatus {
@@ -157,3 +260,14 @@ func (u *orderUsecase) GetAllOrders(ctx context.Context, userID string) ([]*mode
 	}
 	return orders, nil
 }
+
+// GetReferencedProducts returns the given products that appear in any order,
+// so product-service only purges products no order needs
+func (u *orderUsecase) GetReferencedProducts(ctx context.Context, productIDs []string) ([]string, error) {
+	referenced, err := u.repo.GetReferencedProducts(ctx, productIDs)
+	if err != nil {
+		log.Printf("Failed to look up referenced products: %v", err)
+		return nil, err
+	}
+	return referenced, nil
+}
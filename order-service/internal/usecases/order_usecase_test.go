@@ -0,0 +1,157 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/samObot19/shopverse/order-service/clients/product-client/proto/pb"
+	"github.com/samObot19/shopverse/order-service/internal/models"
+	"github.com/samObot19/shopverse/order-service/internal/money"
+	"github.com/samObot19/shopverse/order-service/internal/repository"
+	"google.golang.org/grpc"
+)
+
+func item(productID string, price money.Money, quantity int) models.OrderItem {
+	return models.OrderItem{ProductID: productID, ProductPrice: price, Quantity: quantity}
+}
+
+func TestTotalOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		items      []models.OrderItem
+		wantItems  []money.Money
+		wantTotal  money.Money
+		wantErr    error
+		wantReason string
+	}{
+		{
+			name:      "price times quantity",
+			items:     []models.OrderItem{item("p-1", money.New(1999, "USD"), 3)},
+			wantItems: []money.Money{money.New(5997, "USD")},
+			wantTotal: money.New(5997, "USD"),
+		},
+		{
+			name: "items summed exactly",
+			items: []models.OrderItem{
+				item("p-1", money.New(10, "USD"), 7),
+				item("p-2", money.New(10, "USD"), 3),
+				item("p-3", money.New(1, "USD"), 1),
+			},
+			wantItems: []money.Money{money.New(70, "USD"), money.New(30, "USD"), money.New(1, "USD")},
+			wantTotal: money.New(101, "USD"),
+		},
+		{
+			name:      "currency without minor units",
+			items:     []models.OrderItem{item("p-1", money.New(1500, "JPY"), 2)},
+			wantItems: []money.Money{money.New(3000, "JPY")},
+			wantTotal: money.New(3000, "JPY"),
+		},
+		{
+			name: "mixed currencies",
+			items: []models.OrderItem{
+				item("p-1", money.New(1999, "USD"), 1),
+				item("p-2", money.New(1999, "EUR"), 1),
+			},
+			wantReason: "priced in EUR, not the order's USD",
+		},
+		{
+			name:    "item total overflows",
+			items:   []models.OrderItem{item("p-1", money.New(math.MaxInt64/2, "USD"), 3)},
+			wantErr: money.ErrInvalidAmount,
+		},
+		{
+			name: "order total overflows",
+			items: []models.OrderItem{
+				item("p-1", money.New(math.MaxInt64/2, "USD"), 1),
+				item("p-2", money.New(math.MaxInt64/2, "USD"), 1),
+				item("p-3", money.New(2, "USD"), 1),
+			},
+			wantErr: money.ErrInvalidAmount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{Items: tt.items}
+			err := totalOrder(order)
+			if tt.wantReason != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantReason) {
+					t.Fatalf("totalOrder() error = %v, want %q", err, tt.wantReason)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("totalOrder() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			for i, want := range tt.wantItems {
+				if got := order.Items[i].TotalPrice; got != want {
+					t.Errorf("item %d total = %v, want %v", i, got, want)
+				}
+			}
+			if order.TotalAmount != tt.wantTotal {
+				t.Errorf("order total = %v, want %v", order.TotalAmount, tt.wantTotal)
+			}
+		})
+	}
+}
+
+// fakeProducts serves fixed products from product-service
+type fakeProducts struct {
+	pb.ProductServiceClient
+	products map[string]*pb.Product
+}
+
+func (f *fakeProducts) GetProductByID(ctx context.Context, in *pb.GetProductByIDRequest, opts ...grpc.CallOption) (*pb.GetProductByIDResponse, error) {
+	product, ok := f.products[in.Id]
+	if !ok {
+		return nil, errors.New("product not found")
+	}
+	return &pb.GetProductByIDResponse{Product: product}, nil
+}
+
+// fakeOrders records the orders created through it
+type fakeOrders struct {
+	repository.OrderRepository
+	created []*models.Order
+}
+
+func (f *fakeOrders) CreateOrder(ctx context.Context, order *models.Order) (uint, error) {
+	f.created = append(f.created, order)
+	return uint(len(f.created)), nil
+}
+
+func TestCreateOrderRefusesUnpricedTotals(t *testing.T) {
+	products := &fakeProducts{products: map[string]*pb.Product{
+		"shirt": {Id: "shirt", Stock: 10, EffectivePrice: &pb.Money{Amount: 1999, Currency: "USD"}},
+		"hat":   {Id: "hat", Stock: 10, EffectivePrice: &pb.Money{Amount: 1500, Currency: "EUR"}},
+		"yacht": {Id: "yacht", Stock: 10, EffectivePrice: &pb.Money{Amount: math.MaxInt64 / 2, Currency: "USD"}},
+	}}
+
+	tests := []struct {
+		name  string
+		items []models.OrderItem
+	}{
+		{name: "mixed currencies", items: []models.OrderItem{{ProductID: "shirt", Quantity: 1}, {ProductID: "hat", Quantity: 1}}},
+		{name: "total overflows", items: []models.OrderItem{{ProductID: "yacht", Quantity: 3}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &fakeOrders{}
+			u := NewOrderUsecase(orders, products)
+
+			if _, err := u.CreateOrder(context.Background(), &models.Order{UserID: "alice", Items: tt.items}, ""); err == nil {
+				t.Fatal("CreateOrder() succeeded, want an error")
+			}
+			if len(orders.created) != 0 {
+				t.Errorf("CreateOrder() stored an order that could not be totalled")
+			}
+		})
+	}
+}
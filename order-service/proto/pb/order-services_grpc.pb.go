@@ -19,12 +19,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	OrderService_CreateOrder_FullMethodName         = "/order.OrderService/CreateOrder"
-	OrderService_GetOrderByID_FullMethodName        = "/order.OrderService/GetOrderByID"
-	OrderService_UpdateOrderStatus_FullMethodName   = "/order.OrderService/UpdateOrderStatus"
-	OrderService_UpdatePaymentStatus_FullMethodName = "/order.OrderService/UpdatePaymentStatus"
-	OrderService_DeleteOrder_FullMethodName         = "/order.OrderService/DeleteOrder"
-	OrderService_GetAllOrders_FullMethodName        = "/order.OrderService/GetAllOrders"
+	OrderService_CreateOrder_FullMethodName           = "/order.OrderService/CreateOrder"
+	OrderService_GetOrderByID_FullMethodName          = "/order.OrderService/GetOrderByID"
+	OrderService_UpdateOrderStatus_FullMethodName     = "/order.OrderService/UpdateOrderStatus"
+	OrderService_UpdatePaymentStatus_FullMethodName   = "/order.OrderService/UpdatePaymentStatus"
+	OrderService_DeleteOrder_FullMethodName           = "/order.OrderService/DeleteOrder"
+	OrderService_GetAllOrders_FullMethodName          = "/order.OrderService/GetAllOrders"
+	OrderService_GetReferencedProducts_FullMethodName = "/order.OrderService/GetReferencedProducts"
 )
 
 // OrderServiceClient is the client API for OrderService service.
@@ -45,6 +46,8 @@ type OrderServiceClient interface {
 	DeleteOrder(ctx context.Context, in *DeleteOrderRequest, opts ...grpc.CallOption) (*DeleteOrderResponse, error)
 	// Get all orders for a specific user
 	GetAllOrders(ctx context.Context, in *GetAllOrdersRequest, opts ...grpc.CallOption) (*GetAllOrdersResponse, error)
+	// Get which of the given products appear in any order
+	GetReferencedProducts(ctx context.Context, in *GetReferencedProductsRequest, opts ...grpc.CallOption) (*GetReferencedProductsResponse, error)
 }
 
 type orderServiceClient struct {
@@ -115,6 +118,16 @@ func (c *orderServiceClient) GetAllOrders(ctx context.Context, in *GetAllOrdersR
 	return out, nil
 }
 
+func (c *orderServiceClient) GetReferencedProducts(ctx context.Context, in *GetReferencedProductsRequest, opts ...grpc.CallOption) (*GetReferencedProductsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetReferencedProductsResponse)
+	err := c.cc.Invoke(ctx, OrderService_GetReferencedProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // OrderServiceServer is the server API for OrderService service.
 // All implementations must embed UnimplementedOrderServiceServer
 // for forward compatibility.
@@ -133,6 +146,8 @@ type OrderServiceServer interface {
 	DeleteOrder(context.Context, *DeleteOrderRequest) (*DeleteOrderResponse, error)
 	// Get all orders for a specific user
 	GetAllOrders(context.Context, *GetAllOrdersRequest) (*GetAllOrdersResponse, error)
+	// Get which of the given products appear in any order
+	GetReferencedProducts(context.Context, *GetReferencedProductsRequest) (*GetReferencedProductsResponse, error)
 	mustEmbedUnimplementedOrderServiceServer()
 }
 
@@ -161,6 +176,9 @@ func (UnimplementedOrderServiceServer) DeleteOrder(context.Context, *DeleteOrder
 func (UnimplementedOrderServiceServer) GetAllOrders(context.Context, *GetAllOrdersRequest) (*GetAllOrdersResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllOrders not implemented")
 }
+func (UnimplementedOrderServiceServer) GetReferencedProducts(context.Context, *GetReferencedProductsRequest) (*GetReferencedProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReferencedProducts not implemented")
+}
 func (UnimplementedOrderServiceServer) mustEmbedUnimplementedOrderServiceServer() {}
 func (UnimplementedOrderServiceServer) testEmbeddedByValue()                      {}
 
@@ -290,6 +308,24 @@ func _OrderService_GetAllOrders_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrderService_GetReferencedProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReferencedProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetReferencedProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_GetReferencedProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetReferencedProducts(ctx, req.(*GetReferencedProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // OrderService_ServiceDesc is the grpc.ServiceDesc for OrderService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -321,6 +357,10 @@ var OrderService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAllOrders",
 			Handler:    _OrderService_GetAllOrders_Handler,
 		},
+		{
+			MethodName: "GetReferencedProducts",
+			Handler:    _OrderService_GetReferencedProducts_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/order-services.proto",
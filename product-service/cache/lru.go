@@ -0,0 +1,79 @@
+package cache
+
+import (
+    "container/list"
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// entry is a cached product and when it stops being served
+type entry struct {
+    id        string
+    product   *models.Product
+    expiresAt time.Time
+}
+
+// lru holds up to size products for ttl each, evicting the least recently
+// used first. It is not safe for concurrent use.
+type lru struct {
+    size    int
+    ttl     time.Duration
+    order   *list.List // front is the most recently used
+    entries map[string]*list.Element
+}
+
+func newLRU(size int, ttl time.Duration) *lru {
+    return &lru{size: size, ttl: ttl, order: list.New(), entries: make(map[string]*list.Element, size)}
+}
+
+// get returns the product cached under id unless it has expired
+func (c *lru) get(id string, now time.Time) (*models.Product, bool) {
+    elem, ok := c.entries[id]
+    if !ok {
+        return nil, false
+    }
+    e := elem.Value.(*entry)
+    if !now.Before(e.expiresAt) {
+        c.removeElement(elem)
+        return nil, false
+    }
+    c.order.MoveToFront(elem)
+    return e.product, true
+}
+
+// add caches a product and reports whether another one was evicted to make room
+func (c *lru) add(product *models.Product, now time.Time) bool {
+    if elem, ok := c.entries[product.ID]; ok {
+        e := elem.Value.(*entry)
+        e.product, e.expiresAt = product, now.Add(c.ttl)
+        c.order.MoveToFront(elem)
+        return false
+    }
+    c.entries[product.ID] = c.order.PushFront(&entry{id: product.ID, product: product, expiresAt: now.Add(c.ttl)})
+    if c.order.Len() <= c.size {
+        return false
+    }
+    c.removeElement(c.order.Back())
+    return true
+}
+
+func (c *lru) remove(id string) {
+    if elem, ok := c.entries[id]; ok {
+        c.removeElement(elem)
+    }
+}
+
+func (c *lru) purge() {
+    c.order.Init()
+    c.entries = make(map[string]*list.Element, c.size)
+}
+
+func (c *lru) len() int {
+    return c.order.Len()
+}
+
+func (c *lru) removeElement(elem *list.Element) {
+    c.order.Remove(elem)
+    delete(c.entries, elem.Value.(*entry).id)
+}
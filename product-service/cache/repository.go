@@ -0,0 +1,266 @@
+// Package cache keeps recently read products in memory in front of a
+// repository.
+package cache
+
+import (
+    "context"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "golang.org/x/sync/singleflight"
+
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+// Store is the repository a Repository reads through to: the product
+// repository, plus those whose writes also change a product
+type Store interface {
+    repository.ProductRepository
+    repository.ReservationRepository
+    repository.StockLedgerRepository
+    repository.CategoryRepository
+    repository.ReviewRepository
+}
+
+// Stats counts the lookups made through a Repository
+type Stats struct {
+    Hits          uint64 `json:"hits"`
+    Misses        uint64 `json:"misses"`
+    Evictions     uint64 `json:"evictions"`
+    Invalidations uint64 `json:"invalidations"`
+    Size          int    `json:"size"`
+}
+
+// Repository serves GetProductByID from memory and passes everything else
+// through to its Store. Up to size products are kept for ttl each, the least
+// recently used evicted first, and concurrent misses of the same product share
+// one read. Every write that changes a product drops it from the cache; writes
+// made by other replicas are only seen once ttl has passed, unless they are
+// passed to Invalidate.
+type Repository struct {
+    Store
+
+    now   func() time.Time
+    group singleflight.Group
+
+    mu      sync.Mutex
+    entries *lru
+    // generation is incremented by every invalidation, so a read that started
+    // before one is not cached
+    generation uint64
+
+    hits, misses, evictions, invalidations atomic.Uint64
+}
+
+// loadTimeout bounds a read shared by concurrent misses, which runs on past
+// the caller that started it
+const loadTimeout = 10 * time.Second
+
+// NewRepository creates a Repository caching up to size products of store for ttl
+func NewRepository(store Store, size int, ttl time.Duration) *Repository {
+    return &Repository{Store: store, now: time.Now, entries: newLRU(size, ttl)}
+}
+
+// GetProductByID returns a copy of the cached product, reading it from the
+// store on a miss. A caller whose ctx is done stops waiting for the read,
+// which carries on for the others sharing it.
+func (r *Repository) GetProductByID(ctx context.Context, id string) (*models.Product, error) {
+    r.mu.Lock()
+    product, ok := r.entries.get(id, r.now())
+    r.mu.Unlock()
+    if ok {
+        r.hits.Add(1)
+        return clone(product), nil
+    }
+    r.misses.Add(1)
+
+    loaded := r.group.DoChan(id, func() (interface{}, error) {
+        // keep the values of the first caller's ctx, but not its cancellation
+        ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+        defer cancel()
+
+        r.mu.Lock()
+        generation := r.generation
+        r.mu.Unlock()
+
+        product, err := r.Store.GetProductByID(ctx, id)
+        if err != nil {
+            return nil, err
+        }
+        r.mu.Lock()
+        if r.generation == generation && r.entries.add(product, r.now()) {
+            r.evictions.Add(1)
+        }
+        r.mu.Unlock()
+        return product, nil
+    })
+    select {
+    case <-ctx.Done():
+        return nil, ctx.Err()
+    case result := <-loaded:
+        if result.Err != nil {
+            return nil, result.Err
+        }
+        return clone(result.Val.(*models.Product)), nil
+    }
+}
+
+// Invalidate drops products from the cache, so they are read from the store next time
+func (r *Repository) Invalidate(ids ...string) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.generation++
+    for _, id := range ids {
+        r.entries.remove(id)
+    }
+    r.invalidations.Add(uint64(len(ids)))
+}
+
+// Purge drops every product from the cache
+func (r *Repository) Purge() {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.generation++
+    r.invalidations.Add(uint64(r.entries.len()))
+    r.entries.purge()
+}
+
+// Stats returns the lookups counted so far and the number of cached products
+func (r *Repository) Stats() Stats {
+    r.mu.Lock()
+    size := r.entries.len()
+    r.mu.Unlock()
+    return Stats{
+        Hits:          r.hits.Load(),
+        Misses:        r.misses.Load(),
+        Evictions:     r.evictions.Load(),
+        Invalidations: r.invalidations.Load(),
+        Size:          size,
+    }
+}
+
+// clone copies a product, so callers that change what they read, such as
+// when prices are applied, do not change the cached product
+func clone(product *models.Product) *models.Product {
+    c := *product
+    c.Attributes.Size = append([]string(nil), product.Attributes.Size...)
+    c.Images = append([]string(nil), product.Images...)
+    if product.Translations != nil {
+        c.Translations = make(models.Translations, len(product.Translations))
+        for locale, t := range product.Translations {
+            c.Translations[locale] = t
+        }
+    }
+    if product.SalePrice != nil {
+        price := *product.SalePrice
+        c.SalePrice = &price
+    }
+    if product.DeletedAt != nil {
+        at := *product.DeletedAt
+        c.DeletedAt = &at
+    }
+    return &c
+}
+
+// The writes below change products; each drops them from the cache once it
+// returns, whether or not it succeeded, as a failed write may have been
+// partly applied.
+
+func (r *Repository) UpdateProduct(ctx context.Context, id string, updatedProduct *models.Product, mask models.ProductMask) error {
+    defer r.Invalidate(id)
+    return r.Store.UpdateProduct(ctx, id, updatedProduct, mask)
+}
+
+func (r *Repository) ArchiveProduct(ctx context.Context, id string, at time.Time, version int64) error {
+    defer r.Invalidate(id)
+    return r.Store.ArchiveProduct(ctx, id, at, version)
+}
+
+func (r *Repository) RestoreProduct(ctx context.Context, id string) error {
+    defer r.Invalidate(id)
+    return r.Store.RestoreProduct(ctx, id)
+}
+
+func (r *Repository) PurgeProduct(ctx context.Context, id string) (bool, error) {
+    defer r.Invalidate(id)
+    return r.Store.PurgeProduct(ctx, id)
+}
+
+func (r *Repository) UpdateStock(ctx context.Context, id string, quantity int, change models.StockChange, version int64) error {
+    defer r.Invalidate(id)
+    return r.Store.UpdateStock(ctx, id, quantity, change, version)
+}
+
+func (r *Repository) ImportProducts(ctx context.Context, created, replaced []*models.Product) error {
+    ids := make([]string, 0, len(created)+len(replaced))
+    for _, product := range created {
+        ids = append(ids, product.ID)
+    }
+    for _, product := range replaced {
+        ids = append(ids, product.ID)
+    }
+    defer r.Invalidate(ids...)
+    return r.Store.ImportProducts(ctx, created, replaced)
+}
+
+func (r *Repository) ReserveStock(ctx context.Context, reservation *models.Reservation) error {
+    defer r.invalidateItems(reservation)
+    return r.Store.ReserveStock(ctx, reservation)
+}
+
+func (r *Repository) ReleaseReservation(ctx context.Context, id string, status models.ReservationStatus) (*models.Reservation, error) {
+    reservation, err := r.Store.GetReservation(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    defer r.invalidateItems(reservation)
+    return r.Store.ReleaseReservation(ctx, id, status)
+}
+
+func (r *Repository) invalidateItems(reservation *models.Reservation) {
+    ids := make([]string, 0, len(reservation.Items))
+    for _, item := range reservation.Items {
+        ids = append(ids, item.ProductID)
+    }
+    r.Invalidate(ids...)
+}
+
+func (r *Repository) AdjustStock(ctx context.Context, movement *models.StockMovement) error {
+    defer r.Invalidate(movement.ProductID)
+    return r.Store.AdjustStock(ctx, movement)
+}
+
+// UpdateCategory copies the category name to every product filed under it, so
+// the whole cache is dropped
+func (r *Repository) UpdateCategory(ctx context.Context, id string, category *models.Category) error {
+    defer r.Purge()
+    return r.Store.UpdateCategory(ctx, id, category)
+}
+
+func (r *Repository) CreateReview(ctx context.Context, review *models.Review) error {
+    defer r.Invalidate(review.ProductID)
+    return r.Store.CreateReview(ctx, review)
+}
+
+func (r *Repository) UpdateReview(ctx context.Context, id string, review *models.Review) error {
+    defer r.invalidateReviewed(ctx, id)()
+    return r.Store.UpdateReview(ctx, id, review)
+}
+
+func (r *Repository) DeleteReview(ctx context.Context, id string) error {
+    defer r.invalidateReviewed(ctx, id)()
+    return r.Store.DeleteReview(ctx, id)
+}
+
+// invalidateReviewed looks up the product of a review before it is written
+// and returns a func that drops the product from the cache, or the whole
+// cache when the review cannot be read
+func (r *Repository) invalidateReviewed(ctx context.Context, reviewID string) func() {
+    review, err := r.Store.GetReview(ctx, reviewID)
+    if err != nil {
+        return r.Purge
+    }
+    return func() { r.Invalidate(review.ProductID) }
+}
@@ -0,0 +1,242 @@
+package cache
+
+import (
+    "context"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+// fakeStore counts product reads; the embedded Store is nil, so any other
+// method it is asked for panics
+type fakeStore struct {
+    Store
+    reads   atomic.Int32
+    started chan struct{} // when set, receives each read as it starts
+    release chan struct{} // when set, reads wait for it to be closed
+    aborted atomic.Bool   // set when a read's ctx is done once it is released
+}
+
+func (s *fakeStore) GetProductByID(ctx context.Context, id string) (*models.Product, error) {
+    s.reads.Add(1)
+    if s.started != nil {
+        s.started <- struct{}{}
+    }
+    if s.release != nil {
+        <-s.release
+    }
+    if ctx.Err() != nil {
+        s.aborted.Store(true)
+        return nil, ctx.Err()
+    }
+    if id == "missing" {
+        return nil, repository.ErrProductNotFound
+    }
+    return &models.Product{ID: id, Title: "Title " + id, Images: []string{"a.jpg"}}, nil
+}
+
+func (s *fakeStore) UpdateProduct(ctx context.Context, id string, updatedProduct *models.Product, mask models.ProductMask) error {
+    return nil
+}
+
+// clock is a settable time source
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time { return c.now }
+
+func newTestRepository(store *fakeStore, size int) (*Repository, *clock) {
+    c := &clock{now: time.Unix(0, 0)}
+    r := NewRepository(store, size, time.Minute)
+    r.now = c.Now
+    return r, c
+}
+
+func TestGetProductByIDServesHits(t *testing.T) {
+    store := &fakeStore{}
+    r, _ := newTestRepository(store, 10)
+    ctx := context.Background()
+
+    first, err := r.GetProductByID(ctx, "p-1")
+    if err != nil {
+        t.Fatalf("GetProductByID() error = %v", err)
+    }
+    // callers may change what they read without changing the cached product
+    first.Title = "changed"
+    first.Images[0] = "changed.jpg"
+
+    second, err := r.GetProductByID(ctx, "p-1")
+    if err != nil {
+        t.Fatalf("GetProductByID() error = %v", err)
+    }
+    if second.Title != "Title p-1" || second.Images[0] != "a.jpg" {
+        t.Errorf("cached product was changed through a read: %+v", second)
+    }
+    if reads := store.reads.Load(); reads != 1 {
+        t.Errorf("store read %d times, want 1", reads)
+    }
+    if stats := r.Stats(); stats.Hits != 1 || stats.Misses != 1 || stats.Size != 1 {
+        t.Errorf("Stats() = %+v, want 1 hit, 1 miss and 1 product", stats)
+    }
+}
+
+func TestGetProductByIDDoesNotCacheErrors(t *testing.T) {
+    store := &fakeStore{}
+    r, _ := newTestRepository(store, 10)
+
+    for i := 0; i < 2; i++ {
+        if _, err := r.GetProductByID(context.Background(), "missing"); err != repository.ErrProductNotFound {
+            t.Fatalf("GetProductByID() error = %v, want ErrProductNotFound", err)
+        }
+    }
+    if reads := store.reads.Load(); reads != 2 {
+        t.Errorf("store read %d times, want 2", reads)
+    }
+}
+
+func TestGetProductByIDExpiresAndEvicts(t *testing.T) {
+    tests := []struct {
+        name     string
+        size     int
+        advance  time.Duration
+        others   []string
+        want     int32
+        evicting bool
+    }{
+        {"fresh", 2, 59 * time.Second, nil, 1, false},
+        {"expired", 2, time.Minute, nil, 2, false},
+        {"recently used", 2, 0, []string{"p-2"}, 2, false},
+        {"least recently used", 2, 0, []string{"p-2", "p-3"}, 4, true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            store := &fakeStore{}
+            r, c := newTestRepository(store, tt.size)
+            ctx := context.Background()
+
+            r.GetProductByID(ctx, "p-1")
+            for _, id := range tt.others {
+                r.GetProductByID(ctx, id)
+            }
+            c.now = c.now.Add(tt.advance)
+            r.GetProductByID(ctx, "p-1")
+
+            if reads := store.reads.Load(); reads != tt.want {
+                t.Errorf("store read %d times, want %d", reads, tt.want)
+            }
+            if evicted := r.Stats().Evictions > 0; evicted != tt.evicting {
+                t.Errorf("evicted = %t, want %t", evicted, tt.evicting)
+            }
+        })
+    }
+}
+
+func TestGetProductByIDSharesConcurrentMisses(t *testing.T) {
+    store := &fakeStore{release: make(chan struct{})}
+    r, _ := newTestRepository(store, 10)
+
+    var wg sync.WaitGroup
+    for i := 0; i < 5; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            if _, err := r.GetProductByID(context.Background(), "p-1"); err != nil {
+                t.Errorf("GetProductByID() error = %v", err)
+            }
+        }()
+    }
+    // give the readers time to join the read in flight
+    for r.Stats().Misses < 5 {
+        time.Sleep(time.Millisecond)
+    }
+    time.Sleep(10 * time.Millisecond)
+    close(store.release)
+    wg.Wait()
+
+    if reads := store.reads.Load(); reads != 1 {
+        t.Errorf("store read %d times, want 1", reads)
+    }
+}
+
+func TestGetProductByIDOutlivesCallerThatStartedRead(t *testing.T) {
+    store := &fakeStore{started: make(chan struct{}, 1), release: make(chan struct{})}
+    r, _ := newTestRepository(store, 10)
+
+    first, cancel := context.WithCancel(context.Background())
+    firstErr := make(chan error, 1)
+    go func() {
+        _, err := r.GetProductByID(first, "p-1")
+        firstErr <- err
+    }()
+    <-store.started
+
+    second := make(chan error, 1)
+    go func() {
+        _, err := r.GetProductByID(context.Background(), "p-1")
+        second <- err
+    }()
+    for r.Stats().Misses < 2 {
+        time.Sleep(time.Millisecond)
+    }
+
+    // the caller that started the read gives up without waiting for it
+    cancel()
+    if err := <-firstErr; err != context.Canceled {
+        t.Errorf("GetProductByID() of the canceled caller error = %v, want %v", err, context.Canceled)
+    }
+    close(store.release)
+    if err := <-second; err != nil {
+        t.Errorf("GetProductByID() of the caller still waiting error = %v", err)
+    }
+    if store.aborted.Load() {
+        t.Error("the shared read was canceled with the caller that started it")
+    }
+    if reads := store.reads.Load(); reads != 1 {
+        t.Errorf("store read %d times, want 1", reads)
+    }
+}
+
+func TestWritesInvalidate(t *testing.T) {
+    store := &fakeStore{}
+    r, _ := newTestRepository(store, 10)
+    ctx := context.Background()
+
+    r.GetProductByID(ctx, "p-1")
+    r.GetProductByID(ctx, "p-2")
+    if err := r.UpdateProduct(ctx, "p-1", &models.Product{}, models.FullProductMask()); err != nil {
+        t.Fatalf("UpdateProduct() error = %v", err)
+    }
+    r.GetProductByID(ctx, "p-1")
+    r.GetProductByID(ctx, "p-2")
+
+    // p-1 is read again after its update, p-2 is still cached
+    if reads := store.reads.Load(); reads != 3 {
+        t.Errorf("store read %d times, want 3", reads)
+    }
+    if stats := r.Stats(); stats.Invalidations != 1 {
+        t.Errorf("Stats().Invalidations = %d, want 1", stats.Invalidations)
+    }
+}
+
+func TestInvalidateDuringReadSkipsCaching(t *testing.T) {
+    store := &fakeStore{started: make(chan struct{}), release: make(chan struct{})}
+    r, _ := newTestRepository(store, 10)
+
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        r.GetProductByID(context.Background(), "p-1")
+    }()
+    <-store.started
+    // a write lands while the read is in flight, so what it read may be stale
+    r.Invalidate("p-1")
+    close(store.release)
+    <-done
+
+    if size := r.Stats().Size; size != 0 {
+        t.Errorf("cached %d products read before an invalidation, want 0", size)
+    }
+}
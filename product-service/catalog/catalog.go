@@ -0,0 +1,351 @@
+// Package catalog reads and writes products as CSV or JSON Lines files for
+// bulk imports and exports.
+package catalog
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/csv"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+)
+
+// Format is the file format of an import or export
+type Format string
+
+const (
+    // CSV has a header row naming the columns; sizes and images are separated by "|"
+    CSV Format = "csv"
+    // JSONLines has one JSON object per line
+    JSONLines Format = "jsonl"
+)
+
+// Columns are the CSV columns, in the order they are exported
+var Columns = []string{"id", "sku", "title", "description", "price", "currency", "stock", "category", "category_id", "color", "sizes", "images"}
+
+// requiredColumns must appear in the header of a CSV import
+var requiredColumns = []string{"title", "price"}
+
+// listSeparator separates the sizes and images in a CSV cell
+const listSeparator = "|"
+
+// ParseFormat parses a format name as used on the command line
+func ParseFormat(name string) (Format, error) {
+    switch Format(strings.ToLower(name)) {
+    case CSV:
+        return CSV, nil
+    case JSONLines, "jsonlines", "ndjson":
+        return JSONLines, nil
+    }
+    return "", fmt.Errorf("unknown catalog format %q, expected %q or %q", name, CSV, JSONLines)
+}
+
+// RowError reports a row that could not be read. Reading continues with the
+// next row.
+type RowError struct {
+    Line int    // Line the row starts on, counting from one
+    ID   string // Product ID of the row, if it could be read
+    SKU  string // SKU of the row, if it could be read
+    Err  error
+}
+
+func (e *RowError) Error() string {
+    return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+    return e.Err
+}
+
+// Reader reads products one row at a time
+type Reader interface {
+    // Read returns the next product and the line it starts on, a *RowError
+    // for a malformed row or io.EOF after the last row. Any other error ends
+    // the file.
+    Read() (*models.Product, int, error)
+}
+
+// Writer writes products one row at a time
+type Writer interface {
+    Write(product *models.Product) error
+    // Flush writes any buffered rows to the underlying writer
+    Flush() error
+}
+
+// record is the flat form of a product in a catalog file. Prices are decimals
+// in major units, such as 12.50; a row without a currency is in
+// models.DefaultCurrency.
+type record struct {
+    ID          string      `json:"id,omitempty"`
+    SKU         string      `json:"sku,omitempty"`
+    Title       string      `json:"title"`
+    Description string      `json:"description,omitempty"`
+    Price       json.Number `json:"price"`
+    Currency    string      `json:"currency,omitempty"`
+    Stock       int         `json:"stock"`
+    Category    string      `json:"category,omitempty"`
+    CategoryID  string      `json:"category_id,omitempty"`
+    Color       string      `json:"color,omitempty"`
+    Sizes       []string    `json:"sizes,omitempty"`
+    Images      []string    `json:"images,omitempty"`
+}
+
+func toRecord(p *models.Product) record {
+    return record{
+        ID:          p.ID,
+        SKU:         p.SKU,
+        Title:       p.Title,
+        Description: p.Description,
+        Price:       json.Number(p.Price.Decimal()),
+        Currency:    p.Price.Currency,
+        Stock:       p.Stock,
+        Category:    p.Category,
+        CategoryID:  p.CategoryID,
+        Color:       p.Attributes.Color,
+        Sizes:       p.Attributes.Size,
+        Images:      p.Images,
+    }
+}
+
+func (r record) product() (*models.Product, error) {
+    currency := strings.ToUpper(strings.TrimSpace(r.Currency))
+    if currency == "" {
+        currency = models.DefaultCurrency
+    }
+    price, err := money.Parse(r.Price.String(), currency)
+    if err != nil {
+        return nil, fmt.Errorf("invalid price: %w", err)
+    }
+    return &models.Product{
+        ID:          strings.TrimSpace(r.ID),
+        SKU:         strings.TrimSpace(r.SKU),
+        Title:       strings.TrimSpace(r.Title),
+        Description: r.Description,
+        Price:       price,
+        Stock:       r.Stock,
+        Category:    strings.TrimSpace(r.Category),
+        CategoryID:  strings.TrimSpace(r.CategoryID),
+        Attributes:  models.ProductAttributes{Color: strings.TrimSpace(r.Color), Size: r.Sizes},
+        Images:      r.Images,
+    }, nil
+}
+
+// NewReader returns a Reader of products in the given format. A CSV file must
+// start with a header row naming at least the title and price columns.
+func NewReader(r io.Reader, format Format) (Reader, error) {
+    switch format {
+    case CSV:
+        return newCSVReader(r)
+    case JSONLines:
+        return &jsonReader{r: bufio.NewReader(r)}, nil
+    }
+    return nil, fmt.Errorf("unknown catalog format %q", format)
+}
+
+// NewWriter returns a Writer of products in the given format. A CSV file
+// starts with a header row.
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+    switch format {
+    case CSV:
+        cw := csv.NewWriter(w)
+        if err := cw.Write(Columns); err != nil {
+            return nil, err
+        }
+        return &csvWriter{w: cw}, nil
+    case JSONLines:
+        bw := bufio.NewWriter(w)
+        return &jsonWriter{w: bw, enc: json.NewEncoder(bw)}, nil
+    }
+    return nil, fmt.Errorf("unknown catalog format %q", format)
+}
+
+type csvReader struct {
+    r       *csv.Reader
+    columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+    cr := csv.NewReader(r)
+    cr.FieldsPerRecord = -1
+    cr.TrimLeadingSpace = true
+
+    header, err := cr.Read()
+    if err == io.EOF {
+        return nil, errors.New("catalog file is empty")
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to read header: %w", err)
+    }
+
+    columns := make(map[string]int, len(header))
+    for i, name := range header {
+        name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+        if !knownColumn(name) {
+            return nil, fmt.Errorf("unknown column %q", name)
+        }
+        if _, ok := columns[name]; ok {
+            return nil, fmt.Errorf("duplicate column %q", name)
+        }
+        columns[name] = i
+    }
+    for _, name := range requiredColumns {
+        if _, ok := columns[name]; !ok {
+            return nil, fmt.Errorf("missing required column %q", name)
+        }
+    }
+    return &csvReader{r: cr, columns: columns}, nil
+}
+
+func (c *csvReader) Read() (*models.Product, int, error) {
+    fields, err := c.r.Read()
+    if err == io.EOF {
+        return nil, 0, io.EOF
+    }
+    var parseErr *csv.ParseError
+    if errors.As(err, &parseErr) {
+        return nil, parseErr.StartLine, &RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+    }
+    if err != nil {
+        return nil, 0, err
+    }
+
+    line, _ := c.r.FieldPos(0)
+    field := func(name string) string {
+        if i, ok := c.columns[name]; ok && i < len(fields) {
+            return fields[i]
+        }
+        return ""
+    }
+    rowErr := func(err error) error {
+        return &RowError{Line: line, ID: strings.TrimSpace(field("id")), SKU: strings.TrimSpace(field("sku")), Err: err}
+    }
+    if len(fields) != len(c.columns) {
+        return nil, line, rowErr(fmt.Errorf("expected %d fields, got %d", len(c.columns), len(fields)))
+    }
+
+    rec := record{
+        ID:          field("id"),
+        SKU:         field("sku"),
+        Title:       field("title"),
+        Description: field("description"),
+        Price:       json.Number(field("price")),
+        Currency:    field("currency"),
+        Category:    field("category"),
+        CategoryID:  field("category_id"),
+        Color:       field("color"),
+        Sizes:       splitList(field("sizes")),
+        Images:      splitList(field("images")),
+    }
+    if stock := strings.TrimSpace(field("stock")); stock != "" {
+        if rec.Stock, err = strconv.Atoi(stock); err != nil {
+            return nil, line, rowErr(fmt.Errorf("invalid stock %q", stock))
+        }
+    }
+    product, err := rec.product()
+    if err != nil {
+        return nil, line, rowErr(err)
+    }
+    return product, line, nil
+}
+
+type jsonReader struct {
+    r    *bufio.Reader
+    line int
+}
+
+func (j *jsonReader) Read() (*models.Product, int, error) {
+    for {
+        data, err := j.r.ReadBytes('\n')
+        if err != nil && err != io.EOF {
+            return nil, 0, err
+        }
+        if len(data) == 0 && err == io.EOF {
+            return nil, 0, io.EOF
+        }
+        j.line++
+
+        data = bytes.TrimSpace(data)
+        if len(data) == 0 {
+            continue
+        }
+
+        var rec record
+        dec := json.NewDecoder(bytes.NewReader(data))
+        dec.DisallowUnknownFields()
+        if err := dec.Decode(&rec); err != nil {
+            return nil, j.line, &RowError{Line: j.line, ID: rec.ID, SKU: rec.SKU, Err: err}
+        }
+        product, err := rec.product()
+        if err != nil {
+            return nil, j.line, &RowError{Line: j.line, ID: rec.ID, SKU: rec.SKU, Err: err}
+        }
+        return product, j.line, nil
+    }
+}
+
+type csvWriter struct {
+    w *csv.Writer
+}
+
+func (c *csvWriter) Write(product *models.Product) error {
+    rec := toRecord(product)
+    return c.w.Write([]string{
+        rec.ID,
+        rec.SKU,
+        rec.Title,
+        rec.Description,
+        rec.Price.String(),
+        rec.Currency,
+        strconv.Itoa(rec.Stock),
+        rec.Category,
+        rec.CategoryID,
+        rec.Color,
+        strings.Join(rec.Sizes, listSeparator),
+        strings.Join(rec.Images, listSeparator),
+    })
+}
+
+func (c *csvWriter) Flush() error {
+    c.w.Flush()
+    return c.w.Error()
+}
+
+type jsonWriter struct {
+    w   *bufio.Writer
+    enc *json.Encoder
+}
+
+func (j *jsonWriter) Write(product *models.Product) error {
+    return j.enc.Encode(toRecord(product))
+}
+
+func (j *jsonWriter) Flush() error {
+    return j.w.Flush()
+}
+
+// splitList splits a CSV cell into its non-empty, trimmed values
+func splitList(cell string) []string {
+    var values []string
+    for _, v := range strings.Split(cell, listSeparator) {
+        if v = strings.TrimSpace(v); v != "" {
+            values = append(values, v)
+        }
+    }
+    return values
+}
+
+func knownColumn(name string) bool {
+    for _, column := range Columns {
+        if column == name {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,158 @@
+package catalog
+
+import (
+    "bytes"
+    "errors"
+    "io"
+    "reflect"
+    "strings"
+    "testing"
+
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+)
+
+var products = []*models.Product{
+    {ID: "1", SKU: "JKT-1", Title: "Leather Jacket", Description: "A warm jacket, for winter", Price: money.New(17950, "USD"), Stock: 4,
+        Category: "outerwear", CategoryID: "c-1", Attributes: models.ProductAttributes{Color: "black", Size: []string{"M", "L"}},
+        Images: []string{"https://img/1.png", "https://img/2.png"}},
+    {ID: "2", Title: "Running Shoes", Price: money.New(9000, "JPY"), Category: "footwear"},
+}
+
+func TestRoundTrip(t *testing.T) {
+    for _, format := range []Format{CSV, JSONLines} {
+        t.Run(string(format), func(t *testing.T) {
+            var buf bytes.Buffer
+            w, err := NewWriter(&buf, format)
+            if err != nil {
+                t.Fatalf("NewWriter() error = %v", err)
+            }
+            for _, p := range products {
+                if err := w.Write(p); err != nil {
+                    t.Fatalf("Write() error = %v", err)
+                }
+            }
+            if err := w.Flush(); err != nil {
+                t.Fatalf("Flush() error = %v", err)
+            }
+
+            r, err := NewReader(&buf, format)
+            if err != nil {
+                t.Fatalf("NewReader() error = %v", err)
+            }
+            got := readAll(t, r)
+            if !reflect.DeepEqual(got, products) {
+                t.Errorf("read %+v, want %+v", got, products)
+            }
+        })
+    }
+}
+
+func TestReadRowErrors(t *testing.T) {
+    tests := []struct {
+        name      string
+        format    Format
+        input     string
+        wantLines []int
+        wantErrs  []int
+    }{
+        {
+            name:   "csv",
+            format: CSV,
+            input: "title,price,sku,sizes\n" +
+                "Tee,9.99,TEE-1,S|M\n" +
+                "Hat,cheap,HAT-1,\n" +
+                "Cap,5\n" +
+                "Scarf,12,SCF-1,\n",
+            wantLines: []int{2, 5},
+            wantErrs:  []int{3, 4},
+        },
+        {
+            name:   "json lines",
+            format: JSONLines,
+            input: `{"title":"Tee","price":9.99,"sku":"TEE-1"}` + "\n" +
+                `{"title":"Hat","price":"cheap"}` + "\n" +
+                "\n" +
+                `{"title":"Cap","price":5,"colour":"red"}` + "\n" +
+                `{"title":"Scarf","price":12}`,
+            wantLines: []int{1, 5},
+            wantErrs:  []int{2, 4},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            r, err := NewReader(strings.NewReader(tt.input), tt.format)
+            if err != nil {
+                t.Fatalf("NewReader() error = %v", err)
+            }
+
+            var lines, errLines []int
+            for {
+                _, line, err := r.Read()
+                if err == io.EOF {
+                    break
+                }
+                var rowErr *RowError
+                if errors.As(err, &rowErr) {
+                    errLines = append(errLines, rowErr.Line)
+                    continue
+                }
+                if err != nil {
+                    t.Fatalf("Read() error = %v", err)
+                }
+                lines = append(lines, line)
+            }
+            if !reflect.DeepEqual(lines, tt.wantLines) {
+                t.Errorf("read lines %v, want %v", lines, tt.wantLines)
+            }
+            if !reflect.DeepEqual(errLines, tt.wantErrs) {
+                t.Errorf("row errors on lines %v, want %v", errLines, tt.wantErrs)
+            }
+        })
+    }
+}
+
+func TestReadPrices(t *testing.T) {
+    input := "title,price,currency\n" +
+        "Tee,9.99,\n" +
+        "Hat,12.345,eur\n" +
+        "Cap,1500,JPY\n"
+    r, err := NewReader(strings.NewReader(input), CSV)
+    if err != nil {
+        t.Fatalf("NewReader() error = %v", err)
+    }
+    var got []money.Money
+    for _, p := range readAll(t, r) {
+        got = append(got, p.Price)
+    }
+    // a row without a currency is in the default currency, and places beyond
+    // the currency's are rounded
+    want := []money.Money{money.New(999, models.DefaultCurrency), money.New(1235, "EUR"), money.New(1500, "JPY")}
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("read prices %v, want %v", got, want)
+    }
+}
+
+func TestNewReaderRejectsHeader(t *testing.T) {
+    for _, header := range []string{"", "title,cost\n", "title,price,title\n", "sku,price\n"} {
+        if _, err := NewReader(strings.NewReader(header), CSV); err == nil {
+            t.Errorf("NewReader(%q) succeeded, want an error", header)
+        }
+    }
+}
+
+func readAll(t *testing.T, r Reader) []*models.Product {
+    t.Helper()
+    var got []*models.Product
+    for {
+        p, _, err := r.Read()
+        if err == io.EOF {
+            return got
+        }
+        if err != nil {
+            t.Fatalf("Read() error = %v", err)
+        }
+        got = append(got, p)
+    }
+}
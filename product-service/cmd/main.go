@@ -1,14 +1,40 @@
 package main
 
 import (
+    "context"
+    "database/sql"
+    "expvar"
+    "flag"
+    "fmt"
+    "io"
     "log"
     "net"
+    "net/http"
+    "os"
+    "os/signal"
+    "path/filepath"
+    "strings"
+    "syscall"
+    "text/tabwriter"
+    "time"
 
     "google.golang.org/grpc"
+    "google.golang.org/grpc/credentials/insecure"
 
     pb "github.com/samObot19/shopverse/product-service/proto/pb"
+    orderpb "github.com/samObot19/shopverse/product-service/clients/order-client/proto/pb"
+    "github.com/samObot19/shopverse/product-service/cache"
+    "github.com/samObot19/shopverse/product-service/catalog"
     "github.com/samObot19/shopverse/product-service/db"
+    "github.com/samObot19/shopverse/product-service/db/migrations"
+    "github.com/samObot19/shopverse/product-service/events/publish"
+    "github.com/samObot19/shopverse/product-service/events/subscribe"
+    "github.com/samObot19/shopverse/product-service/feed"
+    "github.com/samObot19/shopverse/product-service/media"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/outbox"
     "github.com/samObot19/shopverse/product-service/repository"
+    "github.com/samObot19/shopverse/product-service/search"
     "github.com/samObot19/shopverse/product-service/service"
     "github.com/samObot19/shopverse/product-service/usecases"
 )
@@ -29,10 +55,154 @@ func main() {
 
     log.Println("Connected to MySQL successfully")
 
+    // `product-service migrate up|down|status` manages the schema and exits
+    if len(os.Args) > 1 && os.Args[1] == "migrate" {
+        if err := runMigrate(context.Background(), sqlDB, os.Args[2:]); err != nil {
+            log.Fatalf("Migration failed: %v", err)
+        }
+        return
+    }
+
+    // `product-service reconcile-stock` compares stock levels with the stock
+    // ledger and exits non-zero if any differ
+    if len(os.Args) > 1 && os.Args[1] == "reconcile-stock" {
+        if err := runReconcileStock(context.Background(), repository.NewMySQLProductRepository(sqlDB)); err != nil {
+            log.Fatalf("Stock reconciliation failed: %v", err)
+        }
+        return
+    }
+
+    // Apply any pending migrations before serving
+    migrator, err := migrations.NewMigrator(sqlDB)
+    if err != nil {
+        log.Fatalf("Failed to load migrations: %v", err)
+    }
+    if err := migrator.Up(context.Background()); err != nil {
+        log.Fatalf("Failed to apply migrations: %v", err)
+    }
+
+    // Reviews are checked against the orders held by order-service
+    orderConn, err := grpc.Dial(config.OrderServiceAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+    if err != nil {
+        log.Fatalf("Failed to connect to order service: %v", err)
+    }
+    defer orderConn.Close()
+
     // Initialize repository, use case, and gRPC server
+    // Products are read through an in-memory cache, which every write that
+    // changes a product goes through as well
     productRepo := repository.NewMySQLProductRepository(sqlDB)
-    productUseCase := usecases.NewProductUseCase(productRepo)
-    productServiceServer := service.NewProductServiceServer(productUseCase)
+    cachedRepo := cache.NewRepository(productRepo, config.ProductCacheSize, config.ProductCacheTTL)
+    expvar.Publish("product_cache", expvar.Func(func() interface{} { return cachedRepo.Stats() }))
+
+    // The renditions of uploaded product images are kept on the local disk
+    blobs, err := media.NewDiskStore(config.MediaDir, config.MediaBaseURL)
+    if err != nil {
+        log.Fatalf("Failed to open media store: %v", err)
+    }
+    // Only the stores whose writes change a product go through the cache
+    productUseCase := usecases.NewProductUseCase(usecases.Deps{
+        Repo:            cachedRepo,
+        Variants:        productRepo,
+        Reservations:    cachedRepo,
+        StockChecks:     productRepo,
+        Ledger:          cachedRepo,
+        Warehouses:      productRepo,
+        Categories:      cachedRepo,
+        Reviews:         cachedRepo,
+        Prices:          productRepo,
+        Rates:           productRepo,
+        Images:          productRepo,
+        Blobs:           blobs,
+        Orders:          orderpb.NewOrderServiceClient(orderConn),
+        Index:           search.NewIndex(),
+        SuggestionLimit: config.SuggestionLimit,
+        ReservationTTL:  config.ReservationTTL,
+        Fulfillment:     config.FulfillmentStrategy,
+    })
+
+    // `product-service import|export` move the catalogue in and out of CSV or
+    // JSON Lines files and exit; import exits non-zero if any row failed
+    if len(os.Args) > 1 && os.Args[1] == "import" {
+        if err := runImport(context.Background(), productUseCase, os.Args[2:]); err != nil {
+            log.Fatalf("Import failed: %v", err)
+        }
+        return
+    }
+    if len(os.Args) > 1 && os.Args[1] == "export" {
+        if err := runExport(context.Background(), productUseCase, os.Args[2:]); err != nil {
+            log.Fatalf("Export failed: %v", err)
+        }
+        return
+    }
+
+    if err := productUseCase.RebuildSearchIndex(context.Background()); err != nil {
+        log.Fatalf("Failed to build search index: %v", err)
+    }
+    if config.SearchReindexInterval > 0 {
+        go reindexPeriodically(productUseCase, config.SearchReindexInterval)
+    }
+    go expireReservationsPeriodically(productUseCase, config.ReservationSweepInterval)
+    go purgeArchivedProductsPeriodically(productUseCase, config.ArchiveRetention, config.ArchivePurgeInterval)
+
+    // The background workers and the gRPC server stop on SIGINT or SIGTERM
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    // Domain events are written to the outbox with each change and relayed to Kafka
+    producer, err := publish.NewProducer(config.KafkaBrokers)
+    if err != nil {
+        log.Fatalf("Failed to connect to Kafka: %v", err)
+    }
+    defer producer.Close()
+    go outbox.NewRelay(productRepo, producer, config.ProductEventTopic).Run(ctx, config.OutboxRelayInterval)
+
+    // New orders are answered with whether their stock could be reserved
+    consumerDone := make(chan struct{})
+    go func() {
+        defer close(consumerDone)
+        if err := subscribe.ConsumeOrderCreated(ctx, config.KafkaBrokers, productUseCase, producer); err != nil {
+            log.Printf("Order consumer stopped: %v", err)
+        }
+    }()
+
+    // Product events, including those of other replicas, are kept for
+    // WatchProducts clients and, if enabled, drop changed products from the cache
+    changes := feed.NewLog(config.ProductFeedSize)
+    handlers := []subscribe.ProductEventHandler{changes.Append}
+    if config.ProductCacheInvalidation {
+        handlers = append(handlers, func(event *models.Event, partition int32, offset int64) { cachedRepo.Invalidate(event.ProductID) })
+    }
+    go func() {
+        if err := subscribe.ConsumeProductEvents(ctx, config.KafkaBrokers, config.ProductEventTopic, handlers...); err != nil {
+            log.Printf("Product event consumer stopped: %v", err)
+        }
+    }()
+
+    // Counters such as the cache hits and misses are served at /debug/vars
+    metricsServer := &http.Server{Addr: config.MetricsAddress}
+    go func() {
+        if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+            log.Printf("Metrics server stopped: %v", err)
+        }
+    }()
+    defer metricsServer.Close()
+
+    // Product images are served from the media directory unless another web
+    // server does it
+    if config.MediaAddress != "" {
+        mux := http.NewServeMux()
+        mux.Handle("/media/", http.StripPrefix("/media", mediaHandler(blobs.Root())))
+        mediaServer := &http.Server{Addr: config.MediaAddress, Handler: mux}
+        go func() {
+            if err := mediaServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+                log.Printf("Media server stopped: %v", err)
+            }
+        }()
+        defer mediaServer.Close()
+    }
+
+    productServiceServer := service.NewProductServiceServer(productUseCase, changes)
 
     // Start gRPC server
     listener, err := net.Listen("tcp", ":"+config.GRPCPort)
@@ -40,11 +210,257 @@ func main() {
         log.Fatalf("Failed to listen on port %s: %v", config.GRPCPort, err)
     }
 
-    grpcServer := grpc.NewServer()
+    // Preferences such as the currency prices are read in and the locales
+    // products are described in come as metadata
+    grpcServer := grpc.NewServer(
+        grpc.UnaryInterceptor(service.UnaryMetadataInterceptor),
+        grpc.StreamInterceptor(service.StreamMetadataInterceptor),
+    )
     pb.RegisterProductServiceServer(grpcServer, productServiceServer)
 
+    go func() {
+        <-ctx.Done()
+        log.Println("Shutting down")
+        grpcServer.GracefulStop()
+    }()
+
     log.Printf("gRPC server is running on port %s", config.GRPCPort)
     if err := grpcServer.Serve(listener); err != nil {
         log.Fatalf("Failed to serve gRPC server: %v", err)
     }
-}
\ No newline at end of file
+    // let the order in flight finish before the producer is closed
+    <-consumerDone
+}
+
+// reindexPeriodically rebuilds the search index so it also reflects writes
+// handled by other instances of the service
+func reindexPeriodically(productUseCase *usecases.ProductUseCase, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for range ticker.C {
+        if err := productUseCase.RebuildSearchIndex(context.Background()); err != nil {
+            log.Printf("Failed to rebuild search index: %v", err)
+        }
+    }
+}
+
+// expireReservationsPeriodically gives back the stock of reservations that
+// were neither committed nor released within their TTL
+func expireReservationsPeriodically(productUseCase *usecases.ProductUseCase, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for range ticker.C {
+        expired, err := productUseCase.ExpireReservations(context.Background())
+        if err != nil {
+            log.Printf("Failed to expire reservations: %v", err)
+        }
+        if expired > 0 {
+            log.Printf("Expired %d stock reservations", expired)
+        }
+    }
+}
+
+// purgeArchivedProductsPeriodically deletes the products archived for longer
+// than retention that no order references
+func purgeArchivedProductsPeriodically(productUseCase *usecases.ProductUseCase, retention, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for range ticker.C {
+        purged, err := productUseCase.PurgeArchivedProducts(context.Background(), retention)
+        if err != nil {
+            log.Printf("Failed to purge archived products: %v", err)
+        }
+        if purged > 0 {
+            log.Printf("Purged %d archived products", purged)
+        }
+    }
+}
+
+// mediaHandler serves the renditions kept in dir. Every image gets new keys,
+// so a rendition never changes and can be cached for good; directories are
+// not listed.
+func mediaHandler(dir string) http.Handler {
+    files := http.FileServer(http.Dir(dir))
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if strings.HasSuffix(r.URL.Path, "/") {
+            http.NotFound(w, r)
+            return
+        }
+        w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+        files.ServeHTTP(w, r)
+    })
+}
+
+// runReconcileStock implements `product-service reconcile-stock`
+func runReconcileStock(ctx context.Context, ledger repository.StockLedgerRepository) error {
+    discrepancies, err := ledger.StockDiscrepancies(ctx)
+    if err != nil {
+        return err
+    }
+    if len(discrepancies) == 0 {
+        fmt.Println("Stock matches the ledger")
+        return nil
+    }
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(w, "PRODUCT\tVARIANT\tSTOCK\tLEDGER")
+    for _, d := range discrepancies {
+        fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", d.ProductID, d.VariantID, d.Stock, d.Ledger)
+    }
+    if err := w.Flush(); err != nil {
+        return err
+    }
+    return fmt.Errorf("%d stock levels differ from the ledger", len(discrepancies))
+}
+
+// runImport implements `product-service import [-format csv|jsonl] [-dry-run] [-batch-size n] file`
+func runImport(ctx context.Context, productUseCase *usecases.ProductUseCase, args []string) error {
+    flags := flag.NewFlagSet("import", flag.ContinueOnError)
+    formatName := flags.String("format", "", "file format, csv or jsonl; taken from the file extension when omitted")
+    dryRun := flags.Bool("dry-run", false, "validate and match the rows without writing them")
+    batchSize := flags.Int("batch-size", 0, "rows written per transaction; 0 uses the default")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() != 1 {
+        return fmt.Errorf("usage: import [-format csv|jsonl] [-dry-run] [-batch-size n] file")
+    }
+
+    path := flags.Arg(0)
+    format, err := catalogFormat(*formatName, path)
+    if err != nil {
+        return err
+    }
+    file, err := os.Open(path)
+    if err != nil {
+        return err
+    }
+    defer file.Close()
+
+    reader, err := catalog.NewReader(file, format)
+    if err != nil {
+        return err
+    }
+    report, err := productUseCase.ImportProducts(ctx, reader, usecases.ImportOptions{DryRun: *dryRun, BatchSize: *batchSize})
+    if report != nil {
+        if err := printImportReport(report, *dryRun); err != nil {
+            return err
+        }
+    }
+    if err != nil {
+        return err
+    }
+    if report.Failed > 0 {
+        return fmt.Errorf("%d rows were not imported", report.Failed)
+    }
+    return nil
+}
+
+// printImportReport prints the outcome of an import and the rows that failed
+func printImportReport(report *usecases.ImportReport, dryRun bool) error {
+    w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    if dryRun {
+        fmt.Fprintln(w, "Dry run: no products were written")
+    }
+    fmt.Fprintf(w, "Created\t%d\nUpdated\t%d\nFailed\t%d\n", report.Created, report.Updated, report.Failed)
+    if len(report.Errors) > 0 {
+        fmt.Fprintln(w, "\nLINE\tID\tSKU\tERROR")
+        for _, e := range report.Errors {
+            fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", e.Line, e.ID, e.SKU, e.Message)
+        }
+    }
+    return w.Flush()
+}
+
+// runExport implements `product-service export [-format csv|jsonl] [file]`;
+// without a file the catalogue is written to standard output
+func runExport(ctx context.Context, productUseCase *usecases.ProductUseCase, args []string) error {
+    flags := flag.NewFlagSet("export", flag.ContinueOnError)
+    formatName := flags.String("format", "", "file format, csv or jsonl; taken from the file extension when omitted")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+    if flags.NArg() > 1 {
+        return fmt.Errorf("usage: export [-format csv|jsonl] [file]")
+    }
+
+    var out io.Writer = os.Stdout
+    path := flags.Arg(0)
+    format := catalog.CSV
+    if path != "" || *formatName != "" {
+        var err error
+        if format, err = catalogFormat(*formatName, path); err != nil {
+            return err
+        }
+    }
+    if path != "" {
+        file, err := os.Create(path)
+        if err != nil {
+            return err
+        }
+        defer file.Close()
+        out = file
+    }
+
+    writer, err := catalog.NewWriter(out, format)
+    if err != nil {
+        return err
+    }
+    exported, err := productUseCase.ExportProducts(ctx, writer)
+    if err != nil {
+        return err
+    }
+    log.Printf("Exported %d products", exported)
+    return nil
+}
+
+// catalogFormat parses a -format flag, falling back to the extension of the file
+func catalogFormat(name, path string) (catalog.Format, error) {
+    if name != "" {
+        return catalog.ParseFormat(name)
+    }
+    switch ext := filepath.Ext(path); ext {
+    case ".csv":
+        return catalog.CSV, nil
+    case ".jsonl", ".ndjson":
+        return catalog.JSONLines, nil
+    default:
+        return "", fmt.Errorf("cannot tell the format of %q from its extension, pass -format", path)
+    }
+}
+
+// runMigrate implements `product-service migrate up|down|status`
+func runMigrate(ctx context.Context, sqlDB *sql.DB, args []string) error {
+    if len(args) != 1 {
+        return fmt.Errorf("usage: migrate up|down|status")
+    }
+
+    migrator, err := migrations.NewMigrator(sqlDB)
+    if err != nil {
+        return err
+    }
+
+    switch args[0] {
+    case "up":
+        return migrator.Up(ctx)
+    case "down":
+        return migrator.Down(ctx)
+    case "status":
+        statuses, err := migrator.Status(ctx)
+        if err != nil {
+            return err
+        }
+        w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+        fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+        for _, s := range statuses {
+            appliedAt := "pending"
+            if s.AppliedAt != nil {
+                appliedAt = s.AppliedAt.Format(time.RFC3339)
+            }
+            fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+        }
+        return w.Flush()
+    default:
+        return fmt.Errorf("unknown migrate command %q, expected up, down or status", args[0])
+    }
+}
@@ -6,10 +6,13 @@ import (
     "fmt"
     "log"
     "os"
+    "strconv"
     "time"
 
     "github.com/joho/godotenv"
     _ "github.com/go-sql-driver/mysql" // MySQL driver
+    "github.com/samObot19/shopverse/product-service/fulfillment"
+    "github.com/samObot19/shopverse/product-service/search"
     "go.mongodb.org/mongo-driver/mongo"
     "go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -22,6 +25,64 @@ type Config struct {
     MySQLPort     string
     MySQLDatabase string
     GRPCPort      string
+
+    // SearchReindexInterval is how often the search index is rebuilt from the
+    // database to pick up writes made by other replicas; zero disables it
+    SearchReindexInterval time.Duration
+
+    // SuggestionLimit caps the number of autocomplete suggestions per request,
+    // at most search.MaxSuggestions
+    SuggestionLimit int
+
+    // ReservationTTL is how long reserved stock is held for a checkout before
+    // it is given back, and ReservationSweepInterval how often expired
+    // reservations are looked for
+    ReservationTTL           time.Duration
+    ReservationSweepInterval time.Duration
+
+    // FulfillmentStrategy picks the warehouses that reserved stock is taken from
+    FulfillmentStrategy fulfillment.Strategy
+
+    // OrderServiceAddress is where order-service listens; reviews are checked
+    // against the orders it holds
+    OrderServiceAddress string
+
+    // KafkaBrokers lists the comma-separated Kafka brokers that product events
+    // are published to, ProductEventTopic names their topic and
+    // OutboxRelayInterval is how often the outbox is checked for new events
+    KafkaBrokers        string
+    ProductEventTopic   string
+    OutboxRelayInterval time.Duration
+
+    // ArchiveRetention is how long a deleted product stays archived and can be
+    // restored, and ArchivePurgeInterval how often archived products past it
+    // that no order references are purged
+    ArchiveRetention     time.Duration
+    ArchivePurgeInterval time.Duration
+
+    // ProductCacheSize caps the number of products kept in memory and
+    // ProductCacheTTL how long each is served before it is read again. With
+    // ProductCacheInvalidation set, products changed by other replicas are
+    // dropped from the cache as their events are published.
+    ProductCacheSize         int
+    ProductCacheTTL          time.Duration
+    ProductCacheInvalidation bool
+
+    // ProductFeedSize is the number of the latest catalogue changes kept for
+    // WatchProducts clients to resume after
+    ProductFeedSize int
+
+    // MetricsAddress is where the service's counters, such as its cache hits
+    // and misses, are served at /debug/vars
+    MetricsAddress string
+
+    // MediaDir is the directory the renditions of product images are kept in
+    // and MediaBaseURL where shoppers fetch them from. The directory is served
+    // at /media/ on MediaAddress unless it is empty, for when another web
+    // server or a CDN serves it.
+    MediaDir     string
+    MediaBaseURL string
+    MediaAddress string
 }
 
 // LoadConfig loads environment variables and returns the configuration
@@ -47,6 +108,148 @@ func LoadConfig() (*Config, error) {
         return nil, fmt.Errorf("missing required environment variables")
     }
 
+    config.SearchReindexInterval = 5 * time.Minute
+    if v := os.Getenv("SEARCH_REINDEX_INTERVAL"); v != "" {
+        interval, err := time.ParseDuration(v)
+        if err != nil {
+            return nil, fmt.Errorf("invalid SEARCH_REINDEX_INTERVAL: %w", err)
+        }
+        config.SearchReindexInterval = interval
+    }
+
+    config.SuggestionLimit = 10
+    if v := os.Getenv("SUGGESTION_LIMIT"); v != "" {
+        limit, err := strconv.Atoi(v)
+        if err != nil || limit <= 0 || limit > search.MaxSuggestions {
+            return nil, fmt.Errorf("invalid SUGGESTION_LIMIT %q: must be between 1 and %d", v, search.MaxSuggestions)
+        }
+        config.SuggestionLimit = limit
+    }
+
+    config.ReservationTTL = 15 * time.Minute
+    if v := os.Getenv("RESERVATION_TTL"); v != "" {
+        ttl, err := time.ParseDuration(v)
+        if err != nil || ttl <= 0 {
+            return nil, fmt.Errorf("invalid RESERVATION_TTL %q", v)
+        }
+        config.ReservationTTL = ttl
+    }
+
+    config.ReservationSweepInterval = time.Minute
+    if v := os.Getenv("RESERVATION_SWEEP_INTERVAL"); v != "" {
+        interval, err := time.ParseDuration(v)
+        if err != nil || interval <= 0 {
+            return nil, fmt.Errorf("invalid RESERVATION_SWEEP_INTERVAL %q", v)
+        }
+        config.ReservationSweepInterval = interval
+    }
+
+    config.FulfillmentStrategy = fulfillment.MostStock
+    if v := os.Getenv("FULFILLMENT_STRATEGY"); v != "" {
+        strategy, err := fulfillment.ParseStrategy(v)
+        if err != nil {
+            return nil, fmt.Errorf("invalid FULFILLMENT_STRATEGY: %w", err)
+        }
+        config.FulfillmentStrategy = strategy
+    }
+
+    config.OrderServiceAddress = "localhost:8082"
+    if v := os.Getenv("ORDER_SERVICE_ADDRESS"); v != "" {
+        config.OrderServiceAddress = v
+    }
+
+    config.KafkaBrokers = "localhost:9092"
+    if v := os.Getenv("KAFKA_BROKERS"); v != "" {
+        config.KafkaBrokers = v
+    }
+
+    config.ProductEventTopic = "productEvent"
+    if v := os.Getenv("PRODUCT_EVENT_TOPIC"); v != "" {
+        config.ProductEventTopic = v
+    }
+
+    config.OutboxRelayInterval = time.Second
+    if v := os.Getenv("OUTBOX_RELAY_INTERVAL"); v != "" {
+        interval, err := time.ParseDuration(v)
+        if err != nil || interval <= 0 {
+            return nil, fmt.Errorf("invalid OUTBOX_RELAY_INTERVAL %q", v)
+        }
+        config.OutboxRelayInterval = interval
+    }
+
+    config.ArchiveRetention = 30 * 24 * time.Hour
+    if v := os.Getenv("ARCHIVE_RETENTION"); v != "" {
+        retention, err := time.ParseDuration(v)
+        if err != nil || retention < 0 {
+            return nil, fmt.Errorf("invalid ARCHIVE_RETENTION %q", v)
+        }
+        config.ArchiveRetention = retention
+    }
+
+    config.ArchivePurgeInterval = time.Hour
+    if v := os.Getenv("ARCHIVE_PURGE_INTERVAL"); v != "" {
+        interval, err := time.ParseDuration(v)
+        if err != nil || interval <= 0 {
+            return nil, fmt.Errorf("invalid ARCHIVE_PURGE_INTERVAL %q", v)
+        }
+        config.ArchivePurgeInterval = interval
+    }
+
+    config.ProductCacheSize = 10000
+    if v := os.Getenv("PRODUCT_CACHE_SIZE"); v != "" {
+        size, err := strconv.Atoi(v)
+        if err != nil || size <= 0 {
+            return nil, fmt.Errorf("invalid PRODUCT_CACHE_SIZE %q", v)
+        }
+        config.ProductCacheSize = size
+    }
+
+    config.ProductCacheTTL = 30 * time.Second
+    if v := os.Getenv("PRODUCT_CACHE_TTL"); v != "" {
+        ttl, err := time.ParseDuration(v)
+        if err != nil || ttl <= 0 {
+            return nil, fmt.Errorf("invalid PRODUCT_CACHE_TTL %q", v)
+        }
+        config.ProductCacheTTL = ttl
+    }
+
+    if v := os.Getenv("PRODUCT_CACHE_INVALIDATION"); v != "" {
+        enabled, err := strconv.ParseBool(v)
+        if err != nil {
+            return nil, fmt.Errorf("invalid PRODUCT_CACHE_INVALIDATION %q", v)
+        }
+        config.ProductCacheInvalidation = enabled
+    }
+
+    config.ProductFeedSize = 10000
+    if v := os.Getenv("PRODUCT_FEED_SIZE"); v != "" {
+        size, err := strconv.Atoi(v)
+        if err != nil || size <= 0 {
+            return nil, fmt.Errorf("invalid PRODUCT_FEED_SIZE %q", v)
+        }
+        config.ProductFeedSize = size
+    }
+
+    config.MetricsAddress = ":9091"
+    if v := os.Getenv("METRICS_ADDRESS"); v != "" {
+        config.MetricsAddress = v
+    }
+
+    config.MediaDir = "media"
+    if v := os.Getenv("MEDIA_DIR"); v != "" {
+        config.MediaDir = v
+    }
+
+    config.MediaAddress = ":8090"
+    if v, ok := os.LookupEnv("MEDIA_ADDRESS"); ok {
+        config.MediaAddress = v
+    }
+
+    config.MediaBaseURL = "http://localhost:8090/media"
+    if v := os.Getenv("MEDIA_BASE_URL"); v != "" {
+        config.MediaBaseURL = v
+    }
+
     return config, nil
 }
 
@@ -0,0 +1,238 @@
+package migrations
+
+import (
+    "context"
+    "database/sql"
+    "embed"
+    "fmt"
+    "io/fs"
+    "path"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// schemaTable records every migration version that has been applied
+const schemaTable = "schema_migrations"
+
+// Migration is a single versioned schema change with its rollback
+type Migration struct {
+    Version int
+    Name    string
+    Up      string
+    Down    string
+}
+
+// Status describes whether a migration has been applied
+type Status struct {
+    Migration
+    AppliedAt *time.Time
+}
+
+// Migrator applies the embedded migrations to a MySQL database
+type Migrator struct {
+    db         *sql.DB
+    migrations []Migration
+}
+
+// NewMigrator creates a Migrator backed by the embedded SQL files
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+    migrations, err := Load(files)
+    if err != nil {
+        return nil, err
+    }
+    return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Load reads NNNN_name.up.sql / NNNN_name.down.sql pairs from fsys, ordered by version
+func Load(fsys fs.FS) ([]Migration, error) {
+    entries, err := fs.ReadDir(fsys, "sql")
+    if err != nil {
+        return nil, fmt.Errorf("failed to read migrations: %w", err)
+    }
+
+    byVersion := make(map[int]*Migration)
+    for _, entry := range entries {
+        name := entry.Name()
+        var direction string
+        switch {
+        case strings.HasSuffix(name, ".up.sql"):
+            direction = "up"
+        case strings.HasSuffix(name, ".down.sql"):
+            direction = "down"
+        default:
+            return nil, fmt.Errorf("unexpected migration file %q", name)
+        }
+
+        base := strings.TrimSuffix(name, "."+direction+".sql")
+        prefix, label, ok := strings.Cut(base, "_")
+        if !ok {
+            return nil, fmt.Errorf("migration file %q must be named NNNN_name.%s.sql", name, direction)
+        }
+        version, err := strconv.Atoi(prefix)
+        if err != nil {
+            return nil, fmt.Errorf("invalid version in migration file %q: %w", name, err)
+        }
+
+        body, err := fs.ReadFile(fsys, path.Join("sql", name))
+        if err != nil {
+            return nil, fmt.Errorf("failed to read migration %q: %w", name, err)
+        }
+
+        m, exists := byVersion[version]
+        if !exists {
+            m = &Migration{Version: version, Name: label}
+            byVersion[version] = m
+        } else if m.Name != label {
+            return nil, fmt.Errorf("migration version %d is used by both %q and %q", version, m.Name, label)
+        }
+        if direction == "up" {
+            m.Up = string(body)
+        } else {
+            m.Down = string(body)
+        }
+    }
+
+    migrations := make([]Migration, 0, len(byVersion))
+    for _, m := range byVersion {
+        if m.Up == "" || m.Down == "" {
+            return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", m.Version, m.Name)
+        }
+        migrations = append(migrations, *m)
+    }
+    sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+    return migrations, nil
+}
+
+// Up applies every pending migration in version order
+func (m *Migrator) Up(ctx context.Context) error {
+    applied, err := m.applied(ctx)
+    if err != nil {
+        return err
+    }
+
+    for _, migration := range m.migrations {
+        if _, ok := applied[migration.Version]; ok {
+            continue
+        }
+        if err := m.exec(ctx, migration.Up); err != nil {
+            return fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+        }
+        _, err := m.db.ExecContext(ctx,
+            "INSERT INTO "+schemaTable+" (version, name, applied_at) VALUES (?, ?, ?)",
+            migration.Version, migration.Name, time.Now().UTC())
+        if err != nil {
+            return fmt.Errorf("failed to record migration %04d_%s: %w", migration.Version, migration.Name, err)
+        }
+    }
+    return nil
+}
+
+// Down rolls back the most recently applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+    applied, err := m.applied(ctx)
+    if err != nil {
+        return err
+    }
+
+    for i := len(m.migrations) - 1; i >= 0; i-- {
+        migration := m.migrations[i]
+        if _, ok := applied[migration.Version]; !ok {
+            continue
+        }
+        if err := m.exec(ctx, migration.Down); err != nil {
+            return fmt.Errorf("rollback of %04d_%s failed: %w", migration.Version, migration.Name, err)
+        }
+        _, err := m.db.ExecContext(ctx, "DELETE FROM "+schemaTable+" WHERE version = ?", migration.Version)
+        if err != nil {
+            return fmt.Errorf("failed to unrecord migration %04d_%s: %w", migration.Version, migration.Name, err)
+        }
+        return nil
+    }
+    return nil
+}
+
+// Status reports every known migration and when it was applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+    applied, err := m.applied(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    statuses := make([]Status, 0, len(m.migrations))
+    for _, migration := range m.migrations {
+        status := Status{Migration: migration}
+        if at, ok := applied[migration.Version]; ok {
+            status.AppliedAt = &at
+        }
+        statuses = append(statuses, status)
+    }
+    return statuses, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]time.Time, error) {
+    _, err := m.db.ExecContext(ctx, `
+        CREATE TABLE IF NOT EXISTS `+schemaTable+` (
+            version INT NOT NULL PRIMARY KEY,
+            name VARCHAR(255) NOT NULL,
+            applied_at DATETIME NOT NULL
+        )`)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create %s table: %w", schemaTable, err)
+    }
+
+    rows, err := m.db.QueryContext(ctx, "SELECT version, applied_at FROM "+schemaTable)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    applied := make(map[int]time.Time)
+    for rows.Next() {
+        var version int
+        var at time.Time
+        if err := rows.Scan(&version, &at); err != nil {
+            return nil, err
+        }
+        applied[version] = at
+    }
+    return applied, rows.Err()
+}
+
+// exec runs each statement of a migration in turn; MySQL commits DDL implicitly,
+// so a failed migration has to be fixed forward or rolled back by hand
+func (m *Migrator) exec(ctx context.Context, script string) error {
+    for _, stmt := range Statements(script) {
+        if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Statements splits a migration script into individual statements
+func Statements(script string) []string {
+    var stmts []string
+    var current strings.Builder
+    for _, line := range strings.Split(script, "\n") {
+        trimmed := strings.TrimSpace(line)
+        if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+            continue
+        }
+        current.WriteString(line)
+        current.WriteString("\n")
+        if strings.HasSuffix(trimmed, ";") {
+            stmt := strings.TrimSuffix(strings.TrimSpace(current.String()), ";")
+            stmts = append(stmts, stmt)
+            current.Reset()
+        }
+    }
+    if rest := strings.TrimSpace(current.String()); rest != "" {
+        stmts = append(stmts, rest)
+    }
+    return stmts
+}
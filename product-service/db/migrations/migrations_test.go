@@ -0,0 +1,99 @@
+package migrations
+
+import (
+    "reflect"
+    "testing"
+    "testing/fstest"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+    migrations, err := Load(files)
+    if err != nil {
+        t.Fatalf("Load() error = %v", err)
+    }
+    if len(migrations) == 0 {
+        t.Fatal("Load() returned no migrations")
+    }
+    for i, m := range migrations {
+        if i > 0 && m.Version <= migrations[i-1].Version {
+            t.Errorf("migration %d is out of order", m.Version)
+        }
+        if len(Statements(m.Up)) == 0 || len(Statements(m.Down)) == 0 {
+            t.Errorf("migration %04d_%s has an empty up or down script", m.Version, m.Name)
+        }
+    }
+}
+
+func TestLoad(t *testing.T) {
+    tests := []struct {
+        name    string
+        files   fstest.MapFS
+        want    []int
+        wantErr bool
+    }{
+        {
+            name: "Ordered by version",
+            files: fstest.MapFS{
+                "sql/0002_b.up.sql":   {Data: []byte("SELECT 2;")},
+                "sql/0002_b.down.sql": {Data: []byte("SELECT 2;")},
+                "sql/0001_a.up.sql":   {Data: []byte("SELECT 1;")},
+                "sql/0001_a.down.sql": {Data: []byte("SELECT 1;")},
+            },
+            want: []int{1, 2},
+        },
+        {
+            name: "Missing down file",
+            files: fstest.MapFS{
+                "sql/0001_a.up.sql": {Data: []byte("SELECT 1;")},
+            },
+            wantErr: true,
+        },
+        {
+            name: "Duplicate version",
+            files: fstest.MapFS{
+                "sql/0001_a.up.sql":   {Data: []byte("SELECT 1;")},
+                "sql/0001_a.down.sql": {Data: []byte("SELECT 1;")},
+                "sql/0001_b.up.sql":   {Data: []byte("SELECT 1;")},
+                "sql/0001_b.down.sql": {Data: []byte("SELECT 1;")},
+            },
+            wantErr: true,
+        },
+        {
+            name: "Bad file name",
+            files: fstest.MapFS{
+                "sql/create.up.sql": {Data: []byte("SELECT 1;")},
+            },
+            wantErr: true,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            migrations, err := Load(tt.files)
+            if (err != nil) != tt.wantErr {
+                t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+            }
+            var got []int
+            for _, m := range migrations {
+                got = append(got, m.Version)
+            }
+            if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("Load() versions = %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestStatements(t *testing.T) {
+    script := `-- two tables
+CREATE TABLE a (
+    id INT
+);
+
+CREATE TABLE b (id INT);
+`
+    want := []string{"CREATE TABLE a (\n    id INT\n)", "CREATE TABLE b (id INT)"}
+    if got := Statements(script); !reflect.DeepEqual(got, want) {
+        t.Errorf("Statements() = %q, want %q", got, want)
+    }
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS product_images;
+DROP TABLE IF EXISTS product_sizes;
+DROP TABLE IF EXISTS product_attributes;
+DROP TABLE IF EXISTS products;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS products (
+    id VARCHAR(64) NOT NULL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    price DOUBLE NOT NULL,
+    stock INT NOT NULL DEFAULT 0,
+    category VARCHAR(255) NOT NULL,
+    ratings DOUBLE NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    INDEX idx_products_category (category)
+);
+
+CREATE TABLE IF NOT EXISTS product_attributes (
+    product_id VARCHAR(64) NOT NULL PRIMARY KEY,
+    color VARCHAR(64) NOT NULL DEFAULT '',
+    CONSTRAINT fk_product_attributes_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS product_sizes (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    product_id VARCHAR(64) NOT NULL,
+    size VARCHAR(32) NOT NULL,
+    INDEX idx_product_sizes_product (product_id),
+    CONSTRAINT fk_product_sizes_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS product_images (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    product_id VARCHAR(64) NOT NULL,
+    image_url VARCHAR(1024) NOT NULL,
+    INDEX idx_product_images_product (product_id),
+    CONSTRAINT fk_product_images_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
@@ -0,0 +1,6 @@
+DROP INDEX idx_products_category_created_at ON products;
+DROP INDEX idx_products_category_price ON products;
+DROP INDEX idx_products_title ON products;
+DROP INDEX idx_products_ratings ON products;
+DROP INDEX idx_products_created_at ON products;
+DROP INDEX idx_products_price ON products;
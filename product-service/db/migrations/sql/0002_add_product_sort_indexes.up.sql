@@ -0,0 +1,6 @@
+CREATE INDEX idx_products_price ON products (price);
+CREATE INDEX idx_products_created_at ON products (created_at);
+CREATE INDEX idx_products_ratings ON products (ratings);
+CREATE INDEX idx_products_title ON products (title);
+CREATE INDEX idx_products_category_price ON products (category, price);
+CREATE INDEX idx_products_category_created_at ON products (category, created_at);
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS product_variants (
+    id VARCHAR(64) NOT NULL PRIMARY KEY,
+    product_id VARCHAR(64) NOT NULL,
+    sku VARCHAR(64) NOT NULL,
+    price DOUBLE NULL,
+    stock INT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    UNIQUE KEY uq_product_variants_sku (sku),
+    INDEX idx_product_variants_product (product_id),
+    CONSTRAINT fk_product_variants_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS product_variant_options (
+    variant_id VARCHAR(64) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    PRIMARY KEY (variant_id, name),
+    CONSTRAINT fk_product_variant_options_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS product_variant_images (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    variant_id VARCHAR(64) NOT NULL,
+    image_url VARCHAR(1024) NOT NULL,
+    INDEX idx_product_variant_images_variant (variant_id),
+    CONSTRAINT fk_product_variant_images_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id) ON DELETE CASCADE
+);
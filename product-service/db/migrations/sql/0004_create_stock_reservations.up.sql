@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id VARCHAR(64) NOT NULL PRIMARY KEY,
+    reference_id VARCHAR(128) NULL,
+    status VARCHAR(16) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE KEY uq_stock_reservations_reference (reference_id),
+    INDEX idx_stock_reservations_pending (status, expires_at)
+);
+
+CREATE TABLE IF NOT EXISTS stock_reservation_items (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    reservation_id VARCHAR(64) NOT NULL,
+    product_id VARCHAR(64) NOT NULL,
+    variant_id VARCHAR(64) NOT NULL DEFAULT '',
+    quantity INT NOT NULL,
+    INDEX idx_stock_reservation_items_reservation (reservation_id),
+    CONSTRAINT fk_stock_reservation_items_reservation FOREIGN KEY (reservation_id) REFERENCES stock_reservations (id) ON DELETE CASCADE
+);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    product_id VARCHAR(64) NOT NULL,
+    variant_id VARCHAR(64) NOT NULL DEFAULT '',
+    delta INT NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    actor VARCHAR(128) NOT NULL,
+    reference_id VARCHAR(128) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    INDEX idx_stock_movements_product (product_id, variant_id, id)
+);
+
+-- Open the ledger with the stock held before it existed, so stock and
+-- movements reconcile from the start
+INSERT INTO stock_movements (product_id, variant_id, delta, reason, actor, reference_id, created_at)
+SELECT id, '', stock, 'adjustment', 'system', 'opening-balance', UTC_TIMESTAMP()
+FROM products WHERE stock <> 0;
+
+INSERT INTO stock_movements (product_id, variant_id, delta, reason, actor, reference_id, created_at)
+SELECT product_id, id, stock, 'adjustment', 'system', 'opening-balance', UTC_TIMESTAMP()
+FROM product_variants WHERE stock <> 0;
@@ -0,0 +1,4 @@
+ALTER TABLE stock_movements DROP COLUMN warehouse_id;
+ALTER TABLE stock_reservation_items DROP COLUMN warehouse_id;
+DROP TABLE IF EXISTS warehouse_stock;
+DROP TABLE IF EXISTS warehouses;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS warehouses (
+    id VARCHAR(64) NOT NULL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    address VARCHAR(512) NOT NULL DEFAULT '',
+    latitude DOUBLE NOT NULL DEFAULT 0,
+    longitude DOUBLE NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL
+);
+
+-- All stock recorded so far is held in the default warehouse
+INSERT INTO warehouses (id, name, created_at) VALUES ('default', 'Default warehouse', UTC_TIMESTAMP());
+
+CREATE TABLE IF NOT EXISTS warehouse_stock (
+    warehouse_id VARCHAR(64) NOT NULL,
+    product_id VARCHAR(64) NOT NULL,
+    variant_id VARCHAR(64) NOT NULL DEFAULT '',
+    quantity INT NOT NULL,
+    PRIMARY KEY (warehouse_id, product_id, variant_id),
+    INDEX idx_warehouse_stock_product (product_id, variant_id),
+    CONSTRAINT fk_warehouse_stock_warehouse FOREIGN KEY (warehouse_id) REFERENCES warehouses (id),
+    CONSTRAINT fk_warehouse_stock_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+
+INSERT INTO warehouse_stock (warehouse_id, product_id, variant_id, quantity)
+SELECT 'default', id, '', stock FROM products WHERE stock <> 0;
+
+INSERT INTO warehouse_stock (warehouse_id, product_id, variant_id, quantity)
+SELECT 'default', product_id, id, stock FROM product_variants WHERE stock <> 0;
+
+ALTER TABLE stock_reservation_items ADD COLUMN warehouse_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+ALTER TABLE stock_movements ADD COLUMN warehouse_id VARCHAR(64) NOT NULL DEFAULT 'default';
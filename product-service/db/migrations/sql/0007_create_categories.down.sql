@@ -0,0 +1,3 @@
+DROP INDEX idx_products_category_id ON products;
+ALTER TABLE products DROP COLUMN category_id;
+DROP TABLE IF EXISTS categories;
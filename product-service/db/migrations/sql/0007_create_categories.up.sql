@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id VARCHAR(64) NOT NULL PRIMARY KEY,
+    parent_id VARCHAR(64) NOT NULL DEFAULT '',
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    UNIQUE KEY uq_categories_parent_slug (parent_id, slug),
+    INDEX idx_categories_parent_position (parent_id, position)
+);
+
+ALTER TABLE products ADD COLUMN category_id VARCHAR(64) NOT NULL DEFAULT '';
+CREATE INDEX idx_products_category_id ON products (category_id);
+
+-- Every category name in use becomes a top-level category
+INSERT INTO categories (id, parent_id, name, slug, position, created_at)
+SELECT UUID(), '', category, LOWER(REPLACE(TRIM(category), ' ', '-')), 0, UTC_TIMESTAMP()
+FROM (SELECT DISTINCT category FROM products WHERE category <> '') AS names;
+
+UPDATE products p JOIN categories c ON c.parent_id = '' AND c.name = p.category
+SET p.category_id = c.id;
@@ -0,0 +1,5 @@
+UPDATE products SET ratings = ratings_before_reviews WHERE ratings_before_reviews IS NOT NULL;
+ALTER TABLE products DROP COLUMN ratings_before_reviews;
+ALTER TABLE products DROP COLUMN review_count;
+DROP TABLE IF EXISTS product_review_votes;
+DROP TABLE IF EXISTS product_reviews;
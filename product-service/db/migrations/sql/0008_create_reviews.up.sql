@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS product_reviews (
+    id VARCHAR(64) NOT NULL PRIMARY KEY,
+    product_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(128) NOT NULL,
+    order_id VARCHAR(64) NOT NULL,
+    rating TINYINT NOT NULL,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    helpful_votes INT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE KEY uq_product_reviews_product_user (product_id, user_id),
+    INDEX idx_product_reviews_status (status, created_at),
+    CONSTRAINT fk_product_reviews_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS product_review_votes (
+    review_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(128) NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (review_id, user_id),
+    CONSTRAINT fk_product_review_votes_review FOREIGN KEY (review_id) REFERENCES product_reviews (id) ON DELETE CASCADE
+);
+
+ALTER TABLE products ADD COLUMN review_count INT NOT NULL DEFAULT 0;
+
+-- Ratings were typed in by hand; from now on they are the average of the
+-- approved reviews. The hand-typed ratings are kept until the first approved
+-- review of a product replaces them, and are copied so that migrating down
+-- brings them back.
+ALTER TABLE products ADD COLUMN ratings_before_reviews DOUBLE NULL;
+UPDATE products SET ratings_before_reviews = ratings;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS price_history;
+DROP TABLE IF EXISTS price_list_entries;
+DROP TABLE IF EXISTS price_lists;
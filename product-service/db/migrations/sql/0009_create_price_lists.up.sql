@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS price_lists (
+    id VARCHAR(64) NOT NULL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    INDEX idx_price_lists_period (starts_at, ends_at)
+);
+
+CREATE TABLE IF NOT EXISTS price_list_entries (
+    price_list_id VARCHAR(64) NOT NULL,
+    product_id VARCHAR(64) NOT NULL,
+    variant_id VARCHAR(64) NOT NULL DEFAULT '',
+    price DOUBLE NULL,
+    percent_off DOUBLE NOT NULL DEFAULT 0,
+    PRIMARY KEY (price_list_id, product_id, variant_id),
+    INDEX idx_price_list_entries_product (product_id),
+    CONSTRAINT fk_price_list_entries_list FOREIGN KEY (price_list_id) REFERENCES price_lists (id) ON DELETE CASCADE,
+    CONSTRAINT fk_price_list_entries_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS price_history (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    product_id VARCHAR(64) NOT NULL,
+    variant_id VARCHAR(64) NOT NULL DEFAULT '',
+    price_list_id VARCHAR(64) NOT NULL DEFAULT '',
+    price DOUBLE NULL,
+    percent_off DOUBLE NOT NULL DEFAULT 0,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    INDEX idx_price_history_product (product_id, id)
+);
+
+-- Open the history with the prices set before it existed
+INSERT INTO price_history (product_id, variant_id, price, starts_at, created_at)
+SELECT id, '', price, created_at, UTC_TIMESTAMP() FROM products;
+
+INSERT INTO price_history (product_id, variant_id, price, starts_at, created_at)
+SELECT product_id, id, price, created_at, UTC_TIMESTAMP() FROM product_variants WHERE price IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE products DROP INDEX uq_products_sku;
+ALTER TABLE products DROP COLUMN sku;
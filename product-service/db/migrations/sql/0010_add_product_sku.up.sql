@@ -0,0 +1,3 @@
+-- Products without a SKU keep NULL, which the unique key allows any number of times
+ALTER TABLE products ADD COLUMN sku VARCHAR(64) NULL AFTER id;
+ALTER TABLE products ADD UNIQUE KEY uq_products_sku (sku);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    product_id VARCHAR(64) NOT NULL,
+    payload JSON NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    created_at DATETIME NOT NULL
+);
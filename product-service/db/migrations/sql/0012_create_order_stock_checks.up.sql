@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS order_stock_checks (
+    order_id VARCHAR(64) NOT NULL PRIMARY KEY,
+    stock_available BOOLEAN NOT NULL,
+    reservation_id VARCHAR(64) NOT NULL DEFAULT '',
+    reason VARCHAR(512) NOT NULL DEFAULT '',
+    checked_at DATETIME NOT NULL
+);
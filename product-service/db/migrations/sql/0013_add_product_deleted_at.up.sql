@@ -0,0 +1,3 @@
+-- Deleted products are archived rather than removed, so orders can still refer to them
+ALTER TABLE products ADD COLUMN deleted_at DATETIME NULL AFTER created_at;
+ALTER TABLE products ADD INDEX idx_products_deleted_at (deleted_at);
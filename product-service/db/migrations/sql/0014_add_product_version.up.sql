@@ -0,0 +1,2 @@
+-- Every edit of a product increments its version, so concurrent edits can be detected
+ALTER TABLE products ADD COLUMN version BIGINT NOT NULL DEFAULT 1 AFTER review_count;
@@ -0,0 +1,8 @@
+ALTER TABLE price_history DROP COLUMN currency;
+ALTER TABLE price_history MODIFY price DOUBLE NULL;
+ALTER TABLE price_list_entries DROP COLUMN currency;
+ALTER TABLE price_list_entries MODIFY price DOUBLE NULL;
+ALTER TABLE product_variants DROP COLUMN currency;
+ALTER TABLE product_variants MODIFY price DOUBLE NULL;
+ALTER TABLE products DROP COLUMN currency;
+ALTER TABLE products MODIFY price DOUBLE NOT NULL;
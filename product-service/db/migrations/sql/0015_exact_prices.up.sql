@@ -0,0 +1,15 @@
+-- Prices are kept exactly as decimals in major units, next to their ISO 4217
+-- currency, and rounded to whole cents; prices recorded before were all in USD
+ALTER TABLE products MODIFY price DECIMAL(19,4) NOT NULL;
+ALTER TABLE products ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD' AFTER price;
+ALTER TABLE products ALTER COLUMN currency DROP DEFAULT;
+UPDATE products SET price = ROUND(price, 2);
+ALTER TABLE product_variants MODIFY price DECIMAL(19,4) NULL;
+ALTER TABLE product_variants ADD COLUMN currency CHAR(3) NULL AFTER price;
+UPDATE product_variants SET price = ROUND(price, 2), currency = 'USD' WHERE price IS NOT NULL;
+ALTER TABLE price_list_entries MODIFY price DECIMAL(19,4) NULL;
+ALTER TABLE price_list_entries ADD COLUMN currency CHAR(3) NULL AFTER price;
+UPDATE price_list_entries SET price = ROUND(price, 2), currency = 'USD' WHERE price IS NOT NULL;
+ALTER TABLE price_history MODIFY price DECIMAL(19,4) NULL;
+ALTER TABLE price_history ADD COLUMN currency CHAR(3) NULL AFTER price;
+UPDATE price_history SET price = ROUND(price, 2), currency = 'USD' WHERE price IS NOT NULL;
@@ -0,0 +1,9 @@
+-- Rates are what one USD, the currency of prices that name none, buys of
+-- each other currency prices can be shown and charged in
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency CHAR(3) NOT NULL PRIMARY KEY,
+    rate DECIMAL(20,10) NOT NULL,
+    rounding_mode VARCHAR(16) NOT NULL DEFAULT 'nearest',
+    rounding_increment BIGINT NOT NULL DEFAULT 1,
+    updated_at DATETIME NOT NULL
+);
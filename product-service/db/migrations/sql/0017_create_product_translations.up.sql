@@ -0,0 +1,10 @@
+-- The title and description of a product in other locales; the columns of
+-- products hold its default content
+CREATE TABLE IF NOT EXISTS product_translations (
+    product_id VARCHAR(64) NOT NULL,
+    locale VARCHAR(35) NOT NULL,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    description TEXT NOT NULL,
+    PRIMARY KEY (product_id, locale),
+    CONSTRAINT fk_product_translations_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
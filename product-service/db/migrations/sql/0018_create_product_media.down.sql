@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS product_media_renditions;
+DROP TABLE IF EXISTS product_media;
@@ -0,0 +1,25 @@
+-- Images uploaded for products and the renditions made of them, whose files
+-- are kept in a blob store; product_images holds image URLs set by hand
+CREATE TABLE IF NOT EXISTS product_media (
+    id VARCHAR(64) NOT NULL PRIMARY KEY,
+    product_id VARCHAR(64) NOT NULL,
+    alt_text VARCHAR(512) NOT NULL DEFAULT '',
+    width INT NOT NULL,
+    height INT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    INDEX idx_product_media_product (product_id, position, created_at),
+    CONSTRAINT fk_product_media_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS product_media_renditions (
+    media_id VARCHAR(64) NOT NULL,
+    size VARCHAR(32) NOT NULL,
+    format VARCHAR(8) NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    bytes BIGINT NOT NULL,
+    blob_key VARCHAR(512) NOT NULL,
+    PRIMARY KEY (media_id, size, format),
+    CONSTRAINT fk_product_media_renditions_media FOREIGN KEY (media_id) REFERENCES product_media (id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+DROP INDEX idx_products_category_currency_price ON products;
+DROP INDEX idx_products_currency_price ON products;
+CREATE INDEX idx_products_price ON products (price);
+CREATE INDEX idx_products_category_price ON products (category, price);
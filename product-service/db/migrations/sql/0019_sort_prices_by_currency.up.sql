@@ -0,0 +1,6 @@
+-- Price filters compare the prices of each currency with the bound converted
+-- into it, so prices are indexed within their currency
+DROP INDEX idx_products_price ON products;
+DROP INDEX idx_products_category_price ON products;
+CREATE INDEX idx_products_currency_price ON products (currency, price);
+CREATE INDEX idx_products_category_currency_price ON products (category, currency, price);
@@ -0,0 +1,4 @@
+-- A relay claims the events it publishes for a while, so the relays of other
+-- replicas leave them be
+ALTER TABLE outbox_events ADD COLUMN claimed_by VARCHAR(64) NULL;
+ALTER TABLE outbox_events ADD COLUMN claimed_until DATETIME NULL;
@@ -9,44 +9,74 @@ import (
 
 const KafkaServer = "localhost:9092"
 
+// Producer publishes messages to Kafka over one long-lived connection
+type Producer struct {
+    producer *kafka.Producer
+}
 
-func PublishEvent(topic string, eventMessage interface{}) error {
+// NewProducer connects a Producer to the given comma-separated brokers. Each
+// message is acknowledged by all in-sync replicas before Publish returns.
+func NewProducer(brokers string) (*Producer, error) {
     producer, err := kafka.NewProducer(&kafka.ConfigMap{
-        "bootstrap.servers": KafkaServer,
+        "bootstrap.servers":  brokers,
+        "acks":               "all",
+        "enable.idempotence": true,
     })
     if err != nil {
-        return fmt.Errorf("failed to create Kafka producer: %v", err)
+        return nil, fmt.Errorf("failed to create Kafka producer: %v", err)
     }
-    defer producer.Close()
+    return &Producer{producer: producer}, nil
+}
 
-    messageValue, err := json.Marshal(eventMessage)
-    if err != nil {
-        return fmt.Errorf("failed to serialize event message: %v", err)
-    }
-
-    
-    err = producer.Produce(&kafka.Message{
+// Publish sends a message to a topic and waits until it is delivered.
+// Messages with the same key go to the same partition and keep their order.
+func (p *Producer) Publish(topic, key string, value []byte) error {
+    delivery := make(chan kafka.Event, 1)
+    msg := &kafka.Message{
         TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
-        Value:          messageValue,
-    }, nil)
-    if err != nil {
+        Value:          value,
+    }
+    if key != "" {
+        msg.Key = []byte(key)
+    }
+    if err := p.producer.Produce(msg, delivery); err != nil {
         return fmt.Errorf("failed to produce message: %v", err)
     }
 
-    for e := range producer.Events() {
-        switch ev := e.(type) {
-        case *kafka.Message:
-            if ev.TopicPartition.Error != nil {
-                return fmt.Errorf("delivery failed: %v", ev.TopicPartition.Error)
-            }
-            fmt.Printf("✅ Event published to topic %s: %s\n", topic, string(messageValue))
-            return nil
-        case kafka.Error:
-            return fmt.Errorf("kafka error: %v", ev)
-        default:
-            log.Printf("⚠️ Ignored event type: %T\n", ev)
+    switch ev := (<-delivery).(type) {
+    case *kafka.Message:
+        if ev.TopicPartition.Error != nil {
+            return fmt.Errorf("delivery failed: %v", ev.TopicPartition.Error)
         }
+        return nil
+    case kafka.Error:
+        return fmt.Errorf("kafka error: %v", ev)
+    default:
+        return fmt.Errorf("unexpected delivery event %T", ev)
+    }
+}
+
+// Close waits briefly for outstanding messages and closes the connection
+func (p *Producer) Close() {
+    p.producer.Flush(5000)
+    p.producer.Close()
+}
+
+func PublishEvent(topic string, eventMessage interface{}) error {
+    producer, err := NewProducer(KafkaServer)
+    if err != nil {
+        return err
     }
+    defer producer.Close()
 
-    return fmt.Errorf("no delivery event received")
-}
\ No newline at end of file
+    messageValue, err := json.Marshal(eventMessage)
+    if err != nil {
+        return fmt.Errorf("failed to serialize event message: %v", err)
+    }
+
+    if err := producer.Publish(topic, "", messageValue); err != nil {
+        return err
+    }
+    log.Printf("Event published to topic %s: %s", topic, string(messageValue))
+    return nil
+}
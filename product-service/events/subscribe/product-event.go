@@ -0,0 +1,74 @@
+package subscribe
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/confluentinc/confluent-kafka-go/kafka"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// ProductEventHandler is passed every product event read and where in the
+// topic it was read from
+type ProductEventHandler func(event *models.Event, partition int32, offset int64)
+
+// productEventReaderID identifies the consumers of ConsumeProductEvents to the
+// brokers. They are assigned their partitions rather than joining a group and
+// commit no offsets, so no group of this name is ever formed.
+const productEventReaderID = "product-service-product-event-reader"
+
+// ConsumeProductEvents passes every event published to the product event
+// topic to each of handlers until ctx is done. Every replica reads every
+// partition of the topic itself, starting from the events published after it
+// started; events that cannot be decoded are logged and skipped. Partitions
+// added to the topic later are read from the next start.
+func ConsumeProductEvents(ctx context.Context, brokers, topic string, handlers ...ProductEventHandler) error {
+    consumer, err := kafka.NewConsumer(&kafka.ConfigMap{
+        "bootstrap.servers":  brokers,
+        "group.id":           productEventReaderID,
+        "enable.auto.commit": false,
+    })
+    if err != nil {
+        return fmt.Errorf("failed to create Kafka consumer: %v", err)
+    }
+    defer consumer.Close()
+
+    metadata, err := consumer.GetMetadata(&topic, false, 10000)
+    if err != nil {
+        return fmt.Errorf("failed to read the partitions of topic %s: %v", topic, err)
+    }
+    described := metadata.Topics[topic]
+    if described.Error.Code() != kafka.ErrNoError {
+        return fmt.Errorf("failed to read the partitions of topic %s: %v", topic, described.Error)
+    }
+    partitions := make([]kafka.TopicPartition, len(described.Partitions))
+    for i, p := range described.Partitions {
+        partitions[i] = kafka.TopicPartition{Topic: &topic, Partition: p.ID, Offset: kafka.OffsetEnd}
+    }
+    if err := consumer.Assign(partitions); err != nil {
+        return fmt.Errorf("failed to assign the partitions of topic %s: %v", topic, err)
+    }
+
+    for ctx.Err() == nil {
+        msg, err := consumer.ReadMessage(time.Second)
+        if err != nil {
+            if kafkaErr, ok := err.(kafka.Error); ok && kafkaErr.Code() == kafka.ErrTimedOut {
+                continue
+            }
+            log.Printf("Consumer error: %v", err)
+            continue
+        }
+        var event models.Event
+        if err := json.Unmarshal(msg.Value, &event); err != nil {
+            log.Printf("Skipping malformed product event at %v: %v", msg.TopicPartition, err)
+            continue
+        }
+        for _, handle := range handlers {
+            handle(&event, msg.TopicPartition.Partition, int64(msg.TopicPartition.Offset))
+        }
+    }
+    return nil
+}
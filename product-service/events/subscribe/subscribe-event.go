@@ -1,91 +1,160 @@
 package subscribe
 
 import (
-    // "context"
-    // "encoding/json"
-    // "fmt"
-    // "github.com/confluentinc/confluent-kafka-go/kafka"
-    // "github.com/samObot19/shopverse/product-service/events/publish"
-    // "github.com/samObot19/shopverse/product-service/usecases"
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "strconv"
+    "time"
+
+    "github.com/confluentinc/confluent-kafka-go/kafka"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+const (
+    OrderCreatedTopic = "orderCreated"
+    StockEventTopic   = "stockEvent"
+    GroupID           = "product-service-group"
 )
 
-// const (
-//     KafkaServer       = "localhost:9092"
-//     StockEventTopic   = "stockEvent"
-//     OrderEventTopic   = "orderEvent"
-//     GroupID           = "order-service-group"
-// )
-
-// // SubscribeAndProcessStockEvent subscribes to the stockEvent topic and publishes to the orderEvent topic
-// func SubscribeAndProcessStockEvent(orderUsecase usecases.OrderUsecase) {
-//     // Create a new Kafka consumer
-//     consumer, err := kafka.NewConsumer(&kafka.ConfigMap{
-//         "bootstrap.servers": KafkaServer,
-//         "group.id":          GroupID,
-//         "auto.offset.reset": "earliest",
-//     })
-//     if err != nil {
-//         panic(fmt.Sprintf("Failed to create Kafka consumer: %v", err))
-//     }
-//     defer consumer.Close()
-
-//     // Subscribe to the stockEvent topic
-//     err = consumer.SubscribeTopics([]string{StockEventTopic}, nil)
-//     if err != nil {
-//         panic(fmt.Sprintf("Failed to subscribe to topic: %v", err))
-//     }
-
-//     fmt.Println("Listening to stockEvent topic...")
-//     for {
-//         // Read messages from the stockEvent topic
-//         msg, err := consumer.ReadMessage(-1)
-//         if err == nil {
-//             fmt.Printf("Received stock event: %s\n", string(msg.Value))
-
-//             // Deserialize the stock event message
-//             var stockEvent struct {
-//                 OrderID   uint `json:"order_id"`
-//                 StockAvailable bool `json:"stock_available"`
-//             }
-//             err := json.Unmarshal(msg.Value, &stockEvent)
-//             if err != nil {
-//                 fmt.Printf("Failed to deserialize stock event: %v\n", err)
-//                 continue
-//             }
-
-//             // Update order status based on stock availability
-//             var newStatus string
-//             if stockEvent.StockAvailable {
-//                 newStatus = "Accepted"
-//             } else {
-//                 newStatus = "Failed"
-//             }
-
-//             err = orderUsecase.UpdateOrderStatus(context.Background(), stockEvent.OrderID, newStatus)
-//             if err != nil {
-//                 fmt.Printf("Failed to update order status: %v\n", err)
-//                 continue
-//             }
-
-//             // Publish the updated order to the orderEvent topic
-//             order, err := orderUsecase.GetOrderByID(context.Background(), stockEvent.OrderID)
-//             if err != nil {
-//                 fmt.Printf("Failed to retrieve updated order: %v\n", err)
-//                 continue
-//             }
-
-//             err = publish.PublishEvent(OrderEventTopic, order)
-//             if err != nil {
-//                 fmt.Printf("Failed to publish order event: %v\n", err)
-//             } else {
-//                 fmt.Println("Successfully published updated order to orderEvent topic.")
-//             }
-//         } else {
-//             fmt.Printf("Consumer error: %v\n", err)
-//         }
-//     }
-// }
+// retryBackoff and maxRetryBackoff bound the wait before an order that could
+// not be checked is read again
+const (
+    retryBackoff    = time.Second
+    maxRetryBackoff = time.Minute
+)
+
+// StockChecker checks and reserves the stock of an order shipping to the
+// optional destination
+type StockChecker interface {
+    CheckOrderStock(ctx context.Context, orderID string, items []models.ReservationItem, destination *models.Location) (*models.StockCheck, error)
+}
+
+// Publisher sends a message to a topic and returns once it is delivered
+type Publisher interface {
+    Publish(topic, key string, value []byte) error
+}
+
+// orderCreated is the part of an order published by order-service that the
+// stock check needs
+type orderCreated struct {
+    ID    uint `json:"id"`
+    Items []struct {
+        ProductID string `json:"product_id"`
+        VariantID string `json:"variant_id"`
+        Quantity  int    `json:"quantity"`
+    } `json:"items"`
+    ShippingLocation *struct {
+        Latitude  float64 `json:"latitude"`
+        Longitude float64 `json:"longitude"`
+    } `json:"shipping_location"`
+}
+
+// stockEvent tells order-service whether the stock of an order was reserved
+type stockEvent struct {
+    OrderID        uint `json:"order_id"`
+    StockAvailable bool `json:"stock_available"`
+}
+
+// errMalformed marks a message that can never be handled
+type errMalformed struct{ err error }
+
+func (e errMalformed) Error() string { return "malformed order: " + e.err.Error() }
+
+// HandleOrderCreated checks the stock of the order in an orderCreated message
+// and publishes the outcome to the stockEvent topic, keyed by order ID. A
+// message that is not an order returns an errMalformed.
+func HandleOrderCreated(ctx context.Context, value []byte, checker StockChecker, publisher Publisher) error {
+    var order orderCreated
+    if err := json.Unmarshal(value, &order); err != nil {
+        return errMalformed{err}
+    }
+    if order.ID == 0 {
+        return errMalformed{fmt.Errorf("order has no ID")}
+    }
+
+    items := make([]models.ReservationItem, 0, len(order.Items))
+    for _, item := range order.Items {
+        items = append(items, models.ReservationItem{ProductID: item.ProductID, VariantID: item.VariantID, Quantity: item.Quantity})
+    }
+    var destination *models.Location
+    if l := order.ShippingLocation; l != nil {
+        destination = &models.Location{Latitude: l.Latitude, Longitude: l.Longitude}
+    }
+    orderID := strconv.FormatUint(uint64(order.ID), 10)
+    check, err := checker.CheckOrderStock(ctx, orderID, items, destination)
+    if err != nil {
+        return fmt.Errorf("failed to check stock of order %s: %w", orderID, err)
+    }
+
+    event, err := json.Marshal(stockEvent{OrderID: order.ID, StockAvailable: check.StockAvailable})
+    if err != nil {
+        return err
+    }
+    if err := publisher.Publish(StockEventTopic, orderID, event); err != nil {
+        return fmt.Errorf("failed to publish stock event of order %s: %w", orderID, err)
+    }
+    log.Printf("Stock of order %s checked: available %t %s", orderID, check.StockAvailable, check.Reason)
+    return nil
+}
+
+// ConsumeOrderCreated handles the orderCreated topic until ctx is done. An
+// offset is only committed once the stock event of its order is published, so
+// every order is answered at least once; as the outcome of a check is recorded
+// per order, an order read again is answered the same way without reserving
+// its stock twice. An order that fails is read again after a backoff.
+func ConsumeOrderCreated(ctx context.Context, brokers string, checker StockChecker, publisher Publisher) error {
+    consumer, err := kafka.NewConsumer(&kafka.ConfigMap{
+        "bootstrap.servers":  brokers,
+        "group.id":           GroupID,
+        "auto.offset.reset":  "earliest",
+        "enable.auto.commit": false,
+    })
+    if err != nil {
+        return fmt.Errorf("failed to create Kafka consumer: %v", err)
+    }
+    defer consumer.Close()
 
+    if err := consumer.SubscribeTopics([]string{OrderCreatedTopic}, nil); err != nil {
+        return fmt.Errorf("failed to subscribe to topic %s: %v", OrderCreatedTopic, err)
+    }
 
+    backoff := retryBackoff
+    for ctx.Err() == nil {
+        msg, err := consumer.ReadMessage(time.Second)
+        if err != nil {
+            if kafkaErr, ok := err.(kafka.Error); ok && kafkaErr.Code() == kafka.ErrTimedOut {
+                continue
+            }
+            log.Printf("Consumer error: %v", err)
+            continue
+        }
 
+        err = HandleOrderCreated(ctx, msg.Value, checker, publisher)
+        if _, ok := err.(errMalformed); ok {
+            log.Printf("Skipping message at %v: %v", msg.TopicPartition, err)
+            err = nil
+        }
+        if err != nil {
+            log.Printf("Failed to handle order, retrying in %s: %v", backoff, err)
+            if err := consumer.Seek(msg.TopicPartition, 0); err != nil {
+                log.Printf("Failed to rewind to %v: %v", msg.TopicPartition, err)
+            }
+            select {
+            case <-ctx.Done():
+            case <-time.After(backoff):
+            }
+            if backoff *= 2; backoff > maxRetryBackoff {
+                backoff = maxRetryBackoff
+            }
+            continue
+        }
+        backoff = retryBackoff
 
+        if _, err := consumer.CommitMessage(msg); err != nil {
+            log.Printf("Failed to commit offset %v: %v", msg.TopicPartition, err)
+        }
+    }
+    return nil
+}
@@ -0,0 +1,116 @@
+package subscribe
+
+import (
+    "context"
+    "errors"
+    "testing"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+type fakeChecker struct {
+    items       []models.ReservationItem
+    destination *models.Location
+    check       *models.StockCheck
+    err         error
+}
+
+func (c *fakeChecker) CheckOrderStock(ctx context.Context, orderID string, items []models.ReservationItem, destination *models.Location) (*models.StockCheck, error) {
+    c.items, c.destination = items, destination
+    if c.err != nil {
+        return nil, c.err
+    }
+    check := *c.check
+    check.OrderID = orderID
+    return &check, nil
+}
+
+type recordingPublisher struct {
+    topic, key string
+    value      string
+}
+
+func (p *recordingPublisher) Publish(topic, key string, value []byte) error {
+    p.topic, p.key, p.value = topic, key, string(value)
+    return nil
+}
+
+func TestHandleOrderCreated(t *testing.T) {
+    order := `{"id": 42, "user_id": "u1", "Items": [
+        {"id": 1, "product_id": "p1", "quantity": 2},
+        {"id": 2, "product_id": "p2", "variant_id": "v1", "quantity": 1}]}`
+
+    tests := []struct {
+        name      string
+        available bool
+        want      string
+    }{
+        {"available", true, `{"order_id":42,"stock_available":true}`},
+        {"unavailable", false, `{"order_id":42,"stock_available":false}`},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            checker := &fakeChecker{check: &models.StockCheck{StockAvailable: tt.available}}
+            publisher := &recordingPublisher{}
+            if err := HandleOrderCreated(context.Background(), []byte(order), checker, publisher); err != nil {
+                t.Fatalf("HandleOrderCreated: %v", err)
+            }
+            if len(checker.items) != 2 || checker.items[1].VariantID != "v1" || checker.items[0].Quantity != 2 {
+                t.Errorf("checked items %+v", checker.items)
+            }
+            if publisher.topic != StockEventTopic || publisher.key != "42" || publisher.value != tt.want {
+                t.Errorf("published %s/%s %s, want %s/42 %s", publisher.topic, publisher.key, publisher.value, StockEventTopic, tt.want)
+            }
+        })
+    }
+}
+
+func TestHandleOrderCreatedErrors(t *testing.T) {
+    tests := []struct {
+        name      string
+        value     string
+        checkErr  error
+        malformed bool
+    }{
+        {"not JSON", `{`, nil, true},
+        {"no order ID", `{"items": []}`, nil, true},
+        {"check failed", `{"id": 7}`, errors.New("connection refused"), false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            publisher := &recordingPublisher{}
+            err := HandleOrderCreated(context.Background(), []byte(tt.value), &fakeChecker{err: tt.checkErr}, publisher)
+            if err == nil {
+                t.Fatal("expected an error")
+            }
+            if _, ok := err.(errMalformed); ok != tt.malformed {
+                t.Errorf("malformed = %t, want %t: %v", ok, tt.malformed, err)
+            }
+            if publisher.topic != "" {
+                t.Errorf("published a stock event after %v", err)
+            }
+        })
+    }
+}
+
+func TestHandleOrderCreatedPassesShippingLocation(t *testing.T) {
+    tests := []struct {
+        name  string
+        value string
+        want  *models.Location
+    }{
+        {"with location", `{"id": 42, "shipping_location": {"latitude": 50.85, "longitude": 4.35}}`, &models.Location{Latitude: 50.85, Longitude: 4.35}},
+        {"without location", `{"id": 42}`, nil},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            checker := &fakeChecker{check: &models.StockCheck{StockAvailable: true}}
+            if err := HandleOrderCreated(context.Background(), []byte(tt.value), checker, &recordingPublisher{}); err != nil {
+                t.Fatalf("HandleOrderCreated: %v", err)
+            }
+            if (checker.destination == nil) != (tt.want == nil) || tt.want != nil && *checker.destination != *tt.want {
+                t.Errorf("checked with destination %+v, want %+v", checker.destination, tt.want)
+            }
+        })
+    }
+}
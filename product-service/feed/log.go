@@ -0,0 +1,184 @@
+// Package feed keeps the latest catalogue changes in memory for clients that
+// watch the catalogue.
+package feed
+
+import (
+    "errors"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+var (
+    // ErrInvalidToken is returned for a token that is not one the log hands out
+    ErrInvalidToken = errors.New("invalid resume token")
+    // ErrTokenExpired is returned for a token whose following changes are no
+    // longer held, because the log has moved past them
+    ErrTokenExpired = errors.New("resume token expired")
+)
+
+// tokenPrefix starts every token, telling them from tokens of other formats
+const tokenPrefix = "offsets:"
+
+// Change is an event held in the log and the token to resume reading after it
+type Change struct {
+    Token string
+    Event *models.Event
+}
+
+// entry is an event held in the log and where it was read from the product
+// event topic
+type entry struct {
+    event     *models.Event
+    partition int32
+    offset    int64
+}
+
+// Log holds the last size events appended to it. Readers keep their own
+// position as a token, so a slow reader holds no events of its own; one that
+// falls more than size events behind has its token expire.
+//
+// A token is the offset of the last event a reader was given in each
+// partition of the product event topic. Every replica reads the whole topic,
+// so a token stays valid after a restart and on other replicas for as long as
+// they hold the events following it. Events of a partition the token has no
+// offset for are all returned, so a reader may be given some twice.
+type Log struct {
+    size int
+
+    mu        sync.Mutex
+    entries   []entry // the oldest event held is at first
+    first     int
+    held      int
+    positions map[int32]int64 // offset of the last event appended from each partition
+    floors    map[int32]int64 // offset of each partition from which the events after are held
+    seen      map[string]bool // IDs of the events held, as events may be delivered twice
+    appended  chan struct{}   // closed and replaced by every append
+}
+
+// NewLog creates a Log holding up to size events
+func NewLog(size int) *Log {
+    return &Log{
+        size:      size,
+        entries:   make([]entry, size),
+        positions: make(map[int32]int64),
+        floors:    make(map[int32]int64),
+        seen:      make(map[string]bool, size),
+        appended:  make(chan struct{}),
+    }
+}
+
+// Append adds an event read at offset of partition, dropping the oldest one
+// once the log is full. An event whose ID is already held, or read again from
+// where it was already read, is not added again.
+func (l *Log) Append(event *models.Event, partition int32, offset int64) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    if last, ok := l.positions[partition]; l.seen[event.ID] || ok && offset <= last {
+        return
+    }
+    if _, ok := l.floors[partition]; !ok {
+        l.floors[partition] = offset - 1
+    }
+    if l.held == l.size {
+        oldest := l.entries[l.first]
+        delete(l.seen, oldest.event.ID)
+        l.floors[oldest.partition] = oldest.offset
+        l.first = (l.first + 1) % l.size
+        l.held--
+    }
+    l.entries[(l.first+l.held)%l.size] = entry{event: event, partition: partition, offset: offset}
+    l.seen[event.ID] = true
+    l.positions[partition] = offset
+    l.held++
+    close(l.appended)
+    l.appended = make(chan struct{})
+}
+
+// Head returns the token of the latest events, to read the events appended from now on
+func (l *Log) Head() string {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return encode(l.positions)
+}
+
+// Read returns up to max events appended after the ones token was handed out
+// for. When there are none yet it returns a channel that is closed on the next
+// append instead.
+func (l *Log) Read(token string, max int) ([]Change, <-chan struct{}, error) {
+    positions, err := parse(token)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    for partition, offset := range positions {
+        if floor, ok := l.floors[partition]; ok && offset < floor {
+            return nil, nil, ErrTokenExpired
+        }
+    }
+
+    var changes []Change
+    for i := 0; i < l.held && len(changes) < max; i++ {
+        e := l.entries[(l.first+i)%l.size]
+        if last, ok := positions[e.partition]; ok && e.offset <= last {
+            continue
+        }
+        positions[e.partition] = e.offset
+        changes = append(changes, Change{Token: encode(positions), Event: e.event})
+    }
+    if len(changes) == 0 {
+        return nil, l.appended, nil
+    }
+    return changes, nil, nil
+}
+
+// encode makes the token of the offset of each partition, as "offsets:0=41,1=17"
+func encode(positions map[int32]int64) string {
+    partitions := make([]int32, 0, len(positions))
+    for partition := range positions {
+        partitions = append(partitions, partition)
+    }
+    sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+
+    pairs := make([]string, len(partitions))
+    for i, partition := range partitions {
+        pairs[i] = strconv.FormatInt(int64(partition), 10) + "=" + strconv.FormatInt(positions[partition], 10)
+    }
+    return tokenPrefix + strings.Join(pairs, ",")
+}
+
+// parse returns the offset of each partition a token was handed out for
+func parse(token string) (map[int32]int64, error) {
+    encoded, ok := strings.CutPrefix(token, tokenPrefix)
+    if !ok {
+        return nil, ErrInvalidToken
+    }
+    positions := make(map[int32]int64)
+    if encoded == "" {
+        return positions, nil
+    }
+    for _, pair := range strings.Split(encoded, ",") {
+        p, o, ok := strings.Cut(pair, "=")
+        if !ok {
+            return nil, ErrInvalidToken
+        }
+        partition, err := strconv.ParseInt(p, 10, 32)
+        if err != nil || partition < 0 {
+            return nil, ErrInvalidToken
+        }
+        offset, err := strconv.ParseInt(o, 10, 64)
+        if err != nil || offset < 0 {
+            return nil, ErrInvalidToken
+        }
+        if _, repeated := positions[int32(partition)]; repeated {
+            return nil, ErrInvalidToken
+        }
+        positions[int32(partition)] = offset
+    }
+    return positions, nil
+}
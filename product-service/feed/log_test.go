@@ -0,0 +1,168 @@
+package feed
+
+import (
+    "errors"
+    "fmt"
+    "reflect"
+    "testing"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+func event(n int) *models.Event {
+    return &models.Event{ID: fmt.Sprintf("e-%d", n), Type: models.EventProductUpdated, ProductID: "p-1"}
+}
+
+func eventIDs(changes []Change) []string {
+    ids := make([]string, len(changes))
+    for i, change := range changes {
+        ids[i] = change.Event.ID
+    }
+    return ids
+}
+
+func TestReadResumesAfterToken(t *testing.T) {
+    l := NewLog(10)
+    start := l.Head()
+
+    changes, wait, err := l.Read(start, 10)
+    if err != nil || len(changes) != 0 || wait == nil {
+        t.Fatalf("Read() of an empty log = %v, %v, %v, want a channel to wait on", changes, wait, err)
+    }
+    for i := 1; i <= 3; i++ {
+        l.Append(event(i), 0, int64(i))
+    }
+    select {
+    case <-wait:
+    default:
+        t.Fatal("wait channel not closed by Append")
+    }
+
+    changes, _, err = l.Read(start, 2)
+    if err != nil {
+        t.Fatalf("Read() error = %v", err)
+    }
+    if got := eventIDs(changes); !reflect.DeepEqual(got, []string{"e-1", "e-2"}) {
+        t.Fatalf("Read() = %v, want e-1 and e-2", got)
+    }
+    changes, _, err = l.Read(changes[1].Token, 10)
+    if err != nil {
+        t.Fatalf("Read() error = %v", err)
+    }
+    if got := eventIDs(changes); !reflect.DeepEqual(got, []string{"e-3"}) {
+        t.Fatalf("Read() = %v, want e-3", got)
+    }
+    if head := l.Head(); head != changes[0].Token {
+        t.Errorf("Head() = %q, want the token of e-3 %q", head, changes[0].Token)
+    }
+}
+
+func TestAppendDropsRepeats(t *testing.T) {
+    l := NewLog(10)
+    start := l.Head()
+    l.Append(event(1), 0, 1)
+    l.Append(event(1), 0, 2) // published twice
+    l.Append(event(2), 0, 3)
+    l.Append(event(2), 0, 3) // read twice
+
+    changes, _, err := l.Read(start, 10)
+    if err != nil {
+        t.Fatalf("Read() error = %v", err)
+    }
+    if got := eventIDs(changes); !reflect.DeepEqual(got, []string{"e-1", "e-2"}) {
+        t.Errorf("Read() = %v, want e-1 and e-2", got)
+    }
+}
+
+func TestReadRejectsTokens(t *testing.T) {
+    l := NewLog(2)
+    start := l.Head()
+    for i := 1; i <= 3; i++ {
+        l.Append(event(i), 0, int64(i))
+    }
+
+    tests := []struct {
+        name  string
+        token string
+        want  error
+    }{
+        {"malformed", "not-a-token", ErrInvalidToken},
+        {"bad offset", "offsets:0=x", ErrInvalidToken},
+        {"negative offset", "offsets:0=-2", ErrInvalidToken},
+        {"repeated partition", "offsets:0=2,0=3", ErrInvalidToken},
+        {"fallen behind", "offsets:0=0", ErrTokenExpired},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if _, _, err := l.Read(tt.token, 10); !errors.Is(err, tt.want) {
+                t.Errorf("Read(%q) error = %v, want %v", tt.token, err, tt.want)
+            }
+        })
+    }
+
+    // the oldest event still held can be resumed after the one before it
+    changes, _, err := l.Read("offsets:0=1", 10)
+    if err != nil {
+        t.Fatalf("Read() error = %v", err)
+    }
+    if got := eventIDs(changes); !reflect.DeepEqual(got, []string{"e-2", "e-3"}) {
+        t.Errorf("Read() = %v, want e-2 and e-3", got)
+    }
+    // an empty log hands out a token with no partitions, which reads everything held
+    if changes, _, err := l.Read(start, 10); err != nil || len(changes) != 2 {
+        t.Errorf("Read(%q) = %v, %v, want e-2 and e-3", start, eventIDs(changes), err)
+    }
+}
+
+func TestTokensResumeOnOtherLogs(t *testing.T) {
+    // the same events of two partitions, read in another order by each replica
+    a, b := NewLog(10), NewLog(10)
+    a.Append(event(1), 0, 10)
+    a.Append(event(2), 1, 20)
+    a.Append(event(3), 0, 11)
+    b.Append(event(2), 1, 20)
+    b.Append(event(1), 0, 10)
+    b.Append(event(3), 0, 11)
+
+    changes, _, err := a.Read("offsets:", 2)
+    if err != nil {
+        t.Fatalf("Read() error = %v", err)
+    }
+    token := changes[1].Token
+    if token != "offsets:0=10,1=20" {
+        t.Fatalf("token of e-2 = %q, want offsets:0=10,1=20", token)
+    }
+
+    changes, _, err = b.Read(token, 10)
+    if err != nil {
+        t.Fatalf("Read() on another log error = %v", err)
+    }
+    if got := eventIDs(changes); !reflect.DeepEqual(got, []string{"e-3"}) {
+        t.Errorf("Read() on another log = %v, want e-3", got)
+    }
+
+    // a replica that has not read as far yet waits for the events after the token
+    lagging := NewLog(10)
+    lagging.Append(event(1), 0, 10)
+    changes, wait, err := lagging.Read(a.Head(), 10)
+    if err != nil || len(changes) != 0 || wait == nil {
+        t.Fatalf("Read() on a lagging log = %v, %v, %v, want a channel to wait on", eventIDs(changes), wait, err)
+    }
+    lagging.Append(event(2), 1, 20)
+    lagging.Append(event(3), 0, 11)
+    lagging.Append(event(4), 1, 21)
+    changes, _, err = lagging.Read(a.Head(), 10)
+    if err != nil {
+        t.Fatalf("Read() error = %v", err)
+    }
+    if got := eventIDs(changes); !reflect.DeepEqual(got, []string{"e-4"}) {
+        t.Errorf("Read() on a lagging log = %v, want e-4", got)
+    }
+
+    // a replica started after the token was handed out does not hold the events following it
+    restarted := NewLog(10)
+    restarted.Append(event(5), 0, 30)
+    if _, _, err := restarted.Read("offsets:0=10", 10); !errors.Is(err, ErrTokenExpired) {
+        t.Errorf("Read() on a restarted log error = %v, want %v", err, ErrTokenExpired)
+    }
+}
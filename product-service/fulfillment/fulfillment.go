@@ -0,0 +1,173 @@
+// Package fulfillment decides which warehouses a reservation takes its stock from.
+package fulfillment
+
+import (
+    "errors"
+    "fmt"
+    "math"
+    "sort"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// Strategy ranks the warehouses that could fulfil a reservation
+type Strategy string
+
+const (
+    // Nearest prefers the warehouse closest to the destination; without a
+    // destination it behaves like MostStock
+    Nearest Strategy = "nearest"
+    // MostStock prefers the warehouse holding the most of the requested items
+    MostStock Strategy = "most_stock"
+)
+
+// ErrUnfulfillable is returned when the active warehouses together do not hold
+// enough stock for an item
+var ErrUnfulfillable = errors.New("not enough stock across warehouses")
+
+// ParseStrategy parses a strategy name as used in configuration
+func ParseStrategy(name string) (Strategy, error) {
+    switch Strategy(name) {
+    case Nearest, MostStock:
+        return Strategy(name), nil
+    }
+    return "", fmt.Errorf("unknown fulfillment strategy %q, expected %q or %q", name, Nearest, MostStock)
+}
+
+type stockKey struct{ warehouseID, productID, variantID string }
+
+// Plan assigns every item to warehouses. A single warehouse that holds all
+// items is preferred, so the order ships in one parcel; otherwise each item is
+// taken from the best ranked warehouses that hold it, split if it must be.
+// Inactive warehouses are skipped.
+func Plan(strategy Strategy, warehouses []*models.Warehouse, levels []models.StockLevel, items []models.ReservationItem, destination *models.Location) ([]models.ReservationItem, error) {
+    active := make(map[string]*models.Warehouse, len(warehouses))
+    for _, w := range warehouses {
+        if w.Active {
+            active[w.ID] = w
+        }
+    }
+    available := make(map[stockKey]int, len(levels))
+    for _, level := range levels {
+        if _, ok := active[level.WarehouseID]; ok && level.Quantity > 0 {
+            available[stockKey{level.WarehouseID, level.ProductID, level.VariantID}] += level.Quantity
+        }
+    }
+
+    ranked := rank(strategy, active, available, items, destination)
+    for _, w := range ranked {
+        if holdsAll(w.ID, available, items) {
+            planned := make([]models.ReservationItem, len(items))
+            for i, item := range items {
+                item.WarehouseID = w.ID
+                planned[i] = item
+            }
+            return planned, nil
+        }
+    }
+
+    var planned []models.ReservationItem
+    for _, item := range items {
+        remaining := item.Quantity
+        for _, w := range rankForItem(strategy, ranked, available, item) {
+            if remaining == 0 {
+                break
+            }
+            key := stockKey{w.ID, item.ProductID, item.VariantID}
+            take := min(available[key], remaining)
+            if take == 0 {
+                continue
+            }
+            available[key] -= take
+            remaining -= take
+
+            part := item
+            part.WarehouseID = w.ID
+            part.Quantity = take
+            planned = append(planned, part)
+        }
+        if remaining > 0 {
+            if item.VariantID != "" {
+                return nil, fmt.Errorf("%w for variant %s of product %s", ErrUnfulfillable, item.VariantID, item.ProductID)
+            }
+            return nil, fmt.Errorf("%w for product %s", ErrUnfulfillable, item.ProductID)
+        }
+    }
+    return planned, nil
+}
+
+// rank orders the active warehouses by strategy, breaking ties by ID
+func rank(strategy Strategy, active map[string]*models.Warehouse, available map[stockKey]int, items []models.ReservationItem, destination *models.Location) []*models.Warehouse {
+    ranked := make([]*models.Warehouse, 0, len(active))
+    for _, w := range active {
+        ranked = append(ranked, w)
+    }
+
+    if strategy == Nearest && destination != nil {
+        distance := make(map[string]float64, len(ranked))
+        for _, w := range ranked {
+            distance[w.ID] = Distance(models.Location{Latitude: w.Latitude, Longitude: w.Longitude}, *destination)
+        }
+        sort.Slice(ranked, func(i, j int) bool {
+            if distance[ranked[i].ID] != distance[ranked[j].ID] {
+                return distance[ranked[i].ID] < distance[ranked[j].ID]
+            }
+            return ranked[i].ID < ranked[j].ID
+        })
+        return ranked
+    }
+
+    held := make(map[string]int, len(ranked))
+    for _, w := range ranked {
+        for _, item := range items {
+            held[w.ID] += available[stockKey{w.ID, item.ProductID, item.VariantID}]
+        }
+    }
+    sort.Slice(ranked, func(i, j int) bool {
+        if held[ranked[i].ID] != held[ranked[j].ID] {
+            return held[ranked[i].ID] > held[ranked[j].ID]
+        }
+        return ranked[i].ID < ranked[j].ID
+    })
+    return ranked
+}
+
+// rankForItem reorders warehouses for splitting one item: by distance they
+// keep their order, by stock the warehouses holding most of the item come first
+func rankForItem(strategy Strategy, ranked []*models.Warehouse, available map[stockKey]int, item models.ReservationItem) []*models.Warehouse {
+    if strategy == Nearest {
+        return ranked
+    }
+    byItem := append([]*models.Warehouse(nil), ranked...)
+    sort.SliceStable(byItem, func(i, j int) bool {
+        return available[stockKey{byItem[i].ID, item.ProductID, item.VariantID}] >
+            available[stockKey{byItem[j].ID, item.ProductID, item.VariantID}]
+    })
+    return byItem
+}
+
+func holdsAll(warehouseID string, available map[stockKey]int, items []models.ReservationItem) bool {
+    for _, item := range items {
+        if available[stockKey{warehouseID, item.ProductID, item.VariantID}] < item.Quantity {
+            return false
+        }
+    }
+    return true
+}
+
+// earthRadiusKm is the mean radius of the Earth
+const earthRadiusKm = 6371.0
+
+// Distance returns the great-circle distance between two locations in kilometres
+func Distance(a, b models.Location) float64 {
+    lat1, lat2 := radians(a.Latitude), radians(b.Latitude)
+    dLat := lat2 - lat1
+    dLon := radians(b.Longitude - a.Longitude)
+
+    h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+    return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(degrees float64) float64 {
+    return degrees * math.Pi / 180
+}
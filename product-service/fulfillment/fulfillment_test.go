@@ -0,0 +1,137 @@
+package fulfillment
+
+import (
+    "errors"
+    "math"
+    "reflect"
+    "testing"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+var (
+    berlin = &models.Warehouse{ID: "berlin", Latitude: 52.52, Longitude: 13.40, Active: true}
+    paris  = &models.Warehouse{ID: "paris", Latitude: 48.86, Longitude: 2.35, Active: true}
+    madrid = &models.Warehouse{ID: "madrid", Latitude: 40.42, Longitude: -3.70, Active: true}
+    closed = &models.Warehouse{ID: "closed", Latitude: 50.85, Longitude: 4.35, Active: false}
+
+    warehouses = []*models.Warehouse{berlin, paris, madrid, closed}
+    lyon       = &models.Location{Latitude: 45.76, Longitude: 4.84}
+)
+
+func level(warehouseID, productID string, quantity int) models.StockLevel {
+    return models.StockLevel{WarehouseID: warehouseID, ProductID: productID, Quantity: quantity}
+}
+
+func item(productID string, quantity int) models.ReservationItem {
+    return models.ReservationItem{ProductID: productID, Quantity: quantity}
+}
+
+func planned(warehouseID, productID string, quantity int) models.ReservationItem {
+    return models.ReservationItem{ProductID: productID, WarehouseID: warehouseID, Quantity: quantity}
+}
+
+func TestPlan(t *testing.T) {
+    tests := []struct {
+        name        string
+        strategy    Strategy
+        levels      []models.StockLevel
+        items       []models.ReservationItem
+        destination *models.Location
+        want        []models.ReservationItem
+    }{
+        {
+            name:        "nearest warehouse holding everything",
+            strategy:    Nearest,
+            levels:      []models.StockLevel{level("berlin", "a", 9), level("paris", "a", 2), level("madrid", "a", 5)},
+            items:       []models.ReservationItem{item("a", 2)},
+            destination: lyon,
+            want:        []models.ReservationItem{planned("paris", "a", 2)},
+        },
+        {
+            name:        "nearest skips a warehouse that lacks an item",
+            strategy:    Nearest,
+            levels:      []models.StockLevel{level("paris", "a", 2), level("madrid", "a", 2), level("madrid", "b", 1)},
+            items:       []models.ReservationItem{item("a", 1), item("b", 1)},
+            destination: lyon,
+            want:        []models.ReservationItem{planned("madrid", "a", 1), planned("madrid", "b", 1)},
+        },
+        {
+            name:        "nearest without a destination falls back to most stock",
+            strategy:    Nearest,
+            levels:      []models.StockLevel{level("berlin", "a", 9), level("paris", "a", 2)},
+            items:       []models.ReservationItem{item("a", 2)},
+            destination: nil,
+            want:        []models.ReservationItem{planned("berlin", "a", 2)},
+        },
+        {
+            name:     "most stock",
+            strategy: MostStock,
+            levels:   []models.StockLevel{level("berlin", "a", 3), level("paris", "a", 8)},
+            items:    []models.ReservationItem{item("a", 2)},
+            want:     []models.ReservationItem{planned("paris", "a", 2)},
+        },
+        {
+            name:     "inactive warehouses are ignored",
+            strategy: MostStock,
+            levels:   []models.StockLevel{level("closed", "a", 50), level("berlin", "a", 3)},
+            items:    []models.ReservationItem{item("a", 2)},
+            want:     []models.ReservationItem{planned("berlin", "a", 2)},
+        },
+        {
+            name:     "items split when no warehouse holds everything",
+            strategy: MostStock,
+            levels:   []models.StockLevel{level("berlin", "a", 3), level("paris", "a", 2), level("paris", "b", 1)},
+            items:    []models.ReservationItem{item("a", 4), item("b", 1)},
+            want: []models.ReservationItem{
+                planned("berlin", "a", 3), planned("paris", "a", 1), planned("paris", "b", 1),
+            },
+        },
+        {
+            name:        "nearest splits in distance order",
+            strategy:    Nearest,
+            levels:      []models.StockLevel{level("berlin", "a", 5), level("paris", "a", 1), level("madrid", "a", 1)},
+            items:       []models.ReservationItem{item("a", 7)},
+            destination: lyon,
+            want: []models.ReservationItem{
+                planned("paris", "a", 1), planned("madrid", "a", 1), planned("berlin", "a", 5),
+            },
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, err := Plan(tt.strategy, warehouses, tt.levels, tt.items, tt.destination)
+            if err != nil {
+                t.Fatalf("Plan() error = %v", err)
+            }
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("Plan() = %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestPlanUnfulfillable(t *testing.T) {
+    levels := []models.StockLevel{level("berlin", "a", 1), level("closed", "a", 10)}
+    _, err := Plan(MostStock, warehouses, levels, []models.ReservationItem{item("a", 2)}, nil)
+    if !errors.Is(err, ErrUnfulfillable) {
+        t.Errorf("Plan() error = %v, want ErrUnfulfillable", err)
+    }
+}
+
+func TestDistance(t *testing.T) {
+    got := Distance(models.Location{Latitude: 48.86, Longitude: 2.35}, models.Location{Latitude: 51.51, Longitude: -0.13})
+    if math.Abs(got-343) > 2 {
+        t.Errorf("Distance(Paris, London) = %.1f km, want about 343 km", got)
+    }
+}
+
+func TestParseStrategy(t *testing.T) {
+    if s, err := ParseStrategy("nearest"); err != nil || s != Nearest {
+        t.Errorf("ParseStrategy(nearest) = %q, %v", s, err)
+    }
+    if _, err := ParseStrategy("cheapest"); err == nil {
+        t.Error("ParseStrategy(cheapest) succeeded, want an error")
+    }
+}
@@ -0,0 +1,129 @@
+// Package locale picks the content of a product to show a shopper from its
+// translations, following a fallback chain such as fr-CA, fr and then the
+// product's default content.
+package locale
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// Normalize checks that tag is a well-formed BCP 47 language tag and returns
+// it in its usual case, such as fr-CA for FR_ca: the language in lower case,
+// a script in title case and a region in upper case
+func Normalize(tag string) (string, error) {
+    subtags := strings.FieldsFunc(strings.TrimSpace(tag), func(r rune) bool { return r == '-' || r == '_' })
+    if len(subtags) == 0 {
+        return "", fmt.Errorf("invalid locale %q", tag)
+    }
+    for i, subtag := range subtags {
+        if !alphanumeric(subtag) || len(subtag) > 8 {
+            return "", fmt.Errorf("invalid locale %q", tag)
+        }
+        subtag = strings.ToLower(subtag)
+        switch {
+        case i == 0:
+            if len(subtag) < 2 || !letters(subtag) {
+                return "", fmt.Errorf("invalid locale %q: %q is not a language", tag, subtag)
+            }
+        case len(subtag) == 4 && letters(subtag):
+            subtag = strings.ToUpper(subtag[:1]) + subtag[1:]
+        case len(subtag) == 2 && letters(subtag), len(subtag) == 3 && !letters(subtag):
+            subtag = strings.ToUpper(subtag)
+        }
+        subtags[i] = subtag
+    }
+    return strings.Join(subtags, "-"), nil
+}
+
+func alphanumeric(s string) bool {
+    for _, r := range s {
+        if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9') {
+            return false
+        }
+    }
+    return s != ""
+}
+
+func letters(s string) bool {
+    for _, r := range s {
+        if '0' <= r && r <= '9' {
+            return false
+        }
+    }
+    return true
+}
+
+// Chain turns the locales a shopper asked for, most preferred first, into
+// the order their translations are looked for in: each locale followed by
+// the ones it falls back to, so fr-CA, en gives fr-CA, fr, en. Malformed and
+// repeated locales are skipped. The default content comes after the chain.
+func Chain(tags []string) []string {
+    var chain []string
+    seen := make(map[string]bool)
+    for _, tag := range tags {
+        normalized, err := Normalize(tag)
+        if err != nil {
+            continue
+        }
+        for t := normalized; t != ""; t = parent(t) {
+            if !seen[t] {
+                seen[t] = true
+                chain = append(chain, t)
+            }
+        }
+    }
+    return chain
+}
+
+// parent drops the last subtag of a tag, and any single-letter subtag that
+// would be left at its end, such as the x of en-x-private
+func parent(tag string) string {
+    i := strings.LastIndexByte(tag, '-')
+    if i < 0 {
+        return ""
+    }
+    tag = tag[:i]
+    if j := strings.LastIndexByte(tag, '-'); j >= 0 && len(tag)-j == 2 {
+        return tag[:j]
+    }
+    return tag
+}
+
+// Resolve returns the title and description of a product in the first
+// locale of chain that translates each of them, falling back to its default
+// content, along with the locale the title was taken from
+func Resolve(product *models.Product, chain []string) (title, description, locale string) {
+    title, description = product.Title, product.Description
+    titleFound, descriptionFound := false, false
+    for _, tag := range chain {
+        translation, ok := product.Translations[tag]
+        if !ok {
+            continue
+        }
+        if !titleFound && translation.Title != "" {
+            title, locale, titleFound = translation.Title, tag, true
+        }
+        if !descriptionFound && translation.Description != "" {
+            description, descriptionFound = translation.Description, true
+        }
+        if titleFound && descriptionFound {
+            break
+        }
+    }
+    return title, description, locale
+}
+
+// Localize shows products in the locales of chain: their title and
+// description are replaced with those Resolve picks and Locale is set. The
+// translations themselves are kept.
+func Localize(chain []string, products ...*models.Product) {
+    if len(chain) == 0 {
+        return
+    }
+    for _, product := range products {
+        product.Title, product.Description, product.Locale = Resolve(product, chain)
+    }
+}
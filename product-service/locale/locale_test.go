@@ -0,0 +1,96 @@
+package locale
+
+import (
+    "reflect"
+    "testing"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+func TestNormalize(t *testing.T) {
+    tests := []struct {
+        tag     string
+        want    string
+        wantErr bool
+    }{
+        {tag: "fr", want: "fr"},
+        {tag: "FR_ca", want: "fr-CA"},
+        {tag: " zh-hant-tw ", want: "zh-Hant-TW"},
+        {tag: "es-419", want: "es-419"},
+        {tag: "", wantErr: true},
+        {tag: "f", wantErr: true},
+        {tag: "12-US", wantErr: true},
+        {tag: "en--US", want: "en-US"},
+        {tag: "en-toolongsubtag", wantErr: true},
+        {tag: "fr-ça", wantErr: true},
+    }
+    for _, tt := range tests {
+        got, err := Normalize(tt.tag)
+        if (err != nil) != tt.wantErr {
+            t.Errorf("Normalize(%q) error = %v, wantErr %v", tt.tag, err, tt.wantErr)
+            continue
+        }
+        if got != tt.want {
+            t.Errorf("Normalize(%q) = %q, want %q", tt.tag, got, tt.want)
+        }
+    }
+}
+
+func TestChain(t *testing.T) {
+    tests := []struct {
+        tags []string
+        want []string
+    }{
+        {tags: nil, want: nil},
+        {tags: []string{"fr-CA"}, want: []string{"fr-CA", "fr"}},
+        {tags: []string{"fr-ca", "en", "fr"}, want: []string{"fr-CA", "fr", "en"}},
+        {tags: []string{"zh-Hant-TW"}, want: []string{"zh-Hant-TW", "zh-Hant", "zh"}},
+        {tags: []string{"en-x-private"}, want: []string{"en-x-private", "en"}},
+        {tags: []string{"*", "de"}, want: []string{"de"}},
+    }
+    for _, tt := range tests {
+        if got := Chain(tt.tags); !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("Chain(%q) = %q, want %q", tt.tags, got, tt.want)
+        }
+    }
+}
+
+func TestLocalize(t *testing.T) {
+    product := func() *models.Product {
+        return &models.Product{
+            Title:       "Rain jacket",
+            Description: "Keeps you dry",
+            Translations: models.Translations{
+                "fr":    {Title: "Veste de pluie", Description: "Vous garde au sec"},
+                "fr-CA": {Title: "Manteau de pluie"},
+                "de":    {Description: "Hält trocken"},
+            },
+        }
+    }
+    tests := []struct {
+        name            string
+        chain           []string
+        wantTitle       string
+        wantDescription string
+        wantLocale      string
+    }{
+        {"no chain keeps the default", nil, "Rain jacket", "Keeps you dry", ""},
+        {"exact locale", []string{"fr"}, "Veste de pluie", "Vous garde au sec", "fr"},
+        {"fields fall back separately", []string{"fr-CA", "fr"}, "Manteau de pluie", "Vous garde au sec", "fr-CA"},
+        {"untranslated title falls back to the default", []string{"de"}, "Rain jacket", "Hält trocken", ""},
+        {"unknown locale", []string{"ja"}, "Rain jacket", "Keeps you dry", ""},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            p := product()
+            Localize(tt.chain, p)
+            if p.Title != tt.wantTitle || p.Description != tt.wantDescription || p.Locale != tt.wantLocale {
+                t.Errorf("Localize() = %q, %q in %q, want %q, %q in %q",
+                    p.Title, p.Description, p.Locale, tt.wantTitle, tt.wantDescription, tt.wantLocale)
+            }
+            if len(p.Translations) != 3 {
+                t.Errorf("Localize() left %d translations, want 3", len(p.Translations))
+            }
+        })
+    }
+}
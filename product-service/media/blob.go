@@ -0,0 +1,119 @@
+package media
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "io/fs"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+// ErrBlobNotFound is returned when nothing is stored under a key
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps the files of renditions under keys such as
+// images/i-1/thumbnail.webp and says where shoppers fetch them
+// from. Writing a key again replaces its file.
+type BlobStore interface {
+    Put(ctx context.Context, key string, r io.Reader, contentType string) error
+    Open(ctx context.Context, key string) (io.ReadCloser, error)
+    // Delete removes the file of a key; deleting a missing key is not an error
+    Delete(ctx context.Context, key string) error
+    // URL is where shoppers fetch the file of a key from
+    URL(key string) string
+}
+
+// DiskStore is a BlobStore keeping files in a directory of the local disk,
+// which a web server is expected to serve at baseURL
+type DiskStore struct {
+    root    string
+    baseURL string
+}
+
+// NewDiskStore creates the directory files are kept in, if need be
+func NewDiskStore(root, baseURL string) (*DiskStore, error) {
+    if err := os.MkdirAll(root, 0o755); err != nil {
+        return nil, fmt.Errorf("failed to create media directory: %w", err)
+    }
+    return &DiskStore{root: root, baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+// Root is the directory files are kept in
+func (s *DiskStore) Root() string {
+    return s.root
+}
+
+// path is the file of a key, which must be a relative slash-separated path
+// that stays inside the store
+func (s *DiskStore) path(key string) (string, error) {
+    if !fs.ValidPath(key) || key == "." {
+        return "", fmt.Errorf("invalid blob key %q", key)
+    }
+    return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes the file to a temporary file next to it first, so a reader
+// never sees it half-written. The web server serving the directory picks the
+// content type from the file extension.
+func (s *DiskStore) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+    name, err := s.path(key)
+    if err != nil {
+        return err
+    }
+    if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+        return err
+    }
+    tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+
+    if _, err := io.Copy(tmp, r); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Chmod(0o644); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+    return os.Rename(tmp.Name(), name)
+}
+
+func (s *DiskStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+    name, err := s.path(key)
+    if err != nil {
+        return nil, err
+    }
+    f, err := os.Open(name)
+    if errors.Is(err, fs.ErrNotExist) {
+        return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, key)
+    }
+    return f, err
+}
+
+func (s *DiskStore) Delete(ctx context.Context, key string) error {
+    name, err := s.path(key)
+    if err != nil {
+        return err
+    }
+    if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+        return err
+    }
+    return nil
+}
+
+func (s *DiskStore) URL(key string) string {
+    segments := strings.Split(key, "/")
+    for i, segment := range segments {
+        segments[i] = url.PathEscape(segment)
+    }
+    return s.baseURL + "/" + strings.Join(segments, "/")
+}
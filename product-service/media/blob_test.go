@@ -0,0 +1,66 @@
+package media
+
+import (
+    "context"
+    "errors"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+func TestDiskStore(t *testing.T) {
+    ctx := context.Background()
+    store, err := NewDiskStore(filepath.Join(t.TempDir(), "media"), "https://cdn.example.com/media/")
+    if err != nil {
+        t.Fatalf("NewDiskStore() error = %v", err)
+    }
+    key := "images/i-1/thumbnail.webp"
+
+    if err := store.Put(ctx, key, strings.NewReader("first"), "image/webp"); err != nil {
+        t.Fatalf("Put() error = %v", err)
+    }
+    if err := store.Put(ctx, key, strings.NewReader("second"), "image/webp"); err != nil {
+        t.Fatalf("Put() again error = %v", err)
+    }
+    f, err := store.Open(ctx, key)
+    if err != nil {
+        t.Fatalf("Open() error = %v", err)
+    }
+    data, _ := io.ReadAll(f)
+    f.Close()
+    if string(data) != "second" {
+        t.Errorf("Open() read %q, want the second write", data)
+    }
+    entries, _ := os.ReadDir(filepath.Join(store.Root(), "images/i-1"))
+    if len(entries) != 1 {
+        t.Errorf("store left %d files behind, want 1", len(entries))
+    }
+
+    if got, want := store.URL(key), "https://cdn.example.com/media/"+key; got != want {
+        t.Errorf("URL() = %q, want %q", got, want)
+    }
+
+    if err := store.Delete(ctx, key); err != nil {
+        t.Fatalf("Delete() error = %v", err)
+    }
+    if err := store.Delete(ctx, key); err != nil {
+        t.Errorf("Delete() of a missing key error = %v", err)
+    }
+    if _, err := store.Open(ctx, key); !errors.Is(err, ErrBlobNotFound) {
+        t.Errorf("Open() after Delete() error = %v, want ErrBlobNotFound", err)
+    }
+}
+
+func TestDiskStoreRejectsKeysOutsideIt(t *testing.T) {
+    store, err := NewDiskStore(t.TempDir(), "/media")
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, key := range []string{"", "../escape.jpg", "/etc/passwd", "a/../../b.jpg", "a//b.jpg"} {
+        if err := store.Put(context.Background(), key, strings.NewReader("x"), "image/jpeg"); err == nil {
+            t.Errorf("Put(%q) succeeded, want an error", key)
+        }
+    }
+}
@@ -0,0 +1,115 @@
+package media
+
+import (
+    "bytes"
+    "encoding/binary"
+    "image"
+)
+
+// exifOrientation is the EXIF tag saying how a photo must be turned to be upright
+const exifOrientation = 0x0112
+
+// orientation reads the EXIF orientation of a JPEG image, from 1 for an
+// image that is already upright to 8; other images are taken as upright
+func orientation(data []byte) int {
+    if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+        return 1
+    }
+    for i := 2; i+4 <= len(data); {
+        if data[i] != 0xFF {
+            return 1
+        }
+        marker := data[i+1]
+        if marker == 0xD8 || marker >= 0xD0 && marker <= 0xD7 || marker == 0xFF {
+            i++
+            continue
+        }
+        // the image data starts at the start of scan; no metadata follows it
+        if marker == 0xDA || marker == 0xD9 {
+            return 1
+        }
+        length := int(binary.BigEndian.Uint16(data[i+2:]))
+        if length < 2 || i+2+length > len(data) {
+            return 1
+        }
+        segment := data[i+4 : i+2+length]
+        if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+            return tiffOrientation(segment[6:])
+        }
+        i += 2 + length
+    }
+    return 1
+}
+
+// tiffOrientation reads the orientation tag of the first IFD of the TIFF
+// structure EXIF data is kept in
+func tiffOrientation(tiff []byte) int {
+    if len(tiff) < 8 {
+        return 1
+    }
+    var order binary.ByteOrder
+    switch string(tiff[:2]) {
+    case "II":
+        order = binary.LittleEndian
+    case "MM":
+        order = binary.BigEndian
+    default:
+        return 1
+    }
+    offset := int(order.Uint32(tiff[4:]))
+    if offset < 8 || offset+2 > len(tiff) {
+        return 1
+    }
+    entries := int(order.Uint16(tiff[offset:]))
+    for n := 0; n < entries; n++ {
+        entry := offset + 2 + 12*n
+        if entry+12 > len(tiff) {
+            return 1
+        }
+        if order.Uint16(tiff[entry:]) != exifOrientation {
+            continue
+        }
+        if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+            return o
+        }
+        return 1
+    }
+    return 1
+}
+
+// orient turns an image upright as its EXIF orientation o says: 2 to 4
+// mirror or rotate it by half a turn, 5 to 8 also swap its width and height
+func orient(src *image.RGBA, o int) *image.RGBA {
+    if o <= 1 || o > 8 {
+        return src
+    }
+    w, h := src.Bounds().Dx(), src.Bounds().Dy()
+    dw, dh := w, h
+    if o >= 5 {
+        dw, dh = h, w
+    }
+    dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+    for y := 0; y < dh; y++ {
+        for x := 0; x < dw; x++ {
+            var sx, sy int
+            switch o {
+            case 2: // mirrored
+                sx, sy = w-1-x, y
+            case 3: // upside down
+                sx, sy = w-1-x, h-1-y
+            case 4: // mirrored upside down
+                sx, sy = x, h-1-y
+            case 5: // mirrored and turned a quarter anticlockwise
+                sx, sy = y, x
+            case 6: // turned a quarter anticlockwise
+                sx, sy = y, h-1-x
+            case 7: // mirrored and turned a quarter clockwise
+                sx, sy = w-1-y, h-1-x
+            case 8: // turned a quarter clockwise
+                sx, sy = w-1-y, x
+            }
+            copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+        }
+    }
+    return dst
+}
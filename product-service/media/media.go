@@ -0,0 +1,219 @@
+// Package media turns the images uploaded for products into the renditions
+// shoppers are served: an upload is decoded, turned upright, scaled down to
+// each size and encoded again as JPEG and WebP. Only the pixels are encoded
+// again, so the EXIF data and other metadata of an upload, such as where a
+// photo was taken, never reach the blob store.
+package media
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "image"
+    "image/color"
+    "image/jpeg"
+    "sort"
+
+    // formats an upload can be in
+    _ "image/gif"
+    _ "image/png"
+
+    "github.com/HugoSmits86/nativewebp"
+    "golang.org/x/image/draw"
+    _ "golang.org/x/image/webp"
+)
+
+// MaxUploadSize caps the bytes of an uploaded image
+const MaxUploadSize = 20 << 20
+
+// maxPixels caps the pixels of an uploaded image, which a small file can
+// claim a great many of
+var maxPixels = 50_000_000
+
+// jpegQuality is the quality JPEG renditions are encoded at
+const jpegQuality = 85
+
+var (
+    // ErrUnsupportedImage is returned for an upload that is not a JPEG, PNG,
+    // GIF or WebP image
+    ErrUnsupportedImage = errors.New("unsupported image")
+    // ErrImageTooLarge is returned for an upload of more than MaxUploadSize
+    // bytes or of too many pixels
+    ErrImageTooLarge = errors.New("image is too large")
+)
+
+// Format is an encoding renditions are made in
+type Format string
+
+const (
+    JPEG Format = "jpeg"
+    WebP Format = "webp"
+)
+
+// ContentType is the MIME type of images in the format
+func (f Format) ContentType() string {
+    return "image/" + string(f)
+}
+
+// Extension is the file extension of images in the format, without the dot
+func (f Format) Extension() string {
+    if f == JPEG {
+        return "jpg"
+    }
+    return string(f)
+}
+
+// Size is a box renditions are scaled down to fit in, keeping their aspect
+// ratio; images that already fit are left at their size
+type Size struct {
+    Name      string
+    MaxWidth  int
+    MaxHeight int
+}
+
+// DefaultSizes are the sizes renditions are made in, from thumbnails for
+// listings to the large image of a product page
+var DefaultSizes = []Size{
+    {Name: "thumbnail", MaxWidth: 160, MaxHeight: 160},
+    {Name: "medium", MaxWidth: 640, MaxHeight: 640},
+    {Name: "large", MaxWidth: 1600, MaxHeight: 1600},
+}
+
+// DefaultFormats are the formats every size is encoded in: WebP for browsers
+// that show it and JPEG for the others. WebP renditions are lossless, which is
+// all a pure Go encoder offers, so for photos they can be the larger of the two.
+var DefaultFormats = []Format{WebP, JPEG}
+
+// Rendition is an image encoded in one size and format
+type Rendition struct {
+    Size   string
+    Format Format
+    Width  int
+    Height int
+    Data   []byte
+}
+
+// Result is what Process made of an upload
+type Result struct {
+    // Width and Height are those of the upload once turned upright
+    Width      int
+    Height     int
+    Renditions []Rendition
+}
+
+// Process decodes an uploaded image, turns it upright as its EXIF orientation
+// says and encodes it in every size and format. The renditions come in the
+// order of sizes, each in the order of formats.
+func Process(data []byte, sizes []Size, formats []Format) (*Result, error) {
+    if len(data) > MaxUploadSize {
+        return nil, fmt.Errorf("%w: more than %d bytes", ErrImageTooLarge, MaxUploadSize)
+    }
+    config, _, err := image.DecodeConfig(bytes.NewReader(data))
+    if err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+    }
+    if config.Width <= 0 || config.Height <= 0 {
+        return nil, fmt.Errorf("%w: image has no pixels", ErrUnsupportedImage)
+    }
+    if config.Width*config.Height > maxPixels {
+        return nil, fmt.Errorf("%w: %dx%d pixels", ErrImageTooLarge, config.Width, config.Height)
+    }
+    decoded, _, err := image.Decode(bytes.NewReader(data))
+    if err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+    }
+    src := orient(toRGBA(decoded), orientation(data))
+    bounds := src.Bounds()
+    result := &Result{Width: bounds.Dx(), Height: bounds.Dy()}
+
+    // each size is scaled from the smallest larger one already made, which is
+    // much cheaper than scaling every size from the upload
+    order := make([]int, len(sizes))
+    for i := range order {
+        order[i] = i
+    }
+    sort.SliceStable(order, func(a, b int) bool {
+        return area(sizes[order[a]]) > area(sizes[order[b]])
+    })
+    scaled := make([]*image.RGBA, len(sizes))
+    from := src
+    for _, i := range order {
+        width, height := fit(result.Width, result.Height, sizes[i])
+        if width != from.Bounds().Dx() || height != from.Bounds().Dy() {
+            dst := image.NewRGBA(image.Rect(0, 0, width, height))
+            draw.CatmullRom.Scale(dst, dst.Bounds(), from, from.Bounds(), draw.Src, nil)
+            from = dst
+        }
+        scaled[i] = from
+    }
+
+    for i, size := range sizes {
+        img := scaled[i]
+        for _, format := range formats {
+            encoded, err := encode(img, format)
+            if err != nil {
+                return nil, fmt.Errorf("failed to encode %s %s rendition: %w", size.Name, format, err)
+            }
+            result.Renditions = append(result.Renditions, Rendition{
+                Size:   size.Name,
+                Format: format,
+                Width:  img.Bounds().Dx(),
+                Height: img.Bounds().Dy(),
+                Data:   encoded,
+            })
+        }
+    }
+    return result, nil
+}
+
+func area(size Size) int {
+    return size.MaxWidth * size.MaxHeight
+}
+
+// fit is the size of a width by height image scaled down to fit in size
+func fit(width, height int, size Size) (int, int) {
+    scale := 1.0
+    if size.MaxWidth > 0 && width > size.MaxWidth {
+        scale = float64(size.MaxWidth) / float64(width)
+    }
+    if size.MaxHeight > 0 && float64(height)*scale > float64(size.MaxHeight) {
+        scale = float64(size.MaxHeight) / float64(height)
+    }
+    if scale == 1 {
+        return width, height
+    }
+    return max(1, int(float64(width)*scale+0.5)), max(1, int(float64(height)*scale+0.5))
+}
+
+// toRGBA copies an image into an RGBA image whose bounds start at zero
+func toRGBA(img image.Image) *image.RGBA {
+    bounds := img.Bounds()
+    rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+    draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+    return rgba
+}
+
+func encode(img *image.RGBA, format Format) ([]byte, error) {
+    var buf bytes.Buffer
+    switch format {
+    case JPEG:
+        // JPEG has no transparency, so transparent pixels are shown on white
+        // rather than the black they would otherwise turn into
+        if !img.Opaque() {
+            flat := image.NewRGBA(img.Bounds())
+            draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+            draw.Draw(flat, flat.Bounds(), img, image.Point{}, draw.Over)
+            img = flat
+        }
+        if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+            return nil, err
+        }
+    case WebP:
+        if err := nativewebp.Encode(&buf, img, nil); err != nil {
+            return nil, err
+        }
+    default:
+        return nil, fmt.Errorf("unknown image format %q", format)
+    }
+    return buf.Bytes(), nil
+}
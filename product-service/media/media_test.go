@@ -0,0 +1,154 @@
+package media
+
+import (
+    "bytes"
+    "errors"
+    "image"
+    "image/color"
+    "image/jpeg"
+    "image/png"
+    "testing"
+
+    "golang.org/x/image/webp"
+)
+
+// halves is a width by height image, red on its left half and blue on its right
+func halves(width, height int) *image.RGBA {
+    img := image.NewRGBA(image.Rect(0, 0, width, height))
+    for y := 0; y < height; y++ {
+        for x := 0; x < width; x++ {
+            c := color.RGBA{R: 255, A: 255}
+            if x >= width/2 {
+                c = color.RGBA{B: 255, A: 255}
+            }
+            img.SetRGBA(x, y, c)
+        }
+    }
+    return img
+}
+
+// withOrientation encodes img as a JPEG with an EXIF segment giving its orientation
+func withOrientation(t *testing.T, img image.Image, o byte) []byte {
+    t.Helper()
+    var buf bytes.Buffer
+    if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+        t.Fatal(err)
+    }
+    exif := []byte("Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08" +
+        "\x00\x01" + // one entry
+        "\x01\x12\x00\x03\x00\x00\x00\x01\x00" + string([]byte{o}) + "\x00\x00" +
+        "\x00\x00\x00\x00") // no next IFD
+    segment := append([]byte{0xFF, 0xE1, byte((len(exif) + 2) >> 8), byte(len(exif) + 2)}, exif...)
+    data := buf.Bytes()
+    return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+    t.Helper()
+    var buf bytes.Buffer
+    if err := png.Encode(&buf, img); err != nil {
+        t.Fatal(err)
+    }
+    return buf.Bytes()
+}
+
+func TestProcessSizes(t *testing.T) {
+    sizes := []Size{
+        {Name: "thumbnail", MaxWidth: 100, MaxHeight: 100},
+        {Name: "large", MaxWidth: 1000, MaxHeight: 1000},
+        {Name: "tall", MaxWidth: 400, MaxHeight: 20},
+    }
+    result, err := Process(encodePNG(t, halves(400, 200)), sizes, DefaultFormats)
+    if err != nil {
+        t.Fatalf("Process() error = %v", err)
+    }
+    if result.Width != 400 || result.Height != 200 {
+        t.Errorf("Process() size = %dx%d, want 400x200", result.Width, result.Height)
+    }
+
+    want := []struct {
+        size          string
+        format        Format
+        width, height int
+    }{
+        {"thumbnail", WebP, 100, 50},
+        {"thumbnail", JPEG, 100, 50},
+        // images are never scaled up
+        {"large", WebP, 400, 200},
+        {"large", JPEG, 400, 200},
+        {"tall", WebP, 40, 20},
+        {"tall", JPEG, 40, 20},
+    }
+    if len(result.Renditions) != len(want) {
+        t.Fatalf("Process() made %d renditions, want %d", len(result.Renditions), len(want))
+    }
+    for i, w := range want {
+        r := result.Renditions[i]
+        if r.Size != w.size || r.Format != w.format || r.Width != w.width || r.Height != w.height {
+            t.Errorf("rendition %d = %s %s %dx%d, want %s %s %dx%d",
+                i, r.Size, r.Format, r.Width, r.Height, w.size, w.format, w.width, w.height)
+            continue
+        }
+        var decoded image.Image
+        if r.Format == WebP {
+            decoded, err = webp.Decode(bytes.NewReader(r.Data))
+        } else {
+            decoded, err = jpeg.Decode(bytes.NewReader(r.Data))
+        }
+        if err != nil {
+            t.Errorf("rendition %d does not decode: %v", i, err)
+            continue
+        }
+        if b := decoded.Bounds(); b.Dx() != w.width || b.Dy() != w.height {
+            t.Errorf("rendition %d decodes to %dx%d, want %dx%d", i, b.Dx(), b.Dy(), w.width, w.height)
+        }
+    }
+}
+
+func TestProcessOrientsAndStripsExif(t *testing.T) {
+    tests := []struct {
+        orientation   byte
+        width, height int
+        // where the red half of the upload ends up
+        redX, redY int
+    }{
+        {orientation: 1, width: 40, height: 20, redX: 5, redY: 10},
+        {orientation: 3, width: 40, height: 20, redX: 35, redY: 10},
+        {orientation: 6, width: 20, height: 40, redX: 10, redY: 5},
+        {orientation: 8, width: 20, height: 40, redX: 10, redY: 35},
+    }
+    for _, tt := range tests {
+        data := withOrientation(t, halves(40, 20), tt.orientation)
+        result, err := Process(data, []Size{{Name: "original"}}, []Format{JPEG})
+        if err != nil {
+            t.Fatalf("Process() error = %v", err)
+        }
+        if result.Width != tt.width || result.Height != tt.height {
+            t.Errorf("orientation %d: size = %dx%d, want %dx%d", tt.orientation, result.Width, result.Height, tt.width, tt.height)
+            continue
+        }
+        out := result.Renditions[0].Data
+        if bytes.Contains(out, []byte("Exif")) {
+            t.Errorf("orientation %d: rendition kept the EXIF data", tt.orientation)
+        }
+        decoded, err := jpeg.Decode(bytes.NewReader(out))
+        if err != nil {
+            t.Fatalf("rendition does not decode: %v", err)
+        }
+        if r, _, b, _ := decoded.At(tt.redX, tt.redY).RGBA(); r < b {
+            t.Errorf("orientation %d: pixel (%d, %d) is not red", tt.orientation, tt.redX, tt.redY)
+        }
+    }
+}
+
+func TestProcessRejects(t *testing.T) {
+    defer func(n int) { maxPixels = n }(maxPixels)
+    maxPixels = 100
+
+    if _, err := Process([]byte("not an image"), DefaultSizes, DefaultFormats); !errors.Is(err, ErrUnsupportedImage) {
+        t.Errorf("Process(text) error = %v, want ErrUnsupportedImage", err)
+    }
+    if _, err := Process(encodePNG(t, halves(20, 20)), DefaultSizes, DefaultFormats); !errors.Is(err, ErrImageTooLarge) {
+        t.Errorf("Process(400 pixels) error = %v, want ErrImageTooLarge", err)
+    }
+}
@@ -1,23 +1,458 @@
 package models
 
-import "time"
+import (
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/money"
+)
 
 // Product represents the schema for a product
 type Product struct {
-    ID          string            `json:"_id" bson:"_id"`                     // Unique identifier
-    Title       string            `json:"title" bson:"title"`                 // Product title
-    Description string            `json:"description" bson:"description"`     // Product description
-    Price       float64           `json:"price" bson:"price"`                 // Product price
-    Stock       int               `json:"stock" bson:"stock"`                 // Stock quantity
-    Category    string            `json:"category" bson:"category"`           // Product category
-    Attributes  ProductAttributes `json:"attributes" bson:"attributes"`       // Additional attributes
-    Images      []string          `json:"images" bson:"images"`               // List of image URLs
-    Ratings     float64           `json:"ratings" bson:"ratings"`             // Product ratings
-    CreatedAt   time.Time         `json:"created_at" bson:"created_at"`       // Creation timestamp
+    ID           string            `json:"_id" bson:"_id"`                                       // Unique identifier
+    SKU          string            `json:"sku,omitempty" bson:"sku,omitempty"`                   // Stock keeping unit, unique across products when set
+    Title        string            `json:"title" bson:"title"`                                   // Product title
+    Description  string            `json:"description" bson:"description"`                       // Product description
+    Translations Translations      `json:"translations,omitempty" bson:"translations,omitempty"` // Title and description in other locales
+    Locale       string            `json:"locale,omitempty" bson:"-"`                            // Locale of the title and description; set on reads, empty for the default content
+    Price        money.Money       `json:"price" bson:"price"`                                   // Base price, before any price list; its currency is that of all of the product's prices
+    SalePrice    *money.Money      `json:"sale_price,omitempty" bson:"-"`                        // Lowest price of the price lists in effect; set on reads, nil when not on sale
+    Conversion   *PriceConversion  `json:"conversion,omitempty" bson:"-"`                        // Set on reads whose prices were converted into another currency
+    Stock        int               `json:"stock" bson:"stock"`                                   // Stock quantity, summed over all warehouses
+    Category     string            `json:"category" bson:"category"`                             // Name of the product category
+    CategoryID   string            `json:"category_id" bson:"category_id"`                       // Category in the category tree; its name is copied to Category
+    Attributes   ProductAttributes `json:"attributes" bson:"attributes"`                         // Additional attributes
+    Images       []string          `json:"images" bson:"images"`                                 // List of image URLs
+    Ratings      float64           `json:"ratings" bson:"ratings"`                               // Average star rating of the approved reviews
+    ReviewCount  int               `json:"review_count" bson:"review_count"`                     // Number of approved reviews
+    Version      int64             `json:"version" bson:"version"`                               // Incremented by every edit; updates must name the version they were based on
+    CreatedAt    time.Time         `json:"created_at" bson:"created_at"`                         // Creation timestamp
+    DeletedAt    *time.Time        `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`     // When the product was archived; nil while it is active
+}
+
+// DefaultCurrency is the currency of prices that name none, such as those of
+// catalog rows without one; all prices recorded before currencies were
+// introduced are in it
+const DefaultCurrency = "USD"
+
+// Archived reports whether the product was deleted and is kept only for the
+// orders that reference it
+func (p *Product) Archived() bool {
+    return p.DeletedAt != nil
+}
+
+// Translation is the content of a product in one locale; an empty field falls
+// back to the next locale of the chain
+type Translation struct {
+    Title       string `json:"title,omitempty" bson:"title,omitempty"`
+    Description string `json:"description,omitempty" bson:"description,omitempty"`
+}
+
+// Translations maps BCP 47 language tags, such as fr-CA, to the content of a
+// product in that locale
+type Translations map[string]Translation
+
+// Category is a node of the category tree, such as "Shirts" under "Clothing > Men"
+type Category struct {
+    ID        string    `json:"_id" bson:"_id"`               // Unique identifier
+    ParentID  string    `json:"parent_id" bson:"parent_id"`   // Parent category; empty for a top-level category
+    Name      string    `json:"name" bson:"name"`             // Display name
+    Slug      string    `json:"slug" bson:"slug"`             // URL-safe name, unique among its siblings
+    Position  int       `json:"position" bson:"position"`     // Order among its siblings, ascending
+    CreatedAt time.Time `json:"created_at" bson:"created_at"` // Creation timestamp
+}
+
+// CategoryNode is a category together with its subcategories, in order
+type CategoryNode struct {
+    Category *Category
+    Children []*CategoryNode
 }
 
 // ProductAttributes represents additional attributes for a product
 type ProductAttributes struct {
     Color string   `json:"color" bson:"color"` // Product color
     Size  []string `json:"size" bson:"size"`   // Available sizes
-}
\ No newline at end of file
+}
+
+// ProductField is a field of a product that an update can set on its own;
+// the names are the field mask paths of the Product message
+type ProductField string
+
+const (
+    ProductSKU          ProductField = "sku"
+    ProductTitle        ProductField = "title"
+    ProductDescription  ProductField = "description"
+    ProductPrice        ProductField = "price"
+    ProductStock        ProductField = "stock"
+    ProductCategory     ProductField = "category"
+    ProductCategoryID   ProductField = "category_id"
+    ProductColor        ProductField = "attributes.color"
+    ProductSizes        ProductField = "attributes.size"
+    ProductImages       ProductField = "images"
+    ProductTranslations ProductField = "translations"
+)
+
+// ProductFields lists every field an update can set
+var ProductFields = []ProductField{
+    ProductSKU, ProductTitle, ProductDescription, ProductPrice, ProductStock,
+    ProductCategory, ProductCategoryID, ProductColor, ProductSizes, ProductImages,
+    ProductTranslations,
+}
+
+// ProductMask is the set of fields an update sets; the others are left as they are
+type ProductMask map[ProductField]bool
+
+// FullProductMask returns a mask that sets every field, replacing the whole product
+func FullProductMask() ProductMask {
+    return NewProductMask(ProductFields...)
+}
+
+// NewProductMask returns a mask of the given fields
+func NewProductMask(fields ...ProductField) ProductMask {
+    mask := make(ProductMask, len(fields))
+    for _, field := range fields {
+        mask[field] = true
+    }
+    return mask
+}
+
+// With returns a copy of the mask that also sets the given fields
+func (m ProductMask) With(fields ...ProductField) ProductMask {
+    mask := make(ProductMask, len(m)+len(fields))
+    for field := range m {
+        mask[field] = true
+    }
+    for _, field := range fields {
+        mask[field] = true
+    }
+    return mask
+}
+
+// Apply copies the masked fields of from onto product
+func (m ProductMask) Apply(product, from *Product) {
+    if m[ProductSKU] {
+        product.SKU = from.SKU
+    }
+    if m[ProductTitle] {
+        product.Title = from.Title
+    }
+    if m[ProductDescription] {
+        product.Description = from.Description
+    }
+    if m[ProductPrice] {
+        product.Price = from.Price
+    }
+    if m[ProductStock] {
+        product.Stock = from.Stock
+    }
+    if m[ProductCategory] {
+        product.Category = from.Category
+    }
+    if m[ProductCategoryID] {
+        product.CategoryID = from.CategoryID
+    }
+    if m[ProductColor] {
+        product.Attributes.Color = from.Attributes.Color
+    }
+    if m[ProductSizes] {
+        product.Attributes.Size = from.Attributes.Size
+    }
+    if m[ProductImages] {
+        product.Images = from.Images
+    }
+    if m[ProductTranslations] {
+        product.Translations = from.Translations
+    }
+}
+
+// Variant is a sellable version of a product, such as "red / M", with its own SKU and stock
+type Variant struct {
+    ID         string            `json:"_id" bson:"_id"`                // Unique identifier
+    ProductID  string            `json:"product_id" bson:"-"`           // Owning product; implied by the parent document in MongoDB
+    SKU        string            `json:"sku" bson:"sku"`                // Stock keeping unit, unique across the catalogue
+    Options    map[string]string `json:"options" bson:"options"`        // Option values, e.g. color: red, size: M
+    Price      *money.Money      `json:"price,omitempty" bson:"price"`  // Overrides the product price when set
+    SalePrice  *money.Money      `json:"sale_price,omitempty" bson:"-"` // Lowest price of the price lists in effect; set on reads, nil when not on sale
+    Conversion *PriceConversion  `json:"conversion,omitempty" bson:"-"` // Set on reads whose prices were converted into another currency
+    Stock      int               `json:"stock" bson:"stock"`            // Stock quantity
+    Images     []string          `json:"images" bson:"images"`          // Variant specific image URLs
+    CreatedAt  time.Time         `json:"created_at" bson:"created_at"`  // Creation timestamp
+}
+
+// EffectivePrice is the price a customer pays for the product: its sale price
+// while it is on sale, or else its base price
+func (p *Product) EffectivePrice() money.Money {
+    if p.SalePrice != nil {
+        return *p.SalePrice
+    }
+    return p.Price
+}
+
+// BasePrice is the variant's price override, or the product's base price when there is none
+func (v *Variant) BasePrice(product *Product) money.Money {
+    if v.Price != nil {
+        return *v.Price
+    }
+    return product.Price
+}
+
+// EffectivePrice is the price a customer pays for the variant: its sale price
+// while it is on sale, or else its base price
+func (v *Variant) EffectivePrice(product *Product) money.Money {
+    if v.SalePrice != nil {
+        return *v.SalePrice
+    }
+    return v.BasePrice(product)
+}
+
+// ReservationStatus is the lifecycle state of a stock reservation
+type ReservationStatus string
+
+const (
+    ReservationPending   ReservationStatus = "pending"   // Stock is held until the reservation expires
+    ReservationCommitted ReservationStatus = "committed" // Stock has been sold
+    ReservationReleased  ReservationStatus = "released"  // Stock was returned by the caller
+    ReservationExpired   ReservationStatus = "expired"   // Stock was returned after the TTL elapsed
+)
+
+// Reservation holds stock for a checkout. Stock is taken from the product or
+// variant when the reservation is made and given back if it is released or expires.
+type Reservation struct {
+    ID          string            `json:"_id" bson:"_id"`                         // Unique identifier
+    ReferenceID string            `json:"reference_id" bson:"reference_id"`       // Caller's idempotency key, such as a cart or order ID
+    Status      ReservationStatus `json:"status" bson:"status"`                   // Lifecycle state
+    Items       []ReservationItem `json:"items" bson:"items"`                     // Reserved quantities
+    ExpiresAt   time.Time         `json:"expires_at" bson:"expires_at"`           // When a pending reservation lapses
+    CreatedAt   time.Time         `json:"created_at" bson:"created_at"`           // Creation timestamp
+}
+
+// ReservationItem is the quantity held for one product, or one of its variants
+type ReservationItem struct {
+    ProductID   string `json:"product_id" bson:"product_id"`                     // Reserved product
+    VariantID   string `json:"variant_id,omitempty" bson:"variant_id,omitempty"` // Reserved variant; empty to reserve the product's own stock
+    WarehouseID string `json:"warehouse_id" bson:"warehouse_id"`                 // Warehouse the units are held in
+    Quantity    int    `json:"quantity" bson:"quantity"`                         // Units held
+}
+
+// StockCheck is the outcome of checking the stock of a new order. An order
+// whose stock is available has it reserved and committed in one go.
+type StockCheck struct {
+    OrderID        string    `json:"order_id" bson:"_id"`                    // Order in order-service
+    StockAvailable bool      `json:"stock_available" bson:"stock_available"` // Whether every item was in stock
+    ReservationID  string    `json:"reservation_id" bson:"reservation_id"`   // Reservation holding the stock; empty when unavailable
+    Reason         string    `json:"reason" bson:"reason"`                   // Why the stock was unavailable
+    CheckedAt      time.Time `json:"checked_at" bson:"checked_at"`           // When the order was checked
+}
+
+// StockReason says why a stock level changed
+type StockReason string
+
+const (
+    StockReceiving   StockReason = "receiving"   // Goods arrived from a supplier
+    StockSale        StockReason = "sale"        // Goods were sold outside of a reservation
+    StockReservation StockReason = "reservation" // Stock was held for a checkout, or given back when the hold ended
+    StockReturn      StockReason = "return"      // A customer sent goods back
+    StockAdjustment  StockReason = "adjustment"  // A count was corrected by hand
+)
+
+// SystemActor is recorded for stock changes the service makes on its own behalf
+const SystemActor = "system"
+
+// StockChange describes who changed a stock level, where and why
+type StockChange struct {
+    WarehouseID string // Empty for the default warehouse
+    Reason      StockReason
+    Actor       string
+    ReferenceID string // Such as an order or reservation ID
+}
+
+// StockMovement is one append-only entry of the stock ledger. The stock of a
+// product or variant always equals the sum of the deltas recorded for it.
+type StockMovement struct {
+    ID          string      `json:"_id" bson:"_id"`                         // Unique identifier, increasing over time
+    ProductID   string      `json:"product_id" bson:"product_id"`           // Product whose stock changed
+    VariantID   string      `json:"variant_id,omitempty" bson:"variant_id"` // Variant whose stock changed; empty for the product's own stock
+    WarehouseID string      `json:"warehouse_id" bson:"warehouse_id"`       // Warehouse whose stock changed
+    Delta       int         `json:"delta" bson:"delta"`                     // Signed change in units
+    Reason      StockReason `json:"reason" bson:"reason"`                   // Why the stock changed
+    Actor       string      `json:"actor" bson:"actor"`                     // Who changed it
+    ReferenceID string      `json:"reference_id" bson:"reference_id"`       // Related order, reservation or delivery
+    CreatedAt   time.Time   `json:"created_at" bson:"created_at"`           // When the change was made
+}
+
+// DefaultWarehouseID names the warehouse that stock goes to when none is given;
+// it holds all stock recorded before warehouses were introduced
+const DefaultWarehouseID = "default"
+
+// Warehouse is a location that stock is held and shipped from
+type Warehouse struct {
+    ID        string    `json:"_id" bson:"_id"`               // Unique identifier
+    Name      string    `json:"name" bson:"name"`             // Display name
+    Address   string    `json:"address" bson:"address"`       // Postal address
+    Latitude  float64   `json:"latitude" bson:"latitude"`     // Location, used to find the nearest warehouse
+    Longitude float64   `json:"longitude" bson:"longitude"`   // Location, used to find the nearest warehouse
+    Active    bool      `json:"active" bson:"active"`         // Inactive warehouses keep their stock but fulfil no new reservations
+    CreatedAt time.Time `json:"created_at" bson:"created_at"` // Creation timestamp
+}
+
+// StockLevel is the stock of a product, or of one of its variants, in one warehouse
+type StockLevel struct {
+    WarehouseID string `json:"warehouse_id" bson:"warehouse_id"` // Warehouse holding the units
+    ProductID   string `json:"product_id" bson:"product_id"`     // Product the units belong to
+    VariantID   string `json:"variant_id" bson:"variant_id"`     // Variant the units belong to; empty for the product's own stock
+    Quantity    int    `json:"quantity" bson:"quantity"`         // Units on hand
+}
+
+// Location is a point on the map, such as where an order ships to
+type Location struct {
+    Latitude  float64
+    Longitude float64
+}
+
+// StockDiscrepancy is a product or variant whose stock differs from its ledger
+type StockDiscrepancy struct {
+    ProductID string
+    VariantID string
+    Stock     int // Stored stock level
+    Ledger    int // Sum of recorded movements
+}
+
+// ReviewStatus is the moderation state of a review
+type ReviewStatus string
+
+const (
+    ReviewPending  ReviewStatus = "pending"
+    ReviewApproved ReviewStatus = "approved"
+    ReviewRejected ReviewStatus = "rejected"
+)
+
+// Review is a customer's star rating and write-up of a product they bought.
+// Only approved reviews count towards the rating of the product.
+type Review struct {
+    ID           string       `json:"_id" bson:"_id"`                     // Unique identifier
+    ProductID    string       `json:"product_id" bson:"product_id"`       // Reviewed product
+    UserID       string       `json:"user_id" bson:"user_id"`             // Author; a user reviews a product at most once
+    OrderID      string       `json:"order_id" bson:"order_id"`           // Delivered order the product was bought in
+    Rating       int          `json:"rating" bson:"rating"`               // Stars, from 1 to 5
+    Title        string       `json:"title" bson:"title"`                 // Headline
+    Body         string       `json:"body" bson:"body"`                   // Review text
+    Status       ReviewStatus `json:"status" bson:"status"`               // Moderation state
+    HelpfulVotes int          `json:"helpful_votes" bson:"helpful_votes"` // Number of users who found the review helpful
+    CreatedAt    time.Time    `json:"created_at" bson:"created_at"`       // Creation timestamp
+    UpdatedAt    time.Time    `json:"updated_at" bson:"updated_at"`       // Last edit or moderation
+}
+
+// PriceList schedules prices for a period, such as a weekend sale. While a
+// list is in effect, the products and variants it names sell at the lowest of
+// their base price and the prices its entries give them.
+type PriceList struct {
+    ID        string           `json:"_id" bson:"_id"`               // Unique identifier
+    Name      string           `json:"name" bson:"name"`             // Display name, such as "Weekend sale"
+    StartsAt  time.Time        `json:"starts_at" bson:"starts_at"`   // When the prices take effect
+    EndsAt    *time.Time       `json:"ends_at" bson:"ends_at"`       // When the prices lapse; nil for a list without an end
+    Entries   []PriceListEntry `json:"entries" bson:"entries"`       // Scheduled prices
+    CreatedAt time.Time        `json:"created_at" bson:"created_at"` // Creation timestamp
+}
+
+// InEffect reports whether the list's prices apply at the given time
+func (l *PriceList) InEffect(at time.Time) bool {
+    return !at.Before(l.StartsAt) && (l.EndsAt == nil || at.Before(*l.EndsAt))
+}
+
+// PriceListEntry is the scheduled price of one product, or of one of its
+// variants. It gives either a fixed price or a percentage off the base price.
+type PriceListEntry struct {
+    PriceListID string       `json:"price_list_id" bson:"-"`                           // Owning price list; implied by the parent document in MongoDB
+    ProductID   string       `json:"product_id" bson:"product_id"`                     // Product the price applies to
+    VariantID   string       `json:"variant_id,omitempty" bson:"variant_id,omitempty"` // Variant the price applies to; empty for the product and all of its variants
+    Price       *money.Money `json:"price,omitempty" bson:"price,omitempty"`           // Fixed sale price, in the product's currency
+    PercentOff  float64      `json:"percent_off" bson:"percent_off"`                   // Percentage taken off the base price when Price is unset
+}
+
+// PriceChange is one entry of the price history of a product: a new base price
+// of the product or one of its variants, or a price scheduled by a price list
+type PriceChange struct {
+    ID          string       `json:"_id" bson:"_id"`                               // Unique identifier, increasing over time
+    ProductID   string       `json:"product_id" bson:"product_id"`                 // Product whose price changed
+    VariantID   string       `json:"variant_id,omitempty" bson:"variant_id"`       // Variant whose price changed; empty for the product's own price
+    PriceListID string       `json:"price_list_id,omitempty" bson:"price_list_id"` // Price list that scheduled the price; empty for a new base price
+    Price       *money.Money `json:"price,omitempty" bson:"price,omitempty"`       // New price; nil for a percentage off, or a variant that went back to the product price
+    PercentOff  float64      `json:"percent_off" bson:"percent_off"`               // Percentage off the base price, for a scheduled price without a fixed price
+    StartsAt    time.Time    `json:"starts_at" bson:"starts_at"`                   // When the price takes effect
+    EndsAt      *time.Time   `json:"ends_at,omitempty" bson:"ends_at"`             // When a scheduled price lapses; nil if it does not
+    CreatedAt   time.Time    `json:"created_at" bson:"created_at"`                 // When the change was made
+}
+
+// ExchangeRate is what one unit of DefaultCurrency buys of another currency
+// prices can be shown and charged in, and how prices converted into that
+// currency are rounded
+type ExchangeRate struct {
+    Currency  string         `json:"_id" bson:"_id"`               // ISO 4217 code
+    Rate      string         `json:"rate" bson:"rate"`             // Units of Currency per unit of DefaultCurrency, as a decimal
+    Rounding  money.Rounding `json:"rounding" bson:"rounding"`     // How converted prices are rounded
+    UpdatedAt time.Time      `json:"updated_at" bson:"updated_at"` // Last change of the rate
+}
+
+// PriceConversion is the exchange rate the prices of a product or variant
+// were converted at
+type PriceConversion struct {
+    From string `json:"from"` // Currency the prices are kept in
+    Rate string `json:"rate"` // Units of the currency shown per unit of From, as a decimal
+}
+
+// ProductImage is an image uploaded for a product. It is kept as renditions
+// of several sizes and formats; the upload itself, with its EXIF data, is not.
+type ProductImage struct {
+    ID         string           `json:"_id" bson:"_id"`               // Unique identifier
+    ProductID  string           `json:"product_id" bson:"product_id"` // Product shown in the image
+    AltText    string           `json:"alt_text" bson:"alt_text"`     // Describes the image to shoppers who cannot see it
+    Width      int              `json:"width" bson:"width"`           // Width of the upload once turned upright, in pixels
+    Height     int              `json:"height" bson:"height"`         // Height of the upload once turned upright, in pixels
+    Position   int              `json:"position" bson:"position"`     // Order among the images of the product, from 0
+    Renditions []ImageRendition `json:"renditions" bson:"renditions"` // Stored versions of the image
+    CreatedAt  time.Time        `json:"created_at" bson:"created_at"` // Upload timestamp
+}
+
+// ImageRendition is a product image encoded in one size and format
+type ImageRendition struct {
+    Size   string `json:"size" bson:"size"`     // Name of the size, such as "thumbnail"
+    Format string `json:"format" bson:"format"` // Encoding, "jpeg" or "webp"
+    Width  int    `json:"width" bson:"width"`   // Width in pixels
+    Height int    `json:"height" bson:"height"` // Height in pixels
+    Bytes  int64  `json:"bytes" bson:"bytes"`   // Length of the file
+    Key    string `json:"key" bson:"key"`       // Where the file is kept in the blob store
+    URL    string `json:"url" bson:"-"`         // Where shoppers fetch the file from; set on reads
+}
+
+// EventType names a product domain event
+type EventType string
+
+const (
+    EventProductCreated  EventType = "ProductCreated"
+    EventProductUpdated  EventType = "ProductUpdated"
+    EventProductArchived EventType = "ProductArchived"
+    EventProductRestored EventType = "ProductRestored"
+    EventProductDeleted  EventType = "ProductDeleted"
+    EventStockChanged    EventType = "StockChanged"
+)
+
+// Event is a change to the catalogue as published to other services. Events
+// are delivered at least once; consumers drop repeats by their ID.
+type Event struct {
+    ID         string         `json:"id"`                // Unique identifier
+    Type       EventType      `json:"type"`              // What happened
+    ProductID  string         `json:"product_id"`        // Product the event is about; events of one product keep their order
+    Product    *Product       `json:"product,omitempty"` // The product as written; set on ProductCreated and ProductUpdated
+    Stock      *StockMovement `json:"stock,omitempty"`   // The stock movement; set on StockChanged
+    OccurredAt time.Time      `json:"occurred_at"`       // When the change was made
+}
+
+// OutboxEvent is an event written to the outbox together with the change that
+// raised it, waiting to be published
+type OutboxEvent struct {
+    ID        string    `bson:"_id"`        // Unique identifier, increasing over time
+    ProductID string    `bson:"product_id"` // Key the event is published under
+    Payload   []byte    `bson:"payload"`    // The event as JSON
+    Attempts  int       `bson:"attempts"`   // Failed attempts to publish the event
+    LastError string    `bson:"last_error"` // Why the last attempt failed
+    CreatedAt time.Time `bson:"created_at"` // When the event was written
+}
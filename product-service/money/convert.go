@@ -0,0 +1,128 @@
+package money
+
+import (
+    "errors"
+    "fmt"
+    "math/big"
+    "strings"
+)
+
+// RateScale is the number of decimal places exchange rates are kept to
+const RateScale = 10
+
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// RoundingMode is the direction a converted amount is rounded in
+type RoundingMode string
+
+const (
+    // RoundNearest rounds to the nearer step, halves away from zero
+    RoundNearest RoundingMode = "nearest"
+    // RoundUp rounds towards positive infinity
+    RoundUp RoundingMode = "up"
+    // RoundDown rounds towards negative infinity
+    RoundDown RoundingMode = "down"
+)
+
+// Rounding is how an amount converted into a currency is rounded to a price
+// in it: to a multiple of Increment minor units, in the direction of Mode.
+// An increment of 5 in CHF rounds to 0.05 CHF; one of 100 in EUR to whole euros.
+type Rounding struct {
+    Mode      RoundingMode `json:"mode" bson:"mode"`
+    Increment int64        `json:"increment" bson:"increment"`
+}
+
+// DefaultRounding rounds to the nearest minor unit
+var DefaultRounding = Rounding{Mode: RoundNearest, Increment: 1}
+
+// Validate checks the mode is known and the increment positive
+func (r Rounding) Validate() error {
+    switch r.Mode {
+    case RoundNearest, RoundUp, RoundDown:
+    default:
+        return fmt.Errorf("unknown rounding mode %q", r.Mode)
+    }
+    if r.Increment <= 0 {
+        return fmt.Errorf("rounding increment must be positive, not %d", r.Increment)
+    }
+    return nil
+}
+
+// ParseRate reads a positive decimal exchange rate, such as "0.9215", with at
+// most RateScale decimal places
+func ParseRate(decimal string) (*big.Rat, error) {
+    decimal = strings.TrimSpace(decimal)
+    if !decimalPattern.MatchString(decimal) || strings.HasPrefix(decimal, "-") {
+        return nil, fmt.Errorf("%w %q", ErrInvalidRate, decimal)
+    }
+    if i := strings.IndexByte(decimal, '.'); i >= 0 && len(decimal)-i-1 > RateScale {
+        return nil, fmt.Errorf("%w %q: more than %d decimal places", ErrInvalidRate, decimal, RateScale)
+    }
+    rate, _ := new(big.Rat).SetString(decimal)
+    if rate.Sign() == 0 {
+        return nil, fmt.Errorf("%w %q: must be positive", ErrInvalidRate, decimal)
+    }
+    return rate, nil
+}
+
+// FormatRate writes an exchange rate as a decimal of at most RateScale places
+func FormatRate(rate *big.Rat) string {
+    s := rate.FloatString(RateScale)
+    s = strings.TrimRight(s, "0")
+    return strings.TrimSuffix(s, ".")
+}
+
+// CrossRate is the rate from one currency to another given the rate of each
+// against the same reference currency, rounded to RateScale places so that
+// the rate used can be recorded exactly
+func CrossRate(from, to *big.Rat) *big.Rat {
+    r := new(big.Rat).Quo(to, from)
+    unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(RateScale), nil)
+    scaled := roundBig(r.Mul(r, new(big.Rat).SetInt(unit)))
+    return new(big.Rat).SetFrac(scaled, unit)
+}
+
+// Convert returns m in currency at rate, the amount of currency one major
+// unit of m's currency buys, rounded as rounding says
+func Convert(m Money, currency string, rate *big.Rat, rounding Rounding) (Money, error) {
+    if !ValidCurrency(currency) {
+        return Money{}, fmt.Errorf("%w %q", ErrInvalidCurrency, currency)
+    }
+    if err := rounding.Validate(); err != nil {
+        return Money{}, err
+    }
+    r := new(big.Rat).SetInt64(m.Amount)
+    r.Quo(r, scale(m.Currency)).Mul(r, rate).Mul(r, scale(currency))
+
+    // count whole increments, then scale back to minor units
+    r.Quo(r, new(big.Rat).SetInt64(rounding.Increment))
+    var steps *big.Int
+    switch rounding.Mode {
+    case RoundUp:
+        steps = ceil(r)
+    case RoundDown:
+        steps = floor(r)
+    default:
+        steps = roundBig(r)
+    }
+    amount := steps.Mul(steps, big.NewInt(rounding.Increment))
+    if !amount.IsInt64() {
+        return Money{}, fmt.Errorf("%w: %s in %s is out of range", ErrInvalidAmount, m, currency)
+    }
+    return New(amount.Int64(), currency), nil
+}
+
+// floor rounds r towards negative infinity; Div is Euclidean division and the
+// denominator of a Rat is positive
+func floor(r *big.Rat) *big.Int {
+    return new(big.Int).Div(r.Num(), r.Denom())
+}
+
+// ceil rounds r towards positive infinity
+func ceil(r *big.Rat) *big.Int {
+    q := floor(r)
+    if !r.IsInt() {
+        q.Add(q, big.NewInt(1))
+    }
+    return q
+}
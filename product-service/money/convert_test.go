@@ -0,0 +1,93 @@
+package money
+
+import (
+    "errors"
+    "math/big"
+    "testing"
+)
+
+func rate(t *testing.T, decimal string) *big.Rat {
+    t.Helper()
+    r, err := ParseRate(decimal)
+    if err != nil {
+        t.Fatalf("ParseRate(%q) error = %v", decimal, err)
+    }
+    return r
+}
+
+func TestParseRate(t *testing.T) {
+    tests := []struct {
+        decimal string
+        want    string
+        err     error
+    }{
+        {"0.92", "0.92", nil},
+        {"149.5", "149.5", nil},
+        {"1", "1", nil},
+        {"0.0000000001", "0.0000000001", nil},
+        {"0.00000000001", "", ErrInvalidRate},
+        {"0", "", ErrInvalidRate},
+        {"-1.1", "", ErrInvalidRate},
+        {"1e2", "", ErrInvalidRate},
+    }
+    for _, tt := range tests {
+        got, err := ParseRate(tt.decimal)
+        if !errors.Is(err, tt.err) {
+            t.Errorf("ParseRate(%q) error = %v, want %v", tt.decimal, err, tt.err)
+            continue
+        }
+        if err == nil && FormatRate(got) != tt.want {
+            t.Errorf("ParseRate(%q) = %s, want %s", tt.decimal, FormatRate(got), tt.want)
+        }
+    }
+}
+
+func TestCrossRate(t *testing.T) {
+    // EUR at 0.92 and GBP at 0.79 to the dollar: a euro buys 0.79/0.92 pounds
+    if got := FormatRate(CrossRate(rate(t, "0.92"), rate(t, "0.79"))); got != "0.8586956522" {
+        t.Errorf("CrossRate() = %s, want 0.8586956522", got)
+    }
+    if got := FormatRate(CrossRate(rate(t, "1"), rate(t, "149.5"))); got != "149.5" {
+        t.Errorf("CrossRate() = %s, want 149.5", got)
+    }
+}
+
+func TestConvert(t *testing.T) {
+    tests := []struct {
+        name     string
+        m        Money
+        currency string
+        rate     string
+        rounding Rounding
+        want     Money
+    }{
+        {"nearest cent", New(1999, "USD"), "EUR", "0.92", DefaultRounding, New(1839, "EUR")}, // 18.3908
+        {"below half", New(1000, "USD"), "EUR", "0.92125", DefaultRounding, New(921, "EUR")},
+        {"half up", New(2000, "USD"), "EUR", "0.92125", DefaultRounding, New(1843, "EUR")}, // 18.425
+        {"up", New(1999, "USD"), "EUR", "0.92", Rounding{RoundUp, 1}, New(1840, "EUR")},
+        {"down", New(1999, "USD"), "EUR", "0.92", Rounding{RoundDown, 1}, New(1839, "EUR")},
+        {"to five cents", New(1999, "USD"), "CHF", "0.88", Rounding{RoundNearest, 5}, New(1760, "CHF")}, // 17.5912
+        {"up to whole units", New(1999, "USD"), "EUR", "0.92", Rounding{RoundUp, 100}, New(1900, "EUR")},
+        {"into no minor unit", New(1999, "USD"), "JPY", "149.5", DefaultRounding, New(2989, "JPY")}, // 2988.505
+        {"from no minor unit", New(3000, "JPY"), "USD", "0.0066889632", DefaultRounding, New(2007, "USD")},
+        {"exact", New(1000, "USD"), "EUR", "1", DefaultRounding, New(1000, "EUR")},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, err := Convert(tt.m, tt.currency, rate(t, tt.rate), tt.rounding)
+            if err != nil {
+                t.Fatalf("Convert() error = %v", err)
+            }
+            if got != tt.want {
+                t.Errorf("Convert() = %v, want %v", got, tt.want)
+            }
+        })
+    }
+
+    if _, err := Convert(New(100, "USD"), "eur", rate(t, "1"), DefaultRounding); !errors.Is(err, ErrInvalidCurrency) {
+        t.Errorf("Convert() into an invalid currency error = %v, want ErrInvalidCurrency", err)
+    }
+    if _, err := Convert(New(100, "USD"), "EUR", rate(t, "1"), Rounding{RoundNearest, 0}); err == nil {
+        t.Error("Convert() with a zero increment succeeded, want an error")
+    }
+}
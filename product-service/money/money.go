@@ -0,0 +1,216 @@
+// Package money represents amounts of money exactly, as whole minor units of
+// a currency.
+//
+// Amounts that fall between two minor units, such as a percentage off a
+// price or a decimal with more places than its currency has, are rounded to
+// the nearest minor unit, halves away from zero: 0.125 USD is 0.13 USD and
+// -0.125 USD is -0.13 USD.
+package money
+
+import (
+    "errors"
+    "fmt"
+    "math/big"
+    "regexp"
+    "strings"
+)
+
+var (
+    ErrInvalidCurrency  = errors.New("invalid currency")
+    ErrInvalidAmount    = errors.New("invalid amount")
+    ErrCurrencyMismatch = errors.New("currencies differ")
+)
+
+// Money is an amount of a currency in its minor units, such as cents
+type Money struct {
+    Amount   int64  `json:"amount" bson:"amount"`     // Amount in minor units of the currency
+    Currency string `json:"currency" bson:"currency"` // ISO 4217 code
+}
+
+// exponents lists the currencies whose minor unit is not a hundredth
+var exponents = map[string]int{
+    "BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+    "PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+    "BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+    "CLF": 4, "UYW": 4,
+}
+
+var (
+    currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+    decimalPattern  = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+)
+
+// ValidCurrency reports whether currency is shaped like an ISO 4217 code
+func ValidCurrency(currency string) bool {
+    return currencyPattern.MatchString(currency)
+}
+
+// Exponent is the number of decimal places of the currency's minor unit
+func Exponent(currency string) int {
+    if e, ok := exponents[currency]; ok {
+        return e
+    }
+    return 2
+}
+
+// New returns amount minor units of currency
+func New(amount int64, currency string) Money {
+    return Money{Amount: amount, Currency: currency}
+}
+
+// Parse reads a decimal amount in major units, such as "12.34", rounding it
+// to the currency's minor unit
+func Parse(decimal, currency string) (Money, error) {
+    return ParseRounded(decimal, currency, RoundNearest)
+}
+
+// ParseRounded is Parse rounding in the direction of mode, such as up for
+// the lower bound of a price range so that no price below it is let in
+func ParseRounded(decimal, currency string, mode RoundingMode) (Money, error) {
+    if !ValidCurrency(currency) {
+        return Money{}, fmt.Errorf("%w %q", ErrInvalidCurrency, currency)
+    }
+    decimal = strings.TrimSpace(decimal)
+    if !decimalPattern.MatchString(decimal) {
+        return Money{}, fmt.Errorf("%w %q", ErrInvalidAmount, decimal)
+    }
+    r, _ := new(big.Rat).SetString(decimal)
+    r.Mul(r, scale(currency))
+    var amount *big.Int
+    switch mode {
+    case RoundUp:
+        amount = ceil(r)
+    case RoundDown:
+        amount = floor(r)
+    default:
+        amount = roundBig(r)
+    }
+    if !amount.IsInt64() {
+        return Money{}, fmt.Errorf("%w %q: out of range", ErrInvalidAmount, decimal)
+    }
+    return New(amount.Int64(), currency), nil
+}
+
+// Validate checks that the currency is valid and the amount not negative
+func (m Money) Validate() error {
+    if !ValidCurrency(m.Currency) {
+        return fmt.Errorf("%w %q", ErrInvalidCurrency, m.Currency)
+    }
+    if m.Amount < 0 {
+        return fmt.Errorf("%w: %s is negative", ErrInvalidAmount, m)
+    }
+    return nil
+}
+
+// Decimal formats the amount in major units with the currency's decimal
+// places, such as "12.30"
+func (m Money) Decimal() string {
+    exp := Exponent(m.Currency)
+    if exp == 0 {
+        return fmt.Sprintf("%d", m.Amount)
+    }
+    sign, amount := "", m.Amount
+    if amount < 0 {
+        sign = "-"
+    }
+    digits := new(big.Int).Abs(big.NewInt(amount)).String()
+    if len(digits) <= exp {
+        digits = strings.Repeat("0", exp-len(digits)+1) + digits
+    }
+    return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+func (m Money) String() string {
+    return m.Decimal() + " " + m.Currency
+}
+
+// Float64 is the amount in major units, for uses that need no exactness
+// such as ranking and bucketing; it is never used to compute amounts
+func (m Money) Float64() float64 {
+    f, _ := new(big.Rat).Quo(new(big.Rat).SetInt64(m.Amount), scale(m.Currency)).Float64()
+    return f
+}
+
+// Add returns m + o, which must be of the same currency
+func (m Money) Add(o Money) (Money, error) {
+    if m.Currency != o.Currency {
+        return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+    }
+    sum := m.Amount + o.Amount
+    if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+        return Money{}, fmt.Errorf("%w: %s + %s overflows", ErrInvalidAmount, m, o)
+    }
+    return New(sum, m.Currency), nil
+}
+
+// Mul returns m times n, such as the price of n items
+func (m Money) Mul(n int64) (Money, error) {
+    product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(n))
+    if !product.IsInt64() {
+        return Money{}, fmt.Errorf("%w: %s × %d overflows", ErrInvalidAmount, m, n)
+    }
+    return New(product.Int64(), m.Currency), nil
+}
+
+// PercentOff returns m less percent of it, rounded to the minor unit
+func (m Money) PercentOff(percent float64) (Money, error) {
+    rest := new(big.Rat).SetFloat64(100 - percent)
+    if rest == nil {
+        return Money{}, fmt.Errorf("%w: %v percent off %s", ErrInvalidAmount, percent, m)
+    }
+    r := new(big.Rat).SetInt64(m.Amount)
+    r.Mul(r, rest).Quo(r, big.NewRat(100, 1))
+    amount, ok := round(r)
+    if !ok {
+        return Money{}, fmt.Errorf("%w: %v percent off %s overflows", ErrInvalidAmount, percent, m)
+    }
+    return New(amount, m.Currency), nil
+}
+
+// Cmp compares the amounts of two sums of the same currency, returning -1, 0 or +1
+func (m Money) Cmp(o Money) int {
+    switch {
+    case m.Amount < o.Amount:
+        return -1
+    case m.Amount > o.Amount:
+        return 1
+    }
+    return 0
+}
+
+// Sum adds up amounts of currency, which is returned as zero when there are none
+func Sum(currency string, amounts ...Money) (Money, error) {
+    total := New(0, currency)
+    for _, m := range amounts {
+        var err error
+        if total, err = total.Add(m); err != nil {
+            return Money{}, err
+        }
+    }
+    return total, nil
+}
+
+// scale is the number of minor units in a major unit of currency
+func scale(currency string) *big.Rat {
+    return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(Exponent(currency))), nil))
+}
+
+// round rounds r to a whole number, halves away from zero, reporting whether
+// it fits an int64
+func round(r *big.Rat) (int64, bool) {
+    q := roundBig(r)
+    return q.Int64(), q.IsInt64()
+}
+
+// roundBig rounds r to a whole number, halves away from zero
+func roundBig(r *big.Rat) *big.Int {
+    num, den := new(big.Int).Set(r.Num()), r.Denom()
+    // |r| + 1/2, truncated, with the sign of r
+    twice := new(big.Int).Mul(new(big.Int).Abs(num), big.NewInt(2))
+    twice.Add(twice, den)
+    q := new(big.Int).Quo(twice, new(big.Int).Mul(den, big.NewInt(2)))
+    if num.Sign() < 0 {
+        q.Neg(q)
+    }
+    return q
+}
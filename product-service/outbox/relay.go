@@ -0,0 +1,132 @@
+// Package outbox relays the domain events written to the outbox to Kafka.
+package outbox
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/google/uuid"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+// Publisher sends a message to a topic and returns once it is delivered;
+// messages with the same key keep their order
+type Publisher interface {
+    Publish(topic, key string, value []byte) error
+}
+
+// defaultBatchSize is the number of events read from the outbox at a time
+const defaultBatchSize = 100
+
+// defaultLease is how long a relay holds the events it claims; it should
+// outlast publishing a batch
+const defaultLease = time.Minute
+
+// Relay publishes the events in the outbox in the order they were written and
+// removes them once delivered. An event that fails to publish stays at the
+// head of the outbox and is retried with exponential backoff, so every event
+// is delivered at least once; an event published just before a crash may be
+// delivered again.
+//
+// Every replica runs a relay. A relay claims the batch it publishes, so the
+// others publish none of it and wait for the events after it; a batch whose
+// relay stops is claimed by another once its lease runs out.
+type Relay struct {
+    repo       repository.OutboxRepository
+    publisher  Publisher
+    topic      string
+    id         string // claimant of the events the relay publishes
+    batchSize  int
+    lease      time.Duration
+    minBackoff time.Duration
+    maxBackoff time.Duration
+}
+
+// NewRelay creates a Relay that publishes events to topic, keyed by product ID
+func NewRelay(repo repository.OutboxRepository, publisher Publisher, topic string) *Relay {
+    return &Relay{
+        repo:       repo,
+        publisher:  publisher,
+        topic:      topic,
+        id:         uuid.NewString(),
+        batchSize:  defaultBatchSize,
+        lease:      defaultLease,
+        minBackoff: time.Second,
+        maxBackoff: time.Minute,
+    }
+}
+
+// RelayOnce publishes the pending events until the outbox is empty, or its
+// head is claimed by another relay, and returns how many were published. It
+// stops at the first event that fails to publish.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+    published := 0
+    for {
+        events, err := r.repo.ClaimEvents(ctx, r.id, time.Now().UTC(), r.lease, r.batchSize)
+        if err != nil {
+            return published, fmt.Errorf("failed to read outbox: %w", err)
+        }
+        if len(events) == 0 {
+            return published, nil
+        }
+
+        ids := make([]string, 0, len(events))
+        var publishErr error
+        for _, event := range events {
+            if publishErr = r.publisher.Publish(r.topic, event.ProductID, event.Payload); publishErr != nil {
+                if err := r.repo.RecordEventFailure(ctx, event.ID, publishErr.Error()); err != nil {
+                    log.Printf("Failed to record outbox failure of event %s: %v", event.ID, err)
+                }
+                publishErr = fmt.Errorf("failed to publish event %s: %w", event.ID, publishErr)
+                break
+            }
+            ids = append(ids, event.ID)
+        }
+
+        // delivered events that stay in the outbox are published again next time
+        if err := r.repo.DeleteEvents(ctx, ids); err != nil {
+            return published, fmt.Errorf("failed to remove published events: %w", err)
+        }
+        published += len(ids)
+        if publishErr != nil {
+            return published, publishErr
+        }
+        if len(events) < r.batchSize {
+            return published, nil
+        }
+    }
+}
+
+// Run relays events every interval until ctx is done. After a failure it
+// retries after a second, doubling the wait up to a minute while it keeps
+// failing.
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+    wait := interval
+    backoff := r.minBackoff
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-time.After(wait):
+        }
+
+        if _, err := r.RelayOnce(ctx); err != nil {
+            log.Printf("Outbox relay failed, retrying in %s: %v", backoff, err)
+            wait = backoff
+            backoff = nextBackoff(backoff, r.maxBackoff)
+            continue
+        }
+        wait = interval
+        backoff = r.minBackoff
+    }
+}
+
+// nextBackoff doubles a backoff up to max
+func nextBackoff(backoff, max time.Duration) time.Duration {
+    if backoff *= 2; backoff > max {
+        return max
+    }
+    return backoff
+}
@@ -0,0 +1,231 @@
+package outbox
+
+import (
+    "context"
+    "errors"
+    "reflect"
+    "strconv"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// memoryOutbox claims events as the repositories do: from the head of the
+// outbox, stopping at the first event another claimant holds
+type memoryOutbox struct {
+    mu       sync.Mutex
+    events   []*models.OutboxEvent
+    claims   map[string]claim
+    failures map[string]int
+}
+
+type claim struct {
+    claimant string
+    until    time.Time
+}
+
+func (m *memoryOutbox) ClaimEvents(ctx context.Context, claimant string, now time.Time, lease time.Duration, limit int) ([]*models.OutboxEvent, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    var claimed []*models.OutboxEvent
+    for _, event := range m.events {
+        if len(claimed) == limit {
+            break
+        }
+        if c, ok := m.claims[event.ID]; ok && c.claimant != claimant && c.until.After(now) {
+            break
+        }
+        m.claims[event.ID] = claim{claimant, now.Add(lease)}
+        claimed = append(claimed, event)
+    }
+    return claimed, nil
+}
+
+func (m *memoryOutbox) DeleteEvents(ctx context.Context, ids []string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    deleted := make(map[string]bool, len(ids))
+    for _, id := range ids {
+        deleted[id] = true
+        delete(m.claims, id)
+    }
+    var kept []*models.OutboxEvent
+    for _, event := range m.events {
+        if !deleted[event.ID] {
+            kept = append(kept, event)
+        }
+    }
+    m.events = kept
+    return nil
+}
+
+func (m *memoryOutbox) RecordEventFailure(ctx context.Context, id string, reason string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.failures[id]++
+    return nil
+}
+
+type message struct{ topic, key, value string }
+
+// flakyPublisher fails every message whose value is in down
+type flakyPublisher struct {
+    mu   sync.Mutex
+    sent []message
+    down map[string]bool
+}
+
+func (p *flakyPublisher) Publish(topic, key string, value []byte) error {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    if p.down[string(value)] {
+        return errors.New("broker unavailable")
+    }
+    p.sent = append(p.sent, message{topic, key, string(value)})
+    return nil
+}
+
+func outboxOf(ids ...string) *memoryOutbox {
+    m := &memoryOutbox{claims: make(map[string]claim), failures: make(map[string]int)}
+    for _, id := range ids {
+        m.events = append(m.events, &models.OutboxEvent{ID: id, ProductID: "p-" + id, Payload: []byte("event " + id)})
+    }
+    return m
+}
+
+func TestRelayOnceDrainsInBatches(t *testing.T) {
+    repo := outboxOf("1", "2", "3", "4", "5")
+    publisher := &flakyPublisher{}
+    relay := NewRelay(repo, publisher, "productEvent")
+    relay.batchSize = 2
+
+    published, err := relay.RelayOnce(context.Background())
+    if err != nil {
+        t.Fatalf("RelayOnce() error = %v", err)
+    }
+    if published != 5 || len(repo.events) != 0 {
+        t.Errorf("published %d, %d left in the outbox; want 5 and 0", published, len(repo.events))
+    }
+    if want := (message{"productEvent", "p-1", "event 1"}); publisher.sent[0] != want {
+        t.Errorf("first message = %+v, want %+v", publisher.sent[0], want)
+    }
+}
+
+func TestRelayOnceStopsAtFailure(t *testing.T) {
+    repo := outboxOf("1", "2", "3")
+    publisher := &flakyPublisher{down: map[string]bool{"event 2": true}}
+    relay := NewRelay(repo, publisher, "productEvent")
+
+    published, err := relay.RelayOnce(context.Background())
+    if err == nil {
+        t.Fatal("RelayOnce() succeeded, want the publish error")
+    }
+    if published != 1 {
+        t.Errorf("published %d, want 1", published)
+    }
+    var left []string
+    for _, event := range repo.events {
+        left = append(left, event.ID)
+    }
+    if !reflect.DeepEqual(left, []string{"2", "3"}) {
+        t.Errorf("left in the outbox %v, want [2 3] so the order is kept", left)
+    }
+    if repo.failures["2"] != 1 {
+        t.Errorf("recorded %d failures of event 2, want 1", repo.failures["2"])
+    }
+
+    // the failed event is retried first once the broker is back
+    publisher.down = nil
+    if _, err := relay.RelayOnce(context.Background()); err != nil {
+        t.Fatalf("RelayOnce() error = %v", err)
+    }
+    if got := publisher.sent[1].value; got != "event 2" {
+        t.Errorf("retried %q first, want event 2", got)
+    }
+}
+
+func TestConcurrentRelaysPublishEachEventOnce(t *testing.T) {
+    var ids []string
+    for i := 1; i <= 50; i++ {
+        ids = append(ids, strconv.Itoa(i))
+    }
+    repo := outboxOf(ids...)
+    publisher := &flakyPublisher{}
+
+    var wg sync.WaitGroup
+    errs := make(chan error, 2)
+    for i := 0; i < 2; i++ {
+        relay := NewRelay(repo, publisher, "productEvent")
+        relay.batchSize = 3
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            // a relay that finds the head claimed publishes nothing and tries again
+            for {
+                repo.mu.Lock()
+                left := len(repo.events)
+                repo.mu.Unlock()
+                if left == 0 {
+                    return
+                }
+                if _, err := relay.RelayOnce(context.Background()); err != nil {
+                    errs <- err
+                    return
+                }
+            }
+        }()
+    }
+    wg.Wait()
+    close(errs)
+    for err := range errs {
+        t.Fatalf("RelayOnce() error = %v", err)
+    }
+
+    var sent []string
+    for _, m := range publisher.sent {
+        sent = append(sent, strings.TrimPrefix(m.value, "event "))
+    }
+    if !reflect.DeepEqual(sent, ids) {
+        t.Errorf("published %v, want every event once and in order", sent)
+    }
+}
+
+func TestRelayReclaimsExpiredClaims(t *testing.T) {
+    repo := outboxOf("1", "2")
+    stalled := NewRelay(repo, &flakyPublisher{}, "productEvent")
+    if _, err := repo.ClaimEvents(context.Background(), stalled.id, time.Now().UTC(), stalled.lease, 2); err != nil {
+        t.Fatalf("ClaimEvents() error = %v", err)
+    }
+
+    publisher := &flakyPublisher{}
+    relay := NewRelay(repo, publisher, "productEvent")
+    if published, err := relay.RelayOnce(context.Background()); err != nil || published != 0 {
+        t.Fatalf("RelayOnce() = %d, %v while another relay holds the events, want 0", published, err)
+    }
+
+    // the stalled relay's lease runs out
+    for id := range repo.claims {
+        repo.claims[id] = claim{stalled.id, time.Now().Add(-time.Second)}
+    }
+    if published, err := relay.RelayOnce(context.Background()); err != nil || published != 2 {
+        t.Errorf("RelayOnce() = %d, %v after the lease ran out, want 2", published, err)
+    }
+}
+
+func TestNextBackoff(t *testing.T) {
+    tests := []struct {
+        backoff, want time.Duration
+    }{
+        {time.Second, 2 * time.Second},
+        {40 * time.Second, time.Minute},
+        {time.Minute, time.Minute},
+    }
+    for _, tt := range tests {
+        if got := nextBackoff(tt.backoff, time.Minute); got != tt.want {
+            t.Errorf("nextBackoff(%s) = %s, want %s", tt.backoff, got, tt.want)
+        }
+    }
+}
@@ -0,0 +1,154 @@
+package pricing
+
+import (
+    "errors"
+    "fmt"
+    "math/big"
+
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+)
+
+// ErrUnsupportedCurrency rejects a currency there is no exchange rate for
+var ErrUnsupportedCurrency = errors.New("currency not supported")
+
+// Rates converts prices between currencies through the exchange rates of each
+// against models.DefaultCurrency, whose own rate is 1
+type Rates struct {
+    rates    map[string]*big.Rat
+    rounding map[string]money.Rounding
+}
+
+// NewRates reads a table of exchange rates
+func NewRates(rates []*models.ExchangeRate) (*Rates, error) {
+    r := &Rates{
+        rates:    map[string]*big.Rat{models.DefaultCurrency: big.NewRat(1, 1)},
+        rounding: map[string]money.Rounding{models.DefaultCurrency: money.DefaultRounding},
+    }
+    for _, rate := range rates {
+        parsed, err := money.ParseRate(rate.Rate)
+        if err != nil {
+            return nil, fmt.Errorf("exchange rate of %s: %w", rate.Currency, err)
+        }
+        r.rates[rate.Currency] = parsed
+        r.rounding[rate.Currency] = rate.Rounding
+    }
+    return r, nil
+}
+
+// Supports reports whether prices can be converted into currency
+func (r *Rates) Supports(currency string) bool {
+    _, ok := r.rates[currency]
+    return ok
+}
+
+// Convert returns m in currency, rounded as that currency's rate says, and
+// the rate it was converted at; an amount already in currency is returned as
+// it is with a nil conversion
+func (r *Rates) Convert(m money.Money, currency string) (money.Money, *models.PriceConversion, error) {
+    if m.Currency == currency {
+        return m, nil, nil
+    }
+    from, ok := r.rates[m.Currency]
+    if !ok {
+        return money.Money{}, nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, m.Currency)
+    }
+    to, ok := r.rates[currency]
+    if !ok {
+        return money.Money{}, nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+    }
+    rate := money.CrossRate(from, to)
+    converted, err := money.Convert(m, currency, rate, r.rounding[currency])
+    if err != nil {
+        return money.Money{}, nil, err
+    }
+    return converted, &models.PriceConversion{From: m.Currency, Rate: money.FormatRate(rate)}, nil
+}
+
+// Float64 is m in currency as a float, unrounded, for ranking and bucketing
+// prices of different currencies together; it reports false when there is
+// no rate for either currency
+func (r *Rates) Float64(m money.Money, currency string) (float64, bool) {
+    if m.Currency == currency {
+        return m.Float64(), true
+    }
+    rate, ok := r.worth(m.Currency, currency)
+    return m.Float64() * rate, ok
+}
+
+// Worth gives the value in currency of one major unit of every currency
+// there is a rate for, unrounded, to filter and sort prices of different
+// currencies together
+func (r *Rates) Worth(currency string) map[string]float64 {
+    worth := make(map[string]float64, len(r.rates))
+    for from := range r.rates {
+        if rate, ok := r.worth(from, currency); ok {
+            worth[from] = rate
+        }
+    }
+    return worth
+}
+
+// worth is the value in to of one major unit of from
+func (r *Rates) worth(from, to string) (float64, bool) {
+    if from == to {
+        return 1, true
+    }
+    fromRate, ok := r.rates[from]
+    if !ok {
+        return 0, false
+    }
+    toRate, ok := r.rates[to]
+    if !ok {
+        return 0, false
+    }
+    rate, _ := money.CrossRate(fromRate, toRate).Float64()
+    return rate, true
+}
+
+// convertOptional converts a price that may be unset
+func (r *Rates) convertOptional(m *money.Money, currency string) (*money.Money, error) {
+    if m == nil {
+        return nil, nil
+    }
+    converted, _, err := r.Convert(*m, currency)
+    return &converted, err
+}
+
+// ConvertProducts converts the base and sale prices of products into currency
+func (r *Rates) ConvertProducts(currency string, products ...*models.Product) error {
+    for _, product := range products {
+        price, conversion, err := r.Convert(product.Price, currency)
+        if err != nil {
+            return err
+        }
+        salePrice, err := r.convertOptional(product.SalePrice, currency)
+        if err != nil {
+            return err
+        }
+        product.Price, product.SalePrice, product.Conversion = price, salePrice, conversion
+    }
+    return nil
+}
+
+// ConvertVariants converts the price overrides and sale prices of variants
+// into currency. A variant without an override takes the price of its
+// product, which ConvertProducts converts afterwards.
+func (r *Rates) ConvertVariants(currency string, product *models.Product, variants ...*models.Variant) error {
+    for _, variant := range variants {
+        _, conversion, err := r.Convert(variant.BasePrice(product), currency)
+        if err != nil {
+            return err
+        }
+        price, err := r.convertOptional(variant.Price, currency)
+        if err != nil {
+            return err
+        }
+        salePrice, err := r.convertOptional(variant.SalePrice, currency)
+        if err != nil {
+            return err
+        }
+        variant.Price, variant.SalePrice, variant.Conversion = price, salePrice, conversion
+    }
+    return nil
+}
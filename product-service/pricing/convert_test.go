@@ -0,0 +1,90 @@
+package pricing
+
+import (
+    "errors"
+    "testing"
+
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+)
+
+func rates(t *testing.T) *Rates {
+    t.Helper()
+    r, err := NewRates([]*models.ExchangeRate{
+        {Currency: "EUR", Rate: "0.92", Rounding: money.DefaultRounding},
+        {Currency: "GBP", Rate: "0.79", Rounding: money.Rounding{Mode: money.RoundUp, Increment: 100}},
+    })
+    if err != nil {
+        t.Fatalf("NewRates() error = %v", err)
+    }
+    return r
+}
+
+func TestConvertProducts(t *testing.T) {
+    r := rates(t)
+    product := &models.Product{ID: "shirt", Price: usd(1999), SalePrice: price(1499)}
+    if err := r.ConvertProducts("EUR", product); err != nil {
+        t.Fatalf("ConvertProducts() error = %v", err)
+    }
+    if product.Price != money.New(1839, "EUR") || *product.SalePrice != money.New(1379, "EUR") {
+        t.Errorf("ConvertProducts() prices = %v, %v, want 18.39 EUR and 13.79 EUR", product.Price, product.SalePrice)
+    }
+    if product.Conversion == nil || *product.Conversion != (models.PriceConversion{From: "USD", Rate: "0.92"}) {
+        t.Errorf("ConvertProducts() conversion = %+v, want from USD at 0.92", product.Conversion)
+    }
+
+    // GBP prices are rounded up to whole pounds
+    product = &models.Product{ID: "shirt", Price: usd(1999)}
+    if err := r.ConvertProducts("GBP", product); err != nil {
+        t.Fatalf("ConvertProducts() error = %v", err)
+    }
+    if product.Price != money.New(1600, "GBP") || product.SalePrice != nil {
+        t.Errorf("ConvertProducts() prices = %v, %v, want 16.00 GBP and no sale price", product.Price, product.SalePrice)
+    }
+
+    // a price in the currency asked for is left as it is
+    product = &models.Product{ID: "shirt", Price: usd(1999)}
+    if err := r.ConvertProducts("USD", product); err != nil || product.Price != usd(1999) || product.Conversion != nil {
+        t.Errorf("ConvertProducts() into USD = %v, %+v, %v, want the price unchanged", product.Price, product.Conversion, err)
+    }
+
+    if err := r.ConvertProducts("JPY", product); !errors.Is(err, ErrUnsupportedCurrency) {
+        t.Errorf("ConvertProducts() into JPY error = %v, want ErrUnsupportedCurrency", err)
+    }
+}
+
+func TestConvertBetweenRates(t *testing.T) {
+    r := rates(t)
+    // a euro buys 0.79/0.92 pounds, rounded up to whole pounds
+    got, conversion, err := r.Convert(money.New(10000, "EUR"), "GBP")
+    if err != nil {
+        t.Fatalf("Convert() error = %v", err)
+    }
+    if got != money.New(8600, "GBP") || conversion.Rate != "0.8586956522" {
+        t.Errorf("Convert() = %v at %s, want 86.00 GBP at 0.8586956522", got, conversion.Rate)
+    }
+}
+
+func TestConvertVariants(t *testing.T) {
+    r := rates(t)
+    product := &models.Product{ID: "shirt", Price: usd(2000)}
+    override := &models.Variant{ID: "xl", Price: price(2500), SalePrice: price(2000)}
+    inherits := &models.Variant{ID: "m"}
+    if err := r.ConvertVariants("EUR", product, override, inherits); err != nil {
+        t.Fatalf("ConvertVariants() error = %v", err)
+    }
+    if err := r.ConvertProducts("EUR", product); err != nil {
+        t.Fatalf("ConvertProducts() error = %v", err)
+    }
+    if *override.Price != money.New(2300, "EUR") || *override.SalePrice != money.New(1840, "EUR") {
+        t.Errorf("ConvertVariants() prices = %v, %v, want 23.00 EUR and 18.40 EUR", override.Price, override.SalePrice)
+    }
+    if inherits.Price != nil || inherits.BasePrice(product) != money.New(1840, "EUR") {
+        t.Errorf("ConvertVariants() left the variant at %v, want the product's 18.40 EUR", inherits.BasePrice(product))
+    }
+    for _, v := range []*models.Variant{override, inherits} {
+        if v.Conversion == nil || v.Conversion.From != "USD" {
+            t.Errorf("variant %s conversion = %+v, want from USD", v.ID, v.Conversion)
+        }
+    }
+}
@@ -0,0 +1,60 @@
+// Package pricing resolves the prices that price lists give products and variants.
+package pricing
+
+import (
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+)
+
+// SalePrice returns the lowest price that the lists in effect at the given time
+// give a product, or one of its variants when variantID is set, provided it is
+// below base; otherwise it returns nil. An entry without a variant applies to
+// the product and to every one of its variants; a fixed price in another
+// currency than base, or a percentage off that gives no price, is ignored.
+func SalePrice(lists []*models.PriceList, at time.Time, productID, variantID string, base money.Money) *money.Money {
+    var lowest *money.Money
+    for _, list := range lists {
+        if !list.InEffect(at) {
+            continue
+        }
+        for _, entry := range list.Entries {
+            if entry.ProductID != productID || (entry.VariantID != "" && entry.VariantID != variantID) {
+                continue
+            }
+            price, err := entryPrice(entry, base)
+            if err != nil || price.Currency != base.Currency {
+                continue
+            }
+            if price.Cmp(base) < 0 && (lowest == nil || price.Cmp(*lowest) < 0) {
+                lowest = &price
+            }
+        }
+    }
+    return lowest
+}
+
+// entryPrice is the fixed price of an entry, or its percentage off base
+// rounded to the minor unit
+func entryPrice(entry models.PriceListEntry, base money.Money) (money.Money, error) {
+    if entry.Price != nil {
+        return *entry.Price, nil
+    }
+    return base.PercentOff(entry.PercentOff)
+}
+
+// ApplyToProducts sets the sale price of every product from the lists in effect at the given time
+func ApplyToProducts(lists []*models.PriceList, at time.Time, products ...*models.Product) {
+    for _, product := range products {
+        product.SalePrice = SalePrice(lists, at, product.ID, "", product.Price)
+    }
+}
+
+// ApplyToVariants sets the sale price of the variants of a product from the
+// lists in effect at the given time; a variant is priced from its own base price
+func ApplyToVariants(lists []*models.PriceList, at time.Time, product *models.Product, variants ...*models.Variant) {
+    for _, variant := range variants {
+        variant.SalePrice = SalePrice(lists, at, product.ID, variant.ID, variant.BasePrice(product))
+    }
+}
@@ -0,0 +1,100 @@
+package pricing
+
+import (
+    "math"
+    "testing"
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+)
+
+var (
+    friday = time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC)
+    monday = friday.AddDate(0, 0, 3)
+)
+
+func usd(cents int64) money.Money {
+    return money.New(cents, "USD")
+}
+
+func price(cents int64) *money.Money {
+    p := usd(cents)
+    return &p
+}
+
+func list(id string, startsAt time.Time, endsAt *time.Time, entries ...models.PriceListEntry) *models.PriceList {
+    return &models.PriceList{ID: id, StartsAt: startsAt, EndsAt: endsAt, Entries: entries}
+}
+
+func percentOff(productID, variantID string, percent float64) models.PriceListEntry {
+    return models.PriceListEntry{ProductID: productID, VariantID: variantID, PercentOff: percent}
+}
+
+func fixed(productID, variantID string, p int64) models.PriceListEntry {
+    return models.PriceListEntry{ProductID: productID, VariantID: variantID, Price: price(p)}
+}
+
+func TestSalePrice(t *testing.T) {
+    weekend := list("weekend", friday, &monday, percentOff("shirt", "", 20))
+    tests := []struct {
+        name      string
+        lists     []*models.PriceList
+        at        time.Time
+        variantID string
+        base      money.Money
+        want      *money.Money
+    }{
+        {"percentage off in effect", []*models.PriceList{weekend}, friday.Add(time.Hour), "", usd(2499), price(1999)},
+        {"percentage off rounds half up", []*models.PriceList{list("half", friday, nil, percentOff("shirt", "", 50))}, friday, "", usd(1999), price(1000)},
+        {"before the start", []*models.PriceList{weekend}, friday.Add(-time.Second), "", usd(2499), nil},
+        {"at the end", []*models.PriceList{weekend}, monday, "", usd(2499), nil},
+        {"without an end", []*models.PriceList{list("clearance", friday, nil, fixed("shirt", "", 1000))}, monday.AddDate(1, 0, 0), "", usd(2499), price(1000)},
+        {"product entry applies to variants", []*models.PriceList{weekend}, friday, "red", usd(3000), price(2400)},
+        {"variant entry skips other variants", []*models.PriceList{list("red", friday, nil, fixed("shirt", "red", 500))}, friday, "blue", usd(3000), nil},
+        {"variant entry skips the product", []*models.PriceList{list("red", friday, nil, fixed("shirt", "red", 500))}, friday, "", usd(3000), nil},
+        {"other products are ignored", []*models.PriceList{list("other", friday, nil, fixed("hat", "", 500))}, friday, "", usd(3000), nil},
+        {"lowest price wins", []*models.PriceList{weekend, list("red", friday, nil, fixed("shirt", "red", 2000))}, friday, "red", usd(3000), price(2000)},
+        {"price above base is no sale", []*models.PriceList{list("up", friday, nil, fixed("shirt", "", 4000))}, friday, "", usd(3000), nil},
+        {"percentage off that is not a number is ignored", []*models.PriceList{list("nan", friday, nil, percentOff("shirt", "", math.NaN()))}, friday, "", usd(3000), nil},
+        {"price in another currency is ignored", []*models.PriceList{list("eur", friday, nil, models.PriceListEntry{ProductID: "shirt", Price: &money.Money{Amount: 500, Currency: "EUR"}})}, friday, "", usd(3000), nil},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := SalePrice(tt.lists, tt.at, "shirt", tt.variantID, tt.base)
+            switch {
+            case got == nil && tt.want == nil:
+            case got == nil || tt.want == nil || *got != *tt.want:
+                t.Errorf("SalePrice() = %v, want %v", describe(got), describe(tt.want))
+            }
+        })
+    }
+}
+
+func TestApplyToVariants(t *testing.T) {
+    product := &models.Product{ID: "shirt", Price: usd(3000)}
+    plain := &models.Variant{ID: "blue"}
+    override := &models.Variant{ID: "red", Price: price(5000)}
+    lists := []*models.PriceList{list("weekend", friday, &monday, percentOff("shirt", "", 10))}
+
+    ApplyToProducts(lists, friday, product)
+    ApplyToVariants(lists, friday, product, plain, override)
+
+    if got := product.EffectivePrice(); got != usd(2700) {
+        t.Errorf("product effective price = %v, want 27.00 USD", got)
+    }
+    if got := plain.EffectivePrice(product); got != usd(2700) {
+        t.Errorf("variant without override effective price = %v, want 27.00 USD", got)
+    }
+    if got := override.EffectivePrice(product); got != usd(4500) {
+        t.Errorf("variant with override effective price = %v, want 45.00 USD", got)
+    }
+}
+
+func describe(p *money.Money) interface{} {
+    if p == nil {
+        return nil
+    }
+    return *p
+}
@@ -0,0 +1,446 @@
+package query
+
+import (
+    "fmt"
+    "math"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/samObot19/shopverse/product-service/money"
+    "go.mongodb.org/mongo-driver/bson"
+)
+
+// Field is a product attribute that may appear in a filter
+type Field string
+
+const (
+    FieldCategory Field = "category"
+    FieldColor    Field = "color"
+    FieldSize     Field = "size"
+    FieldPrice    Field = "price"
+    FieldStock    Field = "stock"
+    FieldRatings  Field = "ratings"
+)
+
+// Operator is the comparison applied by a Condition
+type Operator string
+
+const (
+    OpEq    Operator = "eq"
+    OpIn    Operator = "in"
+    OpRange Operator = "range"
+    OpGte   Operator = "gte"
+    OpLte   Operator = "lte"
+    OpGt    Operator = "gt"
+)
+
+// Logic joins the members of a Filter group
+type Logic string
+
+const (
+    And Logic = "and"
+    Or  Logic = "or"
+)
+
+const (
+    maxDepth  = 4
+    maxValues = 100
+)
+
+// Condition compares a single field. Values is used by eq and in; Min and Max
+// bound range, gte, gt and lte comparisons.
+type Condition struct {
+    Field    Field
+    Operator Operator
+    Values   []string
+    Min      *float64
+    Max      *float64
+}
+
+// Filter is a group of conditions and nested groups joined by Logic, which
+// defaults to And
+type Filter struct {
+    Logic      Logic
+    Conditions []Condition
+    Groups     []*Filter
+}
+
+// fieldSpec describes how an allowlisted field is stored and which operators it accepts
+type fieldSpec struct {
+    numeric    bool
+    column     string // products column, for scalar fields
+    table      string // child table, for fields stored outside products
+    mongoKey   string
+    // currency and mongoCurrency are the column and key of the currency of a
+    // money field, which is compared by its worth as Prices gives it; its
+    // mongoKey holds whole minor units of that currency
+    currency      string
+    mongoCurrency string
+    ops           map[Operator]bool
+}
+
+var fields = map[Field]fieldSpec{
+    FieldCategory: {column: "category", mongoKey: "category", ops: ops(OpEq, OpIn)},
+    FieldColor:    {table: "product_attributes", column: "color", mongoKey: "attributes.color", ops: ops(OpEq, OpIn)},
+    FieldSize:     {table: "product_sizes", column: "size", mongoKey: "attributes.size", ops: ops(OpEq, OpIn)},
+    FieldPrice:    {numeric: true, column: "price", mongoKey: "price.amount", currency: "currency", mongoCurrency: "price.currency", ops: ops(OpEq, OpRange, OpGte, OpLte)},
+    FieldStock:    {numeric: true, column: "stock", mongoKey: "stock", ops: ops(OpEq, OpGt, OpGte, OpLte)},
+    FieldRatings:  {numeric: true, column: "ratings", mongoKey: "ratings", ops: ops(OpRange, OpGte, OpLte)},
+}
+
+func ops(list ...Operator) map[Operator]bool {
+    set := make(map[Operator]bool, len(list))
+    for _, op := range list {
+        set[op] = true
+    }
+    return set
+}
+
+// FromMap converts legacy key/value equality filters into a Filter. Keys must
+// name an allowlisted field; anything else is rejected.
+func FromMap(filters map[string]string) (*Filter, error) {
+    if len(filters) == 0 {
+        return nil, nil
+    }
+
+    keys := make([]string, 0, len(filters))
+    for key := range filters {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+
+    filter := &Filter{Logic: And}
+    for _, key := range keys {
+        field := Field(strings.ToLower(key))
+        if _, ok := fields[field]; !ok {
+            return nil, fmt.Errorf("unknown filter field %q", key)
+        }
+        filter.Conditions = append(filter.Conditions, Condition{
+            Field:    field,
+            Operator: OpEq,
+            Values:   []string{filters[key]},
+        })
+    }
+    return filter, filter.Validate()
+}
+
+// Validate checks every condition against the field allowlist
+func (f *Filter) Validate() error {
+    return f.validate(1)
+}
+
+func (f *Filter) validate(depth int) error {
+    if f == nil {
+        return nil
+    }
+    if depth > maxDepth {
+        return fmt.Errorf("filter groups may be nested at most %d levels deep", maxDepth)
+    }
+    if f.Logic != "" && f.Logic != And && f.Logic != Or {
+        return fmt.Errorf("unknown filter logic %q", f.Logic)
+    }
+    for _, c := range f.Conditions {
+        if err := c.validate(); err != nil {
+            return err
+        }
+    }
+    for _, g := range f.Groups {
+        if err := g.validate(depth + 1); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (c Condition) validate() error {
+    spec, ok := fields[c.Field]
+    if !ok {
+        return fmt.Errorf("unknown filter field %q", c.Field)
+    }
+    if !spec.ops[c.Operator] {
+        return fmt.Errorf("operator %q is not supported on %s", c.Operator, c.Field)
+    }
+
+    switch c.Operator {
+    case OpEq:
+        if len(c.Values) != 1 {
+            return fmt.Errorf("%s eq needs exactly one value", c.Field)
+        }
+    case OpIn:
+        if len(c.Values) == 0 || len(c.Values) > maxValues {
+            return fmt.Errorf("%s in needs between 1 and %d values", c.Field, maxValues)
+        }
+    case OpRange:
+        if c.Min == nil || c.Max == nil {
+            return fmt.Errorf("%s range needs both min and max", c.Field)
+        }
+        if *c.Min > *c.Max {
+            return fmt.Errorf("%s range min is greater than max", c.Field)
+        }
+    case OpGte, OpGt:
+        if c.Min == nil {
+            return fmt.Errorf("%s %s needs min", c.Field, c.Operator)
+        }
+    case OpLte:
+        if c.Max == nil {
+            return fmt.Errorf("%s lte needs max", c.Field)
+        }
+    }
+
+    if spec.numeric {
+        for _, v := range c.Values {
+            if n, err := strconv.ParseFloat(v, 64); err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+                return fmt.Errorf("%s value %q is not a number", c.Field, v)
+            }
+        }
+        for _, bound := range []*float64{c.Min, c.Max} {
+            if bound != nil && (math.IsInf(*bound, 0) || math.IsNaN(*bound)) {
+                return fmt.Errorf("%s bound %v is not a number", c.Field, *bound)
+            }
+        }
+    }
+    return nil
+}
+
+// SQL compiles the filter into a WHERE clause over the products table. Column
+// names come from the allowlist only; every value is bound as a parameter.
+// Prices are compared by their worth in prices, which is needed when the
+// filter has price conditions.
+func (f *Filter) SQL(prices *Prices) (string, []interface{}, error) {
+    if err := f.Validate(); err != nil {
+        return "", nil, err
+    }
+    if f.usesPrices() && prices == nil {
+        return "", nil, ErrNoPrices
+    }
+    if f == nil || (len(f.Conditions) == 0 && len(f.Groups) == 0) {
+        return "1=1", nil, nil
+    }
+    var args []interface{}
+    clause := f.sql(&args, prices)
+    return clause, args, nil
+}
+
+func (f *Filter) sql(args *[]interface{}, prices *Prices) string {
+    var parts []string
+    for _, c := range f.Conditions {
+        parts = append(parts, c.sql(args, prices))
+    }
+    for _, g := range f.Groups {
+        if len(g.Conditions) == 0 && len(g.Groups) == 0 {
+            continue
+        }
+        parts = append(parts, g.sql(args, prices))
+    }
+    if len(parts) == 0 {
+        return "1=1"
+    }
+    joiner := " AND "
+    if f.Logic == Or {
+        joiner = " OR "
+    }
+    return "(" + strings.Join(parts, joiner) + ")"
+}
+
+func (c Condition) sql(args *[]interface{}, prices *Prices) string {
+    spec := fields[c.Field]
+    if spec.currency != "" {
+        return c.sqlMoney(spec, args, prices)
+    }
+    column := "products." + spec.column
+    if spec.table != "" {
+        column = "t." + spec.column
+    }
+    predicate := c.predicate(spec, column, args)
+    if spec.table != "" {
+        return "EXISTS (SELECT 1 FROM " + spec.table + " t WHERE t.product_id = products.id AND " + predicate + ")"
+    }
+    return predicate
+}
+
+// sqlMoney compares a money field once for each currency prices can be
+// compared in, with the values of the condition converted into it
+func (c Condition) sqlMoney(spec fieldSpec, args *[]interface{}, prices *Prices) string {
+    var groups []string
+    for _, currency := range prices.currencies() {
+        *args = append(*args, currency)
+        predicate := c.in(prices.Worth[currency]).predicate(spec, "products."+spec.column, args)
+        groups = append(groups, "(products."+spec.currency+" = ? AND "+predicate+")")
+    }
+    if len(groups) == 0 {
+        return "1=0"
+    }
+    return "(" + strings.Join(groups, " OR ") + ")"
+}
+
+// predicate compares column with the values or bounds of the condition
+func (c Condition) predicate(spec fieldSpec, column string, args *[]interface{}) string {
+    var predicate string
+    switch c.Operator {
+    case OpEq:
+        predicate = column + " = ?"
+        *args = append(*args, c.value(spec, c.Values[0]))
+    case OpIn:
+        placeholders := make([]string, len(c.Values))
+        for i, v := range c.Values {
+            placeholders[i] = "?"
+            *args = append(*args, c.value(spec, v))
+        }
+        predicate = column + " IN (" + strings.Join(placeholders, ", ") + ")"
+    case OpRange:
+        predicate = column + " BETWEEN ? AND ?"
+        *args = append(*args, *c.Min, *c.Max)
+    case OpGte:
+        predicate = column + " >= ?"
+        *args = append(*args, *c.Min)
+    case OpGt:
+        predicate = column + " > ?"
+        *args = append(*args, *c.Min)
+    case OpLte:
+        predicate = column + " <= ?"
+        *args = append(*args, *c.Max)
+    }
+    return predicate
+}
+
+func (c Condition) value(spec fieldSpec, v string) interface{} {
+    if spec.numeric {
+        n, _ := strconv.ParseFloat(v, 64)
+        return n
+    }
+    return v
+}
+
+// Mongo compiles the filter into a MongoDB query document. Prices are
+// compared by their worth in prices, which is needed when the filter has
+// price conditions.
+func (f *Filter) Mongo(prices *Prices) (bson.M, error) {
+    if err := f.Validate(); err != nil {
+        return nil, err
+    }
+    if f.usesPrices() && prices == nil {
+        return nil, ErrNoPrices
+    }
+    if f == nil {
+        return bson.M{}, nil
+    }
+    return f.mongo(prices), nil
+}
+
+func (f *Filter) mongo(prices *Prices) bson.M {
+    var parts bson.A
+    for _, c := range f.Conditions {
+        parts = append(parts, c.mongo(prices))
+    }
+    for _, g := range f.Groups {
+        if len(g.Conditions) == 0 && len(g.Groups) == 0 {
+            continue
+        }
+        parts = append(parts, g.mongo(prices))
+    }
+    if len(parts) == 0 {
+        return bson.M{}
+    }
+    if f.Logic == Or {
+        return bson.M{"$or": parts}
+    }
+    return bson.M{"$and": parts}
+}
+
+func (c Condition) mongo(prices *Prices) bson.M {
+    spec := fields[c.Field]
+    if spec.mongoCurrency != "" {
+        return c.mongoMoney(spec, prices)
+    }
+    var match interface{}
+    switch c.Operator {
+    case OpEq:
+        match = c.value(spec, c.Values[0])
+    case OpIn:
+        values := make(bson.A, len(c.Values))
+        for i, v := range c.Values {
+            values[i] = c.value(spec, v)
+        }
+        match = bson.M{"$in": values}
+    case OpRange:
+        match = bson.M{"$gte": *c.Min, "$lte": *c.Max}
+    case OpGte:
+        match = bson.M{"$gte": *c.Min}
+    case OpGt:
+        match = bson.M{"$gt": *c.Min}
+    case OpLte:
+        match = bson.M{"$lte": *c.Max}
+    }
+    return bson.M{spec.mongoKey: match}
+}
+
+// mongoMoney compares a money field, whose amounts are whole minor units of
+// their own currency, once for each currency prices can be compared in, with
+// the values of the condition converted exactly into minor units of it
+func (c Condition) mongoMoney(spec fieldSpec, prices *Prices) bson.M {
+    groups := bson.A{}
+    for _, currency := range prices.currencies() {
+        match := c.in(prices.Worth[currency]).minorUnits(currency)
+        groups = append(groups, bson.M{spec.mongoCurrency: currency, spec.mongoKey: match})
+    }
+    if len(groups) == 0 {
+        return bson.M{spec.mongoCurrency: bson.M{"$in": bson.A{}}}
+    }
+    return bson.M{"$or": groups}
+}
+
+func stringValues(list []string) bson.A {
+    values := make(bson.A, len(list))
+    for i, v := range list {
+        values[i] = v
+    }
+    return values
+}
+
+// minorUnits is the match of the condition on amounts in minor units of
+// currency. Bounds are rounded inwards, so 19.995 USD or more is 2000 cents
+// or more; equality with a value between two minor units matches nothing.
+func (c Condition) minorUnits(currency string) interface{} {
+    units := func(v float64, mode money.RoundingMode) int64 {
+        m, err := money.ParseRounded(strconv.FormatFloat(v, 'f', -1, 64), currency, mode)
+        if err != nil {
+            // only amounts beyond int64 are refused; no amount lies past them
+            if v < 0 {
+                return math.MinInt64
+            }
+            return math.MaxInt64
+        }
+        return m.Amount
+    }
+    exact := func(v string) (int64, bool) {
+        n, _ := strconv.ParseFloat(v, 64)
+        up, down := units(n, money.RoundUp), units(n, money.RoundDown)
+        return up, up == down
+    }
+
+    switch c.Operator {
+    case OpEq:
+        if amount, ok := exact(c.Values[0]); ok {
+            return amount
+        }
+        return bson.M{"$in": bson.A{}}
+    case OpIn:
+        values := bson.A{}
+        for _, v := range c.Values {
+            if amount, ok := exact(v); ok {
+                values = append(values, amount)
+            }
+        }
+        return bson.M{"$in": values}
+    case OpRange:
+        return bson.M{"$gte": units(*c.Min, money.RoundUp), "$lte": units(*c.Max, money.RoundDown)}
+    case OpGte:
+        return bson.M{"$gte": units(*c.Min, money.RoundUp)}
+    case OpGt:
+        return bson.M{"$gt": units(*c.Min, money.RoundDown)}
+    case OpLte:
+        return bson.M{"$lte": units(*c.Max, money.RoundDown)}
+    }
+    return nil
+}
@@ -0,0 +1,259 @@
+package query
+
+import (
+    "errors"
+    "math"
+    "reflect"
+    "testing"
+
+    "go.mongodb.org/mongo-driver/bson"
+)
+
+func float(v float64) *float64 { return &v }
+
+// eurPrices shows listings in USD, with a euro worth two dollars
+var eurPrices = &Prices{Currency: "USD", Worth: map[string]float64{"USD": 1, "EUR": 2}}
+
+func TestFilterSQL(t *testing.T) {
+    tests := []struct {
+        name      string
+        filter    *Filter
+        prices    *Prices
+        wantWhere string
+        wantArgs  []interface{}
+        wantErr   bool
+    }{
+        {
+            name:      "No filter",
+            filter:    nil,
+            wantWhere: "1=1",
+        },
+        {
+            name: "Category in and price range",
+            filter: &Filter{Conditions: []Condition{
+                {Field: FieldCategory, Operator: OpIn, Values: []string{"shoes", "shirts"}},
+                {Field: FieldPrice, Operator: OpRange, Min: float(10), Max: float(50)},
+            }},
+            prices:    eurPrices,
+            wantWhere: "(products.category IN (?, ?) AND ((products.currency = ? AND products.price BETWEEN ? AND ?) OR (products.currency = ? AND products.price BETWEEN ? AND ?)))",
+            wantArgs:  []interface{}{"shoes", "shirts", "EUR", 5.0, 25.0, "USD", 10.0, 50.0},
+        },
+        {
+            name: "Price without the prices to compare it in",
+            filter: &Filter{Conditions: []Condition{
+                {Field: FieldPrice, Operator: OpLte, Max: float(50)},
+            }},
+            wantErr: true,
+        },
+        {
+            name: "In stock or on a nested color group",
+            filter: &Filter{Logic: Or, Conditions: []Condition{
+                {Field: FieldStock, Operator: OpGt, Min: float(0)},
+            }, Groups: []*Filter{{Conditions: []Condition{
+                {Field: FieldColor, Operator: OpEq, Values: []string{"red"}},
+                {Field: FieldSize, Operator: OpIn, Values: []string{"M"}},
+            }}}},
+            wantWhere: "(products.stock > ? OR (EXISTS (SELECT 1 FROM product_attributes t WHERE t.product_id = products.id AND t.color = ?) AND EXISTS (SELECT 1 FROM product_sizes t WHERE t.product_id = products.id AND t.size IN (?))))",
+            wantArgs:  []interface{}{0.0, "red", "M"},
+        },
+        {
+            name: "Unknown field",
+            filter: &Filter{Conditions: []Condition{
+                {Field: "id = id OR 1", Operator: OpEq, Values: []string{"x"}},
+            }},
+            wantErr: true,
+        },
+        {
+            name: "Operator not allowed on field",
+            filter: &Filter{Conditions: []Condition{
+                {Field: FieldCategory, Operator: OpGte, Min: float(1)},
+            }},
+            wantErr: true,
+        },
+        {
+            name: "Non numeric price",
+            filter: &Filter{Conditions: []Condition{
+                {Field: FieldPrice, Operator: OpEq, Values: []string{"cheap"}},
+            }},
+            wantErr: true,
+        },
+        {
+            name: "Price bound that is not a number",
+            filter: &Filter{Conditions: []Condition{
+                {Field: FieldPrice, Operator: OpLte, Max: float(math.NaN())},
+            }},
+            wantErr: true,
+        },
+        {
+            name: "Inverted range",
+            filter: &Filter{Conditions: []Condition{
+                {Field: FieldPrice, Operator: OpRange, Min: float(50), Max: float(10)},
+            }},
+            wantErr: true,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            where, args, err := tt.filter.SQL(tt.prices)
+            if (err != nil) != tt.wantErr {
+                t.Fatalf("SQL() error = %v, wantErr %v", err, tt.wantErr)
+            }
+            if tt.wantErr {
+                return
+            }
+            if where != tt.wantWhere {
+                t.Errorf("SQL() where = %q, want %q", where, tt.wantWhere)
+            }
+            if !reflect.DeepEqual(args, tt.wantArgs) {
+                t.Errorf("SQL() args = %v, want %v", args, tt.wantArgs)
+            }
+        })
+    }
+}
+
+func TestFilterMongo(t *testing.T) {
+    filter := &Filter{Logic: Or, Conditions: []Condition{
+        {Field: FieldSize, Operator: OpIn, Values: []string{"S", "M"}},
+        {Field: FieldStock, Operator: OpGt, Min: float(0)},
+    }}
+    want := bson.M{"$or": bson.A{
+        bson.M{"attributes.size": bson.M{"$in": bson.A{"S", "M"}}},
+        bson.M{"stock": bson.M{"$gt": 0.0}},
+    }}
+
+    got, err := filter.Mongo(nil)
+    if err != nil {
+        t.Fatalf("Mongo() error = %v", err)
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("Mongo() = %v, want %v", got, want)
+    }
+}
+
+// priceMatch is the match a Mongo price query applies to amounts of currency
+func priceMatch(t *testing.T, query bson.M, currency string) interface{} {
+    t.Helper()
+    groups, ok := query["$or"].(bson.A)
+    if !ok {
+        t.Fatalf("price query %v is not grouped by currency", query)
+    }
+    for _, g := range groups {
+        if group := g.(bson.M); group["price.currency"] == currency {
+            return group["price.amount"]
+        }
+    }
+    t.Fatalf("price query %v does not cover %s", query, currency)
+    return nil
+}
+
+func TestFilterMongoPrice(t *testing.T) {
+    tests := []struct {
+        name      string
+        condition Condition
+        prices    *Prices
+        currency  string
+        want      interface{}
+    }{
+        {
+            name:      "decimal a float64 cannot hold",
+            condition: Condition{Field: FieldPrice, Operator: OpLte, Max: float(19.99)},
+            currency:  "USD",
+            want:      bson.M{"$lte": int64(1999)},
+        },
+        {
+            name:      "range of a currency without minor units",
+            condition: Condition{Field: FieldPrice, Operator: OpRange, Min: float(19.99), Max: float(1500)},
+            currency:  "JPY",
+            want:      bson.M{"$gte": int64(20), "$lte": int64(1500)},
+        },
+        {
+            name:      "currency of three decimals",
+            condition: Condition{Field: FieldPrice, Operator: OpGte, Min: float(19.99)},
+            currency:  "KWD",
+            want:      bson.M{"$gte": int64(19990)},
+        },
+        {
+            name:      "bounds between two minor units are rounded inwards",
+            condition: Condition{Field: FieldPrice, Operator: OpRange, Min: float(19.995), Max: float(20.005)},
+            currency:  "EUR",
+            want:      bson.M{"$gte": int64(2000), "$lte": int64(2000)},
+        },
+        {
+            name:      "equal to a decimal",
+            condition: Condition{Field: FieldPrice, Operator: OpEq, Values: []string{"0.29"}},
+            currency:  "USD",
+            want:      int64(29),
+        },
+        {
+            name:      "equal to an amount between two minor units",
+            condition: Condition{Field: FieldPrice, Operator: OpEq, Values: []string{"0.29"}},
+            currency:  "JPY",
+            want:      bson.M{"$in": bson.A{}},
+        },
+        {
+            name:      "bound beyond any amount",
+            condition: Condition{Field: FieldPrice, Operator: OpLte, Max: float(1e30)},
+            currency:  "USD",
+            want:      bson.M{"$lte": int64(math.MaxInt64)},
+        },
+        {
+            name:      "bound converted into the currency of the price",
+            condition: Condition{Field: FieldPrice, Operator: OpLte, Max: float(19.99)},
+            prices:    eurPrices,
+            currency:  "EUR",
+            want:      bson.M{"$lte": int64(999)},
+        },
+        {
+            name:      "bound in the currency of the listing",
+            condition: Condition{Field: FieldPrice, Operator: OpGte, Min: float(20)},
+            prices:    eurPrices,
+            currency:  "USD",
+            want:      bson.M{"$gte": int64(2000)},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            prices := tt.prices
+            if prices == nil {
+                // the amounts of every currency are worth the same
+                prices = &Prices{Currency: tt.currency, Worth: map[string]float64{tt.currency: 1}}
+            }
+            filter := &Filter{Conditions: []Condition{tt.condition}}
+            got, err := filter.Mongo(prices)
+            if err != nil {
+                t.Fatalf("Mongo() error = %v", err)
+            }
+            match := priceMatch(t, got["$and"].(bson.A)[0].(bson.M), tt.currency)
+            if !reflect.DeepEqual(match, tt.want) {
+                t.Errorf("Mongo() matches %s amounts with %v, want %v", tt.currency, match, tt.want)
+            }
+        })
+    }
+
+    if _, err := (&Filter{Conditions: []Condition{tests[0].condition}}).Mongo(nil); !errors.Is(err, ErrNoPrices) {
+        t.Errorf("Mongo() without prices error = %v, want %v", err, ErrNoPrices)
+    }
+    // products of a currency without a worth match no price condition
+    got, _ := (&Filter{Conditions: []Condition{tests[0].condition}}).Mongo(eurPrices)
+    if groups := got["$and"].(bson.A)[0].(bson.M)["$or"].(bson.A); len(groups) != 2 {
+        t.Errorf("Mongo() compares prices of %d currencies, want only EUR and USD", len(groups))
+    }
+}
+
+func TestFromMap(t *testing.T) {
+    if _, err := FromMap(map[string]string{"category = 'x' OR 1": "1"}); err == nil {
+        t.Error("FromMap() accepted a key outside the allowlist")
+    }
+
+    filter, err := FromMap(map[string]string{"Category": "shoes", "color": "red"})
+    if err != nil {
+        t.Fatalf("FromMap() error = %v", err)
+    }
+    where, args, _ := filter.SQL(nil)
+    wantWhere := "(products.category = ? AND EXISTS (SELECT 1 FROM product_attributes t WHERE t.product_id = products.id AND t.color = ?))"
+    if where != wantWhere || !reflect.DeepEqual(args, []interface{}{"shoes", "red"}) {
+        t.Errorf("FromMap() compiled to %q %v", where, args)
+    }
+}
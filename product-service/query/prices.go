@@ -0,0 +1,112 @@
+package query
+
+import (
+    "errors"
+    "math"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/samObot19/shopverse/product-service/money"
+    "go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrNoPrices is returned for a filter or sort that compares prices without
+// the Prices to compare them in
+var ErrNoPrices = errors.New("prices of different currencies cannot be compared without exchange rates")
+
+// Prices says what prices of every currency are worth in the currency a
+// listing is shown in, so they are filtered and sorted by that worth rather
+// than by their own amounts. Products priced in a currency Worth has no entry
+// for match no price condition and sort after the others in either direction.
+type Prices struct {
+    Currency string
+    // Worth is the value in Currency of one major unit of each currency
+    Worth map[string]float64
+}
+
+// UsesPrices reports whether a filter or sort keys compare prices
+func UsesPrices(filter *Filter, sorts []Sort) bool {
+    for _, s := range sorts {
+        if s.Field == SortPrice {
+            return true
+        }
+    }
+    return filter.usesPrices()
+}
+
+func (f *Filter) usesPrices() bool {
+    if f == nil {
+        return false
+    }
+    for _, c := range f.Conditions {
+        if fields[c.Field].currency != "" {
+            return true
+        }
+    }
+    for _, g := range f.Groups {
+        if g.usesPrices() {
+            return true
+        }
+    }
+    return false
+}
+
+// currencies lists the currencies prices can be compared in, in order
+func (p *Prices) currencies() []string {
+    currencies := make([]string, 0, len(p.Worth))
+    for currency := range p.Worth {
+        currencies = append(currencies, currency)
+    }
+    sort.Strings(currencies)
+    return currencies
+}
+
+// sql is the worth of the price of a product, or NULL when it has none
+func (p *Prices) sql(args *[]interface{}) string {
+    var b strings.Builder
+    b.WriteString("CASE products.currency")
+    for _, currency := range p.currencies() {
+        b.WriteString(" WHEN ? THEN products.price * ?")
+        *args = append(*args, currency, p.Worth[currency])
+    }
+    b.WriteString(" ELSE NULL END")
+    return b.String()
+}
+
+// mongo computes the fields a price is sorted by: whether it has no worth,
+// and its worth. Amounts are held in minor units of their currency.
+func (p *Prices) mongo() bson.D {
+    currencies := p.currencies()
+    branches := make(bson.A, len(currencies))
+    for i, currency := range currencies {
+        perUnit := p.Worth[currency] / math.Pow10(money.Exponent(currency))
+        branches[i] = bson.M{
+            "case": bson.M{"$eq": bson.A{"$price.currency", currency}},
+            "then": bson.M{"$multiply": bson.A{"$price.amount", perUnit}},
+        }
+    }
+    return bson.D{
+        {Key: "price_unconverted", Value: bson.M{"$not": bson.A{bson.M{"$in": bson.A{"$price.currency", stringValues(currencies)}}}}},
+        {Key: "price_converted", Value: bson.M{"$switch": bson.M{"branches": branches, "default": 0}}},
+    }
+}
+
+// in returns the condition with its values and bounds, worth in the currency
+// of a listing, converted into amounts of a currency worth worth
+func (c Condition) in(worth float64) Condition {
+    converted := Condition{Field: c.Field, Operator: c.Operator}
+    for _, v := range c.Values {
+        n, _ := strconv.ParseFloat(v, 64)
+        converted.Values = append(converted.Values, strconv.FormatFloat(n/worth, 'f', -1, 64))
+    }
+    if c.Min != nil {
+        min := *c.Min / worth
+        converted.Min = &min
+    }
+    if c.Max != nil {
+        max := *c.Max / worth
+        converted.Max = &max
+    }
+    return converted
+}
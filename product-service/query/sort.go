@@ -0,0 +1,127 @@
+package query
+
+import (
+    "fmt"
+    "strings"
+
+    "go.mongodb.org/mongo-driver/bson"
+)
+
+// SortField is a product attribute results may be ordered by. SortPrice orders
+// products by the worth of their price as Prices gives it.
+type SortField string
+
+const (
+    SortPrice     SortField = "price"
+    SortCreatedAt SortField = "created_at"
+    SortRatings   SortField = "ratings"
+    SortTitle     SortField = "title"
+)
+
+// Direction is the order applied to a SortField
+type Direction string
+
+const (
+    Asc  Direction = "asc"
+    Desc Direction = "desc"
+)
+
+const maxSortKeys = 4
+
+// Sort is one key of a multi-key ordering; earlier keys take precedence
+type Sort struct {
+    Field     SortField
+    Direction Direction
+}
+
+// sortColumns maps each allowlisted sort field to its column, which is also
+// its Mongo key; prices are ordered by their worth instead
+var sortColumns = map[SortField]string{
+    SortPrice:     "price",
+    SortCreatedAt: "created_at",
+    SortRatings:   "ratings",
+    SortTitle:     "title",
+}
+
+// ValidateSort checks sort keys against the allowlist
+func ValidateSort(sorts []Sort) error {
+    if len(sorts) > maxSortKeys {
+        return fmt.Errorf("at most %d sort keys are allowed", maxSortKeys)
+    }
+    seen := make(map[SortField]bool, len(sorts))
+    for _, s := range sorts {
+        if _, ok := sortColumns[s.Field]; !ok {
+            return fmt.Errorf("unknown sort field %q", s.Field)
+        }
+        if s.Direction != Asc && s.Direction != Desc {
+            return fmt.Errorf("unknown sort direction %q", s.Direction)
+        }
+        if seen[s.Field] {
+            return fmt.Errorf("sort field %q is repeated", s.Field)
+        }
+        seen[s.Field] = true
+    }
+    return nil
+}
+
+// validateSortPrices checks sort keys against the allowlist, and that prices
+// are given when they are sorted by
+func validateSortPrices(sorts []Sort, prices *Prices) error {
+    if err := ValidateSort(sorts); err != nil {
+        return err
+    }
+    if prices == nil && UsesPrices(nil, sorts) {
+        return ErrNoPrices
+    }
+    return nil
+}
+
+// SortSQL compiles sort keys into an ORDER BY clause over the products table,
+// with its parameters. The product ID is always the final key so paging
+// through results is stable.
+func SortSQL(sorts []Sort, prices *Prices) (string, []interface{}, error) {
+    if err := validateSortPrices(sorts, prices); err != nil {
+        return "", nil, err
+    }
+    var args []interface{}
+    keys := make([]string, 0, 2*len(sorts)+1)
+    for _, s := range sorts {
+        direction := strings.ToUpper(string(s.Direction))
+        if s.Field == SortPrice {
+            keys = append(keys, "("+prices.sql(&args)+") IS NULL ASC", prices.sql(&args)+" "+direction)
+            continue
+        }
+        keys = append(keys, "products."+sortColumns[s.Field]+" "+direction)
+    }
+    keys = append(keys, "products.id ASC")
+    return " ORDER BY " + strings.Join(keys, ", "), args, nil
+}
+
+// MongoSort orders MongoDB documents. Fields are computed for Order with
+// $addFields, and are not part of the documents returned.
+type MongoSort struct {
+    Fields bson.D
+    Order  bson.D
+}
+
+// SortMongo compiles sort keys into a MongoDB sort
+func SortMongo(sorts []Sort, prices *Prices) (MongoSort, error) {
+    if err := validateSortPrices(sorts, prices); err != nil {
+        return MongoSort{}, err
+    }
+    var sort MongoSort
+    for _, s := range sorts {
+        direction := 1
+        if s.Direction == Desc {
+            direction = -1
+        }
+        if s.Field == SortPrice {
+            sort.Fields = prices.mongo()
+            sort.Order = append(sort.Order, bson.E{Key: "price_unconverted", Value: 1}, bson.E{Key: "price_converted", Value: direction})
+            continue
+        }
+        sort.Order = append(sort.Order, bson.E{Key: sortColumns[s.Field], Value: direction})
+    }
+    sort.Order = append(sort.Order, bson.E{Key: "_id", Value: 1})
+    return sort, nil
+}
@@ -0,0 +1,105 @@
+package query
+
+import (
+    "errors"
+    "reflect"
+    "testing"
+
+    "go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSortSQL(t *testing.T) {
+    worth := "CASE products.currency WHEN ? THEN products.price * ? WHEN ? THEN products.price * ? ELSE NULL END"
+    worthArgs := []interface{}{"EUR", 2.0, "USD", 1.0}
+
+    tests := []struct {
+        name     string
+        sorts    []Sort
+        prices   *Prices
+        want     string
+        wantArgs []interface{}
+        wantErr  bool
+    }{
+        {
+            name: "Default order",
+            want: " ORDER BY products.id ASC",
+        },
+        {
+            name:     "Newest then cheapest",
+            sorts:    []Sort{{Field: SortCreatedAt, Direction: Desc}, {Field: SortPrice, Direction: Asc}},
+            prices:   eurPrices,
+            want:     " ORDER BY products.created_at DESC, (" + worth + ") IS NULL ASC, " + worth + " ASC, products.id ASC",
+            wantArgs: append(append([]interface{}{}, worthArgs...), worthArgs...),
+        },
+        {
+            name:     "Dearest first, prices without a worth last",
+            sorts:    []Sort{{Field: SortPrice, Direction: Desc}},
+            prices:   eurPrices,
+            want:     " ORDER BY (" + worth + ") IS NULL ASC, " + worth + " DESC, products.id ASC",
+            wantArgs: append(append([]interface{}{}, worthArgs...), worthArgs...),
+        },
+        {
+            name:    "Unknown field",
+            sorts:   []Sort{{Field: "price; DROP TABLE products", Direction: Asc}},
+            wantErr: true,
+        },
+        {
+            name:    "Repeated field",
+            sorts:   []Sort{{Field: SortTitle, Direction: Asc}, {Field: SortTitle, Direction: Desc}},
+            wantErr: true,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, args, err := SortSQL(tt.sorts, tt.prices)
+            if (err != nil) != tt.wantErr {
+                t.Fatalf("SortSQL() error = %v, wantErr %v", err, tt.wantErr)
+            }
+            if got != tt.want {
+                t.Errorf("SortSQL() = %q, want %q", got, tt.want)
+            }
+            if !reflect.DeepEqual(args, tt.wantArgs) {
+                t.Errorf("SortSQL() args = %v, want %v", args, tt.wantArgs)
+            }
+        })
+    }
+
+    if _, _, err := SortSQL([]Sort{{Field: SortPrice, Direction: Asc}}, nil); !errors.Is(err, ErrNoPrices) {
+        t.Errorf("SortSQL() by price without prices error = %v, want %v", err, ErrNoPrices)
+    }
+}
+
+func TestSortMongo(t *testing.T) {
+    got, err := SortMongo([]Sort{{Field: SortPrice, Direction: Desc}, {Field: SortRatings, Direction: Desc}}, eurPrices)
+    if err != nil {
+        t.Fatalf("SortMongo() error = %v", err)
+    }
+    wantOrder := bson.D{
+        {Key: "price_unconverted", Value: 1},
+        {Key: "price_converted", Value: -1},
+        {Key: "ratings", Value: -1},
+        {Key: "_id", Value: 1},
+    }
+    if !reflect.DeepEqual(got.Order, wantOrder) {
+        t.Errorf("SortMongo() order = %v, want %v", got.Order, wantOrder)
+    }
+    wantFields := bson.D{
+        {Key: "price_unconverted", Value: bson.M{"$not": bson.A{bson.M{"$in": bson.A{"$price.currency", bson.A{"EUR", "USD"}}}}}},
+        {Key: "price_converted", Value: bson.M{"$switch": bson.M{
+            "branches": bson.A{
+                bson.M{"case": bson.M{"$eq": bson.A{"$price.currency", "EUR"}}, "then": bson.M{"$multiply": bson.A{"$price.amount", 0.02}}},
+                bson.M{"case": bson.M{"$eq": bson.A{"$price.currency", "USD"}}, "then": bson.M{"$multiply": bson.A{"$price.amount", 0.01}}},
+            },
+            "default": 0,
+        }}},
+    }
+    if !reflect.DeepEqual(got.Fields, wantFields) {
+        t.Errorf("SortMongo() fields = %v, want %v", got.Fields, wantFields)
+    }
+
+    plain, err := SortMongo([]Sort{{Field: SortTitle, Direction: Asc}}, nil)
+    if err != nil || plain.Fields != nil {
+        t.Errorf("SortMongo() by title = %+v, %v, want no computed fields", plain, err)
+    }
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+    "context"
+    "strings"
+    "time"
+
+    "github.com/google/uuid"
+    "github.com/samObot19/shopverse/product-service/models"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Categories live in their own collection; products refer to them by
+// category_id and keep a copy of the name in category.
+
+func (r *MongoProductRepository) categories() *mongo.Collection {
+    return r.collection.Database().Collection("categories")
+}
+
+func (r *MongoProductRepository) CreateCategory(ctx context.Context, category *models.Category) error {
+    _, err := r.categories().InsertOne(ctx, category)
+    if mongo.IsDuplicateKeyError(err) {
+        return ErrDuplicateCategory
+    }
+    return err
+}
+
+func (r *MongoProductRepository) GetCategory(ctx context.Context, id string) (*models.Category, error) {
+    var category models.Category
+    err := r.categories().FindOne(ctx, bson.M{"_id": id}).Decode(&category)
+    if err != nil {
+        if err == mongo.ErrNoDocuments {
+            return nil, ErrCategoryNotFound
+        }
+        return nil, err
+    }
+    return &category, nil
+}
+
+func (r *MongoProductRepository) ListCategories(ctx context.Context) ([]*models.Category, error) {
+    cursor, err := r.categories().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{
+        {Key: "parent_id", Value: 1}, {Key: "position", Value: 1}, {Key: "name", Value: 1},
+    }))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var categories []*models.Category
+    for cursor.Next(ctx) {
+        var category models.Category
+        if err := cursor.Decode(&category); err != nil {
+            return nil, err
+        }
+        categories = append(categories, &category)
+    }
+    return categories, cursor.Err()
+}
+
+func (r *MongoProductRepository) UpdateCategory(ctx context.Context, id string, category *models.Category) error {
+    result, err := r.categories().UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+        "parent_id": category.ParentID,
+        "name":      category.Name,
+        "slug":      category.Slug,
+        "position":  category.Position,
+    }})
+    if mongo.IsDuplicateKeyError(err) {
+        return ErrDuplicateCategory
+    }
+    if err != nil {
+        return err
+    }
+    if result.MatchedCount == 0 {
+        return ErrCategoryNotFound
+    }
+
+    _, err = r.collection.UpdateMany(ctx, bson.M{"category_id": id}, bson.M{"$set": bson.M{"category": category.Name}})
+    return err
+}
+
+func (r *MongoProductRepository) DeleteCategory(ctx context.Context, id string) error {
+    children, err := r.categories().CountDocuments(ctx, bson.M{"parent_id": id}, options.Count().SetLimit(1))
+    if err != nil {
+        return err
+    }
+    products, err := r.collection.CountDocuments(ctx, bson.M{"category_id": id}, options.Count().SetLimit(1))
+    if err != nil {
+        return err
+    }
+    if children > 0 || products > 0 {
+        return ErrCategoryInUse
+    }
+
+    result, err := r.categories().DeleteOne(ctx, bson.M{"_id": id})
+    if err != nil {
+        return err
+    }
+    if result.DeletedCount == 0 {
+        return ErrCategoryNotFound
+    }
+    return nil
+}
+
+// ensureCategories creates the category indexes. While there are no
+// categories yet, every category name in use becomes a top-level category and
+// its products are filed under it.
+func (r *MongoProductRepository) ensureCategories(ctx context.Context) error {
+    _, err := r.categories().Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {
+            Keys:    bson.D{{Key: "parent_id", Value: 1}, {Key: "slug", Value: 1}},
+            Options: options.Index().SetUnique(true),
+        },
+        {Keys: bson.D{{Key: "parent_id", Value: 1}, {Key: "position", Value: 1}}},
+    })
+    if err != nil {
+        return err
+    }
+
+    count, err := r.categories().CountDocuments(ctx, bson.M{}, options.Count().SetLimit(1))
+    if err != nil || count > 0 {
+        return err
+    }
+    names, err := r.collection.Distinct(ctx, "category", bson.M{"category": bson.M{"$ne": ""}})
+    if err != nil {
+        return err
+    }
+    for _, value := range names {
+        name, ok := value.(string)
+        if !ok {
+            continue
+        }
+        category := &models.Category{
+            ID:        uuid.NewString(),
+            Name:      name,
+            Slug:      strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "-")),
+            CreatedAt: time.Now().UTC(),
+        }
+        if err := r.CreateCategory(ctx, category); err != nil {
+            return err
+        }
+        _, err := r.collection.UpdateMany(ctx, bson.M{"category": name}, bson.M{"$set": bson.M{"category_id": category.ID}})
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+    "context"
+
+    "github.com/samObot19/shopverse/product-service/models"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Exchange rates are keyed by currency code.
+
+func (r *MongoProductRepository) exchangeRates() *mongo.Collection {
+    return r.collection.Database().Collection("exchange_rates")
+}
+
+func (r *MongoProductRepository) ListExchangeRates(ctx context.Context) ([]*models.ExchangeRate, error) {
+    cursor, err := r.exchangeRates().Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var rates []*models.ExchangeRate
+    if err := cursor.All(ctx, &rates); err != nil {
+        return nil, err
+    }
+    return rates, nil
+}
+
+func (r *MongoProductRepository) SetExchangeRate(ctx context.Context, rate *models.ExchangeRate) error {
+    _, err := r.exchangeRates().ReplaceOne(ctx, bson.M{"_id": rate.Currency}, rate, options.Replace().SetUpsert(true))
+    return err
+}
+
+func (r *MongoProductRepository) DeleteExchangeRate(ctx context.Context, currency string) error {
+    result, err := r.exchangeRates().DeleteOne(ctx, bson.M{"_id": currency})
+    if err != nil {
+        return err
+    }
+    if result.DeletedCount == 0 {
+        return ErrExchangeRateNotFound
+    }
+    return nil
+}
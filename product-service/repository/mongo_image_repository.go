@@ -0,0 +1,97 @@
+package repository
+
+import (
+    "context"
+
+    "github.com/samObot19/shopverse/product-service/models"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Product images live in their own collection with their renditions
+// embedded.
+
+func (r *MongoProductRepository) productImages() *mongo.Collection {
+    return r.collection.Database().Collection("product_images")
+}
+
+// CreateProductImage counts the upload on the product document in the
+// transaction that inserts the image, so concurrent uploads of a product
+// conflict and the one retried takes the next position
+func (r *MongoProductRepository) CreateProductImage(ctx context.Context, image *models.ProductImage) error {
+    return r.inTransaction(ctx, func(ctx context.Context) error {
+        result, err := r.collection.UpdateOne(ctx, bson.M{"_id": image.ProductID}, bson.M{"$inc": bson.M{"image_uploads": 1}})
+        if err != nil {
+            return err
+        }
+        if result.MatchedCount == 0 {
+            return ErrProductNotFound
+        }
+
+        var last models.ProductImage
+        err = r.productImages().FindOne(ctx, bson.M{"product_id": image.ProductID},
+            options.FindOne().SetSort(bson.D{{Key: "position", Value: -1}}).SetProjection(bson.M{"position": 1})).Decode(&last)
+        switch {
+        case err == mongo.ErrNoDocuments:
+            image.Position = 0
+        case err != nil:
+            return err
+        default:
+            image.Position = last.Position + 1
+        }
+        _, err = r.productImages().InsertOne(ctx, image)
+        return err
+    })
+}
+
+func (r *MongoProductRepository) GetProductImage(ctx context.Context, id string) (*models.ProductImage, error) {
+    var image models.ProductImage
+    err := r.productImages().FindOne(ctx, bson.M{"_id": id}).Decode(&image)
+    if err != nil {
+        if err == mongo.ErrNoDocuments {
+            return nil, ErrImageNotFound
+        }
+        return nil, err
+    }
+    return &image, nil
+}
+
+func (r *MongoProductRepository) ListProductImages(ctx context.Context, productID string) ([]*models.ProductImage, error) {
+    cursor, err := r.productImages().Find(ctx, bson.M{"product_id": productID}, options.Find().
+        SetSort(bson.D{{Key: "position", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var images []*models.ProductImage
+    if err := cursor.All(ctx, &images); err != nil {
+        return nil, err
+    }
+    return images, nil
+}
+
+func (r *MongoProductRepository) UpdateProductImage(ctx context.Context, id, altText string, position int) error {
+    result, err := r.productImages().UpdateOne(ctx, bson.M{"_id": id},
+        bson.M{"$set": bson.M{"alt_text": altText, "position": position}})
+    if err != nil {
+        return err
+    }
+    if result.MatchedCount == 0 {
+        return ErrImageNotFound
+    }
+    return nil
+}
+
+func (r *MongoProductRepository) DeleteProductImage(ctx context.Context, id string) error {
+    result, err := r.productImages().DeleteOne(ctx, bson.M{"_id": id})
+    if err != nil {
+        return err
+    }
+    if result.DeletedCount == 0 {
+        return ErrImageNotFound
+    }
+    return nil
+}
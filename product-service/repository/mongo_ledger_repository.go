@@ -0,0 +1,153 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/models"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Movements live in their own collection. Their IDs are ObjectID hex strings,
+// which sort in creation order. A movement is recorded in the transaction of
+// the stock change it describes; StockDiscrepancies still reports any stock
+// changed outside the ledger.
+
+func (r *MongoProductRepository) movements() *mongo.Collection {
+    return r.collection.Database().Collection("stock_movements")
+}
+
+// recordMovement appends a movement to the ledger, sets its ID and raises its
+// StockChanged event
+func (r *MongoProductRepository) recordMovement(ctx context.Context, movement *models.StockMovement) error {
+    movement.ID = primitive.NewObjectID().Hex()
+    if movement.CreatedAt.IsZero() {
+        movement.CreatedAt = time.Now().UTC()
+    }
+    if _, err := r.movements().InsertOne(ctx, movement); err != nil {
+        return err
+    }
+    return r.writeEvent(ctx, stockEvent(movement))
+}
+
+func (r *MongoProductRepository) AdjustStock(ctx context.Context, movement *models.StockMovement) error {
+    if movement.VariantID != "" {
+        variant, err := r.GetVariantByID(ctx, movement.VariantID)
+        if err != nil {
+            return err
+        }
+        if variant.ProductID != movement.ProductID {
+            return fmt.Errorf("variant %s does not belong to product %s", movement.VariantID, movement.ProductID)
+        }
+    } else if _, err := r.GetProductByID(ctx, movement.ProductID); err != nil {
+        return err
+    }
+    return r.moveStock(ctx, movement)
+}
+
+func (r *MongoProductRepository) GetStockHistory(ctx context.Context, productID, variantID, before string, limit int) ([]*models.StockMovement, error) {
+    filter := bson.M{"product_id": productID}
+    if variantID != "" {
+        filter["variant_id"] = variantID
+    }
+    if before != "" {
+        filter["_id"] = bson.M{"$lt": before}
+    }
+
+    cursor, err := r.movements().Find(ctx, filter,
+        options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(limit)))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var movements []*models.StockMovement
+    if err := cursor.All(ctx, &movements); err != nil {
+        return nil, err
+    }
+    return movements, nil
+}
+
+func (r *MongoProductRepository) StockDiscrepancies(ctx context.Context) ([]models.StockDiscrepancy, error) {
+    type key struct{ productID, variantID string }
+
+    cursor, err := r.movements().Aggregate(ctx, mongo.Pipeline{
+        {{Key: "$group", Value: bson.M{
+            "_id":    bson.M{"product_id": "$product_id", "variant_id": "$variant_id"},
+            "ledger": bson.M{"$sum": "$delta"},
+        }}},
+    })
+    if err != nil {
+        return nil, err
+    }
+    var sums []struct {
+        ID struct {
+            ProductID string `bson:"product_id"`
+            VariantID string `bson:"variant_id"`
+        } `bson:"_id"`
+        Ledger int `bson:"ledger"`
+    }
+    if err := cursor.All(ctx, &sums); err != nil {
+        return nil, err
+    }
+    ledger := make(map[key]int, len(sums))
+    for _, s := range sums {
+        ledger[key{s.ID.ProductID, s.ID.VariantID}] = s.Ledger
+    }
+
+    cursor, err = r.collection.Find(ctx, bson.M{},
+        options.Find().SetProjection(bson.M{"stock": 1, "variants._id": 1, "variants.stock": 1}))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var discrepancies []models.StockDiscrepancy
+    for cursor.Next(ctx) {
+        var doc struct {
+            ID       string `bson:"_id"`
+            Stock    int    `bson:"stock"`
+            Variants []struct {
+                ID    string `bson:"_id"`
+                Stock int    `bson:"stock"`
+            } `bson:"variants"`
+        }
+        if err := cursor.Decode(&doc); err != nil {
+            return nil, err
+        }
+        if sum := ledger[key{doc.ID, ""}]; sum != doc.Stock {
+            discrepancies = append(discrepancies, models.StockDiscrepancy{ProductID: doc.ID, Stock: doc.Stock, Ledger: sum})
+        }
+        for _, v := range doc.Variants {
+            if sum := ledger[key{doc.ID, v.ID}]; sum != v.Stock {
+                discrepancies = append(discrepancies, models.StockDiscrepancy{ProductID: doc.ID, VariantID: v.ID, Stock: v.Stock, Ledger: sum})
+            }
+        }
+    }
+    if err := cursor.Err(); err != nil {
+        return nil, err
+    }
+
+    sort.Slice(discrepancies, func(i, j int) bool {
+        if discrepancies[i].ProductID != discrepancies[j].ProductID {
+            return discrepancies[i].ProductID < discrepancies[j].ProductID
+        }
+        return discrepancies[i].VariantID < discrepancies[j].VariantID
+    })
+    return discrepancies, nil
+}
+
+// ensureMovementIndexes backs paging through the history of a product or variant
+func (r *MongoProductRepository) ensureMovementIndexes(ctx context.Context) error {
+    _, err := r.movements().Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "_id", Value: -1}}},
+        {Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "variant_id", Value: 1}, {Key: "_id", Value: -1}}},
+    })
+    return err
+}
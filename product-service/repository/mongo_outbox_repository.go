@@ -0,0 +1,90 @@
+package repository
+
+import (
+    "context"
+    "encoding/json"
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/models"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Outbox events live in their own collection. Their IDs are ObjectID hex
+// strings, which sort in creation order. An event is written in the
+// transaction of the change that raised it, so both are kept or neither is.
+// A relay claims events by setting claimed_by and claimed_until on them.
+
+func (r *MongoProductRepository) outbox() *mongo.Collection {
+    return r.collection.Database().Collection("outbox_events")
+}
+
+// writeEvent adds an event to the outbox
+func (r *MongoProductRepository) writeEvent(ctx context.Context, event *models.Event) error {
+    payload, err := json.Marshal(event)
+    if err != nil {
+        return err
+    }
+    _, err = r.outbox().InsertOne(ctx, &models.OutboxEvent{
+        ID:        primitive.NewObjectID().Hex(),
+        ProductID: event.ProductID,
+        Payload:   payload,
+        CreatedAt: event.OccurredAt,
+    })
+    return err
+}
+
+func (r *MongoProductRepository) ClaimEvents(ctx context.Context, claimant string, now time.Time, lease time.Duration, limit int) ([]*models.OutboxEvent, error) {
+    cursor, err := r.outbox().Find(ctx, bson.M{}, options.Find().
+        SetSort(bson.D{{Key: "_id", Value: 1}}).
+        SetLimit(int64(limit)))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var events []*models.OutboxEvent
+    for cursor.Next(ctx) {
+        var event models.OutboxEvent
+        if err := cursor.Decode(&event); err != nil {
+            return nil, err
+        }
+        // events are claimed one at a time from the head; a relay that finds
+        // one claimed by another, or already gone, claims none after it
+        result, err := r.outbox().UpdateOne(ctx, bson.M{
+            "_id": event.ID,
+            "$or": bson.A{
+                bson.M{"claimed_until": nil},
+                bson.M{"claimed_by": claimant},
+                bson.M{"claimed_until": bson.M{"$lte": now}},
+            },
+        }, bson.M{"$set": bson.M{"claimed_by": claimant, "claimed_until": now.Add(lease)}})
+        if err != nil {
+            return nil, err
+        }
+        if result.MatchedCount == 0 {
+            break
+        }
+        events = append(events, &event)
+    }
+    return events, cursor.Err()
+}
+
+func (r *MongoProductRepository) DeleteEvents(ctx context.Context, ids []string) error {
+    if len(ids) == 0 {
+        return nil
+    }
+    _, err := r.outbox().DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+    return err
+}
+
+func (r *MongoProductRepository) RecordEventFailure(ctx context.Context, id string, reason string) error {
+    _, err := r.outbox().UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+        "$inc": bson.M{"attempts": 1},
+        "$set": bson.M{"last_error": reason},
+    })
+    return err
+}
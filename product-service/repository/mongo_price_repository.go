@@ -0,0 +1,229 @@
+package repository
+
+import (
+    "context"
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/models"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Price lists embed their entries. The price history lives in its own
+// collection, with ObjectID hex string IDs that sort in creation order.
+
+func (r *MongoProductRepository) priceLists() *mongo.Collection {
+    return r.collection.Database().Collection("price_lists")
+}
+
+func (r *MongoProductRepository) priceHistory() *mongo.Collection {
+    return r.collection.Database().Collection("price_history")
+}
+
+func (r *MongoProductRepository) CreatePriceList(ctx context.Context, list *models.PriceList) error {
+    _, err := r.priceLists().InsertOne(ctx, list)
+    return err
+}
+
+func (r *MongoProductRepository) GetPriceList(ctx context.Context, id string) (*models.PriceList, error) {
+    var list models.PriceList
+    err := r.priceLists().FindOne(ctx, bson.M{"_id": id}).Decode(&list)
+    if err != nil {
+        if err == mongo.ErrNoDocuments {
+            return nil, ErrPriceListNotFound
+        }
+        return nil, err
+    }
+    setEntryListIDs(&list)
+    return &list, nil
+}
+
+func (r *MongoProductRepository) ListPriceLists(ctx context.Context) ([]*models.PriceList, error) {
+    return r.findPriceLists(ctx, bson.M{})
+}
+
+func (r *MongoProductRepository) UpdatePriceList(ctx context.Context, id string, list *models.PriceList) error {
+    result, err := r.priceLists().UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+        "name":      list.Name,
+        "starts_at": list.StartsAt,
+        "ends_at":   list.EndsAt,
+        "entries":   list.Entries,
+    }})
+    if err != nil {
+        return err
+    }
+    if result.MatchedCount == 0 {
+        return ErrPriceListNotFound
+    }
+    return nil
+}
+
+func (r *MongoProductRepository) DeletePriceList(ctx context.Context, id string) error {
+    result, err := r.priceLists().DeleteOne(ctx, bson.M{"_id": id})
+    if err != nil {
+        return err
+    }
+    if result.DeletedCount == 0 {
+        return ErrPriceListNotFound
+    }
+    return nil
+}
+
+func (r *MongoProductRepository) ActivePriceLists(ctx context.Context, productIDs []string, at time.Time) ([]*models.PriceList, error) {
+    if len(productIDs) == 0 {
+        return nil, nil
+    }
+    lists, err := r.findPriceLists(ctx, bson.M{
+        "starts_at":          bson.M{"$lte": at},
+        "$or":                bson.A{bson.M{"ends_at": nil}, bson.M{"ends_at": bson.M{"$gt": at}}},
+        "entries.product_id": bson.M{"$in": productIDs},
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    // narrow each list to the entries of the requested products
+    wanted := make(map[string]bool, len(productIDs))
+    for _, id := range productIDs {
+        wanted[id] = true
+    }
+    for _, list := range lists {
+        entries := list.Entries[:0]
+        for _, entry := range list.Entries {
+            if wanted[entry.ProductID] {
+                entries = append(entries, entry)
+            }
+        }
+        list.Entries = entries
+    }
+    return lists, nil
+}
+
+// findPriceLists loads the price lists matching filter, latest start first
+func (r *MongoProductRepository) findPriceLists(ctx context.Context, filter bson.M) ([]*models.PriceList, error) {
+    cursor, err := r.priceLists().Find(ctx, filter,
+        options.Find().SetSort(bson.D{{Key: "starts_at", Value: -1}, {Key: "_id", Value: 1}}))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var lists []*models.PriceList
+    if err := cursor.All(ctx, &lists); err != nil {
+        return nil, err
+    }
+    for _, list := range lists {
+        setEntryListIDs(list)
+    }
+    return lists, nil
+}
+
+// setEntryListIDs fills in the list ID that embedded entries do not store
+func setEntryListIDs(list *models.PriceList) {
+    for i := range list.Entries {
+        list.Entries[i].PriceListID = list.ID
+    }
+}
+
+func (r *MongoProductRepository) RecordPriceChanges(ctx context.Context, changes []*models.PriceChange) error {
+    if len(changes) == 0 {
+        return nil
+    }
+    docs := make([]interface{}, len(changes))
+    for i, change := range changes {
+        change.ID = primitive.NewObjectID().Hex()
+        docs[i] = change
+    }
+    _, err := r.priceHistory().InsertMany(ctx, docs)
+    return err
+}
+
+func (r *MongoProductRepository) GetPriceHistory(ctx context.Context, productID, before string, limit int) ([]*models.PriceChange, error) {
+    filter := bson.M{"product_id": productID}
+    if before != "" {
+        filter["_id"] = bson.M{"$lt": before}
+    }
+
+    cursor, err := r.priceHistory().Find(ctx, filter,
+        options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(limit)))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var changes []*models.PriceChange
+    if err := cursor.All(ctx, &changes); err != nil {
+        return nil, err
+    }
+    return changes, nil
+}
+
+// deleteProductPrices removes the entries of a deleted product from every price
+// list; its price history is kept
+func (r *MongoProductRepository) deleteProductPrices(ctx context.Context, productID string) error {
+    _, err := r.priceLists().UpdateMany(ctx, bson.M{"entries.product_id": productID},
+        bson.M{"$pull": bson.M{"entries": bson.M{"product_id": productID}}})
+    return err
+}
+
+// ensurePriceIndexes backs the lookup of the price lists in effect for a
+// product and paging through its price history
+func (r *MongoProductRepository) ensurePriceIndexes(ctx context.Context) error {
+    _, err := r.priceLists().Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {Keys: bson.D{{Key: "entries.product_id", Value: 1}, {Key: "starts_at", Value: 1}}},
+        {Keys: bson.D{{Key: "starts_at", Value: -1}}},
+    })
+    if err != nil {
+        return err
+    }
+    _, err = r.priceHistory().Indexes().CreateOne(ctx, mongo.IndexModel{
+        Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "_id", Value: -1}},
+    })
+    if err != nil {
+        return err
+    }
+    return r.convertLegacyPrices(ctx)
+}
+
+// convertLegacyPrices rewrites the prices stored as doubles, before prices
+// were Money, as minor units of the default currency
+func (r *MongoProductRepository) convertLegacyPrices(ctx context.Context) error {
+    number := bson.M{"$type": "number"}
+    inEach := func(array string) bson.M {
+        return bson.M{"$map": bson.M{"input": "$" + array, "as": "item", "in": bson.M{
+            "$mergeObjects": bson.A{"$$item", bson.M{"price": legacyPrice("$$item.price")}},
+        }}}
+    }
+    updates := []struct {
+        collection *mongo.Collection
+        filter     bson.M
+        set        bson.M
+    }{
+        {r.collection, bson.M{"price": number}, bson.M{"price": legacyPrice("$price")}},
+        {r.collection, bson.M{"variants.price": number}, bson.M{"variants": inEach("variants")}},
+        {r.priceLists(), bson.M{"entries.price": number}, bson.M{"entries": inEach("entries")}},
+        {r.priceHistory(), bson.M{"price": number}, bson.M{"price": legacyPrice("$price")}},
+    }
+    for _, u := range updates {
+        if _, err := u.collection.UpdateMany(ctx, u.filter, mongo.Pipeline{{{Key: "$set", Value: u.set}}}); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// legacyPrice converts the double at path to Money, leaving anything else,
+// such as a price that is already Money or unset, as it is
+func legacyPrice(path string) bson.M {
+    return bson.M{"$cond": bson.A{
+        bson.M{"$isNumber": path},
+        bson.M{
+            "amount":   bson.M{"$toLong": bson.M{"$round": bson.A{bson.M{"$multiply": bson.A{path, 100}}, 0}}},
+            "currency": models.DefaultCurrency,
+        },
+        path,
+    }}
+}
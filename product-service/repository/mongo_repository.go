@@ -2,11 +2,14 @@ package repository
 
 import (
     "context"
-    "errors"
+    "time"
+
     "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/query"
 
     "go.mongodb.org/mongo-driver/bson"
     "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type MongoProductRepository struct {
@@ -17,31 +20,143 @@ func NewMongoProductRepository(collection *mongo.Collection) *MongoProductReposi
     return &MongoProductRepository{collection: collection}
 }
 
-func (r *MongoProductRepository) CreateProduct(ctx context.Context, product *models.Product) error {
-    _, err := r.collection.InsertOne(ctx, product)
+// inTransaction runs fn in a transaction, so the writes it makes, such as a
+// change and the outbox event it raises, are applied together or not at all.
+// The driver retries fn on transient errors. A call made inside a transaction
+// joins it. Transactions need MongoDB to run as a replica set.
+func (r *MongoProductRepository) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+    if session := mongo.SessionFromContext(ctx); session != nil {
+        return fn(ctx)
+    }
+    session, err := r.collection.Database().Client().StartSession()
+    if err != nil {
+        return err
+    }
+    defer session.EndSession(ctx)
+    _, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+        return nil, fn(sc)
+    })
     return err
 }
 
+// EnsureIndexes creates the indexes of the products collection, which back
+// category lookups, the common sort keys, variant lookups and the purge of
+// archived products and keep product and variant SKUs unique, and those of
+// the reservation, stock movement, review and price collections. It also
+// backfills product versions and creates the default warehouse and the first
+// categories.
+func (r *MongoProductRepository) EnsureIndexes(ctx context.Context) error {
+    _, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {Keys: bson.D{{Key: "price.currency", Value: 1}, {Key: "price.amount", Value: 1}}},
+        {Keys: bson.D{{Key: "created_at", Value: -1}}},
+        {Keys: bson.D{{Key: "ratings", Value: -1}}},
+        {Keys: bson.D{{Key: "category", Value: 1}, {Key: "price.currency", Value: 1}, {Key: "price.amount", Value: 1}}},
+        {Keys: bson.D{{Key: "category", Value: 1}, {Key: "created_at", Value: -1}}},
+        {Keys: bson.D{{Key: "variants._id", Value: 1}}, Options: options.Index().SetSparse(true)},
+        {Keys: bson.D{{Key: "variants.sku", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+        {Keys: bson.D{{Key: "category_id", Value: 1}}},
+        {Keys: bson.D{{Key: "sku", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+        {Keys: bson.D{{Key: "deleted_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+    })
+    if err != nil {
+        return err
+    }
+    // products written before versions were introduced start at version 1
+    _, err = r.collection.UpdateMany(ctx, bson.M{"version": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"version": 1}})
+    if err != nil {
+        return err
+    }
+    if err := r.ensureReservationIndexes(ctx); err != nil {
+        return err
+    }
+    if err := r.ensureMovementIndexes(ctx); err != nil {
+        return err
+    }
+    if err := r.ensureWarehouses(ctx); err != nil {
+        return err
+    }
+    if err := r.ensureCategories(ctx); err != nil {
+        return err
+    }
+    if err := r.ensureReviewIndexes(ctx); err != nil {
+        return err
+    }
+    return r.ensurePriceIndexes(ctx)
+}
+
+func (r *MongoProductRepository) CreateProduct(ctx context.Context, product *models.Product) error {
+    return r.inTransaction(ctx, func(ctx context.Context) error {
+        if _, err := r.collection.InsertOne(ctx, product); err != nil {
+            return err
+        }
+        if err := r.writeEvent(ctx, productEvent(models.EventProductCreated, product.ID, product)); err != nil {
+            return err
+        }
+        if product.Stock == 0 {
+            return nil
+        }
+        // the initial stock is received into the default warehouse
+        change := models.StockChange{WarehouseID: models.DefaultWarehouseID, Reason: models.StockReceiving, Actor: models.SystemActor}
+        return r.placeStock(ctx, newMovement(product.ID, "", product.Stock, change))
+    })
+}
+
 func (r *MongoProductRepository) GetProductByID(ctx context.Context, id string) (*models.Product, error) {
     var product models.Product
     err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&product)
     if err != nil {
         if err == mongo.ErrNoDocuments {
-            return nil, errors.New("product not found")
+            return nil, ErrProductNotFound
         }
         return nil, err
     }
     return &product, nil
 }
 
-func (r *MongoProductRepository) GetAllProducts(ctx context.Context, filters map[string]interface{}) ([]*models.Product, error) {
-    var products []*models.Product
-    cursor, err := r.collection.Find(ctx, filters)
+func (r *MongoProductRepository) GetAllProducts(ctx context.Context, filter *query.Filter, sort []query.Sort, prices *query.Prices, includeArchived bool) ([]*models.Product, error) {
+    doc, err := filter.Mongo(prices)
+    if err != nil {
+        return nil, err
+    }
+    if !includeArchived {
+        doc["deleted_at"] = notArchived
+    }
+    return r.find(ctx, doc, sort, prices)
+}
+
+// notArchived matches the products that have not been archived
+var notArchived = bson.M{"$exists": false}
+
+// find loads the products matching filter in order. A sort on computed fields
+// runs as an aggregation, which computes them and leaves them out afterwards.
+func (r *MongoProductRepository) find(ctx context.Context, filter bson.M, sort []query.Sort, prices *query.Prices) ([]*models.Product, error) {
+    order, err := query.SortMongo(sort, prices)
+    if err != nil {
+        return nil, err
+    }
+
+    var cursor *mongo.Cursor
+    if len(order.Fields) == 0 {
+        cursor, err = r.collection.Find(ctx, filter, options.Find().SetSort(order.Order))
+    } else {
+        computed := make(bson.A, len(order.Fields))
+        for i, field := range order.Fields {
+            computed[i] = field.Key
+        }
+        cursor, err = r.collection.Aggregate(ctx, mongo.Pipeline{
+            {{Key: "$match", Value: filter}},
+            {{Key: "$addFields", Value: order.Fields}},
+            {{Key: "$sort", Value: order.Order}},
+            {{Key: "$unset", Value: computed}},
+        })
+    }
     if err != nil {
         return nil, err
     }
+
     defer cursor.Close(ctx)
 
+    var products []*models.Product
     for cursor.Next(ctx) {
         var product models.Product
         if err := cursor.Decode(&product); err != nil {
@@ -52,28 +167,256 @@ func (r *MongoProductRepository) GetAllProducts(ctx context.Context, filters map
     return products, nil
 }
 
-func (r *MongoProductRepository) UpdateProduct(ctx context.Context, id string, updatedProduct *models.Product) error {
-    _, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": updatedProduct})
-    return err
+// UpdateProduct sets the masked fields of a product if it is still at
+// updatedProduct.Version, and increments its version; a changed stock level is
+// applied to the default warehouse and recorded in the ledger as an adjustment
+func (r *MongoProductRepository) UpdateProduct(ctx context.Context, id string, updatedProduct *models.Product, mask models.ProductMask) error {
+    set := bson.M{}
+    for _, f := range fieldKeys {
+        if mask[f.field] {
+            set[f.key] = f.value(updatedProduct)
+        }
+    }
+    update := bson.M{"$inc": bson.M{"version": 1}}
+    // a product without a SKU has no sku field, so the sparse unique index skips it
+    if mask[models.ProductSKU] {
+        if updatedProduct.SKU != "" {
+            set["sku"] = updatedProduct.SKU
+        } else {
+            update["$unset"] = bson.M{"sku": ""}
+        }
+    }
+    if len(set) > 0 {
+        update["$set"] = set
+    }
+
+    return r.inTransaction(ctx, func(ctx context.Context) error {
+        var before models.Product
+        err := r.collection.FindOneAndUpdate(ctx, versionFilter(id, updatedProduct.Version), update,
+            options.FindOneAndUpdate().SetProjection(bson.M{"stock": 1, "version": 1})).Decode(&before)
+        if err != nil {
+            if err == mongo.ErrNoDocuments {
+                return r.versionMismatch(ctx, id, updatedProduct.Version)
+            }
+            if mongo.IsDuplicateKeyError(err) {
+                return ErrDuplicateSKU
+            }
+            return err
+        }
+        event := productEvent(models.EventProductUpdated, id, updatedProduct)
+        event.Product.Version = before.Version + 1
+        if err := r.writeEvent(ctx, event); err != nil {
+            return err
+        }
+        if !mask[models.ProductStock] || before.Stock == updatedProduct.Stock {
+            return nil
+        }
+        change := models.StockChange{WarehouseID: models.DefaultWarehouseID, Reason: models.StockAdjustment, Actor: models.SystemActor}
+        return r.placeStock(ctx, newMovement(id, "", updatedProduct.Stock-before.Stock, change))
+    })
 }
 
-func (r *MongoProductRepository) DeleteProduct(ctx context.Context, id string) error {
-    _, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
-    return err
+// fieldKeys maps the fields of a product other than its SKU to their document key
+var fieldKeys = []struct {
+    field models.ProductField
+    key   string
+    value func(*models.Product) interface{}
+}{
+    {models.ProductTitle, "title", func(p *models.Product) interface{} { return p.Title }},
+    {models.ProductDescription, "description", func(p *models.Product) interface{} { return p.Description }},
+    {models.ProductPrice, "price", func(p *models.Product) interface{} { return p.Price }},
+    {models.ProductStock, "stock", func(p *models.Product) interface{} { return p.Stock }},
+    {models.ProductCategory, "category", func(p *models.Product) interface{} { return p.Category }},
+    {models.ProductCategoryID, "category_id", func(p *models.Product) interface{} { return p.CategoryID }},
+    {models.ProductColor, "attributes.color", func(p *models.Product) interface{} { return p.Attributes.Color }},
+    {models.ProductSizes, "attributes.size", func(p *models.Product) interface{} { return p.Attributes.Size }},
+    {models.ProductImages, "images", func(p *models.Product) interface{} { return p.Images }},
+    {models.ProductTranslations, "translations", func(p *models.Product) interface{} { return p.Translations }},
 }
 
-func (r *MongoProductRepository) UpdateStock(ctx context.Context, id string, quantity int) error {
-    _, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"stock": quantity}})
-    return err
+func (r *MongoProductRepository) ArchiveProduct(ctx context.Context, id string, at time.Time, version int64) error {
+    filter := versionFilter(id, version)
+    filter["deleted_at"] = notArchived
+    return r.inTransaction(ctx, func(ctx context.Context) error {
+        result, err := r.collection.UpdateOne(ctx, filter,
+            bson.M{"$set": bson.M{"deleted_at": at}, "$inc": bson.M{"version": 1}})
+        if err != nil {
+            return err
+        }
+        if result.MatchedCount == 0 {
+            return r.versionMismatch(ctx, id, version)
+        }
+        return r.writeEvent(ctx, newEvent(models.EventProductArchived, id))
+    })
+}
+
+func (r *MongoProductRepository) RestoreProduct(ctx context.Context, id string) error {
+    return r.inTransaction(ctx, func(ctx context.Context) error {
+        result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$exists": true}},
+            bson.M{"$unset": bson.M{"deleted_at": ""}, "$inc": bson.M{"version": 1}})
+        if err != nil {
+            return err
+        }
+        if result.MatchedCount == 0 {
+            return r.productExists(ctx, id)
+        }
+        return r.writeEvent(ctx, newEvent(models.EventProductRestored, id))
+    })
+}
+
+// productExists returns ErrProductNotFound unless a product has the ID
+func (r *MongoProductRepository) productExists(ctx context.Context, id string) error {
+    count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
+    if err != nil {
+        return err
+    }
+    if count == 0 {
+        return ErrProductNotFound
+    }
+    return nil
+}
+
+// versionFilter matches a product that is at the given version, or at any
+// version for anyVersion
+func versionFilter(id string, version int64) bson.M {
+    filter := bson.M{"_id": id}
+    if version != anyVersion {
+        filter["version"] = version
+    }
+    return filter
+}
+
+// versionMismatch explains why a write filtered by versionFilter matched
+// nothing: the product is missing or at another version. A product at the
+// expected version was already in the state the write asked for.
+func (r *MongoProductRepository) versionMismatch(ctx context.Context, id string, expected int64) error {
+    var current models.Product
+    err := r.collection.FindOne(ctx, bson.M{"_id": id}, options.FindOne().SetProjection(bson.M{"version": 1})).Decode(&current)
+    if err != nil {
+        if err == mongo.ErrNoDocuments {
+            return ErrProductNotFound
+        }
+        return err
+    }
+    return checkVersion(id, expected, current.Version)
+}
+
+func (r *MongoProductRepository) ArchivedProducts(ctx context.Context, before time.Time, afterID string, limit int) ([]string, error) {
+    cursor, err := r.collection.Find(ctx,
+        bson.M{"deleted_at": bson.M{"$lt": before}, "_id": bson.M{"$gt": afterID}},
+        options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit)).SetProjection(bson.M{"_id": 1}))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var ids []string
+    for cursor.Next(ctx) {
+        var doc struct {
+            ID string `bson:"_id"`
+        }
+        if err := cursor.Decode(&doc); err != nil {
+            return nil, err
+        }
+        ids = append(ids, doc.ID)
+    }
+    return ids, cursor.Err()
+}
+
+// PurgeProduct deletes an archived product document, which holds its
+// variants, with its warehouse stock, reviews, images and price list entries
+func (r *MongoProductRepository) PurgeProduct(ctx context.Context, id string) (bool, error) {
+    purged := false
+    err := r.inTransaction(ctx, func(ctx context.Context) error {
+        purged = false
+        result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$exists": true}})
+        if err != nil {
+            return err
+        }
+        if result.DeletedCount == 0 {
+            return r.productExists(ctx, id)
+        }
+        if err := r.writeEvent(ctx, newEvent(models.EventProductDeleted, id)); err != nil {
+            return err
+        }
+        if _, err := r.warehouseStock().DeleteMany(ctx, bson.M{"product_id": id}); err != nil {
+            return err
+        }
+        if err := r.deleteProductReviews(ctx, id); err != nil {
+            return err
+        }
+        if _, err := r.productImages().DeleteMany(ctx, bson.M{"product_id": id}); err != nil {
+            return err
+        }
+        if err := r.deleteProductPrices(ctx, id); err != nil {
+            return err
+        }
+        purged = true
+        return nil
+    })
+    return purged && err == nil, err
+}
+
+// UpdateStock claims the expected version of a product by incrementing it,
+// then sets its stock quantity in one warehouse and records the difference
+func (r *MongoProductRepository) UpdateStock(ctx context.Context, id string, quantity int, change models.StockChange, version int64) error {
+    return r.inTransaction(ctx, func(ctx context.Context) error {
+        result, err := r.collection.UpdateOne(ctx, versionFilter(id, version), bson.M{"$inc": bson.M{"version": 1}})
+        if err != nil {
+            return err
+        }
+        if result.MatchedCount == 0 {
+            return r.versionMismatch(ctx, id, version)
+        }
+        return r.setLevel(ctx, id, "", quantity, change)
+    })
 }
 
-func (r *MongoProductRepository) GetProductsByCategory(ctx context.Context, category string) ([]*models.Product, error) {
-    return r.GetAllProducts(ctx, bson.M{"category": category})
+func (r *MongoProductRepository) GetProductsByCategory(ctx context.Context, category string, sort []query.Sort, prices *query.Prices) ([]*models.Product, error) {
+    return r.find(ctx, bson.M{"category": category, "deleted_at": notArchived}, sort, prices)
 }
 
-func (r *MongoProductRepository) SearchProducts(ctx context.Context, query string) ([]*models.Product, error) {
-    filter := bson.M{"$text": bson.M{"$search": query}}
-    return r.GetAllProducts(ctx, filter)
+// GetProductsByCategoryIDs loads the active products filed under any of the given categories
+func (r *MongoProductRepository) GetProductsByCategoryIDs(ctx context.Context, categoryIDs []string, sort []query.Sort, prices *query.Prices) ([]*models.Product, error) {
+    if len(categoryIDs) == 0 {
+        return nil, nil
+    }
+    return r.find(ctx, bson.M{"category_id": bson.M{"$in": categoryIDs}, "deleted_at": notArchived}, sort, prices)
+}
+
+// GetProductsByIDs loads the given products in no particular order; unknown IDs are skipped
+func (r *MongoProductRepository) GetProductsByIDs(ctx context.Context, ids []string) ([]*models.Product, error) {
+    if len(ids) == 0 {
+        return nil, nil
+    }
+    return r.find(ctx, bson.M{"_id": bson.M{"$in": ids}}, nil, nil)
+}
+
+// GetProductsBySKUs loads the products with any of the given SKUs; unknown SKUs are skipped
+func (r *MongoProductRepository) GetProductsBySKUs(ctx context.Context, skus []string) ([]*models.Product, error) {
+    if len(skus) == 0 {
+        return nil, nil
+    }
+    return r.find(ctx, bson.M{"sku": bson.M{"$in": skus}}, nil, nil)
+}
+
+// ImportProducts writes the batch product by product; Mongo writes are not
+// transactional, so a failure leaves the products before it written
+func (r *MongoProductRepository) ImportProducts(ctx context.Context, created, replaced []*models.Product) error {
+    for _, product := range created {
+        if err := r.CreateProduct(ctx, product); err != nil {
+            if mongo.IsDuplicateKeyError(err) {
+                return ErrDuplicateSKU
+            }
+            return err
+        }
+    }
+    for _, product := range replaced {
+        if err := r.UpdateProduct(ctx, product.ID, product, models.FullProductMask()); err != nil {
+            return err
+        }
+    }
+    return nil
 }
 
 
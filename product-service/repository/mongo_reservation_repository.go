@@ -0,0 +1,173 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/models"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Reservations live in their own collection next to the products. Stock is
+// taken item by item with conditional updates in the transaction that inserts
+// the reservation; if one item fails, none of the stock is taken.
+
+func (r *MongoProductRepository) reservations() *mongo.Collection {
+    return r.collection.Database().Collection("stock_reservations")
+}
+
+func (r *MongoProductRepository) ReserveStock(ctx context.Context, reservation *models.Reservation) error {
+    err := r.inTransaction(ctx, func(ctx context.Context) error {
+        for _, item := range reservation.Items {
+            if err := r.takeStock(ctx, reservation.ID, item); err != nil {
+                return err
+            }
+        }
+        _, err := r.reservations().InsertOne(ctx, reservation)
+        return err
+    })
+    if mongo.IsDuplicateKeyError(err) {
+        return ErrDuplicateReservation
+    }
+    return err
+}
+
+// takeStock decrements the stock of an item in its warehouse only if enough is available
+func (r *MongoProductRepository) takeStock(ctx context.Context, reservationID string, item models.ReservationItem) error {
+    change := reservationChange(reservationID, item.WarehouseID)
+    return r.moveStock(ctx, newMovement(item.ProductID, item.VariantID, -item.Quantity, change))
+}
+
+// returnStock gives the stock of items back to their warehouses
+func (r *MongoProductRepository) returnStock(ctx context.Context, reservationID string, items []models.ReservationItem) error {
+    for _, item := range items {
+        change := reservationChange(reservationID, item.WarehouseID)
+        if err := r.moveStock(ctx, newMovement(item.ProductID, item.VariantID, item.Quantity, change)); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (r *MongoProductRepository) GetReservation(ctx context.Context, id string) (*models.Reservation, error) {
+    return r.findReservation(ctx, bson.M{"_id": id})
+}
+
+func (r *MongoProductRepository) GetReservationByReference(ctx context.Context, referenceID string) (*models.Reservation, error) {
+    return r.findReservation(ctx, bson.M{"reference_id": referenceID})
+}
+
+func (r *MongoProductRepository) findReservation(ctx context.Context, filter bson.M) (*models.Reservation, error) {
+    var reservation models.Reservation
+    err := r.reservations().FindOne(ctx, filter).Decode(&reservation)
+    if err != nil {
+        if err == mongo.ErrNoDocuments {
+            return nil, ErrReservationNotFound
+        }
+        return nil, err
+    }
+    return &reservation, nil
+}
+
+func (r *MongoProductRepository) CommitReservation(ctx context.Context, id string, now time.Time) (*models.Reservation, error) {
+    var reservation models.Reservation
+    err := r.reservations().FindOneAndUpdate(ctx,
+        bson.M{"_id": id, "status": models.ReservationPending, "expires_at": bson.M{"$gt": now}},
+        bson.M{"$set": bson.M{"status": models.ReservationCommitted, "updated_at": now}},
+        options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&reservation)
+    if err == nil {
+        return &reservation, nil
+    }
+    if err != mongo.ErrNoDocuments {
+        return nil, err
+    }
+
+    // the reservation is missing, already committed or closed
+    existing, err := r.GetReservation(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    switch {
+    case existing.Status == models.ReservationCommitted:
+        return existing, nil
+    case existing.Status != models.ReservationPending:
+        return nil, fmt.Errorf("%w: it is %s", ErrReservationClosed, existing.Status)
+    default:
+        return nil, fmt.Errorf("%w: it expired at %s", ErrReservationClosed, existing.ExpiresAt.Format(time.RFC3339))
+    }
+}
+
+func (r *MongoProductRepository) ReleaseReservation(ctx context.Context, id string, status models.ReservationStatus) (*models.Reservation, error) {
+    var released *models.Reservation
+    err := r.inTransaction(ctx, func(ctx context.Context) error {
+        // moving the status first makes sure the stock is only returned once
+        var reservation models.Reservation
+        err := r.reservations().FindOneAndUpdate(ctx,
+            bson.M{"_id": id, "status": models.ReservationPending},
+            bson.M{"$set": bson.M{"status": status, "updated_at": time.Now().UTC()}},
+            options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&reservation)
+        if err == mongo.ErrNoDocuments {
+            existing, err := r.GetReservation(ctx, id)
+            if err != nil {
+                return err
+            }
+            if existing.Status == models.ReservationCommitted {
+                return fmt.Errorf("%w: it is %s", ErrReservationClosed, existing.Status)
+            }
+            released = existing
+            return nil
+        }
+        if err != nil {
+            return err
+        }
+        released = &reservation
+        return r.returnStock(ctx, reservation.ID, reservation.Items)
+    })
+    if err != nil {
+        return nil, err
+    }
+    return released, nil
+}
+
+func (r *MongoProductRepository) ExpiredReservations(ctx context.Context, now time.Time, limit int) ([]string, error) {
+    cursor, err := r.reservations().Find(ctx,
+        bson.M{"status": models.ReservationPending, "expires_at": bson.M{"$lte": now}},
+        options.Find().
+            SetSort(bson.D{{Key: "expires_at", Value: 1}}).
+            SetLimit(int64(limit)).
+            SetProjection(bson.M{"_id": 1}))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var ids []string
+    for cursor.Next(ctx) {
+        var doc struct {
+            ID string `bson:"_id"`
+        }
+        if err := cursor.Decode(&doc); err != nil {
+            return nil, err
+        }
+        ids = append(ids, doc.ID)
+    }
+    return ids, cursor.Err()
+}
+
+// ensureReservationIndexes backs reference lookups and the expiry sweep; only
+// non-empty reference IDs have to be unique
+func (r *MongoProductRepository) ensureReservationIndexes(ctx context.Context) error {
+    _, err := r.reservations().Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {
+            Keys: bson.D{{Key: "reference_id", Value: 1}},
+            Options: options.Index().SetUnique(true).
+                SetPartialFilterExpression(bson.M{"reference_id": bson.M{"$gt": ""}}),
+        },
+        {Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}}},
+    })
+    return err
+}
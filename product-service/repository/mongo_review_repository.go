@@ -0,0 +1,205 @@
+package repository
+
+import (
+    "context"
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/models"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Reviews and helpful votes live in their own collections. The rating and
+// review count of a product are recomputed from its approved reviews after
+// every review write.
+
+func (r *MongoProductRepository) reviews() *mongo.Collection {
+    return r.collection.Database().Collection("reviews")
+}
+
+func (r *MongoProductRepository) reviewVotes() *mongo.Collection {
+    return r.collection.Database().Collection("review_votes")
+}
+
+func (r *MongoProductRepository) CreateReview(ctx context.Context, review *models.Review) error {
+    _, err := r.reviews().InsertOne(ctx, review)
+    if mongo.IsDuplicateKeyError(err) {
+        return ErrDuplicateReview
+    }
+    if err != nil {
+        return err
+    }
+    if review.Status != models.ReviewApproved {
+        return nil
+    }
+    return r.refreshRating(ctx, review.ProductID)
+}
+
+func (r *MongoProductRepository) GetReview(ctx context.Context, id string) (*models.Review, error) {
+    var review models.Review
+    err := r.reviews().FindOne(ctx, bson.M{"_id": id}).Decode(&review)
+    if err != nil {
+        if err == mongo.ErrNoDocuments {
+            return nil, ErrReviewNotFound
+        }
+        return nil, err
+    }
+    return &review, nil
+}
+
+func (r *MongoProductRepository) ListReviews(ctx context.Context, productID string, status models.ReviewStatus, limit, offset int) ([]*models.Review, error) {
+    filter := bson.M{}
+    if productID != "" {
+        filter["product_id"] = productID
+    }
+    if status != "" {
+        filter["status"] = status
+    }
+
+    cursor, err := r.reviews().Find(ctx, filter, options.Find().
+        SetSort(bson.D{{Key: "helpful_votes", Value: -1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: 1}}).
+        SetSkip(int64(offset)).
+        SetLimit(int64(limit)))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var reviews []*models.Review
+    for cursor.Next(ctx) {
+        var review models.Review
+        if err := cursor.Decode(&review); err != nil {
+            return nil, err
+        }
+        reviews = append(reviews, &review)
+    }
+    return reviews, cursor.Err()
+}
+
+func (r *MongoProductRepository) UpdateReview(ctx context.Context, id string, review *models.Review) error {
+    var before models.Review
+    err := r.reviews().FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+        "rating":     review.Rating,
+        "title":      review.Title,
+        "body":       review.Body,
+        "status":     review.Status,
+        "updated_at": review.UpdatedAt,
+    }}, options.FindOneAndUpdate().SetProjection(bson.M{"product_id": 1})).Decode(&before)
+    if err != nil {
+        if err == mongo.ErrNoDocuments {
+            return ErrReviewNotFound
+        }
+        return err
+    }
+    return r.refreshRating(ctx, before.ProductID)
+}
+
+func (r *MongoProductRepository) DeleteReview(ctx context.Context, id string) error {
+    var before models.Review
+    err := r.reviews().FindOneAndDelete(ctx, bson.M{"_id": id},
+        options.FindOneAndDelete().SetProjection(bson.M{"product_id": 1})).Decode(&before)
+    if err != nil {
+        if err == mongo.ErrNoDocuments {
+            return ErrReviewNotFound
+        }
+        return err
+    }
+    if _, err := r.reviewVotes().DeleteMany(ctx, bson.M{"review_id": id}); err != nil {
+        return err
+    }
+    return r.refreshRating(ctx, before.ProductID)
+}
+
+func (r *MongoProductRepository) AddHelpfulVote(ctx context.Context, reviewID, userID string, at time.Time) error {
+    if _, err := r.GetReview(ctx, reviewID); err != nil {
+        return err
+    }
+    _, err := r.reviewVotes().InsertOne(ctx, bson.M{"review_id": reviewID, "user_id": userID, "created_at": at})
+    if mongo.IsDuplicateKeyError(err) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    _, err = r.reviews().UpdateOne(ctx, bson.M{"_id": reviewID}, bson.M{"$inc": bson.M{"helpful_votes": 1}})
+    return err
+}
+
+// refreshRating sets the rating and review count of a product from its
+// approved reviews. The rating typed in by hand before there were reviews is
+// kept aside when the first approved review replaces it, and comes back when
+// a product has no approved reviews any more.
+func (r *MongoProductRepository) refreshRating(ctx context.Context, productID string) error {
+    cursor, err := r.reviews().Aggregate(ctx, mongo.Pipeline{
+        {{Key: "$match", Value: bson.M{"product_id": productID, "status": models.ReviewApproved}}},
+        {{Key: "$group", Value: bson.M{
+            "_id":     nil,
+            "average": bson.M{"$avg": "$rating"},
+            "count":   bson.M{"$sum": 1},
+        }}},
+    })
+    if err != nil {
+        return err
+    }
+    defer cursor.Close(ctx)
+
+    var summary struct {
+        Average float64 `bson:"average"`
+        Count   int     `bson:"count"`
+    }
+    if cursor.Next(ctx) {
+        if err := cursor.Decode(&summary); err != nil {
+            return err
+        }
+    }
+    if err := cursor.Err(); err != nil {
+        return err
+    }
+
+    handTyped := bson.M{"$ifNull": bson.A{"$ratings_before_reviews", "$ratings"}}
+    set := bson.M{"review_count": summary.Count, "ratings": handTyped}
+    if summary.Count > 0 {
+        set = bson.M{"review_count": summary.Count, "ratings": summary.Average, "ratings_before_reviews": handTyped}
+    }
+    _, err = r.collection.UpdateOne(ctx, bson.M{"_id": productID}, mongo.Pipeline{{{Key: "$set", Value: set}}})
+    return err
+}
+
+// deleteProductReviews removes the reviews of a deleted product and their votes
+func (r *MongoProductRepository) deleteProductReviews(ctx context.Context, productID string) error {
+    ids, err := r.reviews().Distinct(ctx, "_id", bson.M{"product_id": productID})
+    if err != nil {
+        return err
+    }
+    if len(ids) == 0 {
+        return nil
+    }
+    if _, err := r.reviewVotes().DeleteMany(ctx, bson.M{"review_id": bson.M{"$in": ids}}); err != nil {
+        return err
+    }
+    _, err = r.reviews().DeleteMany(ctx, bson.M{"product_id": productID})
+    return err
+}
+
+// ensureReviewIndexes creates the indexes that keep one review per user and
+// product and one helpful vote per user and review
+func (r *MongoProductRepository) ensureReviewIndexes(ctx context.Context) error {
+    _, err := r.reviews().Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {
+            Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "user_id", Value: 1}},
+            Options: options.Index().SetUnique(true),
+        },
+        {Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "helpful_votes", Value: -1}, {Key: "created_at", Value: -1}}},
+        {Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+    })
+    if err != nil {
+        return err
+    }
+    _, err = r.reviewVotes().Indexes().CreateOne(ctx, mongo.IndexModel{
+        Keys:    bson.D{{Key: "review_id", Value: 1}, {Key: "user_id", Value: 1}},
+        Options: options.Index().SetUnique(true),
+    })
+    return err
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+    "context"
+
+    "github.com/samObot19/shopverse/product-service/models"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+// Stock checks are keyed by order ID, so the first outcome recorded for an
+// order wins and a later insert fails with a duplicate key that is ignored.
+
+func (r *MongoProductRepository) stockChecks() *mongo.Collection {
+    return r.collection.Database().Collection("order_stock_checks")
+}
+
+func (r *MongoProductRepository) GetStockCheck(ctx context.Context, orderID string) (*models.StockCheck, error) {
+    var check models.StockCheck
+    err := r.stockChecks().FindOne(ctx, bson.M{"_id": orderID}).Decode(&check)
+    if err != nil {
+        if err == mongo.ErrNoDocuments {
+            return nil, ErrStockCheckNotFound
+        }
+        return nil, err
+    }
+    return &check, nil
+}
+
+func (r *MongoProductRepository) RecordStockCheck(ctx context.Context, check *models.StockCheck) error {
+    _, err := r.stockChecks().InsertOne(ctx, check)
+    if mongo.IsDuplicateKeyError(err) {
+        return nil
+    }
+    return err
+}
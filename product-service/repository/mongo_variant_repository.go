@@ -0,0 +1,129 @@
+package repository
+
+import (
+    "context"
+    "errors"
+
+    "github.com/samObot19/shopverse/product-service/models"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Variants are embedded in their product document under "variants", so they
+// are removed together with the product.
+
+// variantsDocument decodes the variants array of a product document
+type variantsDocument struct {
+    ID       string           `bson:"_id"`
+    Variants []models.Variant `bson:"variants"`
+}
+
+func (r *MongoProductRepository) CreateVariant(ctx context.Context, variant *models.Variant) error {
+    result, err := r.collection.UpdateOne(ctx,
+        bson.M{"_id": variant.ProductID},
+        bson.M{"$push": bson.M{"variants": variant}})
+    if err != nil {
+        return err
+    }
+    if result.MatchedCount == 0 {
+        return errors.New("product not found")
+    }
+    if variant.Stock == 0 {
+        return nil
+    }
+    // the initial stock is received into the default warehouse
+    change := models.StockChange{WarehouseID: models.DefaultWarehouseID, Reason: models.StockReceiving, Actor: models.SystemActor}
+    return r.placeStock(ctx, newMovement(variant.ProductID, variant.ID, variant.Stock, change))
+}
+
+func (r *MongoProductRepository) GetVariantByID(ctx context.Context, id string) (*models.Variant, error) {
+    var doc variantsDocument
+    err := r.collection.FindOne(ctx,
+        bson.M{"variants._id": id},
+        options.FindOne().SetProjection(bson.M{"variants.$": 1})).Decode(&doc)
+    if err != nil {
+        if err == mongo.ErrNoDocuments {
+            return nil, ErrVariantNotFound
+        }
+        return nil, err
+    }
+    if len(doc.Variants) == 0 {
+        return nil, ErrVariantNotFound
+    }
+
+    variant := doc.Variants[0]
+    variant.ProductID = doc.ID
+    return &variant, nil
+}
+
+func (r *MongoProductRepository) ListVariants(ctx context.Context, productID string) ([]*models.Variant, error) {
+    var doc variantsDocument
+    err := r.collection.FindOne(ctx,
+        bson.M{"_id": productID},
+        options.FindOne().SetProjection(bson.M{"variants": 1})).Decode(&doc)
+    if err != nil {
+        if err == mongo.ErrNoDocuments {
+            return nil, errors.New("product not found")
+        }
+        return nil, err
+    }
+
+    variants := make([]*models.Variant, 0, len(doc.Variants))
+    for i := range doc.Variants {
+        doc.Variants[i].ProductID = doc.ID
+        variants = append(variants, &doc.Variants[i])
+    }
+    return variants, nil
+}
+
+// UpdateVariant replaces a variant; a changed stock level is applied to the
+// default warehouse and recorded in the ledger as an adjustment
+func (r *MongoProductRepository) UpdateVariant(ctx context.Context, id string, variant *models.Variant) error {
+    var before variantsDocument
+    err := r.collection.FindOneAndUpdate(ctx,
+        bson.M{"variants._id": id},
+        bson.M{"$set": bson.M{
+            "variants.$.sku":     variant.SKU,
+            "variants.$.options": variant.Options,
+            "variants.$.price":   variant.Price,
+            "variants.$.stock":   variant.Stock,
+            "variants.$.images":  variant.Images,
+        }},
+        options.FindOneAndUpdate().SetProjection(bson.M{"variants.$": 1})).Decode(&before)
+    if err != nil {
+        if err == mongo.ErrNoDocuments {
+            return ErrVariantNotFound
+        }
+        return err
+    }
+    if len(before.Variants) == 0 || before.Variants[0].Stock == variant.Stock {
+        return nil
+    }
+    change := models.StockChange{WarehouseID: models.DefaultWarehouseID, Reason: models.StockAdjustment, Actor: models.SystemActor}
+    return r.placeStock(ctx, newMovement(before.ID, id, variant.Stock-before.Variants[0].Stock, change))
+}
+
+func (r *MongoProductRepository) DeleteVariant(ctx context.Context, id string) error {
+    result, err := r.collection.UpdateOne(ctx,
+        bson.M{"variants._id": id},
+        bson.M{"$pull": bson.M{"variants": bson.M{"_id": id}}})
+    if err != nil {
+        return err
+    }
+    if result.MatchedCount == 0 {
+        return ErrVariantNotFound
+    }
+    _, err = r.warehouseStock().DeleteMany(ctx, bson.M{"variant_id": id})
+    return err
+}
+
+// UpdateVariantStock sets the stock quantity of a variant in one warehouse and records the difference
+func (r *MongoProductRepository) UpdateVariantStock(ctx context.Context, id string, quantity int, change models.StockChange) error {
+    variant, err := r.GetVariantByID(ctx, id)
+    if err != nil {
+        return err
+    }
+    return r.setLevel(ctx, variant.ProductID, id, quantity, change)
+}
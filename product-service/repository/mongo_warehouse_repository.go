@@ -0,0 +1,279 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/models"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Warehouses and the stock held in each live in their own collections. The
+// stock fields of products and variants hold the total over all warehouses;
+// a level, the total and the movement recording the change are written in one
+// transaction.
+
+func (r *MongoProductRepository) warehouses() *mongo.Collection {
+    return r.collection.Database().Collection("warehouses")
+}
+
+func (r *MongoProductRepository) warehouseStock() *mongo.Collection {
+    return r.collection.Database().Collection("warehouse_stock")
+}
+
+func (r *MongoProductRepository) CreateWarehouse(ctx context.Context, warehouse *models.Warehouse) error {
+    _, err := r.warehouses().InsertOne(ctx, warehouse)
+    return err
+}
+
+func (r *MongoProductRepository) GetWarehouse(ctx context.Context, id string) (*models.Warehouse, error) {
+    var warehouse models.Warehouse
+    err := r.warehouses().FindOne(ctx, bson.M{"_id": id}).Decode(&warehouse)
+    if err != nil {
+        if err == mongo.ErrNoDocuments {
+            return nil, ErrWarehouseNotFound
+        }
+        return nil, err
+    }
+    return &warehouse, nil
+}
+
+func (r *MongoProductRepository) ListWarehouses(ctx context.Context) ([]*models.Warehouse, error) {
+    cursor, err := r.warehouses().Find(ctx, bson.M{},
+        options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var warehouses []*models.Warehouse
+    for cursor.Next(ctx) {
+        var warehouse models.Warehouse
+        if err := cursor.Decode(&warehouse); err != nil {
+            return nil, err
+        }
+        warehouses = append(warehouses, &warehouse)
+    }
+    return warehouses, cursor.Err()
+}
+
+func (r *MongoProductRepository) UpdateWarehouse(ctx context.Context, id string, warehouse *models.Warehouse) error {
+    result, err := r.warehouses().UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+        "name":      warehouse.Name,
+        "address":   warehouse.Address,
+        "latitude":  warehouse.Latitude,
+        "longitude": warehouse.Longitude,
+        "active":    warehouse.Active,
+    }})
+    if err != nil {
+        return err
+    }
+    if result.MatchedCount == 0 {
+        return ErrWarehouseNotFound
+    }
+    return nil
+}
+
+func (r *MongoProductRepository) StockLevels(ctx context.Context, productIDs []string) ([]models.StockLevel, error) {
+    if len(productIDs) == 0 {
+        return nil, nil
+    }
+
+    cursor, err := r.warehouseStock().Find(ctx,
+        bson.M{"product_id": bson.M{"$in": productIDs}, "quantity": bson.M{"$ne": 0}},
+        options.Find().SetSort(bson.D{{Key: "product_id", Value: 1}, {Key: "variant_id", Value: 1}, {Key: "warehouse_id", Value: 1}}))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var levels []models.StockLevel
+    for cursor.Next(ctx) {
+        var level models.StockLevel
+        if err := cursor.Decode(&level); err != nil {
+            return nil, err
+        }
+        levels = append(levels, level)
+    }
+    return levels, cursor.Err()
+}
+
+func levelFilter(warehouseID, productID, variantID string) bson.M {
+    return bson.M{"warehouse_id": warehouseID, "product_id": productID, "variant_id": variantID}
+}
+
+// changeLevel applies a movement to the warehouse it names. Stock in a
+// warehouse cannot go negative; a larger decrease fails with ErrInsufficientStock.
+func (r *MongoProductRepository) changeLevel(ctx context.Context, movement *models.StockMovement) error {
+    filter := levelFilter(movement.WarehouseID, movement.ProductID, movement.VariantID)
+    update := bson.M{"$inc": bson.M{"quantity": movement.Delta}}
+
+    if movement.Delta < 0 {
+        filter["quantity"] = bson.M{"$gte": -movement.Delta}
+        result, err := r.warehouseStock().UpdateOne(ctx, filter, update)
+        if err != nil {
+            return err
+        }
+        if result.MatchedCount == 0 {
+            if movement.VariantID != "" {
+                return fmt.Errorf("%w for variant %s of product %s in warehouse %s",
+                    ErrInsufficientStock, movement.VariantID, movement.ProductID, movement.WarehouseID)
+            }
+            return fmt.Errorf("%w for product %s in warehouse %s", ErrInsufficientStock, movement.ProductID, movement.WarehouseID)
+        }
+        return nil
+    }
+
+    if _, err := r.GetWarehouse(ctx, movement.WarehouseID); err != nil {
+        if errors.Is(err, ErrWarehouseNotFound) {
+            return fmt.Errorf("%w: %s", ErrWarehouseNotFound, movement.WarehouseID)
+        }
+        return err
+    }
+    _, err := r.warehouseStock().UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+    return err
+}
+
+// changeTotal adds delta to the stock of a product or variant
+func (r *MongoProductRepository) changeTotal(ctx context.Context, productID, variantID string, delta int) error {
+    filter := bson.M{"_id": productID}
+    update := bson.M{"$inc": bson.M{"stock": delta}}
+    if variantID != "" {
+        filter = bson.M{"_id": productID, "variants._id": variantID}
+        update = bson.M{"$inc": bson.M{"variants.$.stock": delta}}
+    }
+
+    result, err := r.collection.UpdateOne(ctx, filter, update)
+    if err != nil {
+        return err
+    }
+    if result.MatchedCount == 0 {
+        if variantID != "" {
+            return ErrVariantNotFound
+        }
+        return errors.New("product not found")
+    }
+    return nil
+}
+
+// moveStock applies a movement to its warehouse and to the product or variant
+// total, then records it
+func (r *MongoProductRepository) moveStock(ctx context.Context, movement *models.StockMovement) error {
+    return r.inTransaction(ctx, func(ctx context.Context) error {
+        if err := r.changeLevel(ctx, movement); err != nil {
+            return err
+        }
+        if err := r.changeTotal(ctx, movement.ProductID, movement.VariantID, movement.Delta); err != nil {
+            return err
+        }
+        return r.recordMovement(ctx, movement)
+    })
+}
+
+// setLevel sets the stock of a product or variant in the warehouse named by
+// change, keeps the total in step and records the difference
+func (r *MongoProductRepository) setLevel(ctx context.Context, productID, variantID string, quantity int, change models.StockChange) error {
+    if _, err := r.GetWarehouse(ctx, change.WarehouseID); err != nil {
+        return err
+    }
+
+    filter := levelFilter(change.WarehouseID, productID, variantID)
+    return r.inTransaction(ctx, func(ctx context.Context) error {
+        var before models.StockLevel
+        err := r.warehouseStock().FindOneAndUpdate(ctx, filter,
+            bson.M{"$set": bson.M{"quantity": quantity}},
+            options.FindOneAndUpdate().SetUpsert(true)).Decode(&before)
+        if err != nil && err != mongo.ErrNoDocuments {
+            return err
+        }
+
+        delta := quantity - before.Quantity
+        if delta == 0 {
+            return nil
+        }
+        if err := r.changeTotal(ctx, productID, variantID, delta); err != nil {
+            return err
+        }
+        return r.recordMovement(ctx, newMovement(productID, variantID, delta, change))
+    })
+}
+
+// placeStock applies a change already made to a product or variant total to
+// its warehouse level and records it. It is called in the transaction that
+// changed the total, so if the level cannot take the change, neither is made.
+func (r *MongoProductRepository) placeStock(ctx context.Context, movement *models.StockMovement) error {
+    return r.inTransaction(ctx, func(ctx context.Context) error {
+        if err := r.changeLevel(ctx, movement); err != nil {
+            return err
+        }
+        return r.recordMovement(ctx, movement)
+    })
+}
+
+// ensureWarehouses creates the warehouse indexes and the default warehouse.
+// When the default warehouse is first created, all stock held so far is
+// placed in it.
+func (r *MongoProductRepository) ensureWarehouses(ctx context.Context) error {
+    _, err := r.warehouseStock().Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {
+            Keys:    bson.D{{Key: "warehouse_id", Value: 1}, {Key: "product_id", Value: 1}, {Key: "variant_id", Value: 1}},
+            Options: options.Index().SetUnique(true),
+        },
+        {Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "variant_id", Value: 1}}},
+    })
+    if err != nil {
+        return err
+    }
+
+    result, err := r.warehouses().UpdateOne(ctx,
+        bson.M{"_id": models.DefaultWarehouseID},
+        bson.M{"$setOnInsert": models.Warehouse{
+            ID:        models.DefaultWarehouseID,
+            Name:      "Default warehouse",
+            Active:    true,
+            CreatedAt: time.Now().UTC(),
+        }},
+        options.Update().SetUpsert(true))
+    if err != nil || result.UpsertedCount == 0 {
+        return err
+    }
+
+    cursor, err := r.collection.Find(ctx, bson.M{},
+        options.Find().SetProjection(bson.M{"stock": 1, "variants._id": 1, "variants.stock": 1}))
+    if err != nil {
+        return err
+    }
+    defer cursor.Close(ctx)
+
+    var levels []interface{}
+    for cursor.Next(ctx) {
+        var product struct {
+            ID       string           `bson:"_id"`
+            Stock    int              `bson:"stock"`
+            Variants []models.Variant `bson:"variants"`
+        }
+        if err := cursor.Decode(&product); err != nil {
+            return err
+        }
+        if product.Stock != 0 {
+            levels = append(levels, models.StockLevel{WarehouseID: models.DefaultWarehouseID, ProductID: product.ID, Quantity: product.Stock})
+        }
+        for _, variant := range product.Variants {
+            if variant.Stock != 0 {
+                levels = append(levels, models.StockLevel{
+                    WarehouseID: models.DefaultWarehouseID, ProductID: product.ID, VariantID: variant.ID, Quantity: variant.Stock,
+                })
+            }
+        }
+    }
+    if err := cursor.Err(); err != nil || len(levels) == 0 {
+        return err
+    }
+    _, err = r.warehouseStock().InsertMany(ctx, levels)
+    return err
+}
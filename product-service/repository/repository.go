@@ -2,17 +2,265 @@ package repository
 
 import (
     "context"
+    "errors"
+    "time"
+
     "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/query"
 )
 
 // ProductRepository defines the interface for product-related database operations
 type ProductRepository interface {
     CreateProduct(ctx context.Context, product *models.Product) error
     GetProductByID(ctx context.Context, id string) (*models.Product, error)
-    GetAllProducts(ctx context.Context, filters map[string]interface{}) ([]*models.Product, error)
-    UpdateProduct(ctx context.Context, id string, updatedProduct *models.Product) error
-    DeleteProduct(ctx context.Context, id string) error
-    UpdateStock(ctx context.Context, id string, quantity int) error
-    GetProductsByCategory(ctx context.Context, category string) ([]*models.Product, error)
-    SearchProducts(ctx context.Context, query string) ([]*models.Product, error)
-}
\ No newline at end of file
+    // GetAllProducts lists the active products matching a filter, and the
+    // archived ones as well when includeArchived is set; prices are filtered
+    // and sorted by their worth in prices
+    GetAllProducts(ctx context.Context, filter *query.Filter, sort []query.Sort, prices *query.Prices, includeArchived bool) ([]*models.Product, error)
+    // UpdateProduct writes the masked fields of a product that is still at
+    // updatedProduct.Version, leaving the others alone, and increments its
+    // version; updatedProduct is the whole product after the update
+    UpdateProduct(ctx context.Context, id string, updatedProduct *models.Product, mask models.ProductMask) error
+    // ArchiveProduct hides a product that is still at the given version from
+    // the catalogue while keeping it for the orders that reference it;
+    // archiving an archived product is a no-op
+    ArchiveProduct(ctx context.Context, id string, at time.Time, version int64) error
+    // RestoreProduct brings an archived product back into the catalogue;
+    // restoring an active product is a no-op
+    RestoreProduct(ctx context.Context, id string) error
+    // ArchivedProducts returns the IDs of up to limit products archived before
+    // the given time, in ID order starting after afterID
+    ArchivedProducts(ctx context.Context, before time.Time, afterID string, limit int) ([]string, error)
+    // PurgeProduct permanently deletes an archived product with its variants,
+    // stock, reviews and prices and reports whether it did; an active product
+    // is left alone
+    PurgeProduct(ctx context.Context, id string) (bool, error)
+    // UpdateStock sets the stock of a product that is still at the given
+    // version in one warehouse and increments its version
+    UpdateStock(ctx context.Context, id string, quantity int, change models.StockChange, version int64) error
+    // GetProductsByCategory lists the active products of a category
+    GetProductsByCategory(ctx context.Context, category string, sort []query.Sort, prices *query.Prices) ([]*models.Product, error)
+    // GetProductsByCategoryIDs loads the active products filed under any of the given categories
+    GetProductsByCategoryIDs(ctx context.Context, categoryIDs []string, sort []query.Sort, prices *query.Prices) ([]*models.Product, error)
+    // GetProductsByIDs loads the given products, archived ones included
+    GetProductsByIDs(ctx context.Context, ids []string) ([]*models.Product, error)
+    // GetProductsBySKUs loads the products with any of the given SKUs; unknown SKUs are skipped
+    GetProductsBySKUs(ctx context.Context, skus []string) ([]*models.Product, error)
+    // ImportProducts creates and replaces a batch of products, including their
+    // attributes, sizes and images, in one transaction where the store has them.
+    // Replaced products keep their rating and creation time, and must still be
+    // at their Version.
+    ImportProducts(ctx context.Context, created, replaced []*models.Product) error
+}
+
+// ErrProductNotFound is returned when no product has the requested ID
+var ErrProductNotFound = errors.New("product not found")
+
+// ErrVersionConflict is returned when a product was changed after the version
+// an update was based on
+var ErrVersionConflict = errors.New("product was changed since it was read")
+
+// ErrDuplicateSKU is returned when a product write would give two products the same SKU
+var ErrDuplicateSKU = errors.New("another product already has this SKU")
+
+// ErrVariantNotFound is returned when no variant has the requested ID
+var ErrVariantNotFound = errors.New("variant not found")
+
+// VariantRepository defines the interface for product variant persistence
+type VariantRepository interface {
+    CreateVariant(ctx context.Context, variant *models.Variant) error
+    GetVariantByID(ctx context.Context, id string) (*models.Variant, error)
+    ListVariants(ctx context.Context, productID string) ([]*models.Variant, error)
+    UpdateVariant(ctx context.Context, id string, variant *models.Variant) error
+    DeleteVariant(ctx context.Context, id string) error
+    UpdateVariantStock(ctx context.Context, id string, quantity int, change models.StockChange) error
+}
+
+// Reservation errors
+var (
+    ErrInsufficientStock    = errors.New("insufficient stock")
+    ErrReservationNotFound  = errors.New("reservation not found")
+    ErrReservationClosed    = errors.New("reservation is no longer pending")
+    ErrDuplicateReservation = errors.New("a reservation with this reference already exists")
+)
+
+// ReservationRepository holds and returns stock for checkouts. Implementations
+// must take the stock of every item or none of it.
+type ReservationRepository interface {
+    // ReserveStock takes the stock of every item and records the reservation. It
+    // fails with ErrInsufficientStock if any item lacks stock, and with
+    // ErrDuplicateReservation if the reference ID is already in use.
+    ReserveStock(ctx context.Context, reservation *models.Reservation) error
+    GetReservation(ctx context.Context, id string) (*models.Reservation, error)
+    GetReservationByReference(ctx context.Context, referenceID string) (*models.Reservation, error)
+    // CommitReservation marks a pending, unexpired reservation as sold
+    CommitReservation(ctx context.Context, id string, now time.Time) (*models.Reservation, error)
+    // ReleaseReservation returns the stock of a pending reservation and moves it
+    // to status, which is either released or expired
+    ReleaseReservation(ctx context.Context, id string, status models.ReservationStatus) (*models.Reservation, error)
+    // ExpiredReservations lists pending reservations whose TTL has elapsed
+    ExpiredReservations(ctx context.Context, now time.Time, limit int) ([]string, error)
+}
+
+// ErrStockCheckNotFound is returned when an order has not been checked yet
+var ErrStockCheckNotFound = errors.New("stock check not found")
+
+// StockCheckRepository records the outcome of checking the stock of an order,
+// so an order redelivered by the message broker gets the same answer
+type StockCheckRepository interface {
+    GetStockCheck(ctx context.Context, orderID string) (*models.StockCheck, error)
+    // RecordStockCheck stores the outcome of a check unless the order already
+    // has one, which is kept
+    RecordStockCheck(ctx context.Context, check *models.StockCheck) error
+}
+
+// StockLedgerRepository reads and extends the append-only stock ledger.
+// Implementations also record a movement for every other write that changes a
+// stock level, including product and variant creation, updates and reservations.
+type StockLedgerRepository interface {
+    // AdjustStock applies movement.Delta to the stock of a product or variant
+    // and records the movement; it fails with ErrInsufficientStock rather than
+    // leave the stock negative
+    AdjustStock(ctx context.Context, movement *models.StockMovement) error
+    // GetStockHistory pages through the movements of a product, newest first.
+    // A non-empty variantID narrows them to one variant, and before is the ID
+    // of the last movement of the previous page.
+    GetStockHistory(ctx context.Context, productID, variantID, before string, limit int) ([]*models.StockMovement, error)
+    // StockDiscrepancies lists products and variants whose stock differs from
+    // the sum of their movements
+    StockDiscrepancies(ctx context.Context) ([]models.StockDiscrepancy, error)
+}
+
+// ErrWarehouseNotFound is returned when no warehouse has the requested ID
+var ErrWarehouseNotFound = errors.New("warehouse not found")
+
+// WarehouseRepository defines the interface for warehouse persistence. Stock
+// is moved between warehouses through the stock writes of the other repositories,
+// each of which names the warehouse in its models.StockChange.
+type WarehouseRepository interface {
+    CreateWarehouse(ctx context.Context, warehouse *models.Warehouse) error
+    GetWarehouse(ctx context.Context, id string) (*models.Warehouse, error)
+    ListWarehouses(ctx context.Context) ([]*models.Warehouse, error)
+    UpdateWarehouse(ctx context.Context, id string, warehouse *models.Warehouse) error
+    // StockLevels lists the non-zero stock levels of the given products, and
+    // of their variants, in every warehouse
+    StockLevels(ctx context.Context, productIDs []string) ([]models.StockLevel, error)
+}
+
+// Category errors
+var (
+    ErrCategoryNotFound  = errors.New("category not found")
+    ErrDuplicateCategory = errors.New("a sibling category already has this slug")
+    ErrCategoryInUse     = errors.New("category still has subcategories or products")
+)
+
+// CategoryRepository defines the interface for category tree persistence
+type CategoryRepository interface {
+    // CreateCategory fails with ErrDuplicateCategory if a sibling has the same slug
+    CreateCategory(ctx context.Context, category *models.Category) error
+    GetCategory(ctx context.Context, id string) (*models.Category, error)
+    // ListCategories returns every category, ordered by parent, position and name
+    ListCategories(ctx context.Context) ([]*models.Category, error)
+    // UpdateCategory replaces a category and copies its name to the products filed under it
+    UpdateCategory(ctx context.Context, id string, category *models.Category) error
+    // DeleteCategory fails with ErrCategoryInUse while the category has
+    // subcategories or products
+    DeleteCategory(ctx context.Context, id string) error
+}
+
+// Review errors
+var (
+    ErrReviewNotFound  = errors.New("review not found")
+    ErrDuplicateReview = errors.New("this user has already reviewed the product")
+)
+
+// ReviewRepository defines the interface for product review persistence.
+// Every write that adds, changes or removes an approved review also updates
+// the rating and review count of its product in the same step.
+type ReviewRepository interface {
+    // CreateReview fails with ErrDuplicateReview if the user has already
+    // reviewed the product
+    CreateReview(ctx context.Context, review *models.Review) error
+    GetReview(ctx context.Context, id string) (*models.Review, error)
+    // ListReviews pages through reviews, most helpful and then newest first.
+    // An empty productID lists the reviews of every product and an empty
+    // status those in any state.
+    ListReviews(ctx context.Context, productID string, status models.ReviewStatus, limit, offset int) ([]*models.Review, error)
+    // UpdateReview replaces the rating, text and status of a review
+    UpdateReview(ctx context.Context, id string, review *models.Review) error
+    DeleteReview(ctx context.Context, id string) error
+    // AddHelpfulVote counts a user's vote for a review once; voting again
+    // changes nothing
+    AddHelpfulVote(ctx context.Context, reviewID, userID string, at time.Time) error
+}
+
+// ErrPriceListNotFound is returned when no price list has the requested ID
+var ErrPriceListNotFound = errors.New("price list not found")
+
+// PriceListRepository defines the interface for price list and price history
+// persistence
+type PriceListRepository interface {
+    CreatePriceList(ctx context.Context, list *models.PriceList) error
+    GetPriceList(ctx context.Context, id string) (*models.PriceList, error)
+    // ListPriceLists returns every price list, latest start first
+    ListPriceLists(ctx context.Context) ([]*models.PriceList, error)
+    // UpdatePriceList replaces the name, period and entries of a price list
+    UpdatePriceList(ctx context.Context, id string, list *models.PriceList) error
+    DeletePriceList(ctx context.Context, id string) error
+    // ActivePriceLists returns the price lists in effect at the given time,
+    // each narrowed to its entries for the given products
+    ActivePriceLists(ctx context.Context, productIDs []string, at time.Time) ([]*models.PriceList, error)
+    // RecordPriceChanges appends changes to the price history and sets their IDs
+    RecordPriceChanges(ctx context.Context, changes []*models.PriceChange) error
+    // GetPriceHistory pages through the price changes of a product, newest
+    // first; before is the ID of the last change of the previous page
+    GetPriceHistory(ctx context.Context, productID, before string, limit int) ([]*models.PriceChange, error)
+}
+
+// ErrExchangeRateNotFound is returned when there is no rate for a currency
+var ErrExchangeRateNotFound = errors.New("exchange rate not found")
+
+// ExchangeRateRepository defines the interface for the exchange rate table
+type ExchangeRateRepository interface {
+    // ListExchangeRates returns the rate of every currency, by currency
+    ListExchangeRates(ctx context.Context) ([]*models.ExchangeRate, error)
+    // SetExchangeRate adds the rate of a currency or replaces the one it has
+    SetExchangeRate(ctx context.Context, rate *models.ExchangeRate) error
+    DeleteExchangeRate(ctx context.Context, currency string) error
+}
+
+// ErrImageNotFound is returned when no product image has the requested ID
+var ErrImageNotFound = errors.New("image not found")
+
+// ProductImageRepository defines the interface for the images uploaded for
+// products; the files of their renditions are kept in a media.BlobStore
+type ProductImageRepository interface {
+    // CreateProductImage places the image after the other images of its
+    // product and sets its position. It fails with ErrProductNotFound if the
+    // product does not exist.
+    CreateProductImage(ctx context.Context, image *models.ProductImage) error
+    GetProductImage(ctx context.Context, id string) (*models.ProductImage, error)
+    // ListProductImages returns the images of a product by position, oldest
+    // first among those at the same position
+    ListProductImages(ctx context.Context, productID string) ([]*models.ProductImage, error)
+    // UpdateProductImage replaces the alt text and position of an image
+    UpdateProductImage(ctx context.Context, id, altText string, position int) error
+    DeleteProductImage(ctx context.Context, id string) error
+}
+
+// OutboxRepository reads the outbox that product and stock writes add their
+// domain events to. Where the store has transactions an event is written
+// in the same transaction as its change.
+type OutboxRepository interface {
+    // ClaimEvents claims up to limit of the oldest events waiting to be
+    // published for claimant until lease after now, and returns them in the
+    // order they were written. Claims are taken from the head of the outbox
+    // and stop at the first event another claimant holds, so while one relay
+    // publishes a batch the others claim nothing and events keep their order.
+    // Events claimant already holds, and expired claims, are claimed again.
+    ClaimEvents(ctx context.Context, claimant string, now time.Time, lease time.Duration, limit int) ([]*models.OutboxEvent, error)
+    // DeleteEvents removes published events from the outbox
+    DeleteEvents(ctx context.Context, ids []string) error
+    // RecordEventFailure counts a failed attempt to publish an event
+    RecordEventFailure(ctx context.Context, id string, reason string) error
+}
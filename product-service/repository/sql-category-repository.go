@@ -0,0 +1,127 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+
+    "github.com/go-sql-driver/mysql"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+func (r *MySQLProductRepository) CreateCategory(ctx context.Context, category *models.Category) error {
+    _, err := r.DB.ExecContext(ctx, `
+        INSERT INTO categories (id, parent_id, name, slug, position, created_at)
+        VALUES (?, ?, ?, ?, ?, ?)`,
+        category.ID, category.ParentID, category.Name, category.Slug, category.Position, category.CreatedAt,
+    )
+    return categoryWriteError(err)
+}
+
+func (r *MySQLProductRepository) GetCategory(ctx context.Context, id string) (*models.Category, error) {
+    var category models.Category
+    err := r.DB.QueryRowContext(ctx, `
+        SELECT id, parent_id, name, slug, position, created_at
+        FROM categories WHERE id = ?`, id).
+        Scan(&category.ID, &category.ParentID, &category.Name, &category.Slug, &category.Position, &category.CreatedAt)
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil, ErrCategoryNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &category, nil
+}
+
+func (r *MySQLProductRepository) ListCategories(ctx context.Context) ([]*models.Category, error) {
+    rows, err := r.DB.QueryContext(ctx, `
+        SELECT id, parent_id, name, slug, position, created_at
+        FROM categories ORDER BY parent_id, position, name`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var categories []*models.Category
+    for rows.Next() {
+        var category models.Category
+        if err := rows.Scan(&category.ID, &category.ParentID, &category.Name, &category.Slug,
+            &category.Position, &category.CreatedAt); err != nil {
+            return nil, err
+        }
+        categories = append(categories, &category)
+    }
+    return categories, rows.Err()
+}
+
+func (r *MySQLProductRepository) UpdateCategory(ctx context.Context, id string, category *models.Category) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    result, err := tx.ExecContext(ctx, `
+        UPDATE categories SET parent_id = ?, name = ?, slug = ?, position = ?
+        WHERE id = ?`,
+        category.ParentID, category.Name, category.Slug, category.Position, id,
+    )
+    if err != nil {
+        return categoryWriteError(err)
+    }
+    // MySQL reports no affected rows for an update that changes nothing, so
+    // only a missing row is an error
+    if n, err := result.RowsAffected(); err == nil && n == 0 {
+        var exists int
+        err := tx.QueryRowContext(ctx, `SELECT 1 FROM categories WHERE id = ?`, id).Scan(&exists)
+        if errors.Is(err, sql.ErrNoRows) {
+            return ErrCategoryNotFound
+        }
+        if err != nil {
+            return err
+        }
+    }
+
+    _, err = tx.ExecContext(ctx, `UPDATE products SET category = ? WHERE category_id = ?`, category.Name, id)
+    if err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func (r *MySQLProductRepository) DeleteCategory(ctx context.Context, id string) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var inUse bool
+    err = tx.QueryRowContext(ctx, `
+        SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = ?)
+            OR EXISTS (SELECT 1 FROM products WHERE category_id = ?)`, id, id).Scan(&inUse)
+    if err != nil {
+        return err
+    }
+    if inUse {
+        return ErrCategoryInUse
+    }
+
+    result, err := tx.ExecContext(ctx, `DELETE FROM categories WHERE id = ?`, id)
+    if err != nil {
+        return err
+    }
+    if n, err := result.RowsAffected(); err == nil && n == 0 {
+        return ErrCategoryNotFound
+    }
+    return tx.Commit()
+}
+
+// categoryWriteError maps a slug clash between siblings to ErrDuplicateCategory
+func categoryWriteError(err error) error {
+    var mysqlErr *mysql.MySQLError
+    if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+        return ErrDuplicateCategory
+    }
+    return err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "testing"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+func TestDeleteCategory(t *testing.T) {
+    tests := []struct {
+        name    string
+        inUse   bool
+        deleted int64
+        wantErr error
+    }{
+        {"unused", false, 1, nil},
+        {"has subcategories or products", true, 0, ErrCategoryInUse},
+        {"missing", false, 0, ErrCategoryNotFound},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            repo, mock := newMockRepository(t)
+            mock.ExpectBegin()
+            mock.ExpectQuery("SELECT EXISTS").WithArgs("c-1", "c-1").
+                WillReturnRows(sqlmock.NewRows([]string{"in_use"}).AddRow(tt.inUse))
+            if !tt.inUse {
+                mock.ExpectExec("DELETE FROM categories WHERE id = \\?").WithArgs("c-1").
+                    WillReturnResult(sqlmock.NewResult(0, tt.deleted))
+            }
+            if tt.wantErr == nil {
+                mock.ExpectCommit()
+            } else {
+                mock.ExpectRollback()
+            }
+
+            err := repo.DeleteCategory(context.Background(), "c-1")
+            if !errors.Is(err, tt.wantErr) {
+                t.Fatalf("DeleteCategory() error = %v, want %v", err, tt.wantErr)
+            }
+            if err := mock.ExpectationsWereMet(); err != nil {
+                t.Errorf("unexpected queries: %v", err)
+            }
+        })
+    }
+}
+
+func TestUpdateCategoryRenamesProducts(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    mock.ExpectBegin()
+    mock.ExpectExec("UPDATE categories SET").WithArgs("", "Shirts", "shirts", 2, "c-1").
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("UPDATE products SET category = \\? WHERE category_id = \\?").WithArgs("Shirts", "c-1").
+        WillReturnResult(sqlmock.NewResult(0, 12))
+    mock.ExpectCommit()
+
+    category := &models.Category{Name: "Shirts", Slug: "shirts", Position: 2}
+    if err := repo.UpdateCategory(context.Background(), "c-1", category); err != nil {
+        t.Fatalf("UpdateCategory() error = %v", err)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+    "context"
+
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+)
+
+func (r *MySQLProductRepository) ListExchangeRates(ctx context.Context) ([]*models.ExchangeRate, error) {
+    rows, err := r.DB.QueryContext(ctx, `
+        SELECT currency, rate, rounding_mode, rounding_increment, updated_at
+        FROM exchange_rates ORDER BY currency`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var rates []*models.ExchangeRate
+    for rows.Next() {
+        var rate models.ExchangeRate
+        var mode string
+        if err := rows.Scan(&rate.Currency, &rate.Rate, &mode, &rate.Rounding.Increment, &rate.UpdatedAt); err != nil {
+            return nil, err
+        }
+        // DECIMAL columns come back padded with zeros, such as 0.9200000000
+        parsed, err := money.ParseRate(rate.Rate)
+        if err != nil {
+            return nil, err
+        }
+        rate.Rate = money.FormatRate(parsed)
+        rate.Rounding.Mode = money.RoundingMode(mode)
+        rates = append(rates, &rate)
+    }
+    return rates, rows.Err()
+}
+
+func (r *MySQLProductRepository) SetExchangeRate(ctx context.Context, rate *models.ExchangeRate) error {
+    _, err := r.DB.ExecContext(ctx, `
+        INSERT INTO exchange_rates (currency, rate, rounding_mode, rounding_increment, updated_at)
+        VALUES (?, ?, ?, ?, ?)
+        ON DUPLICATE KEY UPDATE rate = VALUES(rate), rounding_mode = VALUES(rounding_mode),
+            rounding_increment = VALUES(rounding_increment), updated_at = VALUES(updated_at)`,
+        rate.Currency, rate.Rate, string(rate.Rounding.Mode), rate.Rounding.Increment, rate.UpdatedAt,
+    )
+    return err
+}
+
+func (r *MySQLProductRepository) DeleteExchangeRate(ctx context.Context, currency string) error {
+    result, err := r.DB.ExecContext(ctx, `DELETE FROM exchange_rates WHERE currency = ?`, currency)
+    if err != nil {
+        return err
+    }
+    if n, err := result.RowsAffected(); err == nil && n == 0 {
+        return ErrExchangeRateNotFound
+    }
+    return nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+
+    "github.com/samObot19/shopverse/product-service/money"
+)
+
+func TestListExchangeRatesTrimsRates(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    now := time.Unix(0, 0)
+    mock.ExpectQuery("FROM exchange_rates ORDER BY currency").
+        WillReturnRows(sqlmock.NewRows([]string{"currency", "rate", "rounding_mode", "rounding_increment", "updated_at"}).
+            AddRow("EUR", "0.9200000000", "nearest", 1, now).
+            AddRow("GBP", "0.7900000000", "up", 100, now))
+
+    rates, err := repo.ListExchangeRates(context.Background())
+    if err != nil {
+        t.Fatalf("ListExchangeRates() error = %v", err)
+    }
+    if len(rates) != 2 {
+        t.Fatalf("ListExchangeRates() returned %d rates, want 2", len(rates))
+    }
+    if rates[0].Rate != "0.92" || rates[0].Rounding != money.DefaultRounding {
+        t.Errorf("EUR rate = %+v, want 0.92 rounded to the nearest cent", rates[0])
+    }
+    if rates[1].Rounding != (money.Rounding{Mode: money.RoundUp, Increment: 100}) {
+        t.Errorf("GBP rounding = %+v, want up to whole pounds", rates[1].Rounding)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
+
+func TestDeleteMissingExchangeRate(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    mock.ExpectExec("DELETE FROM exchange_rates WHERE currency = \\?").
+        WithArgs("JPY").
+        WillReturnResult(sqlmock.NewResult(0, 0))
+
+    if err := repo.DeleteExchangeRate(context.Background(), "JPY"); !errors.Is(err, ErrExchangeRateNotFound) {
+        t.Errorf("DeleteExchangeRate() error = %v, want ErrExchangeRateNotFound", err)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "strings"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// CreateProductImage locks the product row, so concurrent uploads of a
+// product take their positions one after the other
+func (r *MySQLProductRepository) CreateProductImage(ctx context.Context, image *models.ProductImage) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var exists int
+    err = tx.QueryRowContext(ctx, `SELECT 1 FROM products WHERE id = ? FOR UPDATE`, image.ProductID).Scan(&exists)
+    if errors.Is(err, sql.ErrNoRows) {
+        return ErrProductNotFound
+    }
+    if err != nil {
+        return err
+    }
+
+    _, err = tx.ExecContext(ctx, `
+        INSERT INTO product_media (id, product_id, alt_text, width, height, position, created_at)
+        SELECT ?, ?, ?, ?, ?, COALESCE(MAX(position) + 1, 0), ?
+        FROM product_media WHERE product_id = ?`,
+        image.ID, image.ProductID, image.AltText, image.Width, image.Height, image.CreatedAt, image.ProductID,
+    )
+    if err != nil {
+        return err
+    }
+    err = tx.QueryRowContext(ctx, `SELECT position FROM product_media WHERE id = ?`, image.ID).Scan(&image.Position)
+    if err != nil {
+        return err
+    }
+
+    for _, rendition := range image.Renditions {
+        _, err := tx.ExecContext(ctx, `
+            INSERT INTO product_media_renditions (media_id, size, format, width, height, bytes, blob_key)
+            VALUES (?, ?, ?, ?, ?, ?, ?)`,
+            image.ID, rendition.Size, rendition.Format, rendition.Width, rendition.Height, rendition.Bytes, rendition.Key,
+        )
+        if err != nil {
+            return err
+        }
+    }
+    return tx.Commit()
+}
+
+func (r *MySQLProductRepository) GetProductImage(ctx context.Context, id string) (*models.ProductImage, error) {
+    images, err := r.listProductImages(ctx, "id = ?", id)
+    if err != nil {
+        return nil, err
+    }
+    if len(images) == 0 {
+        return nil, ErrImageNotFound
+    }
+    return images[0], nil
+}
+
+func (r *MySQLProductRepository) ListProductImages(ctx context.Context, productID string) ([]*models.ProductImage, error) {
+    return r.listProductImages(ctx, "product_id = ? ORDER BY position, created_at, id", productID)
+}
+
+func (r *MySQLProductRepository) UpdateProductImage(ctx context.Context, id, altText string, position int) error {
+    result, err := r.DB.ExecContext(ctx, `
+        UPDATE product_media SET alt_text = ?, position = ? WHERE id = ?`, altText, position, id)
+    if err != nil {
+        return err
+    }
+    // MySQL reports no affected rows for an update that changes nothing, so
+    // only a missing row is an error
+    if n, err := result.RowsAffected(); err == nil && n == 0 {
+        if _, err := r.GetProductImage(ctx, id); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// DeleteProductImage removes the rows of an image; its renditions go with it
+func (r *MySQLProductRepository) DeleteProductImage(ctx context.Context, id string) error {
+    result, err := r.DB.ExecContext(ctx, `DELETE FROM product_media WHERE id = ?`, id)
+    if err != nil {
+        return err
+    }
+    if n, err := result.RowsAffected(); err == nil && n == 0 {
+        return ErrImageNotFound
+    }
+    return nil
+}
+
+// listProductImages loads the images matching a WHERE clause, then their
+// renditions with one grouped query
+func (r *MySQLProductRepository) listProductImages(ctx context.Context, where string, args ...interface{}) ([]*models.ProductImage, error) {
+    rows, err := r.DB.QueryContext(ctx, `
+        SELECT id, product_id, alt_text, width, height, position, created_at
+        FROM product_media WHERE `+where, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var images []*models.ProductImage
+    byID := make(map[string]*models.ProductImage)
+    for rows.Next() {
+        var image models.ProductImage
+        if err := rows.Scan(&image.ID, &image.ProductID, &image.AltText, &image.Width, &image.Height,
+            &image.Position, &image.CreatedAt); err != nil {
+            return nil, err
+        }
+        images = append(images, &image)
+        byID[image.ID] = &image
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+    if len(images) == 0 {
+        return nil, nil
+    }
+
+    ids := make([]interface{}, 0, len(images))
+    for _, image := range images {
+        ids = append(ids, image.ID)
+    }
+    in := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ") + ")"
+
+    // smallest first, so a client can take the first rendition that is big enough
+    renditionRows, err := r.DB.QueryContext(ctx, `
+        SELECT media_id, size, format, width, height, bytes, blob_key
+        FROM product_media_renditions WHERE media_id IN `+in+`
+        ORDER BY width * height, format`, ids...)
+    if err != nil {
+        return nil, err
+    }
+    defer renditionRows.Close()
+    for renditionRows.Next() {
+        var imageID string
+        var rendition models.ImageRendition
+        if err := renditionRows.Scan(&imageID, &rendition.Size, &rendition.Format, &rendition.Width, &rendition.Height,
+            &rendition.Bytes, &rendition.Key); err != nil {
+            return nil, err
+        }
+        if image, ok := byID[imageID]; ok {
+            image.Renditions = append(image.Renditions, rendition)
+        }
+    }
+    return images, renditionRows.Err()
+}
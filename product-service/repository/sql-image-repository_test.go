@@ -0,0 +1,99 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+func TestCreateProductImageWritesRenditions(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    now := time.Unix(0, 0)
+    image := &models.ProductImage{
+        ID: "i-1", ProductID: "p-1", AltText: "Front", Width: 1200, Height: 800, CreatedAt: now,
+        Renditions: []models.ImageRendition{
+            {Size: "thumbnail", Format: "webp", Width: 160, Height: 107, Bytes: 900, Key: "images/i-1/thumbnail.webp"},
+            {Size: "thumbnail", Format: "jpeg", Width: 160, Height: 107, Bytes: 1200, Key: "images/i-1/thumbnail.jpg"},
+        },
+    }
+
+    mock.ExpectBegin()
+    mock.ExpectQuery("SELECT 1 FROM products WHERE id = \\? FOR UPDATE").WithArgs("p-1").
+        WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+    mock.ExpectExec("INSERT INTO product_media .* COALESCE\\(MAX\\(position\\) \\+ 1, 0\\)").
+        WithArgs("i-1", "p-1", "Front", 1200, 800, now, "p-1").
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectQuery("SELECT position FROM product_media WHERE id = \\?").WithArgs("i-1").
+        WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(3))
+    for _, r := range image.Renditions {
+        mock.ExpectExec("INSERT INTO product_media_renditions").
+            WithArgs("i-1", r.Size, r.Format, r.Width, r.Height, r.Bytes, r.Key).
+            WillReturnResult(sqlmock.NewResult(0, 1))
+    }
+    mock.ExpectCommit()
+
+    if err := repo.CreateProductImage(context.Background(), image); err != nil {
+        t.Fatalf("CreateProductImage() error = %v", err)
+    }
+    if image.Position != 3 {
+        t.Errorf("CreateProductImage() position = %d, want 3", image.Position)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
+
+func TestCreateProductImageOfMissingProduct(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    mock.ExpectBegin()
+    mock.ExpectQuery("SELECT 1 FROM products WHERE id = \\? FOR UPDATE").WithArgs("missing").
+        WillReturnError(sql.ErrNoRows)
+    mock.ExpectRollback()
+
+    err := repo.CreateProductImage(context.Background(), &models.ProductImage{ID: "i-1", ProductID: "missing"})
+    if !errors.Is(err, ErrProductNotFound) {
+        t.Errorf("CreateProductImage() error = %v, want ErrProductNotFound", err)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
+
+func TestListProductImagesLoadsRenditions(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    now := time.Unix(0, 0)
+    mock.ExpectQuery("FROM product_media WHERE product_id = \\? ORDER BY position").
+        WithArgs("p-1").
+        WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "alt_text", "width", "height", "position", "created_at"}).
+            AddRow("i-1", "p-1", "Front", 1200, 800, 0, now).
+            AddRow("i-2", "p-1", "Back", 800, 800, 1, now))
+    mock.ExpectQuery("FROM product_media_renditions WHERE media_id IN \\(\\?, \\?\\)").
+        WithArgs("i-1", "i-2").
+        WillReturnRows(sqlmock.NewRows([]string{"media_id", "size", "format", "width", "height", "bytes", "blob_key"}).
+            AddRow("i-2", "thumbnail", "jpeg", 160, 160, 1000, "images/i-2/thumbnail.jpg").
+            AddRow("i-1", "thumbnail", "jpeg", 160, 107, 1000, "images/i-1/thumbnail.jpg").
+            AddRow("i-1", "large", "jpeg", 1200, 800, 90000, "images/i-1/large.jpg"))
+
+    images, err := repo.ListProductImages(context.Background(), "p-1")
+    if err != nil {
+        t.Fatalf("ListProductImages() error = %v", err)
+    }
+    if len(images) != 2 || images[0].ID != "i-1" || images[1].ID != "i-2" {
+        t.Fatalf("ListProductImages() = %+v, want i-1 and i-2", images)
+    }
+    if n := len(images[0].Renditions); n != 2 || images[0].Renditions[1].Size != "large" {
+        t.Errorf("i-1 renditions = %+v, want thumbnail then large", images[0].Renditions)
+    }
+    if n := len(images[1].Renditions); n != 1 {
+        t.Errorf("i-2 has %d renditions, want 1", n)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
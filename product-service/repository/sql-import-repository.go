@@ -0,0 +1,60 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "strings"
+
+    "github.com/go-sql-driver/mysql"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// GetProductsBySKUs loads the products with any of the given SKUs; unknown SKUs are skipped
+func (r *MySQLProductRepository) GetProductsBySKUs(ctx context.Context, skus []string) ([]*models.Product, error) {
+    if len(skus) == 0 {
+        return nil, nil
+    }
+
+    args := make([]interface{}, len(skus))
+    for i, sku := range skus {
+        args[i] = sku
+    }
+    return r.listProducts(ctx, "sku IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(skus)), ", ")+")", args...)
+}
+
+func (r *MySQLProductRepository) ImportProducts(ctx context.Context, created, replaced []*models.Product) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    for _, product := range created {
+        if err := insertProduct(ctx, tx, product); err != nil {
+            return err
+        }
+    }
+    for _, product := range replaced {
+        if err := updateProduct(ctx, tx, product.ID, product, models.FullProductMask()); err != nil {
+            return err
+        }
+    }
+    return tx.Commit()
+}
+
+// nullSKU stores an empty SKU as NULL, which the unique key does not compare
+func nullSKU(sku string) interface{} {
+    if sku == "" {
+        return nil
+    }
+    return sku
+}
+
+// productWriteError maps a SKU clash to ErrDuplicateSKU
+func productWriteError(err error) error {
+    var mysqlErr *mysql.MySQLError
+    if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+        return ErrDuplicateSKU
+    }
+    return err
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "github.com/go-sql-driver/mysql"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+)
+
+func TestImportProductsCreates(t *testing.T) {
+    tests := []struct {
+        name      string
+        insertErr error
+        wantErr   error
+    }{
+        {"new product", nil, nil},
+        {"duplicate SKU", &mysql.MySQLError{Number: mysqlDuplicateEntry}, ErrDuplicateSKU},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            repo, mock := newMockRepository(t)
+            now := time.Unix(0, 0)
+            product := &models.Product{
+                ID: "p-1", SKU: "TS-1", Title: "Tee", Price: money.New(999, "USD"), Category: "shirts", CreatedAt: now,
+                Attributes: models.ProductAttributes{Color: "red", Size: []string{"S"}},
+                Images:     []string{"a.png"},
+            }
+
+            mock.ExpectBegin()
+            insert := mock.ExpectExec("INSERT INTO products").
+                WithArgs("p-1", "TS-1", "Tee", "", "9.99", "USD", "shirts", "", now)
+            if tt.insertErr != nil {
+                insert.WillReturnError(tt.insertErr)
+                mock.ExpectRollback()
+            } else {
+                insert.WillReturnResult(sqlmock.NewResult(0, 1))
+                mock.ExpectExec("INSERT INTO product_attributes").WithArgs("p-1", "red").WillReturnResult(sqlmock.NewResult(0, 1))
+                mock.ExpectExec("INSERT INTO product_sizes").WithArgs("p-1", "S").WillReturnResult(sqlmock.NewResult(0, 1))
+                mock.ExpectExec("INSERT INTO product_images").WithArgs("p-1", "a.png").WillReturnResult(sqlmock.NewResult(0, 1))
+                expectEvent(mock, "p-1", models.EventProductCreated)
+                mock.ExpectCommit()
+            }
+
+            err := repo.ImportProducts(context.Background(), []*models.Product{product}, nil)
+            if !errors.Is(err, tt.wantErr) {
+                t.Fatalf("ImportProducts() error = %v, want %v", err, tt.wantErr)
+            }
+            if err := mock.ExpectationsWereMet(); err != nil {
+                t.Errorf("unexpected queries: %v", err)
+            }
+        })
+    }
+}
@@ -0,0 +1,281 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "strconv"
+    "time"
+
+    "github.com/go-sql-driver/mysql"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// Stock is held per warehouse in warehouse_stock, and the stock columns of
+// products and variants hold the total over all warehouses. Every change goes
+// through moveStock, which updates both and appends the stock_movements row in
+// the same transaction, so neither the totals nor the ledger can drift.
+
+// recordMovement appends a movement to the ledger, sets its ID and raises its
+// StockChanged event
+func recordMovement(ctx context.Context, tx *sql.Tx, movement *models.StockMovement) error {
+    if movement.CreatedAt.IsZero() {
+        movement.CreatedAt = time.Now().UTC()
+    }
+    result, err := tx.ExecContext(ctx, `
+        INSERT INTO stock_movements (product_id, variant_id, warehouse_id, delta, reason, actor, reference_id, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+        movement.ProductID, movement.VariantID, movement.WarehouseID, movement.Delta, movement.Reason,
+        movement.Actor, movement.ReferenceID, movement.CreatedAt,
+    )
+    if err != nil {
+        return err
+    }
+    id, err := result.LastInsertId()
+    if err != nil {
+        return err
+    }
+    movement.ID = strconv.FormatInt(id, 10)
+    return writeEvent(ctx, tx, stockEvent(movement))
+}
+
+// newMovement builds the ledger entry for a change of delta units
+func newMovement(productID, variantID string, delta int, change models.StockChange) *models.StockMovement {
+    return &models.StockMovement{
+        ProductID:   productID,
+        VariantID:   variantID,
+        WarehouseID: change.WarehouseID,
+        Delta:       delta,
+        Reason:      change.Reason,
+        Actor:       change.Actor,
+        ReferenceID: change.ReferenceID,
+    }
+}
+
+// lockProductStock reads the stock of a product and locks its row until the transaction ends
+func lockProductStock(ctx context.Context, tx *sql.Tx, id string) (int, error) {
+    var stock int
+    err := tx.QueryRowContext(ctx, `SELECT stock FROM products WHERE id = ? FOR UPDATE`, id).Scan(&stock)
+    if errors.Is(err, sql.ErrNoRows) {
+        return 0, ErrProductNotFound
+    }
+    return stock, err
+}
+
+// lockVariantStock reads the product and stock of a variant and locks its row until the transaction ends
+func lockVariantStock(ctx context.Context, tx *sql.Tx, id string) (string, int, error) {
+    var productID string
+    var stock int
+    err := tx.QueryRowContext(ctx, `SELECT product_id, stock FROM product_variants WHERE id = ? FOR UPDATE`, id).
+        Scan(&productID, &stock)
+    if errors.Is(err, sql.ErrNoRows) {
+        return "", 0, ErrVariantNotFound
+    }
+    return productID, stock, err
+}
+
+// lockWarehouseStock reads the stock of a product or variant in one warehouse
+// and locks it until the transaction ends; stock never placed there is zero
+func lockWarehouseStock(ctx context.Context, tx *sql.Tx, warehouseID, productID, variantID string) (int, error) {
+    var quantity int
+    err := tx.QueryRowContext(ctx, `
+        SELECT quantity FROM warehouse_stock
+        WHERE warehouse_id = ? AND product_id = ? AND variant_id = ? FOR UPDATE`,
+        warehouseID, productID, variantID).Scan(&quantity)
+    if errors.Is(err, sql.ErrNoRows) {
+        return 0, nil
+    }
+    return quantity, err
+}
+
+// moveStock applies a movement to the warehouse it names and to the product or
+// variant total, then records it. Stock in a warehouse cannot go negative; a
+// larger decrease fails with ErrInsufficientStock.
+func moveStock(ctx context.Context, tx *sql.Tx, movement *models.StockMovement) error {
+    if movement.Delta < 0 {
+        result, err := tx.ExecContext(ctx, `
+            UPDATE warehouse_stock SET quantity = quantity + ?
+            WHERE warehouse_id = ? AND product_id = ? AND variant_id = ? AND quantity >= ?`,
+            movement.Delta, movement.WarehouseID, movement.ProductID, movement.VariantID, -movement.Delta)
+        if err != nil {
+            return err
+        }
+        n, err := result.RowsAffected()
+        if err != nil {
+            return err
+        }
+        if n == 0 {
+            if movement.VariantID != "" {
+                return fmt.Errorf("%w for variant %s of product %s in warehouse %s",
+                    ErrInsufficientStock, movement.VariantID, movement.ProductID, movement.WarehouseID)
+            }
+            return fmt.Errorf("%w for product %s in warehouse %s", ErrInsufficientStock, movement.ProductID, movement.WarehouseID)
+        }
+    } else {
+        _, err := tx.ExecContext(ctx, `
+            INSERT INTO warehouse_stock (warehouse_id, product_id, variant_id, quantity)
+            VALUES (?, ?, ?, ?)
+            ON DUPLICATE KEY UPDATE quantity = quantity + VALUES(quantity)`,
+            movement.WarehouseID, movement.ProductID, movement.VariantID, movement.Delta)
+        var mysqlErr *mysql.MySQLError
+        if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlNoReferencedRow {
+            return fmt.Errorf("%w: %s", ErrWarehouseNotFound, movement.WarehouseID)
+        }
+        if err != nil {
+            return err
+        }
+    }
+
+    var err error
+    if movement.VariantID != "" {
+        _, err = tx.ExecContext(ctx, `UPDATE product_variants SET stock = stock + ? WHERE id = ?`, movement.Delta, movement.VariantID)
+    } else {
+        _, err = tx.ExecContext(ctx, `UPDATE products SET stock = stock + ? WHERE id = ?`, movement.Delta, movement.ProductID)
+    }
+    if err != nil {
+        return err
+    }
+    return recordMovement(ctx, tx, movement)
+}
+
+// setProductStock sets the stock of a product in the warehouse named by change
+// and records the difference
+func setProductStock(ctx context.Context, tx *sql.Tx, id string, quantity int, change models.StockChange) error {
+    if _, err := lockProductStock(ctx, tx, id); err != nil {
+        return err
+    }
+    current, err := lockWarehouseStock(ctx, tx, change.WarehouseID, id, "")
+    if err != nil || quantity == current {
+        return err
+    }
+    return moveStock(ctx, tx, newMovement(id, "", quantity-current, change))
+}
+
+// setVariantStock sets the stock of a variant in the warehouse named by change
+// and records the difference
+func setVariantStock(ctx context.Context, tx *sql.Tx, id string, quantity int, change models.StockChange) error {
+    productID, _, err := lockVariantStock(ctx, tx, id)
+    if err != nil {
+        return err
+    }
+    current, err := lockWarehouseStock(ctx, tx, change.WarehouseID, productID, id)
+    if err != nil || quantity == current {
+        return err
+    }
+    return moveStock(ctx, tx, newMovement(productID, id, quantity-current, change))
+}
+
+// setProductTotal brings the total stock of a product to quantity by moving
+// the difference into or out of the warehouse named by change
+func setProductTotal(ctx context.Context, tx *sql.Tx, id string, quantity int, change models.StockChange) error {
+    total, err := lockProductStock(ctx, tx, id)
+    if err != nil || quantity == total {
+        return err
+    }
+    return moveStock(ctx, tx, newMovement(id, "", quantity-total, change))
+}
+
+// setVariantTotal brings the total stock of a variant to quantity by moving
+// the difference into or out of the warehouse named by change
+func setVariantTotal(ctx context.Context, tx *sql.Tx, id string, quantity int, change models.StockChange) error {
+    productID, total, err := lockVariantStock(ctx, tx, id)
+    if err != nil || quantity == total {
+        return err
+    }
+    return moveStock(ctx, tx, newMovement(productID, id, quantity-total, change))
+}
+
+func (r *MySQLProductRepository) AdjustStock(ctx context.Context, movement *models.StockMovement) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if movement.VariantID != "" {
+        productID, _, err := lockVariantStock(ctx, tx, movement.VariantID)
+        if err != nil {
+            return err
+        }
+        if productID != movement.ProductID {
+            return fmt.Errorf("variant %s does not belong to product %s", movement.VariantID, movement.ProductID)
+        }
+    } else if _, err := lockProductStock(ctx, tx, movement.ProductID); err != nil {
+        return err
+    }
+
+    if err := moveStock(ctx, tx, movement); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func (r *MySQLProductRepository) GetStockHistory(ctx context.Context, productID, variantID, before string, limit int) ([]*models.StockMovement, error) {
+    where := "product_id = ?"
+    args := []interface{}{productID}
+    if variantID != "" {
+        where += " AND variant_id = ?"
+        args = append(args, variantID)
+    }
+    if before != "" {
+        id, err := strconv.ParseInt(before, 10, 64)
+        if err != nil {
+            return nil, fmt.Errorf("invalid page token %q", before)
+        }
+        where += " AND id < ?"
+        args = append(args, id)
+    }
+    args = append(args, limit)
+
+    rows, err := r.DB.QueryContext(ctx, `
+        SELECT id, product_id, variant_id, warehouse_id, delta, reason, actor, reference_id, created_at
+        FROM stock_movements WHERE `+where+` ORDER BY id DESC LIMIT ?`, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var movements []*models.StockMovement
+    for rows.Next() {
+        var movement models.StockMovement
+        var id int64
+        if err := rows.Scan(&id, &movement.ProductID, &movement.VariantID, &movement.WarehouseID, &movement.Delta,
+            &movement.Reason, &movement.Actor, &movement.ReferenceID, &movement.CreatedAt); err != nil {
+            return nil, err
+        }
+        movement.ID = strconv.FormatInt(id, 10)
+        movements = append(movements, &movement)
+    }
+    return movements, rows.Err()
+}
+
+func (r *MySQLProductRepository) StockDiscrepancies(ctx context.Context) ([]models.StockDiscrepancy, error) {
+    rows, err := r.DB.QueryContext(ctx, `
+        SELECT p.id, '', p.stock, COALESCE(SUM(m.delta), 0) AS ledger
+        FROM products p
+        LEFT JOIN stock_movements m ON m.product_id = p.id AND m.variant_id = ''
+        GROUP BY p.id, p.stock
+        HAVING p.stock <> ledger
+        UNION ALL
+        SELECT v.product_id, v.id, v.stock, COALESCE(SUM(m.delta), 0) AS ledger
+        FROM product_variants v
+        LEFT JOIN stock_movements m ON m.variant_id = v.id
+        GROUP BY v.product_id, v.id, v.stock
+        HAVING v.stock <> ledger
+        ORDER BY 1, 2`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var discrepancies []models.StockDiscrepancy
+    for rows.Next() {
+        var d models.StockDiscrepancy
+        if err := rows.Scan(&d.ProductID, &d.VariantID, &d.Stock, &d.Ledger); err != nil {
+            return nil, err
+        }
+        discrepancies = append(discrepancies, d)
+    }
+    return discrepancies, rows.Err()
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+    "context"
+    "database/sql/driver"
+    "errors"
+    "testing"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+func TestUpdateStockRecordsDifference(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    change := models.StockChange{WarehouseID: "berlin", Reason: models.StockReceiving, Actor: "alice", ReferenceID: "po-7"}
+
+    mock.ExpectBegin()
+    mock.ExpectQuery("SELECT version, deleted_at FROM products WHERE id = \\? FOR UPDATE").WithArgs("p-1").
+        WillReturnRows(sqlmock.NewRows([]string{"version", "deleted_at"}).AddRow(5, nil))
+    mock.ExpectQuery("SELECT stock FROM products WHERE id = \\? FOR UPDATE").WithArgs("p-1").
+        WillReturnRows(sqlmock.NewRows([]string{"stock"}).AddRow(9))
+    mock.ExpectQuery("SELECT quantity FROM warehouse_stock").WithArgs("berlin", "p-1", "").
+        WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(4))
+    mock.ExpectExec("INSERT INTO warehouse_stock").WithArgs("berlin", "p-1", "", 6).
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("UPDATE products SET stock = stock \\+ \\?").WithArgs(6, "p-1").
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("INSERT INTO stock_movements").
+        WithArgs("p-1", "", "berlin", 6, models.StockReceiving, "alice", "po-7", sqlmock.AnyArg()).
+        WillReturnResult(sqlmock.NewResult(42, 1))
+    expectEvent(mock, "p-1", models.EventStockChanged)
+    mock.ExpectExec("UPDATE products SET version = version \\+ 1").WithArgs("p-1").
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectCommit()
+
+    if err := repo.UpdateStock(context.Background(), "p-1", 10, change, 5); err != nil {
+        t.Fatalf("UpdateStock() error = %v", err)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
+
+func TestUpdateStockRejectsStaleVersion(t *testing.T) {
+    tests := []struct {
+        name    string
+        rows    *sqlmock.Rows
+        wantErr error
+    }{
+        {"changed since read", sqlmock.NewRows([]string{"version", "deleted_at"}).AddRow(6, nil), ErrVersionConflict},
+        {"missing", sqlmock.NewRows([]string{"version", "deleted_at"}), ErrProductNotFound},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            repo, mock := newMockRepository(t)
+            mock.ExpectBegin()
+            mock.ExpectQuery("SELECT version, deleted_at FROM products WHERE id = \\? FOR UPDATE").WithArgs("p-1").
+                WillReturnRows(tt.rows)
+            mock.ExpectRollback()
+
+            err := repo.UpdateStock(context.Background(), "p-1", 10, models.StockChange{WarehouseID: "berlin"}, 5)
+            if !errors.Is(err, tt.wantErr) {
+                t.Fatalf("UpdateStock() error = %v, want %v", err, tt.wantErr)
+            }
+            if err := mock.ExpectationsWereMet(); err != nil {
+                t.Errorf("unexpected queries: %v", err)
+            }
+        })
+    }
+}
+
+func TestAdjustStockRejectsNegativeWarehouseStock(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    movement := &models.StockMovement{ProductID: "p-1", WarehouseID: "berlin", Delta: -5, Reason: models.StockSale, Actor: "alice"}
+
+    mock.ExpectBegin()
+    mock.ExpectQuery("SELECT stock FROM products WHERE id = \\? FOR UPDATE").WithArgs("p-1").
+        WillReturnRows(sqlmock.NewRows([]string{"stock"}).AddRow(8))
+    // the product has 8 units in total but fewer than 5 in berlin
+    mock.ExpectExec("UPDATE warehouse_stock SET quantity = quantity \\+ \\?").
+        WithArgs(-5, "berlin", "p-1", "", 5).WillReturnResult(sqlmock.NewResult(0, 0))
+    mock.ExpectRollback()
+
+    if err := repo.AdjustStock(context.Background(), movement); !errors.Is(err, ErrInsufficientStock) {
+        t.Fatalf("AdjustStock() error = %v, want ErrInsufficientStock", err)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
+
+func TestGetStockHistoryPagesByID(t *testing.T) {
+    tests := []struct {
+        name      string
+        variantID string
+        before    string
+        args      []driver.Value
+        wantErr   bool
+    }{
+        {"first page", "", "", []driver.Value{"p-1", 3}, false},
+        {"variant", "v-1", "", []driver.Value{"p-1", "v-1", 3}, false},
+        {"next page", "", "17", []driver.Value{"p-1", int64(17), 3}, false},
+        {"bad token", "", "abc", nil, true},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            repo, mock := newMockRepository(t)
+            if tt.args != nil {
+                mock.ExpectQuery("FROM stock_movements WHERE .* ORDER BY id DESC LIMIT").WithArgs(tt.args...).
+                    WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "variant_id", "warehouse_id", "delta", "reason", "actor", "reference_id", "created_at"}))
+            }
+
+            _, err := repo.GetStockHistory(context.Background(), "p-1", tt.variantID, tt.before, 3)
+            if (err != nil) != tt.wantErr {
+                t.Fatalf("GetStockHistory() error = %v, wantErr %v", err, tt.wantErr)
+            }
+            if err := mock.ExpectationsWereMet(); err != nil {
+                t.Errorf("unexpected queries: %v", err)
+            }
+        })
+    }
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/google/uuid"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// Domain events are written to outbox_events in the transaction of the change
+// that raised them, so an event exists exactly when its change was committed.
+// A relay claims them in ID order, publishes them and deletes them once they
+// are delivered.
+
+// newEvent builds an event about a product with a new ID
+func newEvent(eventType models.EventType, productID string) *models.Event {
+    return &models.Event{
+        ID:         uuid.NewString(),
+        Type:       eventType,
+        ProductID:  productID,
+        OccurredAt: time.Now().UTC(),
+    }
+}
+
+// writeEvent adds an event to the outbox as part of tx
+func writeEvent(ctx context.Context, tx *sql.Tx, event *models.Event) error {
+    payload, err := json.Marshal(event)
+    if err != nil {
+        return err
+    }
+    _, err = tx.ExecContext(ctx, `
+        INSERT INTO outbox_events (product_id, payload, created_at)
+        VALUES (?, ?, ?)`, event.ProductID, payload, event.OccurredAt)
+    return err
+}
+
+// productEvent builds a ProductCreated or ProductUpdated event carrying the product as written
+func productEvent(eventType models.EventType, id string, product *models.Product) *models.Event {
+    snapshot := *product
+    snapshot.ID = id
+    event := newEvent(eventType, id)
+    event.Product = &snapshot
+    return event
+}
+
+// stockEvent builds the StockChanged event of a ledger movement
+func stockEvent(movement *models.StockMovement) *models.Event {
+    event := newEvent(models.EventStockChanged, movement.ProductID)
+    event.Stock = movement
+    event.OccurredAt = movement.CreatedAt
+    return event
+}
+
+func (r *MySQLProductRepository) ClaimEvents(ctx context.Context, claimant string, now time.Time, lease time.Duration, limit int) ([]*models.OutboxEvent, error) {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback()
+
+    // the head of the outbox stays locked until the claim is written, so a
+    // concurrent claim waits for it and then sees the events claimed
+    rows, err := tx.QueryContext(ctx, `
+        SELECT id, product_id, payload, attempts, COALESCE(last_error, ''), created_at, claimed_by, claimed_until
+        FROM outbox_events ORDER BY id LIMIT ? FOR UPDATE`, limit)
+    if err != nil {
+        return nil, err
+    }
+    var events []*models.OutboxEvent
+    for rows.Next() {
+        var event models.OutboxEvent
+        var id int64
+        var claimedBy sql.NullString
+        var claimedUntil sql.NullTime
+        if err := rows.Scan(&id, &event.ProductID, &event.Payload, &event.Attempts, &event.LastError, &event.CreatedAt, &claimedBy, &claimedUntil); err != nil {
+            rows.Close()
+            return nil, err
+        }
+        if claimedBy.Valid && claimedBy.String != claimant && claimedUntil.Time.After(now) {
+            break
+        }
+        event.ID = strconv.FormatInt(id, 10)
+        events = append(events, &event)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+    if len(events) == 0 {
+        return nil, tx.Commit()
+    }
+
+    args := []interface{}{claimant, now.Add(lease)}
+    for _, event := range events {
+        args = append(args, event.ID)
+    }
+    _, err = tx.ExecContext(ctx, `
+        UPDATE outbox_events SET claimed_by = ?, claimed_until = ?
+        WHERE id IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(events)), ", ")+`)`, args...)
+    if err != nil {
+        return nil, err
+    }
+    return events, tx.Commit()
+}
+
+func (r *MySQLProductRepository) DeleteEvents(ctx context.Context, ids []string) error {
+    if len(ids) == 0 {
+        return nil
+    }
+    args := make([]interface{}, len(ids))
+    for i, id := range ids {
+        args[i] = id
+    }
+    _, err := r.DB.ExecContext(ctx, `
+        DELETE FROM outbox_events WHERE id IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")+`)`, args...)
+    return err
+}
+
+func (r *MySQLProductRepository) RecordEventFailure(ctx context.Context, id string, reason string) error {
+    _, err := r.DB.ExecContext(ctx, `
+        UPDATE outbox_events SET attempts = attempts + 1, last_error = ? WHERE id = ?`, reason, id)
+    return err
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+    "context"
+    "database/sql/driver"
+    "encoding/json"
+    "reflect"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// eventOfType matches an outbox payload holding an event of the given type
+type eventOfType models.EventType
+
+func (t eventOfType) Match(v driver.Value) bool {
+    payload, ok := v.([]byte)
+    if !ok {
+        return false
+    }
+    var event models.Event
+    return json.Unmarshal(payload, &event) == nil && event.Type == models.EventType(t) && event.ID != ""
+}
+
+// expectEvent queues the outbox insert of an event about a product
+func expectEvent(mock sqlmock.Sqlmock, productID string, eventType models.EventType) {
+    mock.ExpectExec("INSERT INTO outbox_events").
+        WithArgs(productID, eventOfType(eventType), sqlmock.AnyArg()).
+        WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func TestArchiveProductWritesEvent(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    at := time.Unix(100, 0)
+    mock.ExpectBegin()
+    mock.ExpectQuery("SELECT version, deleted_at FROM products WHERE id = \\? FOR UPDATE").WithArgs("p-1").
+        WillReturnRows(sqlmock.NewRows([]string{"version", "deleted_at"}).AddRow(3, nil))
+    mock.ExpectExec("UPDATE products SET deleted_at = \\?, version = version \\+ 1").WithArgs(&at, "p-1").
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    expectEvent(mock, "p-1", models.EventProductArchived)
+    mock.ExpectCommit()
+
+    if err := repo.ArchiveProduct(context.Background(), "p-1", at, 3); err != nil {
+        t.Fatalf("ArchiveProduct() error = %v", err)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
+
+func TestClaimEvents(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    now := time.Unix(1000, 0)
+    columns := []string{"id", "product_id", "payload", "attempts", "last_error", "created_at", "claimed_by", "claimed_until"}
+    mock.ExpectBegin()
+    mock.ExpectQuery("SELECT id, product_id, payload, .* FROM outbox_events ORDER BY id LIMIT \\? FOR UPDATE").WithArgs(5).
+        WillReturnRows(sqlmock.NewRows(columns).
+            AddRow(7, "p-1", []byte(`{"type":"ProductCreated"}`), 0, "", now, nil, nil).
+            AddRow(8, "p-1", []byte(`{"type":"StockChanged"}`), 2, "broker down", now, "relay-a", now.Add(time.Second)).
+            AddRow(9, "p-2", []byte(`{"type":"StockChanged"}`), 0, "", now, "relay-b", now.Add(-time.Second)).
+            AddRow(10, "p-2", []byte(`{"type":"StockChanged"}`), 0, "", now, "relay-b", now.Add(time.Second)).
+            AddRow(11, "p-3", []byte(`{"type":"ProductCreated"}`), 0, "", now, nil, nil))
+    mock.ExpectExec("UPDATE outbox_events SET claimed_by = \\?, claimed_until = \\? WHERE id IN \\(\\?, \\?, \\?\\)").
+        WithArgs("relay-a", now.Add(time.Minute), "7", "8", "9").
+        WillReturnResult(sqlmock.NewResult(0, 3))
+    mock.ExpectCommit()
+
+    // 8 is held by relay-a already and the claim of 9 ran out; 10 is held by
+    // relay-b, so neither it nor 11 after it is claimed
+    events, err := repo.ClaimEvents(context.Background(), "relay-a", now, time.Minute, 5)
+    if err != nil {
+        t.Fatalf("ClaimEvents() error = %v", err)
+    }
+    want := []*models.OutboxEvent{
+        {ID: "7", ProductID: "p-1", Payload: []byte(`{"type":"ProductCreated"}`), CreatedAt: now},
+        {ID: "8", ProductID: "p-1", Payload: []byte(`{"type":"StockChanged"}`), Attempts: 2, LastError: "broker down", CreatedAt: now},
+        {ID: "9", ProductID: "p-2", Payload: []byte(`{"type":"StockChanged"}`), CreatedAt: now},
+    }
+    if !reflect.DeepEqual(events, want) {
+        t.Errorf("ClaimEvents() = %+v, want %+v", events, want)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
+
+func TestClaimEventsHeldByAnotherRelay(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    now := time.Unix(1000, 0)
+    mock.ExpectBegin()
+    mock.ExpectQuery("SELECT id, product_id, payload, .* FOR UPDATE").WithArgs(5).
+        WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "payload", "attempts", "last_error", "created_at", "claimed_by", "claimed_until"}).
+            AddRow(7, "p-1", []byte(`{}`), 0, "", now, "relay-b", now.Add(time.Second)).
+            AddRow(8, "p-1", []byte(`{}`), 0, "", now, nil, nil))
+    mock.ExpectCommit()
+
+    events, err := repo.ClaimEvents(context.Background(), "relay-a", now, time.Minute, 5)
+    if err != nil || len(events) != 0 {
+        t.Errorf("ClaimEvents() = %+v, %v, want nothing while relay-b holds the head", events, err)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
@@ -0,0 +1,277 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+)
+
+func (r *MySQLProductRepository) CreatePriceList(ctx context.Context, list *models.PriceList) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    _, err = tx.ExecContext(ctx, `
+        INSERT INTO price_lists (id, name, starts_at, ends_at, created_at)
+        VALUES (?, ?, ?, ?, ?)`,
+        list.ID, list.Name, list.StartsAt, list.EndsAt, list.CreatedAt,
+    )
+    if err != nil {
+        return err
+    }
+    if err := insertPriceListEntries(ctx, tx, list.ID, list.Entries); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// insertPriceListEntries writes the entry rows of a price list
+func insertPriceListEntries(ctx context.Context, tx *sql.Tx, listID string, entries []models.PriceListEntry) error {
+    for _, entry := range entries {
+        _, err := tx.ExecContext(ctx, `
+            INSERT INTO price_list_entries (price_list_id, product_id, variant_id, price, currency, percent_off)
+            VALUES (?, ?, ?, ?, ?, ?)`,
+            listID, entry.ProductID, entry.VariantID, priceDecimal(entry.Price), priceCurrency(entry.Price), entry.PercentOff,
+        )
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (r *MySQLProductRepository) GetPriceList(ctx context.Context, id string) (*models.PriceList, error) {
+    lists, err := r.listPriceLists(ctx, "l.id = ?", id)
+    if err != nil {
+        return nil, err
+    }
+    if len(lists) == 0 {
+        return nil, ErrPriceListNotFound
+    }
+    return lists[0], nil
+}
+
+func (r *MySQLProductRepository) ListPriceLists(ctx context.Context) ([]*models.PriceList, error) {
+    return r.listPriceLists(ctx, "TRUE")
+}
+
+func (r *MySQLProductRepository) UpdatePriceList(ctx context.Context, id string, list *models.PriceList) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var exists int
+    err = tx.QueryRowContext(ctx, `SELECT 1 FROM price_lists WHERE id = ? FOR UPDATE`, id).Scan(&exists)
+    if errors.Is(err, sql.ErrNoRows) {
+        return ErrPriceListNotFound
+    }
+    if err != nil {
+        return err
+    }
+
+    _, err = tx.ExecContext(ctx, `
+        UPDATE price_lists SET name = ?, starts_at = ?, ends_at = ? WHERE id = ?`,
+        list.Name, list.StartsAt, list.EndsAt, id,
+    )
+    if err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, `DELETE FROM price_list_entries WHERE price_list_id = ?`, id); err != nil {
+        return err
+    }
+    if err := insertPriceListEntries(ctx, tx, id, list.Entries); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func (r *MySQLProductRepository) DeletePriceList(ctx context.Context, id string) error {
+    result, err := r.DB.ExecContext(ctx, `DELETE FROM price_lists WHERE id = ?`, id)
+    if err != nil {
+        return err
+    }
+    if n, err := result.RowsAffected(); err == nil && n == 0 {
+        return ErrPriceListNotFound
+    }
+    return nil
+}
+
+func (r *MySQLProductRepository) ActivePriceLists(ctx context.Context, productIDs []string, at time.Time) ([]*models.PriceList, error) {
+    if len(productIDs) == 0 {
+        return nil, nil
+    }
+    args := []interface{}{at, at}
+    for _, id := range productIDs {
+        args = append(args, id)
+    }
+    in := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(productIDs)), ", ") + ")"
+    return r.listPriceLists(ctx, "l.starts_at <= ? AND (l.ends_at IS NULL OR l.ends_at > ?) AND e.product_id IN "+in, args...)
+}
+
+// listPriceLists loads the price lists matching where, which may refer to the
+// list as l and its entries as e; a list is returned with the matching entries
+// only, or with none if it has no entries at all
+func (r *MySQLProductRepository) listPriceLists(ctx context.Context, where string, args ...interface{}) ([]*models.PriceList, error) {
+    rows, err := r.DB.QueryContext(ctx, `
+        SELECT l.id, l.name, l.starts_at, l.ends_at, l.created_at,
+               e.product_id, e.variant_id, e.price, e.currency, e.percent_off
+        FROM price_lists l
+        LEFT JOIN price_list_entries e ON e.price_list_id = l.id
+        WHERE `+where+`
+        ORDER BY l.starts_at DESC, l.id, e.product_id, e.variant_id`, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var lists []*models.PriceList
+    byID := make(map[string]*models.PriceList)
+    for rows.Next() {
+        var list models.PriceList
+        var endsAt sql.NullTime
+        var productID, variantID sql.NullString
+        var price sqlPrice
+        var percentOff sql.NullFloat64
+        if err := rows.Scan(&list.ID, &list.Name, &list.StartsAt, &endsAt, &list.CreatedAt,
+            &productID, &variantID, &price.amount, &price.currency, &percentOff); err != nil {
+            return nil, err
+        }
+        current, ok := byID[list.ID]
+        if !ok {
+            if endsAt.Valid {
+                list.EndsAt = &endsAt.Time
+            }
+            current = &list
+            byID[list.ID] = current
+            lists = append(lists, current)
+        }
+        if !productID.Valid {
+            continue
+        }
+        entry := models.PriceListEntry{
+            PriceListID: current.ID,
+            ProductID:   productID.String,
+            VariantID:   variantID.String,
+            PercentOff:  percentOff.Float64,
+        }
+        if entry.Price, err = price.money(); err != nil {
+            return nil, err
+        }
+        current.Entries = append(current.Entries, entry)
+    }
+    return lists, rows.Err()
+}
+
+func (r *MySQLProductRepository) RecordPriceChanges(ctx context.Context, changes []*models.PriceChange) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    for _, change := range changes {
+        result, err := tx.ExecContext(ctx, `
+            INSERT INTO price_history (product_id, variant_id, price_list_id, price, currency, percent_off, starts_at, ends_at, created_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+            change.ProductID, change.VariantID, change.PriceListID, priceDecimal(change.Price), priceCurrency(change.Price), change.PercentOff,
+            change.StartsAt, change.EndsAt, change.CreatedAt,
+        )
+        if err != nil {
+            return err
+        }
+        id, err := result.LastInsertId()
+        if err != nil {
+            return err
+        }
+        change.ID = strconv.FormatInt(id, 10)
+    }
+    return tx.Commit()
+}
+
+func (r *MySQLProductRepository) GetPriceHistory(ctx context.Context, productID, before string, limit int) ([]*models.PriceChange, error) {
+    where := "product_id = ?"
+    args := []interface{}{productID}
+    if before != "" {
+        id, err := strconv.ParseInt(before, 10, 64)
+        if err != nil {
+            return nil, fmt.Errorf("invalid page token %q", before)
+        }
+        where += " AND id < ?"
+        args = append(args, id)
+    }
+    args = append(args, limit)
+
+    rows, err := r.DB.QueryContext(ctx, `
+        SELECT id, product_id, variant_id, price_list_id, price, currency, percent_off, starts_at, ends_at, created_at
+        FROM price_history WHERE `+where+` ORDER BY id DESC LIMIT ?`, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var changes []*models.PriceChange
+    for rows.Next() {
+        var change models.PriceChange
+        var id int64
+        var price sqlPrice
+        var endsAt sql.NullTime
+        if err := rows.Scan(&id, &change.ProductID, &change.VariantID, &change.PriceListID, &price.amount, &price.currency,
+            &change.PercentOff, &change.StartsAt, &endsAt, &change.CreatedAt); err != nil {
+            return nil, err
+        }
+        change.ID = strconv.FormatInt(id, 10)
+        if change.Price, err = price.money(); err != nil {
+            return nil, err
+        }
+        if endsAt.Valid {
+            change.EndsAt = &endsAt.Time
+        }
+        changes = append(changes, &change)
+    }
+    return changes, rows.Err()
+}
+
+// sqlPrice is a price read from a DECIMAL price column and the currency
+// column beside it
+type sqlPrice struct {
+    amount, currency sql.NullString
+}
+
+// money returns the price, or nil when the price column is NULL
+func (p sqlPrice) money() (*money.Money, error) {
+    if !p.amount.Valid {
+        return nil, nil
+    }
+    price, err := money.Parse(p.amount.String, p.currency.String)
+    if err != nil {
+        return nil, fmt.Errorf("invalid stored price: %w", err)
+    }
+    return &price, nil
+}
+
+// priceDecimal and priceCurrency are the values of the price and currency
+// columns of an optional price, both NULL when it is nil
+func priceDecimal(price *money.Money) interface{} {
+    if price == nil {
+        return nil
+    }
+    return price.Decimal()
+}
+
+func priceCurrency(price *money.Money) interface{} {
+    if price == nil {
+        return nil
+    }
+    return price.Currency
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+
+    "github.com/samObot19/shopverse/product-service/money"
+)
+
+var priceListColumns = []string{"id", "name", "starts_at", "ends_at", "created_at", "product_id", "variant_id", "price", "currency", "percent_off"}
+
+func TestActivePriceListsGroupsEntries(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    now := time.Unix(0, 0)
+    ends := now.Add(48 * time.Hour)
+    mock.ExpectQuery("LEFT JOIN price_list_entries e ON e.price_list_id = l.id\\s+WHERE l.starts_at <= \\? AND \\(l.ends_at IS NULL OR l.ends_at > \\?\\) AND e.product_id IN \\(\\?, \\?\\)").
+        WithArgs(now, now, "p-1", "p-2").
+        WillReturnRows(sqlmock.NewRows(priceListColumns).
+            AddRow("weekend", "Weekend sale", now, ends, now, "p-1", "", nil, nil, 20.0).
+            AddRow("weekend", "Weekend sale", now, ends, now, "p-2", "v-1", "9.5000", "USD", 0.0).
+            AddRow("clearance", "Clearance", now, nil, now, "p-2", "", nil, nil, 50.0))
+
+    lists, err := repo.ActivePriceLists(context.Background(), []string{"p-1", "p-2"}, now)
+    if err != nil {
+        t.Fatalf("ActivePriceLists() error = %v", err)
+    }
+    if len(lists) != 2 {
+        t.Fatalf("ActivePriceLists() returned %d lists, want 2", len(lists))
+    }
+    weekend, clearance := lists[0], lists[1]
+    if len(weekend.Entries) != 2 || weekend.EndsAt == nil || !weekend.EndsAt.Equal(ends) {
+        t.Errorf("weekend list = %+v, want two entries ending at %v", weekend, ends)
+    }
+    if entry := weekend.Entries[1]; entry.PriceListID != "weekend" || entry.VariantID != "v-1" || entry.Price == nil || *entry.Price != money.New(950, "USD") {
+        t.Errorf("fixed price entry = %+v", entry)
+    }
+    if weekend.Entries[0].Price != nil || weekend.Entries[0].PercentOff != 20 {
+        t.Errorf("percentage entry = %+v", weekend.Entries[0])
+    }
+    if clearance.EndsAt != nil || len(clearance.Entries) != 1 {
+        t.Errorf("clearance list = %+v, want one entry and no end", clearance)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
+
+func TestActivePriceListsWithoutProducts(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    lists, err := repo.ActivePriceLists(context.Background(), nil, time.Unix(0, 0))
+    if err != nil || lists != nil {
+        t.Fatalf("ActivePriceLists() = %v, %v, want no lists and no query", lists, err)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
@@ -3,9 +3,13 @@ package repository
 import (
     "context"
     "database/sql"
-	"fmt"
+    "errors"
+    "fmt"
+    "sort"
 	"strings"
+    "time"
     "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/query"
 )
 
 
@@ -25,14 +29,23 @@ func (r *MySQLProductRepository) CreateProduct(ctx context.Context, product *mod
     }
     defer tx.Rollback()
 
-    _, err = tx.ExecContext(ctx, `
-        INSERT INTO products (id, title, description, price, stock, category, ratings, created_at)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
-        product.ID, product.Title, product.Description, product.Price,
-        product.Stock, product.Category, product.Ratings, product.CreatedAt,
+    if err := insertProduct(ctx, tx, product); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// insertProduct writes a new product with its child rows and receives its
+// initial stock into the default warehouse
+func insertProduct(ctx context.Context, tx *sql.Tx, product *models.Product) error {
+    _, err := tx.ExecContext(ctx, `
+        INSERT INTO products (id, sku, title, description, price, currency, stock, category, category_id, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?, ?)`,
+        product.ID, nullSKU(product.SKU), product.Title, product.Description, product.Price.Decimal(), product.Price.Currency,
+        product.Category, product.CategoryID, product.CreatedAt,
     )
     if err != nil {
-        return err
+        return productWriteError(err)
     }
 
     _, err = tx.ExecContext(ctx, `
@@ -41,39 +54,95 @@ func (r *MySQLProductRepository) CreateProduct(ctx context.Context, product *mod
     if err != nil {
         return err
     }
+    if err := insertProductChildren(ctx, tx, product); err != nil {
+        return err
+    }
+    if err := writeEvent(ctx, tx, productEvent(models.EventProductCreated, product.ID, product)); err != nil {
+        return err
+    }
 
-    for _, size := range product.Attributes.Size {
-        _, err = tx.ExecContext(ctx, `
+    // the initial stock is received into the default warehouse
+    if product.Stock != 0 {
+        change := models.StockChange{WarehouseID: models.DefaultWarehouseID, Reason: models.StockReceiving, Actor: models.SystemActor}
+        if err := moveStock(ctx, tx, newMovement(product.ID, "", product.Stock, change)); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// insertProductChildren writes the size, image and translation rows of a product
+func insertProductChildren(ctx context.Context, tx *sql.Tx, product *models.Product) error {
+    if err := insertProductSizes(ctx, tx, product.ID, product.Attributes.Size); err != nil {
+        return err
+    }
+    if err := insertProductImages(ctx, tx, product.ID, product.Images); err != nil {
+        return err
+    }
+    return insertProductTranslations(ctx, tx, product.ID, product.Translations)
+}
+
+func insertProductSizes(ctx context.Context, tx *sql.Tx, id string, sizes []string) error {
+    for _, size := range sizes {
+        _, err := tx.ExecContext(ctx, `
             INSERT INTO product_sizes (product_id, size)
-            VALUES (?, ?)`, product.ID, size)
+            VALUES (?, ?)`, id, size)
         if err != nil {
             return err
         }
     }
+    return nil
+}
 
-    for _, img := range product.Images {
-        _, err = tx.ExecContext(ctx, `
+func insertProductImages(ctx context.Context, tx *sql.Tx, id string, images []string) error {
+    for _, img := range images {
+        _, err := tx.ExecContext(ctx, `
             INSERT INTO product_images (product_id, image_url)
-            VALUES (?, ?)`, product.ID, img)
+            VALUES (?, ?)`, id, img)
         if err != nil {
             return err
         }
     }
+    return nil
+}
 
-    return tx.Commit()
+// insertProductTranslations writes the translation rows of a product in locale order
+func insertProductTranslations(ctx context.Context, tx *sql.Tx, id string, translations models.Translations) error {
+    locales := make([]string, 0, len(translations))
+    for locale := range translations {
+        locales = append(locales, locale)
+    }
+    sort.Strings(locales)
+    for _, locale := range locales {
+        t := translations[locale]
+        _, err := tx.ExecContext(ctx, `
+            INSERT INTO product_translations (product_id, locale, title, description)
+            VALUES (?, ?, ?, ?)`, id, locale, t.Title, t.Description)
+        if err != nil {
+            return err
+        }
+    }
+    return nil
 }
 
 func (r *MySQLProductRepository) GetProductByID(ctx context.Context, id string) (*models.Product, error) {
     var product models.Product
+    var price sqlPrice
 
     err := r.DB.QueryRowContext(ctx, `
-        SELECT id, title, description, price, stock, category, ratings, created_at
+        SELECT id, COALESCE(sku, ''), title, description, price, currency, stock, category, category_id, ratings, review_count, version, created_at, deleted_at
         FROM products WHERE id = ?`, id).
-        Scan(&product.ID, &product.Title, &product.Description, &product.Price,
-            &product.Stock, &product.Category, &product.Ratings, &product.CreatedAt)
+        Scan(&product.ID, &product.SKU, &product.Title, &product.Description, &price.amount, &price.currency,
+            &product.Stock, &product.Category, &product.CategoryID, &product.Ratings, &product.ReviewCount, &product.Version, &product.CreatedAt, &product.DeletedAt)
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil, ErrProductNotFound
+    }
     if err != nil {
         return nil, err
     }
+    if err := scanProductPrice(&product, price); err != nil {
+        return nil, err
+    }
 
     err = r.DB.QueryRowContext(ctx, `
         SELECT color FROM product_attributes WHERE product_id = ?`, id).
@@ -112,103 +181,345 @@ func (r *MySQLProductRepository) GetProductByID(ctx context.Context, id string)
         product.Images = append(product.Images, img)
     }
 
+    err = r.loadTranslations(ctx, "(?)", []interface{}{id}, map[string]*models.Product{id: &product})
+    if err != nil {
+        return nil, err
+    }
     return &product, nil
 }
 
-func (r *MySQLProductRepository) GetAllProducts(ctx context.Context, filters map[string]interface{}) ([]*models.Product, error) {
-    query := "SELECT id FROM products WHERE 1=1"
-    args := []interface{}{}
+func (r *MySQLProductRepository) GetAllProducts(ctx context.Context, filter *query.Filter, sort []query.Sort, prices *query.Prices, includeArchived bool) ([]*models.Product, error) {
+    where, args, err := filter.SQL(prices)
+    if err != nil {
+        return nil, err
+    }
+    if !includeArchived {
+        where += " AND deleted_at IS NULL"
+    }
+    orderBy, orderArgs, err := query.SortSQL(sort, prices)
+    if err != nil {
+        return nil, err
+    }
+
+    return r.listProducts(ctx, where+orderBy, append(args, orderArgs...)...)
+}
+
+// UpdateProduct writes the masked fields of a product, including its color,
+// sizes and images; a changed stock level is applied to the default warehouse
+// and recorded in the ledger as an adjustment
+func (r *MySQLProductRepository) UpdateProduct(ctx context.Context, id string, updated *models.Product, mask models.ProductMask) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
 
-    for key, value := range filters {
-        query += fmt.Sprintf(" AND %s = ?", key)
-        args = append(args, value)
+    if err := updateProduct(ctx, tx, id, updated, mask); err != nil {
+        return err
     }
+    return tx.Commit()
+}
 
-    rows, err := r.DB.QueryContext(ctx, query, args...)
+// scanProductPrice sets the price of a product read from its price columns
+func scanProductPrice(product *models.Product, price sqlPrice) error {
+    p, err := price.money()
     if err != nil {
-        return nil, err
+        return err
     }
-    defer rows.Close()
+    if p == nil {
+        return fmt.Errorf("product %s has no price", product.ID)
+    }
+    product.Price = *p
+    return nil
+}
 
-    var products []*models.Product
-    for rows.Next() {
-        var id string
-        if err := rows.Scan(&id); err != nil {
-            return nil, err
+// fieldColumns maps the scalar fields of a product to their columns; the
+// price is written to two
+var fieldColumns = []struct {
+    field  models.ProductField
+    column string
+    value  func(*models.Product) interface{}
+}{
+    {models.ProductSKU, "sku", func(p *models.Product) interface{} { return nullSKU(p.SKU) }},
+    {models.ProductTitle, "title", func(p *models.Product) interface{} { return p.Title }},
+    {models.ProductDescription, "description", func(p *models.Product) interface{} { return p.Description }},
+    {models.ProductPrice, "price", func(p *models.Product) interface{} { return p.Price.Decimal() }},
+    {models.ProductPrice, "currency", func(p *models.Product) interface{} { return p.Price.Currency }},
+    {models.ProductCategory, "category", func(p *models.Product) interface{} { return p.Category }},
+    {models.ProductCategoryID, "category_id", func(p *models.Product) interface{} { return p.CategoryID }},
+}
+
+// updateProduct writes the masked fields of a product that is still at
+// updated.Version within a transaction and increments its version
+func updateProduct(ctx context.Context, tx *sql.Tx, id string, updated *models.Product, mask models.ProductMask) error {
+    version, _, err := lockProduct(ctx, tx, id)
+    if err != nil {
+        return err
+    }
+    if err := checkVersion(id, updated.Version, version); err != nil {
+        return err
+    }
+
+    if mask[models.ProductStock] {
+        change := models.StockChange{WarehouseID: models.DefaultWarehouseID, Reason: models.StockAdjustment, Actor: models.SystemActor}
+        if err := setProductTotal(ctx, tx, id, updated.Stock, change); err != nil {
+            return err
+        }
+    }
+
+    set := "version = version + 1"
+    var args []interface{}
+    for _, c := range fieldColumns {
+        if mask[c.field] {
+            set += ", " + c.column + " = ?"
+            args = append(args, c.value(updated))
         }
-        product, err := r.GetProductByID(ctx, id)
+    }
+    if _, err := tx.ExecContext(ctx, `UPDATE products SET `+set+` WHERE id = ?`, append(args, id)...); err != nil {
+        return productWriteError(err)
+    }
+    if err := updateProductChildren(ctx, tx, id, updated, mask); err != nil {
+        return err
+    }
+
+    event := productEvent(models.EventProductUpdated, id, updated)
+    event.Product.Version = version + 1
+    return writeEvent(ctx, tx, event)
+}
+
+// updateProductChildren replaces the masked color, sizes, images and translations of a product
+func updateProductChildren(ctx context.Context, tx *sql.Tx, id string, updated *models.Product, mask models.ProductMask) error {
+    if mask[models.ProductColor] {
+        _, err := tx.ExecContext(ctx, `
+            INSERT INTO product_attributes (product_id, color) VALUES (?, ?)
+            ON DUPLICATE KEY UPDATE color = VALUES(color)`, id, updated.Attributes.Color)
         if err != nil {
-            return nil, err
+            return err
         }
-        products = append(products, product)
     }
+    if mask[models.ProductSizes] {
+        if _, err := tx.ExecContext(ctx, `DELETE FROM product_sizes WHERE product_id = ?`, id); err != nil {
+            return err
+        }
+        if err := insertProductSizes(ctx, tx, id, updated.Attributes.Size); err != nil {
+            return err
+        }
+    }
+    if mask[models.ProductImages] {
+        if _, err := tx.ExecContext(ctx, `DELETE FROM product_images WHERE product_id = ?`, id); err != nil {
+            return err
+        }
+        if err := insertProductImages(ctx, tx, id, updated.Images); err != nil {
+            return err
+        }
+    }
+    if mask[models.ProductTranslations] {
+        if _, err := tx.ExecContext(ctx, `DELETE FROM product_translations WHERE product_id = ?`, id); err != nil {
+            return err
+        }
+        if err := insertProductTranslations(ctx, tx, id, updated.Translations); err != nil {
+            return err
+        }
+    }
+    return nil
+}
 
-    return products, nil
+// ArchiveProduct sets the deleted_at of an active product
+func (r *MySQLProductRepository) ArchiveProduct(ctx context.Context, id string, at time.Time, version int64) error {
+    return r.setDeletedAt(ctx, id, &at, version, models.EventProductArchived)
 }
 
-func (r *MySQLProductRepository) UpdateProduct(ctx context.Context, id string, updated *models.Product) error {
-    _, err := r.DB.ExecContext(ctx, `
-        UPDATE products SET title = ?, description = ?, price = ?, stock = ?, category = ?, ratings = ?
-        WHERE id = ?`,
-        updated.Title, updated.Description, updated.Price,
-        updated.Stock, updated.Category, updated.Ratings, id,
-    )
-    return err
+// RestoreProduct clears the deleted_at of an archived product at whatever version it is
+func (r *MySQLProductRepository) RestoreProduct(ctx context.Context, id string) error {
+    return r.setDeletedAt(ctx, id, nil, anyVersion, models.EventProductRestored)
 }
 
-func (r *MySQLProductRepository) DeleteProduct(ctx context.Context, id string) error {
+// setDeletedAt archives a product, or restores it when at is nil, increments
+// its version and records the event; a product already in the requested
+// state is left alone
+func (r *MySQLProductRepository) setDeletedAt(ctx context.Context, id string, at *time.Time, expected int64, eventType models.EventType) error {
     tx, err := r.DB.BeginTx(ctx, nil)
     if err != nil {
         return err
     }
     defer tx.Rollback()
 
-    _, _ = tx.ExecContext(ctx, `DELETE FROM product_attributes WHERE product_id = ?`, id)
-    _, _ = tx.ExecContext(ctx, `DELETE FROM product_sizes WHERE product_id = ?`, id)
-    _, _ = tx.ExecContext(ctx, `DELETE FROM product_images WHERE product_id = ?`, id)
-
-    _, err = tx.ExecContext(ctx, `DELETE FROM products WHERE id = ?`, id)
+    version, archived, err := lockProduct(ctx, tx, id)
     if err != nil {
         return err
     }
+    if err := checkVersion(id, expected, version); err != nil {
+        return err
+    }
+    if archived == (at != nil) {
+        return nil
+    }
 
+    if _, err := tx.ExecContext(ctx, `UPDATE products SET deleted_at = ?, version = version + 1 WHERE id = ?`, at, id); err != nil {
+        return err
+    }
+    if err := writeEvent(ctx, tx, newEvent(eventType, id)); err != nil {
+        return err
+    }
     return tx.Commit()
 }
 
-func (r *MySQLProductRepository) UpdateStock(ctx context.Context, id string, quantity int) error {
-    _, err := r.DB.ExecContext(ctx, `
-        UPDATE products SET stock = ? WHERE id = ?`, quantity, id)
-    return err
+// anyVersion skips the version check of a write the caller did not base on a read
+const anyVersion int64 = 0
+
+// lockProduct locks a product row until the transaction ends and reads its
+// version and whether it is archived
+func lockProduct(ctx context.Context, tx *sql.Tx, id string) (int64, bool, error) {
+    var version int64
+    var deletedAt sql.NullTime
+    err := tx.QueryRowContext(ctx, `SELECT version, deleted_at FROM products WHERE id = ? FOR UPDATE`, id).Scan(&version, &deletedAt)
+    if errors.Is(err, sql.ErrNoRows) {
+        return 0, false, ErrProductNotFound
+    }
+    if err != nil {
+        return 0, false, err
+    }
+    return version, deletedAt.Valid, nil
 }
 
-func (r *MySQLProductRepository) GetProductsByCategory(ctx context.Context, category string) ([]*models.Product, error) {
-    rows, err := r.DB.QueryContext(ctx, `SELECT id FROM products WHERE category = ?`, category)
+// checkVersion returns ErrVersionConflict unless a product is at the expected version
+func checkVersion(id string, expected, current int64) error {
+    if expected != anyVersion && expected != current {
+        return fmt.Errorf("%w: product %s is at version %d, not %d", ErrVersionConflict, id, current, expected)
+    }
+    return nil
+}
+
+func (r *MySQLProductRepository) ArchivedProducts(ctx context.Context, before time.Time, afterID string, limit int) ([]string, error) {
+    rows, err := r.DB.QueryContext(ctx, `
+        SELECT id FROM products
+        WHERE deleted_at IS NOT NULL AND deleted_at < ? AND id > ?
+        ORDER BY id LIMIT ?`, before, afterID, limit)
     if err != nil {
         return nil, err
     }
     defer rows.Close()
 
-    var products []*models.Product
+    var ids []string
     for rows.Next() {
         var id string
         if err := rows.Scan(&id); err != nil {
             return nil, err
         }
-        product, err := r.GetProductByID(ctx, id)
-        if err != nil {
-            return nil, err
+        ids = append(ids, id)
+    }
+    return ids, rows.Err()
+}
+
+// PurgeProduct deletes an archived product; its variants, warehouse stock,
+// reviews and price list entries go with it through their foreign keys
+func (r *MySQLProductRepository) PurgeProduct(ctx context.Context, id string) (bool, error) {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return false, err
+    }
+    defer tx.Rollback()
+
+    _, archived, err := lockProduct(ctx, tx, id)
+    if err != nil || !archived {
+        return false, err
+    }
+
+    for _, stmt := range []string{
+        `DELETE FROM product_attributes WHERE product_id = ?`,
+        `DELETE FROM product_sizes WHERE product_id = ?`,
+        `DELETE FROM product_images WHERE product_id = ?`,
+        `DELETE FROM product_translations WHERE product_id = ?`,
+        `DELETE FROM product_media WHERE product_id = ?`,
+        `DELETE FROM products WHERE id = ?`,
+    } {
+        if _, err := tx.ExecContext(ctx, stmt, id); err != nil {
+            return false, err
         }
-        products = append(products, product)
     }
+    if err := writeEvent(ctx, tx, newEvent(models.EventProductDeleted, id)); err != nil {
+        return false, err
+    }
+    if err := tx.Commit(); err != nil {
+        return false, err
+    }
+    return true, nil
+}
 
-    return products, nil
+// UpdateStock sets the stock quantity of a product in one warehouse, records
+// the difference and increments the product's version
+func (r *MySQLProductRepository) UpdateStock(ctx context.Context, id string, quantity int, change models.StockChange, version int64) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    current, _, err := lockProduct(ctx, tx, id)
+    if err != nil {
+        return err
+    }
+    if err := checkVersion(id, version, current); err != nil {
+        return err
+    }
+    if err := setProductStock(ctx, tx, id, quantity, change); err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, `UPDATE products SET version = version + 1 WHERE id = ?`, id); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func (r *MySQLProductRepository) GetProductsByCategory(ctx context.Context, category string, sort []query.Sort, prices *query.Prices) ([]*models.Product, error) {
+    orderBy, orderArgs, err := query.SortSQL(sort, prices)
+    if err != nil {
+        return nil, err
+    }
+
+    return r.listProducts(ctx, "category = ? AND deleted_at IS NULL"+orderBy, append([]interface{}{category}, orderArgs...)...)
+}
+
+// GetProductsByCategoryIDs loads the active products filed under any of the given categories
+func (r *MySQLProductRepository) GetProductsByCategoryIDs(ctx context.Context, categoryIDs []string, sort []query.Sort, prices *query.Prices) ([]*models.Product, error) {
+    if len(categoryIDs) == 0 {
+        return nil, nil
+    }
+    orderBy, orderArgs, err := query.SortSQL(sort, prices)
+    if err != nil {
+        return nil, err
+    }
+
+    args := make([]interface{}, len(categoryIDs))
+    for i, id := range categoryIDs {
+        args[i] = id
+    }
+    in := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ") + ")"
+    return r.listProducts(ctx, "category_id IN "+in+" AND deleted_at IS NULL"+orderBy, append(args, orderArgs...)...)
+}
+
+// GetProductsByIDs loads the given products in no particular order; unknown IDs are skipped
+func (r *MySQLProductRepository) GetProductsByIDs(ctx context.Context, ids []string) ([]*models.Product, error) {
+    if len(ids) == 0 {
+        return nil, nil
+    }
+
+    args := make([]interface{}, len(ids))
+    for i, id := range ids {
+        args[i] = id
+    }
+    return r.listProducts(ctx, "id IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")+")", args...)
 }
 
-func (r *MySQLProductRepository) SearchProducts(ctx context.Context, query string) ([]*models.Product, error) {
-    search := "%" + strings.ToLower(query) + "%"
+// hydrateBatchSize bounds the number of IDs bound into a single IN (...) list
+const hydrateBatchSize = 500
+
+// listProducts loads the products matching a WHERE clause with one query, then
+// fills in attributes, sizes, images and translations with one grouped query per child table
+func (r *MySQLProductRepository) listProducts(ctx context.Context, where string, args ...interface{}) ([]*models.Product, error) {
     rows, err := r.DB.QueryContext(ctx, `
-        SELECT id FROM products
-        WHERE LOWER(title) LIKE ? OR LOWER(description) LIKE ?`, search, search)
+        SELECT id, COALESCE(sku, ''), title, description, price, currency, stock, category, category_id, ratings, review_count, version, created_at, deleted_at
+        FROM products WHERE `+where, args...)
     if err != nil {
         return nil, err
     }
@@ -216,16 +527,113 @@ func (r *MySQLProductRepository) SearchProducts(ctx context.Context, query strin
 
     var products []*models.Product
     for rows.Next() {
-        var id string
-        if err := rows.Scan(&id); err != nil {
+        var product models.Product
+        var price sqlPrice
+        if err := rows.Scan(&product.ID, &product.SKU, &product.Title, &product.Description, &price.amount, &price.currency,
+            &product.Stock, &product.Category, &product.CategoryID, &product.Ratings, &product.ReviewCount, &product.Version, &product.CreatedAt, &product.DeletedAt); err != nil {
             return nil, err
         }
-        product, err := r.GetProductByID(ctx, id)
-        if err != nil {
+        if err := scanProductPrice(&product, price); err != nil {
             return nil, err
         }
-        products = append(products, product)
+        products = append(products, &product)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
     }
 
+    if err := r.hydrate(ctx, products); err != nil {
+        return nil, err
+    }
     return products, nil
 }
+
+// hydrate loads the child rows of every product in batches and stitches them together in memory
+func (r *MySQLProductRepository) hydrate(ctx context.Context, products []*models.Product) error {
+    for start := 0; start < len(products); start += hydrateBatchSize {
+        end := start + hydrateBatchSize
+        if end > len(products) {
+            end = len(products)
+        }
+        batch := products[start:end]
+
+        byID := make(map[string]*models.Product, len(batch))
+        ids := make([]interface{}, 0, len(batch))
+        for _, product := range batch {
+            byID[product.ID] = product
+            ids = append(ids, product.ID)
+        }
+        in := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ") + ")"
+
+        err := r.eachRow(ctx, `SELECT product_id, color FROM product_attributes WHERE product_id IN `+in, ids,
+            func(product *models.Product, value string) { product.Attributes.Color = value }, byID)
+        if err != nil {
+            return err
+        }
+
+        err = r.eachRow(ctx, `SELECT product_id, size FROM product_sizes WHERE product_id IN `+in+` ORDER BY id`, ids,
+            func(product *models.Product, value string) { product.Attributes.Size = append(product.Attributes.Size, value) }, byID)
+        if err != nil {
+            return err
+        }
+
+        err = r.eachRow(ctx, `SELECT product_id, image_url FROM product_images WHERE product_id IN `+in+` ORDER BY id`, ids,
+            func(product *models.Product, value string) { product.Images = append(product.Images, value) }, byID)
+        if err != nil {
+            return err
+        }
+
+        if err := r.loadTranslations(ctx, in, ids, byID); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// loadTranslations sets the translations of the products whose IDs are in
+// the parenthesised list of placeholders in
+func (r *MySQLProductRepository) loadTranslations(ctx context.Context, in string, ids []interface{}, byID map[string]*models.Product) error {
+    rows, err := r.DB.QueryContext(ctx, `
+        SELECT product_id, locale, title, description FROM product_translations WHERE product_id IN `+in, ids...)
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var productID, locale string
+        var t models.Translation
+        if err := rows.Scan(&productID, &locale, &t.Title, &t.Description); err != nil {
+            return err
+        }
+        product, ok := byID[productID]
+        if !ok {
+            continue
+        }
+        if product.Translations == nil {
+            product.Translations = make(models.Translations)
+        }
+        product.Translations[locale] = t
+    }
+    return rows.Err()
+}
+
+// eachRow runs a (product_id, value) query and applies every row to its product
+func (r *MySQLProductRepository) eachRow(ctx context.Context, stmt string, args []interface{}, apply func(*models.Product, string), byID map[string]*models.Product) error {
+    rows, err := r.DB.QueryContext(ctx, stmt, args...)
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var productID, value string
+        if err := rows.Scan(&productID, &value); err != nil {
+            return err
+        }
+        if product, ok := byID[productID]; ok {
+            apply(product, value)
+        }
+    }
+    return rows.Err()
+}
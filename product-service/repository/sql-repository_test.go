@@ -0,0 +1,320 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "reflect"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+)
+
+// roundTrip simulates the latency of one query against a remote MySQL server
+const roundTrip = 50 * time.Microsecond
+
+var productColumns = []string{"id", "sku", "title", "description", "price", "currency", "stock", "category", "category_id", "ratings", "review_count", "version", "created_at", "deleted_at"}
+
+func productRows(ids []string) *sqlmock.Rows {
+    rows := sqlmock.NewRows(productColumns)
+    for _, id := range ids {
+        rows.AddRow(id, "", "Title "+id, "Description", "9.9900", "USD", 3, "shirts", "c-1", 4.5, 2, 1, time.Unix(0, 0), nil)
+    }
+    return rows
+}
+
+func childRows(column string, ids []string, values ...string) *sqlmock.Rows {
+    rows := sqlmock.NewRows([]string{"product_id", column})
+    for _, id := range ids {
+        for _, v := range values {
+            rows.AddRow(id, v)
+        }
+    }
+    return rows
+}
+
+func productIDs(n int) []string {
+    ids := make([]string, n)
+    for i := range ids {
+        ids[i] = fmt.Sprintf("p-%04d", i)
+    }
+    return ids
+}
+
+// translationRows returns a French translation of every product
+func translationRows(ids []string) *sqlmock.Rows {
+    rows := sqlmock.NewRows([]string{"product_id", "locale", "title", "description"})
+    for _, id := range ids {
+        rows.AddRow(id, "fr", "Titre "+id, "")
+    }
+    return rows
+}
+
+// expectBatched queues the five queries issued by listProducts
+func expectBatched(mock sqlmock.Sqlmock, ids []string) {
+    mock.ExpectQuery("FROM products WHERE category").WillReturnRows(productRows(ids)).WillDelayFor(roundTrip)
+    mock.ExpectQuery("FROM product_attributes").WillReturnRows(childRows("color", ids, "red")).WillDelayFor(roundTrip)
+    mock.ExpectQuery("FROM product_sizes").WillReturnRows(childRows("size", ids, "S", "M")).WillDelayFor(roundTrip)
+    mock.ExpectQuery("FROM product_images").WillReturnRows(childRows("image_url", ids, "a.png")).WillDelayFor(roundTrip)
+    mock.ExpectQuery("FROM product_translations").WillReturnRows(translationRows(ids)).WillDelayFor(roundTrip)
+}
+
+func newMockRepository(tb testing.TB) (*MySQLProductRepository, sqlmock.Sqlmock) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        tb.Fatalf("sqlmock.New() error = %v", err)
+    }
+    tb.Cleanup(func() { db.Close() })
+    return NewMySQLProductRepository(db), mock
+}
+
+// queryCounter counts the queries a mocked database runs. sqlmock matches
+// each query it is sent against the next expectation exactly once, so the
+// successful matches are the queries run.
+type queryCounter struct {
+    n int
+}
+
+func (c *queryCounter) Match(expectedSQL, actualSQL string) error {
+    if err := sqlmock.QueryMatcherRegexp.Match(expectedSQL, actualSQL); err != nil {
+        return err
+    }
+    c.n++
+    return nil
+}
+
+// newCountingMockRepository is newMockRepository counting the queries run
+func newCountingMockRepository(tb testing.TB) (*MySQLProductRepository, sqlmock.Sqlmock, *queryCounter) {
+    counter := &queryCounter{}
+    db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(counter))
+    if err != nil {
+        tb.Fatalf("sqlmock.New() error = %v", err)
+    }
+    tb.Cleanup(func() { db.Close() })
+    return NewMySQLProductRepository(db), mock, counter
+}
+
+func TestGetProductsByCategoryHydratesInBatches(t *testing.T) {
+    repo, mock, queries := newCountingMockRepository(t)
+    ids := productIDs(3)
+    expectBatched(mock, ids)
+
+    products, err := repo.GetProductsByCategory(context.Background(), "shirts", nil, nil)
+    if err != nil {
+        t.Fatalf("GetProductsByCategory() error = %v", err)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Fatalf("unexpected queries: %v", err)
+    }
+    if queries.n != 5 {
+        t.Errorf("GetProductsByCategory() ran %d queries, want 5 whatever the page size", queries.n)
+    }
+
+    if len(products) != len(ids) {
+        t.Fatalf("GetProductsByCategory() returned %d products, want %d", len(products), len(ids))
+    }
+    for i, product := range products {
+        if product.ID != ids[i] {
+            t.Errorf("product %d has ID %q, want %q", i, product.ID, ids[i])
+        }
+        if product.Attributes.Color != "red" {
+            t.Errorf("product %s color = %q, want red", product.ID, product.Attributes.Color)
+        }
+        if !reflect.DeepEqual(product.Attributes.Size, []string{"S", "M"}) {
+            t.Errorf("product %s sizes = %v, want [S M]", product.ID, product.Attributes.Size)
+        }
+        if !reflect.DeepEqual(product.Images, []string{"a.png"}) {
+            t.Errorf("product %s images = %v, want [a.png]", product.ID, product.Images)
+        }
+        want := models.Translations{"fr": {Title: "Titre " + product.ID}}
+        if !reflect.DeepEqual(product.Translations, want) {
+            t.Errorf("product %s translations = %v, want %v", product.ID, product.Translations, want)
+        }
+    }
+}
+
+// BenchmarkCategoryPage compares batched hydration of a 500-item category page
+// with the previous approach of selecting IDs and loading each product separately.
+// Each mocked query sleeps for roundTrip, so ns/op tracks the number of round
+// trips; queries/op counts the queries each approach actually ran.
+func BenchmarkCategoryPage(b *testing.B) {
+    ids := productIDs(500)
+    ctx := context.Background()
+
+    b.Run("batched", func(b *testing.B) {
+        queries := 0
+        for i := 0; i < b.N; i++ {
+            b.StopTimer()
+            repo, mock, counter := newCountingMockRepository(b)
+            expectBatched(mock, ids)
+            b.StartTimer()
+
+            if _, err := repo.GetProductsByCategory(ctx, "shirts", nil, nil); err != nil {
+                b.Fatal(err)
+            }
+            queries += counter.n
+        }
+        b.ReportMetric(float64(queries)/float64(b.N), "queries/op")
+    })
+
+    b.Run("per_product", func(b *testing.B) {
+        queries := 0
+        for i := 0; i < b.N; i++ {
+            b.StopTimer()
+            repo, mock, counter := newCountingMockRepository(b)
+            idRows := sqlmock.NewRows([]string{"id"})
+            for _, id := range ids {
+                idRows.AddRow(id)
+            }
+            mock.ExpectQuery("SELECT id FROM products WHERE category").WillReturnRows(idRows).WillDelayFor(roundTrip)
+            for _, id := range ids {
+                one := []string{id}
+                mock.ExpectQuery("FROM products WHERE id").WillReturnRows(productRows(one)).WillDelayFor(roundTrip)
+                mock.ExpectQuery("FROM product_attributes").WillReturnRows(sqlmock.NewRows([]string{"color"}).AddRow("red")).WillDelayFor(roundTrip)
+                mock.ExpectQuery("FROM product_sizes").WillReturnRows(sqlmock.NewRows([]string{"size"}).AddRow("S").AddRow("M")).WillDelayFor(roundTrip)
+                mock.ExpectQuery("FROM product_images").WillReturnRows(sqlmock.NewRows([]string{"image_url"}).AddRow("a.png")).WillDelayFor(roundTrip)
+                mock.ExpectQuery("FROM product_translations").WillReturnRows(translationRows(one)).WillDelayFor(roundTrip)
+            }
+            b.StartTimer()
+
+            if err := loadPerProduct(ctx, repo, repo.DB); err != nil {
+                b.Fatal(err)
+            }
+            queries += counter.n
+        }
+        b.ReportMetric(float64(queries)/float64(b.N), "queries/op")
+    })
+}
+
+// loadPerProduct reproduces the N+1 access pattern the repository used to have
+func loadPerProduct(ctx context.Context, repo *MySQLProductRepository, db *sql.DB) error {
+    rows, err := db.QueryContext(ctx, `SELECT id FROM products WHERE category = ?`, "shirts")
+    if err != nil {
+        return err
+    }
+    var ids []string
+    for rows.Next() {
+        var id string
+        if err := rows.Scan(&id); err != nil {
+            return err
+        }
+        ids = append(ids, id)
+    }
+    rows.Close()
+
+    for _, id := range ids {
+        if _, err := repo.GetProductByID(ctx, id); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func TestPurgeProduct(t *testing.T) {
+    tests := []struct {
+        name      string
+        deletedAt interface{}
+        missing   bool
+        want      bool
+        wantErr   error
+    }{
+        {name: "archived", deletedAt: time.Unix(0, 0), want: true},
+        {name: "restored in the meantime", deletedAt: nil},
+        {name: "missing", missing: true, wantErr: ErrProductNotFound},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            repo, mock := newMockRepository(t)
+            mock.ExpectBegin()
+            rows := sqlmock.NewRows([]string{"version", "deleted_at"})
+            if !tt.missing {
+                rows.AddRow(4, tt.deletedAt)
+            }
+            mock.ExpectQuery("SELECT version, deleted_at FROM products WHERE id = \\? FOR UPDATE").WithArgs("p-1").WillReturnRows(rows)
+            if tt.want {
+                for _, table := range []string{"product_attributes", "product_sizes", "product_images", "product_translations", "product_media", "products"} {
+                    mock.ExpectExec("DELETE FROM " + table + " WHERE").WithArgs("p-1").WillReturnResult(sqlmock.NewResult(0, 1))
+                }
+                expectEvent(mock, "p-1", models.EventProductDeleted)
+                mock.ExpectCommit()
+            } else {
+                mock.ExpectRollback()
+            }
+
+            purged, err := repo.PurgeProduct(context.Background(), "p-1")
+            if !errors.Is(err, tt.wantErr) {
+                t.Fatalf("PurgeProduct() error = %v, want %v", err, tt.wantErr)
+            }
+            if purged != tt.want {
+                t.Errorf("PurgeProduct() = %t, want %t", purged, tt.want)
+            }
+            if err := mock.ExpectationsWereMet(); err != nil {
+                t.Errorf("unexpected queries: %v", err)
+            }
+        })
+    }
+}
+
+func TestUpdateProductWritesMaskedFields(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    product := &models.Product{
+        Title:      "New title",
+        Price:      money.New(1950, "USD"),
+        Attributes: models.ProductAttributes{Color: "red", Size: []string{"S", "M"}},
+        Images:     []string{"a.jpg"},
+        Version:    2,
+    }
+
+    mock.ExpectBegin()
+    mock.ExpectQuery("SELECT version, deleted_at FROM products WHERE id = \\? FOR UPDATE").WithArgs("p-1").
+        WillReturnRows(sqlmock.NewRows([]string{"version", "deleted_at"}).AddRow(2, nil))
+    mock.ExpectExec("UPDATE products SET version = version \\+ 1, title = \\?, price = \\?, currency = \\? WHERE id = \\?").
+        WithArgs("New title", "19.50", "USD", "p-1").WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("DELETE FROM product_sizes WHERE product_id = \\?").WithArgs("p-1").WillReturnResult(sqlmock.NewResult(0, 3))
+    mock.ExpectExec("INSERT INTO product_sizes").WithArgs("p-1", "S").WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("INSERT INTO product_sizes").WithArgs("p-1", "M").WillReturnResult(sqlmock.NewResult(0, 1))
+    expectEvent(mock, "p-1", models.EventProductUpdated)
+    mock.ExpectCommit()
+
+    // the color, images, stock and other columns are not in the mask and stay as they are
+    mask := models.NewProductMask(models.ProductTitle, models.ProductPrice, models.ProductSizes)
+    if err := repo.UpdateProduct(context.Background(), "p-1", product, mask); err != nil {
+        t.Fatalf("UpdateProduct() error = %v", err)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
+
+func TestUpdateProductReplacesTranslations(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    product := &models.Product{
+        Translations: models.Translations{
+            "fr-CA": {Title: "Manteau de pluie"},
+            "de":    {Title: "Regenjacke", Description: "Hält trocken"},
+        },
+        Version: 3,
+    }
+
+    mock.ExpectBegin()
+    mock.ExpectQuery("SELECT version, deleted_at FROM products WHERE id = \\? FOR UPDATE").WithArgs("p-1").
+        WillReturnRows(sqlmock.NewRows([]string{"version", "deleted_at"}).AddRow(3, nil))
+    mock.ExpectExec("UPDATE products SET version = version \\+ 1 WHERE id = \\?").WithArgs("p-1").WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("DELETE FROM product_translations WHERE product_id = \\?").WithArgs("p-1").WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("INSERT INTO product_translations").WithArgs("p-1", "de", "Regenjacke", "Hält trocken").WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("INSERT INTO product_translations").WithArgs("p-1", "fr-CA", "Manteau de pluie", "").WillReturnResult(sqlmock.NewResult(0, 1))
+    expectEvent(mock, "p-1", models.EventProductUpdated)
+    mock.ExpectCommit()
+
+    mask := models.NewProductMask(models.ProductTranslations)
+    if err := repo.UpdateProduct(context.Background(), "p-1", product, mask); err != nil {
+        t.Fatalf("UpdateProduct() error = %v", err)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "sort"
+    "time"
+
+    "github.com/go-sql-driver/mysql"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// MySQL error numbers
+const (
+    mysqlDuplicateEntry  = 1062 // a unique key violation
+    mysqlNoReferencedRow = 1452 // a foreign key pointing at a missing row
+)
+
+func (r *MySQLProductRepository) ReserveStock(ctx context.Context, reservation *models.Reservation) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var referenceID interface{}
+    if reservation.ReferenceID != "" {
+        referenceID = reservation.ReferenceID
+    }
+    _, err = tx.ExecContext(ctx, `
+        INSERT INTO stock_reservations (id, reference_id, status, expires_at, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?)`,
+        reservation.ID, referenceID, reservation.Status, reservation.ExpiresAt,
+        reservation.CreatedAt, reservation.CreatedAt,
+    )
+    var mysqlErr *mysql.MySQLError
+    if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+        return ErrDuplicateReservation
+    }
+    if err != nil {
+        return err
+    }
+
+    // take stock in a fixed order so concurrent reservations cannot deadlock
+    items := append([]models.ReservationItem(nil), reservation.Items...)
+    sort.Slice(items, func(i, j int) bool {
+        if items[i].ProductID != items[j].ProductID {
+            return items[i].ProductID < items[j].ProductID
+        }
+        if items[i].VariantID != items[j].VariantID {
+            return items[i].VariantID < items[j].VariantID
+        }
+        return items[i].WarehouseID < items[j].WarehouseID
+    })
+
+    for _, item := range items {
+        if err := takeStock(ctx, tx, reservation.ID, item); err != nil {
+            return err
+        }
+        _, err = tx.ExecContext(ctx, `
+            INSERT INTO stock_reservation_items (reservation_id, product_id, variant_id, warehouse_id, quantity)
+            VALUES (?, ?, ?, ?, ?)`,
+            reservation.ID, item.ProductID, item.VariantID, item.WarehouseID, item.Quantity,
+        )
+        if err != nil {
+            return err
+        }
+    }
+
+    return tx.Commit()
+}
+
+// reservationChange describes stock held or given back by a reservation
+func reservationChange(reservationID, warehouseID string) models.StockChange {
+    return models.StockChange{
+        WarehouseID: warehouseID,
+        Reason:      models.StockReservation,
+        Actor:       models.SystemActor,
+        ReferenceID: reservationID,
+    }
+}
+
+// takeStock decrements the stock of an item in its warehouse only if enough is available
+func takeStock(ctx context.Context, tx *sql.Tx, reservationID string, item models.ReservationItem) error {
+    change := reservationChange(reservationID, item.WarehouseID)
+    return moveStock(ctx, tx, newMovement(item.ProductID, item.VariantID, -item.Quantity, change))
+}
+
+// returnStock gives the stock of an item back to its warehouse
+func returnStock(ctx context.Context, tx *sql.Tx, reservationID string, item models.ReservationItem) error {
+    change := reservationChange(reservationID, item.WarehouseID)
+    return moveStock(ctx, tx, newMovement(item.ProductID, item.VariantID, item.Quantity, change))
+}
+
+func (r *MySQLProductRepository) GetReservation(ctx context.Context, id string) (*models.Reservation, error) {
+    return getReservation(ctx, r.DB, "id = ?", id)
+}
+
+func (r *MySQLProductRepository) GetReservationByReference(ctx context.Context, referenceID string) (*models.Reservation, error) {
+    return getReservation(ctx, r.DB, "reference_id = ?", referenceID)
+}
+
+func (r *MySQLProductRepository) CommitReservation(ctx context.Context, id string, now time.Time) (*models.Reservation, error) {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback()
+
+    reservation, err := getReservation(ctx, tx, "id = ? FOR UPDATE", id)
+    if err != nil {
+        return nil, err
+    }
+    switch {
+    case reservation.Status == models.ReservationCommitted:
+        return reservation, nil
+    case reservation.Status != models.ReservationPending:
+        return nil, fmt.Errorf("%w: it is %s", ErrReservationClosed, reservation.Status)
+    case !now.Before(reservation.ExpiresAt):
+        return nil, fmt.Errorf("%w: it expired at %s", ErrReservationClosed, reservation.ExpiresAt.Format(time.RFC3339))
+    }
+
+    if err := setReservationStatus(ctx, tx, reservation, models.ReservationCommitted, now); err != nil {
+        return nil, err
+    }
+    return reservation, tx.Commit()
+}
+
+func (r *MySQLProductRepository) ReleaseReservation(ctx context.Context, id string, status models.ReservationStatus) (*models.Reservation, error) {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback()
+
+    reservation, err := getReservation(ctx, tx, "id = ? FOR UPDATE", id)
+    if err != nil {
+        return nil, err
+    }
+    switch reservation.Status {
+    case models.ReservationReleased, models.ReservationExpired:
+        return reservation, nil
+    case models.ReservationCommitted:
+        return nil, fmt.Errorf("%w: it is %s", ErrReservationClosed, reservation.Status)
+    }
+
+    for _, item := range reservation.Items {
+        if err := returnStock(ctx, tx, reservation.ID, item); err != nil {
+            return nil, err
+        }
+    }
+    if err := setReservationStatus(ctx, tx, reservation, status, time.Now().UTC()); err != nil {
+        return nil, err
+    }
+    return reservation, tx.Commit()
+}
+
+func (r *MySQLProductRepository) ExpiredReservations(ctx context.Context, now time.Time, limit int) ([]string, error) {
+    rows, err := r.DB.QueryContext(ctx, `
+        SELECT id FROM stock_reservations
+        WHERE status = ? AND expires_at <= ?
+        ORDER BY expires_at LIMIT ?`, models.ReservationPending, now, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var ids []string
+    for rows.Next() {
+        var id string
+        if err := rows.Scan(&id); err != nil {
+            return nil, err
+        }
+        ids = append(ids, id)
+    }
+    return ids, rows.Err()
+}
+
+func setReservationStatus(ctx context.Context, tx *sql.Tx, reservation *models.Reservation, status models.ReservationStatus, now time.Time) error {
+    _, err := tx.ExecContext(ctx, `
+        UPDATE stock_reservations SET status = ?, updated_at = ? WHERE id = ?`, status, now, reservation.ID)
+    if err != nil {
+        return err
+    }
+    reservation.Status = status
+    return nil
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+    QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+    QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// getReservation loads one reservation and its items; where may end in FOR UPDATE
+func getReservation(ctx context.Context, q queryer, where string, args ...interface{}) (*models.Reservation, error) {
+    var reservation models.Reservation
+    var referenceID sql.NullString
+    err := q.QueryRowContext(ctx, `
+        SELECT id, reference_id, status, expires_at, created_at
+        FROM stock_reservations WHERE `+where, args...).
+        Scan(&reservation.ID, &referenceID, &reservation.Status, &reservation.ExpiresAt, &reservation.CreatedAt)
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil, ErrReservationNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    reservation.ReferenceID = referenceID.String
+
+    rows, err := q.QueryContext(ctx, `
+        SELECT product_id, variant_id, warehouse_id, quantity FROM stock_reservation_items
+        WHERE reservation_id = ? ORDER BY id`, reservation.ID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var item models.ReservationItem
+        if err := rows.Scan(&item.ProductID, &item.VariantID, &item.WarehouseID, &item.Quantity); err != nil {
+            return nil, err
+        }
+        reservation.Items = append(reservation.Items, item)
+    }
+    return &reservation, rows.Err()
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+func TestReserveStockRollsBackOnInsufficientStock(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    now := time.Unix(0, 0)
+    reservation := &models.Reservation{
+        ID:        "r-1",
+        Status:    models.ReservationPending,
+        ExpiresAt: now.Add(time.Minute),
+        CreatedAt: now,
+        Items: []models.ReservationItem{
+            {ProductID: "p-2", WarehouseID: "berlin", Quantity: 5},
+            {ProductID: "p-1", WarehouseID: "paris", Quantity: 1},
+        },
+    }
+
+    mock.ExpectBegin()
+    mock.ExpectExec("INSERT INTO stock_reservations").
+        WithArgs("r-1", nil, models.ReservationPending, reservation.ExpiresAt, now, now).
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    // items are taken in product order, so p-1 comes first
+    mock.ExpectExec("UPDATE warehouse_stock SET quantity = quantity \\+ \\?").
+        WithArgs(-1, "paris", "p-1", "", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("UPDATE products SET stock = stock \\+ \\?").
+        WithArgs(-1, "p-1").WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("INSERT INTO stock_movements").
+        WithArgs("p-1", "", "paris", -1, models.StockReservation, models.SystemActor, "r-1", sqlmock.AnyArg()).
+        WillReturnResult(sqlmock.NewResult(1, 1))
+    expectEvent(mock, "p-1", models.EventStockChanged)
+    mock.ExpectExec("INSERT INTO stock_reservation_items").
+        WithArgs("r-1", "p-1", "", "paris", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+    mock.ExpectExec("UPDATE warehouse_stock SET quantity = quantity \\+ \\?").
+        WithArgs(-5, "berlin", "p-2", "", 5).WillReturnResult(sqlmock.NewResult(0, 0))
+    mock.ExpectRollback()
+
+    err := repo.ReserveStock(context.Background(), reservation)
+    if !errors.Is(err, ErrInsufficientStock) {
+        t.Fatalf("ReserveStock() error = %v, want ErrInsufficientStock", err)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
+
+func TestCommitReservation(t *testing.T) {
+    now := time.Unix(1000, 0)
+    tests := []struct {
+        name       string
+        status     models.ReservationStatus
+        expiresAt  time.Time
+        wantErr    error
+        wantUpdate bool
+    }{
+        {"pending", models.ReservationPending, now.Add(time.Minute), nil, true},
+        {"already committed", models.ReservationCommitted, now.Add(time.Minute), nil, false},
+        {"expired by TTL", models.ReservationPending, now, ErrReservationClosed, false},
+        {"released", models.ReservationReleased, now.Add(time.Minute), ErrReservationClosed, false},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            repo, mock := newMockRepository(t)
+            mock.ExpectBegin()
+            mock.ExpectQuery("FROM stock_reservations WHERE id = \\? FOR UPDATE").WithArgs("r-1").WillReturnRows(
+                sqlmock.NewRows([]string{"id", "reference_id", "status", "expires_at", "created_at"}).
+                    AddRow("r-1", nil, tt.status, tt.expiresAt, now))
+            mock.ExpectQuery("FROM stock_reservation_items").WithArgs("r-1").WillReturnRows(
+                sqlmock.NewRows([]string{"product_id", "variant_id", "warehouse_id", "quantity"}).AddRow("p-1", "", "default", 2))
+            if tt.wantUpdate {
+                mock.ExpectExec("UPDATE stock_reservations SET status").
+                    WithArgs(models.ReservationCommitted, now, "r-1").WillReturnResult(sqlmock.NewResult(0, 1))
+                mock.ExpectCommit()
+            } else {
+                mock.ExpectRollback()
+            }
+
+            reservation, err := repo.CommitReservation(context.Background(), "r-1", now)
+            if tt.wantErr != nil {
+                if !errors.Is(err, tt.wantErr) {
+                    t.Fatalf("CommitReservation() error = %v, want %v", err, tt.wantErr)
+                }
+            } else if err != nil {
+                t.Fatalf("CommitReservation() error = %v", err)
+            } else if reservation.Status != models.ReservationCommitted {
+                t.Errorf("Status = %s, want committed", reservation.Status)
+            }
+            if err := mock.ExpectationsWereMet(); err != nil {
+                t.Errorf("unexpected queries: %v", err)
+            }
+        })
+    }
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "time"
+
+    "github.com/go-sql-driver/mysql"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+const reviewColumns = `id, product_id, user_id, order_id, rating, title, body, status, helpful_votes, created_at, updated_at`
+
+func (r *MySQLProductRepository) CreateReview(ctx context.Context, review *models.Review) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    _, err = tx.ExecContext(ctx, `
+        INSERT INTO product_reviews (`+reviewColumns+`)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+        review.ID, review.ProductID, review.UserID, review.OrderID, review.Rating, review.Title, review.Body,
+        review.Status, review.HelpfulVotes, review.CreatedAt, review.UpdatedAt,
+    )
+    if err != nil {
+        var mysqlErr *mysql.MySQLError
+        if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+            return ErrDuplicateReview
+        }
+        return err
+    }
+
+    if review.Status == models.ReviewApproved {
+        if err := refreshRating(ctx, tx, review.ProductID); err != nil {
+            return err
+        }
+    }
+    return tx.Commit()
+}
+
+func (r *MySQLProductRepository) GetReview(ctx context.Context, id string) (*models.Review, error) {
+    review, err := scanReview(r.DB.QueryRowContext(ctx, `
+        SELECT `+reviewColumns+` FROM product_reviews WHERE id = ?`, id))
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil, ErrReviewNotFound
+    }
+    return review, err
+}
+
+func (r *MySQLProductRepository) ListReviews(ctx context.Context, productID string, status models.ReviewStatus, limit, offset int) ([]*models.Review, error) {
+    where := "1 = 1"
+    var args []interface{}
+    if productID != "" {
+        where += " AND product_id = ?"
+        args = append(args, productID)
+    }
+    if status != "" {
+        where += " AND status = ?"
+        args = append(args, status)
+    }
+    args = append(args, limit, offset)
+
+    rows, err := r.DB.QueryContext(ctx, `
+        SELECT `+reviewColumns+` FROM product_reviews
+        WHERE `+where+`
+        ORDER BY helpful_votes DESC, created_at DESC, id
+        LIMIT ? OFFSET ?`, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var reviews []*models.Review
+    for rows.Next() {
+        review, err := scanReview(rows)
+        if err != nil {
+            return nil, err
+        }
+        reviews = append(reviews, review)
+    }
+    return reviews, rows.Err()
+}
+
+func (r *MySQLProductRepository) UpdateReview(ctx context.Context, id string, review *models.Review) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    productID, err := lockReview(ctx, tx, id)
+    if err != nil {
+        return err
+    }
+    _, err = tx.ExecContext(ctx, `
+        UPDATE product_reviews SET rating = ?, title = ?, body = ?, status = ?, updated_at = ?
+        WHERE id = ?`,
+        review.Rating, review.Title, review.Body, review.Status, review.UpdatedAt, id,
+    )
+    if err != nil {
+        return err
+    }
+    if err := refreshRating(ctx, tx, productID); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func (r *MySQLProductRepository) DeleteReview(ctx context.Context, id string) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    productID, err := lockReview(ctx, tx, id)
+    if err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, `DELETE FROM product_reviews WHERE id = ?`, id); err != nil {
+        return err
+    }
+    if err := refreshRating(ctx, tx, productID); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func (r *MySQLProductRepository) AddHelpfulVote(ctx context.Context, reviewID, userID string, at time.Time) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    _, err = tx.ExecContext(ctx, `
+        INSERT INTO product_review_votes (review_id, user_id, created_at)
+        VALUES (?, ?, ?)`, reviewID, userID, at)
+    if err != nil {
+        var mysqlErr *mysql.MySQLError
+        if errors.As(err, &mysqlErr) {
+            switch mysqlErr.Number {
+            case mysqlDuplicateEntry:
+                return nil
+            case mysqlNoReferencedRow:
+                return ErrReviewNotFound
+            }
+        }
+        return err
+    }
+
+    _, err = tx.ExecContext(ctx, `UPDATE product_reviews SET helpful_votes = helpful_votes + 1 WHERE id = ?`, reviewID)
+    if err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// lockReview locks a review for the rest of the transaction and returns the
+// product it belongs to
+func lockReview(ctx context.Context, tx *sql.Tx, id string) (string, error) {
+    var productID string
+    err := tx.QueryRowContext(ctx, `SELECT product_id FROM product_reviews WHERE id = ? FOR UPDATE`, id).Scan(&productID)
+    if errors.Is(err, sql.ErrNoRows) {
+        return "", ErrReviewNotFound
+    }
+    return productID, err
+}
+
+// refreshRating sets the rating and review count of a product from its
+// approved reviews. A product without any keeps the rating typed in by hand
+// before there were reviews, if it has one.
+func refreshRating(ctx context.Context, tx *sql.Tx, productID string) error {
+    _, err := tx.ExecContext(ctx, `
+        UPDATE products SET
+            ratings = COALESCE(
+                (SELECT AVG(rating) FROM product_reviews WHERE product_id = ? AND status = ?),
+                ratings_before_reviews, 0),
+            review_count = (SELECT COUNT(*) FROM product_reviews WHERE product_id = ? AND status = ?)
+        WHERE id = ?`,
+        productID, models.ReviewApproved, productID, models.ReviewApproved, productID,
+    )
+    return err
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+    Scan(dest ...interface{}) error
+}
+
+func scanReview(row rowScanner) (*models.Review, error) {
+    var review models.Review
+    err := row.Scan(&review.ID, &review.ProductID, &review.UserID, &review.OrderID, &review.Rating, &review.Title,
+        &review.Body, &review.Status, &review.HelpfulVotes, &review.CreatedAt, &review.UpdatedAt)
+    if err != nil {
+        return nil, err
+    }
+    return &review, nil
+}
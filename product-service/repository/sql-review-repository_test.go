@@ -0,0 +1,76 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "github.com/go-sql-driver/mysql"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+func TestUpdateReviewRefreshesRating(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    now := time.Unix(0, 0)
+    mock.ExpectBegin()
+    mock.ExpectQuery("SELECT product_id FROM product_reviews WHERE id = \\? FOR UPDATE").WithArgs("r-1").
+        WillReturnRows(sqlmock.NewRows([]string{"product_id"}).AddRow("p-1"))
+    mock.ExpectExec("UPDATE product_reviews SET").WithArgs(4, "Snug", "Fits well", models.ReviewApproved, now, "r-1").
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("UPDATE products SET\\s+ratings = .*review_count = ").
+        WithArgs("p-1", models.ReviewApproved, "p-1", models.ReviewApproved, "p-1").
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectCommit()
+
+    review := &models.Review{Rating: 4, Title: "Snug", Body: "Fits well", Status: models.ReviewApproved, UpdatedAt: now}
+    if err := repo.UpdateReview(context.Background(), "r-1", review); err != nil {
+        t.Fatalf("UpdateReview() error = %v", err)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
+
+func TestAddHelpfulVote(t *testing.T) {
+    tests := []struct {
+        name      string
+        insertErr error
+        counted   bool
+        wantErr   error
+    }{
+        {"first vote", nil, true, nil},
+        {"repeated vote", &mysql.MySQLError{Number: mysqlDuplicateEntry}, false, nil},
+        {"missing review", &mysql.MySQLError{Number: mysqlNoReferencedRow}, false, ErrReviewNotFound},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            repo, mock := newMockRepository(t)
+            now := time.Unix(0, 0)
+            mock.ExpectBegin()
+            insert := mock.ExpectExec("INSERT INTO product_review_votes").WithArgs("r-1", "bob", now)
+            if tt.insertErr != nil {
+                insert.WillReturnError(tt.insertErr)
+            } else {
+                insert.WillReturnResult(sqlmock.NewResult(0, 1))
+            }
+            if tt.counted {
+                mock.ExpectExec("UPDATE product_reviews SET helpful_votes = helpful_votes \\+ 1").WithArgs("r-1").
+                    WillReturnResult(sqlmock.NewResult(0, 1))
+                mock.ExpectCommit()
+            } else {
+                mock.ExpectRollback()
+            }
+
+            err := repo.AddHelpfulVote(context.Background(), "r-1", "bob", now)
+            if !errors.Is(err, tt.wantErr) {
+                t.Fatalf("AddHelpfulVote() error = %v, want %v", err, tt.wantErr)
+            }
+            if err := mock.ExpectationsWereMet(); err != nil {
+                t.Errorf("unexpected queries: %v", err)
+            }
+        })
+    }
+}
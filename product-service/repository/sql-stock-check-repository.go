@@ -0,0 +1,34 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+func (r *MySQLProductRepository) GetStockCheck(ctx context.Context, orderID string) (*models.StockCheck, error) {
+    var check models.StockCheck
+    err := r.DB.QueryRowContext(ctx, `
+        SELECT order_id, stock_available, reservation_id, reason, checked_at
+        FROM order_stock_checks WHERE order_id = ?`, orderID).
+        Scan(&check.OrderID, &check.StockAvailable, &check.ReservationID, &check.Reason, &check.CheckedAt)
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil, ErrStockCheckNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &check, nil
+}
+
+func (r *MySQLProductRepository) RecordStockCheck(ctx context.Context, check *models.StockCheck) error {
+    // the first outcome recorded for an order wins
+    _, err := r.DB.ExecContext(ctx, `
+        INSERT INTO order_stock_checks (order_id, stock_available, reservation_id, reason, checked_at)
+        VALUES (?, ?, ?, ?, ?)
+        ON DUPLICATE KEY UPDATE order_id = order_id`,
+        check.OrderID, check.StockAvailable, check.ReservationID, check.Reason, check.CheckedAt)
+    return err
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestGetStockCheck(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    now := time.Unix(0, 0)
+    mock.ExpectQuery("SELECT order_id, .* FROM order_stock_checks WHERE order_id = \\?").WithArgs("42").
+        WillReturnRows(sqlmock.NewRows([]string{"order_id", "stock_available", "reservation_id", "reason", "checked_at"}).
+            AddRow("42", true, "r-1", "", now))
+    mock.ExpectQuery("SELECT order_id, .* FROM order_stock_checks WHERE order_id = \\?").WithArgs("43").
+        WillReturnRows(sqlmock.NewRows([]string{"order_id", "stock_available", "reservation_id", "reason", "checked_at"}))
+
+    check, err := repo.GetStockCheck(context.Background(), "42")
+    if err != nil {
+        t.Fatalf("GetStockCheck() error = %v", err)
+    }
+    if !check.StockAvailable || check.ReservationID != "r-1" {
+        t.Errorf("GetStockCheck() = %+v", check)
+    }
+    if _, err := repo.GetStockCheck(context.Background(), "43"); !errors.Is(err, ErrStockCheckNotFound) {
+        t.Errorf("GetStockCheck() of an unchecked order error = %v, want ErrStockCheckNotFound", err)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Errorf("unexpected queries: %v", err)
+    }
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "strings"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+func (r *MySQLProductRepository) CreateVariant(ctx context.Context, variant *models.Variant) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    _, err = tx.ExecContext(ctx, `
+        INSERT INTO product_variants (id, product_id, sku, price, currency, stock, created_at)
+        VALUES (?, ?, ?, ?, ?, 0, ?)`,
+        variant.ID, variant.ProductID, variant.SKU, priceDecimal(variant.Price), priceCurrency(variant.Price), variant.CreatedAt,
+    )
+    if err != nil {
+        return err
+    }
+
+    if err := insertVariantChildren(ctx, tx, variant); err != nil {
+        return err
+    }
+
+    // the initial stock is received into the default warehouse
+    if variant.Stock != 0 {
+        change := models.StockChange{WarehouseID: models.DefaultWarehouseID, Reason: models.StockReceiving, Actor: models.SystemActor}
+        if err := moveStock(ctx, tx, newMovement(variant.ProductID, variant.ID, variant.Stock, change)); err != nil {
+            return err
+        }
+    }
+    return tx.Commit()
+}
+
+// insertVariantChildren writes the option and image rows of a variant
+func insertVariantChildren(ctx context.Context, tx *sql.Tx, variant *models.Variant) error {
+    for name, value := range variant.Options {
+        _, err := tx.ExecContext(ctx, `
+            INSERT INTO product_variant_options (variant_id, name, value)
+            VALUES (?, ?, ?)`, variant.ID, name, value)
+        if err != nil {
+            return err
+        }
+    }
+
+    for _, img := range variant.Images {
+        _, err := tx.ExecContext(ctx, `
+            INSERT INTO product_variant_images (variant_id, image_url)
+            VALUES (?, ?)`, variant.ID, img)
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (r *MySQLProductRepository) GetVariantByID(ctx context.Context, id string) (*models.Variant, error) {
+    variants, err := r.listVariants(ctx, "id = ?", id)
+    if err != nil {
+        return nil, err
+    }
+    if len(variants) == 0 {
+        return nil, ErrVariantNotFound
+    }
+    return variants[0], nil
+}
+
+func (r *MySQLProductRepository) ListVariants(ctx context.Context, productID string) ([]*models.Variant, error) {
+    return r.listVariants(ctx, "product_id = ? ORDER BY created_at, id", productID)
+}
+
+func (r *MySQLProductRepository) UpdateVariant(ctx context.Context, id string, variant *models.Variant) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    change := models.StockChange{WarehouseID: models.DefaultWarehouseID, Reason: models.StockAdjustment, Actor: models.SystemActor}
+    if err := setVariantTotal(ctx, tx, id, variant.Stock, change); err != nil {
+        return err
+    }
+
+    _, err = tx.ExecContext(ctx, `
+        UPDATE product_variants SET sku = ?, price = ?, currency = ?
+        WHERE id = ?`,
+        variant.SKU, priceDecimal(variant.Price), priceCurrency(variant.Price), id,
+    )
+    if err != nil {
+        return err
+    }
+
+    _, err = tx.ExecContext(ctx, `DELETE FROM product_variant_options WHERE variant_id = ?`, id)
+    if err != nil {
+        return err
+    }
+    _, err = tx.ExecContext(ctx, `DELETE FROM product_variant_images WHERE variant_id = ?`, id)
+    if err != nil {
+        return err
+    }
+
+    updated := *variant
+    updated.ID = id
+    if err := insertVariantChildren(ctx, tx, &updated); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func (r *MySQLProductRepository) DeleteVariant(ctx context.Context, id string) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    result, err := tx.ExecContext(ctx, `DELETE FROM product_variants WHERE id = ?`, id)
+    if err != nil {
+        return err
+    }
+    if n, err := result.RowsAffected(); err == nil && n == 0 {
+        return ErrVariantNotFound
+    }
+    _, err = tx.ExecContext(ctx, `DELETE FROM warehouse_stock WHERE variant_id = ?`, id)
+    if err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// UpdateVariantStock sets the stock quantity of a variant in one warehouse and records the difference
+func (r *MySQLProductRepository) UpdateVariantStock(ctx context.Context, id string, quantity int, change models.StockChange) error {
+    tx, err := r.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if err := setVariantStock(ctx, tx, id, quantity, change); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// listVariants loads the variants matching a WHERE clause, then their options
+// and images with one grouped query each
+func (r *MySQLProductRepository) listVariants(ctx context.Context, where string, args ...interface{}) ([]*models.Variant, error) {
+    rows, err := r.DB.QueryContext(ctx, `
+        SELECT id, product_id, sku, price, currency, stock, created_at
+        FROM product_variants WHERE `+where, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var variants []*models.Variant
+    byID := make(map[string]*models.Variant)
+    for rows.Next() {
+        var variant models.Variant
+        var price sqlPrice
+        if err := rows.Scan(&variant.ID, &variant.ProductID, &variant.SKU, &price.amount, &price.currency,
+            &variant.Stock, &variant.CreatedAt); err != nil {
+            return nil, err
+        }
+        if variant.Price, err = price.money(); err != nil {
+            return nil, err
+        }
+        variant.Options = make(map[string]string)
+        variants = append(variants, &variant)
+        byID[variant.ID] = &variant
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+    if len(variants) == 0 {
+        return nil, nil
+    }
+
+    ids := make([]interface{}, 0, len(variants))
+    for _, variant := range variants {
+        ids = append(ids, variant.ID)
+    }
+    in := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ") + ")"
+
+    optionRows, err := r.DB.QueryContext(ctx, `
+        SELECT variant_id, name, value FROM product_variant_options WHERE variant_id IN `+in, ids...)
+    if err != nil {
+        return nil, err
+    }
+    defer optionRows.Close()
+    for optionRows.Next() {
+        var variantID, name, value string
+        if err := optionRows.Scan(&variantID, &name, &value); err != nil {
+            return nil, err
+        }
+        if variant, ok := byID[variantID]; ok {
+            variant.Options[name] = value
+        }
+    }
+    if err := optionRows.Err(); err != nil {
+        return nil, err
+    }
+
+    imageRows, err := r.DB.QueryContext(ctx, `
+        SELECT variant_id, image_url FROM product_variant_images WHERE variant_id IN `+in+` ORDER BY id`, ids...)
+    if err != nil {
+        return nil, err
+    }
+    defer imageRows.Close()
+    for imageRows.Next() {
+        var variantID, img string
+        if err := imageRows.Scan(&variantID, &img); err != nil {
+            return nil, err
+        }
+        if variant, ok := byID[variantID]; ok {
+            variant.Images = append(variant.Images, img)
+        }
+    }
+    return variants, imageRows.Err()
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "reflect"
+    "testing"
+    "time"
+
+    "github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestGetVariantByID(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    mock.ExpectQuery("FROM product_variants WHERE id").WithArgs("v-1").WillReturnRows(
+        sqlmock.NewRows([]string{"id", "product_id", "sku", "price", "currency", "stock", "created_at"}).
+            AddRow("v-1", "p-1", "TEE-RED-M", nil, nil, 4, time.Unix(0, 0)))
+    mock.ExpectQuery("FROM product_variant_options").WithArgs("v-1").WillReturnRows(
+        sqlmock.NewRows([]string{"variant_id", "name", "value"}).
+            AddRow("v-1", "color", "red").
+            AddRow("v-1", "size", "M"))
+    mock.ExpectQuery("FROM product_variant_images").WithArgs("v-1").WillReturnRows(
+        sqlmock.NewRows([]string{"variant_id", "image_url"}).AddRow("v-1", "red.png"))
+
+    variant, err := repo.GetVariantByID(context.Background(), "v-1")
+    if err != nil {
+        t.Fatalf("GetVariantByID() error = %v", err)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Fatalf("unexpected queries: %v", err)
+    }
+
+    if variant.ProductID != "p-1" || variant.SKU != "TEE-RED-M" || variant.Stock != 4 {
+        t.Errorf("GetVariantByID() = %+v", variant)
+    }
+    if variant.Price != nil {
+        t.Errorf("Price = %v, want nil for a NULL override", *variant.Price)
+    }
+    if want := map[string]string{"color": "red", "size": "M"}; !reflect.DeepEqual(variant.Options, want) {
+        t.Errorf("Options = %v, want %v", variant.Options, want)
+    }
+    if !reflect.DeepEqual(variant.Images, []string{"red.png"}) {
+        t.Errorf("Images = %v, want [red.png]", variant.Images)
+    }
+}
+
+func TestGetVariantByIDNotFound(t *testing.T) {
+    repo, mock := newMockRepository(t)
+    mock.ExpectQuery("FROM product_variants WHERE id").WithArgs("missing").WillReturnRows(
+        sqlmock.NewRows([]string{"id", "product_id", "sku", "price", "currency", "stock", "created_at"}))
+
+    if _, err := repo.GetVariantByID(context.Background(), "missing"); !errors.Is(err, ErrVariantNotFound) {
+        t.Errorf("GetVariantByID() error = %v, want ErrVariantNotFound", err)
+    }
+}
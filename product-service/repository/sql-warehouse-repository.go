@@ -0,0 +1,105 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "strings"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+func (r *MySQLProductRepository) CreateWarehouse(ctx context.Context, warehouse *models.Warehouse) error {
+    _, err := r.DB.ExecContext(ctx, `
+        INSERT INTO warehouses (id, name, address, latitude, longitude, active, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)`,
+        warehouse.ID, warehouse.Name, warehouse.Address, warehouse.Latitude,
+        warehouse.Longitude, warehouse.Active, warehouse.CreatedAt,
+    )
+    return err
+}
+
+func (r *MySQLProductRepository) GetWarehouse(ctx context.Context, id string) (*models.Warehouse, error) {
+    var warehouse models.Warehouse
+    err := r.DB.QueryRowContext(ctx, `
+        SELECT id, name, address, latitude, longitude, active, created_at
+        FROM warehouses WHERE id = ?`, id).
+        Scan(&warehouse.ID, &warehouse.Name, &warehouse.Address, &warehouse.Latitude,
+            &warehouse.Longitude, &warehouse.Active, &warehouse.CreatedAt)
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil, ErrWarehouseNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &warehouse, nil
+}
+
+func (r *MySQLProductRepository) ListWarehouses(ctx context.Context) ([]*models.Warehouse, error) {
+    rows, err := r.DB.QueryContext(ctx, `
+        SELECT id, name, address, latitude, longitude, active, created_at
+        FROM warehouses ORDER BY name, id`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var warehouses []*models.Warehouse
+    for rows.Next() {
+        var warehouse models.Warehouse
+        if err := rows.Scan(&warehouse.ID, &warehouse.Name, &warehouse.Address, &warehouse.Latitude,
+            &warehouse.Longitude, &warehouse.Active, &warehouse.CreatedAt); err != nil {
+            return nil, err
+        }
+        warehouses = append(warehouses, &warehouse)
+    }
+    return warehouses, rows.Err()
+}
+
+func (r *MySQLProductRepository) UpdateWarehouse(ctx context.Context, id string, warehouse *models.Warehouse) error {
+    result, err := r.DB.ExecContext(ctx, `
+        UPDATE warehouses SET name = ?, address = ?, latitude = ?, longitude = ?, active = ?
+        WHERE id = ?`,
+        warehouse.Name, warehouse.Address, warehouse.Latitude, warehouse.Longitude, warehouse.Active, id,
+    )
+    if err != nil {
+        return err
+    }
+    // MySQL reports no affected rows for an update that changes nothing, so
+    // only a missing row is an error
+    if n, err := result.RowsAffected(); err == nil && n == 0 {
+        if _, err := r.GetWarehouse(ctx, id); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (r *MySQLProductRepository) StockLevels(ctx context.Context, productIDs []string) ([]models.StockLevel, error) {
+    if len(productIDs) == 0 {
+        return nil, nil
+    }
+
+    args := make([]interface{}, len(productIDs))
+    for i, id := range productIDs {
+        args[i] = id
+    }
+    rows, err := r.DB.QueryContext(ctx, `
+        SELECT warehouse_id, product_id, variant_id, quantity FROM warehouse_stock
+        WHERE quantity <> 0 AND product_id IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")+`)
+        ORDER BY product_id, variant_id, warehouse_id`, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var levels []models.StockLevel
+    for rows.Next() {
+        var level models.StockLevel
+        if err := rows.Scan(&level.WarehouseID, &level.ProductID, &level.VariantID, &level.Quantity); err != nil {
+            return nil, err
+        }
+        levels = append(levels, level)
+    }
+    return levels, rows.Err()
+}
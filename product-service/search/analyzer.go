@@ -0,0 +1,268 @@
+package search
+
+import (
+    "strings"
+    "unicode"
+)
+
+// stopWords are dropped from documents and queries; they match almost every
+// product and would only add noise to the ranking
+var stopWords = map[string]bool{
+    "a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+    "be": true, "by": true, "for": true, "from": true, "in": true, "is": true,
+    "it": true, "of": true, "on": true, "or": true, "the": true, "to": true,
+    "with": true,
+}
+
+// Tokenize splits text into lower-cased runs of letters and digits
+func Tokenize(text string) []string {
+    return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    })
+}
+
+// Analyze turns text into the terms stored in the index: tokens with stop words
+// removed, reduced to their stems
+func Analyze(text string) []string {
+    tokens := Tokenize(text)
+    terms := make([]string, 0, len(tokens))
+    for _, token := range tokens {
+        if stopWords[token] {
+            continue
+        }
+        terms = append(terms, Stem(token))
+    }
+    return terms
+}
+
+// AnalyzeLocale analyzes text written in the language of a locale. English,
+// which is also assumed for a product's default content under the empty
+// locale, is stemmed and has its stop words removed; other languages are only
+// tokenized.
+func AnalyzeLocale(text, locale string) []string {
+    if english(locale) {
+        return Analyze(text)
+    }
+    return Tokenize(text)
+}
+
+// english reports whether the language of a locale is English
+func english(locale string) bool {
+    return locale == "" || locale == "en" || strings.HasPrefix(locale, "en-")
+}
+
+// Stem reduces an English word to its stem with the Porter algorithm. Words
+// that are short or contain anything but ASCII letters are returned unchanged.
+func Stem(word string) string {
+    if len(word) <= 2 {
+        return word
+    }
+    for i := 0; i < len(word); i++ {
+        if word[i] < 'a' || word[i] > 'z' {
+            return word
+        }
+    }
+
+    s := &stemmer{b: []byte(word)}
+    s.step1a()
+    s.step1b()
+    s.step1c()
+    s.step2()
+    s.step3()
+    s.step4()
+    s.step5()
+    return string(s.b)
+}
+
+type stemmer struct {
+    b []byte
+}
+
+// cons reports whether b[i] is a consonant; y is a consonant at the start of a
+// word or after a vowel
+func (s *stemmer) cons(i int) bool {
+    switch s.b[i] {
+    case 'a', 'e', 'i', 'o', 'u':
+        return false
+    case 'y':
+        return i == 0 || !s.cons(i-1)
+    }
+    return true
+}
+
+// measure counts the vowel-consonant sequences in b[:end]
+func (s *stemmer) measure(end int) int {
+    n, i := 0, 0
+    for i < end && s.cons(i) {
+        i++
+    }
+    for i < end {
+        for i < end && !s.cons(i) {
+            i++
+        }
+        if i >= end {
+            break
+        }
+        for i < end && s.cons(i) {
+            i++
+        }
+        n++
+    }
+    return n
+}
+
+// hasVowel reports whether b[:end] contains a vowel
+func (s *stemmer) hasVowel(end int) bool {
+    for i := 0; i < end; i++ {
+        if !s.cons(i) {
+            return true
+        }
+    }
+    return false
+}
+
+// doubleCons reports whether b[:end] ends in a double consonant
+func (s *stemmer) doubleCons(end int) bool {
+    return end >= 2 && s.b[end-1] == s.b[end-2] && s.cons(end-1)
+}
+
+// cvc reports whether b[:end] ends consonant-vowel-consonant where the final
+// consonant is not w, x or y, as in hop or fil
+func (s *stemmer) cvc(end int) bool {
+    if end < 3 || !s.cons(end-1) || s.cons(end-2) || !s.cons(end-3) {
+        return false
+    }
+    switch s.b[end-1] {
+    case 'w', 'x', 'y':
+        return false
+    }
+    return true
+}
+
+func (s *stemmer) ends(suffix string) bool {
+    return strings.HasSuffix(string(s.b), suffix)
+}
+
+// replace swaps suffix for repl when the remaining stem has a measure above min
+func (s *stemmer) replace(suffix, repl string, min int) bool {
+    if !s.ends(suffix) {
+        return false
+    }
+    stem := len(s.b) - len(suffix)
+    if s.measure(stem) > min {
+        s.b = append(s.b[:stem], repl...)
+    }
+    return true
+}
+
+func (s *stemmer) step1a() {
+    switch {
+    case s.ends("sses"), s.ends("ies"):
+        s.b = s.b[:len(s.b)-2]
+    case s.ends("ss"):
+    case s.ends("s"):
+        s.b = s.b[:len(s.b)-1]
+    }
+}
+
+func (s *stemmer) step1b() {
+    if s.ends("eed") {
+        if s.measure(len(s.b)-3) > 0 {
+            s.b = s.b[:len(s.b)-1]
+        }
+        return
+    }
+
+    var stem int
+    switch {
+    case s.ends("ed") && s.hasVowel(len(s.b)-2):
+        stem = len(s.b) - 2
+    case s.ends("ing") && s.hasVowel(len(s.b)-3):
+        stem = len(s.b) - 3
+    default:
+        return
+    }
+    s.b = s.b[:stem]
+
+    switch {
+    case s.ends("at"), s.ends("bl"), s.ends("iz"):
+        s.b = append(s.b, 'e')
+    case s.doubleCons(len(s.b)):
+        switch s.b[len(s.b)-1] {
+        case 'l', 's', 'z':
+        default:
+            s.b = s.b[:len(s.b)-1]
+        }
+    case s.measure(len(s.b)) == 1 && s.cvc(len(s.b)):
+        s.b = append(s.b, 'e')
+    }
+}
+
+func (s *stemmer) step1c() {
+    if s.ends("y") && s.hasVowel(len(s.b)-1) {
+        s.b[len(s.b)-1] = 'i'
+    }
+}
+
+var step2Suffixes = []struct{ suffix, repl string }{
+    {"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+    {"izer", "ize"}, {"bli", "ble"}, {"alli", "al"}, {"entli", "ent"},
+    {"eli", "e"}, {"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"},
+    {"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"},
+    {"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+    {"logi", "log"},
+}
+
+func (s *stemmer) step2() {
+    for _, r := range step2Suffixes {
+        if s.replace(r.suffix, r.repl, 0) {
+            return
+        }
+    }
+}
+
+var step3Suffixes = []struct{ suffix, repl string }{
+    {"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+    {"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+func (s *stemmer) step3() {
+    for _, r := range step3Suffixes {
+        if s.replace(r.suffix, r.repl, 0) {
+            return
+        }
+    }
+}
+
+var step4Suffixes = []string{
+    "al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+    "ent", "ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+func (s *stemmer) step4() {
+    for _, suffix := range step4Suffixes {
+        if !s.ends(suffix) {
+            continue
+        }
+        stem := len(s.b) - len(suffix)
+        if suffix == "ion" && (stem == 0 || (s.b[stem-1] != 's' && s.b[stem-1] != 't')) {
+            return
+        }
+        if s.measure(stem) > 1 {
+            s.b = s.b[:stem]
+        }
+        return
+    }
+}
+
+func (s *stemmer) step5() {
+    if s.ends("e") {
+        stem := len(s.b) - 1
+        if m := s.measure(stem); m > 1 || (m == 1 && !s.cvc(stem)) {
+            s.b = s.b[:stem]
+        }
+    }
+    if s.ends("ll") && s.measure(len(s.b)) > 1 {
+        s.b = s.b[:len(s.b)-1]
+    }
+}
@@ -0,0 +1,55 @@
+package search
+
+// maxEdits is the number of typos tolerated in a query term of the given length.
+// Short terms must match exactly, otherwise almost anything would match them.
+func maxEdits(term string) int {
+    switch n := len([]rune(term)); {
+    case n >= 8:
+        return 2
+    case n >= 4:
+        return 1
+    default:
+        return 0
+    }
+}
+
+// editDistance returns the optimal string alignment distance between a and b
+// (insertions, deletions, substitutions and adjacent transpositions), or
+// limit+1 once the distance is known to exceed limit
+func editDistance(a, b string, limit int) int {
+    ra, rb := []rune(a), []rune(b)
+    if d := len(ra) - len(rb); d > limit || -d > limit {
+        return limit + 1
+    }
+
+    prev2 := make([]int, len(rb)+1)
+    prev := make([]int, len(rb)+1)
+    cur := make([]int, len(rb)+1)
+    for j := range prev {
+        prev[j] = j
+    }
+
+    for i := 1; i <= len(ra); i++ {
+        cur[0] = i
+        rowMin := cur[0]
+        for j := 1; j <= len(rb); j++ {
+            cost := 1
+            if ra[i-1] == rb[j-1] {
+                cost = 0
+            }
+            cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+            if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+                cur[j] = min(cur[j], prev2[j-2]+1)
+            }
+            rowMin = min(rowMin, cur[j])
+        }
+        if rowMin > limit {
+            return limit + 1
+        }
+        prev2, prev, cur = prev, cur, prev2
+    }
+    if prev[len(rb)] > limit {
+        return limit + 1
+    }
+    return prev[len(rb)]
+}
@@ -0,0 +1,572 @@
+package search
+
+import (
+    "math"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/locale"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+    "github.com/samObot19/shopverse/product-service/pricing"
+    "github.com/samObot19/shopverse/product-service/query"
+)
+
+// BM25 parameters: term frequency saturation and document length normalisation
+const (
+    bm25K1 = 1.2
+    bm25B  = 0.75
+)
+
+// Field weights: a term in the title counts as much as three in the description
+const (
+    titleWeight       = 3
+    categoryWeight    = 2
+    colorWeight       = 2
+    descriptionWeight = 1
+)
+
+// fuzzyPenalty scales the score of a term matched through a typo
+const fuzzyPenalty = 0.5
+
+// Page size bounds for Request.Limit
+const (
+    DefaultLimit = 20
+    MaxLimit     = 100
+)
+
+// priceBounds are the lower bounds of the price facet buckets, in major units
+// of the currency of the search; the last bucket is open ended
+var priceBounds = []float64{0, 25, 50, 100, 250, 500}
+
+// document is the indexed form of a product: the weighted term frequencies
+// of its content in each locale plus the fields needed for facets and sorting
+type document struct {
+    id        string
+    texts     map[string]*text // Locale -> content; the empty locale holds the default content
+    title     string           // Default title, which suggestions complete to
+    category  string
+    color     string
+    sizes     []string
+    price     money.Money
+    ratings   float64
+    createdAt time.Time
+}
+
+// text is the content of a product in one locale, analyzed in its language
+type text struct {
+    terms  map[string]float64
+    length float64
+    title  string
+}
+
+// postings index the content of the products in one locale
+type postings struct {
+    terms       map[string]map[string]float64 // term -> product ID -> weighted term frequency
+    docs        int
+    totalLength float64
+}
+
+// Index is an in-memory inverted index over the product catalogue. Each
+// product is indexed in its default content and in every locale it is
+// translated into. It is safe for concurrent use.
+type Index struct {
+    mu          sync.RWMutex
+    docs        map[string]*document
+    locales     map[string]*postings // locale -> postings of the content in it
+    suggestions *trie
+}
+
+// NewIndex creates an empty Index
+func NewIndex() *Index {
+    return &Index{
+        docs:        make(map[string]*document),
+        locales:     make(map[string]*postings),
+        suggestions: newTrie(),
+    }
+}
+
+// Request describes a search. Sort overrides relevance ordering when set.
+// Locales is the fallback chain of the shopper, as locale.Chain gives it:
+// each product is matched, and sorted by title, in the first of them it is
+// translated into, or else in its default content.
+//
+// Prices are sorted and bucketed in Currency, models.DefaultCurrency unless
+// set, converted with Rates. Products priced in a currency Rates cannot
+// convert sort after the others, grouped by currency, and are left out of the
+// price facet.
+type Request struct {
+    Text     string
+    Locales  []string
+    Sort     []query.Sort
+    Limit    int
+    Offset   int
+    Currency string
+    Rates    *pricing.Rates
+}
+
+// Hit is a matching product and its relevance score
+type Hit struct {
+    ID     string
+    Score  float64
+    Locale string // Locale the product matched in; empty for its default content
+}
+
+// FacetValue is the number of matching products sharing a value
+type FacetValue struct {
+    Value string
+    Count int
+}
+
+// PriceBucket counts matching products priced in [Min, Max) of Currency; a
+// nil Max is unbounded
+type PriceBucket struct {
+    Min      float64
+    Max      *float64
+    Currency string
+    Count    int
+}
+
+// Facets summarise every product matching a search, not only the returned page
+type Facets struct {
+    Category []FacetValue
+    Color    []FacetValue
+    Size     []FacetValue
+    Price    []PriceBucket
+}
+
+// Result is one page of hits, the total number of matches and their facets
+type Result struct {
+    Hits   []Hit
+    Total  int
+    Facets Facets
+}
+
+// Len returns the number of indexed products
+func (idx *Index) Len() int {
+    idx.mu.RLock()
+    defer idx.mu.RUnlock()
+    return len(idx.docs)
+}
+
+// Add indexes a product, replacing any earlier version with the same ID
+func (idx *Index) Add(product *models.Product) {
+    doc := newDocument(product)
+
+    idx.mu.Lock()
+    defer idx.mu.Unlock()
+    idx.remove(product.ID)
+    idx.add(doc)
+}
+
+// Remove drops a product from the index
+func (idx *Index) Remove(id string) {
+    idx.mu.Lock()
+    defer idx.mu.Unlock()
+    idx.remove(id)
+}
+
+// Rebuild replaces the contents of the index with products
+func (idx *Index) Rebuild(products []*models.Product) {
+    docs := make([]*document, len(products))
+    for i, product := range products {
+        docs[i] = newDocument(product)
+    }
+
+    idx.mu.Lock()
+    defer idx.mu.Unlock()
+    idx.docs = make(map[string]*document, len(docs))
+    idx.locales = make(map[string]*postings)
+    idx.suggestions = newTrie()
+    for _, doc := range docs {
+        idx.add(doc)
+    }
+}
+
+func newDocument(product *models.Product) *document {
+    doc := &document{
+        id:        product.ID,
+        texts:     map[string]*text{"": newText(product, product.Title, product.Description, "")},
+        title:     product.Title,
+        category:  product.Category,
+        color:     product.Attributes.Color,
+        sizes:     append([]string(nil), product.Attributes.Size...),
+        price:     product.Price,
+        ratings:   product.Ratings,
+        createdAt: product.CreatedAt,
+    }
+    for tag := range product.Translations {
+        // an untranslated field falls back as it does when the product is read
+        title, description, _ := locale.Resolve(product, locale.Chain([]string{tag}))
+        doc.texts[tag] = newText(product, title, description, tag)
+    }
+    return doc
+}
+
+// newText analyzes the content of a product in a locale; the category and
+// color are not translated and are indexed in every locale
+func newText(product *models.Product, title, description, tag string) *text {
+    t := &text{terms: make(map[string]float64), title: title}
+    for _, field := range []struct {
+        text   string
+        weight float64
+    }{
+        {title, titleWeight},
+        {product.Category, categoryWeight},
+        {product.Attributes.Color, colorWeight},
+        {description, descriptionWeight},
+    } {
+        for _, term := range AnalyzeLocale(field.text, tag) {
+            t.terms[term] += field.weight
+            t.length += field.weight
+        }
+    }
+    return t
+}
+
+// resolve returns the first locale of chain the document has content in, or
+// the empty locale of its default content
+func (doc *document) resolve(chain []string) string {
+    for _, tag := range chain {
+        if _, ok := doc.texts[tag]; ok {
+            return tag
+        }
+    }
+    return ""
+}
+
+func (idx *Index) add(doc *document) {
+    idx.docs[doc.id] = doc
+    for tag, t := range doc.texts {
+        p, ok := idx.locales[tag]
+        if !ok {
+            p = &postings{terms: make(map[string]map[string]float64)}
+            idx.locales[tag] = p
+        }
+        p.docs++
+        p.totalLength += t.length
+        for term, tf := range t.terms {
+            posting, ok := p.terms[term]
+            if !ok {
+                posting = make(map[string]float64)
+                p.terms[term] = posting
+            }
+            posting[doc.id] = tf
+        }
+    }
+    idx.suggestions.add(doc)
+}
+
+func (idx *Index) remove(id string) {
+    doc, ok := idx.docs[id]
+    if !ok {
+        return
+    }
+    delete(idx.docs, id)
+    for tag, t := range doc.texts {
+        p := idx.locales[tag]
+        p.docs--
+        p.totalLength -= t.length
+        for term := range t.terms {
+            delete(p.terms[term], id)
+            if len(p.terms[term]) == 0 {
+                delete(p.terms, term)
+            }
+        }
+        if p.docs == 0 {
+            delete(idx.locales, tag)
+        }
+    }
+    idx.suggestions.remove(doc)
+}
+
+// Suggest completes prefix to at most limit product titles and categories,
+// most popular first
+func (idx *Index) Suggest(prefix string, limit int) []Suggestion {
+    idx.mu.RLock()
+    defer idx.mu.RUnlock()
+    return idx.suggestions.complete(prefix, limit)
+}
+
+// expansion is an index term standing in for a query term, with the weight it scores at
+type expansion struct {
+    term   string
+    weight float64
+}
+
+// Search ranks the products matching every term of req.Text by BM25. A query
+// term also matches index terms within a small edit distance, at a reduced
+// score. Each product is matched in the locale req.Locales resolves it to,
+// against the query analyzed in the language of that locale.
+func (idx *Index) Search(req Request) Result {
+    limit := req.Limit
+    if limit <= 0 {
+        limit = DefaultLimit
+    }
+    if limit > MaxLimit {
+        limit = MaxLimit
+    }
+    offset := max(req.Offset, 0)
+
+    idx.mu.RLock()
+    defer idx.mu.RUnlock()
+
+    // every product resolves to exactly one locale of the chain, so the
+    // matches in each locale are disjoint
+    chain := dedupe(append(append([]string(nil), req.Locales...), ""))
+    var hits []Hit
+    analyzed := false
+    for _, tag := range chain {
+        terms := dedupe(AnalyzeLocale(req.Text, tag))
+        if len(terms) == 0 {
+            continue
+        }
+        analyzed = true
+        for id, score := range idx.searchLocale(terms, tag, chain) {
+            hits = append(hits, Hit{ID: id, Score: score, Locale: tag})
+        }
+    }
+    if !analyzed || len(idx.docs) == 0 {
+        return Result{}
+    }
+    currency := req.Currency
+    if currency == "" {
+        currency = models.DefaultCurrency
+    }
+    rates := req.Rates
+    if rates == nil {
+        rates, _ = pricing.NewRates(nil)
+    }
+    prices := idx.prices(hits, currency, rates)
+    idx.order(hits, req.Sort, prices)
+
+    result := Result{Total: len(hits), Facets: idx.facets(hits, currency, prices)}
+    if offset < len(hits) {
+        result.Hits = hits[offset:min(offset+limit, len(hits))]
+    }
+    return result
+}
+
+// searchLocale scores the products that resolve to a locale of chain and
+// match every one of the analyzed query terms in their content in it
+func (idx *Index) searchLocale(terms []string, tag string, chain []string) map[string]float64 {
+    p, ok := idx.locales[tag]
+    if !ok {
+        return nil
+    }
+
+    var scores map[string]float64
+    avgLength := p.totalLength / float64(p.docs)
+    for i, term := range terms {
+        termScores := make(map[string]float64)
+        for _, exp := range p.expand(term) {
+            posting := p.terms[exp.term]
+            idf := math.Log(1 + (float64(p.docs)-float64(len(posting))+0.5)/(float64(len(posting))+0.5))
+            for id, tf := range posting {
+                doc := idx.docs[id]
+                if doc.resolve(chain) != tag {
+                    continue
+                }
+                norm := tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*doc.texts[tag].length/avgLength))
+                termScores[id] = max(termScores[id], exp.weight*idf*norm)
+            }
+        }
+
+        // every query term has to match
+        if i == 0 {
+            scores = termScores
+            continue
+        }
+        for id, score := range scores {
+            if s, ok := termScores[id]; ok {
+                scores[id] = score + s
+            } else {
+                delete(scores, id)
+            }
+        }
+    }
+    return scores
+}
+
+// expand returns the index terms a query term matches: itself, plus any term
+// within maxEdits typos
+func (p *postings) expand(term string) []expansion {
+    var expansions []expansion
+    if _, ok := p.terms[term]; ok {
+        expansions = append(expansions, expansion{term: term, weight: 1})
+    }
+    limit := maxEdits(term)
+    if limit == 0 {
+        return expansions
+    }
+    for candidate := range p.terms {
+        if candidate == term {
+            continue
+        }
+        if d := editDistance(term, candidate, limit); d <= limit {
+            expansions = append(expansions, expansion{term: candidate, weight: fuzzyPenalty / float64(d)})
+        }
+    }
+    return expansions
+}
+
+// price is the price of a product in the currency of a search, or in its
+// own currency when it could not be converted
+type price struct {
+    value     float64
+    converted bool
+    currency  string
+}
+
+// prices converts the prices of the hits into currency
+func (idx *Index) prices(hits []Hit, currency string, rates *pricing.Rates) map[string]price {
+    prices := make(map[string]price, len(hits))
+    for _, hit := range hits {
+        m := idx.docs[hit.ID].price
+        p := price{currency: m.Currency}
+        if p.value, p.converted = rates.Float64(m, currency); !p.converted {
+            p.value = m.Float64()
+        }
+        prices[hit.ID] = p
+    }
+    return prices
+}
+
+// comparePrice orders converted prices by value, and prices that could not be
+// converted by currency and then by value; order puts the latter last
+func comparePrice(a, b price) int {
+    if !a.converted {
+        if c := strings.Compare(a.currency, b.currency); c != 0 {
+            return c
+        }
+    }
+    return compareFloat(a.value, b.value)
+}
+
+// order sorts hits by the requested keys, or by descending score when there are none.
+// Ties fall back to score and then product ID so pages are stable. Prices that
+// could not be converted sort after the others in either direction.
+func (idx *Index) order(hits []Hit, sorts []query.Sort, prices map[string]price) {
+    sort.Slice(hits, func(i, j int) bool {
+        for _, s := range sorts {
+            if a, b := prices[hits[i].ID], prices[hits[j].ID]; s.Field == query.SortPrice && a.converted != b.converted {
+                return a.converted
+            }
+            c := idx.compare(hits[i], hits[j], s.Field, prices)
+            if c == 0 {
+                continue
+            }
+            if s.Direction == query.Desc {
+                return c > 0
+            }
+            return c < 0
+        }
+        if hits[i].Score != hits[j].Score {
+            return hits[i].Score > hits[j].Score
+        }
+        return hits[i].ID < hits[j].ID
+    })
+}
+
+// compare orders two hits by a field; titles are compared in the locale each matched in
+func (idx *Index) compare(hitA, hitB Hit, field query.SortField, prices map[string]price) int {
+    a, b := idx.docs[hitA.ID], idx.docs[hitB.ID]
+    switch field {
+    case query.SortPrice:
+        return comparePrice(prices[hitA.ID], prices[hitB.ID])
+    case query.SortRatings:
+        return compareFloat(a.ratings, b.ratings)
+    case query.SortCreatedAt:
+        return a.createdAt.Compare(b.createdAt)
+    case query.SortTitle:
+        return strings.Compare(a.texts[hitA.Locale].title, b.texts[hitB.Locale].title)
+    }
+    return 0
+}
+
+func compareFloat(a, b float64) int {
+    switch {
+    case a < b:
+        return -1
+    case a > b:
+        return 1
+    }
+    return 0
+}
+
+// facets counts the values of the hits; prices are bucketed in currency, and
+// those that could not be converted into it are left out
+func (idx *Index) facets(hits []Hit, currency string, prices map[string]price) Facets {
+    categories := make(map[string]int)
+    colors := make(map[string]int)
+    sizes := make(map[string]int)
+    counts := make([]int, len(priceBounds))
+
+    for _, hit := range hits {
+        doc := idx.docs[hit.ID]
+        if doc.category != "" {
+            categories[doc.category]++
+        }
+        if doc.color != "" {
+            colors[doc.color]++
+        }
+        seen := make(map[string]bool, len(doc.sizes))
+        for _, size := range doc.sizes {
+            if size != "" && !seen[size] {
+                seen[size] = true
+                sizes[size]++
+            }
+        }
+        if p := prices[hit.ID]; p.converted {
+            bucket := sort.Search(len(priceBounds), func(i int) bool { return priceBounds[i] > p.value }) - 1
+            counts[max(bucket, 0)]++
+        }
+    }
+
+    facets := Facets{
+        Category: facetValues(categories),
+        Color:    facetValues(colors),
+        Size:     facetValues(sizes),
+    }
+    for i, count := range counts {
+        if count == 0 {
+            continue
+        }
+        bucket := PriceBucket{Min: priceBounds[i], Currency: currency, Count: count}
+        if i+1 < len(priceBounds) {
+            upper := priceBounds[i+1]
+            bucket.Max = &upper
+        }
+        facets.Price = append(facets.Price, bucket)
+    }
+    return facets
+}
+
+// facetValues orders values by descending count, then alphabetically
+func facetValues(counts map[string]int) []FacetValue {
+    values := make([]FacetValue, 0, len(counts))
+    for value, count := range counts {
+        values = append(values, FacetValue{Value: value, Count: count})
+    }
+    sort.Slice(values, func(i, j int) bool {
+        if values[i].Count != values[j].Count {
+            return values[i].Count > values[j].Count
+        }
+        return values[i].Value < values[j].Value
+    })
+    return values
+}
+
+func dedupe(terms []string) []string {
+    seen := make(map[string]bool, len(terms))
+    unique := terms[:0]
+    for _, term := range terms {
+        if !seen[term] {
+            seen[term] = true
+            unique = append(unique, term)
+        }
+    }
+    return unique
+}
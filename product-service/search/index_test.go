@@ -0,0 +1,335 @@
+package search
+
+import (
+    "reflect"
+    "testing"
+
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+    "github.com/samObot19/shopverse/product-service/pricing"
+    "github.com/samObot19/shopverse/product-service/query"
+)
+
+func testIndex() *Index {
+    idx := NewIndex()
+    idx.Rebuild([]*models.Product{
+        {ID: "1", Title: "Leather Jacket", Description: "A warm jacket for winter", Price: money.New(18000, "USD"), Category: "outerwear",
+            Attributes: models.ProductAttributes{Color: "black", Size: []string{"M", "L"}}},
+        {ID: "2", Title: "Running Shoes", Description: "Lightweight shoes for running", Price: money.New(9000, "USD"), Category: "footwear",
+            Attributes: models.ProductAttributes{Color: "blue", Size: []string{"42", "43"}}},
+        {ID: "3", Title: "Denim Jacket", Description: "Classic blue denim", Price: money.New(6000, "USD"), Category: "outerwear",
+            Attributes: models.ProductAttributes{Color: "blue", Size: []string{"M"}}},
+        {ID: "4", Title: "Cotton T-Shirt", Description: "Goes well with a jacket", Price: money.New(1500, "USD"), Category: "tops",
+            Attributes: models.ProductAttributes{Color: "white", Size: []string{"S", "M"}}},
+    })
+    return idx
+}
+
+func hitIDs(hits []Hit) []string {
+    ids := make([]string, len(hits))
+    for i, hit := range hits {
+        ids[i] = hit.ID
+    }
+    return ids
+}
+
+func TestStem(t *testing.T) {
+    tests := map[string]string{
+        "jackets":     "jacket",
+        "running":     "run",
+        "shoes":       "shoe",
+        "caresses":    "caress",
+        "ponies":      "poni",
+        "hopping":     "hop",
+        "relational":  "relat",
+        "generalized": "gener",
+        "xl":          "xl",
+        "café":        "café",
+    }
+    for word, want := range tests {
+        if got := Stem(word); got != want {
+            t.Errorf("Stem(%q) = %q, want %q", word, got, want)
+        }
+    }
+}
+
+func TestEditDistance(t *testing.T) {
+    tests := []struct {
+        a, b  string
+        limit int
+        want  int
+    }{
+        {"jacket", "jacket", 1, 0},
+        {"jacket", "jakcet", 1, 1},
+        {"jacket", "jaket", 1, 1},
+        {"jacket", "jackets", 1, 1},
+        {"jacket", "rocket", 1, 2},
+        {"jacket", "jack", 1, 2},
+    }
+    for _, tt := range tests {
+        if got := editDistance(tt.a, tt.b, tt.limit); got != tt.want {
+            t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+        }
+    }
+}
+
+func TestSearch(t *testing.T) {
+    tests := []struct {
+        name string
+        req  Request
+        want []string
+    }{
+        {
+            name: "Title matches outrank description matches",
+            req:  Request{Text: "jacket"},
+            want: []string{"1", "3", "4"},
+        },
+        {
+            name: "Stemmed query",
+            req:  Request{Text: "Jackets"},
+            want: []string{"1", "3", "4"},
+        },
+        {
+            name: "Typo tolerance",
+            req:  Request{Text: "jakcet"},
+            want: []string{"1", "3", "4"},
+        },
+        {
+            name: "All terms must match",
+            req:  Request{Text: "blue jacket"},
+            want: []string{"3"},
+        },
+        {
+            name: "Explicit sort overrides relevance",
+            req:  Request{Text: "jacket", Sort: []query.Sort{{Field: query.SortPrice, Direction: query.Asc}}},
+            want: []string{"4", "3", "1"},
+        },
+        {
+            name: "Paging",
+            req:  Request{Text: "jacket", Limit: 1, Offset: 1},
+            want: []string{"3"},
+        },
+        {
+            name: "Stop words only",
+            req:  Request{Text: "the and"},
+            want: []string{},
+        },
+        {
+            name: "No match",
+            req:  Request{Text: "umbrella"},
+            want: []string{},
+        },
+    }
+
+    idx := testIndex()
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := hitIDs(idx.Search(tt.req).Hits)
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("Search(%q) = %v, want %v", tt.req.Text, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestSearchFacets(t *testing.T) {
+    result := testIndex().Search(Request{Text: "jacket", Limit: 1})
+    if result.Total != 3 {
+        t.Fatalf("Total = %d, want 3", result.Total)
+    }
+
+    want := Facets{
+        Category: []FacetValue{{"outerwear", 2}, {"tops", 1}},
+        Color:    []FacetValue{{"black", 1}, {"blue", 1}, {"white", 1}},
+        Size:     []FacetValue{{"M", 3}, {"L", 1}, {"S", 1}},
+    }
+    if !reflect.DeepEqual(result.Facets.Category, want.Category) {
+        t.Errorf("Category facet = %v, want %v", result.Facets.Category, want.Category)
+    }
+    if !reflect.DeepEqual(result.Facets.Color, want.Color) {
+        t.Errorf("Color facet = %v, want %v", result.Facets.Color, want.Color)
+    }
+    if !reflect.DeepEqual(result.Facets.Size, want.Size) {
+        t.Errorf("Size facet = %v, want %v", result.Facets.Size, want.Size)
+    }
+
+    var buckets []float64
+    for _, bucket := range result.Facets.Price {
+        if bucket.Count != 1 {
+            t.Errorf("price bucket %v has %d products, want 1", bucket.Min, bucket.Count)
+        }
+        buckets = append(buckets, bucket.Min)
+    }
+    if !reflect.DeepEqual(buckets, []float64{0, 50, 100}) {
+        t.Errorf("price buckets = %v, want [0 50 100]", buckets)
+    }
+}
+
+func TestSearchMixedCurrencies(t *testing.T) {
+    idx := NewIndex()
+    idx.Rebuild([]*models.Product{
+        {ID: "wool", Title: "Wool Scarf", Price: money.New(4000, "USD")},
+        {ID: "silk", Title: "Silk Scarf", Price: money.New(3000, "EUR")},
+        {ID: "linen", Title: "Linen Scarf", Price: money.New(5000, "JPY")},
+        {ID: "cashmere", Title: "Cashmere Scarf", Price: money.New(1000, "GBP")},
+        {ID: "cotton", Title: "Cotton Scarf", Price: money.New(500, "CHF")},
+    })
+    rates, err := pricing.NewRates([]*models.ExchangeRate{
+        {Currency: "EUR", Rate: "0.5", Rounding: money.DefaultRounding},
+        {Currency: "JPY", Rate: "100", Rounding: money.DefaultRounding},
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    upTo := func(max float64) *float64 { return &max }
+
+    tests := []struct {
+        name        string
+        currency    string
+        direction   query.Direction
+        wantIDs     []string
+        wantBuckets []PriceBucket
+    }{
+        {
+            name:        "cheapest first in the base currency",
+            direction:   query.Asc,
+            wantIDs:     []string{"wool", "linen", "silk", "cotton", "cashmere"},
+            wantBuckets: []PriceBucket{{Min: 25, Max: upTo(50.0), Currency: "USD", Count: 1}, {Min: 50, Max: upTo(100.0), Currency: "USD", Count: 2}},
+        },
+        {
+            name:        "dearest first, unconverted prices still last",
+            direction:   query.Desc,
+            wantIDs:     []string{"silk", "linen", "wool", "cashmere", "cotton"},
+            wantBuckets: []PriceBucket{{Min: 25, Max: upTo(50.0), Currency: "USD", Count: 1}, {Min: 50, Max: upTo(100.0), Currency: "USD", Count: 2}},
+        },
+        {
+            name:        "bucketed in the currency asked for",
+            currency:    "EUR",
+            direction:   query.Asc,
+            wantIDs:     []string{"wool", "linen", "silk", "cotton", "cashmere"},
+            wantBuckets: []PriceBucket{{Min: 0, Max: upTo(25.0), Currency: "EUR", Count: 1}, {Min: 25, Max: upTo(50.0), Currency: "EUR", Count: 2}},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            result := idx.Search(Request{
+                Text:     "scarf",
+                Sort:     []query.Sort{{Field: query.SortPrice, Direction: tt.direction}},
+                Currency: tt.currency,
+                Rates:    rates,
+            })
+            if got := hitIDs(result.Hits); !reflect.DeepEqual(got, tt.wantIDs) {
+                t.Errorf("Search() = %v, want %v", got, tt.wantIDs)
+            }
+            if !reflect.DeepEqual(result.Facets.Price, tt.wantBuckets) {
+                t.Errorf("price buckets = %+v, want %+v", result.Facets.Price, tt.wantBuckets)
+            }
+        })
+    }
+}
+
+func TestIndexUpdates(t *testing.T) {
+    idx := testIndex()
+
+    idx.Add(&models.Product{ID: "3", Title: "Denim Shirt", Category: "tops"})
+    if got := hitIDs(idx.Search(Request{Text: "denim"}).Hits); !reflect.DeepEqual(got, []string{"3"}) {
+        t.Errorf("after update, Search(denim) = %v, want [3]", got)
+    }
+    if got := hitIDs(idx.Search(Request{Text: "jacket"}).Hits); !reflect.DeepEqual(got, []string{"1", "4"}) {
+        t.Errorf("after update, Search(jacket) = %v, want [1 4]", got)
+    }
+
+    idx.Remove("1")
+    if got := hitIDs(idx.Search(Request{Text: "leather"}).Hits); len(got) != 0 {
+        t.Errorf("after delete, Search(leather) = %v, want none", got)
+    }
+    if idx.Len() != 3 {
+        t.Errorf("Len() = %d, want 3", idx.Len())
+    }
+}
+
+func TestSearchLocales(t *testing.T) {
+    idx := NewIndex()
+    idx.Rebuild([]*models.Product{
+        {ID: "1", Title: "Rain Jacket", Description: "Keeps you dry", Category: "outerwear",
+            Translations: models.Translations{
+                "fr":    {Title: "Veste de pluie", Description: "Vous garde au sec"},
+                "fr-CA": {Title: "Manteau de pluie"},
+            }},
+        {ID: "2", Title: "Wool Jacket", Description: "Warm for winter", Category: "outerwear",
+            Translations: models.Translations{"fr": {Title: "Parka en laine"}}},
+        {ID: "3", Title: "Leather Boots", Description: "Waterproof boots", Category: "footwear"},
+    })
+
+    tests := []struct {
+        name    string
+        req     Request
+        want    []string
+        locales []string
+    }{
+        {
+            name:    "Default content without locales",
+            req:     Request{Text: "jacket"},
+            want:    []string{"2", "1"},
+            locales: []string{"", ""},
+        },
+        {
+            name:    "Translated content replaces the default",
+            req:     Request{Text: "veste", Locales: []string{"fr"}},
+            want:    []string{"1"},
+            locales: []string{"fr"},
+        },
+        {
+            name:    "Default content is not matched once translated",
+            req:     Request{Text: "jacket", Locales: []string{"fr"}},
+            want:    []string{},
+            locales: []string{},
+        },
+        {
+            name:    "Untranslated products match their default content",
+            req:     Request{Text: "boots", Locales: []string{"fr"}},
+            want:    []string{"3"},
+            locales: []string{""},
+        },
+        {
+            name:    "Closest locale of the chain",
+            req:     Request{Text: "pluie", Locales: []string{"fr-CA", "fr"}},
+            want:    []string{"1"},
+            locales: []string{"fr-CA"},
+        },
+        {
+            name:    "Untranslated description falls back",
+            req:     Request{Text: "sec", Locales: []string{"fr-CA", "fr"}},
+            want:    []string{"1"},
+            locales: []string{"fr-CA"},
+        },
+        {
+            name:    "English stop words are words in French",
+            req:     Request{Text: "de", Locales: []string{"fr"}},
+            want:    []string{"1"},
+            locales: []string{"fr"},
+        },
+        {
+            name:    "Sorted by translated title",
+            req:     Request{Text: "outerwear", Locales: []string{"fr"}, Sort: []query.Sort{{Field: query.SortTitle, Direction: query.Asc}}},
+            want:    []string{"2", "1"},
+            locales: []string{"fr", "fr"},
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            hits := idx.Search(tt.req).Hits
+            if got := hitIDs(hits); !reflect.DeepEqual(got, tt.want) {
+                t.Fatalf("Search(%q, %v) = %v, want %v", tt.req.Text, tt.req.Locales, got, tt.want)
+            }
+            locales := make([]string, len(hits))
+            for i, hit := range hits {
+                locales[i] = hit.Locale
+            }
+            if !reflect.DeepEqual(locales, tt.locales) {
+                t.Errorf("Search(%q, %v) matched in %q, want %q", tt.req.Text, tt.req.Locales, locales, tt.locales)
+            }
+        })
+    }
+}
@@ -0,0 +1,328 @@
+package search
+
+import (
+    "sort"
+    "strings"
+)
+
+// SuggestionKind tells a product title completion from a category completion
+type SuggestionKind string
+
+const (
+    SuggestTitle    SuggestionKind = "title"
+    SuggestCategory SuggestionKind = "category"
+)
+
+// Suggestion is an autocomplete entry. ProductID is set for title suggestions.
+// Popularity is one plus the product's rating, summed over the products of a
+// category, so broad categories rank ahead of individual titles.
+type Suggestion struct {
+    Text       string
+    Kind       SuggestionKind
+    ProductID  string
+    Popularity float64
+}
+
+// MaxSuggestions is the most suggestions a completion returns
+const MaxSuggestions = 50
+
+// suggestion is a trie entry; titles have one per product, categories one per
+// distinct category shared by all of its products
+type suggestion struct {
+    Suggestion
+    key      textKey
+    products int // number of products contributing to a category entry
+}
+
+// textKey tells suggestions apart; titles shared by several products have the
+// same key and are suggested once
+type textKey struct {
+    kind SuggestionKind
+    text string // normalised
+}
+
+// better orders suggestions most popular first, then by text, kind and product
+func better(a, b *suggestion) bool {
+    if a.Popularity != b.Popularity {
+        return a.Popularity > b.Popularity
+    }
+    if a.Text != b.Text {
+        return a.Text < b.Text
+    }
+    if a.Kind != b.Kind {
+        return a.Kind < b.Kind
+    }
+    return a.ProductID < b.ProductID
+}
+
+// trieNode holds the entries whose key ends at it and, so a completion does
+// not visit the nodes below, the best MaxSuggestions entries of distinct text
+// at or below it
+type trieNode struct {
+    children map[rune]*trieNode
+    entries  map[*suggestion]bool
+    top      []*suggestion // best first
+}
+
+// offer adds entry to the top of the node, or moves it once its popularity
+// has risen. The best entry of a text stands for all entries of that text.
+func (n *trieNode) offer(entry *suggestion) {
+    for i, e := range n.top {
+        if e.key != entry.key {
+            continue
+        }
+        if e != entry && better(e, entry) {
+            return
+        }
+        n.top = append(n.top[:i], n.top[i+1:]...)
+        break
+    }
+    at := sort.Search(len(n.top), func(i int) bool { return better(entry, n.top[i]) })
+    if at == MaxSuggestions {
+        return
+    }
+    n.top = append(n.top, nil)
+    copy(n.top[at+1:], n.top[at:])
+    n.top[at] = entry
+    if len(n.top) > MaxSuggestions {
+        n.top = n.top[:MaxSuggestions]
+    }
+}
+
+// holds reports whether entry is in the top of the node
+func (n *trieNode) holds(entry *suggestion) bool {
+    for _, e := range n.top {
+        if e == entry {
+            return true
+        }
+    }
+    return false
+}
+
+// recount rebuilds the top of the node from its entries and the tops of its
+// children, after an entry of its top is removed or falls in popularity
+func (n *trieNode) recount() {
+    var candidates []*suggestion
+    for entry := range n.entries {
+        candidates = append(candidates, entry)
+    }
+    for _, child := range n.children {
+        candidates = append(candidates, child.top...)
+    }
+    sort.Slice(candidates, func(i, j int) bool { return better(candidates[i], candidates[j]) })
+
+    n.top = n.top[:0]
+    seen := make(map[textKey]bool)
+    for _, entry := range candidates {
+        if seen[entry.key] {
+            continue
+        }
+        seen[entry.key] = true
+        n.top = append(n.top, entry)
+        if len(n.top) == MaxSuggestions {
+            break
+        }
+    }
+}
+
+// trie is a prefix index over normalised titles and categories. Every word of
+// a title is a starting point, so "jac" completes "Leather Jacket".
+type trie struct {
+    root       *trieNode
+    titles     map[string]*suggestion // product ID -> title entry
+    categories map[string]*suggestion // normalised category -> category entry
+}
+
+func newTrie() *trie {
+    return &trie{
+        root:       &trieNode{},
+        titles:     make(map[string]*suggestion),
+        categories: make(map[string]*suggestion),
+    }
+}
+
+// normalize lower-cases text and collapses punctuation and whitespace to single spaces
+func normalize(text string) string {
+    return strings.Join(Tokenize(text), " ")
+}
+
+// keys returns every word-aligned suffix of a normalised string
+func keys(normalized string) []string {
+    if normalized == "" {
+        return nil
+    }
+    keys := []string{normalized}
+    for i, r := range normalized {
+        if r == ' ' {
+            keys = append(keys, normalized[i+1:])
+        }
+    }
+    return keys
+}
+
+// path returns the nodes from the root to the node of key, or nil when there is none
+func (t *trie) path(key string) []*trieNode {
+    path := []*trieNode{t.root}
+    for _, r := range key {
+        child, ok := path[len(path)-1].children[r]
+        if !ok {
+            return nil
+        }
+        path = append(path, child)
+    }
+    return path
+}
+
+func (t *trie) insert(key string, entry *suggestion) {
+    path := []*trieNode{t.root}
+    for _, r := range key {
+        node := path[len(path)-1]
+        child, ok := node.children[r]
+        if !ok {
+            if node.children == nil {
+                node.children = make(map[rune]*trieNode)
+            }
+            child = &trieNode{}
+            node.children[r] = child
+        }
+        path = append(path, child)
+    }
+    node := path[len(path)-1]
+    if node.entries == nil {
+        node.entries = make(map[*suggestion]bool)
+    }
+    node.entries[entry] = true
+    for i := len(path) - 1; i >= 0; i-- {
+        path[i].offer(entry)
+    }
+}
+
+// delete removes entry from key and prunes the nodes left empty
+func (t *trie) delete(key string, entry *suggestion) {
+    path := t.path(key)
+    if path == nil {
+        return
+    }
+    delete(path[len(path)-1].entries, entry)
+
+    runes := []rune(key)
+    kept := len(runes)
+    for ; kept > 0; kept-- {
+        node := path[kept]
+        if len(node.entries) > 0 || len(node.children) > 0 {
+            break
+        }
+        delete(path[kept-1].children, runes[kept-1])
+    }
+    t.recount(path[:kept+1], entry)
+}
+
+// recount rebuilds the tops of the nodes of path that hold entry, deepest
+// first, as each is built from the tops below it
+func (t *trie) recount(path []*trieNode, entry *suggestion) {
+    for i := len(path) - 1; i >= 0; i-- {
+        if !path[i].holds(entry) {
+            return // neither do the nodes above
+        }
+        path[i].recount()
+    }
+}
+
+// reorder moves entry within the tops of its nodes after its popularity changed
+func (t *trie) reorder(entry *suggestion, raised bool) {
+    for _, key := range keys(entry.key.text) {
+        path := t.path(key)
+        if raised {
+            for i := len(path) - 1; i >= 0; i-- {
+                path[i].offer(entry)
+            }
+        } else {
+            t.recount(path, entry)
+        }
+    }
+}
+
+func (t *trie) add(doc *document) {
+    if title := normalize(doc.title); title != "" {
+        entry := &suggestion{
+            Suggestion: Suggestion{
+                Text:       doc.title,
+                Kind:       SuggestTitle,
+                ProductID:  doc.id,
+                Popularity: 1 + doc.ratings,
+            },
+            key: textKey{SuggestTitle, title},
+        }
+        t.titles[doc.id] = entry
+        for _, key := range keys(title) {
+            t.insert(key, entry)
+        }
+    }
+
+    if category := normalize(doc.category); category != "" {
+        entry, ok := t.categories[category]
+        if !ok {
+            entry = &suggestion{
+                Suggestion: Suggestion{Text: doc.category, Kind: SuggestCategory},
+                key:        textKey{SuggestCategory, category},
+            }
+            t.categories[category] = entry
+        }
+        entry.products++
+        entry.Popularity += 1 + doc.ratings
+        if ok {
+            t.reorder(entry, true)
+        } else {
+            for _, key := range keys(category) {
+                t.insert(key, entry)
+            }
+        }
+    }
+}
+
+func (t *trie) remove(doc *document) {
+    if entry, ok := t.titles[doc.id]; ok {
+        delete(t.titles, doc.id)
+        for _, key := range keys(entry.key.text) {
+            t.delete(key, entry)
+        }
+    }
+
+    category := normalize(doc.category)
+    if entry, ok := t.categories[category]; ok {
+        entry.products--
+        entry.Popularity -= 1 + doc.ratings
+        if entry.products == 0 {
+            delete(t.categories, category)
+            for _, key := range keys(category) {
+                t.delete(key, entry)
+            }
+        } else {
+            t.reorder(entry, false)
+        }
+    }
+}
+
+// complete returns up to limit, and at most MaxSuggestions, entries under
+// prefix, most popular first. Titles shared by several products are suggested
+// once.
+func (t *trie) complete(prefix string, limit int) []Suggestion {
+    prefix = normalize(prefix)
+    if prefix == "" || limit <= 0 {
+        return nil
+    }
+    path := t.path(prefix)
+    if path == nil {
+        return nil
+    }
+
+    top := path[len(path)-1].top
+    if limit < len(top) {
+        top = top[:limit]
+    }
+    suggestions := make([]Suggestion, len(top))
+    for i, entry := range top {
+        suggestions[i] = entry.Suggestion
+    }
+    return suggestions
+}
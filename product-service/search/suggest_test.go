@@ -0,0 +1,157 @@
+package search
+
+import (
+    "math/rand"
+    "reflect"
+    "sort"
+    "strconv"
+    "testing"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+func suggestionTexts(suggestions []Suggestion) []string {
+    texts := make([]string, len(suggestions))
+    for i, s := range suggestions {
+        texts[i] = string(s.Kind) + ":" + s.Text
+    }
+    return texts
+}
+
+func TestSuggest(t *testing.T) {
+    idx := NewIndex()
+    idx.Rebuild([]*models.Product{
+        {ID: "1", Title: "Leather Jacket", Category: "Jackets", Ratings: 4},
+        {ID: "2", Title: "Denim Jacket", Category: "Jackets", Ratings: 2},
+        {ID: "3", Title: "Jack-o-lantern Mug", Category: "Kitchen", Ratings: 5},
+        {ID: "4", Title: "Denim Jacket", Category: "Jackets", Ratings: 1},
+        {ID: "5", Title: "Lamp", Category: "Lighting", Ratings: 3},
+    })
+
+    tests := []struct {
+        name   string
+        prefix string
+        limit  int
+        want   []string
+    }{
+        {
+            name:   "Categories and titles by popularity",
+            prefix: "jac",
+            limit:  10,
+            want:   []string{"category:Jackets", "title:Jack-o-lantern Mug", "title:Leather Jacket", "title:Denim Jacket"},
+        },
+        {
+            name:   "Capped",
+            prefix: "jac",
+            limit:  2,
+            want:   []string{"category:Jackets", "title:Jack-o-lantern Mug"},
+        },
+        {
+            name:   "Case and punctuation insensitive",
+            prefix: "JACK O",
+            limit:  10,
+            want:   []string{"title:Jack-o-lantern Mug"},
+        },
+        {
+            name:   "Completes later words",
+            prefix: "mu",
+            limit:  10,
+            want:   []string{"title:Jack-o-lantern Mug"},
+        },
+        {
+            name:   "Unknown prefix",
+            prefix: "xyz",
+            limit:  10,
+            want:   []string{},
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := suggestionTexts(idx.Suggest(tt.prefix, tt.limit))
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("Suggest(%q, %d) = %v, want %v", tt.prefix, tt.limit, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestSuggestFollowsWrites(t *testing.T) {
+    idx := NewIndex()
+    idx.Add(&models.Product{ID: "1", Title: "Wool Scarf", Category: "Accessories"})
+    idx.Add(&models.Product{ID: "1", Title: "Silk Scarf", Category: "Accessories"})
+
+    if got := suggestionTexts(idx.Suggest("wool", 10)); len(got) != 0 {
+        t.Errorf("after update, Suggest(wool) = %v, want none", got)
+    }
+    if got := suggestionTexts(idx.Suggest("si", 10)); !reflect.DeepEqual(got, []string{"title:Silk Scarf"}) {
+        t.Errorf("after update, Suggest(si) = %v, want [title:Silk Scarf]", got)
+    }
+
+    idx.Remove("1")
+    if got := suggestionTexts(idx.Suggest("acc", 10)); len(got) != 0 {
+        t.Errorf("after delete, Suggest(acc) = %v, want none", got)
+    }
+    if len(idx.suggestions.root.children) != 0 {
+        t.Errorf("after delete, trie still has %d branches", len(idx.suggestions.root.children))
+    }
+}
+
+// allCompletions completes prefix by visiting every entry under it, as the
+// tops kept on the trie nodes must agree with
+func allCompletions(t *trie, prefix string, limit int) []string {
+    path := t.path(normalize(prefix))
+    if path == nil {
+        return []string{}
+    }
+    var entries []*suggestion
+    var walk func(*trieNode)
+    walk = func(n *trieNode) {
+        for entry := range n.entries {
+            entries = append(entries, entry)
+        }
+        for _, child := range n.children {
+            walk(child)
+        }
+    }
+    walk(path[len(path)-1])
+    sort.Slice(entries, func(i, j int) bool { return better(entries[i], entries[j]) })
+
+    texts := []string{}
+    seen := make(map[textKey]bool)
+    for _, entry := range entries {
+        if !seen[entry.key] && len(texts) < limit {
+            seen[entry.key] = true
+            texts = append(texts, string(entry.Kind)+":"+entry.Text)
+        }
+    }
+    return texts
+}
+
+func TestSuggestKeepsBestAfterWrites(t *testing.T) {
+    rng := rand.New(rand.NewSource(1))
+    words := []string{"jacket", "jeans", "jersey", "jumper", "mug", "lamp"}
+    idx := NewIndex()
+    for i := 0; i < 2000; i++ {
+        id := strconv.Itoa(rng.Intn(300))
+        if rng.Intn(4) == 0 {
+            idx.Remove(id)
+            continue
+        }
+        idx.Add(&models.Product{
+            ID:       id,
+            Title:    words[rng.Intn(len(words))] + " " + strconv.Itoa(rng.Intn(80)),
+            Category: words[rng.Intn(len(words))] + "s",
+            Ratings:  float64(rng.Intn(5)),
+        })
+    }
+
+    for _, prefix := range []string{"j", "je", "jersey", "m", "1", "lamps"} {
+        for _, limit := range []int{1, 10, MaxSuggestions + 10} {
+            got := suggestionTexts(idx.Suggest(prefix, limit))
+            want := allCompletions(idx.suggestions, prefix, min(limit, MaxSuggestions))
+            if !reflect.DeepEqual(got, want) {
+                t.Errorf("Suggest(%q, %d) = %v, want %v", prefix, limit, got, want)
+            }
+        }
+    }
+}
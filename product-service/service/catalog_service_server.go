@@ -0,0 +1,117 @@
+package service
+
+import (
+    "io"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+
+    pb "github.com/samObot19/shopverse/product-service/proto/pb"
+    "github.com/samObot19/shopverse/product-service/catalog"
+    "github.com/samObot19/shopverse/product-service/usecases"
+)
+
+var catalogFormats = map[pb.CatalogFormat]catalog.Format{
+    pb.CatalogFormat_CATALOG_FORMAT_CSV:        catalog.CSV,
+    pb.CatalogFormat_CATALOG_FORMAT_JSON_LINES: catalog.JSONLines,
+}
+
+// ImportProducts handles the gRPC stream of a catalog file to import. The
+// first message carries the options and the following ones the file, which
+// is imported while it arrives.
+func (s *ProductServiceServer) ImportProducts(stream pb.ProductService_ImportProductsServer) error {
+    first, err := stream.Recv()
+    if err == io.EOF {
+        return status.Error(codes.InvalidArgument, "import stream is empty")
+    }
+    if err != nil {
+        return err
+    }
+    opts := first.GetOptions()
+    if opts == nil {
+        return status.Error(codes.InvalidArgument, "the first message of an import must carry its options")
+    }
+    format, ok := catalogFormats[opts.Format]
+    if !ok {
+        return status.Errorf(codes.InvalidArgument, "unsupported catalog format %v", opts.Format)
+    }
+
+    pr, pw := io.Pipe()
+    defer pr.Close()
+    go func() {
+        for {
+            req, err := stream.Recv()
+            if err == io.EOF {
+                pw.Close()
+                return
+            }
+            if err != nil {
+                pw.CloseWithError(err)
+                return
+            }
+            if req.GetOptions() != nil {
+                pw.CloseWithError(status.Error(codes.InvalidArgument, "import options may only be sent once"))
+                return
+            }
+            if _, err := pw.Write(req.GetChunk()); err != nil {
+                return
+            }
+        }
+    }()
+
+    reader, err := catalog.NewReader(pr, format)
+    if err != nil {
+        return status.Error(codes.InvalidArgument, err.Error())
+    }
+    report, err := s.useCase.ImportProducts(stream.Context(), reader, usecases.ImportOptions{
+        DryRun:    opts.DryRun,
+        BatchSize: int(opts.BatchSize),
+    })
+    if err != nil {
+        return err
+    }
+    return stream.SendAndClose(toProtoImportReport(report))
+}
+
+// ExportProducts handles the gRPC request to stream every product as a catalog file
+func (s *ProductServiceServer) ExportProducts(req *pb.ExportProductsRequest, stream pb.ProductService_ExportProductsServer) error {
+    format, ok := catalogFormats[req.Format]
+    if !ok {
+        return status.Errorf(codes.InvalidArgument, "unsupported catalog format %v", req.Format)
+    }
+    writer, err := catalog.NewWriter(&chunkWriter{stream: stream}, format)
+    if err != nil {
+        return err
+    }
+    _, err = s.useCase.ExportProducts(stream.Context(), writer)
+    return err
+}
+
+// chunkWriter sends everything written to it as export chunks
+type chunkWriter struct {
+    stream pb.ProductService_ExportProductsServer
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+    if err := w.stream.Send(&pb.ExportProductsResponse{Chunk: p}); err != nil {
+        return 0, err
+    }
+    return len(p), nil
+}
+
+func toProtoImportReport(r *usecases.ImportReport) *pb.ImportProductsResponse {
+    resp := &pb.ImportProductsResponse{
+        Created: int32(r.Created),
+        Updated: int32(r.Updated),
+        Failed:  int32(r.Failed),
+    }
+    for _, e := range r.Errors {
+        resp.Errors = append(resp.Errors, &pb.ImportRowError{
+            Line:    int32(e.Line),
+            Id:      e.ID,
+            Sku:     e.SKU,
+            Message: e.Message,
+        })
+    }
+    return resp
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+    "context"
+    "errors"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+
+    pb "github.com/samObot19/shopverse/product-service/proto/pb"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+// CreateCategory handles the gRPC request to create a category
+func (s *ProductServiceServer) CreateCategory(ctx context.Context, req *pb.CreateCategoryRequest) (*pb.CreateCategoryResponse, error) {
+    if req.Category == nil {
+        return nil, status.Error(codes.InvalidArgument, "category is required")
+    }
+    id, err := s.useCase.CreateCategory(ctx, fromProtoCategory(req.Category))
+    if err != nil {
+        return nil, categoryError(err)
+    }
+    return &pb.CreateCategoryResponse{Id: id}, nil
+}
+
+// GetCategory handles the gRPC request to retrieve a category with its breadcrumbs
+func (s *ProductServiceServer) GetCategory(ctx context.Context, req *pb.GetCategoryRequest) (*pb.GetCategoryResponse, error) {
+    category, path, err := s.useCase.GetCategory(ctx, req.Id)
+    if err != nil {
+        return nil, categoryError(err)
+    }
+
+    breadcrumbs := make([]*pb.Category, 0, len(path))
+    for _, c := range path {
+        breadcrumbs = append(breadcrumbs, toProtoCategory(c))
+    }
+    return &pb.GetCategoryResponse{Category: toProtoCategory(category), Breadcrumbs: breadcrumbs}, nil
+}
+
+// UpdateCategory handles the gRPC request to rename, move or reorder a category
+func (s *ProductServiceServer) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryRequest) (*pb.UpdateCategoryResponse, error) {
+    if req.Category == nil {
+        return nil, status.Error(codes.InvalidArgument, "category is required")
+    }
+    if err := s.useCase.UpdateCategory(ctx, req.Id, fromProtoCategory(req.Category)); err != nil {
+        return nil, categoryError(err)
+    }
+    return &pb.UpdateCategoryResponse{Message: "Category updated successfully"}, nil
+}
+
+// DeleteCategory handles the gRPC request to delete a category
+func (s *ProductServiceServer) DeleteCategory(ctx context.Context, req *pb.DeleteCategoryRequest) (*pb.DeleteCategoryResponse, error) {
+    if err := s.useCase.DeleteCategory(ctx, req.Id); err != nil {
+        return nil, categoryError(err)
+    }
+    return &pb.DeleteCategoryResponse{Message: "Category deleted successfully"}, nil
+}
+
+// GetCategoryTree handles the gRPC request to retrieve the category tree
+func (s *ProductServiceServer) GetCategoryTree(ctx context.Context, req *pb.GetCategoryTreeRequest) (*pb.GetCategoryTreeResponse, error) {
+    roots, err := s.useCase.GetCategoryTree(ctx, req.RootId)
+    if err != nil {
+        return nil, categoryError(err)
+    }
+    return &pb.GetCategoryTreeResponse{Roots: toProtoCategoryNodes(roots)}, nil
+}
+
+// categoryError maps a missing category to NotFound, a slug clash to
+// AlreadyExists and a category in use to FailedPrecondition, and passes other
+// errors through
+func categoryError(err error) error {
+    switch {
+    case errors.Is(err, repository.ErrCategoryNotFound):
+        return status.Error(codes.NotFound, err.Error())
+    case errors.Is(err, repository.ErrDuplicateCategory):
+        return status.Error(codes.AlreadyExists, err.Error())
+    case errors.Is(err, repository.ErrCategoryInUse):
+        return status.Error(codes.FailedPrecondition, err.Error())
+    }
+    return err
+}
+
+func fromProtoCategory(c *pb.Category) *models.Category {
+    return &models.Category{
+        ID:       c.Id,
+        ParentID: c.ParentId,
+        Name:     c.Name,
+        Slug:     c.Slug,
+        Position: int(c.Position),
+    }
+}
+
+func toProtoCategory(c *models.Category) *pb.Category {
+    return &pb.Category{
+        Id:        c.ID,
+        ParentId:  c.ParentID,
+        Name:      c.Name,
+        Slug:      c.Slug,
+        Position:  int32(c.Position),
+        CreatedAt: formatTime(c.CreatedAt),
+    }
+}
+
+func toProtoCategoryNodes(nodes []*models.CategoryNode) []*pb.CategoryNode {
+    pbNodes := make([]*pb.CategoryNode, 0, len(nodes))
+    for _, node := range nodes {
+        pbNodes = append(pbNodes, &pb.CategoryNode{
+            Category: toProtoCategory(node.Category),
+            Children: toProtoCategoryNodes(node.Children),
+        })
+    }
+    return pbNodes
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+    "context"
+    "errors"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+
+    pb "github.com/samObot19/shopverse/product-service/proto/pb"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+var roundingModes = map[money.RoundingMode]pb.RoundingMode{
+    money.RoundNearest: pb.RoundingMode_ROUNDING_MODE_NEAREST,
+    money.RoundUp:      pb.RoundingMode_ROUNDING_MODE_UP,
+    money.RoundDown:    pb.RoundingMode_ROUNDING_MODE_DOWN,
+}
+
+// SetExchangeRate handles the gRPC request to add or replace the exchange rate of a currency
+func (s *ProductServiceServer) SetExchangeRate(ctx context.Context, req *pb.SetExchangeRateRequest) (*pb.SetExchangeRateResponse, error) {
+    rate, err := fromProtoExchangeRate(req.ExchangeRate)
+    if err != nil {
+        return nil, err
+    }
+    if err := s.useCase.SetExchangeRate(ctx, rate); err != nil {
+        return nil, err
+    }
+    return &pb.SetExchangeRateResponse{ExchangeRate: toProtoExchangeRate(rate)}, nil
+}
+
+// ListExchangeRates handles the gRPC request to list all exchange rates
+func (s *ProductServiceServer) ListExchangeRates(ctx context.Context, req *pb.ListExchangeRatesRequest) (*pb.ListExchangeRatesResponse, error) {
+    rates, err := s.useCase.ListExchangeRates(ctx)
+    if err != nil {
+        return nil, err
+    }
+    pbRates := make([]*pb.ExchangeRate, 0, len(rates))
+    for _, rate := range rates {
+        pbRates = append(pbRates, toProtoExchangeRate(rate))
+    }
+    return &pb.ListExchangeRatesResponse{ExchangeRates: pbRates}, nil
+}
+
+// DeleteExchangeRate handles the gRPC request to stop prices being read in a currency
+func (s *ProductServiceServer) DeleteExchangeRate(ctx context.Context, req *pb.DeleteExchangeRateRequest) (*pb.DeleteExchangeRateResponse, error) {
+    if err := s.useCase.DeleteExchangeRate(ctx, req.Currency); err != nil {
+        if errors.Is(err, repository.ErrExchangeRateNotFound) {
+            return nil, status.Error(codes.NotFound, err.Error())
+        }
+        return nil, err
+    }
+    return &pb.DeleteExchangeRateResponse{Message: "Exchange rate deleted successfully"}, nil
+}
+
+func fromProtoExchangeRate(r *pb.ExchangeRate) (*models.ExchangeRate, error) {
+    if r == nil {
+        return nil, status.Error(codes.InvalidArgument, "exchange rate is required")
+    }
+    rate := &models.ExchangeRate{
+        Currency: r.Currency,
+        Rate:     r.Rate,
+        Rounding: money.Rounding{Increment: r.RoundingIncrement},
+    }
+    for mode, pbMode := range roundingModes {
+        if pbMode == r.RoundingMode {
+            rate.Rounding.Mode = mode
+        }
+    }
+    // an unset mode or increment takes that of the default rounding
+    if rate.Rounding.Mode == "" {
+        rate.Rounding.Mode = money.DefaultRounding.Mode
+    }
+    if rate.Rounding.Increment == 0 {
+        rate.Rounding.Increment = money.DefaultRounding.Increment
+    }
+    return rate, nil
+}
+
+func toProtoExchangeRate(r *models.ExchangeRate) *pb.ExchangeRate {
+    return &pb.ExchangeRate{
+        Currency:          r.Currency,
+        Rate:              r.Rate,
+        RoundingMode:      roundingModes[r.Rounding.Mode],
+        RoundingIncrement: r.Rounding.Increment,
+        UpdatedAt:         formatTime(r.UpdatedAt),
+    }
+}
+
+// toProtoConversion leaves prices that were not converted without a conversion
+func toProtoConversion(c *models.PriceConversion) *pb.PriceConversion {
+    if c == nil {
+        return nil
+    }
+    return &pb.PriceConversion{FromCurrency: c.From, Rate: c.Rate}
+}
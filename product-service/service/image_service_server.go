@@ -0,0 +1,132 @@
+package service
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "io"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+
+    pb "github.com/samObot19/shopverse/product-service/proto/pb"
+    "github.com/samObot19/shopverse/product-service/media"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+var imageFormats = map[media.Format]pb.ImageFormat{
+    media.JPEG: pb.ImageFormat_IMAGE_FORMAT_JPEG,
+    media.WebP: pb.ImageFormat_IMAGE_FORMAT_WEBP,
+}
+
+// UploadProductImage handles the gRPC request to upload an image of a product
+func (s *ProductServiceServer) UploadProductImage(stream pb.ProductService_UploadProductImageServer) error {
+    first, err := stream.Recv()
+    if err == io.EOF {
+        return status.Error(codes.InvalidArgument, "upload stream is empty")
+    }
+    if err != nil {
+        return err
+    }
+    upload := first.GetUpload()
+    if upload == nil {
+        return status.Error(codes.InvalidArgument, "the first message of an upload must carry its details")
+    }
+
+    var data bytes.Buffer
+    for {
+        req, err := stream.Recv()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return err
+        }
+        if req.GetUpload() != nil {
+            return status.Error(codes.InvalidArgument, "upload details may only be sent once")
+        }
+        // the rest of an upload that is too large is not read
+        if data.Len()+len(req.GetChunk()) > media.MaxUploadSize {
+            return imageError(fmt.Errorf("%w: more than %d bytes", media.ErrImageTooLarge, media.MaxUploadSize))
+        }
+        data.Write(req.GetChunk())
+    }
+
+    image, err := s.useCase.UploadProductImage(stream.Context(), upload.ProductId, upload.AltText, data.Bytes())
+    if err != nil {
+        return imageError(err)
+    }
+    return stream.SendAndClose(&pb.UploadProductImageResponse{Image: toProtoProductImage(image)})
+}
+
+// ListProductImages handles the gRPC request to list the images of a product
+func (s *ProductServiceServer) ListProductImages(ctx context.Context, req *pb.ListProductImagesRequest) (*pb.ListProductImagesResponse, error) {
+    images, err := s.useCase.ListProductImages(ctx, req.ProductId)
+    if err != nil {
+        return nil, imageError(err)
+    }
+    pbImages := make([]*pb.ProductImage, 0, len(images))
+    for _, image := range images {
+        pbImages = append(pbImages, toProtoProductImage(image))
+    }
+    return &pb.ListProductImagesResponse{Images: pbImages}, nil
+}
+
+// UpdateProductImage handles the gRPC request to change the alt text or position of an image
+func (s *ProductServiceServer) UpdateProductImage(ctx context.Context, req *pb.UpdateProductImageRequest) (*pb.UpdateProductImageResponse, error) {
+    var position *int
+    if req.Position != nil {
+        p := int(*req.Position)
+        position = &p
+    }
+    image, err := s.useCase.UpdateProductImage(ctx, req.Id, req.AltText, position)
+    if err != nil {
+        return nil, imageError(err)
+    }
+    return &pb.UpdateProductImageResponse{Image: toProtoProductImage(image)}, nil
+}
+
+// DeleteProductImage handles the gRPC request to delete a product image
+func (s *ProductServiceServer) DeleteProductImage(ctx context.Context, req *pb.DeleteProductImageRequest) (*pb.DeleteProductImageResponse, error) {
+    if err := s.useCase.DeleteProductImage(ctx, req.Id); err != nil {
+        return nil, imageError(err)
+    }
+    return &pb.DeleteProductImageResponse{Message: "Image deleted successfully"}, nil
+}
+
+// imageError maps image errors to gRPC status codes
+func imageError(err error) error {
+    switch {
+    case errors.Is(err, repository.ErrImageNotFound):
+        return status.Error(codes.NotFound, err.Error())
+    case errors.Is(err, media.ErrUnsupportedImage), errors.Is(err, media.ErrImageTooLarge):
+        return status.Error(codes.InvalidArgument, err.Error())
+    }
+    return productError(err)
+}
+
+func toProtoProductImage(image *models.ProductImage) *pb.ProductImage {
+    renditions := make([]*pb.ImageRendition, 0, len(image.Renditions))
+    for _, r := range image.Renditions {
+        renditions = append(renditions, &pb.ImageRendition{
+            Size:   r.Size,
+            Format: imageFormats[media.Format(r.Format)],
+            Width:  int32(r.Width),
+            Height: int32(r.Height),
+            Bytes:  r.Bytes,
+            Url:    r.URL,
+        })
+    }
+    return &pb.ProductImage{
+        Id:         image.ID,
+        ProductId:  image.ProductID,
+        AltText:    image.AltText,
+        Width:      int32(image.Width),
+        Height:     int32(image.Height),
+        Position:   int32(image.Position),
+        Renditions: renditions,
+        CreatedAt:  formatTime(image.CreatedAt),
+    }
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+    "context"
+    "strings"
+
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/metadata"
+
+    "github.com/samObot19/shopverse/product-service/usecases"
+)
+
+// CurrencyMetadataKey is the request metadata naming the currency prices are
+// read in, such as EUR; without it prices are read in the currency they are set in
+const CurrencyMetadataKey = "x-currency"
+
+// LocaleMetadataKey is the request metadata naming the locales products are
+// read in, most preferred first, such as fr-CA and fr; each value may also
+// list several separated by commas. Without it products are read in their
+// default content.
+const LocaleMetadataKey = "x-locale"
+
+// UnaryMetadataInterceptor carries the preferences sent in the request
+// metadata into the context the request is handled with
+func UnaryMetadataInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+    return handler(withMetadata(ctx), req)
+}
+
+// StreamMetadataInterceptor is UnaryMetadataInterceptor for streaming calls,
+// such as WatchProducts
+func StreamMetadataInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+    return handler(srv, &metadataStream{ServerStream: stream, ctx: withMetadata(stream.Context())})
+}
+
+// metadataStream is a server stream handled with the context withMetadata made
+type metadataStream struct {
+    grpc.ServerStream
+    ctx context.Context
+}
+
+func (s *metadataStream) Context() context.Context {
+    return s.ctx
+}
+
+func withMetadata(ctx context.Context) context.Context {
+    md, ok := metadata.FromIncomingContext(ctx)
+    if !ok {
+        return ctx
+    }
+    if values := md.Get(CurrencyMetadataKey); len(values) > 0 {
+        ctx = usecases.WithCurrency(ctx, values[0])
+    }
+    if values := md.Get(LocaleMetadataKey); len(values) > 0 {
+        var locales []string
+        for _, value := range values {
+            locales = append(locales, strings.Split(value, ",")...)
+        }
+        ctx = usecases.WithLocales(ctx, locales)
+    }
+    return ctx
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+
+    pb "github.com/samObot19/shopverse/product-service/proto/pb"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+// CreatePriceList handles the gRPC request to schedule the prices of a price list
+func (s *ProductServiceServer) CreatePriceList(ctx context.Context, req *pb.CreatePriceListRequest) (*pb.CreatePriceListResponse, error) {
+    list, err := fromProtoPriceList(req.PriceList)
+    if err != nil {
+        return nil, err
+    }
+    id, err := s.useCase.CreatePriceList(ctx, list)
+    if err != nil {
+        return nil, priceListError(err)
+    }
+    return &pb.CreatePriceListResponse{Id: id}, nil
+}
+
+// GetPriceList handles the gRPC request to retrieve a price list by its ID
+func (s *ProductServiceServer) GetPriceList(ctx context.Context, req *pb.GetPriceListRequest) (*pb.GetPriceListResponse, error) {
+    list, err := s.useCase.GetPriceList(ctx, req.Id)
+    if err != nil {
+        return nil, priceListError(err)
+    }
+    return &pb.GetPriceListResponse{PriceList: toProtoPriceList(list)}, nil
+}
+
+// ListPriceLists handles the gRPC request to list all price lists
+func (s *ProductServiceServer) ListPriceLists(ctx context.Context, req *pb.ListPriceListsRequest) (*pb.ListPriceListsResponse, error) {
+    lists, err := s.useCase.ListPriceLists(ctx)
+    if err != nil {
+        return nil, err
+    }
+    pbLists := make([]*pb.PriceList, 0, len(lists))
+    for _, list := range lists {
+        pbLists = append(pbLists, toProtoPriceList(list))
+    }
+    return &pb.ListPriceListsResponse{PriceLists: pbLists}, nil
+}
+
+// UpdatePriceList handles the gRPC request to replace the name, period and prices of a price list
+func (s *ProductServiceServer) UpdatePriceList(ctx context.Context, req *pb.UpdatePriceListRequest) (*pb.UpdatePriceListResponse, error) {
+    list, err := fromProtoPriceList(req.PriceList)
+    if err != nil {
+        return nil, err
+    }
+    if err := s.useCase.UpdatePriceList(ctx, req.Id, list); err != nil {
+        return nil, priceListError(err)
+    }
+    return &pb.UpdatePriceListResponse{Message: "Price list updated successfully"}, nil
+}
+
+// DeletePriceList handles the gRPC request to delete a price list
+func (s *ProductServiceServer) DeletePriceList(ctx context.Context, req *pb.DeletePriceListRequest) (*pb.DeletePriceListResponse, error) {
+    if err := s.useCase.DeletePriceList(ctx, req.Id); err != nil {
+        return nil, priceListError(err)
+    }
+    return &pb.DeletePriceListResponse{Message: "Price list deleted successfully"}, nil
+}
+
+// GetPriceHistory handles the gRPC request to page through the price history of a product
+func (s *ProductServiceServer) GetPriceHistory(ctx context.Context, req *pb.GetPriceHistoryRequest) (*pb.GetPriceHistoryResponse, error) {
+    changes, next, err := s.useCase.GetPriceHistory(ctx, req.ProductId, req.PageToken, int(req.PageSize))
+    if err != nil {
+        return nil, err
+    }
+
+    pbChanges := make([]*pb.PriceChange, 0, len(changes))
+    for _, c := range changes {
+        pbChanges = append(pbChanges, &pb.PriceChange{
+            Id:          c.ID,
+            ProductId:   c.ProductID,
+            VariantId:   c.VariantID,
+            PriceListId: c.PriceListID,
+            Price:       toProtoOptionalMoney(c.Price),
+            PercentOff:  c.PercentOff,
+            StartsAt:    formatTime(c.StartsAt),
+            EndsAt:      formatOptionalTime(c.EndsAt),
+            CreatedAt:   formatTime(c.CreatedAt),
+        })
+    }
+    return &pb.GetPriceHistoryResponse{Changes: pbChanges, NextPageToken: next}, nil
+}
+
+// priceListError maps a missing price list or variant to NotFound, and passes
+// other errors through
+func priceListError(err error) error {
+    switch {
+    case errors.Is(err, repository.ErrPriceListNotFound), errors.Is(err, repository.ErrVariantNotFound):
+        return status.Error(codes.NotFound, err.Error())
+    }
+    return err
+}
+
+func fromProtoPriceList(l *pb.PriceList) (*models.PriceList, error) {
+    if l == nil {
+        return nil, status.Error(codes.InvalidArgument, "price list is required")
+    }
+    startsAt, err := parseTime(l.StartsAt)
+    if err != nil {
+        return nil, status.Errorf(codes.InvalidArgument, "invalid start %q: %v", l.StartsAt, err)
+    }
+    list := &models.PriceList{Name: l.Name, StartsAt: startsAt}
+    if l.EndsAt != "" {
+        endsAt, err := parseTime(l.EndsAt)
+        if err != nil {
+            return nil, status.Errorf(codes.InvalidArgument, "invalid end %q: %v", l.EndsAt, err)
+        }
+        list.EndsAt = &endsAt
+    }
+    for _, e := range l.Entries {
+        list.Entries = append(list.Entries, models.PriceListEntry{
+            ProductID:  e.ProductId,
+            VariantID:  e.VariantId,
+            Price:      fromProtoOptionalMoney(e.Price),
+            PercentOff: e.PercentOff,
+        })
+    }
+    return list, nil
+}
+
+func toProtoPriceList(l *models.PriceList) *pb.PriceList {
+    entries := make([]*pb.PriceListEntry, 0, len(l.Entries))
+    for _, e := range l.Entries {
+        entries = append(entries, &pb.PriceListEntry{
+            ProductId:  e.ProductID,
+            VariantId:  e.VariantID,
+            Price:      toProtoOptionalMoney(e.Price),
+            PercentOff: e.PercentOff,
+        })
+    }
+    return &pb.PriceList{
+        Id:        l.ID,
+        Name:      l.Name,
+        StartsAt:  formatTime(l.StartsAt),
+        EndsAt:    formatOptionalTime(l.EndsAt),
+        Entries:   entries,
+        CreatedAt: formatTime(l.CreatedAt),
+    }
+}
+
+// formatOptionalTime formats t, or returns an empty string when it is nil
+func formatOptionalTime(t *time.Time) string {
+    if t == nil {
+        return ""
+    }
+    return formatTime(*t)
+}
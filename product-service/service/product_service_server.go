@@ -2,21 +2,34 @@ package service
 
 import (
     "context"
+    "errors"
     "time"
 
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/types/known/fieldmaskpb"
+
+    "github.com/samObot19/shopverse/product-service/feed"
     pb "github.com/samObot19/shopverse/product-service/proto/pb"
     "github.com/samObot19/shopverse/product-service/usecases"
     "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+    "github.com/samObot19/shopverse/product-service/pricing"
+    "github.com/samObot19/shopverse/product-service/query"
+    "github.com/samObot19/shopverse/product-service/repository"
+    "github.com/samObot19/shopverse/product-service/search"
 )
 
 type ProductServiceServer struct {
     pb.UnimplementedProductServiceServer
     useCase *usecases.ProductUseCase
+    changes *feed.Log
 }
 
-// NewProductServiceServer creates a new ProductServiceServer
-func NewProductServiceServer(useCase *usecases.ProductUseCase) *ProductServiceServer {
-    return &ProductServiceServer{useCase: useCase}
+// NewProductServiceServer creates a new ProductServiceServer, streaming the
+// changes appended to changes to watchers
+func NewProductServiceServer(useCase *usecases.ProductUseCase, changes *feed.Log) *ProductServiceServer {
+    return &ProductServiceServer{useCase: useCase, changes: changes}
 }
 
 // Helper functions for time conversion
@@ -28,6 +41,58 @@ func formatTime(t time.Time) string {
     return t.Format(time.RFC3339)
 }
 
+// compareAtPrice is the base price to show struck through while there is a
+// sale price, and nil otherwise
+func compareAtPrice(salePrice *money.Money, base money.Money) *pb.Money {
+    if salePrice == nil {
+        return nil
+    }
+    return toProtoMoney(base)
+}
+
+func toProtoMoney(m money.Money) *pb.Money {
+    return &pb.Money{Amount: m.Amount, Currency: m.Currency}
+}
+
+// toProtoOptionalMoney leaves a nil price unset
+func toProtoOptionalMoney(m *money.Money) *pb.Money {
+    if m == nil {
+        return nil
+    }
+    return toProtoMoney(*m)
+}
+
+// fromProtoMoney converts an unset price to the zero Money, which has no
+// currency and fails validation
+func fromProtoMoney(m *pb.Money) money.Money {
+    return money.New(m.GetAmount(), m.GetCurrency())
+}
+
+// fromProtoOptionalMoney leaves an unset price nil
+func fromProtoOptionalMoney(m *pb.Money) *money.Money {
+    if m == nil {
+        return nil
+    }
+    price := fromProtoMoney(m)
+    return &price
+}
+
+// productError maps a missing product to NotFound, a SKU already used by
+// another product to AlreadyExists and category errors as categoryError does
+func productError(err error) error {
+    switch {
+    case errors.Is(err, repository.ErrProductNotFound), errors.Is(err, usecases.ErrTranslationNotFound):
+        return status.Error(codes.NotFound, err.Error())
+    case errors.Is(err, repository.ErrDuplicateSKU):
+        return status.Error(codes.AlreadyExists, err.Error())
+    case errors.Is(err, repository.ErrVersionConflict):
+        return status.Error(codes.Aborted, err.Error())
+    case errors.Is(err, usecases.ErrVersionRequired), errors.Is(err, pricing.ErrUnsupportedCurrency):
+        return status.Error(codes.InvalidArgument, err.Error())
+    }
+    return categoryError(err)
+}
+
 // CreateProduct handles the gRPC request to create a new product
 func (s *ProductServiceServer) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.CreateProductResponse, error) {
     createdAt, err := parseTime(req.Product.CreatedAt)
@@ -37,23 +102,25 @@ func (s *ProductServiceServer) CreateProduct(ctx context.Context, req *pb.Create
 
     product := &models.Product{
         ID:          req.Product.Id,
+        SKU:         req.Product.Sku,
         Title:       req.Product.Title,
         Description: req.Product.Description,
-        Price:       req.Product.Price,
+        Price:       fromProtoMoney(req.Product.Price),
         Stock:       int(req.Product.Stock),
         Category:    req.Product.Category,
+        CategoryID:  req.Product.CategoryId,
         Attributes: models.ProductAttributes{
             Color: req.Product.Attributes.Color,
             Size:  req.Product.Attributes.Size,
         },
-        Images:    req.Product.Images,
-        Ratings:   req.Product.Ratings,
-        CreatedAt: createdAt,
+        Images:       req.Product.Images,
+        Translations: fromProtoTranslations(req.Product.Translations),
+        CreatedAt:    createdAt,
     }
 
     err = s.useCase.CreateProduct(ctx, product)
     if err != nil {
-        return nil, err
+        return nil, productError(err)
     }
 
     return &pb.CreateProductResponse{Message: "Product created successfully"}, nil
@@ -63,106 +130,112 @@ func (s *ProductServiceServer) CreateProduct(ctx context.Context, req *pb.Create
 func (s *ProductServiceServer) GetProductByID(ctx context.Context, req *pb.GetProductByIDRequest) (*pb.GetProductByIDResponse, error) {
     product, err := s.useCase.GetProductByID(ctx, req.Id)
     if err != nil {
-        return nil, err
+        return nil, productError(err)
     }
 
-    return &pb.GetProductByIDResponse{
-        Product: &pb.Product{
-            Id:          product.ID,
-            Title:       product.Title,
-            Description: product.Description,
-            Price:       product.Price,
-            Stock:       int32(product.Stock),
-            Category:    product.Category,
-            Attributes: &pb.Attributes{
-                Color: product.Attributes.Color,
-                Size:  product.Attributes.Size,
-            },
-            Images:    product.Images,
-            Ratings:   product.Ratings,
-            CreatedAt: formatTime(product.CreatedAt),
-        },
-    }, nil
+    return &pb.GetProductByIDResponse{Product: toProtoProduct(product)}, nil
 }
 
 // GetAllProducts handles the gRPC request to retrieve all products
 func (s *ProductServiceServer) GetAllProducts(ctx context.Context, req *pb.GetAllProductsRequest) (*pb.GetAllProductsResponse, error) {
-    filters := make(map[string]interface{})
-    for key, value := range req.Filters {
-        filters[key] = value
+    var filter *query.Filter
+    if req.Filter != nil {
+        filter = fromProtoFilter(req.Filter)
+    } else {
+        legacy, err := query.FromMap(req.Filters)
+        if err != nil {
+            return nil, status.Errorf(codes.InvalidArgument, "invalid filters: %v", err)
+        }
+        filter = legacy
+    }
+    if err := filter.Validate(); err != nil {
+        return nil, status.Errorf(codes.InvalidArgument, "invalid filter: %v", err)
+    }
+    sort, err := fromProtoSort(req.Sort)
+    if err != nil {
+        return nil, err
     }
 
-    products, err := s.useCase.GetAllProducts(ctx, filters)
+    products, err := s.useCase.GetAllProducts(ctx, filter, sort, req.IncludeArchived)
     if err != nil {
         return nil, err
     }
 
     var pbProducts []*pb.Product
     for _, product := range products {
-        pbProducts = append(pbProducts, &pb.Product{
-            Id:          product.ID,
-            Title:       product.Title,
-            Description: product.Description,
-            Price:       product.Price,
-            Stock:       int32(product.Stock),
-            Category:    product.Category,
-            Attributes: &pb.Attributes{
-                Color: product.Attributes.Color,
-                Size:  product.Attributes.Size,
-            },
-            Images:    product.Images,
-            Ratings:   product.Ratings,
-            CreatedAt: formatTime(product.CreatedAt),
-        })
+        pbProducts = append(pbProducts, toProtoProduct(product))
     }
 
     return &pb.GetAllProductsResponse{Products: pbProducts}, nil
 }
 
-// UpdateProduct handles the gRPC request to update an existing product
+// UpdateProduct handles the gRPC request to set the fields of an existing
+// product named by the update mask
 func (s *ProductServiceServer) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.UpdateProductResponse, error) {
+    if req.Product == nil {
+        return nil, status.Error(codes.InvalidArgument, "product is required")
+    }
+    mask, err := fromProtoProductMask(req.UpdateMask)
+    if err != nil {
+        return nil, err
+    }
     product := &models.Product{
         ID:          req.Product.Id,
+        SKU:         req.Product.Sku,
         Title:       req.Product.Title,
         Description: req.Product.Description,
-        Price:       req.Product.Price,
+        Price:       fromProtoMoney(req.Product.Price),
         Stock:       int(req.Product.Stock),
         Category:    req.Product.Category,
+        CategoryID:  req.Product.CategoryId,
         Attributes: models.ProductAttributes{
-            Color: req.Product.Attributes.Color,
-            Size:  req.Product.Attributes.Size,
+            Color: req.Product.GetAttributes().GetColor(),
+            Size:  req.Product.GetAttributes().GetSize(),
         },
-        Images:    req.Product.Images,
-        Ratings:   req.Product.Ratings,
-        CreatedAt: func() time.Time {
-            parsedTime, _ := parseTime(req.Product.CreatedAt)
-            return parsedTime
-        }(),
+        Images:       req.Product.Images,
+        Translations: fromProtoTranslations(req.Product.Translations),
+        Version:      req.Product.Version,
     }
 
-    err := s.useCase.UpdateProduct(ctx, req.Id, product)
+    err = s.useCase.UpdateProduct(ctx, req.Id, product, mask)
     if err != nil {
-        return nil, err
+        return nil, productError(err)
     }
 
     return &pb.UpdateProductResponse{Message: "Product updated successfully"}, nil
 }
 
-// DeleteProduct handles the gRPC request to delete a product by its ID
+// DeleteProduct handles the gRPC request to archive a product by its ID
 func (s *ProductServiceServer) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
-    err := s.useCase.DeleteProduct(ctx, req.Id)
+    err := s.useCase.DeleteProduct(ctx, req.Id, req.Version)
     if err != nil {
-        return nil, err
+        return nil, productError(err)
+    }
+
+    return &pb.DeleteProductResponse{Message: "Product archived successfully"}, nil
+}
+
+// RestoreProduct handles the gRPC request to bring an archived product back
+func (s *ProductServiceServer) RestoreProduct(ctx context.Context, req *pb.RestoreProductRequest) (*pb.RestoreProductResponse, error) {
+    product, err := s.useCase.RestoreProduct(ctx, req.Id)
+    if err != nil {
+        return nil, productError(err)
     }
 
-    return &pb.DeleteProductResponse{Message: "Product deleted successfully"}, nil
+    return &pb.RestoreProductResponse{Product: toProtoProduct(product)}, nil
 }
 
 // UpdateStock handles the gRPC request to update the stock quantity of a product
 func (s *ProductServiceServer) UpdateStock(ctx context.Context, req *pb.UpdateStockRequest) (*pb.UpdateStockResponse, error) {
-    err := s.useCase.UpdateStock(ctx, req.Id, int(req.Quantity))
+    change := models.StockChange{
+        WarehouseID: req.WarehouseId,
+        Reason:      fromProtoStockReason(req.Reason),
+        Actor:       req.Actor,
+        ReferenceID: req.ReferenceId,
+    }
+    err := s.useCase.UpdateStock(ctx, req.Id, int(req.Quantity), change, req.Version)
     if err != nil {
-        return nil, err
+        return nil, stockError(err)
     }
 
     return &pb.UpdateStockResponse{Message: "Stock updated successfully"}, nil
@@ -170,28 +243,24 @@ func (s *ProductServiceServer) UpdateStock(ctx context.Context, req *pb.UpdateSt
 
 // GetProductsByCategory handles the gRPC request to retrieve products by category
 func (s *ProductServiceServer) GetProductsByCategory(ctx context.Context, req *pb.GetProductsByCategoryRequest) (*pb.GetProductsByCategoryResponse, error) {
-    products, err := s.useCase.GetProductsByCategory(ctx, req.Category)
+    sort, err := fromProtoSort(req.Sort)
     if err != nil {
         return nil, err
     }
 
+    var products []*models.Product
+    if req.CategoryId != "" {
+        products, err = s.useCase.GetProductsInCategory(ctx, req.CategoryId, req.IncludeDescendants, sort)
+    } else {
+        products, err = s.useCase.GetProductsByCategory(ctx, req.Category, sort)
+    }
+    if err != nil {
+        return nil, categoryError(err)
+    }
+
     var pbProducts []*pb.Product
     for _, product := range products {
-        pbProducts = append(pbProducts, &pb.Product{
-            Id:          product.ID,
-            Title:       product.Title,
-            Description: product.Description,
-            Price:       product.Price,
-            Stock:       int32(product.Stock),
-            Category:    product.Category,
-            Attributes: &pb.Attributes{
-                Color: product.Attributes.Color,
-                Size:  product.Attributes.Size,
-            },
-            Images:    product.Images,
-            Ratings:   product.Ratings,
-            CreatedAt: formatTime(product.CreatedAt),
-        })
+        pbProducts = append(pbProducts, toProtoProduct(product))
     }
 
     return &pb.GetProductsByCategoryResponse{Products: pbProducts}, nil
@@ -199,29 +268,203 @@ func (s *ProductServiceServer) GetProductsByCategory(ctx context.Context, req *p
 
 // SearchProducts handles the gRPC request to search for products
 func (s *ProductServiceServer) SearchProducts(ctx context.Context, req *pb.SearchProductsRequest) (*pb.SearchProductsResponse, error) {
-    products, err := s.useCase.SearchProducts(ctx, req.Query)
+    sort, err := fromProtoSort(req.Sort)
+    if err != nil {
+        return nil, err
+    }
+
+    result, err := s.useCase.SearchProducts(ctx, search.Request{
+        Text:   req.Query,
+        Sort:   sort,
+        Limit:  int(req.Limit),
+        Offset: int(req.Offset),
+    })
     if err != nil {
         return nil, err
     }
 
     var pbProducts []*pb.Product
-    for _, product := range products {
-        pbProducts = append(pbProducts, &pb.Product{
-            Id:          product.ID,
-            Title:       product.Title,
-            Description: product.Description,
-            Price:       product.Price,
-            Stock:       int32(product.Stock),
-            Category:    product.Category,
-            Attributes: &pb.Attributes{
-                Color: product.Attributes.Color,
-                Size:  product.Attributes.Size,
-            },
-            Images:    product.Images,
-            Ratings:   product.Ratings,
-            CreatedAt: formatTime(product.CreatedAt),
+    for _, product := range result.Products {
+        pbProducts = append(pbProducts, toProtoProduct(product))
+    }
+
+    return &pb.SearchProductsResponse{
+        Products: pbProducts,
+        Total:    int32(result.Total),
+        Facets:   toProtoFacets(result.Facets),
+    }, nil
+}
+
+// toProtoProduct converts a product read through the use case, with its prices applied
+func toProtoProduct(product *models.Product) *pb.Product {
+    return &pb.Product{
+        Id:          product.ID,
+        Sku:         product.SKU,
+        Title:       product.Title,
+        Description: product.Description,
+        Price:       toProtoMoney(product.Price),
+        Stock:       int32(product.Stock),
+        Category:    product.Category,
+        CategoryId:  product.CategoryID,
+        Attributes: &pb.Attributes{
+            Color: product.Attributes.Color,
+            Size:  product.Attributes.Size,
+        },
+        Images:         product.Images,
+        Ratings:        product.Ratings,
+        ReviewCount:    int32(product.ReviewCount),
+        EffectivePrice: toProtoMoney(product.EffectivePrice()),
+        CompareAtPrice: compareAtPrice(product.SalePrice, product.Price),
+        CreatedAt:      formatTime(product.CreatedAt),
+        DeletedAt:      formatOptionalTime(product.DeletedAt),
+        Version:        product.Version,
+        Conversion:     toProtoConversion(product.Conversion),
+        Translations:   toProtoTranslations(product.Translations),
+        Locale:         product.Locale,
+    }
+}
+
+// fromProtoProductMask maps the paths of an update mask to the product fields
+// they set; "attributes" sets both the color and the sizes, and an empty mask
+// sets every field but the translations, which clients that predate them
+// would otherwise erase
+func fromProtoProductMask(mask *fieldmaskpb.FieldMask) (models.ProductMask, error) {
+    if len(mask.GetPaths()) == 0 {
+        full := models.FullProductMask()
+        delete(full, models.ProductTranslations)
+        return full, nil
+    }
+    fields := make([]models.ProductField, 0, len(mask.GetPaths()))
+    for _, path := range mask.GetPaths() {
+        if path == "attributes" {
+            fields = append(fields, models.ProductColor, models.ProductSizes)
+            continue
+        }
+        field := models.ProductField(path)
+        if !isProductField(field) {
+            return nil, status.Errorf(codes.InvalidArgument, "update mask: %q is not a product field that can be updated", path)
+        }
+        fields = append(fields, field)
+    }
+    return models.NewProductMask(fields...), nil
+}
+
+func isProductField(field models.ProductField) bool {
+    for _, f := range models.ProductFields {
+        if f == field {
+            return true
+        }
+    }
+    return false
+}
+
+func toProtoFacets(f search.Facets) *pb.SearchFacets {
+    facets := &pb.SearchFacets{
+        Category: toProtoFacetValues(f.Category),
+        Color:    toProtoFacetValues(f.Color),
+        Size:     toProtoFacetValues(f.Size),
+    }
+    for _, bucket := range f.Price {
+        facets.Price = append(facets.Price, &pb.PriceBucket{
+            Min:      bucket.Min,
+            Max:      bucket.Max,
+            Count:    int32(bucket.Count),
+            Currency: bucket.Currency,
+        })
+    }
+    return facets
+}
+
+func toProtoFacetValues(values []search.FacetValue) []*pb.FacetValue {
+    var pbValues []*pb.FacetValue
+    for _, v := range values {
+        pbValues = append(pbValues, &pb.FacetValue{Value: v.Value, Count: int32(v.Count)})
+    }
+    return pbValues
+}
+
+var suggestionKinds = map[search.SuggestionKind]pb.SuggestionKind{
+    search.SuggestTitle:    pb.SuggestionKind_SUGGESTION_KIND_TITLE,
+    search.SuggestCategory: pb.SuggestionKind_SUGGESTION_KIND_CATEGORY,
+}
+
+// SuggestProducts handles the gRPC request to autocomplete a search prefix
+func (s *ProductServiceServer) SuggestProducts(ctx context.Context, req *pb.SuggestProductsRequest) (*pb.SuggestProductsResponse, error) {
+    suggestions, err := s.useCase.SuggestProducts(ctx, req.Prefix, int(req.Limit))
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, err.Error())
+    }
+
+    var pbSuggestions []*pb.Suggestion
+    for _, suggestion := range suggestions {
+        pbSuggestions = append(pbSuggestions, &pb.Suggestion{
+            Text:      suggestion.Text,
+            Kind:      suggestionKinds[suggestion.Kind],
+            ProductId: suggestion.ProductID,
+        })
+    }
+    return &pb.SuggestProductsResponse{Suggestions: pbSuggestions}, nil
+}
+
+var filterFields = map[pb.FilterField]query.Field{
+    pb.FilterField_FILTER_FIELD_CATEGORY: query.FieldCategory,
+    pb.FilterField_FILTER_FIELD_COLOR:    query.FieldColor,
+    pb.FilterField_FILTER_FIELD_SIZE:     query.FieldSize,
+    pb.FilterField_FILTER_FIELD_PRICE:    query.FieldPrice,
+    pb.FilterField_FILTER_FIELD_STOCK:    query.FieldStock,
+    pb.FilterField_FILTER_FIELD_RATINGS:  query.FieldRatings,
+}
+
+var filterOperators = map[pb.FilterOperator]query.Operator{
+    pb.FilterOperator_FILTER_OPERATOR_EQ:    query.OpEq,
+    pb.FilterOperator_FILTER_OPERATOR_IN:    query.OpIn,
+    pb.FilterOperator_FILTER_OPERATOR_RANGE: query.OpRange,
+    pb.FilterOperator_FILTER_OPERATOR_GTE:   query.OpGte,
+    pb.FilterOperator_FILTER_OPERATOR_LTE:   query.OpLte,
+    pb.FilterOperator_FILTER_OPERATOR_GT:    query.OpGt,
+}
+
+// fromProtoFilter converts a ProductFilter; unknown enum values map to empty
+// fields and operators, which query.Filter.Validate rejects
+func fromProtoFilter(f *pb.ProductFilter) *query.Filter {
+    filter := &query.Filter{Logic: query.And}
+    if f.Operator == pb.LogicalOperator_LOGICAL_OPERATOR_OR {
+        filter.Logic = query.Or
+    }
+    for _, c := range f.Conditions {
+        filter.Conditions = append(filter.Conditions, query.Condition{
+            Field:    filterFields[c.Field],
+            Operator: filterOperators[c.Operator],
+            Values:   c.Values,
+            Min:      c.Min,
+            Max:      c.Max,
         })
     }
+    for _, g := range f.Groups {
+        filter.Groups = append(filter.Groups, fromProtoFilter(g))
+    }
+    return filter
+}
+
+var sortFields = map[pb.SortField]query.SortField{
+    pb.SortField_SORT_FIELD_PRICE:      query.SortPrice,
+    pb.SortField_SORT_FIELD_CREATED_AT: query.SortCreatedAt,
+    pb.SortField_SORT_FIELD_RATINGS:    query.SortRatings,
+    pb.SortField_SORT_FIELD_TITLE:      query.SortTitle,
+}
 
-    return &pb.SearchProductsResponse{Products: pbProducts}, nil
-}
\ No newline at end of file
+// fromProtoSort converts and validates the sort keys of a request
+func fromProtoSort(keys []*pb.SortKey) ([]query.Sort, error) {
+    sort := make([]query.Sort, 0, len(keys))
+    for _, key := range keys {
+        s := query.Sort{Field: sortFields[key.Field], Direction: query.Asc}
+        if key.Direction == pb.SortDirection_SORT_DIRECTION_DESC {
+            s.Direction = query.Desc
+        }
+        sort = append(sort, s)
+    }
+    if err := query.ValidateSort(sort); err != nil {
+        return nil, status.Errorf(codes.InvalidArgument, "invalid sort: %v", err)
+    }
+    return sort, nil
+}
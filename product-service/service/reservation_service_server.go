@@ -0,0 +1,94 @@
+package service
+
+import (
+    "context"
+    "errors"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+
+    pb "github.com/samObot19/shopverse/product-service/proto/pb"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+// ReserveStock handles the gRPC request to hold stock for a checkout
+func (s *ProductServiceServer) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+    items := make([]models.ReservationItem, 0, len(req.Items))
+    for _, item := range req.Items {
+        items = append(items, models.ReservationItem{
+            ProductID: item.ProductId,
+            VariantID: item.VariantId,
+            Quantity:  int(item.Quantity),
+        })
+    }
+
+    var destination *models.Location
+    if req.Destination != nil {
+        destination = &models.Location{Latitude: req.Destination.Latitude, Longitude: req.Destination.Longitude}
+    }
+
+    reservation, err := s.useCase.ReserveStock(ctx, req.ReferenceId, items, destination)
+    if err != nil {
+        return nil, reservationError(err)
+    }
+    return &pb.ReserveStockResponse{Reservation: toProtoReservation(reservation)}, nil
+}
+
+// CommitReservation handles the gRPC request to turn a reservation into a sale
+func (s *ProductServiceServer) CommitReservation(ctx context.Context, req *pb.CommitReservationRequest) (*pb.CommitReservationResponse, error) {
+    reservation, err := s.useCase.CommitReservation(ctx, req.Id)
+    if err != nil {
+        return nil, reservationError(err)
+    }
+    return &pb.CommitReservationResponse{Reservation: toProtoReservation(reservation)}, nil
+}
+
+// ReleaseReservation handles the gRPC request to give reserved stock back
+func (s *ProductServiceServer) ReleaseReservation(ctx context.Context, req *pb.ReleaseReservationRequest) (*pb.ReleaseReservationResponse, error) {
+    reservation, err := s.useCase.ReleaseReservation(ctx, req.Id)
+    if err != nil {
+        return nil, reservationError(err)
+    }
+    return &pb.ReleaseReservationResponse{Reservation: toProtoReservation(reservation)}, nil
+}
+
+// reservationError maps missing reservations to NotFound and reservations that
+// cannot proceed to FailedPrecondition, and passes other errors through
+func reservationError(err error) error {
+    switch {
+    case errors.Is(err, repository.ErrReservationNotFound):
+        return status.Error(codes.NotFound, err.Error())
+    case errors.Is(err, repository.ErrInsufficientStock), errors.Is(err, repository.ErrReservationClosed):
+        return status.Error(codes.FailedPrecondition, err.Error())
+    }
+    return err
+}
+
+var reservationStatuses = map[models.ReservationStatus]pb.ReservationStatus{
+    models.ReservationPending:   pb.ReservationStatus_RESERVATION_STATUS_PENDING,
+    models.ReservationCommitted: pb.ReservationStatus_RESERVATION_STATUS_COMMITTED,
+    models.ReservationReleased:  pb.ReservationStatus_RESERVATION_STATUS_RELEASED,
+    models.ReservationExpired:   pb.ReservationStatus_RESERVATION_STATUS_EXPIRED,
+}
+
+func toProtoReservation(r *models.Reservation) *pb.Reservation {
+    items := make([]*pb.ReservationItem, 0, len(r.Items))
+    for _, item := range r.Items {
+        items = append(items, &pb.ReservationItem{
+            ProductId:   item.ProductID,
+            VariantId:   item.VariantID,
+            Quantity:    int32(item.Quantity),
+            WarehouseId: item.WarehouseID,
+        })
+    }
+
+    return &pb.Reservation{
+        Id:          r.ID,
+        ReferenceId: r.ReferenceID,
+        Status:      reservationStatuses[r.Status],
+        Items:       items,
+        ExpiresAt:   formatTime(r.ExpiresAt),
+        CreatedAt:   formatTime(r.CreatedAt),
+    }
+}
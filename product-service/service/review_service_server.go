@@ -0,0 +1,149 @@
+package service
+
+import (
+    "context"
+    "errors"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+
+    pb "github.com/samObot19/shopverse/product-service/proto/pb"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+    "github.com/samObot19/shopverse/product-service/usecases"
+)
+
+// CreateReview handles the gRPC request to review a product
+func (s *ProductServiceServer) CreateReview(ctx context.Context, req *pb.CreateReviewRequest) (*pb.CreateReviewResponse, error) {
+    id, err := s.useCase.CreateReview(ctx, &models.Review{
+        ProductID: req.ProductId,
+        UserID:    req.UserId,
+        Rating:    int(req.Rating),
+        Title:     req.Title,
+        Body:      req.Body,
+    })
+    if err != nil {
+        return nil, reviewError(err)
+    }
+    return &pb.CreateReviewResponse{Id: id}, nil
+}
+
+// GetReview handles the gRPC request to retrieve a review by its ID
+func (s *ProductServiceServer) GetReview(ctx context.Context, req *pb.GetReviewRequest) (*pb.GetReviewResponse, error) {
+    review, err := s.useCase.GetReview(ctx, req.Id)
+    if err != nil {
+        return nil, reviewError(err)
+    }
+    return &pb.GetReviewResponse{Review: toProtoReview(review)}, nil
+}
+
+// ListReviews handles the gRPC request to page through reviews
+func (s *ProductServiceServer) ListReviews(ctx context.Context, req *pb.ListReviewsRequest) (*pb.ListReviewsResponse, error) {
+    var reviewStatus models.ReviewStatus
+    if req.Status != pb.ReviewStatus_REVIEW_STATUS_UNSPECIFIED {
+        var err error
+        if reviewStatus, err = fromProtoReviewStatus(req.Status); err != nil {
+            return nil, err
+        }
+    }
+
+    reviews, err := s.useCase.ListReviews(ctx, req.ProductId, reviewStatus, int(req.Limit), int(req.Offset))
+    if err != nil {
+        return nil, reviewError(err)
+    }
+    pbReviews := make([]*pb.Review, 0, len(reviews))
+    for _, review := range reviews {
+        pbReviews = append(pbReviews, toProtoReview(review))
+    }
+    return &pb.ListReviewsResponse{Reviews: pbReviews}, nil
+}
+
+// EditReview handles the gRPC request of an author to change their review
+func (s *ProductServiceServer) EditReview(ctx context.Context, req *pb.EditReviewRequest) (*pb.EditReviewResponse, error) {
+    err := s.useCase.EditReview(ctx, req.Id, req.UserId, &models.Review{
+        Rating: int(req.Rating),
+        Title:  req.Title,
+        Body:   req.Body,
+    })
+    if err != nil {
+        return nil, reviewError(err)
+    }
+    return &pb.EditReviewResponse{Message: "Review updated successfully"}, nil
+}
+
+// ModerateReview handles the gRPC request to approve or reject a review
+func (s *ProductServiceServer) ModerateReview(ctx context.Context, req *pb.ModerateReviewRequest) (*pb.ModerateReviewResponse, error) {
+    reviewStatus, err := fromProtoReviewStatus(req.Status)
+    if err != nil {
+        return nil, err
+    }
+    if err := s.useCase.ModerateReview(ctx, req.Id, reviewStatus); err != nil {
+        return nil, reviewError(err)
+    }
+    return &pb.ModerateReviewResponse{Message: "Review moderated successfully"}, nil
+}
+
+// DeleteReview handles the gRPC request to delete a review
+func (s *ProductServiceServer) DeleteReview(ctx context.Context, req *pb.DeleteReviewRequest) (*pb.DeleteReviewResponse, error) {
+    if err := s.useCase.DeleteReview(ctx, req.Id, req.UserId); err != nil {
+        return nil, reviewError(err)
+    }
+    return &pb.DeleteReviewResponse{Message: "Review deleted successfully"}, nil
+}
+
+// VoteReviewHelpful handles the gRPC request to mark a review as helpful
+func (s *ProductServiceServer) VoteReviewHelpful(ctx context.Context, req *pb.VoteReviewHelpfulRequest) (*pb.VoteReviewHelpfulResponse, error) {
+    if err := s.useCase.VoteReviewHelpful(ctx, req.Id, req.UserId); err != nil {
+        return nil, reviewError(err)
+    }
+    return &pb.VoteReviewHelpfulResponse{Message: "Vote recorded successfully"}, nil
+}
+
+// reviewError maps a missing review to NotFound, a second review of the same
+// product to AlreadyExists, a review without a delivered order or by someone
+// else to PermissionDenied and a vote the review cannot take to
+// FailedPrecondition, and passes other errors through
+func reviewError(err error) error {
+    switch {
+    case errors.Is(err, repository.ErrReviewNotFound):
+        return status.Error(codes.NotFound, err.Error())
+    case errors.Is(err, repository.ErrDuplicateReview):
+        return status.Error(codes.AlreadyExists, err.Error())
+    case errors.Is(err, usecases.ErrNotPurchased), errors.Is(err, usecases.ErrNotReviewAuthor):
+        return status.Error(codes.PermissionDenied, err.Error())
+    case errors.Is(err, usecases.ErrOwnReviewVote), errors.Is(err, usecases.ErrReviewNotApproved):
+        return status.Error(codes.FailedPrecondition, err.Error())
+    }
+    return err
+}
+
+var reviewStatuses = map[models.ReviewStatus]pb.ReviewStatus{
+    models.ReviewPending:  pb.ReviewStatus_REVIEW_STATUS_PENDING,
+    models.ReviewApproved: pb.ReviewStatus_REVIEW_STATUS_APPROVED,
+    models.ReviewRejected: pb.ReviewStatus_REVIEW_STATUS_REJECTED,
+}
+
+func fromProtoReviewStatus(s pb.ReviewStatus) (models.ReviewStatus, error) {
+    for reviewStatus, protoStatus := range reviewStatuses {
+        if protoStatus == s {
+            return reviewStatus, nil
+        }
+    }
+    return "", status.Errorf(codes.InvalidArgument, "unsupported review status %v", s)
+}
+
+func toProtoReview(r *models.Review) *pb.Review {
+    return &pb.Review{
+        Id:           r.ID,
+        ProductId:    r.ProductID,
+        UserId:       r.UserID,
+        OrderId:      r.OrderID,
+        Rating:       int32(r.Rating),
+        Title:        r.Title,
+        Body:         r.Body,
+        Status:       reviewStatuses[r.Status],
+        HelpfulVotes: int32(r.HelpfulVotes),
+        CreatedAt:    formatTime(r.CreatedAt),
+        UpdatedAt:    formatTime(r.UpdatedAt),
+    }
+}
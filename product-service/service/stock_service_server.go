@@ -0,0 +1,112 @@
+package service
+
+import (
+    "context"
+    "errors"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+
+    pb "github.com/samObot19/shopverse/product-service/proto/pb"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+// AdjustStock handles the gRPC request to change a stock level by a signed amount
+func (s *ProductServiceServer) AdjustStock(ctx context.Context, req *pb.AdjustStockRequest) (*pb.AdjustStockResponse, error) {
+    movement := &models.StockMovement{
+        ProductID:   req.ProductId,
+        VariantID:   req.VariantId,
+        WarehouseID: req.WarehouseId,
+        Delta:       int(req.Delta),
+        Reason:      fromProtoStockReason(req.Reason),
+        Actor:       req.Actor,
+        ReferenceID: req.ReferenceId,
+    }
+    if err := s.useCase.AdjustStock(ctx, movement); err != nil {
+        return nil, stockError(err)
+    }
+    return &pb.AdjustStockResponse{Movement: toProtoStockMovement(movement)}, nil
+}
+
+// GetStockHistory handles the gRPC request to page through the stock ledger of a product
+func (s *ProductServiceServer) GetStockHistory(ctx context.Context, req *pb.GetStockHistoryRequest) (*pb.GetStockHistoryResponse, error) {
+    movements, next, err := s.useCase.GetStockHistory(ctx, req.ProductId, req.VariantId, req.PageToken, int(req.PageSize))
+    if err != nil {
+        return nil, err
+    }
+
+    var pbMovements []*pb.StockMovement
+    for _, movement := range movements {
+        pbMovements = append(pbMovements, toProtoStockMovement(movement))
+    }
+    return &pb.GetStockHistoryResponse{Movements: pbMovements, NextPageToken: next}, nil
+}
+
+// ReconcileStock handles the gRPC request to compare stock levels with the stock ledger
+func (s *ProductServiceServer) ReconcileStock(ctx context.Context, req *pb.ReconcileStockRequest) (*pb.ReconcileStockResponse, error) {
+    discrepancies, err := s.useCase.ReconcileStock(ctx)
+    if err != nil {
+        return nil, status.Errorf(codes.Internal, "failed to reconcile stock: %v", err)
+    }
+
+    var pbDiscrepancies []*pb.StockDiscrepancy
+    for _, d := range discrepancies {
+        pbDiscrepancies = append(pbDiscrepancies, &pb.StockDiscrepancy{
+            ProductId: d.ProductID,
+            VariantId: d.VariantID,
+            Stock:     int32(d.Stock),
+            Ledger:    int32(d.Ledger),
+        })
+    }
+    return &pb.ReconcileStockResponse{Discrepancies: pbDiscrepancies}, nil
+}
+
+// stockError maps a missing variant or warehouse to NotFound and a lack of
+// stock to FailedPrecondition, and passes other errors through
+func stockError(err error) error {
+    switch {
+    case errors.Is(err, repository.ErrInsufficientStock):
+        return status.Error(codes.FailedPrecondition, err.Error())
+    case errors.Is(err, repository.ErrWarehouseNotFound):
+        return status.Error(codes.NotFound, err.Error())
+    }
+    return variantError(err)
+}
+
+var stockReasons = map[pb.StockReason]models.StockReason{
+    pb.StockReason_STOCK_REASON_RECEIVING:   models.StockReceiving,
+    pb.StockReason_STOCK_REASON_SALE:        models.StockSale,
+    pb.StockReason_STOCK_REASON_RESERVATION: models.StockReservation,
+    pb.StockReason_STOCK_REASON_RETURN:      models.StockReturn,
+    pb.StockReason_STOCK_REASON_ADJUSTMENT:  models.StockAdjustment,
+}
+
+// fromProtoStockReason maps an unspecified reason to the empty reason, which
+// the use case treats as an adjustment
+func fromProtoStockReason(reason pb.StockReason) models.StockReason {
+    return stockReasons[reason]
+}
+
+func toProtoStockReason(reason models.StockReason) pb.StockReason {
+    for pbReason, r := range stockReasons {
+        if r == reason {
+            return pbReason
+        }
+    }
+    return pb.StockReason_STOCK_REASON_UNSPECIFIED
+}
+
+func toProtoStockMovement(m *models.StockMovement) *pb.StockMovement {
+    return &pb.StockMovement{
+        Id:          m.ID,
+        ProductId:   m.ProductID,
+        VariantId:   m.VariantID,
+        WarehouseId: m.WarehouseID,
+        Delta:       int32(m.Delta),
+        Reason:      toProtoStockReason(m.Reason),
+        Actor:       m.Actor,
+        ReferenceId: m.ReferenceID,
+        CreatedAt:   formatTime(m.CreatedAt),
+    }
+}
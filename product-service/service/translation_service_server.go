@@ -0,0 +1,61 @@
+package service
+
+import (
+    "context"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+
+    pb "github.com/samObot19/shopverse/product-service/proto/pb"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// SetProductTranslation handles the gRPC request to add or replace the translation of a product into a locale
+func (s *ProductServiceServer) SetProductTranslation(ctx context.Context, req *pb.SetProductTranslationRequest) (*pb.SetProductTranslationResponse, error) {
+    if req.Translation == nil {
+        return nil, status.Error(codes.InvalidArgument, "translation is required")
+    }
+    translation := models.Translation{Title: req.Translation.Title, Description: req.Translation.Description}
+    if err := s.useCase.SetProductTranslation(ctx, req.ProductId, req.Locale, translation, req.Version); err != nil {
+        return nil, productError(err)
+    }
+    product, err := s.useCase.GetProductByID(ctx, req.ProductId)
+    if err != nil {
+        return nil, productError(err)
+    }
+    return &pb.SetProductTranslationResponse{Product: toProtoProduct(product)}, nil
+}
+
+// DeleteProductTranslation handles the gRPC request to remove the translation of a product into a locale
+func (s *ProductServiceServer) DeleteProductTranslation(ctx context.Context, req *pb.DeleteProductTranslationRequest) (*pb.DeleteProductTranslationResponse, error) {
+    if err := s.useCase.DeleteProductTranslation(ctx, req.ProductId, req.Locale, req.Version); err != nil {
+        return nil, productError(err)
+    }
+    product, err := s.useCase.GetProductByID(ctx, req.ProductId)
+    if err != nil {
+        return nil, productError(err)
+    }
+    return &pb.DeleteProductTranslationResponse{Product: toProtoProduct(product)}, nil
+}
+
+func fromProtoTranslations(translations map[string]*pb.Translation) models.Translations {
+    if len(translations) == 0 {
+        return nil
+    }
+    t := make(models.Translations, len(translations))
+    for locale, translation := range translations {
+        t[locale] = models.Translation{Title: translation.GetTitle(), Description: translation.GetDescription()}
+    }
+    return t
+}
+
+func toProtoTranslations(translations models.Translations) map[string]*pb.Translation {
+    if len(translations) == 0 {
+        return nil
+    }
+    t := make(map[string]*pb.Translation, len(translations))
+    for locale, translation := range translations {
+        t[locale] = &pb.Translation{Title: translation.Title, Description: translation.Description}
+    }
+    return t
+}
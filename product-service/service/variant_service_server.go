@@ -0,0 +1,127 @@
+package service
+
+import (
+    "context"
+    "errors"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+
+    pb "github.com/samObot19/shopverse/product-service/proto/pb"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+// CreateVariant handles the gRPC request to create a product variant
+func (s *ProductServiceServer) CreateVariant(ctx context.Context, req *pb.CreateVariantRequest) (*pb.CreateVariantResponse, error) {
+    if req.Variant == nil {
+        return nil, status.Error(codes.InvalidArgument, "variant is required")
+    }
+
+    variant := fromProtoVariant(req.Variant)
+    if req.Variant.CreatedAt != "" {
+        createdAt, err := parseTime(req.Variant.CreatedAt)
+        if err != nil {
+            return nil, status.Errorf(codes.InvalidArgument, "invalid created_at: %v", err)
+        }
+        variant.CreatedAt = createdAt
+    }
+
+    id, err := s.useCase.CreateVariant(ctx, variant)
+    if err != nil {
+        return nil, variantError(err)
+    }
+    return &pb.CreateVariantResponse{Id: id}, nil
+}
+
+// GetVariant handles the gRPC request to retrieve a variant by its ID
+func (s *ProductServiceServer) GetVariant(ctx context.Context, req *pb.GetVariantRequest) (*pb.GetVariantResponse, error) {
+    variant, product, err := s.useCase.GetVariant(ctx, req.Id)
+    if err != nil {
+        return nil, variantError(err)
+    }
+    return &pb.GetVariantResponse{Variant: toProtoVariant(variant, product)}, nil
+}
+
+// ListVariants handles the gRPC request to list the variants of a product
+func (s *ProductServiceServer) ListVariants(ctx context.Context, req *pb.ListVariantsRequest) (*pb.ListVariantsResponse, error) {
+    product, variants, err := s.useCase.ListVariants(ctx, req.ProductId)
+    if err != nil {
+        return nil, variantError(err)
+    }
+
+    var pbVariants []*pb.Variant
+    for _, variant := range variants {
+        pbVariants = append(pbVariants, toProtoVariant(variant, product))
+    }
+    return &pb.ListVariantsResponse{Variants: pbVariants}, nil
+}
+
+// UpdateVariant handles the gRPC request to update a variant
+func (s *ProductServiceServer) UpdateVariant(ctx context.Context, req *pb.UpdateVariantRequest) (*pb.UpdateVariantResponse, error) {
+    if req.Variant == nil {
+        return nil, status.Error(codes.InvalidArgument, "variant is required")
+    }
+    if err := s.useCase.UpdateVariant(ctx, req.Id, fromProtoVariant(req.Variant)); err != nil {
+        return nil, variantError(err)
+    }
+    return &pb.UpdateVariantResponse{Message: "Variant updated successfully"}, nil
+}
+
+// DeleteVariant handles the gRPC request to delete a variant
+func (s *ProductServiceServer) DeleteVariant(ctx context.Context, req *pb.DeleteVariantRequest) (*pb.DeleteVariantResponse, error) {
+    if err := s.useCase.DeleteVariant(ctx, req.Id); err != nil {
+        return nil, variantError(err)
+    }
+    return &pb.DeleteVariantResponse{Message: "Variant deleted successfully"}, nil
+}
+
+// UpdateVariantStock handles the gRPC request to set the stock of a variant
+func (s *ProductServiceServer) UpdateVariantStock(ctx context.Context, req *pb.UpdateVariantStockRequest) (*pb.UpdateVariantStockResponse, error) {
+    change := models.StockChange{
+        WarehouseID: req.WarehouseId,
+        Reason:      fromProtoStockReason(req.Reason),
+        Actor:       req.Actor,
+        ReferenceID: req.ReferenceId,
+    }
+    if err := s.useCase.UpdateVariantStock(ctx, req.Id, int(req.Quantity), change); err != nil {
+        return nil, stockError(err)
+    }
+    return &pb.UpdateVariantStockResponse{Message: "Variant stock updated successfully"}, nil
+}
+
+// variantError maps a missing variant to NotFound and passes other errors through
+func variantError(err error) error {
+    if errors.Is(err, repository.ErrVariantNotFound) {
+        return status.Error(codes.NotFound, err.Error())
+    }
+    return productError(err)
+}
+
+func fromProtoVariant(v *pb.Variant) *models.Variant {
+    return &models.Variant{
+        ID:        v.Id,
+        ProductID: v.ProductId,
+        SKU:       v.Sku,
+        Options:   v.Options,
+        Price:     fromProtoOptionalMoney(v.Price),
+        Stock:     int(v.Stock),
+        Images:    v.Images,
+    }
+}
+
+func toProtoVariant(v *models.Variant, product *models.Product) *pb.Variant {
+    return &pb.Variant{
+        Id:             v.ID,
+        ProductId:      v.ProductID,
+        Sku:            v.SKU,
+        Options:        v.Options,
+        Price:          toProtoOptionalMoney(v.Price),
+        Stock:          int32(v.Stock),
+        Images:         v.Images,
+        CreatedAt:      formatTime(v.CreatedAt),
+        EffectivePrice: toProtoMoney(v.EffectivePrice(product)),
+        CompareAtPrice: compareAtPrice(v.SalePrice, v.BasePrice(product)),
+        Conversion:     toProtoConversion(v.Conversion),
+    }
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+    "context"
+    "errors"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+
+    pb "github.com/samObot19/shopverse/product-service/proto/pb"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+// CreateWarehouse handles the gRPC request to create a warehouse
+func (s *ProductServiceServer) CreateWarehouse(ctx context.Context, req *pb.CreateWarehouseRequest) (*pb.CreateWarehouseResponse, error) {
+    if req.Warehouse == nil {
+        return nil, status.Error(codes.InvalidArgument, "warehouse is required")
+    }
+    id, err := s.useCase.CreateWarehouse(ctx, fromProtoWarehouse(req.Warehouse))
+    if err != nil {
+        return nil, err
+    }
+    return &pb.CreateWarehouseResponse{Id: id}, nil
+}
+
+// GetWarehouse handles the gRPC request to retrieve a warehouse
+func (s *ProductServiceServer) GetWarehouse(ctx context.Context, req *pb.GetWarehouseRequest) (*pb.GetWarehouseResponse, error) {
+    warehouse, err := s.useCase.GetWarehouse(ctx, req.Id)
+    if err != nil {
+        return nil, warehouseError(err)
+    }
+    return &pb.GetWarehouseResponse{Warehouse: toProtoWarehouse(warehouse)}, nil
+}
+
+// ListWarehouses handles the gRPC request to list all warehouses
+func (s *ProductServiceServer) ListWarehouses(ctx context.Context, req *pb.ListWarehousesRequest) (*pb.ListWarehousesResponse, error) {
+    warehouses, err := s.useCase.ListWarehouses(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    pbWarehouses := make([]*pb.Warehouse, 0, len(warehouses))
+    for _, warehouse := range warehouses {
+        pbWarehouses = append(pbWarehouses, toProtoWarehouse(warehouse))
+    }
+    return &pb.ListWarehousesResponse{Warehouses: pbWarehouses}, nil
+}
+
+// UpdateWarehouse handles the gRPC request to update a warehouse
+func (s *ProductServiceServer) UpdateWarehouse(ctx context.Context, req *pb.UpdateWarehouseRequest) (*pb.UpdateWarehouseResponse, error) {
+    if req.Warehouse == nil {
+        return nil, status.Error(codes.InvalidArgument, "warehouse is required")
+    }
+    if err := s.useCase.UpdateWarehouse(ctx, req.Id, fromProtoWarehouse(req.Warehouse)); err != nil {
+        return nil, warehouseError(err)
+    }
+    return &pb.UpdateWarehouseResponse{Message: "Warehouse updated successfully"}, nil
+}
+
+// GetStockLevels handles the gRPC request to list the stock of a product per warehouse
+func (s *ProductServiceServer) GetStockLevels(ctx context.Context, req *pb.GetStockLevelsRequest) (*pb.GetStockLevelsResponse, error) {
+    levels, err := s.useCase.GetStockLevels(ctx, req.ProductId)
+    if err != nil {
+        return nil, err
+    }
+
+    pbLevels := make([]*pb.StockLevel, 0, len(levels))
+    for _, level := range levels {
+        pbLevels = append(pbLevels, &pb.StockLevel{
+            WarehouseId: level.WarehouseID,
+            ProductId:   level.ProductID,
+            VariantId:   level.VariantID,
+            Quantity:    int32(level.Quantity),
+        })
+    }
+    return &pb.GetStockLevelsResponse{Levels: pbLevels}, nil
+}
+
+// warehouseError maps a missing warehouse to NotFound and passes other errors through
+func warehouseError(err error) error {
+    if errors.Is(err, repository.ErrWarehouseNotFound) {
+        return status.Error(codes.NotFound, err.Error())
+    }
+    return err
+}
+
+func fromProtoWarehouse(w *pb.Warehouse) *models.Warehouse {
+    warehouse := &models.Warehouse{
+        ID:      w.Id,
+        Name:    w.Name,
+        Address: w.Address,
+        Active:  w.Active,
+    }
+    if w.Location != nil {
+        warehouse.Latitude = w.Location.Latitude
+        warehouse.Longitude = w.Location.Longitude
+    }
+    return warehouse
+}
+
+func toProtoWarehouse(w *models.Warehouse) *pb.Warehouse {
+    return &pb.Warehouse{
+        Id:        w.ID,
+        Name:      w.Name,
+        Address:   w.Address,
+        Location:  &pb.Location{Latitude: w.Latitude, Longitude: w.Longitude},
+        Active:    w.Active,
+        CreatedAt: formatTime(w.CreatedAt),
+    }
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+    "errors"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+
+    pb "github.com/samObot19/shopverse/product-service/proto/pb"
+    "github.com/samObot19/shopverse/product-service/feed"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// watchBatch caps the changes read from the log at a time
+const watchBatch = 100
+
+var productChangeTypes = map[models.EventType]pb.ProductChangeType{
+    models.EventProductCreated:  pb.ProductChangeType_PRODUCT_CHANGE_TYPE_CREATED,
+    models.EventProductUpdated:  pb.ProductChangeType_PRODUCT_CHANGE_TYPE_UPDATED,
+    models.EventProductArchived: pb.ProductChangeType_PRODUCT_CHANGE_TYPE_ARCHIVED,
+    models.EventProductRestored: pb.ProductChangeType_PRODUCT_CHANGE_TYPE_RESTORED,
+    models.EventProductDeleted:  pb.ProductChangeType_PRODUCT_CHANGE_TYPE_DELETED,
+    models.EventStockChanged:    pb.ProductChangeType_PRODUCT_CHANGE_TYPE_STOCK_CHANGED,
+}
+
+// WatchProducts streams the changes of the change log from the resume token,
+// or from now, until the client goes away. Each stream reads the log at its
+// own pace: Send blocks while the client is not keeping up, and a client that
+// falls behind the changes the log keeps gets OutOfRange.
+func (s *ProductServiceServer) WatchProducts(req *pb.WatchProductsRequest, stream pb.ProductService_WatchProductsServer) error {
+    token := req.ResumeToken
+    if token == "" {
+        token = s.changes.Head()
+    }
+    ctx := stream.Context()
+    for {
+        changes, appended, err := s.changes.Read(token, watchBatch)
+        if err != nil {
+            return watchError(err)
+        }
+        if len(changes) == 0 {
+            select {
+            case <-appended:
+                continue
+            case <-ctx.Done():
+                return nil
+            }
+        }
+        for _, change := range changes {
+            if err := stream.Send(toProtoProductChange(change)); err != nil {
+                return err
+            }
+            token = change.Token
+        }
+    }
+}
+
+// watchError maps a token the log did not hand out to InvalidArgument and one
+// whose changes it no longer keeps to OutOfRange
+func watchError(err error) error {
+    switch {
+    case errors.Is(err, feed.ErrInvalidToken):
+        return status.Error(codes.InvalidArgument, err.Error())
+    case errors.Is(err, feed.ErrTokenExpired):
+        return status.Error(codes.OutOfRange, err.Error())
+    }
+    return err
+}
+
+func toProtoProductChange(change feed.Change) *pb.ProductChange {
+    event := change.Event
+    c := &pb.ProductChange{
+        Token:      change.Token,
+        Type:       productChangeTypes[event.Type],
+        ProductId:  event.ProductID,
+        OccurredAt: formatTime(event.OccurredAt),
+    }
+    if event.Product != nil {
+        c.Product = toProtoProduct(event.Product)
+    }
+    if event.Stock != nil {
+        c.Stock = toProtoStockMovement(event.Stock)
+    }
+    return c
+}
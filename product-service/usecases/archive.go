@@ -0,0 +1,87 @@
+package usecases
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "time"
+
+    orderpb "github.com/samObot19/shopverse/product-service/clients/order-client/proto/pb"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// purgeBatchSize caps the archived products checked against the orders at a time
+const purgeBatchSize = 100
+
+// RestoreProduct brings an archived product back into the catalogue and search
+func (uc *ProductUseCase) RestoreProduct(ctx context.Context, id string) (*models.Product, error) {
+    if id == "" {
+        return nil, errors.New("product ID cannot be empty")
+    }
+    if err := uc.repo.RestoreProduct(ctx, id); err != nil {
+        return nil, err
+    }
+    product, err := uc.repo.GetProductByID(ctx, id)
+    if err != nil {
+        return nil, fmt.Errorf("product restored but could not be reindexed: %w", err)
+    }
+    uc.index.Add(product)
+    return product, uc.present(ctx, product)
+}
+
+// PurgeArchivedProducts permanently deletes the products archived for longer
+// than retention that no order references, as reported by order-service, and
+// returns how many were purged. Products still referenced stay archived.
+func (uc *ProductUseCase) PurgeArchivedProducts(ctx context.Context, retention time.Duration) (int, error) {
+    before := time.Now().UTC().Add(-retention)
+    purged := 0
+    afterID := ""
+    for {
+        ids, err := uc.repo.ArchivedProducts(ctx, before, afterID, purgeBatchSize)
+        if err != nil {
+            return purged, err
+        }
+        if len(ids) == 0 {
+            return purged, nil
+        }
+        afterID = ids[len(ids)-1]
+
+        resp, err := uc.orders.GetReferencedProducts(ctx, &orderpb.GetReferencedProductsRequest{ProductIds: ids})
+        if err != nil {
+            return purged, fmt.Errorf("failed to check orders for archived products: %w", err)
+        }
+        referenced := make(map[string]bool, len(resp.ProductIds))
+        for _, id := range resp.ProductIds {
+            referenced[id] = true
+        }
+
+        for _, id := range ids {
+            if referenced[id] {
+                continue
+            }
+            images, err := uc.images.ListProductImages(ctx, id)
+            if err != nil {
+                log.Printf("Failed to load images of product %s: %v", id, err)
+                continue
+            }
+            // a product restored in the meantime is left alone
+            ok, err := uc.repo.PurgeProduct(ctx, id)
+            if err != nil {
+                // a transient failure the next pass retries
+                log.Printf("Failed to purge product %s: %v", id, err)
+                continue
+            }
+            if ok {
+                purged++
+                // the rows of its images went with the product, their files go after
+                for _, image := range images {
+                    uc.deleteRenditions(ctx, image)
+                }
+            }
+        }
+        if len(ids) < purgeBatchSize {
+            return purged, nil
+        }
+    }
+}
@@ -0,0 +1,259 @@
+package usecases
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "time"
+
+    "github.com/google/uuid"
+    "github.com/samObot19/shopverse/product-service/catalog"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// defaultImportBatch and maxImportBatch bound the number of rows written in one transaction
+const (
+    defaultImportBatch = 100
+    maxImportBatch     = 1000
+)
+
+// maxSKULength is the longest SKU the products table stores
+const maxSKULength = 64
+
+// ImportOptions controls a bulk import
+type ImportOptions struct {
+    DryRun    bool // Validate and match the rows without writing them
+    BatchSize int  // Rows written per transaction; zero uses the default
+}
+
+// ImportRowError describes a row that was not imported
+type ImportRowError struct {
+    Line    int
+    ID      string
+    SKU     string
+    Message string
+}
+
+// ImportReport counts the outcome of a bulk import. In a dry run Created and
+// Updated count the rows that would have been written.
+type ImportReport struct {
+    Created int
+    Updated int
+    Failed  int
+    Errors  []ImportRowError
+}
+
+func (r *ImportReport) fail(line int, id, sku string, err error) {
+    r.Failed++
+    r.Errors = append(r.Errors, ImportRowError{Line: line, ID: id, SKU: sku, Message: err.Error()})
+}
+
+// importRow is a validated product together with the line it was read from
+type importRow struct {
+    line    int
+    product *models.Product
+}
+
+// ImportProducts creates or replaces the products read from a catalog file.
+// Rows are matched to existing products by ID, or by SKU when they have no
+// ID, and written in batches of one transaction each. Rows that are malformed,
+// fail validation or belong to a failed batch are reported and skipped; any
+// other error stops the import after the batches written so far.
+func (uc *ProductUseCase) ImportProducts(ctx context.Context, r catalog.Reader, opts ImportOptions) (*ImportReport, error) {
+    batchSize := opts.BatchSize
+    if batchSize < 0 {
+        return nil, errors.New("batch size cannot be negative")
+    }
+    if batchSize == 0 {
+        batchSize = defaultImportBatch
+    }
+    if batchSize > maxImportBatch {
+        batchSize = maxImportBatch
+    }
+
+    report := &ImportReport{}
+    batch := make([]importRow, 0, batchSize)
+    for {
+        product, line, err := r.Read()
+        if err == io.EOF {
+            break
+        }
+        var rowErr *catalog.RowError
+        if errors.As(err, &rowErr) {
+            report.fail(rowErr.Line, rowErr.ID, rowErr.SKU, rowErr.Err)
+            continue
+        }
+        if err != nil {
+            return report, fmt.Errorf("failed to read catalog: %w", err)
+        }
+
+        if err := uc.validateProduct(ctx, product); err != nil {
+            report.fail(line, product.ID, product.SKU, err)
+            continue
+        }
+        batch = append(batch, importRow{line: line, product: product})
+        if len(batch) < batchSize {
+            continue
+        }
+        if err := uc.importBatch(ctx, batch, opts.DryRun, report); err != nil {
+            return report, err
+        }
+        batch = batch[:0]
+    }
+
+    if len(batch) > 0 {
+        if err := uc.importBatch(ctx, batch, opts.DryRun, report); err != nil {
+            return report, err
+        }
+    }
+    return report, nil
+}
+
+// ExportProducts writes every active product to a catalog file at its base price and
+// returns the number of products written
+func (uc *ProductUseCase) ExportProducts(ctx context.Context, w catalog.Writer) (int, error) {
+    products, err := uc.repo.GetAllProducts(ctx, nil, nil, nil, false)
+    if err != nil {
+        return 0, fmt.Errorf("failed to load products for export: %w", err)
+    }
+    for i, product := range products {
+        if err := w.Write(product); err != nil {
+            return i, err
+        }
+    }
+    return len(products), w.Flush()
+}
+
+// validateProduct applies the checks of CreateProduct to an imported row or
+// an updated product
+func (uc *ProductUseCase) validateProduct(ctx context.Context, product *models.Product) error {
+    switch {
+    case product.Title == "":
+        return errors.New("title cannot be empty")
+    case product.Stock < 0:
+        return errors.New("stock cannot be negative")
+    case len(product.SKU) > maxSKULength:
+        return fmt.Errorf("SKU cannot be longer than %d characters", maxSKULength)
+    }
+    if err := validatePrice(product.Price, nil); err != nil {
+        return err
+    }
+    translations, err := normalizeTranslations(product.Translations)
+    if err != nil {
+        return err
+    }
+    product.Translations = translations
+    return uc.fileProduct(ctx, product)
+}
+
+// importBatch matches a batch of rows to the existing products and, unless
+// this is a dry run, writes it in one transaction
+func (uc *ProductUseCase) importBatch(ctx context.Context, batch []importRow, dryRun bool, report *ImportReport) error {
+    var ids, skus []string
+    for _, row := range batch {
+        if row.product.ID != "" {
+            ids = append(ids, row.product.ID)
+        }
+        if row.product.SKU != "" {
+            skus = append(skus, row.product.SKU)
+        }
+    }
+    byID, err := uc.repo.GetProductsByIDs(ctx, ids)
+    if err != nil {
+        return fmt.Errorf("failed to match products by ID: %w", err)
+    }
+    bySKU, err := uc.repo.GetProductsBySKUs(ctx, skus)
+    if err != nil {
+        return fmt.Errorf("failed to match products by SKU: %w", err)
+    }
+    existingByID := make(map[string]*models.Product, len(byID))
+    for _, product := range byID {
+        existingByID[product.ID] = product
+    }
+    existingBySKU := make(map[string]*models.Product, len(bySKU))
+    for _, product := range bySKU {
+        existingBySKU[product.SKU] = product
+    }
+
+    now := time.Now().UTC()
+    var created, replaced []*models.Product
+    var written []importRow
+    var priceChanges []*models.PriceChange
+    seenIDs := make(map[string]bool, len(batch))
+    seenSKUs := make(map[string]bool, len(batch))
+    for _, row := range batch {
+        product := row.product
+        existing := existingByID[product.ID]
+        if owner := existingBySKU[product.SKU]; owner != nil {
+            switch {
+            case product.ID == "":
+                existing = owner
+            case owner.ID != product.ID:
+                report.fail(row.line, product.ID, product.SKU, fmt.Errorf("SKU %s belongs to product %s", product.SKU, owner.ID))
+                continue
+            }
+        }
+
+        if existing != nil {
+            product.ID = existing.ID
+        } else if product.ID == "" {
+            product.ID = uuid.NewString()
+        }
+        if seenIDs[product.ID] || (product.SKU != "" && seenSKUs[product.SKU]) {
+            report.fail(row.line, product.ID, product.SKU, errors.New("product appears more than once in the batch"))
+            continue
+        }
+        seenIDs[product.ID] = true
+        if product.SKU != "" {
+            seenSKUs[product.SKU] = true
+        }
+
+        if existing != nil && product.Price.Currency != existing.Price.Currency {
+            report.fail(row.line, product.ID, product.SKU, errCurrencyChange(existing))
+            continue
+        }
+        if existing != nil {
+            // the rating is earned through reviews and kept across imports
+            product.CreatedAt = existing.CreatedAt
+            product.Ratings = existing.Ratings
+            product.ReviewCount = existing.ReviewCount
+            // as are the translations, unless the row brings its own
+            if product.Translations == nil {
+                product.Translations = existing.Translations
+            }
+            // the import replaces the version it matched against
+            product.Version = existing.Version
+            replaced = append(replaced, product)
+        } else {
+            product.CreatedAt = now
+            product.Version = 1
+            created = append(created, product)
+        }
+        if existing == nil || existing.Price != product.Price {
+            price := product.Price
+            priceChanges = append(priceChanges, &models.PriceChange{ProductID: product.ID, Price: &price, StartsAt: now, CreatedAt: now})
+        }
+        written = append(written, row)
+    }
+
+    if !dryRun {
+        if err := uc.repo.ImportProducts(ctx, created, replaced); err != nil {
+            for _, row := range written {
+                report.fail(row.line, row.product.ID, row.product.SKU, fmt.Errorf("batch failed: %w", err))
+            }
+            return nil
+        }
+        for _, row := range written {
+            uc.index.Add(row.product)
+        }
+        if len(priceChanges) > 0 {
+            if err := uc.prices.RecordPriceChanges(ctx, priceChanges); err != nil {
+                return fmt.Errorf("products imported but their price history could not be recorded: %w", err)
+            }
+        }
+    }
+    report.Created += len(created)
+    report.Updated += len(replaced)
+    return nil
+}
@@ -0,0 +1,242 @@
+package usecases
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+    "unicode"
+
+    "github.com/google/uuid"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/query"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+// CreateCategory adds a category under an optional parent and returns its ID;
+// the slug is derived from the name when none is given
+func (uc *ProductUseCase) CreateCategory(ctx context.Context, category *models.Category) (string, error) {
+    if err := validateCategory(category); err != nil {
+        return "", err
+    }
+    if category.ParentID != "" {
+        if _, err := uc.categories.GetCategory(ctx, category.ParentID); err != nil {
+            return "", fmt.Errorf("failed to load parent category %s: %w", category.ParentID, err)
+        }
+    }
+
+    if category.ID == "" {
+        category.ID = uuid.NewString()
+    }
+    if category.CreatedAt.IsZero() {
+        category.CreatedAt = time.Now().UTC()
+    }
+    if err := uc.categories.CreateCategory(ctx, category); err != nil {
+        return "", err
+    }
+    return category.ID, nil
+}
+
+// GetCategory retrieves a category and its breadcrumbs, the path from the
+// top-level category down to and including the category itself
+func (uc *ProductUseCase) GetCategory(ctx context.Context, id string) (*models.Category, []*models.Category, error) {
+    if id == "" {
+        return nil, nil, errors.New("category ID cannot be empty")
+    }
+    categories, err := uc.categories.ListCategories(ctx)
+    if err != nil {
+        return nil, nil, err
+    }
+    byID := make(map[string]*models.Category, len(categories))
+    for _, category := range categories {
+        byID[category.ID] = category
+    }
+
+    category, ok := byID[id]
+    if !ok {
+        return nil, nil, fmt.Errorf("%w: %s", repository.ErrCategoryNotFound, id)
+    }
+    return category, breadcrumbs(byID, category), nil
+}
+
+// UpdateCategory renames, moves or reorders a category; its products follow
+// the new name. A category cannot be moved below itself.
+func (uc *ProductUseCase) UpdateCategory(ctx context.Context, id string, category *models.Category) error {
+    if id == "" {
+        return errors.New("category ID cannot be empty")
+    }
+    if err := validateCategory(category); err != nil {
+        return err
+    }
+
+    if category.ParentID != "" {
+        categories, err := uc.categories.ListCategories(ctx)
+        if err != nil {
+            return err
+        }
+        byID := make(map[string]*models.Category, len(categories))
+        for _, c := range categories {
+            byID[c.ID] = c
+        }
+        parent, ok := byID[category.ParentID]
+        if !ok {
+            return fmt.Errorf("%w: parent %s", repository.ErrCategoryNotFound, category.ParentID)
+        }
+        for _, ancestor := range breadcrumbs(byID, parent) {
+            if ancestor.ID == id {
+                return errors.New("a category cannot be moved below itself")
+            }
+        }
+    }
+
+    if err := uc.categories.UpdateCategory(ctx, id, category); err != nil {
+        return err
+    }
+    // the search index holds category names, which may have changed
+    return uc.RebuildSearchIndex(ctx)
+}
+
+// DeleteCategory removes a category that has no subcategories or products
+func (uc *ProductUseCase) DeleteCategory(ctx context.Context, id string) error {
+    if id == "" {
+        return errors.New("category ID cannot be empty")
+    }
+    return uc.categories.DeleteCategory(ctx, id)
+}
+
+// GetCategoryTree returns the subtree below rootID, or the whole tree when
+// rootID is empty, with siblings in position order
+func (uc *ProductUseCase) GetCategoryTree(ctx context.Context, rootID string) ([]*models.CategoryNode, error) {
+    categories, err := uc.categories.ListCategories(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    nodes := make(map[string]*models.CategoryNode, len(categories))
+    for _, category := range categories {
+        nodes[category.ID] = &models.CategoryNode{Category: category}
+    }
+    var roots []*models.CategoryNode
+    for _, category := range categories {
+        parent, ok := nodes[category.ParentID]
+        if !ok {
+            roots = append(roots, nodes[category.ID])
+            continue
+        }
+        parent.Children = append(parent.Children, nodes[category.ID])
+    }
+
+    if rootID == "" {
+        return roots, nil
+    }
+    root, ok := nodes[rootID]
+    if !ok {
+        return nil, fmt.Errorf("%w: %s", repository.ErrCategoryNotFound, rootID)
+    }
+    return []*models.CategoryNode{root}, nil
+}
+
+// GetProductsInCategory retrieves the products filed under a category and,
+// when includeDescendants is set, under any of its subcategories
+func (uc *ProductUseCase) GetProductsInCategory(ctx context.Context, categoryID string, includeDescendants bool, sort []query.Sort) ([]*models.Product, error) {
+    if categoryID == "" {
+        return nil, errors.New("category ID cannot be empty")
+    }
+    if err := query.ValidateSort(sort); err != nil {
+        return nil, err
+    }
+    prices, err := uc.listingPrices(ctx, nil, sort)
+    if err != nil {
+        return nil, err
+    }
+    if !includeDescendants {
+        if _, err := uc.categories.GetCategory(ctx, categoryID); err != nil {
+            return nil, err
+        }
+        products, err := uc.repo.GetProductsByCategoryIDs(ctx, []string{categoryID}, sort, prices)
+        if err != nil {
+            return nil, err
+        }
+        return products, uc.present(ctx, products...)
+    }
+
+    tree, err := uc.GetCategoryTree(ctx, categoryID)
+    if err != nil {
+        return nil, err
+    }
+    var ids []string
+    var collect func(nodes []*models.CategoryNode)
+    collect = func(nodes []*models.CategoryNode) {
+        for _, node := range nodes {
+            ids = append(ids, node.Category.ID)
+            collect(node.Children)
+        }
+    }
+    collect(tree)
+    products, err := uc.repo.GetProductsByCategoryIDs(ctx, ids, sort, prices)
+    if err != nil {
+        return nil, err
+    }
+    return products, uc.present(ctx, products...)
+}
+
+// fileProduct copies the name of the product's category to the product, so
+// listings and search can show it without a lookup
+func (uc *ProductUseCase) fileProduct(ctx context.Context, product *models.Product) error {
+    if product.CategoryID == "" {
+        return nil
+    }
+    category, err := uc.categories.GetCategory(ctx, product.CategoryID)
+    if err != nil {
+        return fmt.Errorf("failed to load category %s: %w", product.CategoryID, err)
+    }
+    product.Category = category.Name
+    return nil
+}
+
+// breadcrumbs walks from a category up to its top-level ancestor and returns
+// the path top-down; it stops at a missing parent or a cycle
+func breadcrumbs(byID map[string]*models.Category, category *models.Category) []*models.Category {
+    var path []*models.Category
+    seen := make(map[string]bool)
+    for c := category; c != nil && !seen[c.ID]; c = byID[c.ParentID] {
+        seen[c.ID] = true
+        path = append([]*models.Category{c}, path...)
+    }
+    return path
+}
+
+// validateCategory requires a name and fills in a missing slug
+func validateCategory(category *models.Category) error {
+    category.Name = strings.TrimSpace(category.Name)
+    if category.Name == "" {
+        return errors.New("category name cannot be empty")
+    }
+    if category.Slug == "" {
+        category.Slug = slugify(category.Name)
+    }
+    if category.Slug == "" || category.Slug != slugify(category.Slug) {
+        return fmt.Errorf("invalid category slug %q", category.Slug)
+    }
+    return nil
+}
+
+// slugify lowercases a name and joins its letters and digits with hyphens,
+// so "Men's Shirts" becomes "men-s-shirts"
+func slugify(name string) string {
+    var b strings.Builder
+    hyphen := false
+    for _, r := range strings.ToLower(name) {
+        if unicode.IsLetter(r) || unicode.IsDigit(r) {
+            if hyphen && b.Len() > 0 {
+                b.WriteByte('-')
+            }
+            b.WriteRune(r)
+            hyphen = false
+            continue
+        }
+        hyphen = true
+    }
+    return b.String()
+}
@@ -0,0 +1,110 @@
+package usecases
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+    "github.com/samObot19/shopverse/product-service/pricing"
+    "github.com/samObot19/shopverse/product-service/query"
+)
+
+type currencyKey struct{}
+
+// WithCurrency asks for the prices of products read with ctx to be converted
+// into currency; an empty currency leaves them in the currency they are set in
+func WithCurrency(ctx context.Context, currency string) context.Context {
+    return context.WithValue(ctx, currencyKey{}, strings.ToUpper(strings.TrimSpace(currency)))
+}
+
+// currencyFrom is the currency prices are read in, if one was asked for
+func currencyFrom(ctx context.Context) string {
+    currency, _ := ctx.Value(currencyKey{}).(string)
+    return currency
+}
+
+// ListExchangeRates retrieves the exchange rate of every currency other than
+// models.DefaultCurrency
+func (uc *ProductUseCase) ListExchangeRates(ctx context.Context) ([]*models.ExchangeRate, error) {
+    return uc.rates.ListExchangeRates(ctx)
+}
+
+// SetExchangeRate adds or replaces the rate of a currency against
+// models.DefaultCurrency and how prices converted into it are rounded,
+// to the nearest minor unit unless said otherwise
+func (uc *ProductUseCase) SetExchangeRate(ctx context.Context, rate *models.ExchangeRate) error {
+    rate.Currency = strings.ToUpper(strings.TrimSpace(rate.Currency))
+    if !money.ValidCurrency(rate.Currency) {
+        return fmt.Errorf("%w %q", money.ErrInvalidCurrency, rate.Currency)
+    }
+    if rate.Currency == models.DefaultCurrency {
+        return fmt.Errorf("the rate of %s is always 1", models.DefaultCurrency)
+    }
+    parsed, err := money.ParseRate(rate.Rate)
+    if err != nil {
+        return err
+    }
+    rate.Rate = money.FormatRate(parsed)
+    if rate.Rounding == (money.Rounding{}) {
+        rate.Rounding = money.DefaultRounding
+    }
+    if err := rate.Rounding.Validate(); err != nil {
+        return err
+    }
+    rate.UpdatedAt = time.Now().UTC()
+    return uc.rates.SetExchangeRate(ctx, rate)
+}
+
+// DeleteExchangeRate stops prices being converted into a currency
+func (uc *ProductUseCase) DeleteExchangeRate(ctx context.Context, currency string) error {
+    return uc.rates.DeleteExchangeRate(ctx, strings.ToUpper(strings.TrimSpace(currency)))
+}
+
+// exchangeRates loads the rates to convert prices into the currency asked for
+// with ctx, or returns nil when none was asked for
+func (uc *ProductUseCase) exchangeRates(ctx context.Context) (*pricing.Rates, string, error) {
+    currency := currencyFrom(ctx)
+    if currency == "" {
+        return nil, "", nil
+    }
+    r, err := uc.loadRates(ctx)
+    if err != nil {
+        return nil, "", err
+    }
+    if !r.Supports(currency) {
+        return nil, "", fmt.Errorf("%w: %s", pricing.ErrUnsupportedCurrency, currency)
+    }
+    return r, currency, nil
+}
+
+// listingPrices gives what prices are worth in the currency asked for with
+// ctx, or else in models.DefaultCurrency, when filter or sorts compare prices
+func (uc *ProductUseCase) listingPrices(ctx context.Context, filter *query.Filter, sorts []query.Sort) (*query.Prices, error) {
+    if !query.UsesPrices(filter, sorts) {
+        return nil, nil
+    }
+    currency := currencyFrom(ctx)
+    if currency == "" {
+        currency = models.DefaultCurrency
+    }
+    r, err := uc.loadRates(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if !r.Supports(currency) {
+        return nil, fmt.Errorf("%w: %s", pricing.ErrUnsupportedCurrency, currency)
+    }
+    return &query.Prices{Currency: currency, Worth: r.Worth(currency)}, nil
+}
+
+// loadRates loads every exchange rate
+func (uc *ProductUseCase) loadRates(ctx context.Context) (*pricing.Rates, error) {
+    rates, err := uc.rates.ListExchangeRates(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to load exchange rates: %w", err)
+    }
+    return pricing.NewRates(rates)
+}
@@ -0,0 +1,297 @@
+package usecases
+
+import (
+    "context"
+    "errors"
+    "io"
+    "time"
+
+    "google.golang.org/grpc"
+
+    orderpb "github.com/samObot19/shopverse/product-service/clients/order-client/proto/pb"
+    "github.com/samObot19/shopverse/product-service/fulfillment"
+    "github.com/samObot19/shopverse/product-service/media"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+    "github.com/samObot19/shopverse/product-service/search"
+)
+
+// The fakes below keep their data in memory and implement only the methods
+// the tests reach; the embedded interfaces are nil, so any other call panics.
+
+// newTestUseCase creates a ProductUseCase over deps, with a fresh search index,
+// a 15 minute reservation TTL and the nearest warehouse strategy unless deps
+// says otherwise
+func newTestUseCase(deps Deps) *ProductUseCase {
+    if deps.Index == nil {
+        deps.Index = search.NewIndex()
+    }
+    if deps.ReservationTTL == 0 {
+        deps.ReservationTTL = 15 * time.Minute
+    }
+    if deps.Fulfillment == "" {
+        deps.Fulfillment = fulfillment.Nearest
+    }
+    return NewProductUseCase(deps)
+}
+
+// fakeReservations records the reservations made through it. A reservation
+// with the reference ID of raced is refused as a duplicate after raced is
+// recorded, as if a concurrent request had won; the errors of reserveErrs are
+// returned by the first calls to ReserveStock.
+type fakeReservations struct {
+    repository.ReservationRepository
+    byReference map[string]*models.Reservation
+    reserved    []*models.Reservation
+    reserveErrs []error
+    raced       *models.Reservation
+
+    // pending lists the IDs ExpiredReservations reports; failing ones cannot be released
+    pending []string
+    failing map[string]bool
+}
+
+func (f *fakeReservations) ReserveStock(ctx context.Context, reservation *models.Reservation) error {
+    if len(f.reserveErrs) > 0 {
+        err := f.reserveErrs[0]
+        f.reserveErrs = f.reserveErrs[1:]
+        return err
+    }
+    if f.raced != nil && f.raced.ReferenceID == reservation.ReferenceID {
+        f.byReference[f.raced.ReferenceID] = f.raced
+        return repository.ErrDuplicateReservation
+    }
+    f.reserved = append(f.reserved, reservation)
+    if reservation.ReferenceID != "" {
+        f.byReference[reservation.ReferenceID] = reservation
+    }
+    return nil
+}
+
+func (f *fakeReservations) GetReservationByReference(ctx context.Context, referenceID string) (*models.Reservation, error) {
+    if reservation, ok := f.byReference[referenceID]; ok {
+        return reservation, nil
+    }
+    return nil, repository.ErrReservationNotFound
+}
+
+func (f *fakeReservations) CommitReservation(ctx context.Context, id string, now time.Time) (*models.Reservation, error) {
+    for _, reservation := range f.reserved {
+        if reservation.ID == id {
+            reservation.Status = models.ReservationCommitted
+            return reservation, nil
+        }
+    }
+    return nil, repository.ErrReservationNotFound
+}
+
+func (f *fakeReservations) ExpiredReservations(ctx context.Context, now time.Time, limit int) ([]string, error) {
+    return f.pending[:min(limit, len(f.pending))], nil
+}
+
+func (f *fakeReservations) ReleaseReservation(ctx context.Context, id string, status models.ReservationStatus) (*models.Reservation, error) {
+    if f.failing[id] {
+        return nil, repository.ErrReservationClosed
+    }
+    for i, pending := range f.pending {
+        if pending == id {
+            f.pending = append(f.pending[:i:i], f.pending[i+1:]...)
+            return &models.Reservation{ID: id, Status: status}, nil
+        }
+    }
+    return nil, repository.ErrReservationNotFound
+}
+
+// fakeWarehouses serves fixed warehouses and stock levels
+type fakeWarehouses struct {
+    repository.WarehouseRepository
+    warehouses []*models.Warehouse
+    levels     []models.StockLevel
+}
+
+func (f *fakeWarehouses) ListWarehouses(ctx context.Context) ([]*models.Warehouse, error) {
+    return f.warehouses, nil
+}
+
+func (f *fakeWarehouses) StockLevels(ctx context.Context, productIDs []string) ([]models.StockLevel, error) {
+    wanted := make(map[string]bool, len(productIDs))
+    for _, id := range productIDs {
+        wanted[id] = true
+    }
+    var levels []models.StockLevel
+    for _, level := range f.levels {
+        if wanted[level.ProductID] {
+            levels = append(levels, level)
+        }
+    }
+    return levels, nil
+}
+
+// fakeLedger records the movements adjusted through it and pages through
+// history, which it holds newest first
+type fakeLedger struct {
+    repository.StockLedgerRepository
+    adjusted []*models.StockMovement
+    history  []*models.StockMovement
+}
+
+func (f *fakeLedger) AdjustStock(ctx context.Context, movement *models.StockMovement) error {
+    f.adjusted = append(f.adjusted, movement)
+    return nil
+}
+
+func (f *fakeLedger) GetStockHistory(ctx context.Context, productID, variantID, before string, limit int) ([]*models.StockMovement, error) {
+    var page []*models.StockMovement
+    started := before == ""
+    for _, movement := range f.history {
+        if !started {
+            started = movement.ID == before
+            continue
+        }
+        if len(page) < limit {
+            page = append(page, movement)
+        }
+    }
+    return page, nil
+}
+
+// fakeProducts serves fixed products
+type fakeProducts struct {
+    repository.ProductRepository
+    products map[string]*models.Product
+}
+
+func (f *fakeProducts) GetProductByID(ctx context.Context, id string) (*models.Product, error) {
+    if product, ok := f.products[id]; ok {
+        copied := *product
+        return &copied, nil
+    }
+    return nil, repository.ErrProductNotFound
+}
+
+// fakeReviews keeps reviews by ID and the helpful votes cast for them
+type fakeReviews struct {
+    repository.ReviewRepository
+    reviews map[string]*models.Review
+    votes   map[string][]string
+}
+
+func (f *fakeReviews) CreateReview(ctx context.Context, review *models.Review) error {
+    f.reviews[review.ID] = review
+    return nil
+}
+
+func (f *fakeReviews) GetReview(ctx context.Context, id string) (*models.Review, error) {
+    if review, ok := f.reviews[id]; ok {
+        copied := *review
+        return &copied, nil
+    }
+    return nil, repository.ErrReviewNotFound
+}
+
+func (f *fakeReviews) UpdateReview(ctx context.Context, id string, review *models.Review) error {
+    f.reviews[id] = review
+    return nil
+}
+
+func (f *fakeReviews) DeleteReview(ctx context.Context, id string) error {
+    delete(f.reviews, id)
+    return nil
+}
+
+func (f *fakeReviews) AddHelpfulVote(ctx context.Context, reviewID, userID string, at time.Time) error {
+    f.votes[reviewID] = append(f.votes[reviewID], userID)
+    return nil
+}
+
+// fakeOrders serves fixed orders of each user from order-service
+type fakeOrders struct {
+    orderpb.OrderServiceClient
+    orders map[string][]*orderpb.Order
+    err    error
+}
+
+func (f *fakeOrders) GetAllOrders(ctx context.Context, in *orderpb.GetAllOrdersRequest, opts ...grpc.CallOption) (*orderpb.GetAllOrdersResponse, error) {
+    if f.err != nil {
+        return nil, f.err
+    }
+    return &orderpb.GetAllOrdersResponse{Orders: f.orders[in.UserId]}, nil
+}
+
+// fakeStockChecks keeps the first outcome recorded for each order
+type fakeStockChecks struct {
+    repository.StockCheckRepository
+    checks map[string]*models.StockCheck
+}
+
+func (f *fakeStockChecks) GetStockCheck(ctx context.Context, orderID string) (*models.StockCheck, error) {
+    if check, ok := f.checks[orderID]; ok {
+        return check, nil
+    }
+    return nil, repository.ErrStockCheckNotFound
+}
+
+func (f *fakeStockChecks) RecordStockCheck(ctx context.Context, check *models.StockCheck) error {
+    if _, ok := f.checks[check.OrderID]; !ok {
+        f.checks[check.OrderID] = check
+    }
+    return nil
+}
+
+// fakeImages keeps images by ID and places each after the others of its
+// product; createErr fails CreateProductImage
+type fakeImages struct {
+    repository.ProductImageRepository
+    images    map[string]*models.ProductImage
+    createErr error
+}
+
+func (f *fakeImages) CreateProductImage(ctx context.Context, image *models.ProductImage) error {
+    if f.createErr != nil {
+        return f.createErr
+    }
+    image.Position = 0
+    for _, other := range f.images {
+        if other.ProductID == image.ProductID {
+            image.Position++
+        }
+    }
+    f.images[image.ID] = image
+    return nil
+}
+
+// errDiskFull is returned by the call to fakeBlobs.Put set to fail
+var errDiskFull = errors.New("disk full")
+
+// fakeBlobs keeps files in memory; the failPut-th call to Put fails when
+// failPut is set
+type fakeBlobs struct {
+    media.BlobStore
+    files   map[string][]byte
+    puts    int
+    failPut int
+    deleted []string
+}
+
+func (f *fakeBlobs) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+    f.puts++
+    if f.puts == f.failPut {
+        return errDiskFull
+    }
+    data, err := io.ReadAll(r)
+    if err != nil {
+        return err
+    }
+    f.files[key] = data
+    return nil
+}
+
+func (f *fakeBlobs) Delete(ctx context.Context, key string) error {
+    delete(f.files, key)
+    f.deleted = append(f.deleted, key)
+    return nil
+}
+
+func (f *fakeBlobs) URL(key string) string {
+    return "https://cdn.example.com/" + key
+}
@@ -0,0 +1,168 @@
+package usecases
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "path"
+    "strings"
+    "time"
+    "unicode/utf8"
+
+    "github.com/google/uuid"
+    "github.com/samObot19/shopverse/product-service/media"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// maxAltTextLength caps the alt text of an image, in characters
+const maxAltTextLength = 512
+
+// UploadProductImage makes renditions of an image uploaded for a product in
+// every size and format, stores their files and records the image after the
+// other images of the product. Nothing of the upload but its pixels is kept.
+func (uc *ProductUseCase) UploadProductImage(ctx context.Context, productID, altText string, data []byte) (*models.ProductImage, error) {
+    if productID == "" {
+        return nil, errors.New("product ID cannot be empty")
+    }
+    altText, err := validateAltText(altText)
+    if err != nil {
+        return nil, err
+    }
+    if _, err := uc.repo.GetProductByID(ctx, productID); err != nil {
+        return nil, err
+    }
+    processed, err := media.Process(data, media.DefaultSizes, media.DefaultFormats)
+    if err != nil {
+        return nil, err
+    }
+
+    image := &models.ProductImage{
+        ID:        uuid.NewString(),
+        ProductID: productID,
+        AltText:   altText,
+        Width:     processed.Width,
+        Height:    processed.Height,
+        CreatedAt: time.Now().UTC(),
+    }
+    for _, r := range processed.Renditions {
+        key := path.Join("images", image.ID, r.Size+"."+r.Format.Extension())
+        if err := uc.blobs.Put(ctx, key, bytes.NewReader(r.Data), r.Format.ContentType()); err != nil {
+            uc.deleteRenditions(ctx, image)
+            return nil, fmt.Errorf("failed to store %s: %w", key, err)
+        }
+        image.Renditions = append(image.Renditions, models.ImageRendition{
+            Size:   r.Size,
+            Format: string(r.Format),
+            Width:  r.Width,
+            Height: r.Height,
+            Bytes:  int64(len(r.Data)),
+            Key:    key,
+        })
+    }
+    if err := uc.images.CreateProductImage(ctx, image); err != nil {
+        uc.deleteRenditions(ctx, image)
+        return nil, err
+    }
+    uc.locateRenditions(image)
+    return image, nil
+}
+
+// GetProductImage retrieves a product image by its ID
+func (uc *ProductUseCase) GetProductImage(ctx context.Context, id string) (*models.ProductImage, error) {
+    if id == "" {
+        return nil, errors.New("image ID cannot be empty")
+    }
+    image, err := uc.images.GetProductImage(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    uc.locateRenditions(image)
+    return image, nil
+}
+
+// ListProductImages retrieves the images of a product in the order they are shown in
+func (uc *ProductUseCase) ListProductImages(ctx context.Context, productID string) ([]*models.ProductImage, error) {
+    if productID == "" {
+        return nil, errors.New("product ID cannot be empty")
+    }
+    images, err := uc.images.ListProductImages(ctx, productID)
+    if err != nil {
+        return nil, err
+    }
+    uc.locateRenditions(images...)
+    return images, nil
+}
+
+// UpdateProductImage changes the alt text of an image or moves it to another
+// position; a nil argument leaves that field as it is
+func (uc *ProductUseCase) UpdateProductImage(ctx context.Context, id string, altText *string, position *int) (*models.ProductImage, error) {
+    if id == "" {
+        return nil, errors.New("image ID cannot be empty")
+    }
+    image, err := uc.images.GetProductImage(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    if altText != nil {
+        if image.AltText, err = validateAltText(*altText); err != nil {
+            return nil, err
+        }
+    }
+    if position != nil {
+        if *position < 0 {
+            return nil, errors.New("image position cannot be negative")
+        }
+        image.Position = *position
+    }
+    if err := uc.images.UpdateProductImage(ctx, id, image.AltText, image.Position); err != nil {
+        return nil, err
+    }
+    uc.locateRenditions(image)
+    return image, nil
+}
+
+// DeleteProductImage removes an image and the files of its renditions
+func (uc *ProductUseCase) DeleteProductImage(ctx context.Context, id string) error {
+    if id == "" {
+        return errors.New("image ID cannot be empty")
+    }
+    image, err := uc.images.GetProductImage(ctx, id)
+    if err != nil {
+        return err
+    }
+    if err := uc.images.DeleteProductImage(ctx, id); err != nil {
+        return err
+    }
+    uc.deleteRenditions(ctx, image)
+    return nil
+}
+
+// locateRenditions sets where shoppers fetch the renditions of images from
+func (uc *ProductUseCase) locateRenditions(images ...*models.ProductImage) {
+    for _, image := range images {
+        for i := range image.Renditions {
+            image.Renditions[i].URL = uc.blobs.URL(image.Renditions[i].Key)
+        }
+    }
+}
+
+// deleteRenditions removes the files of an image's renditions once nothing
+// refers to them; a file that cannot be removed is only wasted space
+func (uc *ProductUseCase) deleteRenditions(ctx context.Context, image *models.ProductImage) {
+    for _, rendition := range image.Renditions {
+        if err := uc.blobs.Delete(ctx, rendition.Key); err != nil {
+            log.Printf("Failed to delete %s of image %s: %v", rendition.Key, image.ID, err)
+        }
+    }
+}
+
+// validateAltText trims the alt text of an image and caps its length
+func validateAltText(altText string) (string, error) {
+    altText = strings.TrimSpace(altText)
+    if utf8.RuneCountInString(altText) > maxAltTextLength {
+        return "", fmt.Errorf("alt text cannot be longer than %d characters", maxAltTextLength)
+    }
+    return altText, nil
+}
@@ -0,0 +1,122 @@
+package usecases
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "image"
+    "image/png"
+    "testing"
+
+    "github.com/samObot19/shopverse/product-service/media"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+// pngUpload is a small PNG image as it would be uploaded
+func pngUpload(t *testing.T) []byte {
+    t.Helper()
+    var buf bytes.Buffer
+    if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 20))); err != nil {
+        t.Fatal(err)
+    }
+    return buf.Bytes()
+}
+
+func TestUploadProductImage(t *testing.T) {
+    renditions := len(media.DefaultSizes) * len(media.DefaultFormats)
+
+    tests := []struct {
+        name      string
+        productID string
+        data      func(t *testing.T) []byte
+        failPut   int
+        createErr error
+        wantErr   error
+        wantPuts  int
+    }{
+        {
+            name:      "renditions are stored and the image recorded",
+            productID: "p-1",
+            data:      pngUpload,
+            wantPuts:  renditions,
+        },
+        {
+            name:      "files already stored are deleted when one cannot be",
+            productID: "p-1",
+            data:      pngUpload,
+            failPut:   3,
+            wantErr:   errDiskFull,
+            wantPuts:  3,
+        },
+        {
+            name:      "files are deleted when the image cannot be recorded",
+            productID: "p-1",
+            data:      pngUpload,
+            createErr: repository.ErrProductNotFound,
+            wantErr:   repository.ErrProductNotFound,
+            wantPuts:  renditions,
+        },
+        {
+            name:      "upload that is not an image",
+            productID: "p-1",
+            data:      func(t *testing.T) []byte { return []byte("not an image") },
+            wantErr:   media.ErrUnsupportedImage,
+        },
+        {
+            name:      "missing product",
+            productID: "p-2",
+            data:      pngUpload,
+            wantErr:   repository.ErrProductNotFound,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            images := &fakeImages{
+                images: map[string]*models.ProductImage{
+                    "i-0": {ID: "i-0", ProductID: "p-1"},
+                },
+                createErr: tt.createErr,
+            }
+            blobs := &fakeBlobs{files: map[string][]byte{}, failPut: tt.failPut}
+            uc := newTestUseCase(Deps{
+                Repo:   &fakeProducts{products: map[string]*models.Product{"p-1": {ID: "p-1", Title: "Shirt"}}},
+                Images: images,
+                Blobs:  blobs,
+            })
+
+            got, err := uc.UploadProductImage(context.Background(), tt.productID, " Front ", tt.data(t))
+            if blobs.puts != tt.wantPuts {
+                t.Errorf("UploadProductImage() stored %d files, want %d", blobs.puts, tt.wantPuts)
+            }
+            if !errors.Is(err, tt.wantErr) {
+                t.Fatalf("UploadProductImage() error = %v, want %v", err, tt.wantErr)
+            }
+            if tt.wantErr != nil {
+                if len(blobs.files) != 0 {
+                    t.Errorf("UploadProductImage() left %d files behind", len(blobs.files))
+                }
+                if len(images.images) != 1 {
+                    t.Errorf("UploadProductImage() recorded an image that failed")
+                }
+                return
+            }
+
+            if len(blobs.files) != renditions || len(blobs.deleted) != 0 {
+                t.Errorf("UploadProductImage() kept %d files and deleted %d, want %d kept", len(blobs.files), len(blobs.deleted), renditions)
+            }
+            if images.images[got.ID] != got {
+                t.Fatalf("UploadProductImage() did not record image %s", got.ID)
+            }
+            if got.AltText != "Front" || got.Width != 40 || got.Height != 20 || got.Position != 1 {
+                t.Errorf("UploadProductImage() = %q %dx%d at %d, want \"Front\" 40x20 at 1", got.AltText, got.Width, got.Height, got.Position)
+            }
+            for _, r := range got.Renditions {
+                if _, ok := blobs.files[r.Key]; !ok || r.URL != blobs.URL(r.Key) {
+                    t.Errorf("rendition %s stored %v at %q", r.Key, ok, r.URL)
+                }
+            }
+        })
+    }
+}
@@ -0,0 +1,262 @@
+package usecases
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/google/uuid"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+    "github.com/samObot19/shopverse/product-service/pricing"
+)
+
+const (
+    // DefaultPriceHistoryPageSize is used when a history request sets no page size
+    DefaultPriceHistoryPageSize = 50
+    // MaxPriceHistoryPageSize caps the page size of a history request
+    MaxPriceHistoryPageSize = 200
+)
+
+// CreatePriceList schedules the prices of a price list and returns its ID
+func (uc *ProductUseCase) CreatePriceList(ctx context.Context, list *models.PriceList) (string, error) {
+    if err := uc.validatePriceList(ctx, list); err != nil {
+        return "", err
+    }
+    list.ID = uuid.NewString()
+    list.CreatedAt = time.Now().UTC()
+    if err := uc.prices.CreatePriceList(ctx, list); err != nil {
+        return "", err
+    }
+    if err := uc.recordPriceList(ctx, list); err != nil {
+        return "", err
+    }
+    return list.ID, nil
+}
+
+// GetPriceList retrieves a price list by its ID
+func (uc *ProductUseCase) GetPriceList(ctx context.Context, id string) (*models.PriceList, error) {
+    if id == "" {
+        return nil, errors.New("price list ID cannot be empty")
+    }
+    return uc.prices.GetPriceList(ctx, id)
+}
+
+// ListPriceLists retrieves every price list, latest start first
+func (uc *ProductUseCase) ListPriceLists(ctx context.Context) ([]*models.PriceList, error) {
+    return uc.prices.ListPriceLists(ctx)
+}
+
+// UpdatePriceList replaces the name, period and entries of a price list
+func (uc *ProductUseCase) UpdatePriceList(ctx context.Context, id string, list *models.PriceList) error {
+    if id == "" {
+        return errors.New("price list ID cannot be empty")
+    }
+    if err := uc.validatePriceList(ctx, list); err != nil {
+        return err
+    }
+    if err := uc.prices.UpdatePriceList(ctx, id, list); err != nil {
+        return err
+    }
+    list.ID = id
+    return uc.recordPriceList(ctx, list)
+}
+
+// DeletePriceList deletes a price list; the prices it scheduled stay in the
+// price history
+func (uc *ProductUseCase) DeletePriceList(ctx context.Context, id string) error {
+    if id == "" {
+        return errors.New("price list ID cannot be empty")
+    }
+    return uc.prices.DeletePriceList(ctx, id)
+}
+
+// GetPriceHistory returns a page of the price changes of a product, newest
+// first, and the token of the next page, which is empty on the last page
+func (uc *ProductUseCase) GetPriceHistory(ctx context.Context, productID, pageToken string, pageSize int) ([]*models.PriceChange, string, error) {
+    if productID == "" {
+        return nil, "", errors.New("product ID cannot be empty")
+    }
+    if pageSize <= 0 {
+        pageSize = DefaultPriceHistoryPageSize
+    }
+    if pageSize > MaxPriceHistoryPageSize {
+        pageSize = MaxPriceHistoryPageSize
+    }
+
+    // fetch one extra change to learn whether another page follows
+    changes, err := uc.prices.GetPriceHistory(ctx, productID, pageToken, pageSize+1)
+    if err != nil {
+        return nil, "", err
+    }
+    if len(changes) <= pageSize {
+        return changes, "", nil
+    }
+    changes = changes[:pageSize]
+    return changes, changes[pageSize-1].ID, nil
+}
+
+// applyPrices sets the sale price of products from the price lists in effect
+// now, then converts their prices into the currency asked for with ctx
+func (uc *ProductUseCase) applyPrices(ctx context.Context, products ...*models.Product) error {
+    if len(products) == 0 {
+        return nil
+    }
+    ids := make([]string, len(products))
+    for i, product := range products {
+        ids[i] = product.ID
+    }
+    now := time.Now().UTC()
+    lists, err := uc.prices.ActivePriceLists(ctx, ids, now)
+    if err != nil {
+        return fmt.Errorf("failed to load price lists: %w", err)
+    }
+    pricing.ApplyToProducts(lists, now, products...)
+
+    rates, currency, err := uc.exchangeRates(ctx)
+    if err != nil || rates == nil {
+        return err
+    }
+    return rates.ConvertProducts(currency, products...)
+}
+
+// applyVariantPrices sets the sale price of a product and its variants from
+// the price lists in effect now, then converts their prices into the currency
+// asked for with ctx
+func (uc *ProductUseCase) applyVariantPrices(ctx context.Context, product *models.Product, variants ...*models.Variant) error {
+    now := time.Now().UTC()
+    lists, err := uc.prices.ActivePriceLists(ctx, []string{product.ID}, now)
+    if err != nil {
+        return fmt.Errorf("failed to load price lists: %w", err)
+    }
+    pricing.ApplyToProducts(lists, now, product)
+    pricing.ApplyToVariants(lists, now, product, variants...)
+
+    rates, currency, err := uc.exchangeRates(ctx)
+    if err != nil || rates == nil {
+        return err
+    }
+    // variants without a price of their own are converted with their product
+    if err := rates.ConvertVariants(currency, product, variants...); err != nil {
+        return err
+    }
+    return rates.ConvertProducts(currency, product)
+}
+
+// recordBasePrice adds a new base price of a product, or of one of its
+// variants, to the price history
+func (uc *ProductUseCase) recordBasePrice(ctx context.Context, productID, variantID string, price *money.Money) error {
+    now := time.Now().UTC()
+    change := &models.PriceChange{
+        ProductID: productID,
+        VariantID: variantID,
+        Price:     price,
+        StartsAt:  now,
+        CreatedAt: now,
+    }
+    if err := uc.prices.RecordPriceChanges(ctx, []*models.PriceChange{change}); err != nil {
+        return fmt.Errorf("price saved but its history could not be recorded: %w", err)
+    }
+    return nil
+}
+
+// recordPriceList adds the prices scheduled by a price list to the price history
+func (uc *ProductUseCase) recordPriceList(ctx context.Context, list *models.PriceList) error {
+    now := time.Now().UTC()
+    changes := make([]*models.PriceChange, 0, len(list.Entries))
+    for _, entry := range list.Entries {
+        changes = append(changes, &models.PriceChange{
+            ProductID:   entry.ProductID,
+            VariantID:   entry.VariantID,
+            PriceListID: list.ID,
+            Price:       entry.Price,
+            PercentOff:  entry.PercentOff,
+            StartsAt:    list.StartsAt,
+            EndsAt:      list.EndsAt,
+            CreatedAt:   now,
+        })
+    }
+    if err := uc.prices.RecordPriceChanges(ctx, changes); err != nil {
+        return fmt.Errorf("price list saved but its history could not be recorded: %w", err)
+    }
+    return nil
+}
+
+// validatePriceList requires a name, a start before the end, and entries that
+// each give a positive price in the product's currency or a percentage off to
+// an existing product or variant, at most once
+func (uc *ProductUseCase) validatePriceList(ctx context.Context, list *models.PriceList) error {
+    list.Name = strings.TrimSpace(list.Name)
+    if list.Name == "" {
+        return errors.New("price list name cannot be empty")
+    }
+    if list.StartsAt.IsZero() {
+        return errors.New("price list start cannot be empty")
+    }
+    if list.EndsAt != nil && !list.EndsAt.After(list.StartsAt) {
+        return errors.New("price list must end after it starts")
+    }
+    if len(list.Entries) == 0 {
+        return errors.New("price list needs at least one entry")
+    }
+
+    seen := make(map[[2]string]bool, len(list.Entries))
+    for _, entry := range list.Entries {
+        key := [2]string{entry.ProductID, entry.VariantID}
+        if seen[key] {
+            return fmt.Errorf("product %s variant %q is priced more than once", entry.ProductID, entry.VariantID)
+        }
+        seen[key] = true
+
+        switch {
+        case entry.Price != nil && entry.PercentOff != 0:
+            return errors.New("an entry gives either a price or a percentage off, not both")
+        case entry.Price == nil && !(entry.PercentOff > 0 && entry.PercentOff < 100):
+            return errors.New("percentage off must be between 0 and 100")
+        }
+
+        product, err := uc.repo.GetProductByID(ctx, entry.ProductID)
+        if err != nil {
+            return fmt.Errorf("failed to load product %s: %w", entry.ProductID, err)
+        }
+        if entry.Price != nil {
+            if err := validatePrice(*entry.Price, product); err != nil {
+                return fmt.Errorf("entry price: %w", err)
+            }
+        }
+        if entry.VariantID == "" {
+            continue
+        }
+        variant, err := uc.variants.GetVariantByID(ctx, entry.VariantID)
+        if err != nil {
+            return fmt.Errorf("failed to load variant %s: %w", entry.VariantID, err)
+        }
+        if variant.ProductID != entry.ProductID {
+            return fmt.Errorf("variant %s does not belong to product %s", entry.VariantID, entry.ProductID)
+        }
+    }
+    return nil
+}
+
+// validatePrice requires a positive price in the currency of the product it is
+// for, or in a valid currency when there is no product yet
+func validatePrice(price money.Money, product *models.Product) error {
+    if err := price.Validate(); err != nil {
+        return err
+    }
+    if price.Amount == 0 {
+        return errors.New("price must be positive")
+    }
+    if product != nil && price.Currency != product.Price.Currency {
+        return fmt.Errorf("price must be in the product's currency %s, not %s", product.Price.Currency, price.Currency)
+    }
+    return nil
+}
+
+// errCurrencyChange rejects a new price of a product in another currency; its
+// variants and price lists are priced in the currency it has
+func errCurrencyChange(product *models.Product) error {
+    return fmt.Errorf("product %s is priced in %s; its currency cannot change", product.ID, product.Price.Currency)
+}
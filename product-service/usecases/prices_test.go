@@ -0,0 +1,27 @@
+package usecases
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "testing"
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+func TestCreatePriceListRejectsPercentages(t *testing.T) {
+    for _, percent := range []float64{0, -5, 100, 150, math.NaN(), math.Inf(1), math.Inf(-1)} {
+        t.Run(fmt.Sprint(percent), func(t *testing.T) {
+            uc := newTestUseCase(Deps{})
+            list := &models.PriceList{
+                Name:     "Weekend",
+                StartsAt: time.Now(),
+                Entries:  []models.PriceListEntry{{ProductID: "p-1", PercentOff: percent}},
+            }
+            if _, err := uc.CreatePriceList(context.Background(), list); err == nil {
+                t.Errorf("CreatePriceList() accepted %v percent off", percent)
+            }
+        })
+    }
+}
@@ -0,0 +1,172 @@
+package usecases
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/google/uuid"
+    "github.com/samObot19/shopverse/product-service/fulfillment"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+// expiryBatchSize caps the reservations released by one ExpireReservations pass
+const expiryBatchSize = 100
+
+// reserveAttempts bounds how often a reservation is planned again after the
+// stock it was planned against was taken by a concurrent reservation
+const reserveAttempts = 3
+
+// ReserveStock holds stock for every item until the reservation is committed,
+// released or expires. The warehouses the stock is taken from are chosen by the
+// fulfillment strategy, which may use the optional destination. Reserving again
+// with the same reference ID returns the reservation made the first time, so
+// callers can safely retry.
+func (uc *ProductUseCase) ReserveStock(ctx context.Context, referenceID string, items []models.ReservationItem, destination *models.Location) (*models.Reservation, error) {
+    items, err := mergeReservationItems(items)
+    if err != nil {
+        return nil, err
+    }
+
+    if referenceID != "" {
+        existing, err := uc.reservations.GetReservationByReference(ctx, referenceID)
+        if err == nil {
+            return existing, nil
+        }
+        if !errors.Is(err, repository.ErrReservationNotFound) {
+            return nil, err
+        }
+    }
+
+    var reservation *models.Reservation
+    for attempt := 1; ; attempt++ {
+        var planned []models.ReservationItem
+        planned, err = uc.planReservation(ctx, items, destination)
+        if err != nil {
+            return nil, err
+        }
+
+        now := time.Now().UTC()
+        reservation = &models.Reservation{
+            ID:          uuid.NewString(),
+            ReferenceID: referenceID,
+            Status:      models.ReservationPending,
+            Items:       planned,
+            ExpiresAt:   now.Add(uc.reservationTTL),
+            CreatedAt:   now,
+        }
+        err = uc.reservations.ReserveStock(ctx, reservation)
+        if !errors.Is(err, repository.ErrInsufficientStock) || attempt == reserveAttempts {
+            break
+        }
+    }
+    if errors.Is(err, repository.ErrDuplicateReservation) {
+        // a concurrent request with the same reference won the race
+        return uc.reservations.GetReservationByReference(ctx, referenceID)
+    }
+    if err != nil {
+        return nil, err
+    }
+    return reservation, nil
+}
+
+// CommitReservation turns a pending reservation into a sale; committing twice is a no-op
+func (uc *ProductUseCase) CommitReservation(ctx context.Context, id string) (*models.Reservation, error) {
+    if id == "" {
+        return nil, errors.New("reservation ID cannot be empty")
+    }
+    return uc.reservations.CommitReservation(ctx, id, time.Now().UTC())
+}
+
+// ReleaseReservation gives the stock of a pending reservation back; releasing
+// twice, or releasing an expired reservation, is a no-op
+func (uc *ProductUseCase) ReleaseReservation(ctx context.Context, id string) (*models.Reservation, error) {
+    if id == "" {
+        return nil, errors.New("reservation ID cannot be empty")
+    }
+    return uc.reservations.ReleaseReservation(ctx, id, models.ReservationReleased)
+}
+
+// ExpireReservations gives back the stock of every pending reservation whose
+// TTL has elapsed and returns how many were expired
+func (uc *ProductUseCase) ExpireReservations(ctx context.Context) (int, error) {
+    expired := 0
+    for {
+        ids, err := uc.reservations.ExpiredReservations(ctx, time.Now().UTC(), expiryBatchSize)
+        if err != nil {
+            return expired, err
+        }
+        released := 0
+        for _, id := range ids {
+            if _, err := uc.reservations.ReleaseReservation(ctx, id, models.ReservationExpired); err != nil {
+                // committed in the meantime, or a transient failure the next pass retries
+                log.Printf("Failed to expire reservation %s: %v", id, err)
+                continue
+            }
+            released++
+        }
+        expired += released
+        if len(ids) < expiryBatchSize || released == 0 {
+            return expired, nil
+        }
+    }
+}
+
+// planReservation assigns the items to warehouses holding their stock
+func (uc *ProductUseCase) planReservation(ctx context.Context, items []models.ReservationItem, destination *models.Location) ([]models.ReservationItem, error) {
+    warehouses, err := uc.warehouses.ListWarehouses(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    var productIDs []string
+    seen := make(map[string]bool, len(items))
+    for _, item := range items {
+        if !seen[item.ProductID] {
+            seen[item.ProductID] = true
+            productIDs = append(productIDs, item.ProductID)
+        }
+    }
+    levels, err := uc.warehouses.StockLevels(ctx, productIDs)
+    if err != nil {
+        return nil, err
+    }
+
+    planned, err := fulfillment.Plan(uc.fulfillment, warehouses, levels, items, destination)
+    if errors.Is(err, fulfillment.ErrUnfulfillable) {
+        return nil, fmt.Errorf("%w: %v", repository.ErrInsufficientStock, err)
+    }
+    return planned, err
+}
+
+// mergeReservationItems validates items and folds repeated products or
+// variants into one item each, keeping the order they first appear in
+func mergeReservationItems(items []models.ReservationItem) ([]models.ReservationItem, error) {
+    if len(items) == 0 {
+        return nil, errors.New("reservation must contain at least one item")
+    }
+
+    type key struct{ productID, variantID string }
+    positions := make(map[key]int, len(items))
+    var merged []models.ReservationItem
+    for _, item := range items {
+        if item.ProductID == "" {
+            return nil, errors.New("product ID cannot be empty")
+        }
+        if item.Quantity <= 0 {
+            return nil, fmt.Errorf("quantity for product %s must be positive", item.ProductID)
+        }
+
+        k := key{item.ProductID, item.VariantID}
+        if i, ok := positions[k]; ok {
+            merged[i].Quantity += item.Quantity
+            continue
+        }
+        positions[k] = len(merged)
+        merged = append(merged, item)
+    }
+    return merged, nil
+}
@@ -0,0 +1,300 @@
+package usecases
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "reflect"
+    "testing"
+    "time"
+
+    "github.com/samObot19/shopverse/product-service/fulfillment"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+// oneWarehouse holds plenty of stock of p-1 and p-2 in a single warehouse
+func oneWarehouse() *fakeWarehouses {
+    return &fakeWarehouses{
+        warehouses: []*models.Warehouse{{ID: "w-1", Active: true}},
+        levels: []models.StockLevel{
+            {WarehouseID: "w-1", ProductID: "p-1", Quantity: 100},
+            {WarehouseID: "w-1", ProductID: "p-2", Quantity: 100},
+        },
+    }
+}
+
+func TestReserveStock(t *testing.T) {
+    earlier := &models.Reservation{ID: "r-earlier", ReferenceID: "cart-1", Status: models.ReservationPending}
+    winner := &models.Reservation{ID: "r-winner", ReferenceID: "cart-1", Status: models.ReservationPending}
+
+    tests := []struct {
+        name      string
+        reference string
+        items     []models.ReservationItem
+        existing  *models.Reservation
+        raced     *models.Reservation
+        wantID    string
+        wantItems []models.ReservationItem
+        wantErr   bool
+    }{
+        {
+            name: "repeated items are merged",
+            items: []models.ReservationItem{
+                {ProductID: "p-1", Quantity: 2},
+                {ProductID: "p-2", Quantity: 1},
+                {ProductID: "p-1", Quantity: 3},
+            },
+            wantItems: []models.ReservationItem{
+                {ProductID: "p-1", WarehouseID: "w-1", Quantity: 5},
+                {ProductID: "p-2", WarehouseID: "w-1", Quantity: 1},
+            },
+        },
+        {
+            name:    "no items",
+            wantErr: true,
+        },
+        {
+            name:    "quantity that is not positive",
+            items:   []models.ReservationItem{{ProductID: "p-1", Quantity: 0}},
+            wantErr: true,
+        },
+        {
+            name:    "item without a product",
+            items:   []models.ReservationItem{{Quantity: 1}},
+            wantErr: true,
+        },
+        {
+            name:      "reserving again returns the first reservation",
+            reference: "cart-1",
+            items:     []models.ReservationItem{{ProductID: "p-1", Quantity: 1}},
+            existing:  earlier,
+            wantID:    "r-earlier",
+        },
+        {
+            name:      "a concurrent reservation with the same reference wins",
+            reference: "cart-1",
+            items:     []models.ReservationItem{{ProductID: "p-1", Quantity: 1}},
+            raced:     winner,
+            wantID:    "r-winner",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            reservations := &fakeReservations{byReference: map[string]*models.Reservation{}, raced: tt.raced}
+            if tt.existing != nil {
+                reservations.byReference[tt.existing.ReferenceID] = tt.existing
+            }
+            uc := newTestUseCase(Deps{Reservations: reservations, Warehouses: oneWarehouse()})
+
+            got, err := uc.ReserveStock(context.Background(), tt.reference, tt.items, nil)
+            if (err != nil) != tt.wantErr {
+                t.Fatalf("ReserveStock() error = %v, wantErr %v", err, tt.wantErr)
+            }
+            if tt.wantErr {
+                if len(reservations.reserved) != 0 {
+                    t.Errorf("ReserveStock() reserved %+v for invalid items", reservations.reserved)
+                }
+                return
+            }
+            if tt.wantID != "" && got.ID != tt.wantID {
+                t.Errorf("ReserveStock() = %s, want %s", got.ID, tt.wantID)
+            }
+            if tt.wantItems != nil && !reflect.DeepEqual(got.Items, tt.wantItems) {
+                t.Errorf("ReserveStock() items = %+v, want %+v", got.Items, tt.wantItems)
+            }
+            if tt.existing != nil && len(reservations.reserved) != 0 {
+                t.Errorf("ReserveStock() reserved again for a known reference")
+            }
+        })
+    }
+}
+
+func TestReserveStockExpiresAfterTTL(t *testing.T) {
+    reservations := &fakeReservations{byReference: map[string]*models.Reservation{}}
+    uc := newTestUseCase(Deps{Reservations: reservations, Warehouses: oneWarehouse(), ReservationTTL: time.Hour})
+
+    got, err := uc.ReserveStock(context.Background(), "", []models.ReservationItem{{ProductID: "p-1", Quantity: 1}}, nil)
+    if err != nil {
+        t.Fatalf("ReserveStock() error = %v", err)
+    }
+    if got.Status != models.ReservationPending {
+        t.Errorf("ReserveStock() status = %s, want pending", got.Status)
+    }
+    if ttl := got.ExpiresAt.Sub(got.CreatedAt); ttl != time.Hour {
+        t.Errorf("ReserveStock() expires %v after it was made, want 1h", ttl)
+    }
+}
+
+func TestReserveStockRoutesToWarehouses(t *testing.T) {
+    warehouses := []*models.Warehouse{
+        {ID: "paris", Latitude: 48.8566, Longitude: 2.3522, Active: true},
+        {ID: "berlin", Latitude: 52.5200, Longitude: 13.4050, Active: true},
+        {ID: "madrid", Latitude: 40.4168, Longitude: -3.7038, Active: false},
+    }
+    nearBerlin := &models.Location{Latitude: 52.4, Longitude: 13.1}
+
+    tests := []struct {
+        name        string
+        strategy    fulfillment.Strategy
+        destination *models.Location
+        levels      []models.StockLevel
+        items       []models.ReservationItem
+        want        []models.ReservationItem
+        wantErr     error
+    }{
+        {
+            name:        "nearest warehouse holding everything",
+            strategy:    fulfillment.Nearest,
+            destination: nearBerlin,
+            levels: []models.StockLevel{
+                {WarehouseID: "paris", ProductID: "p-1", Quantity: 50},
+                {WarehouseID: "berlin", ProductID: "p-1", Quantity: 5},
+            },
+            items: []models.ReservationItem{{ProductID: "p-1", Quantity: 2}},
+            want:  []models.ReservationItem{{ProductID: "p-1", WarehouseID: "berlin", Quantity: 2}},
+        },
+        {
+            name:        "warehouse holding the most",
+            strategy:    fulfillment.MostStock,
+            destination: nearBerlin,
+            levels: []models.StockLevel{
+                {WarehouseID: "paris", ProductID: "p-1", Quantity: 50},
+                {WarehouseID: "berlin", ProductID: "p-1", Quantity: 5},
+            },
+            items: []models.ReservationItem{{ProductID: "p-1", Quantity: 2}},
+            want:  []models.ReservationItem{{ProductID: "p-1", WarehouseID: "paris", Quantity: 2}},
+        },
+        {
+            name:        "split when no warehouse holds everything",
+            strategy:    fulfillment.Nearest,
+            destination: nearBerlin,
+            levels: []models.StockLevel{
+                {WarehouseID: "paris", ProductID: "p-1", Quantity: 4},
+                {WarehouseID: "berlin", ProductID: "p-1", Quantity: 3},
+            },
+            items: []models.ReservationItem{{ProductID: "p-1", Quantity: 5}},
+            want: []models.ReservationItem{
+                {ProductID: "p-1", WarehouseID: "berlin", Quantity: 3},
+                {ProductID: "p-1", WarehouseID: "paris", Quantity: 2},
+            },
+        },
+        {
+            name:     "stock of inactive warehouses is not reserved",
+            strategy: fulfillment.Nearest,
+            levels: []models.StockLevel{
+                {WarehouseID: "madrid", ProductID: "p-1", Quantity: 10},
+                {WarehouseID: "paris", ProductID: "p-1", Quantity: 1},
+            },
+            items:   []models.ReservationItem{{ProductID: "p-1", Quantity: 2}},
+            wantErr: repository.ErrInsufficientStock,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            reservations := &fakeReservations{byReference: map[string]*models.Reservation{}}
+            uc := newTestUseCase(Deps{
+                Reservations: reservations,
+                Warehouses:   &fakeWarehouses{warehouses: warehouses, levels: tt.levels},
+                Fulfillment:  tt.strategy,
+            })
+
+            got, err := uc.ReserveStock(context.Background(), "", tt.items, tt.destination)
+            if !errors.Is(err, tt.wantErr) {
+                t.Fatalf("ReserveStock() error = %v, want %v", err, tt.wantErr)
+            }
+            if tt.wantErr != nil {
+                if len(reservations.reserved) != 0 {
+                    t.Errorf("ReserveStock() reserved %+v", reservations.reserved[0].Items)
+                }
+                return
+            }
+            if !reflect.DeepEqual(got.Items, tt.want) {
+                t.Errorf("ReserveStock() items = %+v, want %+v", got.Items, tt.want)
+            }
+        })
+    }
+}
+
+func TestReserveStockPlansAgainAfterConcurrentReservation(t *testing.T) {
+    tests := []struct {
+        name        string
+        reserveErrs []error
+        wantErr     error
+    }{
+        {name: "stock taken once", reserveErrs: []error{repository.ErrInsufficientStock}},
+        {
+            name:        "stock taken on every attempt",
+            reserveErrs: []error{repository.ErrInsufficientStock, repository.ErrInsufficientStock, repository.ErrInsufficientStock},
+            wantErr:     repository.ErrInsufficientStock,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            reservations := &fakeReservations{byReference: map[string]*models.Reservation{}, reserveErrs: tt.reserveErrs}
+            uc := newTestUseCase(Deps{Reservations: reservations, Warehouses: oneWarehouse()})
+
+            _, err := uc.ReserveStock(context.Background(), "", []models.ReservationItem{{ProductID: "p-1", Quantity: 1}}, nil)
+            if !errors.Is(err, tt.wantErr) {
+                t.Fatalf("ReserveStock() error = %v, want %v", err, tt.wantErr)
+            }
+            if len(reservations.reserveErrs) != 0 {
+                t.Errorf("ReserveStock() gave up after %d of %d refusals", len(tt.reserveErrs)-len(reservations.reserveErrs), len(tt.reserveErrs))
+            }
+        })
+    }
+}
+
+func TestExpireReservations(t *testing.T) {
+    ids := func(n int) []string {
+        ids := make([]string, n)
+        for i := range ids {
+            ids[i] = fmt.Sprintf("r-%03d", i)
+        }
+        return ids
+    }
+
+    tests := []struct {
+        name    string
+        pending []string
+        failing []string
+        want    int
+    }{
+        {name: "nothing expired", want: 0},
+        {name: "one batch", pending: ids(3), want: 3},
+        {name: "several batches", pending: ids(expiryBatchSize + 50), want: expiryBatchSize + 50},
+        {
+            name:    "a reservation that cannot be released is skipped",
+            pending: ids(expiryBatchSize + 50),
+            failing: []string{"r-000"},
+            want:    expiryBatchSize + 49,
+        },
+        {
+            name:    "a full batch that cannot be released ends the pass",
+            pending: ids(expiryBatchSize),
+            failing: ids(expiryBatchSize),
+            want:    0,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            reservations := &fakeReservations{pending: tt.pending, failing: map[string]bool{}}
+            for _, id := range tt.failing {
+                reservations.failing[id] = true
+            }
+            uc := newTestUseCase(Deps{Reservations: reservations})
+
+            got, err := uc.ExpireReservations(context.Background())
+            if err != nil {
+                t.Fatalf("ExpireReservations() error = %v", err)
+            }
+            if got != tt.want {
+                t.Errorf("ExpireReservations() = %d, want %d", got, tt.want)
+            }
+        })
+    }
+}
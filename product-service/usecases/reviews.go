@@ -0,0 +1,223 @@
+package usecases
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/google/uuid"
+    orderpb "github.com/samObot19/shopverse/product-service/clients/order-client/proto/pb"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// deliveredStatus is the order-service status of an order that has reached the customer
+const deliveredStatus = "Delivered"
+
+// defaultReviewPage and maxReviewPage bound the number of reviews listed at once
+const (
+    defaultReviewPage = 20
+    maxReviewPage     = 100
+)
+
+// Review errors
+var (
+    ErrNotPurchased      = errors.New("only customers with a delivered order of the product can review it")
+    ErrNotReviewAuthor   = errors.New("only the author can change this review")
+    ErrOwnReviewVote     = errors.New("authors cannot vote for their own review")
+    ErrReviewNotApproved = errors.New("only approved reviews can be voted for")
+)
+
+// CreateReview records a customer's review of a product they received in a
+// delivered order and returns its ID. New reviews wait for moderation before
+// they count towards the rating of the product.
+func (uc *ProductUseCase) CreateReview(ctx context.Context, review *models.Review) (string, error) {
+    if review.ProductID == "" || review.UserID == "" {
+        return "", errors.New("product ID and user ID cannot be empty")
+    }
+    if err := validateReview(review); err != nil {
+        return "", err
+    }
+    if _, err := uc.repo.GetProductByID(ctx, review.ProductID); err != nil {
+        return "", fmt.Errorf("failed to load product %s: %w", review.ProductID, err)
+    }
+    orderID, err := uc.deliveredOrder(ctx, review.UserID, review.ProductID)
+    if err != nil {
+        return "", err
+    }
+
+    now := time.Now().UTC()
+    review.ID = uuid.NewString()
+    review.OrderID = orderID
+    review.Status = models.ReviewPending
+    review.HelpfulVotes = 0
+    review.CreatedAt = now
+    review.UpdatedAt = now
+    if err := uc.reviews.CreateReview(ctx, review); err != nil {
+        return "", err
+    }
+    return review.ID, nil
+}
+
+// GetReview retrieves a review by its ID
+func (uc *ProductUseCase) GetReview(ctx context.Context, id string) (*models.Review, error) {
+    if id == "" {
+        return nil, errors.New("review ID cannot be empty")
+    }
+    return uc.reviews.GetReview(ctx, id)
+}
+
+// ListReviews pages through the reviews of a product, or of every product when
+// productID is empty, optionally narrowed to one moderation status. A limit
+// of zero returns the default page size.
+func (uc *ProductUseCase) ListReviews(ctx context.Context, productID string, status models.ReviewStatus, limit, offset int) ([]*models.Review, error) {
+    if status != "" && !validReviewStatus(status) {
+        return nil, fmt.Errorf("unknown review status %q", status)
+    }
+    if limit < 0 || offset < 0 {
+        return nil, errors.New("limit and offset cannot be negative")
+    }
+    if limit == 0 {
+        limit = defaultReviewPage
+    }
+    if limit > maxReviewPage {
+        limit = maxReviewPage
+    }
+    return uc.reviews.ListReviews(ctx, productID, status, limit, offset)
+}
+
+// EditReview lets the author change the rating and text of their review. The
+// edited review goes back to moderation.
+func (uc *ProductUseCase) EditReview(ctx context.Context, id, userID string, edit *models.Review) error {
+    review, err := uc.GetReview(ctx, id)
+    if err != nil {
+        return err
+    }
+    if review.UserID != userID {
+        return ErrNotReviewAuthor
+    }
+    if err := validateReview(edit); err != nil {
+        return err
+    }
+
+    review.Rating = edit.Rating
+    review.Title = edit.Title
+    review.Body = edit.Body
+    review.Status = models.ReviewPending
+    review.UpdatedAt = time.Now().UTC()
+    if err := uc.reviews.UpdateReview(ctx, id, review); err != nil {
+        return err
+    }
+    return uc.reindexProduct(ctx, review.ProductID)
+}
+
+// ModerateReview moves a review to a moderation status; only approved reviews
+// count towards the rating of the product
+func (uc *ProductUseCase) ModerateReview(ctx context.Context, id string, status models.ReviewStatus) error {
+    if !validReviewStatus(status) {
+        return fmt.Errorf("unknown review status %q", status)
+    }
+    review, err := uc.GetReview(ctx, id)
+    if err != nil {
+        return err
+    }
+    if review.Status == status {
+        return nil
+    }
+
+    review.Status = status
+    review.UpdatedAt = time.Now().UTC()
+    if err := uc.reviews.UpdateReview(ctx, id, review); err != nil {
+        return err
+    }
+    return uc.reindexProduct(ctx, review.ProductID)
+}
+
+// DeleteReview removes a review. A non-empty userID must be the author's; an
+// empty one removes the review on behalf of a moderator.
+func (uc *ProductUseCase) DeleteReview(ctx context.Context, id, userID string) error {
+    review, err := uc.GetReview(ctx, id)
+    if err != nil {
+        return err
+    }
+    if userID != "" && review.UserID != userID {
+        return ErrNotReviewAuthor
+    }
+    if err := uc.reviews.DeleteReview(ctx, id); err != nil {
+        return err
+    }
+    return uc.reindexProduct(ctx, review.ProductID)
+}
+
+// VoteReviewHelpful records that a user found an approved review helpful; each
+// user is counted once per review
+func (uc *ProductUseCase) VoteReviewHelpful(ctx context.Context, id, userID string) error {
+    if userID == "" {
+        return errors.New("user ID cannot be empty")
+    }
+    review, err := uc.GetReview(ctx, id)
+    if err != nil {
+        return err
+    }
+    if review.UserID == userID {
+        return ErrOwnReviewVote
+    }
+    if review.Status != models.ReviewApproved {
+        return ErrReviewNotApproved
+    }
+    return uc.reviews.AddHelpfulVote(ctx, id, userID, time.Now().UTC())
+}
+
+// deliveredOrder returns the ID of a delivered order of the user that contains
+// the product, or ErrNotPurchased if there is none
+func (uc *ProductUseCase) deliveredOrder(ctx context.Context, userID, productID string) (string, error) {
+    resp, err := uc.orders.GetAllOrders(ctx, &orderpb.GetAllOrdersRequest{UserId: userID})
+    if err != nil {
+        return "", fmt.Errorf("failed to load orders of user %s: %w", userID, err)
+    }
+    for _, order := range resp.Orders {
+        if !strings.EqualFold(order.OrderStatus, deliveredStatus) {
+            continue
+        }
+        for _, item := range order.Items {
+            if item.ProductId == productID {
+                return fmt.Sprint(order.Id), nil
+            }
+        }
+    }
+    return "", ErrNotPurchased
+}
+
+// reindexProduct refreshes a product in the search index after its rating changed
+func (uc *ProductUseCase) reindexProduct(ctx context.Context, productID string) error {
+    product, err := uc.repo.GetProductByID(ctx, productID)
+    if err != nil {
+        return fmt.Errorf("review saved but product could not be reindexed: %w", err)
+    }
+    if !product.Archived() {
+        uc.index.Add(product)
+    }
+    return nil
+}
+
+// validateReview requires a rating of one to five stars and trims the text
+func validateReview(review *models.Review) error {
+    if review.Rating < 1 || review.Rating > 5 {
+        return errors.New("rating must be between 1 and 5")
+    }
+    review.Title = strings.TrimSpace(review.Title)
+    review.Body = strings.TrimSpace(review.Body)
+    if review.Body == "" {
+        return errors.New("review text cannot be empty")
+    }
+    return nil
+}
+
+func validReviewStatus(status models.ReviewStatus) bool {
+    switch status {
+    case models.ReviewPending, models.ReviewApproved, models.ReviewRejected:
+        return true
+    }
+    return false
+}
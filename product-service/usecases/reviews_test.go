@@ -0,0 +1,199 @@
+package usecases
+
+import (
+    "context"
+    "errors"
+    "testing"
+
+    orderpb "github.com/samObot19/shopverse/product-service/clients/order-client/proto/pb"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// newReviewUseCase serves product p-1 and review rv-1 of it by alice, in status
+func newReviewUseCase(status models.ReviewStatus, orders map[string][]*orderpb.Order) (*ProductUseCase, *fakeReviews) {
+    reviews := &fakeReviews{
+        reviews: map[string]*models.Review{
+            "rv-1": {ID: "rv-1", ProductID: "p-1", UserID: "alice", Rating: 4, Body: "Fits well", Status: status},
+        },
+        votes: map[string][]string{},
+    }
+    uc := newTestUseCase(Deps{
+        Repo:    &fakeProducts{products: map[string]*models.Product{"p-1": {ID: "p-1", Title: "Shirt"}}},
+        Reviews: reviews,
+        Orders:  &fakeOrders{orders: orders},
+    })
+    return uc, reviews
+}
+
+func TestCreateReviewRequiresDeliveredOrder(t *testing.T) {
+    order := func(id uint32, status string, productIDs ...string) *orderpb.Order {
+        o := &orderpb.Order{Id: id, OrderStatus: status}
+        for _, productID := range productIDs {
+            o.Items = append(o.Items, &orderpb.OrderItem{ProductId: productID})
+        }
+        return o
+    }
+
+    tests := []struct {
+        name        string
+        orders      []*orderpb.Order
+        wantOrderID string
+        wantErr     error
+    }{
+        {
+            name:        "delivered order of the product",
+            orders:      []*orderpb.Order{order(7, "Pending", "p-1"), order(9, "Delivered", "p-2", "p-1")},
+            wantOrderID: "9",
+        },
+        {
+            name:        "order status is matched in any case",
+            orders:      []*orderpb.Order{order(3, "delivered", "p-1")},
+            wantOrderID: "3",
+        },
+        {
+            name:    "order of the product not delivered yet",
+            orders:  []*orderpb.Order{order(7, "Shipped", "p-1")},
+            wantErr: ErrNotPurchased,
+        },
+        {
+            name:    "delivered order of other products",
+            orders:  []*orderpb.Order{order(9, "Delivered", "p-2")},
+            wantErr: ErrNotPurchased,
+        },
+        {
+            name:    "no orders",
+            wantErr: ErrNotPurchased,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            uc, reviews := newReviewUseCase(models.ReviewApproved, map[string][]*orderpb.Order{"bob": tt.orders})
+            review := &models.Review{ProductID: "p-1", UserID: "bob", Rating: 5, Body: " Great shirt "}
+
+            id, err := uc.CreateReview(context.Background(), review)
+            if !errors.Is(err, tt.wantErr) {
+                t.Fatalf("CreateReview() error = %v, want %v", err, tt.wantErr)
+            }
+            if tt.wantErr != nil {
+                if len(reviews.reviews) != 1 {
+                    t.Errorf("CreateReview() stored a review of a product that was not delivered")
+                }
+                return
+            }
+            stored := reviews.reviews[id]
+            if stored == nil {
+                t.Fatalf("CreateReview() did not store review %s", id)
+            }
+            if stored.OrderID != tt.wantOrderID {
+                t.Errorf("review order = %q, want %q", stored.OrderID, tt.wantOrderID)
+            }
+            if stored.Status != models.ReviewPending || stored.Body != "Great shirt" {
+                t.Errorf("review stored as %s %q, want pending \"Great shirt\"", stored.Status, stored.Body)
+            }
+        })
+    }
+}
+
+func TestCreateReviewOrderServiceDown(t *testing.T) {
+    uc, _ := newReviewUseCase(models.ReviewApproved, nil)
+    uc.orders = &fakeOrders{err: errors.New("connection refused")}
+
+    _, err := uc.CreateReview(context.Background(), &models.Review{ProductID: "p-1", UserID: "bob", Rating: 5, Body: "Great"})
+    if err == nil || errors.Is(err, ErrNotPurchased) {
+        t.Errorf("CreateReview() error = %v, want the order-service failure", err)
+    }
+}
+
+func TestReviewChangesAreAuthorOnly(t *testing.T) {
+    edit := &models.Review{Rating: 2, Body: "Shrank in the wash"}
+
+    tests := []struct {
+        name    string
+        change  func(uc *ProductUseCase) error
+        wantErr error
+        removed bool
+    }{
+        {
+            name:   "author edits",
+            change: func(uc *ProductUseCase) error { return uc.EditReview(context.Background(), "rv-1", "alice", edit) },
+        },
+        {
+            name:    "someone else edits",
+            change:  func(uc *ProductUseCase) error { return uc.EditReview(context.Background(), "rv-1", "mallory", edit) },
+            wantErr: ErrNotReviewAuthor,
+        },
+        {
+            name:    "author deletes",
+            change:  func(uc *ProductUseCase) error { return uc.DeleteReview(context.Background(), "rv-1", "alice") },
+            removed: true,
+        },
+        {
+            name:    "moderator deletes",
+            change:  func(uc *ProductUseCase) error { return uc.DeleteReview(context.Background(), "rv-1", "") },
+            removed: true,
+        },
+        {
+            name:    "someone else deletes",
+            change:  func(uc *ProductUseCase) error { return uc.DeleteReview(context.Background(), "rv-1", "mallory") },
+            wantErr: ErrNotReviewAuthor,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            uc, reviews := newReviewUseCase(models.ReviewApproved, nil)
+
+            err := tt.change(uc)
+            if !errors.Is(err, tt.wantErr) {
+                t.Fatalf("error = %v, want %v", err, tt.wantErr)
+            }
+            review, kept := reviews.reviews["rv-1"]
+            if kept == tt.removed {
+                t.Fatalf("review kept = %v, want %v", kept, !tt.removed)
+            }
+            switch {
+            case tt.wantErr != nil:
+                if review.Rating != 4 || review.Status != models.ReviewApproved {
+                    t.Errorf("refused change altered the review: %+v", review)
+                }
+            case kept:
+                if review.Rating != 2 || review.Status != models.ReviewPending {
+                    t.Errorf("edited review = %d stars %s, want 2 stars back in moderation", review.Rating, review.Status)
+                }
+            }
+        })
+    }
+}
+
+func TestVoteReviewHelpful(t *testing.T) {
+    tests := []struct {
+        name    string
+        status  models.ReviewStatus
+        voter   string
+        wantErr error
+    }{
+        {name: "approved review", status: models.ReviewApproved, voter: "bob"},
+        {name: "pending review", status: models.ReviewPending, voter: "bob", wantErr: ErrReviewNotApproved},
+        {name: "rejected review", status: models.ReviewRejected, voter: "bob", wantErr: ErrReviewNotApproved},
+        {name: "own review", status: models.ReviewApproved, voter: "alice", wantErr: ErrOwnReviewVote},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            uc, reviews := newReviewUseCase(tt.status, nil)
+
+            err := uc.VoteReviewHelpful(context.Background(), "rv-1", tt.voter)
+            if !errors.Is(err, tt.wantErr) {
+                t.Fatalf("VoteReviewHelpful() error = %v, want %v", err, tt.wantErr)
+            }
+            wantVotes := 0
+            if tt.wantErr == nil {
+                wantVotes = 1
+            }
+            if votes := len(reviews.votes["rv-1"]); votes != wantVotes {
+                t.Errorf("VoteReviewHelpful() recorded %d votes, want %d", votes, wantVotes)
+            }
+        })
+    }
+}
@@ -0,0 +1,91 @@
+package usecases
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+const (
+    // DefaultStockHistoryPageSize is used when a history request sets no page size
+    DefaultStockHistoryPageSize = 50
+    // MaxStockHistoryPageSize caps the page size of a history request
+    MaxStockHistoryPageSize = 200
+)
+
+// AdjustStock changes the stock of a product, or of one of its variants, by
+// movement.Delta and records the movement in the stock ledger
+func (uc *ProductUseCase) AdjustStock(ctx context.Context, movement *models.StockMovement) error {
+    if movement.ProductID == "" {
+        return errors.New("product ID cannot be empty")
+    }
+    if movement.Delta == 0 {
+        return errors.New("delta must not be zero")
+    }
+    change, err := validateStockChange(models.StockChange{
+        WarehouseID: movement.WarehouseID,
+        Reason:      movement.Reason,
+        Actor:       movement.Actor,
+        ReferenceID: movement.ReferenceID,
+    })
+    if err != nil {
+        return err
+    }
+    movement.WarehouseID, movement.Reason = change.WarehouseID, change.Reason
+    movement.Actor, movement.ReferenceID = change.Actor, change.ReferenceID
+    return uc.ledger.AdjustStock(ctx, movement)
+}
+
+// GetStockHistory returns a page of the stock movements of a product, newest
+// first, and the token of the next page, which is empty on the last page
+func (uc *ProductUseCase) GetStockHistory(ctx context.Context, productID, variantID, pageToken string, pageSize int) ([]*models.StockMovement, string, error) {
+    if productID == "" {
+        return nil, "", errors.New("product ID cannot be empty")
+    }
+    if pageSize <= 0 {
+        pageSize = DefaultStockHistoryPageSize
+    }
+    if pageSize > MaxStockHistoryPageSize {
+        pageSize = MaxStockHistoryPageSize
+    }
+
+    // fetch one extra movement to learn whether another page follows
+    movements, err := uc.ledger.GetStockHistory(ctx, productID, variantID, pageToken, pageSize+1)
+    if err != nil {
+        return nil, "", err
+    }
+    if len(movements) <= pageSize {
+        return movements, "", nil
+    }
+    movements = movements[:pageSize]
+    return movements, movements[pageSize-1].ID, nil
+}
+
+// ReconcileStock lists the products and variants whose stock no longer equals
+// the sum of their stock movements
+func (uc *ProductUseCase) ReconcileStock(ctx context.Context) ([]models.StockDiscrepancy, error) {
+    return uc.ledger.StockDiscrepancies(ctx)
+}
+
+// validateStockChange requires a known reason, defaulting to an adjustment,
+// and an actor; a change that names no warehouse applies to the default one
+func validateStockChange(change models.StockChange) (models.StockChange, error) {
+    if change.WarehouseID == "" {
+        change.WarehouseID = models.DefaultWarehouseID
+    }
+    switch change.Reason {
+    case "":
+        change.Reason = models.StockAdjustment
+    case models.StockReceiving, models.StockSale, models.StockReservation, models.StockReturn, models.StockAdjustment:
+    default:
+        return change, fmt.Errorf("unknown stock change reason %q", change.Reason)
+    }
+    change.Actor = strings.TrimSpace(change.Actor)
+    if change.Actor == "" {
+        return change, errors.New("actor cannot be empty")
+    }
+    return change, nil
+}
@@ -0,0 +1,76 @@
+package usecases
+
+import (
+    "context"
+    "errors"
+    "time"
+    "unicode/utf8"
+
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+// maxStockCheckReason caps the bytes of a reason so it fits the order_stock_checks table
+const maxStockCheckReason = 512
+
+// CheckOrderStock reserves and commits the stock of every item of a new order
+// in one reservation, so either all of the order's stock is taken or none is.
+// It is taken from the warehouses the fulfillment strategy picks for the
+// optional destination the order ships to. The outcome is recorded per order:
+// checking an order again returns the outcome of the first check, so a
+// redelivered order is never reserved twice.
+// An order whose items are invalid or out of stock is recorded as
+// unavailable; any other error is returned and the order can be checked again.
+func (uc *ProductUseCase) CheckOrderStock(ctx context.Context, orderID string, items []models.ReservationItem, destination *models.Location) (*models.StockCheck, error) {
+    if orderID == "" {
+        return nil, errors.New("order ID cannot be empty")
+    }
+    existing, err := uc.stockChecks.GetStockCheck(ctx, orderID)
+    if err == nil {
+        return existing, nil
+    }
+    if !errors.Is(err, repository.ErrStockCheckNotFound) {
+        return nil, err
+    }
+
+    check := &models.StockCheck{OrderID: orderID}
+    if _, err := mergeReservationItems(items); err != nil {
+        check.Reason = err.Error()
+    } else {
+        // the reference makes a retry after a failure below reuse the reservation
+        reservation, err := uc.ReserveStock(ctx, "order-"+orderID, items, destination)
+        if err == nil {
+            reservation, err = uc.CommitReservation(ctx, reservation.ID)
+        }
+        switch {
+        case err == nil:
+            check.StockAvailable = true
+            check.ReservationID = reservation.ID
+        case errors.Is(err, repository.ErrInsufficientStock),
+            errors.Is(err, repository.ErrVariantNotFound),
+            errors.Is(err, repository.ErrReservationClosed):
+            check.Reason = err.Error()
+        default:
+            return nil, err
+        }
+    }
+    check.Reason = truncateUTF8(check.Reason, maxStockCheckReason)
+    check.CheckedAt = time.Now().UTC()
+
+    if err := uc.stockChecks.RecordStockCheck(ctx, check); err != nil {
+        return nil, err
+    }
+    // a concurrent check of the same order may have been recorded first
+    return uc.stockChecks.GetStockCheck(ctx, orderID)
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character
+func truncateUTF8(s string, n int) string {
+    if len(s) <= n {
+        return s
+    }
+    for n > 0 && !utf8.RuneStart(s[n]) {
+        n--
+    }
+    return s[:n]
+}
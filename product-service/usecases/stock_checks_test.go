@@ -0,0 +1,166 @@
+package usecases
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+    "testing"
+    "unicode/utf8"
+
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/repository"
+)
+
+func TestCheckOrderStock(t *testing.T) {
+    earlier := &models.StockCheck{OrderID: "42", StockAvailable: false, Reason: "insufficient stock"}
+
+    tests := []struct {
+        name          string
+        items         []models.ReservationItem
+        existing      *models.StockCheck
+        reserveErrs   []error
+        wantAvailable bool
+        wantReason    bool
+        wantReserved  bool
+        wantErr       bool
+    }{
+        {
+            name:          "in stock",
+            items:         []models.ReservationItem{{ProductID: "p-1", Quantity: 2}, {ProductID: "p-2", Quantity: 1}},
+            wantAvailable: true,
+            wantReserved:  true,
+        },
+        {
+            name:       "out of stock",
+            items:      []models.ReservationItem{{ProductID: "p-1", Quantity: 500}},
+            wantReason: true,
+        },
+        {
+            name:       "invalid items",
+            items:      []models.ReservationItem{{ProductID: "p-1", Quantity: 0}},
+            wantReason: true,
+        },
+        {
+            name:       "checked before",
+            items:      []models.ReservationItem{{ProductID: "p-1", Quantity: 1}},
+            existing:   earlier,
+            wantReason: true,
+        },
+        {
+            name:        "transient failure is not recorded",
+            items:       []models.ReservationItem{{ProductID: "p-1", Quantity: 1}},
+            reserveErrs: []error{errors.New("connection reset")},
+            wantErr:     true,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            checks := &fakeStockChecks{checks: map[string]*models.StockCheck{}}
+            if tt.existing != nil {
+                checks.checks[tt.existing.OrderID] = tt.existing
+            }
+            reservations := &fakeReservations{byReference: map[string]*models.Reservation{}, reserveErrs: tt.reserveErrs}
+            uc := newTestUseCase(Deps{StockChecks: checks, Reservations: reservations, Warehouses: oneWarehouse()})
+
+            got, err := uc.CheckOrderStock(context.Background(), "42", tt.items, nil)
+            if (err != nil) != tt.wantErr {
+                t.Fatalf("CheckOrderStock() error = %v, wantErr %v", err, tt.wantErr)
+            }
+            if tt.wantErr {
+                if len(checks.checks) != 0 {
+                    t.Errorf("CheckOrderStock() recorded %+v after a transient failure", checks.checks["42"])
+                }
+                return
+            }
+            if tt.existing != nil && got != tt.existing {
+                t.Errorf("CheckOrderStock() = %+v, want the first outcome %+v", got, tt.existing)
+            }
+            if got.StockAvailable != tt.wantAvailable || (got.Reason != "") != tt.wantReason {
+                t.Errorf("CheckOrderStock() = available %v reason %q, want available %v", got.StockAvailable, got.Reason, tt.wantAvailable)
+            }
+            if reserved := len(reservations.reserved) == 1; reserved != tt.wantReserved {
+                t.Fatalf("CheckOrderStock() made %d reservations", len(reservations.reserved))
+            }
+            if tt.wantReserved {
+                reservation := reservations.reserved[0]
+                if got.ReservationID != reservation.ID || reservation.Status != models.ReservationCommitted {
+                    t.Errorf("CheckOrderStock() recorded reservation %q, want committed %s", got.ReservationID, reservation.ID)
+                }
+                if reservation.ReferenceID != "order-42" {
+                    t.Errorf("reservation reference = %q, want order-42", reservation.ReferenceID)
+                }
+            }
+        })
+    }
+}
+
+func TestCheckOrderStockShipsFromNearestWarehouse(t *testing.T) {
+    // both warehouses hold the order; Paris is nearer to Brussels than Madrid
+    warehouses := &fakeWarehouses{
+        warehouses: []*models.Warehouse{
+            {ID: "madrid", Latitude: 40.4168, Longitude: -3.7038, Active: true},
+            {ID: "paris", Latitude: 48.8566, Longitude: 2.3522, Active: true},
+        },
+        levels: []models.StockLevel{
+            {WarehouseID: "madrid", ProductID: "p-1", Quantity: 10},
+            {WarehouseID: "paris", ProductID: "p-1", Quantity: 10},
+        },
+    }
+    brussels := &models.Location{Latitude: 50.8503, Longitude: 4.3517}
+    lisbon := &models.Location{Latitude: 38.7223, Longitude: -9.1393}
+
+    tests := []struct {
+        name        string
+        destination *models.Location
+        want        string
+    }{
+        {"ships to Brussels", brussels, "paris"},
+        {"ships to Lisbon", lisbon, "madrid"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            reservations := &fakeReservations{byReference: map[string]*models.Reservation{}}
+            uc := newTestUseCase(Deps{
+                StockChecks:  &fakeStockChecks{checks: map[string]*models.StockCheck{}},
+                Reservations: reservations,
+                Warehouses:   warehouses,
+            })
+
+            got, err := uc.CheckOrderStock(context.Background(), "42", []models.ReservationItem{{ProductID: "p-1", Quantity: 2}}, tt.destination)
+            if err != nil || !got.StockAvailable {
+                t.Fatalf("CheckOrderStock() = %+v, %v, want the stock available", got, err)
+            }
+            items := reservations.reserved[0].Items
+            if len(items) != 1 || items[0].WarehouseID != tt.want {
+                t.Errorf("reserved %+v, want all of it from %s", items, tt.want)
+            }
+        })
+    }
+}
+
+func TestCheckOrderStockTruncatesReasonOnRuneBoundary(t *testing.T) {
+    // the reason runs past the cap with "é", which takes two bytes, straddling it
+    long := fmt.Errorf("%w: x%s", repository.ErrInsufficientStock, strings.Repeat("é", maxStockCheckReason))
+    checks := &fakeStockChecks{checks: map[string]*models.StockCheck{}}
+    reservations := &fakeReservations{
+        byReference: map[string]*models.Reservation{},
+        reserveErrs: []error{long, long, long},
+    }
+    uc := newTestUseCase(Deps{StockChecks: checks, Reservations: reservations, Warehouses: oneWarehouse()})
+
+    got, err := uc.CheckOrderStock(context.Background(), "42", []models.ReservationItem{{ProductID: "p-1", Quantity: 1}}, nil)
+    if err != nil {
+        t.Fatalf("CheckOrderStock() error = %v", err)
+    }
+    if len(got.Reason) > maxStockCheckReason || len(got.Reason) < maxStockCheckReason-1 {
+        t.Errorf("reason is %d bytes long, want at most %d and no shorter than needed", len(got.Reason), maxStockCheckReason)
+    }
+    if !utf8.ValidString(got.Reason) {
+        t.Errorf("reason %q is not valid UTF-8", got.Reason[len(got.Reason)-4:])
+    }
+    if !strings.HasPrefix(long.Error(), got.Reason) {
+        t.Errorf("reason is not a prefix of the error")
+    }
+}
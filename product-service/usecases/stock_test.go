@@ -0,0 +1,115 @@
+package usecases
+
+import (
+    "context"
+    "fmt"
+    "testing"
+
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+func TestAdjustStock(t *testing.T) {
+    tests := []struct {
+        name          string
+        movement      models.StockMovement
+        wantWarehouse string
+        wantReason    models.StockReason
+        wantActor     string
+        wantErr       bool
+    }{
+        {
+            name:          "defaults to an adjustment of the default warehouse",
+            movement:      models.StockMovement{ProductID: "p-1", Delta: -2, Actor: " alice "},
+            wantWarehouse: models.DefaultWarehouseID,
+            wantReason:    models.StockAdjustment,
+            wantActor:     "alice",
+        },
+        {
+            name:          "keeps a known reason and warehouse",
+            movement:      models.StockMovement{ProductID: "p-1", WarehouseID: "w-2", Delta: 10, Reason: models.StockReceiving, Actor: "bob"},
+            wantWarehouse: "w-2",
+            wantReason:    models.StockReceiving,
+            wantActor:     "bob",
+        },
+        {
+            name:     "no product",
+            movement: models.StockMovement{Delta: 1, Actor: "alice"},
+            wantErr:  true,
+        },
+        {
+            name:     "zero delta",
+            movement: models.StockMovement{ProductID: "p-1", Actor: "alice"},
+            wantErr:  true,
+        },
+        {
+            name:     "unknown reason",
+            movement: models.StockMovement{ProductID: "p-1", Delta: 1, Reason: "theft", Actor: "alice"},
+            wantErr:  true,
+        },
+        {
+            name:     "blank actor",
+            movement: models.StockMovement{ProductID: "p-1", Delta: 1, Actor: "  "},
+            wantErr:  true,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            ledger := &fakeLedger{}
+            uc := newTestUseCase(Deps{Ledger: ledger})
+            movement := tt.movement
+
+            err := uc.AdjustStock(context.Background(), &movement)
+            if (err != nil) != tt.wantErr {
+                t.Fatalf("AdjustStock() error = %v, wantErr %v", err, tt.wantErr)
+            }
+            if tt.wantErr {
+                if len(ledger.adjusted) != 0 {
+                    t.Errorf("AdjustStock() recorded an invalid movement")
+                }
+                return
+            }
+            if len(ledger.adjusted) != 1 {
+                t.Fatalf("AdjustStock() recorded %d movements, want 1", len(ledger.adjusted))
+            }
+            got := ledger.adjusted[0]
+            if got.WarehouseID != tt.wantWarehouse || got.Reason != tt.wantReason || got.Actor != tt.wantActor {
+                t.Errorf("AdjustStock() recorded %s/%s by %q, want %s/%s by %q",
+                    got.WarehouseID, got.Reason, got.Actor, tt.wantWarehouse, tt.wantReason, tt.wantActor)
+            }
+        })
+    }
+}
+
+func TestGetStockHistoryPages(t *testing.T) {
+    ledger := &fakeLedger{}
+    for i := 5; i > 0; i-- {
+        ledger.history = append(ledger.history, &models.StockMovement{ID: fmt.Sprint(i), ProductID: "p-1", Delta: 1})
+    }
+    uc := newTestUseCase(Deps{Ledger: ledger})
+
+    var ids []string
+    token := ""
+    for page := 0; ; page++ {
+        if page > 5 {
+            t.Fatal("GetStockHistory() never returned the last page")
+        }
+        movements, next, err := uc.GetStockHistory(context.Background(), "p-1", "", token, 2)
+        if err != nil {
+            t.Fatalf("GetStockHistory() error = %v", err)
+        }
+        if len(movements) > 2 {
+            t.Fatalf("GetStockHistory() returned %d movements, want at most 2", len(movements))
+        }
+        for _, m := range movements {
+            ids = append(ids, m.ID)
+        }
+        if next == "" {
+            break
+        }
+        token = next
+    }
+    if fmt.Sprint(ids) != "[5 4 3 2 1]" {
+        t.Errorf("GetStockHistory() paged through %v, want [5 4 3 2 1]", ids)
+    }
+}
@@ -0,0 +1,118 @@
+package usecases
+
+import (
+    "context"
+    "errors"
+    "fmt"
+
+    "github.com/samObot19/shopverse/product-service/locale"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// ErrTranslationNotFound is returned when a product has no translation into a locale
+var ErrTranslationNotFound = errors.New("translation not found")
+
+type localesKey struct{}
+
+// WithLocales asks for products read with ctx to be shown in the first of
+// locales, most preferred first, that they are translated into; no locales
+// leaves them in their default content
+func WithLocales(ctx context.Context, locales []string) context.Context {
+    return context.WithValue(ctx, localesKey{}, locale.Chain(locales))
+}
+
+// localesFrom is the fallback chain of the locales asked for, if any were
+func localesFrom(ctx context.Context) []string {
+    chain, _ := ctx.Value(localesKey{}).([]string)
+    return chain
+}
+
+// present readies products read from the store for the caller: their
+// content is shown in the locales and their prices in the currency asked for
+// with ctx
+func (uc *ProductUseCase) present(ctx context.Context, products ...*models.Product) error {
+    locale.Localize(localesFrom(ctx), products...)
+    return uc.applyPrices(ctx, products...)
+}
+
+// presentVariants readies a product and its variants like present does
+func (uc *ProductUseCase) presentVariants(ctx context.Context, product *models.Product, variants ...*models.Variant) error {
+    locale.Localize(localesFrom(ctx), product)
+    return uc.applyVariantPrices(ctx, product, variants...)
+}
+
+// SetProductTranslation adds or replaces the translation of a product that
+// is still at version into a locale
+func (uc *ProductUseCase) SetProductTranslation(ctx context.Context, id, tag string, translation models.Translation, version int64) error {
+    tag, err := locale.Normalize(tag)
+    if err != nil {
+        return err
+    }
+    return uc.changeTranslations(ctx, id, version, func(translations models.Translations) error {
+        translations[tag] = translation
+        return nil
+    })
+}
+
+// DeleteProductTranslation removes the translation of a product that is
+// still at version into a locale, which falls back to the next one of the chain
+func (uc *ProductUseCase) DeleteProductTranslation(ctx context.Context, id, tag string, version int64) error {
+    tag, err := locale.Normalize(tag)
+    if err != nil {
+        return err
+    }
+    return uc.changeTranslations(ctx, id, version, func(translations models.Translations) error {
+        if _, ok := translations[tag]; !ok {
+            return fmt.Errorf("%w: product %s has no %s translation", ErrTranslationNotFound, id, tag)
+        }
+        delete(translations, tag)
+        return nil
+    })
+}
+
+// changeTranslations applies a change to a copy of the translations of a
+// product and writes them back as an update of the product at version
+func (uc *ProductUseCase) changeTranslations(ctx context.Context, id string, version int64, change func(models.Translations) error) error {
+    if id == "" {
+        return errors.New("product ID cannot be empty")
+    }
+    if version <= 0 {
+        return ErrVersionRequired
+    }
+    product, err := uc.repo.GetProductByID(ctx, id)
+    if err != nil {
+        return err
+    }
+    translations := make(models.Translations, len(product.Translations)+1)
+    for tag, t := range product.Translations {
+        translations[tag] = t
+    }
+    if err := change(translations); err != nil {
+        return err
+    }
+    updated := &models.Product{Translations: translations, Version: version}
+    return uc.UpdateProduct(ctx, id, updated, models.NewProductMask(models.ProductTranslations))
+}
+
+// normalizeTranslations checks the translations of a product and keys them
+// by their normalized locale
+func normalizeTranslations(translations models.Translations) (models.Translations, error) {
+    if len(translations) == 0 {
+        return nil, nil
+    }
+    normalized := make(models.Translations, len(translations))
+    for tag, t := range translations {
+        n, err := locale.Normalize(tag)
+        if err != nil {
+            return nil, err
+        }
+        if t.Title == "" && t.Description == "" {
+            return nil, fmt.Errorf("translation into %s has neither a title nor a description", n)
+        }
+        if _, ok := normalized[n]; ok {
+            return nil, fmt.Errorf("product is translated into %s more than once", n)
+        }
+        normalized[n] = t
+    }
+    return normalized, nil
+}
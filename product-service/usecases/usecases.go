@@ -3,25 +3,140 @@ package usecases
 import (
     "context"
     "errors"
+    "fmt"
+    "time"
+
+    orderpb "github.com/samObot19/shopverse/product-service/clients/order-client/proto/pb"
+    "github.com/samObot19/shopverse/product-service/fulfillment"
+    "github.com/samObot19/shopverse/product-service/media"
     "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/query"
     "github.com/samObot19/shopverse/product-service/repository"
+    "github.com/samObot19/shopverse/product-service/search"
 )
 
 type ProductUseCase struct {
-    repo repository.ProductRepository
+    repo            repository.ProductRepository
+    variants        repository.VariantRepository
+    reservations    repository.ReservationRepository
+    stockChecks     repository.StockCheckRepository
+    ledger          repository.StockLedgerRepository
+    warehouses      repository.WarehouseRepository
+    categories      repository.CategoryRepository
+    reviews         repository.ReviewRepository
+    prices          repository.PriceListRepository
+    rates           repository.ExchangeRateRepository
+    images          repository.ProductImageRepository
+    blobs           media.BlobStore
+    orders          orderpb.OrderServiceClient
+    index           *search.Index
+    suggestionLimit int
+    reservationTTL  time.Duration
+    fulfillment     fulfillment.Strategy
+}
+
+// ErrVersionRequired rejects a change to a product that does not say which
+// version of the product it was based on
+var ErrVersionRequired = errors.New("product version is required")
+
+// SearchResult is one page of ranked products with the facets of the whole result set
+type SearchResult struct {
+    Products []*models.Product
+    Total    int
+    Facets   search.Facets
+}
+
+// Deps are the stores and collaborators a ProductUseCase works with. Writes
+// that change a product must go through a store that keeps the product cache
+// up to date, so Repo, Reservations, Ledger, Categories and Reviews are
+// usually the cache; the others never change a product and may bypass it.
+type Deps struct {
+    Repo         repository.ProductRepository
+    Variants     repository.VariantRepository
+    Reservations repository.ReservationRepository
+    StockChecks  repository.StockCheckRepository
+    Ledger       repository.StockLedgerRepository
+    Warehouses   repository.WarehouseRepository
+    Categories   repository.CategoryRepository
+    Reviews      repository.ReviewRepository
+    Prices       repository.PriceListRepository
+    Rates        repository.ExchangeRateRepository
+    Images       repository.ProductImageRepository
+    // Blobs keeps the renditions of the images uploaded for products
+    Blobs media.BlobStore
+    // Orders reports the orders of customers, whose reviews are only
+    // accepted for products their orders include
+    Orders orderpb.OrderServiceClient
+    // Index is kept up to date with every write made through the use case
+    Index *search.Index
+    // SuggestionLimit caps the number of autocomplete suggestions returned
+    SuggestionLimit int
+    // ReservationTTL is how long reserved stock is held before it expires
+    ReservationTTL time.Duration
+    // Fulfillment picks the warehouses reserved stock is taken from
+    Fulfillment fulfillment.Strategy
+}
+
+// NewProductUseCase creates a new instance of ProductUseCase. Products are
+// read at the price the price lists in effect give them, converted at the
+// exchange rates of deps.Rates when a currency is asked for.
+func NewProductUseCase(deps Deps) *ProductUseCase {
+    return &ProductUseCase{
+        repo:            deps.Repo,
+        variants:        deps.Variants,
+        reservations:    deps.Reservations,
+        stockChecks:     deps.StockChecks,
+        ledger:          deps.Ledger,
+        warehouses:      deps.Warehouses,
+        categories:      deps.Categories,
+        reviews:         deps.Reviews,
+        prices:          deps.Prices,
+        rates:           deps.Rates,
+        images:          deps.Images,
+        blobs:           deps.Blobs,
+        orders:          deps.Orders,
+        index:           deps.Index,
+        suggestionLimit: deps.SuggestionLimit,
+        reservationTTL:  deps.ReservationTTL,
+        fulfillment:     deps.Fulfillment,
+    }
 }
 
-// NewProductUseCase creates a new instance of ProductUseCase
-func NewProductUseCase(repo repository.ProductRepository) *ProductUseCase {
-    return &ProductUseCase{repo: repo}
+// RebuildSearchIndex reloads the whole catalogue into the search index
+func (uc *ProductUseCase) RebuildSearchIndex(ctx context.Context) error {
+    products, err := uc.repo.GetAllProducts(ctx, nil, nil, nil, false)
+    if err != nil {
+        return fmt.Errorf("failed to load products for indexing: %w", err)
+    }
+    uc.index.Rebuild(products)
+    return nil
 }
 
 // CreateProduct creates a new product
 func (uc *ProductUseCase) CreateProduct(ctx context.Context, product *models.Product) error {
-    if product.Title == "" || product.Price <= 0 || product.Stock < 0 {
+    if product.Title == "" || product.Stock < 0 {
         return errors.New("invalid product data")
     }
-    return uc.repo.CreateProduct(ctx, product)
+    if err := validatePrice(product.Price, nil); err != nil {
+        return err
+    }
+    translations, err := normalizeTranslations(product.Translations)
+    if err != nil {
+        return err
+    }
+    product.Translations = translations
+    // the rating is earned through reviews
+    product.Ratings = 0
+    product.ReviewCount = 0
+    product.Version = 1
+    if err := uc.fileProduct(ctx, product); err != nil {
+        return err
+    }
+    if err := uc.repo.CreateProduct(ctx, product); err != nil {
+        return err
+    }
+    uc.index.Add(product)
+    return uc.recordBasePrice(ctx, product.ID, "", &product.Price)
 }
 
 // GetProductByID retrieves a product by its ID
@@ -29,54 +144,197 @@ func (uc *ProductUseCase) GetProductByID(ctx context.Context, id string) (*model
     if id == "" {
         return nil, errors.New("product ID cannot be empty")
     }
-    return uc.repo.GetProductByID(ctx, id)
+    product, err := uc.repo.GetProductByID(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    return product, uc.present(ctx, product)
 }
 
-// GetAllProducts retrieves all products with optional filters
-func (uc *ProductUseCase) GetAllProducts(ctx context.Context, filters map[string]interface{}) ([]*models.Product, error) {
-    return uc.repo.GetAllProducts(ctx, filters)
+// GetAllProducts retrieves all products matching an optional filter, in the
+// requested order; archived products are only included when asked for.
+// Prices are filtered and sorted by their worth in the currency asked for
+// with ctx.
+func (uc *ProductUseCase) GetAllProducts(ctx context.Context, filter *query.Filter, sort []query.Sort, includeArchived bool) ([]*models.Product, error) {
+    if err := filter.Validate(); err != nil {
+        return nil, err
+    }
+    if err := query.ValidateSort(sort); err != nil {
+        return nil, err
+    }
+    prices, err := uc.listingPrices(ctx, filter, sort)
+    if err != nil {
+        return nil, err
+    }
+    products, err := uc.repo.GetAllProducts(ctx, filter, sort, prices, includeArchived)
+    if err != nil {
+        return nil, err
+    }
+    return products, uc.present(ctx, products...)
 }
 
-// UpdateProduct updates an existing product
-func (uc *ProductUseCase) UpdateProduct(ctx context.Context, id string, updatedProduct *models.Product) error {
+// UpdateProduct sets the masked fields of an existing product that is still
+// at updatedProduct.Version and leaves the others as they are; a product
+// changed since that version was read fails with repository.ErrVersionConflict
+func (uc *ProductUseCase) UpdateProduct(ctx context.Context, id string, updatedProduct *models.Product, mask models.ProductMask) error {
     if id == "" {
         return errors.New("product ID cannot be empty")
     }
-    return uc.repo.UpdateProduct(ctx, id, updatedProduct)
+    if updatedProduct.Version <= 0 {
+        return ErrVersionRequired
+    }
+    before, err := uc.repo.GetProductByID(ctx, id)
+    if err != nil {
+        return err
+    }
+
+    // the product is checked as a whole, as it will be once the mask is applied
+    updated := *before
+    mask.Apply(&updated, updatedProduct)
+    updated.Version = updatedProduct.Version
+    if err := uc.validateProduct(ctx, &updated); err != nil {
+        return err
+    }
+    if updated.Price.Currency != before.Price.Currency {
+        return errCurrencyChange(before)
+    }
+    if mask[models.ProductCategoryID] {
+        // the category name follows the category it is filed under
+        mask = mask.With(models.ProductCategory)
+    }
+    if err := uc.repo.UpdateProduct(ctx, id, &updated, mask); err != nil {
+        return err
+    }
+
+    // reindex the stored row rather than the request, which may omit fields
+    product, err := uc.repo.GetProductByID(ctx, id)
+    if err != nil {
+        return fmt.Errorf("product updated but could not be reindexed: %w", err)
+    }
+    if !product.Archived() {
+        uc.index.Add(product)
+    }
+    if product.Price == before.Price {
+        return nil
+    }
+    return uc.recordBasePrice(ctx, id, "", &product.Price)
 }
 
-// DeleteProduct deletes a product by its ID
-func (uc *ProductUseCase) DeleteProduct(ctx context.Context, id string) error {
+// DeleteProduct archives a product: it leaves the catalogue and search, but
+// can still be read by ID for the orders that reference it and be restored
+// until the retention period has passed and it is purged. Like an update, it
+// fails with repository.ErrVersionConflict unless the product is at version.
+func (uc *ProductUseCase) DeleteProduct(ctx context.Context, id string, version int64) error {
     if id == "" {
         return errors.New("product ID cannot be empty")
     }
-    return uc.repo.DeleteProduct(ctx, id)
+    if version <= 0 {
+        return ErrVersionRequired
+    }
+    if err := uc.repo.ArchiveProduct(ctx, id, time.Now().UTC(), version); err != nil {
+        return err
+    }
+    uc.index.Remove(id)
+    return nil
 }
 
-// UpdateStock updates the stock quantity of a product
-func (uc *ProductUseCase) UpdateStock(ctx context.Context, id string, quantity int) error {
+// UpdateStock sets the stock quantity of a product; the difference is recorded
+// in the stock ledger with the given reason. The product must still be at
+// version, or the change fails with repository.ErrVersionConflict.
+func (uc *ProductUseCase) UpdateStock(ctx context.Context, id string, quantity int, change models.StockChange, version int64) error {
     if id == "" {
         return errors.New("product ID cannot be empty")
     }
-    if quantity == 0 {
-        return errors.New("quantity must not be zero")
+    if version <= 0 {
+        return ErrVersionRequired
     }
-
-    return uc.repo.UpdateStock(ctx, id, quantity)
+    if quantity < 0 {
+        return errors.New("stock cannot be negative")
+    }
+    change, err := validateStockChange(change)
+    if err != nil {
+        return err
+    }
+    return uc.repo.UpdateStock(ctx, id, quantity, change, version)
 }
 
 // GetProductsByCategory retrieves products by category
-func (uc *ProductUseCase) GetProductsByCategory(ctx context.Context, category string) ([]*models.Product, error) {
+func (uc *ProductUseCase) GetProductsByCategory(ctx context.Context, category string, sort []query.Sort) ([]*models.Product, error) {
     if category == "" {
         return nil, errors.New("category cannot be empty")
     }
-    return uc.repo.GetProductsByCategory(ctx, category)
+    if err := query.ValidateSort(sort); err != nil {
+        return nil, err
+    }
+    prices, err := uc.listingPrices(ctx, nil, sort)
+    if err != nil {
+        return nil, err
+    }
+    products, err := uc.repo.GetProductsByCategory(ctx, category, sort, prices)
+    if err != nil {
+        return nil, err
+    }
+    return products, uc.present(ctx, products...)
 }
 
-// SearchProducts searches for products based on a query string
-func (uc *ProductUseCase) SearchProducts(ctx context.Context, query string) ([]*models.Product, error) {
-    if query == "" {
+// SearchProducts ranks products against a free-text query, in the locales
+// and currency asked for with ctx, and loads the requested page
+func (uc *ProductUseCase) SearchProducts(ctx context.Context, req search.Request) (*SearchResult, error) {
+    if req.Text == "" {
         return nil, errors.New("search query cannot be empty")
     }
-    return uc.repo.SearchProducts(ctx, query)
-}
\ No newline at end of file
+    if err := query.ValidateSort(req.Sort); err != nil {
+        return nil, err
+    }
+    if req.Limit < 0 || req.Offset < 0 {
+        return nil, errors.New("limit and offset cannot be negative")
+    }
+
+    rates, err := uc.loadRates(ctx)
+    if err != nil {
+        return nil, err
+    }
+    req.Locales = localesFrom(ctx)
+    req.Currency = currencyFrom(ctx)
+    req.Rates = rates
+    result := uc.index.Search(req)
+    ids := make([]string, len(result.Hits))
+    for i, hit := range result.Hits {
+        ids[i] = hit.ID
+    }
+    products, err := uc.repo.GetProductsByIDs(ctx, ids)
+    if err != nil {
+        return nil, err
+    }
+
+    // restore the ranked order; products deleted or archived since indexing are dropped
+    byID := make(map[string]*models.Product, len(products))
+    for _, product := range products {
+        byID[product.ID] = product
+    }
+    ranked := make([]*models.Product, 0, len(ids))
+    for _, id := range ids {
+        if product, ok := byID[id]; ok && !product.Archived() {
+            ranked = append(ranked, product)
+        }
+    }
+    if err := uc.present(ctx, ranked...); err != nil {
+        return nil, err
+    }
+    return &SearchResult{Products: ranked, Total: result.Total, Facets: result.Facets}, nil
+}
+
+// SuggestProducts completes a search prefix to product titles and categories.
+// A limit of zero, or one above the configured cap, returns the cap.
+func (uc *ProductUseCase) SuggestProducts(ctx context.Context, prefix string, limit int) ([]search.Suggestion, error) {
+    if prefix == "" {
+        return nil, errors.New("prefix cannot be empty")
+    }
+    if limit < 0 {
+        return nil, errors.New("limit cannot be negative")
+    }
+    if limit == 0 || limit > uc.suggestionLimit {
+        limit = uc.suggestionLimit
+    }
+    return uc.index.Suggest(prefix, limit), nil
+}
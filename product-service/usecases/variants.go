@@ -0,0 +1,207 @@
+package usecases
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/google/uuid"
+    "github.com/samObot19/shopverse/product-service/models"
+    "github.com/samObot19/shopverse/product-service/money"
+)
+
+// CreateVariant adds a variant to an existing product and returns its ID
+func (uc *ProductUseCase) CreateVariant(ctx context.Context, variant *models.Variant) (string, error) {
+    if variant.ProductID == "" {
+        return "", errors.New("product ID cannot be empty")
+    }
+    if err := validateVariant(variant); err != nil {
+        return "", err
+    }
+    product, err := uc.repo.GetProductByID(ctx, variant.ProductID)
+    if err != nil {
+        return "", fmt.Errorf("failed to load product %s: %w", variant.ProductID, err)
+    }
+    if err := validateVariantPrice(variant, product); err != nil {
+        return "", err
+    }
+    if err := uc.checkOptionsUnique(ctx, variant.ProductID, "", variant.Options); err != nil {
+        return "", err
+    }
+
+    if variant.ID == "" {
+        variant.ID = uuid.NewString()
+    }
+    if variant.CreatedAt.IsZero() {
+        variant.CreatedAt = time.Now().UTC()
+    }
+    if err := uc.variants.CreateVariant(ctx, variant); err != nil {
+        return "", err
+    }
+    if variant.Price != nil {
+        if err := uc.recordBasePrice(ctx, variant.ProductID, variant.ID, variant.Price); err != nil {
+            return "", err
+        }
+    }
+    return variant.ID, nil
+}
+
+// GetVariant retrieves a variant together with the product it belongs to
+func (uc *ProductUseCase) GetVariant(ctx context.Context, id string) (*models.Variant, *models.Product, error) {
+    if id == "" {
+        return nil, nil, errors.New("variant ID cannot be empty")
+    }
+    variant, err := uc.variants.GetVariantByID(ctx, id)
+    if err != nil {
+        return nil, nil, err
+    }
+    product, err := uc.repo.GetProductByID(ctx, variant.ProductID)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to load product %s: %w", variant.ProductID, err)
+    }
+    if err := uc.presentVariants(ctx, product, variant); err != nil {
+        return nil, nil, err
+    }
+    return variant, product, nil
+}
+
+// ListVariants retrieves a product and all of its variants
+func (uc *ProductUseCase) ListVariants(ctx context.Context, productID string) (*models.Product, []*models.Variant, error) {
+    if productID == "" {
+        return nil, nil, errors.New("product ID cannot be empty")
+    }
+    product, err := uc.repo.GetProductByID(ctx, productID)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to load product %s: %w", productID, err)
+    }
+    variants, err := uc.variants.ListVariants(ctx, productID)
+    if err != nil {
+        return nil, nil, err
+    }
+    if err := uc.presentVariants(ctx, product, variants...); err != nil {
+        return nil, nil, err
+    }
+    return product, variants, nil
+}
+
+// UpdateVariant replaces the SKU, options, price, stock and images of a variant
+func (uc *ProductUseCase) UpdateVariant(ctx context.Context, id string, variant *models.Variant) error {
+    if id == "" {
+        return errors.New("variant ID cannot be empty")
+    }
+    if err := validateVariant(variant); err != nil {
+        return err
+    }
+    existing, err := uc.variants.GetVariantByID(ctx, id)
+    if err != nil {
+        return err
+    }
+    if err := uc.checkOptionsUnique(ctx, existing.ProductID, id, variant.Options); err != nil {
+        return err
+    }
+    if variant.Price != nil {
+        product, err := uc.repo.GetProductByID(ctx, existing.ProductID)
+        if err != nil {
+            return fmt.Errorf("failed to load product %s: %w", existing.ProductID, err)
+        }
+        if err := validateVariantPrice(variant, product); err != nil {
+            return err
+        }
+    }
+    if err := uc.variants.UpdateVariant(ctx, id, variant); err != nil {
+        return err
+    }
+    if samePrice(existing.Price, variant.Price) {
+        return nil
+    }
+    return uc.recordBasePrice(ctx, existing.ProductID, id, variant.Price)
+}
+
+// samePrice reports whether two optional prices are equal
+func samePrice(a, b *money.Money) bool {
+    if a == nil || b == nil {
+        return a == b
+    }
+    return *a == *b
+}
+
+// DeleteVariant deletes a variant by its ID
+func (uc *ProductUseCase) DeleteVariant(ctx context.Context, id string) error {
+    if id == "" {
+        return errors.New("variant ID cannot be empty")
+    }
+    return uc.variants.DeleteVariant(ctx, id)
+}
+
+// UpdateVariantStock sets the stock quantity of a variant; the difference is
+// recorded in the stock ledger with the given reason
+func (uc *ProductUseCase) UpdateVariantStock(ctx context.Context, id string, quantity int, change models.StockChange) error {
+    if id == "" {
+        return errors.New("variant ID cannot be empty")
+    }
+    if quantity < 0 {
+        return errors.New("stock cannot be negative")
+    }
+    change, err := validateStockChange(change)
+    if err != nil {
+        return err
+    }
+    return uc.variants.UpdateVariantStock(ctx, id, quantity, change)
+}
+
+func validateVariant(variant *models.Variant) error {
+    variant.SKU = strings.TrimSpace(variant.SKU)
+    if variant.SKU == "" {
+        return errors.New("SKU cannot be empty")
+    }
+    if variant.Stock < 0 {
+        return errors.New("stock cannot be negative")
+    }
+    for name, value := range variant.Options {
+        if strings.TrimSpace(name) == "" || strings.TrimSpace(value) == "" {
+            return errors.New("option names and values cannot be empty")
+        }
+    }
+    return nil
+}
+
+// validateVariantPrice requires a price override to be positive and in the
+// currency of the variant's product
+func validateVariantPrice(variant *models.Variant, product *models.Product) error {
+    if variant.Price == nil {
+        return nil
+    }
+    if err := validatePrice(*variant.Price, product); err != nil {
+        return fmt.Errorf("price override: %w", err)
+    }
+    return nil
+}
+
+// checkOptionsUnique rejects a second variant of a product with the same option
+// values, since a shopper could not choose between them
+func (uc *ProductUseCase) checkOptionsUnique(ctx context.Context, productID, exceptID string, options map[string]string) error {
+    variants, err := uc.variants.ListVariants(ctx, productID)
+    if err != nil {
+        return err
+    }
+    key := optionsKey(options)
+    for _, v := range variants {
+        if v.ID != exceptID && optionsKey(v.Options) == key {
+            return fmt.Errorf("variant %s already has options %s", v.ID, key)
+        }
+    }
+    return nil
+}
+
+// optionsKey renders option values in a canonical, case-insensitive form
+func optionsKey(options map[string]string) string {
+    pairs := make([]string, 0, len(options))
+    for name, value := range options {
+        pairs = append(pairs, strings.ToLower(strings.TrimSpace(name))+"="+strings.ToLower(strings.TrimSpace(value)))
+    }
+    sort.Strings(pairs)
+    return "{" + strings.Join(pairs, ", ") + "}"
+}
@@ -0,0 +1,77 @@
+package usecases
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "time"
+
+    "github.com/google/uuid"
+    "github.com/samObot19/shopverse/product-service/models"
+)
+
+// CreateWarehouse adds a warehouse and returns its ID
+func (uc *ProductUseCase) CreateWarehouse(ctx context.Context, warehouse *models.Warehouse) (string, error) {
+    if err := validateWarehouse(warehouse); err != nil {
+        return "", err
+    }
+    if warehouse.ID == "" {
+        warehouse.ID = uuid.NewString()
+    }
+    if warehouse.CreatedAt.IsZero() {
+        warehouse.CreatedAt = time.Now().UTC()
+    }
+    if err := uc.warehouses.CreateWarehouse(ctx, warehouse); err != nil {
+        return "", err
+    }
+    return warehouse.ID, nil
+}
+
+// GetWarehouse retrieves a warehouse by its ID
+func (uc *ProductUseCase) GetWarehouse(ctx context.Context, id string) (*models.Warehouse, error) {
+    if id == "" {
+        return nil, errors.New("warehouse ID cannot be empty")
+    }
+    return uc.warehouses.GetWarehouse(ctx, id)
+}
+
+// ListWarehouses retrieves all warehouses, active or not
+func (uc *ProductUseCase) ListWarehouses(ctx context.Context) ([]*models.Warehouse, error) {
+    return uc.warehouses.ListWarehouses(ctx)
+}
+
+// UpdateWarehouse replaces the details of a warehouse; deactivating it keeps
+// its stock but stops new reservations from taking stock there
+func (uc *ProductUseCase) UpdateWarehouse(ctx context.Context, id string, warehouse *models.Warehouse) error {
+    if id == "" {
+        return errors.New("warehouse ID cannot be empty")
+    }
+    if err := validateWarehouse(warehouse); err != nil {
+        return err
+    }
+    return uc.warehouses.UpdateWarehouse(ctx, id, warehouse)
+}
+
+// GetStockLevels returns the stock of a product, and of its variants, in every
+// warehouse holding some
+func (uc *ProductUseCase) GetStockLevels(ctx context.Context, productID string) ([]models.StockLevel, error) {
+    if productID == "" {
+        return nil, errors.New("product ID cannot be empty")
+    }
+    return uc.warehouses.StockLevels(ctx, []string{productID})
+}
+
+// validateWarehouse requires a name and a location on the map
+func validateWarehouse(warehouse *models.Warehouse) error {
+    warehouse.Name = strings.TrimSpace(warehouse.Name)
+    if warehouse.Name == "" {
+        return errors.New("warehouse name cannot be empty")
+    }
+    if warehouse.Latitude < -90 || warehouse.Latitude > 90 {
+        return errors.New("latitude must be between -90 and 90")
+    }
+    if warehouse.Longitude < -180 || warehouse.Longitude > 180 {
+        return errors.New("longitude must be between -180 and 180")
+    }
+    return nil
+}
@@ -10,4 +10,5 @@ type User struct {
 	Password 	   string             `json:"password" bson:"password"`
     ProfilePicture string             `json:"profile_picture,omitempty" bson:"profile_picture,omitempty"` // URL to the user's profile picture
     Role           string             `json:"role" bson:"role"`                 // User role (e.g., "customer", "admin")
+    PreferredCurrency string          `json:"preferred_currency,omitempty" bson:"preferred_currency,omitempty"` // ISO 4217 code prices are shown in; empty for the catalog's own
 }
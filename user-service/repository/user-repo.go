@@ -23,6 +23,7 @@ type UserRepository interface {
 	UpdateUser(username string, data *models.User) (models.User, error)
 	GetUsers() ([]models.User, error) 
 	GetUserByID(id string) (models.User, error)
+	UpdatePreferences(email string, preferredCurrency string) (models.User, error)
 }
 
 type MongoUserRepo struct {
@@ -192,3 +193,25 @@ func (s *MongoUserRepo) GetUserByID(id string) (models.User, error) {
     return user, nil
 }
 
+
+
+// UpdatePreferences sets the preferences of the user with the given email; an
+// empty preference is cleared
+func (s *MongoUserRepo) UpdatePreferences(email string, preferredCurrency string) (models.User, error) {
+	filter := bson.M{"email": email}
+	update := bson.M{"$set": bson.M{"preferred_currency": preferredCurrency}}
+	if preferredCurrency == "" {
+		update = bson.M{"$unset": bson.M{"preferred_currency": ""}}
+	}
+
+	var user models.User
+	err := s.Collection.FindOneAndUpdate(context.TODO(), filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.User{}, errors.New("user not found")
+		}
+		return models.User{}, err
+	}
+
+	return user, nil
+}
@@ -35,13 +35,14 @@ func (s *UserServiceImpl) AddUser(ctx context.Context, req *pb.AddUserRequest) (
 
     return &pb.AddUserResponse{
         User: &pb.User{
-            Id:             user.ID.Hex(),
-            GoogleId:       user.GoogleID,
-            Name:           user.Name,
-            Email:          user.Email,
-            Password:       user.Password,
-            ProfilePicture: user.ProfilePicture,
-            Role:           user.Role,
+            Id:                user.ID.Hex(),
+            GoogleId:          user.GoogleID,
+            Name:              user.Name,
+            Email:             user.Email,
+            Password:          user.Password,
+            ProfilePicture:    user.ProfilePicture,
+            Role:              user.Role,
+            PreferredCurrency: user.PreferredCurrency,
         },
     }, nil
 }
@@ -72,13 +73,14 @@ func (s *UserServiceImpl) UpdateUser(ctx context.Context, req *pb.UpdateUserRequ
 
     return &pb.UpdateUserResponse{
         User: &pb.User{
-            Id:             user.ID.Hex(),
-            GoogleId:       user.GoogleID,
-            Name:           user.Name,
-            Email:          user.Email,
-            Password:       user.Password,
-            ProfilePicture: user.ProfilePicture,
-            Role:           user.Role,
+            Id:                user.ID.Hex(),
+            GoogleId:          user.GoogleID,
+            Name:              user.Name,
+            Email:             user.Email,
+            Password:          user.Password,
+            ProfilePicture:    user.ProfilePicture,
+            Role:              user.Role,
+            PreferredCurrency: user.PreferredCurrency,
         },
     }, nil
 }
@@ -93,13 +95,14 @@ func (s *UserServiceImpl) GetUser(ctx context.Context, req *pb.GetUserRequest) (
 
     return &pb.GetUserResponse{
         User: &pb.User{
-            Id:             user.ID.Hex(),
-            GoogleId:       user.GoogleID,
-            Name:           user.Name,
-            Email:          user.Email,
-            Password:       user.Password,
-            ProfilePicture: user.ProfilePicture,
-            Role:           user.Role,
+            Id:                user.ID.Hex(),
+            GoogleId:          user.GoogleID,
+            Name:              user.Name,
+            Email:             user.Email,
+            Password:          user.Password,
+            ProfilePicture:    user.ProfilePicture,
+            Role:              user.Role,
+            PreferredCurrency: user.PreferredCurrency,
         },
     }, nil
 }
@@ -120,13 +123,14 @@ func (s *UserServiceImpl) GetUserByID(ctx context.Context, req *pb.GetUserByIDRe
 
     return &pb.GetUserByIDResponse{
         User: &pb.User{
-            Id:             user.ID.Hex(),
-            GoogleId:       user.GoogleID,
-            Name:           user.Name,
-            Email:          user.Email,
-            Password:       user.Password,
-            ProfilePicture: user.ProfilePicture,
-            Role:           user.Role,
+            Id:                user.ID.Hex(),
+            GoogleId:          user.GoogleID,
+            Name:              user.Name,
+            Email:             user.Email,
+            Password:          user.Password,
+            ProfilePicture:    user.ProfilePicture,
+            Role:              user.Role,
+            PreferredCurrency: user.PreferredCurrency,
         },
     }, nil
 }
@@ -142,13 +146,14 @@ func (s *UserServiceImpl) GetAllUsers(ctx context.Context, req *pb.GetAllUsersRe
     var pbUsers []*pb.User
     for _, user := range users {
         pbUsers = append(pbUsers, &pb.User{
-            Id:             user.ID.Hex(),
-            GoogleId:       user.GoogleID,
-            Name:           user.Name,
-            Email:          user.Email,
-            Password:       user.Password,
-            ProfilePicture: user.ProfilePicture,
-            Role:           user.Role,
+            Id:                user.ID.Hex(),
+            GoogleId:          user.GoogleID,
+            Name:              user.Name,
+            Email:             user.Email,
+            Password:          user.Password,
+            ProfilePicture:    user.ProfilePicture,
+            Role:              user.Role,
+            PreferredCurrency: user.PreferredCurrency,
         })
     }
 
@@ -158,6 +163,28 @@ func (s *UserServiceImpl) GetAllUsers(ctx context.Context, req *pb.GetAllUsersRe
 }
 
 
+func (s *UserServiceImpl) UpdatePreferences(ctx context.Context, req *pb.UpdatePreferencesRequest) (*pb.UpdatePreferencesResponse, error) {
+    user, err := s.UserUsecase.UpdatePreferences(req.Email, req.PreferredCurrency)
+    if err != nil {
+        log.Printf("Error updating preferences: %v", err)
+        return nil, err
+    }
+
+    return &pb.UpdatePreferencesResponse{
+        User: &pb.User{
+            Id:                user.ID.Hex(),
+            GoogleId:          user.GoogleID,
+            Name:              user.Name,
+            Email:             user.Email,
+            Password:          user.Password,
+            ProfilePicture:    user.ProfilePicture,
+            Role:              user.Role,
+            PreferredCurrency: user.PreferredCurrency,
+        },
+    }, nil
+}
+
+
 func (s *UserServiceImpl) PromoteUser(ctx context.Context, req *pb.PromoteUserRequest) (*pb.PromoteUserResponse, error) {
     err := s.UserUsecase.PromoteUser(req.Username)
     if err != nil {
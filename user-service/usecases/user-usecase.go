@@ -6,8 +6,13 @@ import (
 	"github.com/samObot19/shopverse/user-service/events"
 	"errors"
 	"log"
+	"regexp"
+	"strings"
 )
 
+// currencyPattern matches an ISO 4217 currency code such as EUR
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
 type UserUsecase struct{
 	db repository.UserRepository
 }
@@ -77,3 +82,13 @@ func (s *UserUsecase) GetUserByID(id string) (models.User, error) {
     }
     return user, nil
 }
+
+// UpdatePreferences sets the currency the user with the given email sees
+// prices in; an empty currency goes back to the catalog's own
+func (s *UserUsecase) UpdatePreferences(email string, preferredCurrency string) (models.User, error) {
+    preferredCurrency = strings.ToUpper(strings.TrimSpace(preferredCurrency))
+    if preferredCurrency != "" && !currencyPattern.MatchString(preferredCurrency) {
+        return models.User{}, errors.New("invalid currency code")
+    }
+    return s.db.UpdatePreferences(email, preferredCurrency)
+}